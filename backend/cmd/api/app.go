@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"todo-api/internal/calendar"
+	"todo-api/internal/config"
+	"todo-api/internal/encryption"
+	"todo-api/internal/errors"
+	"todo-api/internal/handler"
+	"todo-api/internal/job"
+	"todo-api/internal/mailer"
+	authMiddleware "todo-api/internal/middleware"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/scanner"
+	"todo-api/internal/search"
+	"todo-api/internal/service"
+	"todo-api/internal/storage"
+	"todo-api/internal/suggest"
+	"todo-api/internal/validator"
+)
+
+// dependencies are the external services shared by the API of every tenant
+type dependencies struct {
+	storage            *storage.S3Storage
+	mail               mailer.Mailer
+	suggestionProvider suggest.Provider
+	fileScanner        scanner.Scanner
+	calendarProvider   calendar.Provider
+}
+
+// newDependencies initializes the external services
+func newDependencies(cfg *config.Config) (*dependencies, error) {
+	deps := &dependencies{}
+
+	// Initialize S3 storage
+	s3Storage, err := storage.NewS3Storage(cfg.GetS3Config())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize S3 storage: %w", err)
+	}
+	deps.storage = s3Storage
+
+	// Initialize mailer
+	deps.mail = mailer.New(cfg.GetMailConfig())
+
+	// Initialize AI suggestion provider (nil disables the feature)
+	if aiCfg := cfg.GetAIConfig(); aiCfg.Enabled {
+		deps.suggestionProvider, err = suggest.New(aiCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize AI suggestion provider: %w", err)
+		}
+		log.Info().Str("provider", deps.suggestionProvider.Name()).Msg("AI suggestions enabled")
+	}
+
+	// Initialize antivirus scanner (nil disables scanning)
+	deps.fileScanner, err = scanner.New(cfg.GetScannerConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize file scanner: %w", err)
+	}
+
+	// Initialize calendar provider (nil disables calendar sync)
+	deps.calendarProvider = calendar.New(cfg.GetCalendarConfig())
+	if deps.calendarProvider != nil {
+		log.Info().Str("provider", deps.calendarProvider.Name()).Msg("Calendar sync enabled")
+	}
+
+	return deps, nil
+}
+
+// newEcho creates an Echo instance with the API error handler and validator
+func newEcho() *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+
+	// Set custom error handler
+	e.HTTPErrorHandler = errors.ErrorHandler
+
+	// Set custom validator
+	validator.SetupValidator(e)
+
+	return e
+}
+
+// setupAPI registers the API routes on e, backed by db, and returns the background jobs without starting them.
+// In schema tenancy mode it runs once per tenant with the tenant's configuration and schema connection.
+func setupAPI(e *echo.Echo, cfg *config.Config, db *gorm.DB, deps *dependencies) (*job.Scheduler, error) {
+	// Initialize repositories
+	userRepo := repository.NewUserRepository(db)
+	denylistRepo := repository.NewJwtDenylistRepository(db)
+	todoRepo := repository.NewTodoRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	historyRepo := repository.NewTodoHistoryRepository(db)
+	fileRepo := repository.NewFileRepository(db)
+	noteRepo := repository.NewNoteRepository(db)
+	noteRevisionRepo := repository.NewNoteRevisionRepository(db)
+	preferenceRepo := repository.NewUserPreferenceRepository(db)
+	focusSessionRepo := repository.NewFocusSessionRepository(db)
+	streakRepo := repository.NewStreakRepository(db)
+	dataExportRepo := repository.NewDataExportRepository(db)
+	escalationRuleRepo := repository.NewEscalationRuleRepository(db)
+	myDayRepo := repository.NewMyDayRepository(db)
+	todoLinkRepo := repository.NewTodoLinkRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	encryptionRepo := repository.NewEncryptionRepository(db)
+	consentRepo := repository.NewPolicyConsentRepository(db)
+	usageRepo := repository.NewAPIUsageRepository(db)
+	oauthRepo := repository.NewOAuthRepository(db)
+	auditRepo := repository.NewAuditLogRepository(db)
+	integrationRepo := repository.NewIntegrationRepository(db)
+	syncRepo := repository.NewSyncRepository(db)
+	calendarRepo := repository.NewCalendarRepository(db)
+
+	// Initialize search index; external indexes are kept in sync by write callbacks and a periodic job
+	searchIndex, err := search.New(cfg.GetSearchConfig(), searchRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize search index: %w", err)
+	}
+	var searchSyncService *service.SearchSyncService
+	if es, ok := searchIndex.(*search.ElasticsearchIndex); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.GetSearchConfig().Timeout)
+		if err := es.EnsureIndex(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to create search index")
+		}
+		cancel()
+		if err := search.RegisterCallbacks(db); err != nil {
+			return nil, fmt.Errorf("failed to register search callbacks: %w", err)
+		}
+		searchSyncService = service.NewSearchSyncService(searchRepo, es)
+	}
+	log.Info().Str("backend", searchIndex.Name()).Msg("Search index initialized")
+
+	// Initialize services
+	streakService := service.NewStreakService(streakRepo, preferenceRepo)
+	todoService := service.NewTodoService(todoRepo, categoryRepo, historyRepo, preferenceRepo, streakService, cfg.GetSearchConfig())
+	thumbnailService := service.NewThumbnailService(deps.storage)
+	fileScanService := service.NewFileScanService(fileRepo, deps.storage, deps.fileScanner)
+	fileService := service.NewFileService(fileRepo, todoRepo, deps.storage, thumbnailService, fileScanService)
+	noteService := service.NewNoteService(noteRepo, noteRevisionRepo)
+	preferenceService := service.NewPreferenceService(preferenceRepo)
+	digestService := service.NewDigestService(preferenceRepo, todoRepo, deps.mail)
+	focusService := service.NewFocusService(focusSessionRepo, todoRepo, preferenceRepo)
+	suggestionService := service.NewSuggestionService(deps.suggestionProvider, todoRepo, categoryRepo, preferenceRepo)
+	searchService := service.NewSearchService(searchIndex)
+	retentionService := service.NewRetentionService(preferenceRepo, todoRepo, noteRepo)
+	dataExportService := service.NewDataExportService(
+		dataExportRepo, userRepo, preferenceRepo, todoRepo, categoryRepo, tagRepo,
+		commentRepo, historyRepo, noteRepo, fileRepo, focusSessionRepo, streakRepo, consentRepo, todoLinkRepo,
+		myDayRepo, escalationRuleRepo, integrationRepo, calendarRepo, deps.storage, deps.mail, cfg.GetDataExportConfig(),
+	)
+	escalationService := service.NewEscalationService(escalationRuleRepo, preferenceRepo, todoRepo, historyRepo, deps.mail)
+	myDayService := service.NewMyDayService(myDayRepo, todoRepo, preferenceRepo)
+	todoLinkService := service.NewTodoLinkService(todoLinkRepo, todoRepo)
+	reencryptionService := service.NewReencryptionService(encryptionRepo)
+	consentService := service.NewConsentService(consentRepo, cfg.GetPolicyConfig())
+	usageService := service.NewUsageService(usageRepo, userRepo, cfg.GetUsageConfig())
+	auditService := service.NewAuditService(auditRepo)
+	// Every write through db is recorded, whether made by a request or a background job
+	if err := auditService.RegisterCallbacks(db); err != nil {
+		return nil, fmt.Errorf("failed to register audit callbacks: %w", err)
+	}
+	integrationService := service.NewIntegrationService(integrationRepo)
+	syncService := service.NewSyncService(syncRepo, todoService)
+	calendarService := service.NewCalendarService(calendarRepo, syncRepo, todoService, deps.calendarProvider, cfg.GetCalendarConfig())
+	oauthService := service.NewOAuthService(oauthRepo, userRepo, service.NewAuthService(userRepo, denylistRepo, cfg), cfg.GetOAuthConfig())
+
+	// Initialize handlers
+	authHandler := handler.NewAuthHandler(userRepo, denylistRepo, consentRepo, cfg)
+	todoHandler := handler.NewTodoHandler(todoService, todoRepo, todoLinkService)
+	categoryHandler := handler.NewCategoryHandler(categoryRepo)
+	tagHandler := handler.NewTagHandler(tagRepo)
+	commentHandler := handler.NewCommentHandler(commentRepo, todoRepo)
+	historyHandler := handler.NewTodoHistoryHandler(historyRepo, todoRepo)
+	fileHandler := handler.NewFileHandler(fileService)
+	noteHandler := handler.NewNoteHandler(noteService, noteRepo, noteRevisionRepo)
+	preferenceHandler := handler.NewPreferenceHandler(preferenceService)
+	focusSessionHandler := handler.NewFocusSessionHandler(focusService)
+	streakHandler := handler.NewStreakHandler(streakService)
+	suggestionHandler := handler.NewSuggestionHandler(suggestionService)
+	dataExportHandler := handler.NewDataExportHandler(dataExportService)
+	retentionHandler := handler.NewRetentionHandler(retentionService)
+	escalationHandler := handler.NewEscalationHandler(escalationService)
+	myDayHandler := handler.NewMyDayHandler(myDayService)
+	todoLinkHandler := handler.NewTodoLinkHandler(todoLinkService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	consentHandler := handler.NewConsentHandler(consentService)
+	usageHandler := handler.NewUsageHandler(usageService)
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+	integrationHandler := handler.NewIntegrationHandler(integrationService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	syncHandler := handler.NewSyncHandler(syncService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
+
+	// Write requests, and the rows they write, are recorded with their request ID and client IP, on every route
+	e.Use(authMiddleware.AuditWrites(auditService))
+
+	// Auth routes (public)
+	auth := e.Group("/auth")
+	auth.POST("/sign_up", authHandler.SignUp)
+	auth.POST("/sign_in", authHandler.SignIn)
+	auth.DELETE("/sign_out", authHandler.SignOut, authMiddleware.JWTAuth(cfg, userRepo, denylistRepo))
+
+	// OAuth 2.0 routes for native apps (the token endpoint authenticates with the code or refresh token)
+	oauth := e.Group("/oauth")
+	oauth.GET("/authorize", oauthHandler.AuthorizePage)
+	oauth.POST("/authorize", oauthHandler.Authorize, authMiddleware.JWTAuth(cfg, userRepo, denylistRepo))
+	oauth.POST("/token", oauthHandler.Token)
+	oauth.POST("/revoke", oauthHandler.Revoke)
+
+	// Data export download (public, authorized by the emailed token)
+	e.GET("/exports/download", dataExportHandler.Download)
+
+	// Calendar OAuth callback (public, authorized by the state issued when connecting)
+	e.GET("/calendar/google/callback", calendarHandler.Callback)
+
+	// API v1 requests are authenticated by a JWT or, for integrations, an HMAC signature
+	apiAuth := authMiddleware.SignatureAuth(integrationService, userRepo, authMiddleware.JWTAuth(cfg, userRepo, denylistRepo))
+
+	// Policy consent routes (reachable before accepting the current policy versions; not by integrations)
+	consent := e.Group("/api/v1/users/me/consents",
+		apiAuth,
+		authMiddleware.RejectIntegrations("consents"),
+		authMiddleware.MeterAPIUsage(usageService),
+	)
+	consent.GET("", consentHandler.Show)
+	consent.POST("", consentHandler.Accept)
+
+	// API v1 routes (protected)
+	api := e.Group("/api/v1",
+		apiAuth,
+		authMiddleware.MeterAPIUsage(usageService),
+		authMiddleware.RequirePolicyConsent(consentService),
+	)
+
+	// Global search
+	api.GET("/search", searchHandler.Search)
+
+	// Todo routes
+	api.GET("/todos", todoHandler.List)
+	api.GET("/todos/search", todoHandler.Search)     // Must be before /todos/:id
+	api.GET("/todos/stats", todoHandler.Stats)       // Must be before /todos/:id
+	api.GET("/todos/facets", todoHandler.Facets)     // Must be before /todos/:id
+	api.GET("/todos/report.pdf", todoHandler.Report) // Must be before /todos/:id
+	api.POST("/todos", todoHandler.Create)
+	api.POST("/todos/suggestions", suggestionHandler.Suggest)
+	api.GET("/todos/:id", todoHandler.Show)
+	api.PATCH("/todos/:id", todoHandler.Update)
+	api.DELETE("/todos/:id", todoHandler.Delete)
+	api.POST("/todos/:id/snooze", todoHandler.Snooze)
+	api.PATCH("/todos/update_order", todoHandler.UpdateOrder)
+	api.PATCH("/todos/reorder", todoHandler.Reorder)
+
+	// Sync routes (offline clients push local changes and pull server changes)
+	api.GET("/sync/todos", syncHandler.Pull)
+	api.POST("/sync/todos", syncHandler.Push)
+
+	// My Day routes
+	api.GET("/my_day", myDayHandler.Show)
+	api.POST("/my_day", myDayHandler.Update)
+
+	// Category routes
+	api.GET("/categories", categoryHandler.List)
+	api.POST("/categories", categoryHandler.Create)
+	api.GET("/categories/:id", categoryHandler.Show)
+	api.PATCH("/categories/:id", categoryHandler.Update)
+	api.DELETE("/categories/:id", categoryHandler.Delete)
+
+	// Tag routes
+	api.GET("/tags", tagHandler.List)
+	api.POST("/tags", tagHandler.Create)
+	api.GET("/tags/:id", tagHandler.Show)
+	api.PATCH("/tags/:id", tagHandler.Update)
+	api.DELETE("/tags/:id", tagHandler.Delete)
+
+	// Comment routes (nested under todos)
+	api.GET("/todos/:todo_id/comments", commentHandler.List)
+	api.POST("/todos/:todo_id/comments", commentHandler.Create)
+	api.PATCH("/todos/:todo_id/comments/:id", commentHandler.Update)
+	api.DELETE("/todos/:todo_id/comments/:id", commentHandler.Delete)
+
+	// Link routes (nested under todos)
+	api.GET("/todos/:todo_id/links", todoLinkHandler.List)
+	api.POST("/todos/:todo_id/links", todoLinkHandler.Create)
+	api.PATCH("/todos/:todo_id/links/:id", todoLinkHandler.Update)
+	api.DELETE("/todos/:todo_id/links/:id", todoLinkHandler.Delete)
+
+	// History routes (nested under todos)
+	api.GET("/todos/:todo_id/histories", historyHandler.List)
+
+	// File routes (nested under todos)
+	api.GET("/todos/:todo_id/files", fileHandler.List)
+	api.POST("/todos/:todo_id/files", fileHandler.Upload)
+	api.GET("/todos/:todo_id/files/:file_id", fileHandler.Download)
+	api.GET("/todos/:todo_id/files/:file_id/thumb", fileHandler.DownloadThumb)
+	api.GET("/todos/:todo_id/files/:file_id/medium", fileHandler.DownloadMedium)
+	api.DELETE("/todos/:todo_id/files/:file_id", fileHandler.Delete)
+
+	// Note routes
+	api.GET("/notes", noteHandler.List)
+	api.POST("/notes", noteHandler.Create)
+	api.GET("/notes/:id", noteHandler.Show)
+	api.PATCH("/notes/:id", noteHandler.Update)
+	api.DELETE("/notes/:id", noteHandler.Delete)
+	api.GET("/notes/:id/revisions", noteHandler.ListRevisions)
+	api.POST("/notes/:id/revisions/:revision_id/restore", noteHandler.RestoreRevision)
+
+	// Focus session routes
+	api.GET("/focus_sessions", focusSessionHandler.List)
+	api.POST("/focus_sessions", focusSessionHandler.Start)
+	api.GET("/focus_sessions/active", focusSessionHandler.Active) // Must be before /focus_sessions/:id
+	api.GET("/focus_sessions/stats", focusSessionHandler.Stats)
+	api.POST("/focus_sessions/:id/stop", focusSessionHandler.Stop)
+
+	// Current user routes
+	api.GET("/users/me/preferences", preferenceHandler.Show)
+	api.PATCH("/users/me/preferences", preferenceHandler.Update)
+	api.GET("/users/me/streaks", streakHandler.Show)
+	api.GET("/users/me/retention/preview", retentionHandler.Preview)
+	api.POST("/users/me/export", dataExportHandler.Create, authMiddleware.RejectIntegrations("exports"))
+	api.GET("/users/me/exports", dataExportHandler.List, authMiddleware.RejectIntegrations("exports"))
+	api.GET("/users/me/escalation_rules", escalationHandler.List)
+	api.POST("/users/me/escalation_rules", escalationHandler.Create)
+	api.GET("/users/me/escalation_rules/preview", escalationHandler.Preview)
+	api.PATCH("/users/me/escalation_rules/:id", escalationHandler.Update)
+	api.DELETE("/users/me/escalation_rules/:id", escalationHandler.Delete)
+	api.GET("/users/me/usage/api", usageHandler.Show)
+
+	// Integration routes (server-to-server callers signing requests; managed by the user only)
+	integrations := api.Group("/users/me/integrations", authMiddleware.RejectIntegrations("integrations"))
+	integrations.GET("", integrationHandler.List)
+	integrations.POST("", integrationHandler.Create)
+	integrations.DELETE("/:id", integrationHandler.Delete)
+
+	// Calendar routes (due dates synced to a connected calendar; managed by the user only)
+	calendar := api.Group("/users/me/calendar", authMiddleware.RejectIntegrations("calendar"))
+	calendar.GET("", calendarHandler.Show)
+	calendar.PATCH("", calendarHandler.Update)
+	calendar.DELETE("", calendarHandler.Disconnect)
+	calendar.POST("/connect", calendarHandler.Connect)
+
+	// Admin routes (ADMIN_EMAILS only, not their integrations)
+	admin := api.Group("/admin", authMiddleware.RequireAdmin(cfg))
+	admin.GET("/usage/api", usageHandler.Rollup)
+	admin.PATCH("/users/:id/api_quota", usageHandler.UpdateQuota)
+	admin.GET("/audit_logs", auditHandler.List)
+	admin.GET("/audit_logs/verify", auditHandler.Verify)
+
+	// Background jobs
+	scheduler := job.NewScheduler()
+	scheduler.Register("digest", service.DigestJobInterval, digestService.Run)
+	scheduler.Register("data_export", service.DataExportJobInterval, dataExportService.Run)
+	scheduler.Register("retention", service.RetentionJobInterval, retentionService.Run)
+	scheduler.Register("priority_escalation", service.EscalationJobInterval, escalationService.Run)
+	scheduler.Register("integration_nonce_cleanup", service.IntegrationCleanupJobInterval, integrationService.Run)
+	scheduler.Register("sync_tombstone_cleanup", service.SyncCleanupJobInterval, syncService.Run)
+	scheduler.Register("audit_flush", service.AuditFlushJobInterval, auditService.Run)
+	if calendarService.Enabled() {
+		scheduler.Register("calendar_sync", service.CalendarSyncJobInterval, calendarService.Run)
+	}
+	if searchSyncService != nil {
+		scheduler.Register("search_sync", service.SearchSyncJobInterval, searchSyncService.Run)
+		scheduler.Register("search_flush", service.SearchFlushJobInterval, searchSyncService.Flush)
+	}
+	if fileScanService.Enabled() {
+		scheduler.Register("file_rescan", service.FileScanJobInterval, fileScanService.RescanPending)
+	}
+	if oauthService.Enabled() {
+		scheduler.Register("oauth_cleanup", service.OAuthCleanupJobInterval, oauthService.Run)
+	}
+	if encryption.Enabled() {
+		scheduler.Register("reencryption", service.ReencryptionJobInterval, reencryptionService.Run)
+	}
+
+	return scheduler, nil
+}
+
+// setupHealthDetails registers GET /health/details, readable from HEALTH_DETAILS_ALLOWED_NETWORKS and by admins.
+// In schema tenancy mode users, jobs and exports live in the tenant schemas, so only the networks are allowed
+// and the report covers the public schema.
+func setupHealthDetails(e *echo.Echo, cfg *config.Config, db *gorm.DB, scheduler *job.Scheduler) {
+	models := model.All()
+	var exportRepo *repository.DataExportRepository
+	var adminAuth echo.MiddlewareFunc
+	if cfg.IsSchemaTenancy() {
+		models = []any{&model.Tenant{}}
+	} else {
+		userRepo := repository.NewUserRepository(db)
+		jwtAuth := authMiddleware.JWTAuth(cfg, userRepo, repository.NewJwtDenylistRepository(db))
+		requireAdmin := authMiddleware.RequireAdmin(cfg)
+		adminAuth = func(next echo.HandlerFunc) echo.HandlerFunc {
+			return jwtAuth(requireAdmin(next))
+		}
+		exportRepo = repository.NewDataExportRepository(db)
+	}
+	if !cfg.SchedulerEnabled {
+		scheduler = nil
+	}
+
+	healthService := service.NewHealthService(
+		repository.NewHealthRepository(db),
+		exportRepo,
+		scheduler,
+		models,
+		service.BuildInfo{Version: version, Commit: commit},
+	)
+	healthHandler := handler.NewHealthHandler(healthService)
+	e.GET("/health/details", healthHandler.Details,
+		authMiddleware.RequireNetworkOrAdmin(cfg.GetHealthConfig().AllowedNetworks, adminAuth))
+}
@@ -13,19 +13,24 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 
 	"todo-api/internal/config"
-	"todo-api/internal/errors"
-	"todo-api/internal/handler"
+	"todo-api/internal/encryption"
+	"todo-api/internal/job"
 	authMiddleware "todo-api/internal/middleware"
 	"todo-api/internal/model"
 	"todo-api/internal/repository"
-	"todo-api/internal/service"
-	"todo-api/internal/storage"
-	"todo-api/internal/validator"
+	"todo-api/internal/tenant"
 	"todo-api/pkg/database"
 )
 
+// Build identification, set with -ldflags "-X main.version=... -X main.commit=..."
+var (
+	version = "dev"
+	commit  string
+)
+
 func main() {
 	// Configure zerolog
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
@@ -42,6 +47,16 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to load configuration")
 	}
 
+	// Enable field encryption before anything reads or writes encrypted columns
+	if encryptionCfg := cfg.GetEncryptionConfig(); encryptionCfg.Keys != "" {
+		ring, err := encryption.ParseKeyRing(encryptionCfg.Keys, encryptionCfg.ActiveKey)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to load encryption keys")
+		}
+		encryption.SetKeyRing(ring)
+		log.Info().Str("key_id", ring.ActiveKeyID()).Msg("Field encryption enabled")
+	}
+
 	// Connect to database
 	db, err := database.Connect(cfg.DatabaseURL)
 	if err != nil {
@@ -51,33 +66,50 @@ func main() {
 
 	// Auto migrate models (development only)
 	if cfg.IsDevelopment() {
-		if err := db.AutoMigrate(
-			&model.User{},
-			&model.JwtDenylist{},
-			&model.Category{},
-			&model.Tag{},
-			&model.Todo{},
-			&model.TodoTag{},
-			&model.Comment{},
-			&model.TodoHistory{},
-			&model.File{},
-			&model.Note{},
-			&model.NoteRevision{},
-		); err != nil {
-			log.Fatal().Err(err).Msg("Failed to auto migrate models")
+		if cfg.IsSchemaTenancy() {
+			// The public schema only lists the tenants; their schemas are migrated below
+			if err := db.AutoMigrate(&model.Tenant{}); err != nil {
+				log.Fatal().Err(err).Msg("Failed to auto migrate tenants")
+			}
+		} else {
+			if err := db.AutoMigrate(model.All()...); err != nil {
+				log.Fatal().Err(err).Msg("Failed to auto migrate models")
+			}
+			if err := repository.NewAPIUsageRepository(db).DropUserDateIndex(context.Background()); err != nil {
+				log.Fatal().Err(err).Msg("Failed to drop the former API usage index")
+			}
+			if err := database.EnableTrigramSearch(db, encryption.Enabled()); err != nil {
+				log.Fatal().Err(err).Msg("Failed to enable trigram search")
+			}
 		}
 		log.Info().Msg("Database models migrated")
 	}
 
-	// Initialize Echo
-	e := echo.New()
-	e.HideBanner = true
+	// Enforce row-level security as defense in depth against queries missing a user_id condition
+	// (in schema tenancy mode, on each tenant schema instead)
+	if cfg.DatabaseRLSEnabled && !cfg.IsSchemaTenancy() {
+		if err := database.EnableRowLevelSecurity(db); err != nil {
+			log.Fatal().Err(err).Msg("Failed to enable row-level security")
+		}
+		log.Info().Msg("Row-level security enabled")
+	}
 
-	// Set custom error handler
-	e.HTTPErrorHandler = errors.ErrorHandler
+	// Backfill after row-level security is enabled, as its policies hide every row from unscoped connections
+	if cfg.IsDevelopment() && !cfg.IsSchemaTenancy() {
+		todoRepo := repository.NewTodoRepository(db)
+		if err := todoRepo.BackfillCategoryPositions(context.Background()); err != nil {
+			log.Fatal().Err(err).Msg("Failed to backfill category positions")
+		}
+		if err := todoRepo.BackfillCompletedAt(context.Background()); err != nil {
+			log.Fatal().Err(err).Msg("Failed to backfill completion times")
+		}
+		if err := repository.NewStreakRepository(db).BackfillCompletions(context.Background()); err != nil {
+			log.Fatal().Err(err).Msg("Failed to backfill streak completions")
+		}
+	}
 
-	// Set custom validator
-	validator.SetupValidator(e)
+	// Initialize Echo
+	e := newEcho()
 
 	// Middleware
 	e.Use(middleware.RequestID())
@@ -89,7 +121,7 @@ func main() {
 		AllowOrigins:     cfg.GetCORSOrigins(),
 		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
 		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
-		ExposeHeaders:    []string{echo.HeaderAuthorization},
+		ExposeHeaders:    []string{echo.HeaderAuthorization, echo.HeaderRetryAfter, authMiddleware.PolicyAcceptanceRequiredHeader, authMiddleware.RateLimitLimitHeader, authMiddleware.RateLimitRemainingHeader, authMiddleware.RateLimitResetHeader},
 		AllowCredentials: true,
 		MaxAge:           cfg.CORSMaxAge,
 	}))
@@ -101,97 +133,51 @@ func main() {
 		})
 	})
 
-	// Initialize S3 storage
-	s3Storage, err := storage.NewS3Storage(cfg.GetS3Config())
+	// Initialize external services shared by all tenants
+	deps, err := newDependencies(cfg)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to initialize S3 storage")
+		log.Fatal().Err(err).Msg("Failed to initialize dependencies")
 	}
 
-	// Initialize repositories
-	userRepo := repository.NewUserRepository(db)
-	denylistRepo := repository.NewJwtDenylistRepository(db)
-	todoRepo := repository.NewTodoRepository(db)
-	categoryRepo := repository.NewCategoryRepository(db)
-	tagRepo := repository.NewTagRepository(db)
-	commentRepo := repository.NewCommentRepository(db)
-	historyRepo := repository.NewTodoHistoryRepository(db)
-	fileRepo := repository.NewFileRepository(db)
-	noteRepo := repository.NewNoteRepository(db)
-	noteRevisionRepo := repository.NewNoteRevisionRepository(db)
-
-	// Initialize services
-	todoService := service.NewTodoService(todoRepo, categoryRepo, historyRepo)
-	thumbnailService := service.NewThumbnailService(s3Storage)
-	fileService := service.NewFileService(fileRepo, todoRepo, s3Storage, thumbnailService)
-	noteService := service.NewNoteService(noteRepo, noteRevisionRepo)
-
-	// Initialize handlers
-	authHandler := handler.NewAuthHandler(userRepo, denylistRepo, cfg)
-	todoHandler := handler.NewTodoHandler(todoService, todoRepo)
-	categoryHandler := handler.NewCategoryHandler(categoryRepo)
-	tagHandler := handler.NewTagHandler(tagRepo)
-	commentHandler := handler.NewCommentHandler(commentRepo, todoRepo)
-	historyHandler := handler.NewTodoHistoryHandler(historyRepo, todoRepo)
-	fileHandler := handler.NewFileHandler(fileService)
-	noteHandler := handler.NewNoteHandler(noteService, noteRepo, noteRevisionRepo)
-
-	// Auth routes (public)
-	auth := e.Group("/auth")
-	auth.POST("/sign_up", authHandler.SignUp)
-	auth.POST("/sign_in", authHandler.SignIn)
-	auth.DELETE("/sign_out", authHandler.SignOut, authMiddleware.JWTAuth(cfg, userRepo, denylistRepo))
-
-	// API v1 routes (protected)
-	api := e.Group("/api/v1", authMiddleware.JWTAuth(cfg, userRepo, denylistRepo))
-
-	// Todo routes
-	api.GET("/todos", todoHandler.List)
-	api.GET("/todos/search", todoHandler.Search) // Must be before /todos/:id
-	api.POST("/todos", todoHandler.Create)
-	api.GET("/todos/:id", todoHandler.Show)
-	api.PATCH("/todos/:id", todoHandler.Update)
-	api.DELETE("/todos/:id", todoHandler.Delete)
-	api.PATCH("/todos/update_order", todoHandler.UpdateOrder)
-
-	// Category routes
-	api.GET("/categories", categoryHandler.List)
-	api.POST("/categories", categoryHandler.Create)
-	api.GET("/categories/:id", categoryHandler.Show)
-	api.PATCH("/categories/:id", categoryHandler.Update)
-	api.DELETE("/categories/:id", categoryHandler.Delete)
-
-	// Tag routes
-	api.GET("/tags", tagHandler.List)
-	api.POST("/tags", tagHandler.Create)
-	api.GET("/tags/:id", tagHandler.Show)
-	api.PATCH("/tags/:id", tagHandler.Update)
-	api.DELETE("/tags/:id", tagHandler.Delete)
-
-	// Comment routes (nested under todos)
-	api.GET("/todos/:todo_id/comments", commentHandler.List)
-	api.POST("/todos/:todo_id/comments", commentHandler.Create)
-	api.PATCH("/todos/:todo_id/comments/:id", commentHandler.Update)
-	api.DELETE("/todos/:todo_id/comments/:id", commentHandler.Delete)
-
-	// History routes (nested under todos)
-	api.GET("/todos/:todo_id/histories", historyHandler.List)
-
-	// File routes (nested under todos)
-	api.GET("/todos/:todo_id/files", fileHandler.List)
-	api.POST("/todos/:todo_id/files", fileHandler.Upload)
-	api.GET("/todos/:todo_id/files/:file_id", fileHandler.Download)
-	api.GET("/todos/:todo_id/files/:file_id/thumb", fileHandler.DownloadThumb)
-	api.GET("/todos/:todo_id/files/:file_id/medium", fileHandler.DownloadMedium)
-	api.DELETE("/todos/:todo_id/files/:file_id", fileHandler.Delete)
-
-	// Note routes
-	api.GET("/notes", noteHandler.List)
-	api.POST("/notes", noteHandler.Create)
-	api.GET("/notes/:id", noteHandler.Show)
-	api.PATCH("/notes/:id", noteHandler.Update)
-	api.DELETE("/notes/:id", noteHandler.Delete)
-	api.GET("/notes/:id/revisions", noteHandler.ListRevisions)
-	api.POST("/notes/:id/revisions/:revision_id/restore", noteHandler.RestoreRevision)
+	if !cfg.SchedulerEnabled {
+		log.Warn().Msg("Scheduler disabled: audit log entries are only linked into the chain when the log is listed or verified")
+	}
+
+	var scheduler *job.Scheduler
+	var tenants *tenant.Registry
+	if cfg.IsSchemaTenancy() {
+		// Each tenant gets an API of its own, connected to its schema
+		tenants = tenant.NewRegistry(cfg, db, func(tenantCfg *config.Config, tenantDB *gorm.DB) (http.Handler, *job.Scheduler, error) {
+			if cfg.DatabaseRLSEnabled {
+				if err := database.EnableRowLevelSecurity(tenantDB); err != nil {
+					return nil, nil, err
+				}
+			}
+			tenantAPI := newEcho()
+			tenantScheduler, err := setupAPI(tenantAPI, tenantCfg, tenantDB, deps)
+			return tenantAPI, tenantScheduler, err
+		})
+		if cfg.IsDevelopment() {
+			if err := tenants.MigrateAll(context.Background()); err != nil {
+				log.Fatal().Err(err).Msg("Failed to migrate tenant schemas")
+			}
+		}
+		if err := tenants.LoadAll(context.Background()); err != nil {
+			log.Fatal().Err(err).Msg("Failed to load tenants")
+		}
+		e.Any("/*", tenants.Handle, authMiddleware.ResolveTenant(cfg))
+	} else {
+		scheduler, err = setupAPI(e, cfg, db, deps)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to set up API")
+		}
+		if cfg.SchedulerEnabled {
+			scheduler.Start(context.Background())
+		}
+	}
+
+	// Detailed health report for operators
+	setupHealthDetails(e, cfg, db, scheduler)
 
 	// Log startup information
 	log.Info().
@@ -213,6 +199,14 @@ func main() {
 
 	log.Info().Msg("Shutting down server...")
 
+	// Stop background jobs before the server so in-flight runs can finish
+	if scheduler != nil {
+		scheduler.Stop()
+	}
+	if tenants != nil {
+		tenants.Close()
+	}
+
 	// Graceful shutdown with 10 second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/config"
+	"todo-api/internal/model"
+	"todo-api/internal/tenant"
+	"todo-api/pkg/database"
+)
+
+const usage = `Usage:
+  tenant create <slug> [name]  Create a tenant with its schema and tables
+  tenant migrate [slug]        Migrate the schema of a tenant, or of all tenants
+  tenant list                  List tenants`
+
+// Manages tenants in schema tenancy mode (TENANCY_MODE=schema)
+func main() {
+	// Configure zerolog
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Debug().Msg("No .env file found")
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load configuration")
+	}
+
+	// Connect to database
+	db, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer database.Close(db)
+
+	// The tenants are listed in the public schema
+	if err := db.AutoMigrate(&model.Tenant{}); err != nil {
+		log.Fatal().Err(err).Msg("Failed to migrate tenants")
+	}
+
+	registry := tenant.NewRegistry(cfg, db, nil)
+	args := os.Args[2:]
+
+	switch os.Args[1] {
+	case "create":
+		if len(args) < 1 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		name := args[0]
+		if len(args) > 1 {
+			name = args[1]
+		}
+		t, err := registry.Create(context.Background(), args[0], name)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to create tenant")
+		}
+		log.Info().Str("tenant", t.Slug).Str("schema", tenant.Schema(t.Slug)).Msg("Created tenant")
+	case "migrate":
+		if len(args) > 0 {
+			err = registry.Migrate(context.Background(), args[0])
+		} else {
+			err = registry.MigrateAll(context.Background())
+		}
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to migrate tenants")
+		}
+		log.Info().Msg("Tenant schemas migrated")
+	case "list":
+		tenants, err := registry.Tenants(context.Background())
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to list tenants")
+		}
+		for _, t := range tenants {
+			fmt.Printf("%s\t%s\t%s\n", t.Slug, tenant.Schema(t.Slug), t.Name)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,71 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	appconfig "todo-api/internal/config"
+)
+
+// ProviderGoogle is the only calendar provider
+const ProviderGoogle = "google"
+
+var (
+	// ErrNotFound is returned when an event or calendar no longer exists
+	ErrNotFound = errors.New("calendar: not found")
+	// ErrAuthorizationRevoked is returned when the user revoked access or the refresh token expired
+	ErrAuthorizationRevoked = errors.New("calendar: authorization revoked")
+)
+
+// Token is an OAuth token for a user's calendar
+type Token struct {
+	AccessToken string
+	// RefreshToken is empty when refreshing keeps the previous one
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// Event is an all-day calendar event for a todo's due date
+type Event struct {
+	ID          string
+	Summary     string
+	Description string
+	// Date is the event's day (YYYY-MM-DD)
+	Date string
+	// TodoUUID links the event to its todo
+	TodoUUID string
+	// Cancelled is true for events deleted in the calendar
+	Cancelled bool
+	Updated   time.Time
+}
+
+// Provider defines the interface for calendar backends
+type Provider interface {
+	// Name returns the provider identifier
+	Name() string
+	// AuthCodeURL returns the consent page URL the user is sent to
+	AuthCodeURL(state string) string
+	// Exchange trades an authorization code for a token
+	Exchange(ctx context.Context, code string) (*Token, error)
+	// Refresh gets a new access token
+	Refresh(ctx context.Context, refreshToken string) (*Token, error)
+	// CreateCalendar creates a calendar and returns its ID
+	CreateCalendar(ctx context.Context, accessToken, name string) (string, error)
+	// InsertEvent creates an event and returns it as stored
+	InsertEvent(ctx context.Context, accessToken, calendarID string, event *Event) (*Event, error)
+	// UpdateEvent replaces an event and returns it as stored
+	UpdateEvent(ctx context.Context, accessToken, calendarID string, event *Event) (*Event, error)
+	// DeleteEvent deletes an event
+	DeleteEvent(ctx context.Context, accessToken, calendarID, eventID string) error
+	// ListEvents returns the events updated after updatedMin (every event for the zero time), including deleted ones
+	ListEvents(ctx context.Context, accessToken, calendarID string, updatedMin time.Time) ([]Event, error)
+}
+
+// New returns the provider selected by configuration, or nil if calendar sync is disabled
+func New(cfg *appconfig.CalendarConfig) Provider {
+	if !cfg.Enabled {
+		return nil
+	}
+	return NewGoogleProvider(cfg)
+}
@@ -0,0 +1,312 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	appconfig "todo-api/internal/config"
+)
+
+// googleScope only grants access to calendars the app creates
+const googleScope = "https://www.googleapis.com/auth/calendar.app.created"
+
+// googleTodoUUIDProperty is the private extended property linking an event to its todo
+const googleTodoUUIDProperty = "todo_uuid"
+
+// GoogleProvider syncs with Google Calendar through its REST API
+type GoogleProvider struct {
+	client       *http.Client
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	authURL      string
+	tokenURL     string
+	apiURL       string
+}
+
+// NewGoogleProvider creates a new GoogleProvider
+func NewGoogleProvider(cfg *appconfig.CalendarConfig) *GoogleProvider {
+	return &GoogleProvider{
+		client:       &http.Client{Timeout: cfg.Timeout},
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		redirectURL:  cfg.RedirectURL,
+		authURL:      cfg.AuthURL,
+		tokenURL:     cfg.TokenURL,
+		apiURL:       strings.TrimRight(cfg.APIURL, "/"),
+	}
+}
+
+// Name returns the provider identifier
+func (p *GoogleProvider) Name() string {
+	return ProviderGoogle
+}
+
+// AuthCodeURL returns the consent page URL, asking for a refresh token
+func (p *GoogleProvider) AuthCodeURL(state string) string {
+	params := url.Values{
+		"client_id":     {p.clientID},
+		"redirect_uri":  {p.redirectURL},
+		"response_type": {"code"},
+		"scope":         {googleScope},
+		"access_type":   {"offline"},
+		"prompt":        {"consent"},
+		"state":         {state},
+	}
+	return p.authURL + "?" + params.Encode()
+}
+
+type googleTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+}
+
+// Exchange trades an authorization code for a token
+func (p *GoogleProvider) Exchange(ctx context.Context, code string) (*Token, error) {
+	return p.requestToken(ctx, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {p.redirectURL},
+	})
+}
+
+// Refresh gets a new access token
+func (p *GoogleProvider) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	return p.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+// requestToken calls the token endpoint with the client credentials
+func (p *GoogleProvider) requestToken(ctx context.Context, form url.Values) (*Token, error) {
+	form.Set("client_id", p.clientID)
+	form.Set("client_secret", p.clientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var token googleTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode token response (status %d): %w", resp.StatusCode, err)
+	}
+	if token.Error == "invalid_grant" {
+		return nil, ErrAuthorizationRevoked
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return nil, fmt.Errorf("unexpected token response status %d: %s", resp.StatusCode, token.Error)
+	}
+
+	return &Token{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// CreateCalendar creates a calendar and returns its ID
+func (p *GoogleProvider) CreateCalendar(ctx context.Context, accessToken, name string) (string, error) {
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := p.call(ctx, accessToken, http.MethodPost, "/calendars", map[string]string{"summary": name}, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+type googleEventDate struct {
+	Date string `json:"date"`
+}
+
+type googleEvent struct {
+	ID                 string           `json:"id,omitempty"`
+	Status             string           `json:"status,omitempty"`
+	Summary            string           `json:"summary"`
+	Description        string           `json:"description"`
+	Start              *googleEventDate `json:"start,omitempty"`
+	End                *googleEventDate `json:"end,omitempty"`
+	Updated            string           `json:"updated,omitempty"`
+	ExtendedProperties struct {
+		Private map[string]string `json:"private"`
+	} `json:"extendedProperties"`
+}
+
+// InsertEvent creates an event and returns it as stored
+func (p *GoogleProvider) InsertEvent(ctx context.Context, accessToken, calendarID string, event *Event) (*Event, error) {
+	body, err := toGoogleEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	var stored googleEvent
+	if err := p.call(ctx, accessToken, http.MethodPost, p.eventsPath(calendarID), body, &stored); err != nil {
+		return nil, err
+	}
+	return fromGoogleEvent(&stored), nil
+}
+
+// UpdateEvent replaces an event and returns it as stored
+func (p *GoogleProvider) UpdateEvent(ctx context.Context, accessToken, calendarID string, event *Event) (*Event, error) {
+	body, err := toGoogleEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	var stored googleEvent
+	path := p.eventsPath(calendarID) + "/" + url.PathEscape(event.ID)
+	if err := p.call(ctx, accessToken, http.MethodPut, path, body, &stored); err != nil {
+		return nil, err
+	}
+	return fromGoogleEvent(&stored), nil
+}
+
+// DeleteEvent deletes an event
+func (p *GoogleProvider) DeleteEvent(ctx context.Context, accessToken, calendarID, eventID string) error {
+	return p.call(ctx, accessToken, http.MethodDelete, p.eventsPath(calendarID)+"/"+url.PathEscape(eventID), nil, nil)
+}
+
+// ListEvents returns the events updated after updatedMin, following every page
+func (p *GoogleProvider) ListEvents(ctx context.Context, accessToken, calendarID string, updatedMin time.Time) ([]Event, error) {
+	params := url.Values{
+		"showDeleted": {"true"},
+		"maxResults":  {"2500"},
+	}
+	if !updatedMin.IsZero() {
+		params.Set("updatedMin", updatedMin.UTC().Format(time.RFC3339))
+	}
+
+	var events []Event
+	for {
+		var page struct {
+			Items         []googleEvent `json:"items"`
+			NextPageToken string        `json:"nextPageToken"`
+		}
+		if err := p.call(ctx, accessToken, http.MethodGet, p.eventsPath(calendarID)+"?"+params.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		for i := range page.Items {
+			events = append(events, *fromGoogleEvent(&page.Items[i]))
+		}
+		if page.NextPageToken == "" {
+			return events, nil
+		}
+		params.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// eventsPath returns the path of a calendar's events
+func (p *GoogleProvider) eventsPath(calendarID string) string {
+	return "/calendars/" + url.PathEscape(calendarID) + "/events"
+}
+
+// call sends a JSON request to the Calendar API and decodes the response into out, if given
+func (p *GoogleProvider) call(ctx context.Context, accessToken, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.apiURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrNotFound
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrAuthorizationRevoked
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, truncate(string(respBody), 200))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// toGoogleEvent converts an event to an all-day Google event ending the next day
+func toGoogleEvent(event *Event) (*googleEvent, error) {
+	date, err := time.Parse("2006-01-02", event.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event date %q: %w", event.Date, err)
+	}
+
+	ge := &googleEvent{
+		Summary:     event.Summary,
+		Description: event.Description,
+		Start:       &googleEventDate{Date: event.Date},
+		End:         &googleEventDate{Date: date.AddDate(0, 0, 1).Format("2006-01-02")},
+	}
+	ge.ExtendedProperties.Private = map[string]string{googleTodoUUIDProperty: event.TodoUUID}
+	return ge, nil
+}
+
+// fromGoogleEvent converts a Google event; timed events have no date
+func fromGoogleEvent(ge *googleEvent) *Event {
+	event := &Event{
+		ID:          ge.ID,
+		Summary:     ge.Summary,
+		Description: ge.Description,
+		TodoUUID:    ge.ExtendedProperties.Private[googleTodoUUIDProperty],
+		Cancelled:   ge.Status == "cancelled",
+	}
+	if ge.Start != nil {
+		event.Date = ge.Start.Date
+	}
+	if updated, err := time.Parse(time.RFC3339, ge.Updated); err == nil {
+		event.Updated = updated
+	}
+	return event
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
+	"net"
 	"strings"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
@@ -13,6 +16,8 @@ type Config struct {
 
 	// Database settings
 	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
+	// Enforce row-level security policies on user-owned tables (PostgreSQL only)
+	DatabaseRLSEnabled bool `envconfig:"DATABASE_RLS_ENABLED" default:"false"`
 
 	// JWT settings
 	JWTSecret          string `envconfig:"JWT_SECRET" required:"true"`
@@ -32,6 +37,91 @@ type Config struct {
 	S3AccessKey    string `envconfig:"S3_ACCESS_KEY" default:"rustfs-dev-access"`
 	S3SecretKey    string `envconfig:"S3_SECRET_KEY" default:"rustfs-dev-secret-key"`
 	S3UsePathStyle bool   `envconfig:"S3_USE_PATH_STYLE" default:"true"`
+
+	// Mail settings (emails are logged instead of sent when SMTP_HOST is empty)
+	SMTPHost     string `envconfig:"SMTP_HOST"`
+	SMTPPort     string `envconfig:"SMTP_PORT" default:"587"`
+	SMTPUsername string `envconfig:"SMTP_USERNAME"`
+	SMTPPassword string `envconfig:"SMTP_PASSWORD"`
+	MailFrom     string `envconfig:"MAIL_FROM" default:"no-reply@localhost"`
+
+	// Background job settings
+	SchedulerEnabled bool `envconfig:"SCHEDULER_ENABLED" default:"true"`
+
+	// AI suggestion settings (disabled unless AI_SUGGESTIONS_ENABLED is true)
+	AISuggestionsEnabled bool   `envconfig:"AI_SUGGESTIONS_ENABLED" default:"false"`
+	AIProvider           string `envconfig:"AI_PROVIDER" default:"heuristic"`
+	AIAPIKey             string `envconfig:"AI_API_KEY"`
+	AIBaseURL            string `envconfig:"AI_BASE_URL" default:"https://api.openai.com/v1"`
+	AIModel              string `envconfig:"AI_MODEL" default:"gpt-4o-mini"`
+	AITimeoutSeconds     int    `envconfig:"AI_TIMEOUT_SECONDS" default:"15"`
+
+	// Antivirus scanning of uploads (SCANNER_BACKEND: none, clamav, icap)
+	ScannerBackend        string `envconfig:"SCANNER_BACKEND" default:"none"`
+	ClamAVAddress         string `envconfig:"CLAMAV_ADDRESS" default:"localhost:3310"`
+	ICAPURL               string `envconfig:"ICAP_URL" default:"icap://localhost:1344/avscan"`
+	ScannerTimeoutSeconds int    `envconfig:"SCANNER_TIMEOUT_SECONDS" default:"60"`
+
+	// Account data export settings
+	PublicAPIURL           string `envconfig:"PUBLIC_API_URL" default:"http://localhost:3000"`
+	DataExportLinkTTLHours int    `envconfig:"DATA_EXPORT_LINK_TTL_HOURS" default:"24"`
+
+	// Search backend settings (SEARCH_BACKEND: sql, elasticsearch)
+	SearchBackend         string `envconfig:"SEARCH_BACKEND" default:"sql"`
+	ElasticsearchURL      string `envconfig:"ELASTICSEARCH_URL" default:"http://localhost:9200"`
+	ElasticsearchIndex    string `envconfig:"ELASTICSEARCH_INDEX" default:"todo-search"`
+	ElasticsearchUsername string `envconfig:"ELASTICSEARCH_USERNAME"`
+	ElasticsearchPassword string `envconfig:"ELASTICSEARCH_PASSWORD"`
+	SearchTimeoutSeconds  int    `envconfig:"SEARCH_TIMEOUT_SECONDS" default:"5"`
+
+	// Default minimum trigram word similarity (0-1] for fuzzy todo search
+	SearchFuzzyThreshold float64 `envconfig:"SEARCH_FUZZY_THRESHOLD" default:"0.3"`
+
+	// Field encryption of todo descriptions and comments (disabled unless ENCRYPTION_KEYS is set).
+	// ENCRYPTION_KEYS is "id:base64key,..." with 32-byte keys; ENCRYPTION_ACTIVE_KEY defaults to the last one.
+	EncryptionKeys      string `envconfig:"ENCRYPTION_KEYS"`
+	EncryptionActiveKey string `envconfig:"ENCRYPTION_ACTIVE_KEY"`
+
+	// Policy consent (a policy is not tracked while its version is empty).
+	// POLICY_CONSENT_ENFORCEMENT: flag marks API responses of users who have not accepted the current versions,
+	// block rejects their API requests until they accept.
+	TermsVersion             string `envconfig:"TERMS_VERSION"`
+	PrivacyPolicyVersion     string `envconfig:"PRIVACY_POLICY_VERSION"`
+	PolicyConsentEnforcement string `envconfig:"POLICY_CONSENT_ENFORCEMENT" default:"flag"`
+
+	// OAuth 2.0 authorization code flow with PKCE for first-party native apps (disabled while OAUTH_CLIENTS is empty).
+	// OAUTH_CLIENTS is "client_id=redirect_uri,..."; repeat a client to register several redirect URIs.
+	// OAUTH_LOGIN_URL is the frontend page that signs the user in and approves the authorization request.
+	OAuthClients             string `envconfig:"OAUTH_CLIENTS"`
+	OAuthLoginURL            string `envconfig:"OAUTH_LOGIN_URL" default:"http://localhost:3000/oauth/authorize"`
+	OAuthRefreshTokenTTLDays int    `envconfig:"OAUTH_REFRESH_TOKEN_TTL_DAYS" default:"30"`
+
+	// API usage metering. Requests to /api/v1 are counted per user and UTC day. API_DAILY_QUOTA is the default
+	// daily limit (0: unlimited), which admins can override per user; ADMIN_EMAILS lists the users allowed to use the admin API.
+	APIDailyQuota int    `envconfig:"API_DAILY_QUOTA" default:"0"`
+	AdminEmails   string `envconfig:"ADMIN_EMAILS"`
+
+	// Detailed health report at /health/details, served to admins and to clients connecting from
+	// HEALTH_DETAILS_ALLOWED_NETWORKS (comma-separated CIDRs; empty allows admins only)
+	HealthDetailsAllowedNetworks string `envconfig:"HEALTH_DETAILS_ALLOWED_NETWORKS" default:"127.0.0.0/8,::1/128"`
+
+	// Google Calendar sync of due dates (disabled while GOOGLE_CLIENT_ID is empty).
+	// GOOGLE_REDIRECT_URL is this API's /calendar/google/callback as registered with Google;
+	// users are sent back to GOOGLE_CALENDAR_RETURN_URL once connected.
+	GoogleClientID               string `envconfig:"GOOGLE_CLIENT_ID"`
+	GoogleClientSecret           string `envconfig:"GOOGLE_CLIENT_SECRET"`
+	GoogleRedirectURL            string `envconfig:"GOOGLE_REDIRECT_URL" default:"http://localhost:3000/calendar/google/callback"`
+	GoogleCalendarReturnURL      string `envconfig:"GOOGLE_CALENDAR_RETURN_URL" default:"http://localhost:3000/settings/calendar"`
+	GoogleCalendarTimeoutSeconds int    `envconfig:"GOOGLE_CALENDAR_TIMEOUT_SECONDS" default:"15"`
+
+	// Multi-tenancy (TENANCY_MODE: single, schema). In schema mode each tenant has its own PostgreSQL schema
+	// and is resolved from the subdomain of TENANT_BASE_DOMAIN or the tenant claim of the JWT.
+	TenancyMode        string `envconfig:"TENANCY_MODE" default:"single"`
+	TenantBaseDomain   string `envconfig:"TENANT_BASE_DOMAIN"`
+	TenantMaxOpenConns int    `envconfig:"TENANT_MAX_OPEN_CONNS" default:"10"`
+
+	// Tenant is the tenant a configuration returned by ForTenant belongs to (not read from the environment)
+	Tenant string `ignored:"true"`
 }
 
 // S3Config holds S3 storage configuration
@@ -56,6 +146,263 @@ func (c *Config) GetS3Config() *S3Config {
 	}
 }
 
+// MailConfig holds outgoing mail configuration
+type MailConfig struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
+// GetMailConfig returns mail configuration
+func (c *Config) GetMailConfig() *MailConfig {
+	return &MailConfig{
+		SMTPHost:     c.SMTPHost,
+		SMTPPort:     c.SMTPPort,
+		SMTPUsername: c.SMTPUsername,
+		SMTPPassword: c.SMTPPassword,
+		From:         c.MailFrom,
+	}
+}
+
+// AIConfig holds AI suggestion provider configuration
+type AIConfig struct {
+	Enabled  bool
+	Provider string
+	APIKey   string
+	BaseURL  string
+	Model    string
+	Timeout  time.Duration
+}
+
+// GetAIConfig returns AI suggestion configuration
+func (c *Config) GetAIConfig() *AIConfig {
+	return &AIConfig{
+		Enabled:  c.AISuggestionsEnabled,
+		Provider: c.AIProvider,
+		APIKey:   c.AIAPIKey,
+		BaseURL:  c.AIBaseURL,
+		Model:    c.AIModel,
+		Timeout:  time.Duration(c.AITimeoutSeconds) * time.Second,
+	}
+}
+
+// ScannerConfig holds antivirus scanner configuration
+type ScannerConfig struct {
+	Backend       string
+	ClamAVAddress string
+	ICAPURL       string
+	Timeout       time.Duration
+}
+
+// GetScannerConfig returns antivirus scanner configuration
+func (c *Config) GetScannerConfig() *ScannerConfig {
+	return &ScannerConfig{
+		Backend:       c.ScannerBackend,
+		ClamAVAddress: c.ClamAVAddress,
+		ICAPURL:       c.ICAPURL,
+		Timeout:       time.Duration(c.ScannerTimeoutSeconds) * time.Second,
+	}
+}
+
+// DataExportConfig holds account data export configuration
+type DataExportConfig struct {
+	PublicAPIURL string
+	LinkTTL      time.Duration
+	// Tenant is the tenant of the API in schema tenancy mode, carried in download tokens
+	Tenant string
+}
+
+// GetDataExportConfig returns account data export configuration
+func (c *Config) GetDataExportConfig() *DataExportConfig {
+	return &DataExportConfig{
+		PublicAPIURL: strings.TrimRight(c.PublicAPIURL, "/"),
+		LinkTTL:      time.Duration(c.DataExportLinkTTLHours) * time.Hour,
+		Tenant:       c.Tenant,
+	}
+}
+
+// SearchConfig holds search backend configuration
+type SearchConfig struct {
+	Backend               string
+	ElasticsearchURL      string
+	ElasticsearchIndex    string
+	ElasticsearchUsername string
+	ElasticsearchPassword string
+	Timeout               time.Duration
+	FuzzyThreshold        float64
+}
+
+// GetSearchConfig returns search backend configuration
+func (c *Config) GetSearchConfig() *SearchConfig {
+	return &SearchConfig{
+		Backend:               c.SearchBackend,
+		ElasticsearchURL:      strings.TrimRight(c.ElasticsearchURL, "/"),
+		ElasticsearchIndex:    c.ElasticsearchIndex,
+		ElasticsearchUsername: c.ElasticsearchUsername,
+		ElasticsearchPassword: c.ElasticsearchPassword,
+		Timeout:               time.Duration(c.SearchTimeoutSeconds) * time.Second,
+		FuzzyThreshold:        c.SearchFuzzyThreshold,
+	}
+}
+
+// EncryptionConfig holds field encryption configuration
+type EncryptionConfig struct {
+	Keys      string
+	ActiveKey string
+}
+
+// GetEncryptionConfig returns field encryption configuration
+func (c *Config) GetEncryptionConfig() *EncryptionConfig {
+	return &EncryptionConfig{
+		Keys:      c.EncryptionKeys,
+		ActiveKey: c.EncryptionActiveKey,
+	}
+}
+
+// PolicyConfig holds policy consent configuration
+type PolicyConfig struct {
+	TermsVersion   string
+	PrivacyVersion string
+	Enforcement    string
+}
+
+// GetPolicyConfig returns policy consent configuration
+func (c *Config) GetPolicyConfig() *PolicyConfig {
+	return &PolicyConfig{
+		TermsVersion:   c.TermsVersion,
+		PrivacyVersion: c.PrivacyPolicyVersion,
+		Enforcement:    c.PolicyConsentEnforcement,
+	}
+}
+
+// OAuthConfig holds OAuth 2.0 configuration
+type OAuthConfig struct {
+	// Clients maps each client ID to its registered redirect URIs
+	Clients         map[string][]string
+	LoginURL        string
+	RefreshTokenTTL time.Duration
+}
+
+// GetOAuthConfig returns OAuth 2.0 configuration
+func (c *Config) GetOAuthConfig() *OAuthConfig {
+	clients := map[string][]string{}
+	for _, entry := range splitAndTrim(c.OAuthClients, ",") {
+		clientID, redirectURI, ok := strings.Cut(entry, "=")
+		if ok && clientID != "" && redirectURI != "" {
+			clients[clientID] = append(clients[clientID], redirectURI)
+		}
+	}
+	return &OAuthConfig{
+		Clients:         clients,
+		LoginURL:        c.OAuthLoginURL,
+		RefreshTokenTTL: time.Duration(c.OAuthRefreshTokenTTLDays) * 24 * time.Hour,
+	}
+}
+
+// UsageConfig holds API usage metering configuration
+type UsageConfig struct {
+	DailyQuota int
+}
+
+// GetUsageConfig returns API usage metering configuration
+func (c *Config) GetUsageConfig() *UsageConfig {
+	return &UsageConfig{
+		DailyQuota: c.APIDailyQuota,
+	}
+}
+
+// IsAdmin returns true if the email belongs to an admin listed in ADMIN_EMAILS
+func (c *Config) IsAdmin(email string) bool {
+	for _, admin := range splitAndTrim(c.AdminEmails, ",") {
+		if strings.EqualFold(admin, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// HealthConfig holds configuration of the detailed health report
+type HealthConfig struct {
+	// AllowedNetworks are the networks allowed to read the report without signing in as an admin
+	AllowedNetworks []*net.IPNet
+}
+
+// GetHealthConfig returns configuration of the detailed health report
+func (c *Config) GetHealthConfig() *HealthConfig {
+	networks := []*net.IPNet{}
+	for _, cidr := range splitAndTrim(c.HealthDetailsAllowedNetworks, ",") {
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return &HealthConfig{AllowedNetworks: networks}
+}
+
+// CalendarConfig holds Google Calendar sync configuration
+type CalendarConfig struct {
+	Enabled      bool
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	ReturnURL    string
+	// AuthURL, TokenURL and APIURL are Google's endpoints
+	AuthURL  string
+	TokenURL string
+	APIURL   string
+	Timeout  time.Duration
+	// Tenant is the tenant of the API in schema tenancy mode, carried in OAuth states
+	Tenant string
+}
+
+// GetCalendarConfig returns Google Calendar sync configuration
+func (c *Config) GetCalendarConfig() *CalendarConfig {
+	return &CalendarConfig{
+		Enabled:      c.GoogleClientID != "",
+		ClientID:     c.GoogleClientID,
+		ClientSecret: c.GoogleClientSecret,
+		RedirectURL:  c.GoogleRedirectURL,
+		ReturnURL:    c.GoogleCalendarReturnURL,
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		APIURL:       "https://www.googleapis.com/calendar/v3",
+		Timeout:      time.Duration(c.GoogleCalendarTimeoutSeconds) * time.Second,
+		Tenant:       c.Tenant,
+	}
+}
+
+// TenancyConfig holds multi-tenancy configuration
+type TenancyConfig struct {
+	Mode         string
+	BaseDomain   string
+	MaxOpenConns int
+}
+
+// GetTenancyConfig returns multi-tenancy configuration
+func (c *Config) GetTenancyConfig() *TenancyConfig {
+	return &TenancyConfig{
+		Mode:         c.TenancyMode,
+		BaseDomain:   strings.ToLower(strings.Trim(c.TenantBaseDomain, ".")),
+		MaxOpenConns: c.TenantMaxOpenConns,
+	}
+}
+
+// IsSchemaTenancy returns true if each tenant has its own database schema
+func (c *Config) IsSchemaTenancy() bool {
+	return c.TenancyMode == "schema"
+}
+
+// ForTenant returns a copy of the configuration for a tenant in schema mode.
+// Its tokens carry the tenant claim, its export download tokens and calendar OAuth states are prefixed with
+// the tenant (see tenant.TokenWithSlug), and its search documents go to an index of its own.
+func (c *Config) ForTenant(tenant string) *Config {
+	cfg := *c
+	cfg.Tenant = tenant
+	cfg.ElasticsearchIndex = c.ElasticsearchIndex + "-" + tenant
+	return &cfg
+}
+
 // GetCORSOrigins returns the CORS allowed origins as a slice
 func (c *Config) GetCORSOrigins() []string {
 	if c.CORSAllowOrigins == "*" {
@@ -89,6 +436,28 @@ func Load() (*Config, error) {
 	if err := envconfig.Process("", &cfg); err != nil {
 		return nil, err
 	}
+	if cfg.TenancyMode != "single" && cfg.TenancyMode != "schema" {
+		return nil, fmt.Errorf("invalid TENANCY_MODE %q: must be single or schema", cfg.TenancyMode)
+	}
+	if cfg.PolicyConsentEnforcement != "flag" && cfg.PolicyConsentEnforcement != "block" {
+		return nil, fmt.Errorf("invalid POLICY_CONSENT_ENFORCEMENT %q: must be flag or block", cfg.PolicyConsentEnforcement)
+	}
+	for _, entry := range splitAndTrim(cfg.OAuthClients, ",") {
+		if clientID, redirectURI, ok := strings.Cut(entry, "="); !ok || clientID == "" || redirectURI == "" {
+			return nil, fmt.Errorf("invalid OAUTH_CLIENTS entry %q: must be client_id=redirect_uri", entry)
+		}
+	}
+	if cfg.APIDailyQuota < 0 {
+		return nil, fmt.Errorf("invalid API_DAILY_QUOTA %d: must not be negative", cfg.APIDailyQuota)
+	}
+	if cfg.GoogleClientID != "" && cfg.GoogleClientSecret == "" {
+		return nil, fmt.Errorf("GOOGLE_CLIENT_SECRET is required with GOOGLE_CLIENT_ID")
+	}
+	for _, cidr := range splitAndTrim(cfg.HealthDetailsAllowedNetworks, ",") {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("invalid HEALTH_DETAILS_ALLOWED_NETWORKS entry %q: must be a CIDR", cidr)
+		}
+	}
 	return &cfg, nil
 }
 
@@ -0,0 +1,180 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// prefix marks an encrypted value; the key ID and the sealed data follow, separated by colons.
+// Stored values without the prefix are plaintext written without a key ring.
+const prefix = "enc:"
+
+// plainPrefix escapes plaintext stored without a key ring that would otherwise read as encrypted
+// (or as escaped itself), e.g. a description starting with "enc:"
+const plainPrefix = "plain:"
+
+// Redacted replaces encrypted text where it would otherwise be copied in plaintext (e.g. history)
+const Redacted = "[encrypted]"
+
+// KeyRing holds the AES-256 keys by ID. New values are encrypted with the active key;
+// older keys are kept so values written before a rotation can still be decrypted.
+type KeyRing struct {
+	aeads    map[string]cipher.AEAD
+	activeID string
+}
+
+// ParseKeyRing parses keys in the form "id1:base64key1,id2:base64key2".
+// Each key must be 32 bytes. activeID defaults to the last listed key.
+func ParseKeyRing(spec, activeID string) (*KeyRing, error) {
+	ring := &KeyRing{aeads: map[string]cipher.AEAD{}}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || !validKeyID(id) {
+			return nil, fmt.Errorf("invalid encryption key entry: expected id:base64key with an alphanumeric id")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("invalid encryption key %q: must be 32 bytes, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		ring.aeads[id] = aead
+		ring.activeID = id
+	}
+
+	if len(ring.aeads) == 0 {
+		return nil, fmt.Errorf("no encryption keys given")
+	}
+	if activeID != "" {
+		if _, ok := ring.aeads[activeID]; !ok {
+			return nil, fmt.Errorf("active encryption key %q is not configured", activeID)
+		}
+		ring.activeID = activeID
+	}
+	return ring, nil
+}
+
+// validKeyID reports whether id consists of letters, digits, and hyphens only.
+// IDs are part of stored values and are matched with LIKE by the re-encryption job.
+func validKeyID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// ActiveKeyID returns the ID of the key new values are encrypted with
+func (k *KeyRing) ActiveKeyID() string {
+	return k.activeID
+}
+
+// ActivePrefix returns the prefix of values encrypted with the active key
+func (k *KeyRing) ActivePrefix() string {
+	return prefix + k.activeID + ":"
+}
+
+// Encrypt seals plaintext with the active key
+func (k *KeyRing) Encrypt(plaintext string) (string, error) {
+	aead := k.aeads[k.activeID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return k.ActivePrefix() + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed by Encrypt with any known key. Plaintext values are returned unescaped.
+func (k *KeyRing) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return UnescapePlaintext(value), nil
+	}
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("unknown encryption key %q", id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value with key %q: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether a stored value is encrypted
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// EscapePlaintext returns how plaintext is stored without a key ring: as is, unless it starts with
+// the prefix of an encrypted or escaped value, in which case it is prefixed with plainPrefix
+func EscapePlaintext(plaintext string) string {
+	if strings.HasPrefix(plaintext, prefix) || strings.HasPrefix(plaintext, plainPrefix) {
+		return plainPrefix + plaintext
+	}
+	return plaintext
+}
+
+// UnescapePlaintext returns the plaintext of a value stored by EscapePlaintext
+func UnescapePlaintext(value string) string {
+	return strings.TrimPrefix(value, plainPrefix)
+}
+
+var (
+	mu      sync.RWMutex
+	current *KeyRing
+)
+
+// SetKeyRing sets the key ring used for encrypted columns; nil disables encryption of new values.
+// GORM serializers are global, so the key ring is as well.
+func SetKeyRing(ring *KeyRing) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = ring
+}
+
+// CurrentKeyRing returns the key ring set by SetKeyRing, or nil when encryption is disabled
+func CurrentKeyRing() *KeyRing {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Enabled reports whether encrypted columns are encrypted on write
+func Enabled() bool {
+	return CurrentKeyRing() != nil
+}
@@ -0,0 +1,82 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName is the name to use in struct tags: `gorm:"serializer:encrypted"`
+const SerializerName = "encrypted"
+
+// Serializer encrypts string and *string columns with the current key ring.
+// Without a key ring values are written in plaintext (see EscapePlaintext), and encrypted values cannot be read.
+type Serializer struct{}
+
+// Scan decrypts the stored value into the field
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	fieldValue := reflect.New(field.FieldType)
+
+	if dbValue != nil {
+		var stored string
+		switch v := dbValue.(type) {
+		case []byte:
+			stored = string(v)
+		case string:
+			stored = v
+		default:
+			return fmt.Errorf("cannot decrypt %T into %s", dbValue, field.Name)
+		}
+
+		plaintext := UnescapePlaintext(stored)
+		if IsEncrypted(stored) {
+			ring := CurrentKeyRing()
+			if ring == nil {
+				return fmt.Errorf("%s is encrypted but no encryption keys are configured", field.Name)
+			}
+			var err error
+			if plaintext, err = ring.Decrypt(stored); err != nil {
+				return err
+			}
+		}
+
+		if field.FieldType.Kind() == reflect.Ptr {
+			ptr := reflect.New(field.FieldType.Elem())
+			ptr.Elem().SetString(plaintext)
+			fieldValue.Elem().Set(ptr)
+		} else {
+			fieldValue.Elem().SetString(plaintext)
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+// Value encrypts the field for storage
+func (Serializer) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue any) (any, error) {
+	var plaintext string
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = v
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		plaintext = *v
+	default:
+		return nil, fmt.Errorf("cannot encrypt %T of %s", fieldValue, field.Name)
+	}
+
+	ring := CurrentKeyRing()
+	if ring == nil {
+		return EscapePlaintext(plaintext), nil
+	}
+	return ring.Encrypt(plaintext)
+}
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
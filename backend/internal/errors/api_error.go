@@ -99,11 +99,64 @@ func EditTimeExpired() *ApiError {
 	return NewApiError("EDIT_TIME_EXPIRED", "Edit time limit has expired", http.StatusForbidden, nil)
 }
 
+func FocusSessionAlreadyActive(sessionID int64) *ApiError {
+	return NewApiError("FOCUS_SESSION_ACTIVE", "A focus session is already in progress", http.StatusConflict, map[string]int64{
+		"active_session_id": sessionID,
+	})
+}
+
+func WIPLimitExceeded(limit int, inProgress int64) *ApiError {
+	return NewApiError("WIP_LIMIT_EXCEEDED", "The limit of in-progress todos has been reached", http.StatusConflict, map[string]int64{
+		"limit":       int64(limit),
+		"in_progress": inProgress,
+	})
+}
+
+func FileInfected(fileID int64) *ApiError {
+	return NewApiError("FILE_INFECTED", "File was flagged as infected and quarantined", http.StatusForbidden, map[string]int64{
+		"file_id": fileID,
+	})
+}
+
+func FileNotScanned(fileID int64) *ApiError {
+	return NewApiError("FILE_NOT_SCANNED", "File has not passed the virus scan yet", http.StatusConflict, map[string]int64{
+		"file_id": fileID,
+	})
+}
+
+func FeatureDisabled(feature string) *ApiError {
+	return NewApiError("FEATURE_DISABLED", "This feature is not enabled", http.StatusNotFound, map[string]string{
+		"feature": feature,
+	})
+}
+
+func PolicyAcceptanceRequired(policies []string) *ApiError {
+	return NewApiError("POLICY_ACCEPTANCE_REQUIRED", "The current policy versions must be accepted", http.StatusForbidden, map[string][]string{
+		"policies": policies,
+	})
+}
+
+func PayloadTooLarge(limit int64) *ApiError {
+	return NewApiError("PAYLOAD_TOO_LARGE", "Request body is too large", http.StatusRequestEntityTooLarge, map[string]int64{
+		"limit": limit,
+	})
+}
+
+func SyncTokenExpired() *ApiError {
+	return NewApiError("SYNC_TOKEN_EXPIRED", "Sync token has expired; sync again without a token", http.StatusGone, nil)
+}
+
 // System errors
 func InternalError() *ApiError {
 	return NewApiError("INTERNAL_ERROR", "An unexpected error occurred", http.StatusInternalServerError, nil)
 }
 
+func ExternalServiceError(service string) *ApiError {
+	return NewApiError("EXTERNAL_SERVICE_ERROR", "An external service is unavailable", http.StatusBadGateway, map[string]string{
+		"service": service,
+	})
+}
+
 func RateLimitExceeded() *ApiError {
 	return NewApiError("RATE_LIMIT_EXCEEDED", "Too many requests", http.StatusTooManyRequests, nil)
 }
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/constants"
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// AuditHandler handles the admin audit log endpoints
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// AuditLogResponse represents an audit log entry in API responses
+type AuditLogResponse struct {
+	ID           int64   `json:"id"`
+	ActorID      *int64  `json:"actor_id"`
+	ActorEmail   string  `json:"actor_email"`
+	Action       string  `json:"action"`
+	ResourceType string  `json:"resource_type"`
+	ResourceID   *string `json:"resource_id"`
+	Method       string  `json:"method"`
+	Path         string  `json:"path"`
+	StatusCode   int     `json:"status_code"`
+	RequestID    string  `json:"request_id"`
+	IPAddress    string  `json:"ip_address"`
+	CreatedAt    string  `json:"created_at"`
+	PrevHash     string  `json:"prev_hash"`
+	Hash         string  `json:"hash"`
+}
+
+// AuditLogListResponse represents a page of audit log entries
+type AuditLogListResponse struct {
+	Data []AuditLogResponse `json:"data"`
+	Meta AuditLogMeta       `json:"meta"`
+}
+
+// AuditLogMeta represents pagination metadata
+type AuditLogMeta struct {
+	Total       int64 `json:"total"`
+	CurrentPage int   `json:"current_page"`
+	TotalPages  int   `json:"total_pages"`
+	PerPage     int   `json:"per_page"`
+}
+
+// AuditVerificationResponse represents the result of verifying the audit log hash chain
+type AuditVerificationResponse struct {
+	Valid      bool   `json:"valid"`
+	Checked    int64  `json:"checked"`
+	BrokenAtID *int64 `json:"broken_at_id"`
+}
+
+// List searches the audit log, newest first
+// GET /api/v1/admin/audit_logs?actor_id=&resource_type=&resource_id=&action=&from=&to=&page=&per_page=
+func (h *AuditHandler) List(c echo.Context) error {
+	input := repository.AuditLogSearchInput{
+		ResourceType: c.QueryParam("resource_type"),
+		ResourceID:   c.QueryParam("resource_id"),
+		Action:       c.QueryParam("action"),
+		Page:         1,
+		PerPage:      50,
+	}
+
+	if actorIDStr := c.QueryParam("actor_id"); actorIDStr != "" {
+		actorID, err := strconv.ParseInt(actorIDStr, 10, 64)
+		if err != nil {
+			return errors.ValidationFailed(map[string][]string{
+				"actor_id": {"must be an integer"},
+			})
+		}
+		input.ActorID = &actorID
+	}
+	if fromStr := c.QueryParam("from"); fromStr != "" {
+		from, err := time.ParseInLocation(constants.DateFormat, fromStr, time.UTC)
+		if err != nil {
+			return errors.ValidationFailed(map[string][]string{
+				"from": {"Invalid date format. Use YYYY-MM-DD"},
+			})
+		}
+		input.From = &from
+	}
+	if toStr := c.QueryParam("to"); toStr != "" {
+		to, err := time.ParseInLocation(constants.DateFormat, toStr, time.UTC)
+		if err != nil {
+			return errors.ValidationFailed(map[string][]string{
+				"to": {"Invalid date format. Use YYYY-MM-DD"},
+			})
+		}
+		// Include the whole day
+		to = to.AddDate(0, 0, 1)
+		input.To = &to
+	}
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			input.Page = p
+		}
+	}
+	if perPageStr := c.QueryParam("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 200 {
+			input.PerPage = pp
+		}
+	}
+
+	entries, total, err := h.auditService.Search(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	data := make([]AuditLogResponse, len(entries))
+	for i := range entries {
+		data[i] = toAuditLogResponse(&entries[i])
+	}
+
+	totalPages := int(total) / input.PerPage
+	if int(total)%input.PerPage > 0 {
+		totalPages++
+	}
+
+	return c.JSON(http.StatusOK, AuditLogListResponse{
+		Data: data,
+		Meta: AuditLogMeta{
+			Total:       total,
+			CurrentPage: input.Page,
+			TotalPages:  totalPages,
+			PerPage:     input.PerPage,
+		},
+	})
+}
+
+// Verify checks the hash chain of the whole audit log
+// GET /api/v1/admin/audit_logs/verify
+func (h *AuditHandler) Verify(c echo.Context) error {
+	result, err := h.auditService.Verify(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	return response.OK(c, AuditVerificationResponse{
+		Valid:      result.Valid,
+		Checked:    result.Checked,
+		BrokenAtID: result.BrokenAtID,
+	})
+}
+
+// toAuditLogResponse converts an audit log entry to its response format
+func toAuditLogResponse(entry *model.AuditLog) AuditLogResponse {
+	resp := AuditLogResponse{
+		ID:           entry.ID,
+		ActorID:      entry.ActorID,
+		ActorEmail:   entry.ActorEmail,
+		Action:       string(entry.Action),
+		ResourceType: entry.ResourceType,
+		Method:       entry.Method,
+		Path:         entry.Path,
+		StatusCode:   entry.StatusCode,
+		RequestID:    entry.RequestID,
+		IPAddress:    entry.IPAddress,
+		CreatedAt:    util.FormatRFC3339(entry.CreatedAt),
+		PrevHash:     entry.PrevHash,
+		Hash:         entry.Hash,
+	}
+	if entry.ResourceID != "" {
+		resp.ResourceID = &entry.ResourceID
+	}
+	return resp
+}
@@ -0,0 +1,143 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/handler"
+	"todo-api/internal/middleware"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+// callRoute calls an authenticated handler through AuditWrites as if matched by the given route
+func callRoute(f *testutil.TestFixture, auditService *service.AuditService, token, method, route, body string, params map[string]string, h echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+	path := route
+	names := make([]string, 0, len(params))
+	values := make([]string, 0, len(params))
+	for name, value := range params {
+		path = strings.Replace(path, ":"+name, value, 1)
+		names = append(names, name)
+		values = append(values, value)
+	}
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("Authorization", token)
+	req.Header.Set(echo.HeaderXRequestID, "req-"+method)
+	rec := httptest.NewRecorder()
+	c := f.Echo.NewContext(req, rec)
+	c.SetPath(route)
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+
+	auth := middleware.JWTAuth(testutil.TestConfig, f.UserRepo, f.DenylistRepo)
+	return rec, auth(middleware.AuditWrites(auditService)(h))(c)
+}
+
+// auditEntries lists the audit log entries matching a query, newest first; listing flushes the pending entries
+func auditEntries(t *testing.T, f *testutil.TestFixture, auditHandler *handler.AuditHandler, token, query string) []map[string]any {
+	t.Helper()
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/admin/audit_logs?"+query, "", auditHandler.List)
+	require.NoError(t, err)
+	var entries []map[string]any
+	for _, entry := range testutil.JSONResponse(t, rec)["data"].([]any) {
+		entries = append(entries, entry.(map[string]any))
+	}
+	return entries
+}
+
+// TestAuditWrites tests recording writes in the hash-chained audit log and detecting tampering
+func TestAuditWrites(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(f.DB))
+	require.NoError(t, auditService.RegisterCallbacks(f.DB))
+	auditHandler := handler.NewAuditHandler(auditService)
+
+	// Writes outside /api/v1 are recorded too, without an actor before authentication
+	user, token := f.CreateUser("audited@example.com")
+	signUps := auditEntries(t, f, auditHandler, token, "resource_type=users&action=create")
+	require.Len(t, signUps, 1)
+	assert.Equal(t, strconv.FormatInt(user.ID, 10), signUps[0]["resource_id"])
+	assert.Nil(t, signUps[0]["actor_id"])
+
+	// Successful write requests are recorded along with the rows they write, once flushed
+	rec, err := callRoute(f, auditService, token, http.MethodPost, "/api/v1/todos", `{"title":"Audited"}`, nil, f.TodoHandler.Create)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	todoID := strconv.FormatInt(int64(testutil.JSONResponse(t, rec)["id"].(float64)), 10)
+	var chained int64
+	require.NoError(t, f.DB.Model(&model.AuditLog{}).Where("resource_type = ?", "todos").Count(&chained).Error)
+	assert.Zero(t, chained)
+
+	_, err = callRoute(f, auditService, token, http.MethodPatch, "/api/v1/todos/:id", `{"title":"Renamed"}`,
+		map[string]string{"id": todoID}, f.TodoHandler.Update)
+	require.NoError(t, err)
+	_, err = callRoute(f, auditService, token, http.MethodDelete, "/api/v1/todos/:id", "", map[string]string{"id": todoID}, f.TodoHandler.Delete)
+	require.NoError(t, err)
+
+	// Reads and failed writes are not
+	_, err = callRoute(f, auditService, token, http.MethodGet, "/api/v1/todos", "", nil, f.TodoHandler.List)
+	require.NoError(t, err)
+	_, err = callRoute(f, auditService, token, http.MethodPost, "/api/v1/todos", `{"title":""}`, nil, f.TodoHandler.Create)
+	require.Error(t, err)
+
+	created := auditEntries(t, f, auditHandler, token, "resource_type=todos&action=create")
+	require.Len(t, created, 2)
+	assert.Equal(t, "req-POST", created[0]["request_id"])
+	assert.Nil(t, created[0]["resource_id"])
+	assert.Equal(t, todoID, created[1]["resource_id"])
+	assert.Equal(t, float64(user.ID), created[1]["actor_id"])
+
+	deleted := auditEntries(t, f, auditHandler, token, "resource_type=todos&action=delete")
+	require.Len(t, deleted, 2)
+	request, written := deleted[0], deleted[1]
+	assert.Equal(t, todoID, request["resource_id"])
+	assert.Equal(t, float64(user.ID), request["actor_id"])
+	assert.Equal(t, "audited@example.com", request["actor_email"])
+	assert.Equal(t, "req-DELETE", request["request_id"])
+	assert.Equal(t, "192.0.2.1", request["ip_address"])
+	assert.Equal(t, todoID, written["resource_id"])
+	assert.Equal(t, float64(user.ID), written["actor_id"])
+	assert.Equal(t, "", written["method"])
+	assert.Equal(t, "req-DELETE", written["request_id"])
+	assert.Equal(t, "192.0.2.1", written["ip_address"])
+
+	// The history rows written by the service are recorded as well
+	assert.NotEmpty(t, auditEntries(t, f, auditHandler, token, "resource_type=todo_histories"))
+
+	// The client cannot choose the recorded IP
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/todos", strings.NewReader(`{"title":"Forwarded"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("Authorization", token)
+	req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+	c := f.Echo.NewContext(req, httptest.NewRecorder())
+	c.SetPath("/api/v1/todos")
+	auth := middleware.JWTAuth(testutil.TestConfig, f.UserRepo, f.DenylistRepo)
+	require.NoError(t, auth(middleware.AuditWrites(auditService)(f.TodoHandler.Create))(c))
+	created = auditEntries(t, f, auditHandler, token, "resource_type=todos&action=create")
+	assert.Equal(t, "192.0.2.1", created[0]["ip_address"])
+
+	// The chain verifies until an entry is altered
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/admin/audit_logs/verify", "", auditHandler.Verify)
+	require.NoError(t, err)
+	resp := testutil.JSONResponse(t, rec)
+	assert.Equal(t, true, resp["valid"])
+	assert.Greater(t, resp["checked"], float64(8))
+
+	require.NoError(t, f.DB.Model(&model.AuditLog{}).Where("id = ?", request["id"]).Update("ip_address", "10.0.0.1").Error)
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/admin/audit_logs/verify", "", auditHandler.Verify)
+	require.NoError(t, err)
+	resp = testutil.JSONResponse(t, rec)
+	assert.Equal(t, false, resp["valid"])
+	assert.Equal(t, request["id"], resp["broken_at_id"])
+}
@@ -15,17 +15,20 @@ import (
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	authService *service.AuthService
+	authService    *service.AuthService
+	consentService *service.ConsentService
 }
 
 // NewAuthHandler creates a new AuthHandler
 func NewAuthHandler(
 	userRepo *repository.UserRepository,
 	denylistRepo *repository.JwtDenylistRepository,
+	consentRepo *repository.PolicyConsentRepository,
 	cfg *config.Config,
 ) *AuthHandler {
 	return &AuthHandler{
-		authService: service.NewAuthService(userRepo, denylistRepo, cfg),
+		authService:    service.NewAuthService(userRepo, denylistRepo, cfg),
+		consentService: service.NewConsentService(consentRepo, cfg.GetPolicyConfig()),
 	}
 }
 
@@ -36,6 +39,9 @@ type SignUpRequest struct {
 		Password             string `json:"password" validate:"required,min=6"`
 		PasswordConfirmation string `json:"password_confirmation" validate:"required"`
 		Name                 string `json:"name" validate:"required,min=2,max=50"`
+		// Policy versions shown to the user; required while the policy is tracked
+		TermsVersion   string `json:"terms_version" validate:"max=100"`
+		PrivacyVersion string `json:"privacy_version" validate:"max=100"`
 	} `json:"user" validate:"required"`
 }
 
@@ -75,8 +81,15 @@ func (h *AuthHandler) SignUp(c echo.Context) error {
 		return err
 	}
 
+	// The current policy versions must be accepted before the account is created
+	consent := consentInput(c, req.User.TermsVersion, req.User.PrivacyVersion)
+	if err := h.consentService.ValidateSignUp(consent); err != nil {
+		return err
+	}
+
 	// Register user
 	user, token, err := h.authService.SignUp(
+		c.Request().Context(),
 		req.User.Email,
 		req.User.Password,
 		req.User.PasswordConfirmation,
@@ -86,6 +99,9 @@ func (h *AuthHandler) SignUp(c echo.Context) error {
 		return err
 	}
 
+	// Record the accepted policy versions
+	h.consentService.RecordSignUp(c.Request().Context(), user.ID, consent)
+
 	// Set Authorization header
 	c.Response().Header().Set("Authorization", "Bearer "+token)
 
@@ -114,7 +130,7 @@ func (h *AuthHandler) SignIn(c echo.Context) error {
 	}
 
 	// Authenticate user
-	user, token, err := h.authService.SignIn(req.User.Email, req.User.Password)
+	user, token, err := h.authService.SignIn(c.Request().Context(), req.User.Email, req.User.Password)
 	if err != nil {
 		return err
 	}
@@ -147,7 +163,7 @@ func (h *AuthHandler) SignOut(c echo.Context) error {
 	}
 
 	// Add token to denylist
-	if err := h.authService.SignOut(claims.Jti, claims.ExpiresAt.Time); err != nil {
+	if err := h.authService.SignOut(c.Request().Context(), claims.Jti, claims.ExpiresAt.Time); err != nil {
 		return errors.InternalErrorWithLog(err, "AuthHandler.SignOut: failed to revoke token")
 	}
 
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// CalendarHandler handles endpoints connecting a calendar the todos' due dates are synced to
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+}
+
+// NewCalendarHandler creates a new CalendarHandler
+func NewCalendarHandler(calendarService *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+// UpdateCalendarRequest represents the request body for updating calendar sync settings
+type UpdateCalendarRequest struct {
+	PullEnabled *bool `json:"pull_enabled" validate:"required"`
+}
+
+// CalendarCallbackRequest represents the parameters the provider redirects back with
+type CalendarCallbackRequest struct {
+	State string `query:"state"`
+	Code  string `query:"code"`
+	Error string `query:"error"`
+}
+
+// CalendarResponse represents the calendar connection in API responses
+type CalendarResponse struct {
+	Connected    bool    `json:"connected"`
+	Provider     *string `json:"provider"`
+	PullEnabled  bool    `json:"pull_enabled"`
+	ConnectedAt  *string `json:"connected_at"`
+	LastSyncedAt *string `json:"last_synced_at"`
+	LastError    *string `json:"last_error"`
+}
+
+// ConnectCalendarResponse represents the consent page the user is sent to
+type ConnectCalendarResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// toCalendarResponse converts a model.CalendarConnection to CalendarResponse; nil is not connected
+func toCalendarResponse(connection *model.CalendarConnection) CalendarResponse {
+	if connection == nil || !connection.Connected() {
+		return CalendarResponse{}
+	}
+
+	resp := CalendarResponse{
+		Connected:   true,
+		Provider:    &connection.Provider,
+		PullEnabled: connection.PullEnabled,
+	}
+	if connection.ConnectedAt != nil {
+		connectedAt := util.FormatRFC3339(*connection.ConnectedAt)
+		resp.ConnectedAt = &connectedAt
+	}
+	if connection.LastPushedAt != nil {
+		lastSyncedAt := util.FormatRFC3339(*connection.LastPushedAt)
+		resp.LastSyncedAt = &lastSyncedAt
+	}
+	if connection.LastError != "" {
+		resp.LastError = &connection.LastError
+	}
+	return resp
+}
+
+// Show retrieves the current user's calendar connection
+// GET /api/v1/users/me/calendar
+func (h *CalendarHandler) Show(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	connection, err := h.calendarService.Status(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, toCalendarResponse(connection))
+}
+
+// Connect starts connecting a calendar and returns the provider's consent page URL
+// POST /api/v1/users/me/calendar/connect
+func (h *CalendarHandler) Connect(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	authorizationURL, err := h.calendarService.Connect(c.Request().Context(), currentUser.ID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, ConnectCalendarResponse{AuthorizationURL: authorizationURL})
+}
+
+// Update changes the calendar sync settings
+// PATCH /api/v1/users/me/calendar
+func (h *CalendarHandler) Update(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateCalendarRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	connection, err := h.calendarService.UpdateSettings(c.Request().Context(), currentUser.ID, *req.PullEnabled, time.Now())
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("Calendar", "me")
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, toCalendarResponse(connection))
+}
+
+// Disconnect stops syncing to the calendar
+// DELETE /api/v1/users/me/calendar
+func (h *CalendarHandler) Disconnect(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.calendarService.Disconnect(c.Request().Context(), currentUser.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("Calendar", "me")
+		}
+		if _, ok := err.(*errors.ApiError); ok {
+			return err
+		}
+		return errors.InternalErrorWithLog(err, "CalendarHandler.Disconnect: failed to delete connection")
+	}
+
+	return response.NoContent(c)
+}
+
+// Callback finishes connecting and sends the user back to the frontend with the outcome
+// GET /calendar/google/callback?state=&code=
+func (h *CalendarHandler) Callback(c echo.Context) error {
+	if !h.calendarService.Enabled() {
+		return errors.FeatureDisabled("google_calendar")
+	}
+
+	var req CalendarCallbackRequest
+	if err := c.Bind(&req); err != nil {
+		return errors.ValidationFailed(map[string][]string{"request": {"Invalid request parameters"}})
+	}
+
+	result := "connected"
+	if req.Error != "" || req.State == "" || req.Code == "" {
+		result = "error"
+	} else if err := h.calendarService.Callback(c.Request().Context(), req.State, req.Code, time.Now()); err != nil {
+		log.Warn().Err(err).Msg("CalendarHandler.Callback: failed to connect calendar")
+		result = "error"
+	}
+
+	return c.Redirect(http.StatusFound, returnURLWithResult(h.calendarService.ReturnURL(), result))
+}
+
+// returnURLWithResult adds the outcome of connecting as the calendar query parameter
+func returnURLWithResult(returnURL, result string) string {
+	u, err := url.Parse(returnURL)
+	if err != nil {
+		return returnURL
+	}
+	query := u.Query()
+	query.Set("calendar", result)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,315 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/calendar"
+	"todo-api/internal/config"
+	"todo-api/internal/handler"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+const calendarPath = "/api/v1/users/me/calendar"
+
+// fakeCalendar is an in-memory calendar provider
+type fakeCalendar struct {
+	calendars int
+	events    map[string]*calendar.Event
+	nextID    int
+}
+
+func newFakeCalendar() *fakeCalendar {
+	return &fakeCalendar{events: map[string]*calendar.Event{}}
+}
+
+func (p *fakeCalendar) Name() string { return calendar.ProviderGoogle }
+
+func (p *fakeCalendar) AuthCodeURL(state string) string {
+	return "https://calendar.test/auth?state=" + url.QueryEscape(state)
+}
+
+func (p *fakeCalendar) Exchange(_ context.Context, code string) (*calendar.Token, error) {
+	if code != "valid-code" {
+		return nil, fmt.Errorf("invalid code")
+	}
+	return &calendar.Token{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (p *fakeCalendar) Refresh(_ context.Context, _ string) (*calendar.Token, error) {
+	return &calendar.Token{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (p *fakeCalendar) CreateCalendar(_ context.Context, _, _ string) (string, error) {
+	p.calendars++
+	return fmt.Sprintf("calendar-%d", p.calendars), nil
+}
+
+func (p *fakeCalendar) InsertEvent(_ context.Context, _, _ string, event *calendar.Event) (*calendar.Event, error) {
+	p.nextID++
+	stored := *event
+	stored.ID = fmt.Sprintf("event-%d", p.nextID)
+	stored.Updated = time.Now()
+	p.events[stored.ID] = &stored
+	return &stored, nil
+}
+
+func (p *fakeCalendar) UpdateEvent(_ context.Context, _, _ string, event *calendar.Event) (*calendar.Event, error) {
+	if existing, ok := p.events[event.ID]; !ok || existing.Cancelled {
+		return nil, calendar.ErrNotFound
+	}
+	stored := *event
+	stored.Updated = time.Now()
+	p.events[stored.ID] = &stored
+	return &stored, nil
+}
+
+func (p *fakeCalendar) DeleteEvent(_ context.Context, _, _, eventID string) error {
+	event, ok := p.events[eventID]
+	if !ok || event.Cancelled {
+		return calendar.ErrNotFound
+	}
+	event.Cancelled = true
+	event.Updated = time.Now()
+	return nil
+}
+
+func (p *fakeCalendar) ListEvents(_ context.Context, _, _ string, updatedMin time.Time) ([]calendar.Event, error) {
+	var events []calendar.Event
+	for _, event := range p.events {
+		if event.Updated.After(updatedMin) {
+			events = append(events, *event)
+		}
+	}
+	return events, nil
+}
+
+// liveEvents returns the events that are not deleted
+func (p *fakeCalendar) liveEvents() []*calendar.Event {
+	var events []*calendar.Event
+	for _, event := range p.events {
+		if !event.Cancelled {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// newCalendarService builds a CalendarService on the fixture's database with the given provider
+func newCalendarService(f *testutil.TestFixture, provider calendar.Provider) (*service.CalendarService, *service.TodoService) {
+	todoService := service.NewTodoService(
+		f.TodoRepo, f.CategoryRepo, f.HistoryRepo, f.PreferenceRepo,
+		service.NewStreakService(f.StreakRepo, f.PreferenceRepo), &config.SearchConfig{FuzzyThreshold: 0.3},
+	)
+	calendarService := service.NewCalendarService(
+		repository.NewCalendarRepository(f.DB), repository.NewSyncRepository(f.DB), todoService, provider,
+		&config.CalendarConfig{ReturnURL: "http://localhost:3000/settings/calendar"},
+	)
+	return calendarService, todoService
+}
+
+// callCalendarCallback calls the public OAuth callback and returns the redirect location
+func callCalendarCallback(t *testing.T, f *testutil.TestFixture, h *handler.CalendarHandler, query string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/calendar/google/callback?"+query, nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.Callback(f.Echo.NewContext(req, rec)))
+	require.Equal(t, http.StatusFound, rec.Code)
+	return rec.Header().Get("Location")
+}
+
+// connectCalendar connects the user's calendar through the connect endpoint and the callback
+func connectCalendar(t *testing.T, f *testutil.TestFixture, h *handler.CalendarHandler, token string) {
+	t.Helper()
+	rec, err := f.CallAuth(token, http.MethodPost, calendarPath+"/connect", "", h.Connect)
+	require.NoError(t, err)
+	authorizationURL, err := url.Parse(testutil.JSONResponse(t, rec)["authorization_url"].(string))
+	require.NoError(t, err)
+
+	state := authorizationURL.Query().Get("state")
+	require.NotEmpty(t, state)
+	location := callCalendarCallback(t, f, h, "state="+url.QueryEscape(state)+"&code=valid-code")
+	require.Equal(t, "http://localhost:3000/settings/calendar?calendar=connected", location)
+}
+
+// TestCalendar_Connect tests connecting a calendar through the OAuth flow
+func TestCalendar_Connect(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	provider := newFakeCalendar()
+	calendarService, _ := newCalendarService(f, provider)
+	h := handler.NewCalendarHandler(calendarService)
+
+	_, token := f.CreateUser("calendarconnect@example.com")
+
+	rec, err := f.CallAuth(token, http.MethodGet, calendarPath, "", h.Show)
+	require.NoError(t, err)
+	assert.Equal(t, false, testutil.JSONResponse(t, rec)["connected"])
+
+	// An unknown state or a denied consent sends the user back with an error
+	assert.Contains(t, callCalendarCallback(t, f, h, "state=unknown&code=valid-code"), "calendar=error")
+	assert.Contains(t, callCalendarCallback(t, f, h, "error=access_denied"), "calendar=error")
+
+	connectCalendar(t, f, h, token)
+	assert.Equal(t, 1, provider.calendars)
+
+	rec, err = f.CallAuth(token, http.MethodGet, calendarPath, "", h.Show)
+	require.NoError(t, err)
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, true, response["connected"])
+	assert.Equal(t, "google", response["provider"])
+	assert.Equal(t, false, response["pull_enabled"])
+
+	// Connecting again keeps the calendar
+	connectCalendar(t, f, h, token)
+	assert.Equal(t, 1, provider.calendars)
+
+	rec, err = f.CallAuth(token, http.MethodDelete, calendarPath, "", h.Disconnect)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, err = f.CallAuth(token, http.MethodDelete, calendarPath, "", h.Disconnect)
+	assertAPIError(t, err, http.StatusNotFound)
+}
+
+// TestCalendar_Push tests that the sync job creates, updates and deletes the events of due dates
+func TestCalendar_Push(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	provider := newFakeCalendar()
+	calendarService, todoService := newCalendarService(f, provider)
+	h := handler.NewCalendarHandler(calendarService)
+
+	user, token := f.CreateUser("calendarpush@example.com")
+	other, _ := f.CreateUser("calendarpushother@example.com")
+	connectCalendar(t, f, h, token)
+
+	todo := f.CreateTodoWithDetails(user.ID, "Pay rent", testutil.TodoOptions{DueDate: dueIn(2)})
+	f.CreateTodo(user.ID, "No due date")
+	f.CreateTodoWithDetails(other.ID, "Not connected", testutil.TodoOptions{DueDate: dueIn(1)})
+
+	require.NoError(t, calendarService.Run(context.Background(), time.Now()))
+	events := provider.liveEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, "Pay rent", events[0].Summary)
+	assert.Equal(t, dueIn(2).Format("2006-01-02"), events[0].Date)
+	assert.Equal(t, *todo.UUID, events[0].TodoUUID)
+
+	// Completing the todo and moving its due date updates the event
+	completed := model.StatusCompleted.String()
+	dueDate := dueIn(5).Format("2006-01-02")
+	_, err := todoService.Update(context.Background(), todo.ID, user.ID, service.UpdateInput{Status: &completed, DueDate: &dueDate})
+	require.NoError(t, err)
+
+	require.NoError(t, calendarService.Run(context.Background(), time.Now()))
+	events = provider.liveEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, "✔ Pay rent", events[0].Summary)
+	assert.Equal(t, dueDate, events[0].Date)
+
+	// Removing the due date deletes the event
+	noDueDate := ""
+	_, err = todoService.Update(context.Background(), todo.ID, user.ID, service.UpdateInput{DueDate: &noDueDate})
+	require.NoError(t, err)
+	require.NoError(t, calendarService.Run(context.Background(), time.Now()))
+	assert.Empty(t, provider.liveEvents())
+
+	// Deleting the todo deletes its event
+	deleted := f.CreateTodoWithDetails(user.ID, "Dentist", testutil.TodoOptions{DueDate: dueIn(3)})
+	require.NoError(t, calendarService.Run(context.Background(), time.Now()))
+	require.Len(t, provider.liveEvents(), 1)
+
+	require.NoError(t, f.TodoRepo.Delete(context.Background(), deleted.ID, user.ID))
+	require.NoError(t, calendarService.Run(context.Background(), time.Now()))
+	assert.Empty(t, provider.liveEvents())
+
+	rec, err := f.CallAuth(token, http.MethodGet, calendarPath, "", h.Show)
+	require.NoError(t, err)
+	response := testutil.JSONResponse(t, rec)
+	assert.NotNil(t, response["last_synced_at"])
+	assert.Nil(t, response["last_error"])
+}
+
+// TestCalendar_Pull tests that completion and date edits made in the calendar are applied when enabled
+func TestCalendar_Pull(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	provider := newFakeCalendar()
+	calendarService, _ := newCalendarService(f, provider)
+	h := handler.NewCalendarHandler(calendarService)
+
+	user, token := f.CreateUser("calendarpull@example.com")
+	connectCalendar(t, f, h, token)
+
+	todo := f.CreateTodoWithDetails(user.ID, "Renew passport", testutil.TodoOptions{DueDate: dueIn(2)})
+	require.NoError(t, calendarService.Run(context.Background(), time.Now()))
+	events := provider.liveEvents()
+	require.Len(t, events, 1)
+
+	editEvent := func(date string, completed bool) {
+		events[0].Date = date
+		events[0].Summary = "Renew passport"
+		if completed {
+			events[0].Summary = "✔ Renew passport"
+		}
+		events[0].Updated = time.Now().Add(time.Second)
+	}
+
+	// Edits are not pulled while pulling is disabled
+	editEvent(dueIn(4).Format("2006-01-02"), true)
+	require.NoError(t, calendarService.Run(context.Background(), time.Now()))
+	stored, err := f.TodoRepo.FindByID(context.Background(), todo.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, dueIn(2).Format("2006-01-02"), stored.DueDate.Format("2006-01-02"))
+
+	rec, err := f.CallAuth(token, http.MethodPatch, calendarPath, `{"pull_enabled":true}`, h.Update)
+	require.NoError(t, err)
+	assert.Equal(t, true, testutil.JSONResponse(t, rec)["pull_enabled"])
+
+	// Edits made after enabling it are applied
+	events = provider.liveEvents()
+	editEvent(dueIn(6).Format("2006-01-02"), true)
+	require.NoError(t, calendarService.Run(context.Background(), time.Now()))
+
+	stored, err = f.TodoRepo.FindByID(context.Background(), todo.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, dueIn(6).Format("2006-01-02"), stored.DueDate.Format("2006-01-02"))
+	assert.True(t, stored.Completed)
+
+	// Removing the mark reopens the todo
+	events = provider.liveEvents()
+	editEvent(dueIn(6).Format("2006-01-02"), false)
+	require.NoError(t, calendarService.Run(context.Background(), time.Now()))
+
+	stored, err = f.TodoRepo.FindByID(context.Background(), todo.ID, user.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Completed)
+	assert.Equal(t, model.StatusPending, stored.Status)
+}
+
+// TestCalendar_Disabled tests that the endpoints are unavailable without a provider
+func TestCalendar_Disabled(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	calendarService, _ := newCalendarService(f, nil)
+	h := handler.NewCalendarHandler(calendarService)
+
+	_, token := f.CreateUser("calendardisabled@example.com")
+
+	_, err := f.CallAuth(token, http.MethodGet, calendarPath, "", h.Show)
+	assertAPIError(t, err, http.StatusNotFound)
+
+	_, err = f.CallAuth(token, http.MethodPost, calendarPath+"/connect", "", h.Connect)
+	assertAPIError(t, err, http.StatusNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/calendar/google/callback?state=s&code=c", nil)
+	err = h.Callback(f.Echo.NewContext(req, httptest.NewRecorder()))
+	assertAPIError(t, err, http.StatusNotFound)
+}
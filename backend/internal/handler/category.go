@@ -67,7 +67,7 @@ func (h *CategoryHandler) List(c echo.Context) error {
 		return err
 	}
 
-	categories, err := h.categoryRepo.FindAllByUserID(currentUser.ID)
+	categories, err := h.categoryRepo.FindAllByUserID(c.Request().Context(), currentUser.ID)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "CategoryHandler.List: failed to fetch categories")
 	}
@@ -93,7 +93,7 @@ func (h *CategoryHandler) Show(c echo.Context) error {
 		return err
 	}
 
-	category, err := h.categoryRepo.FindByID(id, currentUser.ID)
+	category, err := h.categoryRepo.FindByID(c.Request().Context(), id, currentUser.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Category", id)
@@ -118,7 +118,7 @@ func (h *CategoryHandler) Create(c echo.Context) error {
 	}
 
 	// Check for duplicate name (case-insensitive)
-	exists, err := h.categoryRepo.ExistsByName(req.Name, currentUser.ID, nil)
+	exists, err := h.categoryRepo.ExistsByName(c.Request().Context(), req.Name, currentUser.ID, nil)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "CategoryHandler.Create: failed to check duplicate name")
 	}
@@ -132,7 +132,7 @@ func (h *CategoryHandler) Create(c echo.Context) error {
 		Color:  req.Color,
 	}
 
-	if err := h.categoryRepo.Create(category); err != nil {
+	if err := h.categoryRepo.Create(c.Request().Context(), category); err != nil {
 		return errors.InternalErrorWithLog(err, "CategoryHandler.Create: failed to create category")
 	}
 
@@ -152,7 +152,7 @@ func (h *CategoryHandler) Update(c echo.Context) error {
 		return err
 	}
 
-	category, err := h.categoryRepo.FindByID(id, currentUser.ID)
+	category, err := h.categoryRepo.FindByID(c.Request().Context(), id, currentUser.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Category", id)
@@ -167,7 +167,7 @@ func (h *CategoryHandler) Update(c echo.Context) error {
 
 	// Check for duplicate name if name is being changed
 	if req.Name != nil && *req.Name != category.Name {
-		exists, err := h.categoryRepo.ExistsByName(*req.Name, currentUser.ID, &id)
+		exists, err := h.categoryRepo.ExistsByName(c.Request().Context(), *req.Name, currentUser.ID, &id)
 		if err != nil {
 			return errors.InternalErrorWithLog(err, "CategoryHandler.Update: failed to check duplicate name")
 		}
@@ -181,7 +181,7 @@ func (h *CategoryHandler) Update(c echo.Context) error {
 		category.Color = *req.Color
 	}
 
-	if err := h.categoryRepo.Update(category); err != nil {
+	if err := h.categoryRepo.Update(c.Request().Context(), category); err != nil {
 		return errors.InternalErrorWithLog(err, "CategoryHandler.Update: failed to update category")
 	}
 
@@ -201,7 +201,7 @@ func (h *CategoryHandler) Delete(c echo.Context) error {
 		return err
 	}
 
-	if err := h.categoryRepo.Delete(id, currentUser.ID); err != nil {
+	if err := h.categoryRepo.Delete(c.Request().Context(), id, currentUser.ID); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Category", id)
 		}
@@ -89,14 +89,14 @@ func (h *CommentHandler) List(c echo.Context) error {
 	}
 
 	// Verify todo exists and belongs to user
-	if _, err := h.todoRepo.FindByID(todoID, currentUser.ID); err != nil {
+	if _, err := h.todoRepo.FindByID(c.Request().Context(), todoID, currentUser.ID); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Todo", todoID)
 		}
 		return errors.InternalErrorWithLog(err, "CommentHandler.List: failed to fetch todo")
 	}
 
-	comments, err := h.commentRepo.FindAllByCommentable(model.CommentableTypeTodo, todoID)
+	comments, err := h.commentRepo.FindAllByCommentable(c.Request().Context(), model.CommentableTypeTodo, todoID, currentUser.ID)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "CommentHandler.List: failed to fetch comments")
 	}
@@ -123,7 +123,7 @@ func (h *CommentHandler) Create(c echo.Context) error {
 	}
 
 	// Verify todo exists and belongs to user
-	if _, err := h.todoRepo.FindByID(todoID, currentUser.ID); err != nil {
+	if _, err := h.todoRepo.FindByID(c.Request().Context(), todoID, currentUser.ID); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Todo", todoID)
 		}
@@ -142,12 +142,12 @@ func (h *CommentHandler) Create(c echo.Context) error {
 		CommentableID:   todoID,
 	}
 
-	if err := h.commentRepo.Create(comment); err != nil {
+	if err := h.commentRepo.Create(c.Request().Context(), comment); err != nil {
 		return errors.InternalErrorWithLog(err, "CommentHandler.Create: failed to create comment")
 	}
 
 	// Reload with user relation
-	comment, err = h.commentRepo.FindByIDWithoutDeleted(comment.ID)
+	comment, err = h.commentRepo.FindByIDWithoutDeleted(c.Request().Context(), comment.ID, currentUser.ID)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "CommentHandler.Create: failed to reload comment")
 	}
@@ -174,7 +174,7 @@ func (h *CommentHandler) Update(c echo.Context) error {
 	}
 
 	// Verify todo exists and belongs to user
-	if _, err := h.todoRepo.FindByID(todoID, currentUser.ID); err != nil {
+	if _, err := h.todoRepo.FindByID(c.Request().Context(), todoID, currentUser.ID); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Todo", todoID)
 		}
@@ -182,7 +182,7 @@ func (h *CommentHandler) Update(c echo.Context) error {
 	}
 
 	// Get comment
-	comment, err := h.commentRepo.FindByIDWithoutDeleted(commentID)
+	comment, err := h.commentRepo.FindByIDWithoutDeleted(c.Request().Context(), commentID, currentUser.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Comment", commentID)
@@ -212,7 +212,7 @@ func (h *CommentHandler) Update(c echo.Context) error {
 
 	comment.Content = req.Content
 
-	if err := h.commentRepo.Update(comment); err != nil {
+	if err := h.commentRepo.Update(c.Request().Context(), comment); err != nil {
 		return errors.InternalErrorWithLog(err, "CommentHandler.Update: failed to update comment")
 	}
 
@@ -238,7 +238,7 @@ func (h *CommentHandler) Delete(c echo.Context) error {
 	}
 
 	// Verify todo exists and belongs to user
-	if _, err := h.todoRepo.FindByID(todoID, currentUser.ID); err != nil {
+	if _, err := h.todoRepo.FindByID(c.Request().Context(), todoID, currentUser.ID); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Todo", todoID)
 		}
@@ -246,7 +246,7 @@ func (h *CommentHandler) Delete(c echo.Context) error {
 	}
 
 	// Get comment (include soft-deleted to check ownership)
-	comment, err := h.commentRepo.FindByID(commentID)
+	comment, err := h.commentRepo.FindByID(c.Request().Context(), commentID, currentUser.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Comment", commentID)
@@ -269,7 +269,7 @@ func (h *CommentHandler) Delete(c echo.Context) error {
 		return errors.NotFound("Comment", commentID)
 	}
 
-	if err := h.commentRepo.SoftDelete(commentID); err != nil {
+	if err := h.commentRepo.SoftDelete(c.Request().Context(), commentID, currentUser.ID); err != nil {
 		return errors.InternalErrorWithLog(err, "CommentHandler.Delete: failed to delete comment")
 	}
 
@@ -1,6 +1,7 @@
 package handler_test
 
 import (
+	"context"
 	"net/http"
 	"testing"
 	"time"
@@ -76,7 +77,7 @@ func TestCommentList_ExcludesSoftDeleted(t *testing.T) {
 	f.CreateComment(user.ID, todo.ID, "Comment 2")
 
 	// Soft delete comment1
-	require.NoError(t, f.CommentRepo.SoftDelete(comment1.ID))
+	require.NoError(t, f.CommentRepo.SoftDelete(context.Background(), comment1.ID, user.ID))
 
 	rec, err := f.CallAuth(token, http.MethodGet, testutil.TodoCommentsPath(todo.ID), "", f.CommentHandler.List)
 	require.NoError(t, err)
@@ -256,7 +257,7 @@ func TestCommentDelete_Success(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, rec.Code)
 
 	// Verify comment is soft deleted
-	exists, err := f.CommentRepo.ExistsByID(comment.ID)
+	exists, err := f.CommentRepo.ExistsByID(context.Background(), comment.ID, user.ID)
 	require.NoError(t, err)
 	assert.False(t, exists)
 }
@@ -291,7 +292,7 @@ func TestCommentDelete_AlreadyDeleted(t *testing.T) {
 	comment := f.CreateComment(user.ID, todo.ID, "Comment to delete")
 
 	// Soft delete first
-	require.NoError(t, f.CommentRepo.SoftDelete(comment.ID))
+	require.NoError(t, f.CommentRepo.SoftDelete(context.Background(), comment.ID, user.ID))
 
 	// Try to delete again
 	_, err := f.CallAuth(token, http.MethodDelete, testutil.CommentPath(todo.ID, comment.ID), "", f.CommentHandler.Delete)
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// ConsentHandler handles terms of service and privacy policy consent endpoints
+type ConsentHandler struct {
+	consentService *service.ConsentService
+}
+
+// NewConsentHandler creates a new ConsentHandler
+func NewConsentHandler(consentService *service.ConsentService) *ConsentHandler {
+	return &ConsentHandler{consentService: consentService}
+}
+
+// AcceptConsentRequest represents the request body for accepting policy versions
+type AcceptConsentRequest struct {
+	TermsVersion   string `json:"terms_version" validate:"max=100"`
+	PrivacyVersion string `json:"privacy_version" validate:"max=100"`
+}
+
+// ConsentResponse represents a user's policy consents in API responses
+type ConsentResponse struct {
+	Enforcement        string                 `json:"enforcement"`
+	AcceptanceRequired bool                   `json:"acceptance_required"`
+	Policies           []PolicyStatusResponse `json:"policies"`
+}
+
+// PolicyStatusResponse represents a user's consent to one policy
+type PolicyStatusResponse struct {
+	Policy          string  `json:"policy"`
+	CurrentVersion  string  `json:"current_version"`
+	AcceptedVersion *string `json:"accepted_version"`
+	AcceptedAt      *string `json:"accepted_at"`
+	Accepted        bool    `json:"accepted"`
+}
+
+// Show retrieves the current policy versions and the versions the current user has accepted
+// GET /api/v1/users/me/consents
+func (h *ConsentHandler) Show(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+	return h.respond(c, currentUser.ID)
+}
+
+// Accept records that the current user accepts the given policy versions
+// POST /api/v1/users/me/consents
+func (h *ConsentHandler) Accept(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var req AcceptConsentRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.consentService.Accept(c.Request().Context(), currentUser.ID, consentInput(c, req.TermsVersion, req.PrivacyVersion)); err != nil {
+		return err
+	}
+	return h.respond(c, currentUser.ID)
+}
+
+// respond writes the consent status of a user
+func (h *ConsentHandler) respond(c echo.Context, userID int64) error {
+	statuses, err := h.consentService.Status(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := ConsentResponse{
+		Enforcement: h.consentService.Enforcement(),
+		Policies:    make([]PolicyStatusResponse, len(statuses)),
+	}
+	for i, status := range statuses {
+		resp.Policies[i] = PolicyStatusResponse{
+			Policy:          string(status.Policy),
+			CurrentVersion:  status.CurrentVersion,
+			AcceptedVersion: status.AcceptedVersion,
+			Accepted:        status.Accepted(),
+		}
+		if status.AcceptedAt != nil {
+			acceptedAt := util.FormatRFC3339(*status.AcceptedAt)
+			resp.Policies[i].AcceptedAt = &acceptedAt
+		}
+		if !status.Accepted() {
+			resp.AcceptanceRequired = true
+		}
+	}
+	return response.OK(c, resp)
+}
+
+// consentInput builds the consent input of a request
+func consentInput(c echo.Context, termsVersion, privacyVersion string) service.ConsentInput {
+	return service.ConsentInput{
+		Versions: map[model.Policy]string{
+			model.PolicyTerms:   termsVersion,
+			model.PolicyPrivacy: privacyVersion,
+		},
+		// The direct peer, as X-Forwarded-For and X-Real-IP can be set by the client
+		IPAddress: echo.ExtractIPDirect()(c.Request()),
+		UserAgent: c.Request().UserAgent(),
+	}
+}
@@ -0,0 +1,155 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/config"
+	"todo-api/internal/errors"
+	"todo-api/internal/handler"
+	"todo-api/internal/middleware"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+// consentService returns a ConsentService tracking the given policy versions
+func consentService(f *testutil.TestFixture, termsVersion, privacyVersion, enforcement string) *service.ConsentService {
+	return service.NewConsentService(repository.NewPolicyConsentRepository(f.DB), &config.PolicyConfig{
+		TermsVersion:   termsVersion,
+		PrivacyVersion: privacyVersion,
+		Enforcement:    enforcement,
+	})
+}
+
+// TestSignUp_RequiresCurrentPolicyVersions tests that sign-up records the accepted policy versions and the client IP
+func TestSignUp_RequiresCurrentPolicyVersions(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	cfg := *testutil.TestConfig
+	cfg.TermsVersion = "2024-06"
+	cfg.PrivacyPolicyVersion = "2024-05"
+	authHandler := handler.NewAuthHandler(f.UserRepo, f.DenylistRepo, repository.NewPolicyConsentRepository(f.DB), &cfg)
+
+	signUp := func(extra string) (*httptest.ResponseRecorder, error) {
+		body := `{"user":{"email":"consent@example.com","password":"password123","password_confirmation":"password123","name":"Test User"` + extra + `}}`
+		req := httptest.NewRequest(http.MethodPost, "/auth/sign_up", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+		rec := httptest.NewRecorder()
+		return rec, authHandler.SignUp(f.Echo.NewContext(req, rec))
+	}
+
+	// Missing and outdated versions are rejected
+	_, err := signUp(`,"terms_version":"2024-01"`)
+	require.Error(t, err)
+	apiErr, ok := err.(*errors.ApiError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	exists, err := f.UserRepo.ExistsByEmail(context.Background(), "consent@example.com")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	rec, err := signUp(`,"terms_version":"2024-06","privacy_version":"2024-05"`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var consents []model.PolicyConsent
+	require.NoError(t, f.DB.Order("policy ASC").Find(&consents).Error)
+	require.Len(t, consents, 2)
+	assert.Equal(t, model.PolicyPrivacy, consents[0].Policy)
+	assert.Equal(t, "2024-05", consents[0].Version)
+	assert.Equal(t, model.PolicyTerms, consents[1].Policy)
+	assert.Equal(t, "2024-06", consents[1].Version)
+
+	// The IP is the direct peer, not the client-supplied X-Forwarded-For
+	assert.Equal(t, "192.0.2.1", consents[0].IPAddress)
+}
+
+// TestConsent_ReacceptAfterVersionChange tests flagging and re-accepting after a policy version changes
+func TestConsent_ReacceptAfterVersionChange(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	user, token := f.CreateUser("reaccept@example.com")
+	require.NoError(t, consentService(f, "v1", "", "flag").Accept(context.Background(), user.ID, service.ConsentInput{
+		Versions: map[model.Policy]string{model.PolicyTerms: "v1"},
+	}))
+
+	svc := consentService(f, "v2", "", "flag")
+	consentHandler := handler.NewConsentHandler(svc)
+	guarded := middleware.RequirePolicyConsent(svc)(f.TodoHandler.List)
+
+	// The new version is pending
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/users/me/consents", "", consentHandler.Show)
+	require.NoError(t, err)
+	resp := testutil.JSONResponse(t, rec)
+	assert.Equal(t, true, resp["acceptance_required"])
+	policy := resp["policies"].([]any)[0].(map[string]any)
+	assert.Equal(t, "v2", policy["current_version"])
+	assert.Equal(t, "v1", policy["accepted_version"])
+	assert.Equal(t, false, policy["accepted"])
+
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", guarded)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "terms", rec.Header().Get(middleware.PolicyAcceptanceRequiredHeader))
+
+	// Only the current version can be accepted
+	_, err = f.CallAuth(token, http.MethodPost, "/api/v1/users/me/consents", `{"terms_version":"v1"}`, consentHandler.Accept)
+	require.Error(t, err)
+
+	rec, err = f.CallAuth(token, http.MethodPost, "/api/v1/users/me/consents", `{"terms_version":"v2"}`, consentHandler.Accept)
+	require.NoError(t, err)
+	resp = testutil.JSONResponse(t, rec)
+	assert.Equal(t, false, resp["acceptance_required"])
+
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", guarded)
+	require.NoError(t, err)
+	assert.Empty(t, rec.Header().Get(middleware.PolicyAcceptanceRequiredHeader))
+}
+
+// TestConsent_TruncatesUserAgent tests that long user agents are cut at a character boundary
+func TestConsent_TruncatesUserAgent(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	user, _ := f.CreateUser("consentua@example.com")
+
+	require.NoError(t, consentService(f, "v1", "", "flag").Accept(context.Background(), user.ID, service.ConsentInput{
+		Versions:  map[model.Policy]string{model.PolicyTerms: "v1"},
+		UserAgent: "a" + strings.Repeat("あ", 600),
+	}))
+
+	var consent model.PolicyConsent
+	require.NoError(t, f.DB.Where("user_id = ?", user.ID).First(&consent).Error)
+	assert.True(t, utf8.ValidString(consent.UserAgent))
+	assert.Equal(t, 500, utf8.RuneCountInString(consent.UserAgent))
+}
+
+// TestRequirePolicyConsent_Block tests that blocking enforcement rejects users until they accept
+func TestRequirePolicyConsent_Block(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	user, token := f.CreateUser("block@example.com")
+
+	svc := consentService(f, "v1", "v1", "block")
+	guarded := middleware.RequirePolicyConsent(svc)(f.TodoHandler.List)
+
+	_, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", guarded)
+	require.Error(t, err)
+	apiErr, ok := err.(*errors.ApiError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	assert.Equal(t, "POLICY_ACCEPTANCE_REQUIRED", apiErr.Code)
+
+	require.NoError(t, svc.Accept(context.Background(), user.ID, service.ConsentInput{
+		Versions: map[model.Policy]string{model.PolicyTerms: "v1", model.PolicyPrivacy: "v1"},
+	}))
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", guarded)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// DataExportHandler handles account data export endpoints
+type DataExportHandler struct {
+	exportService *service.DataExportService
+}
+
+// NewDataExportHandler creates a new DataExportHandler
+func NewDataExportHandler(exportService *service.DataExportService) *DataExportHandler {
+	return &DataExportHandler{exportService: exportService}
+}
+
+// DataExportResponse represents a data export in API responses
+type DataExportResponse struct {
+	ID          int64   `json:"id"`
+	Status      string  `json:"status"`
+	FileSize    int64   `json:"file_size"`
+	Error       *string `json:"error"`
+	CompletedAt *string `json:"completed_at"`
+	ExpiresAt   *string `json:"expires_at"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+// Create queues an export of all of the current user's data
+// POST /api/v1/users/me/export
+func (h *DataExportHandler) Create(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	export, err := h.exportService.Request(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, toDataExportResponse(export))
+}
+
+// List retrieves the current user's recent exports
+// GET /api/v1/users/me/exports
+func (h *DataExportHandler) List(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	exports, err := h.exportService.List(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	responses := make([]DataExportResponse, len(exports))
+	for i := range exports {
+		responses[i] = toDataExportResponse(&exports[i])
+	}
+
+	return response.OK(c, responses)
+}
+
+// Download streams a completed export archive; the token in the emailed link is the only credential
+// GET /exports/download?token=...
+func (h *DataExportHandler) Download(c echo.Context) error {
+	token := strings.TrimSpace(c.QueryParam("token"))
+	if token == "" {
+		return errors.ValidationFailed(map[string][]string{
+			"token": {"is required"},
+		})
+	}
+
+	reader, export, err := h.exportService.Download(c.Request().Context(), token)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	filename := fmt.Sprintf("export-%s.zip", export.CreatedAt.Format("20060102-150405"))
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	return c.Stream(http.StatusOK, "application/zip", reader)
+}
+
+func toDataExportResponse(export *model.DataExport) DataExportResponse {
+	resp := DataExportResponse{
+		ID:        export.ID,
+		Status:    string(export.Status),
+		FileSize:  export.FileSize,
+		Error:     export.Error,
+		CreatedAt: util.FormatRFC3339(export.CreatedAt),
+	}
+	if export.CompletedAt != nil {
+		completedAt := util.FormatRFC3339(*export.CompletedAt)
+		resp.CompletedAt = &completedAt
+	}
+	if export.ExpiresAt != nil {
+		expiresAt := util.FormatRFC3339(*export.ExpiresAt)
+		resp.ExpiresAt = &expiresAt
+	}
+	return resp
+}
@@ -0,0 +1,209 @@
+package handler_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/config"
+	"todo-api/internal/mailer"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+const (
+	dataExportPath  = "/api/v1/users/me/export"
+	dataExportsPath = "/api/v1/users/me/exports"
+)
+
+// TestDataExportCreate tests that requesting an export queues it
+func TestDataExportCreate(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("exportcreate@example.com")
+
+	rec, err := f.CallAuth(token, http.MethodPost, dataExportPath, "", f.DataExportHandler.Create)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, string(model.DataExportStatusPending), response["status"])
+	assert.Nil(t, response["expires_at"])
+}
+
+// TestDataExportCreate_ReturnsInProgress tests that a second request reuses the queued export
+func TestDataExportCreate_ReturnsInProgress(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("exportdup@example.com")
+
+	rec1, err := f.CallAuth(token, http.MethodPost, dataExportPath, "", f.DataExportHandler.Create)
+	require.NoError(t, err)
+	rec2, err := f.CallAuth(token, http.MethodPost, dataExportPath, "", f.DataExportHandler.Create)
+	require.NoError(t, err)
+
+	first := testutil.JSONResponse(t, rec1)
+	second := testutil.JSONResponse(t, rec2)
+	assert.Equal(t, first["id"], second["id"])
+}
+
+// TestDataExportList tests that users only see their own exports
+func TestDataExportList(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token1 := f.CreateUser("exportlist1@example.com")
+	_, token2 := f.CreateUser("exportlist2@example.com")
+
+	_, err := f.CallAuth(token1, http.MethodPost, dataExportPath, "", f.DataExportHandler.Create)
+	require.NoError(t, err)
+
+	rec, err := f.CallAuth(token1, http.MethodGet, dataExportsPath, "", f.DataExportHandler.List)
+	require.NoError(t, err)
+	assert.Len(t, testutil.JSONArrayResponse(t, rec), 1)
+
+	rec, err = f.CallAuth(token2, http.MethodGet, dataExportsPath, "", f.DataExportHandler.List)
+	require.NoError(t, err)
+	assert.Len(t, testutil.JSONArrayResponse(t, rec), 0)
+}
+
+// TestDataExportDownload_InvalidToken tests that an unknown token is rejected
+func TestDataExportDownload_InvalidToken(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/exports/download?token=unknown", nil)
+	rec := httptest.NewRecorder()
+	c := f.Echo.NewContext(req, rec)
+
+	err := f.DataExportHandler.Download(c)
+	require.Error(t, err)
+}
+
+// dataExportService returns a DataExportService storing archives and attachments in store
+func dataExportService(f *testutil.TestFixture, store *memoryStorage) *service.DataExportService {
+	db := f.DB
+	return service.NewDataExportService(
+		repository.NewDataExportRepository(db), f.UserRepo, repository.NewUserPreferenceRepository(db), f.TodoRepo,
+		f.CategoryRepo, f.TagRepo, repository.NewCommentRepository(db), repository.NewTodoHistoryRepository(db),
+		repository.NewNoteRepository(db), repository.NewFileRepository(db), repository.NewFocusSessionRepository(db),
+		repository.NewStreakRepository(db), repository.NewPolicyConsentRepository(db), repository.NewTodoLinkRepository(db),
+		repository.NewMyDayRepository(db), repository.NewEscalationRuleRepository(db), repository.NewIntegrationRepository(db),
+		repository.NewCalendarRepository(db), store, mailer.NewLogMailer(),
+		&config.DataExportConfig{PublicAPIURL: "http://localhost:3000", LinkTTL: 24 * time.Hour},
+	)
+}
+
+// buildExport requests an export for the user, processes it, and returns the archive entries by name
+func buildExport(t *testing.T, f *testutil.TestFixture, store *memoryStorage, userID int64) map[string]string {
+	t.Helper()
+	ctx := context.Background()
+	svc := dataExportService(f, store)
+	_, err := svc.Request(ctx, userID)
+	require.NoError(t, err)
+	require.NoError(t, svc.Run(ctx, time.Now()))
+
+	exports, err := svc.List(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, exports, 1)
+	require.Equal(t, model.DataExportStatusCompleted, exports[0].Status, "export error: %v", exports[0].Error)
+
+	archive := store.objects[*exports[0].StoragePath]
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	entries := map[string]string{}
+	for _, file := range zr.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		entries[file.Name] = string(content)
+	}
+	return entries
+}
+
+// TestDataExport_ArchiveContents tests that the archive covers the user's data without secrets
+func TestDataExport_ArchiveContents(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	ctx := context.Background()
+	user, _ := f.CreateUser("exportcontents@example.com")
+	todo := f.CreateTodo(user.ID, "Exported")
+
+	require.NoError(t, repository.NewFocusSessionRepository(f.DB).Create(ctx, &model.FocusSession{
+		UserID: user.ID, TodoID: todo.ID, StartedAt: time.Now(), PlannedSeconds: 1500,
+	}))
+	require.NoError(t, repository.NewPolicyConsentRepository(f.DB).Create(ctx, &model.PolicyConsent{
+		UserID: user.ID, Policy: model.PolicyTerms, Version: "v1", AcceptedAt: time.Now(),
+	}))
+	require.NoError(t, repository.NewIntegrationRepository(f.DB).Create(ctx, &model.Integration{
+		UserID: user.ID, Name: "CI", KeyID: "key-exported", Secret: "integration-secret",
+	}))
+
+	entries := buildExport(t, f, newMemoryStorage(), user.ID)
+	for _, name := range []string{
+		"focus_sessions.json", "streaks.json", "policy_consents.json", "todo_links.json",
+		"my_day.json", "escalation_rules.json", "integrations.json", "calendar.json",
+	} {
+		assert.Contains(t, entries, name)
+	}
+	assert.Contains(t, entries["focus_sessions.json"], `"planned_seconds": 1500`)
+	assert.Contains(t, entries["policy_consents.json"], `"version": "v1"`)
+	assert.Contains(t, entries["integrations.json"], `"key_id": "key-exported"`)
+	assert.False(t, strings.Contains(entries["integrations.json"], "integration-secret"))
+	assert.Contains(t, entries["calendar.json"], `"connection": null`)
+}
+
+// TestDataExport_MissingAttachment tests that attachments missing from storage are flagged rather than
+// failing the export, while other download errors still fail it
+func TestDataExport_MissingAttachment(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	user, _ := f.CreateUser("exportmissing@example.com")
+	todo := f.CreateTodo(user.ID, "Attachments")
+
+	store := newMemoryStorage()
+	stored := createStoredFile(t, f, store, todo, model.ScanStatusClean)
+	missing := createStoredFile(t, f, store, todo, model.ScanStatusSkipped)
+	delete(store.objects, missing.StoragePath)
+
+	entries := buildExport(t, f, store, user.ID)
+	assert.Contains(t, entries, fmt.Sprintf("attachments/%d_%s", stored.ID, stored.OriginalName))
+	assert.NotContains(t, entries, fmt.Sprintf("attachments/%d_%s", missing.ID, missing.OriginalName))
+
+	var attachments []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(entries["attachments.json"]), &attachments))
+	require.Len(t, attachments, 2)
+	for _, attachment := range attachments {
+		if int64(attachment["id"].(float64)) == missing.ID {
+			assert.Equal(t, true, attachment["missing"])
+		} else {
+			assert.NotContains(t, attachment, "missing")
+		}
+	}
+
+	// A download failure for an object that exists fails the export
+	store.downloadErr = errors.New("connection reset")
+	require.NoError(t, f.DB.Where("user_id = ?", user.ID).Delete(&model.DataExport{}).Error)
+	ctx := context.Background()
+	svc := dataExportService(f, store)
+	_, err := svc.Request(ctx, user.ID)
+	require.NoError(t, err)
+	require.NoError(t, svc.Run(ctx, time.Now()))
+	exports, err := svc.List(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, exports, 1)
+	assert.Equal(t, model.DataExportStatusFailed, exports[0].Status)
+}
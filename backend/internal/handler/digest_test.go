@@ -0,0 +1,173 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/mailer"
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+// recordingMailer keeps the messages it is asked to send
+type recordingMailer struct {
+	sent []*mailer.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg *mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// digestTitles returns the titles of digest items
+func digestTitles(items []service.DigestItem) []string {
+	titles := make([]string, len(items))
+	for i, item := range items {
+		titles[i] = item.Title
+	}
+	return titles
+}
+
+// setupDigest subscribes a user to digests at 8:00 in Asia/Tokyo with the given frequency
+func setupDigest(t *testing.T, f *testutil.TestFixture, email, frequency string) (*model.User, string) {
+	t.Helper()
+	user, token := f.CreateUser(email)
+	body := `{"timezone":"Asia/Tokyo","digest_frequency":"` + frequency + `","digest_hour":8}`
+	_, err := f.CallAuth(token, http.MethodPatch, preferencesPath, body, f.PreferenceHandler.Update)
+	require.NoError(t, err)
+	return user, token
+}
+
+// TestDigestBuild_Contents tests which todos the daily and weekly digests list
+func TestDigestBuild_Contents(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, _ := setupDigest(t, f, "digestbuild@example.com", "daily")
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	// Monday 2024-01-08 09:00 in Tokyo
+	now := time.Date(2024, 1, 8, 9, 0, 0, 0, tokyo)
+
+	f.CreateTodoWithDetails(user.ID, "Due today", testutil.TodoOptions{DueDate: testutil.ParseDate("2024-01-08")})
+	f.CreateTodoWithDetails(user.ID, "Due Friday", testutil.TodoOptions{DueDate: testutil.ParseDate("2024-01-12")})
+	f.CreateTodoWithDetails(user.ID, "Due next week", testutil.TodoOptions{DueDate: testutil.ParseDate("2024-01-15")})
+	f.CreateTodoWithDetails(user.ID, "Overdue", testutil.TodoOptions{DueDate: testutil.ParseDate("2024-01-05")})
+	completeTodoAt(t, f, f.CreateTodoWithDetails(user.ID, "Done yesterday", testutil.TodoOptions{
+		DueDate: testutil.ParseDate("2024-01-05"),
+	}), time.Date(2024, 1, 7, 23, 30, 0, 0, tokyo).UTC())
+	completeTodoAt(t, f, f.CreateTodo(user.ID, "Done last week"), time.Date(2024, 1, 2, 12, 0, 0, 0, tokyo).UTC())
+	completeTodoAt(t, f, f.CreateTodo(user.ID, "Done today"), time.Date(2024, 1, 8, 0, 30, 0, 0, tokyo).UTC())
+	completeTodoAt(t, f, f.CreateTodo(user.ID, "Done long ago"), time.Date(2023, 12, 31, 12, 0, 0, 0, tokyo).UTC())
+
+	digestService := service.NewDigestService(f.PreferenceRepo, f.TodoRepo, &recordingMailer{})
+	pref, err := f.PreferenceRepo.FindOrDefault(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	daily, err := digestService.Build(context.Background(), pref, now)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-08", daily.Date)
+	assert.Equal(t, []string{"Due today"}, digestTitles(daily.Due))
+	assert.Equal(t, []string{"Overdue"}, digestTitles(daily.Overdue))
+	assert.Equal(t, []string{"Done yesterday"}, digestTitles(daily.Completed))
+
+	pref.DigestFrequency = model.DigestFrequencyWeekly
+	weekly, err := digestService.Build(context.Background(), pref, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Due today", "Due Friday"}, digestTitles(weekly.Due))
+	assert.Equal(t, []string{"Overdue"}, digestTitles(weekly.Overdue))
+	assert.ElementsMatch(t, []string{"Done yesterday", "Done last week"}, digestTitles(weekly.Completed))
+}
+
+// TestDigestBuild_CreatedCompleted tests that a todo created as completed is listed as completed
+func TestDigestBuild_CreatedCompleted(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := setupDigest(t, f, "digestcreated@example.com", "daily")
+
+	rec, err := f.CallAuth(token, http.MethodPost, "/api/v1/todos", `{"title":"Already done","status":"completed"}`, f.TodoHandler.Create)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, true, response["completed"])
+	assert.NotNil(t, response["completed_at"])
+
+	digestService := service.NewDigestService(f.PreferenceRepo, f.TodoRepo, &recordingMailer{})
+	pref, err := f.PreferenceRepo.FindOrDefault(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	digest, err := digestService.Build(context.Background(), pref, time.Now().AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Already done"}, digestTitles(digest.Completed))
+}
+
+// TestDigestRun_Daily tests that a daily digest is sent once at the user's hour
+func TestDigestRun_Daily(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, _ := setupDigest(t, f, "digestdaily@example.com", "daily")
+	f.CreateTodoWithDetails(user.ID, "Overdue", testutil.TodoOptions{DueDate: testutil.ParseDate("2024-01-01")})
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	m := &recordingMailer{}
+	digestService := service.NewDigestService(f.PreferenceRepo, f.TodoRepo, m)
+
+	// Before the hour
+	require.NoError(t, digestService.Run(context.Background(), time.Date(2024, 1, 9, 7, 45, 0, 0, tokyo)))
+	assert.Empty(t, m.sent)
+
+	// At the hour
+	require.NoError(t, digestService.Run(context.Background(), time.Date(2024, 1, 9, 8, 0, 0, 0, tokyo)))
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, "digestdaily@example.com", m.sent[0].To)
+
+	// Not again the same day
+	require.NoError(t, digestService.Run(context.Background(), time.Date(2024, 1, 9, 8, 15, 0, 0, tokyo)))
+	assert.Len(t, m.sent, 1)
+
+	// The next day
+	require.NoError(t, digestService.Run(context.Background(), time.Date(2024, 1, 10, 8, 0, 0, 0, tokyo)))
+	assert.Len(t, m.sent, 2)
+}
+
+// TestDigestRun_Weekly tests that a weekly digest is only sent on Mondays
+func TestDigestRun_Weekly(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, _ := setupDigest(t, f, "digestweekly@example.com", "weekly")
+	f.CreateTodoWithDetails(user.ID, "Overdue", testutil.TodoOptions{DueDate: testutil.ParseDate("2024-01-01")})
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	m := &recordingMailer{}
+	digestService := service.NewDigestService(f.PreferenceRepo, f.TodoRepo, m)
+
+	// Tuesday
+	require.NoError(t, digestService.Run(context.Background(), time.Date(2024, 1, 9, 8, 0, 0, 0, tokyo)))
+	assert.Empty(t, m.sent)
+
+	// Monday, which is still Sunday in UTC
+	require.NoError(t, digestService.Run(context.Background(), time.Date(2024, 1, 15, 8, 0, 0, 0, tokyo)))
+	assert.Len(t, m.sent, 1)
+}
+
+// TestDigestRun_Empty tests that digests with nothing to report are not sent
+func TestDigestRun_Empty(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	setupDigest(t, f, "digestempty@example.com", "daily")
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	m := &recordingMailer{}
+	digestService := service.NewDigestService(f.PreferenceRepo, f.TodoRepo, m)
+
+	require.NoError(t, digestService.Run(context.Background(), time.Date(2024, 1, 9, 8, 0, 0, 0, tokyo)))
+	assert.Empty(t, m.sent)
+}
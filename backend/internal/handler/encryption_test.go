@@ -0,0 +1,203 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/encryption"
+	"todo-api/internal/repository"
+	"todo-api/internal/search"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+	"todo-api/pkg/util"
+)
+
+// testKey returns a base64-encoded 32-byte key filled with b
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+// useKeyRing enables field encryption for the rest of the test
+func useKeyRing(t *testing.T, spec, activeID string) {
+	ring, err := encryption.ParseKeyRing(spec, activeID)
+	require.NoError(t, err)
+	encryption.SetKeyRing(ring)
+	t.Cleanup(func() { encryption.SetKeyRing(nil) })
+}
+
+// storedValue reads a column as stored, without decryption
+func storedValue(t *testing.T, f *testutil.TestFixture, table, column string, id int64) string {
+	var value string
+	require.NoError(t, f.DB.Table(table).Select(column).Where("id = ?", id).Scan(&value).Error)
+	return value
+}
+
+// TestFieldEncryption_EncryptsDescriptionAndComment tests that sensitive text is stored encrypted but returned in plaintext
+func TestFieldEncryption_EncryptsDescriptionAndComment(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	useKeyRing(t, "v1:"+testKey(1), "")
+
+	_, token := f.CreateUser("encrypt@example.com")
+
+	rec, err := f.CallAuth(token, http.MethodPost, "/api/v1/todos", `{"title":"Doctor","description":"Blood test results"}`, f.TodoHandler.Create)
+	require.NoError(t, err)
+	todoID := int64(testutil.JSONResponse(t, rec)["id"].(float64))
+
+	rec, err = f.CallAuth(token, http.MethodPost, testutil.TodoCommentsPath(todoID), `{"content":"Call the clinic"}`, f.CommentHandler.Create)
+	require.NoError(t, err)
+	commentID := int64(testutil.JSONResponse(t, rec)["id"].(float64))
+
+	assert.True(t, strings.HasPrefix(storedValue(t, f, "todos", "description", todoID), "enc:v1:"))
+	assert.True(t, strings.HasPrefix(storedValue(t, f, "comments", "content", commentID), "enc:v1:"))
+
+	rec, err = f.CallAuth(token, http.MethodGet, testutil.TodoPath(todoID), "", f.TodoHandler.Show)
+	require.NoError(t, err)
+	assert.Equal(t, "Blood test results", testutil.JSONResponse(t, rec)["description"])
+
+	rec, err = f.CallAuth(token, http.MethodGet, testutil.TodoCommentsPath(todoID), "", f.CommentHandler.List)
+	require.NoError(t, err)
+	comments := testutil.JSONArrayResponse(t, rec)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "Call the clinic", comments[0].(map[string]any)["content"])
+
+	// History does not keep a plaintext copy
+	rec, err = f.CallAuth(token, http.MethodGet, testutil.TodoHistoriesPath(todoID), "", f.HistoryHandler.List)
+	require.NoError(t, err)
+	assert.NotContains(t, rec.Body.String(), "Blood test results")
+}
+
+// TestFieldEncryption_PrefixedPlaintext tests that text starting with the prefix of an encrypted value is read back as written,
+// with and without a key ring
+func TestFieldEncryption_PrefixedPlaintext(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("encryptprefix@example.com")
+	plain := f.CreateTodoWithDetails(user.ID, "Without keys", testutil.TodoOptions{Description: util.Ptr("enc: my note")})
+	escaped := f.CreateTodoWithDetails(user.ID, "Escaped", testutil.TodoOptions{Description: util.Ptr("plain:enc: my note")})
+	assert.Equal(t, "plain:enc: my note", storedValue(t, f, "todos", "description", plain.ID))
+
+	showDescription := func(id int64) any {
+		rec, err := f.CallAuth(token, http.MethodGet, testutil.TodoPath(id), "", f.TodoHandler.Show)
+		require.NoError(t, err)
+		return testutil.JSONResponse(t, rec)["description"]
+	}
+	assert.Equal(t, "enc: my note", showDescription(plain.ID))
+	assert.Equal(t, "plain:enc: my note", showDescription(escaped.ID))
+
+	useKeyRing(t, "v1:"+testKey(1), "")
+	encrypted := f.CreateTodoWithDetails(user.ID, "With keys", testutil.TodoOptions{Description: util.Ptr("enc: my note")})
+	assert.True(t, strings.HasPrefix(storedValue(t, f, "todos", "description", encrypted.ID), "enc:v1:"))
+	assert.Equal(t, "enc: my note", showDescription(encrypted.ID))
+	assert.Equal(t, "enc: my note", showDescription(plain.ID))
+
+	// The re-encryption job encrypts the plaintext, not its escaped form
+	reencryptionService := service.NewReencryptionService(repository.NewEncryptionRepository(f.DB))
+	require.NoError(t, reencryptionService.Run(context.Background(), time.Now()))
+	assert.True(t, strings.HasPrefix(storedValue(t, f, "todos", "description", plain.ID), "enc:v1:"))
+	assert.Equal(t, "enc: my note", showDescription(plain.ID))
+	assert.Equal(t, "plain:enc: my note", showDescription(escaped.ID))
+}
+
+// TestFieldEncryption_SearchMatchesTitlesOnly tests that search doesn't match the ciphertext of encrypted fields
+func TestFieldEncryption_SearchMatchesTitlesOnly(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	useKeyRing(t, "v1:"+testKey(1), "")
+
+	user, token := f.CreateUser("encryptsearch@example.com")
+	todo := f.CreateTodoWithDetails(user.ID, "Doctor", testutil.TodoOptions{Description: util.Ptr("Blood test results")})
+	f.CreateComment(user.ID, todo.ID, "Call the clinic")
+
+	for query, want := range map[string]int{"enc": 0, "Blood": 0, "Doctor": 1} {
+		rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?q="+query, "", f.TodoHandler.Search)
+		require.NoError(t, err)
+		assert.Len(t, testutil.JSONResponse(t, rec)["data"], want, query)
+	}
+
+	for _, query := range []string{"enc", "clinic"} {
+		rec, err := f.CallAuth(token, http.MethodGet, searchPath+"?q="+query, "", f.SearchHandler.Search)
+		require.NoError(t, err)
+		assert.Nil(t, findGroup(testutil.JSONResponse(t, rec)["groups"].([]any), "comment"), query)
+		assert.Nil(t, findGroup(testutil.JSONResponse(t, rec)["groups"].([]any), "todo"), query)
+	}
+}
+
+// recordingSyncer records the documents sent to and deleted from a search index
+type recordingSyncer struct {
+	docs    []search.Document
+	deleted map[string][]int64
+}
+
+func (s *recordingSyncer) Upsert(_ context.Context, docs []search.Document) error {
+	s.docs = append(s.docs, docs...)
+	return nil
+}
+
+func (s *recordingSyncer) Delete(_ context.Context, docType string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if s.deleted == nil {
+		s.deleted = map[string][]int64{}
+	}
+	s.deleted[docType] = append(s.deleted[docType], ids...)
+	return nil
+}
+
+// TestFieldEncryption_SearchIndexWithoutPlaintext tests that encrypted fields are not copied to an external search index
+func TestFieldEncryption_SearchIndexWithoutPlaintext(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	useKeyRing(t, "v1:"+testKey(1), "")
+
+	user, _ := f.CreateUser("encryptindex@example.com")
+	todo := f.CreateTodoWithDetails(user.ID, "Doctor", testutil.TodoOptions{Description: util.Ptr("Blood test results")})
+	f.CreateComment(user.ID, todo.ID, "Call the clinic")
+
+	syncer := &recordingSyncer{}
+	syncService := service.NewSearchSyncService(repository.NewSearchRepository(f.DB), syncer)
+	require.NoError(t, syncService.Run(context.Background(), time.Now()))
+
+	types := map[string]bool{}
+	for _, doc := range syncer.docs {
+		types[doc.Type] = true
+		assert.Empty(t, doc.Body, doc.Type)
+	}
+	assert.True(t, types[search.TypeTodo])
+	assert.True(t, types[search.TypeComment])
+}
+
+// TestReencryption_RotatesKey tests that the re-encryption job moves old and plaintext values to the active key
+func TestReencryption_RotatesKey(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("rotate@example.com")
+	plain := f.CreateTodoWithDetails(user.ID, "Written before encryption", testutil.TodoOptions{Description: util.Ptr("plain text")})
+
+	useKeyRing(t, "v1:"+testKey(1), "")
+	old := f.CreateTodoWithDetails(user.ID, "Written with v1", testutil.TodoOptions{Description: util.Ptr("old key")})
+	assert.True(t, strings.HasPrefix(storedValue(t, f, "todos", "description", old.ID), "enc:v1:"))
+
+	useKeyRing(t, "v1:"+testKey(1)+",v2:"+testKey(2), "v2")
+	reencryptionService := service.NewReencryptionService(repository.NewEncryptionRepository(f.DB))
+	require.NoError(t, reencryptionService.Run(context.Background(), time.Now()))
+
+	for _, todo := range []struct {
+		id          int64
+		description string
+	}{{plain.ID, "plain text"}, {old.ID, "old key"}} {
+		assert.True(t, strings.HasPrefix(storedValue(t, f, "todos", "description", todo.id), "enc:v2:"))
+
+		// The previous key is no longer needed
+		useKeyRing(t, "v2:"+testKey(2), "")
+		rec, err := f.CallAuth(token, http.MethodGet, testutil.TodoPath(todo.id), "", f.TodoHandler.Show)
+		require.NoError(t, err)
+		assert.Equal(t, todo.description, testutil.JSONResponse(t, rec)["description"])
+	}
+}
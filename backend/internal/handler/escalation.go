@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// EscalationHandler handles priority escalation rule endpoints
+type EscalationHandler struct {
+	escalationService *service.EscalationService
+}
+
+// NewEscalationHandler creates a new EscalationHandler
+func NewEscalationHandler(escalationService *service.EscalationService) *EscalationHandler {
+	return &EscalationHandler{escalationService: escalationService}
+}
+
+// CreateEscalationRuleRequest represents the request body for creating a rule
+type CreateEscalationRuleRequest struct {
+	WithinHours *int    `json:"within_hours" validate:"required,gte=1,lte=720"`
+	Priority    *string `json:"priority" validate:"omitempty,oneof=medium high"`
+	Notify      *bool   `json:"notify"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+// UpdateEscalationRuleRequest represents the request body for updating a rule
+type UpdateEscalationRuleRequest struct {
+	WithinHours *int    `json:"within_hours" validate:"omitempty,gte=1,lte=720"`
+	Priority    *string `json:"priority" validate:"omitempty,oneof=medium high"`
+	Notify      *bool   `json:"notify"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+// EscalationRuleResponse represents a rule in API responses
+type EscalationRuleResponse struct {
+	ID          int64  `json:"id"`
+	WithinHours int    `json:"within_hours"`
+	Priority    string `json:"priority"`
+	Notify      bool   `json:"notify"`
+	Enabled     bool   `json:"enabled"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// EscalationPreviewResponse represents a todo a rule would escalate
+type EscalationPreviewResponse struct {
+	TodoID       int64  `json:"todo_id"`
+	Title        string `json:"title"`
+	DueDate      string `json:"due_date"`
+	FromPriority string `json:"from_priority"`
+	ToPriority   string `json:"to_priority"`
+	RuleID       int64  `json:"rule_id"`
+}
+
+// toEscalationRuleResponse converts a model.EscalationRule to EscalationRuleResponse
+func toEscalationRuleResponse(rule *model.EscalationRule) EscalationRuleResponse {
+	return EscalationRuleResponse{
+		ID:          rule.ID,
+		WithinHours: rule.WithinHours,
+		Priority:    rule.Priority.String(),
+		Notify:      rule.Notify,
+		Enabled:     rule.Enabled,
+		CreatedAt:   util.FormatRFC3339(rule.CreatedAt),
+		UpdatedAt:   util.FormatRFC3339(rule.UpdatedAt),
+	}
+}
+
+// List retrieves the current user's escalation rules
+// GET /api/v1/users/me/escalation_rules
+func (h *EscalationHandler) List(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	rules, err := h.escalationService.List(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]EscalationRuleResponse, len(rules))
+	for i := range rules {
+		resp[i] = toEscalationRuleResponse(&rules[i])
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Create adds an escalation rule
+// POST /api/v1/users/me/escalation_rules
+func (h *EscalationHandler) Create(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var req CreateEscalationRuleRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	rule, err := h.escalationService.Create(c.Request().Context(), currentUser.ID, service.EscalationRuleInput{
+		WithinHours: req.WithinHours,
+		Priority:    req.Priority,
+		Notify:      req.Notify,
+		Enabled:     req.Enabled,
+	})
+	if err != nil {
+		return err
+	}
+
+	return response.Created(c, toEscalationRuleResponse(rule))
+}
+
+// Update updates an escalation rule
+// PATCH /api/v1/users/me/escalation_rules/:id
+func (h *EscalationHandler) Update(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := ParseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req UpdateEscalationRuleRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	rule, err := h.escalationService.Update(c.Request().Context(), id, currentUser.ID, service.EscalationRuleInput{
+		WithinHours: req.WithinHours,
+		Priority:    req.Priority,
+		Notify:      req.Notify,
+		Enabled:     req.Enabled,
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("EscalationRule", id)
+		}
+		return err
+	}
+
+	return response.OK(c, toEscalationRuleResponse(rule))
+}
+
+// Delete removes an escalation rule
+// DELETE /api/v1/users/me/escalation_rules/:id
+func (h *EscalationHandler) Delete(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := ParseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.escalationService.Delete(c.Request().Context(), id, currentUser.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("EscalationRule", id)
+		}
+		return errors.InternalErrorWithLog(err, "EscalationHandler.Delete: failed to delete rule")
+	}
+
+	return response.NoContent(c)
+}
+
+// Preview shows which todos the enabled rules would escalate right now
+// GET /api/v1/users/me/escalation_rules/preview
+func (h *EscalationHandler) Preview(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	escalations, err := h.escalationService.Preview(c.Request().Context(), currentUser.ID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	resp := make([]EscalationPreviewResponse, len(escalations))
+	for i, e := range escalations {
+		resp[i] = EscalationPreviewResponse{
+			TodoID:       e.Todo.ID,
+			Title:        e.Todo.Title,
+			DueDate:      util.DerefString(util.FormatDate(e.Todo.DueDate), ""),
+			FromPriority: e.From.String(),
+			ToPriority:   e.To.String(),
+			RuleID:       e.Rule.ID,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,158 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/mailer"
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+const escalationRulesPath = "/api/v1/users/me/escalation_rules"
+
+// dueIn returns today's date shifted by the given number of days, as stored in due_date
+func dueIn(days int) *time.Time {
+	now := time.Now().UTC()
+	date := time.Date(now.Year(), now.Month(), now.Day()+days, 0, 0, 0, 0, time.UTC)
+	return &date
+}
+
+// TestEscalationRuleCreate_Defaults tests that a new rule raises to high, notifies, and is enabled
+func TestEscalationRuleCreate_Defaults(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("escalationcreate@example.com")
+
+	rec, err := f.CallAuth(token, http.MethodPost, escalationRulesPath, `{"within_hours":24}`, f.EscalationHandler.Create)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(24), response["within_hours"])
+	assert.Equal(t, "high", response["priority"])
+	assert.Equal(t, true, response["notify"])
+	assert.Equal(t, true, response["enabled"])
+}
+
+// TestEscalationRuleCreate_ValidationError tests invalid rule settings
+func TestEscalationRuleCreate_ValidationError(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("escalationinvalid@example.com")
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing within_hours", body: `{"priority":"high"}`},
+		{name: "zero hours", body: `{"within_hours":0}`},
+		{name: "too many hours", body: `{"within_hours":721}`},
+		{name: "low priority", body: `{"within_hours":24,"priority":"low"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.CallAuth(token, http.MethodPost, escalationRulesPath, tt.body, f.EscalationHandler.Create)
+			require.Error(t, err)
+		})
+	}
+}
+
+// TestEscalationRuleUpdateAndDelete tests changing and removing a rule, and that other users' rules are not found
+func TestEscalationRuleUpdateAndDelete(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("escalationupdate@example.com")
+	_, otherToken := f.CreateUser("escalationother@example.com")
+
+	rec, err := f.CallAuth(token, http.MethodPost, escalationRulesPath, `{"within_hours":24}`, f.EscalationHandler.Create)
+	require.NoError(t, err)
+	path := fmt.Sprintf("%s/%v", escalationRulesPath, testutil.JSONResponse(t, rec)["id"])
+
+	rec, err = f.CallAuth(token, http.MethodPatch, path, `{"within_hours":72,"priority":"medium","notify":false}`, f.EscalationHandler.Update)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(72), response["within_hours"])
+	assert.Equal(t, "medium", response["priority"])
+	assert.Equal(t, false, response["notify"])
+
+	_, err = f.CallAuth(otherToken, http.MethodDelete, path, "", f.EscalationHandler.Delete)
+	require.Error(t, err)
+
+	_, err = f.CallAuth(token, http.MethodDelete, path, "", f.EscalationHandler.Delete)
+	require.NoError(t, err)
+
+	rec, err = f.CallAuth(token, http.MethodGet, escalationRulesPath, "", f.EscalationHandler.List)
+	require.NoError(t, err)
+	assert.Empty(t, testutil.JSONArrayResponse(t, rec))
+}
+
+// TestEscalationPreview tests that only todos within a rule's window and below its priority are listed
+func TestEscalationPreview(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("escalationpreview@example.com")
+	dueToday := f.CreateTodoWithDetails(user.ID, "Due today", testutil.TodoOptions{DueDate: dueIn(0)})
+	f.CreateTodoWithDetails(user.ID, "Already high", testutil.TodoOptions{DueDate: dueIn(0), Priority: model.PriorityHigh})
+	f.CreateTodoWithDetails(user.ID, "Due later", testutil.TodoOptions{DueDate: dueIn(10)})
+	f.CreateTodo(user.ID, "No due date")
+
+	// No rules, nothing is escalated
+	rec, err := f.CallAuth(token, http.MethodGet, escalationRulesPath+"/preview", "", f.EscalationHandler.Preview)
+	require.NoError(t, err)
+	assert.Empty(t, testutil.JSONArrayResponse(t, rec))
+
+	_, err = f.CallAuth(token, http.MethodPost, escalationRulesPath, `{"within_hours":24}`, f.EscalationHandler.Create)
+	require.NoError(t, err)
+
+	rec, err = f.CallAuth(token, http.MethodGet, escalationRulesPath+"/preview", "", f.EscalationHandler.Preview)
+	require.NoError(t, err)
+
+	items := testutil.JSONArrayResponse(t, rec)
+	require.Len(t, items, 1)
+	item := items[0].(map[string]any)
+	assert.Equal(t, float64(dueToday.ID), item["todo_id"])
+	assert.Equal(t, "medium", item["from_priority"])
+	assert.Equal(t, "high", item["to_priority"])
+}
+
+// TestEscalationRun tests that the job raises the priority once and records it in history
+func TestEscalationRun(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("escalationrun@example.com")
+	todo := f.CreateTodoWithDetails(user.ID, "Due tomorrow", testutil.TodoOptions{DueDate: dueIn(1)})
+
+	_, err := f.CallAuth(token, http.MethodPost, escalationRulesPath, `{"within_hours":72}`, f.EscalationHandler.Create)
+	require.NoError(t, err)
+
+	escalationService := service.NewEscalationService(f.EscalationRepo, f.PreferenceRepo, f.TodoRepo, f.HistoryRepo, mailer.NewLogMailer())
+	require.NoError(t, escalationService.Run(context.Background(), time.Now()))
+
+	updated, err := f.TodoRepo.FindByID(context.Background(), todo.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.PriorityHigh, updated.Priority)
+
+	histories, _, err := f.HistoryRepo.FindByTodoID(context.Background(), todo.ID, user.ID, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, histories, 1)
+	assert.Equal(t, model.ActionPriorityEscalated, histories[0].Action)
+
+	// Lowering the priority by hand is not undone by the next run
+	require.NoError(t, f.TodoRepo.UpdatePriority(context.Background(), todo.ID, user.ID, model.PriorityMedium))
+	require.NoError(t, escalationService.Run(context.Background(), time.Now()))
+
+	updated, err = f.TodoRepo.FindByID(context.Background(), todo.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.PriorityMedium, updated.Priority)
+}
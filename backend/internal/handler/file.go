@@ -33,6 +33,10 @@ type FileResponse struct {
 	ThumbURL     *string `json:"thumb_url,omitempty"`
 	MediumURL    *string `json:"medium_url,omitempty"`
 	DownloadURL  string  `json:"download_url"`
+	ScanStatus   string  `json:"scan_status"`
+	Signature    *string `json:"scan_signature,omitempty"`
+	ScannedAt    *string `json:"scanned_at"`
+	Quarantined  bool    `json:"quarantined"`
 	CreatedAt    string  `json:"created_at"`
 }
 
@@ -45,9 +49,17 @@ func toFileResponse(file *model.File, todoID int64) FileResponse {
 		FileSize:     file.FileSize,
 		FileType:     string(file.FileType),
 		DownloadURL:  fmt.Sprintf("/api/v1/todos/%d/files/%d", todoID, file.ID),
+		ScanStatus:   string(file.ScanStatus),
+		Signature:    file.ScanSignature,
+		Quarantined:  file.IsQuarantined(),
 		CreatedAt:    util.FormatRFC3339(file.CreatedAt),
 	}
 
+	if file.ScannedAt != nil {
+		scannedAt := util.FormatRFC3339(*file.ScannedAt)
+		resp.ScannedAt = &scannedAt
+	}
+
 	if file.ThumbPath != nil {
 		thumbURL := fmt.Sprintf("/api/v1/todos/%d/files/%d/thumb", todoID, file.ID)
 		resp.ThumbURL = &thumbURL
@@ -0,0 +1,205 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/scanner"
+	"todo-api/internal/service"
+	"todo-api/internal/storage"
+	"todo-api/internal/testutil"
+)
+
+// memoryStorage keeps objects in memory and can be made to fail uploads under a prefix
+type memoryStorage struct {
+	objects    map[string][]byte
+	failPrefix string
+	// onUpload, if set, runs after each upload
+	onUpload func(key string)
+	// downloadErr, if set, fails downloads of stored objects
+	downloadErr error
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{objects: map[string][]byte{}}
+}
+
+func (s *memoryStorage) Upload(_ context.Context, key string, reader io.Reader, _ int64, contentType string) (*storage.UploadResult, error) {
+	if s.failPrefix != "" && strings.HasPrefix(key, s.failPrefix) {
+		return nil, errors.New("storage unavailable")
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	s.objects[key] = data
+	if s.onUpload != nil {
+		s.onUpload(key)
+	}
+	return &storage.UploadResult{Path: key, ContentType: contentType, Size: int64(len(data))}, nil
+}
+
+func (s *memoryStorage) Download(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	if s.downloadErr != nil {
+		return nil, s.downloadErr
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memoryStorage) Delete(_ context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memoryStorage) GetURL(_ context.Context, key string, _ time.Duration) (string, error) {
+	return "memory://" + key, nil
+}
+
+func (s *memoryStorage) Exists(_ context.Context, key string) (bool, error) {
+	_, ok := s.objects[key]
+	return ok, nil
+}
+
+// infectedScanner reports every file as infected
+type infectedScanner struct{}
+
+func (infectedScanner) Name() string { return "test" }
+
+func (infectedScanner) Scan(_ context.Context, _ io.Reader) (*scanner.Result, error) {
+	return &scanner.Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+}
+
+// createStoredFile stores content and records a file attached to the todo
+func createStoredFile(t *testing.T, f *testutil.TestFixture, store *memoryStorage, todo *model.Todo, status model.ScanStatus) *model.File {
+	t.Helper()
+	path := "uploads/test/" + string(status) + ".txt"
+	store.objects[path] = []byte("content")
+	file := &model.File{
+		UserID:         todo.UserID,
+		AttachableType: model.AttachableTypeTodo,
+		AttachableID:   todo.ID,
+		OriginalName:   string(status) + ".txt",
+		StoragePath:    path,
+		ContentType:    "text/plain",
+		FileSize:       7,
+		FileType:       model.FileTypeDocument,
+		ScanStatus:     status,
+	}
+	require.NoError(t, repository.NewFileRepository(f.DB).Create(context.Background(), file))
+	return file
+}
+
+// TestFileScan_QuarantineFailure tests that an infected file is not served when quarantining it fails,
+// and that the quarantine is retried
+func TestFileScan_QuarantineFailure(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, _ := f.CreateUser("quarantine@example.com")
+	todo := f.CreateTodo(user.ID, "Attachments")
+
+	store := newMemoryStorage()
+	store.failPrefix = "quarantine/"
+	fileRepo := repository.NewFileRepository(f.DB)
+	scanService := service.NewFileScanService(fileRepo, store, infectedScanner{})
+	fileService := service.NewFileService(fileRepo, f.TodoRepo, store, nil, scanService)
+
+	file := createStoredFile(t, f, store, todo, model.ScanStatusPending)
+	err := scanService.Scan(context.Background(), file, []byte("content"))
+	require.Error(t, err)
+
+	saved, err := fileRepo.FindByID(context.Background(), file.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ScanStatusInfected, saved.ScanStatus)
+	assert.Nil(t, saved.QuarantinedAt)
+
+	_, _, err = fileService.Download(context.Background(), file.ID, todo.ID, user.ID)
+	assertAPIError(t, err, http.StatusForbidden)
+	_, _, err = fileService.DownloadThumbnail(context.Background(), file.ID, todo.ID, user.ID, "thumb")
+	assertAPIError(t, err, http.StatusForbidden)
+
+	// The next job run quarantines the file once storage recovers
+	store.failPrefix = ""
+	require.NoError(t, scanService.RescanPending(context.Background(), time.Now().UTC().Add(time.Hour)))
+
+	saved, err = fileRepo.FindByID(context.Background(), file.ID, user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, saved.QuarantinedAt)
+	assert.Equal(t, "quarantine/"+file.StoragePath, saved.StoragePath)
+	assert.NotContains(t, store.objects, file.StoragePath)
+}
+
+// TestFileScan_DeletedFile tests that a scan finishing after the file was deleted leaves no quarantine copy behind
+func TestFileScan_DeletedFile(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, _ := f.CreateUser("quarantinedeleted@example.com")
+	todo := f.CreateTodo(user.ID, "Attachments")
+
+	store := newMemoryStorage()
+	fileRepo := repository.NewFileRepository(f.DB)
+	scanService := service.NewFileScanService(fileRepo, store, infectedScanner{})
+
+	// Deleted before the scan finished
+	deleted := createStoredFile(t, f, store, todo, model.ScanStatusPending)
+	require.NoError(t, fileRepo.Delete(context.Background(), deleted.ID, user.ID))
+	require.NoError(t, scanService.Scan(context.Background(), deleted, []byte("content")))
+	assert.NotContains(t, store.objects, "quarantine/"+deleted.StoragePath)
+
+	// Deleted while being quarantined
+	racing := createStoredFile(t, f, store, todo, model.ScanStatusError)
+	store.onUpload = func(string) {
+		require.NoError(t, fileRepo.Delete(context.Background(), racing.ID, user.ID))
+	}
+	require.NoError(t, scanService.Scan(context.Background(), racing, []byte("content")))
+	assert.NotContains(t, store.objects, "quarantine/uploads/test/"+string(model.ScanStatusError)+".txt")
+}
+
+// TestFileDownload_ScanStatus tests which scan states are served
+func TestFileDownload_ScanStatus(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, _ := f.CreateUser("scanstatus@example.com")
+	todo := f.CreateTodo(user.ID, "Attachments")
+
+	store := newMemoryStorage()
+	fileRepo := repository.NewFileRepository(f.DB)
+	fileService := service.NewFileService(fileRepo, f.TodoRepo, store, nil, service.NewFileScanService(fileRepo, store, nil))
+
+	tests := []struct {
+		status model.ScanStatus
+		code   int
+	}{
+		{model.ScanStatusClean, http.StatusOK},
+		{model.ScanStatusSkipped, http.StatusOK},
+		{model.ScanStatusPending, http.StatusConflict},
+		{model.ScanStatusError, http.StatusConflict},
+		{model.ScanStatusInfected, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			file := createStoredFile(t, f, store, todo, tt.status)
+			reader, _, err := fileService.Download(context.Background(), file.ID, todo.ID, user.ID)
+			if tt.code == http.StatusOK {
+				require.NoError(t, err)
+				reader.Close()
+				return
+			}
+			assertAPIError(t, err, tt.code)
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// FocusSessionHandler handles focus (Pomodoro) session endpoints
+type FocusSessionHandler struct {
+	focusService *service.FocusService
+}
+
+// NewFocusSessionHandler creates a new FocusSessionHandler
+func NewFocusSessionHandler(focusService *service.FocusService) *FocusSessionHandler {
+	return &FocusSessionHandler{focusService: focusService}
+}
+
+// StartFocusSessionRequest represents the request body for starting a focus session
+type StartFocusSessionRequest struct {
+	TodoID          int64 `json:"todo_id" validate:"required"`
+	DurationMinutes *int  `json:"duration_minutes" validate:"omitempty,gte=1,lte=240"`
+}
+
+// FocusSessionResponse represents a focus session in API responses
+type FocusSessionResponse struct {
+	ID              int64   `json:"id"`
+	TodoID          int64   `json:"todo_id"`
+	StartedAt       string  `json:"started_at"`
+	EndedAt         *string `json:"ended_at"`
+	PlannedSeconds  int     `json:"planned_seconds"`
+	DurationSeconds int     `json:"duration_seconds"`
+	Completed       bool    `json:"completed"`
+	Active          bool    `json:"active"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+// FocusSessionListResponse represents a list of focus sessions with pagination
+type FocusSessionListResponse struct {
+	Data []FocusSessionResponse `json:"data"`
+	Meta FocusSessionMeta       `json:"meta"`
+}
+
+// FocusSessionMeta represents pagination metadata
+type FocusSessionMeta struct {
+	Total       int64 `json:"total"`
+	CurrentPage int   `json:"current_page"`
+	TotalPages  int   `json:"total_pages"`
+	PerPage     int   `json:"per_page"`
+}
+
+// FocusStatsResponse represents daily focus-time aggregates
+type FocusStatsResponse struct {
+	Data []repository.DailyFocusTotal `json:"data"`
+	Meta FocusStatsMeta               `json:"meta"`
+}
+
+// FocusStatsMeta represents the range covered by a focus stats response
+type FocusStatsMeta struct {
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Timezone     string `json:"timezone"`
+	TotalSeconds int64  `json:"total_seconds"`
+}
+
+// toFocusSessionResponse converts a model.FocusSession to FocusSessionResponse
+func toFocusSessionResponse(session *model.FocusSession) FocusSessionResponse {
+	resp := FocusSessionResponse{
+		ID:              session.ID,
+		TodoID:          session.TodoID,
+		StartedAt:       util.FormatRFC3339(session.StartedAt),
+		PlannedSeconds:  session.PlannedSeconds,
+		DurationSeconds: session.DurationSeconds,
+		Completed:       session.Completed,
+		Active:          session.IsActive(),
+		CreatedAt:       util.FormatRFC3339(session.CreatedAt),
+		UpdatedAt:       util.FormatRFC3339(session.UpdatedAt),
+	}
+	if session.EndedAt != nil {
+		endedAt := util.FormatRFC3339(*session.EndedAt)
+		resp.EndedAt = &endedAt
+	}
+	return resp
+}
+
+// List retrieves the current user's focus sessions, newest first
+// GET /api/v1/focus_sessions?todo_id=&page=&per_page=
+func (h *FocusSessionHandler) List(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var todoID *int64
+	if todoIDStr := c.QueryParam("todo_id"); todoIDStr != "" {
+		id, err := strconv.ParseInt(todoIDStr, 10, 64)
+		if err != nil {
+			return errors.ValidationFailed(map[string][]string{
+				"todo_id": {"Invalid todo_id"},
+			})
+		}
+		todoID = &id
+	}
+
+	page := 1
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	perPage := 20
+	if perPageStr := c.QueryParam("per_page"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= 100 {
+			perPage = pp
+		}
+	}
+
+	sessions, total, err := h.focusService.List(c.Request().Context(), repository.FocusSessionListInput{
+		UserID:  currentUser.ID,
+		TodoID:  todoID,
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		return err
+	}
+
+	data := make([]FocusSessionResponse, len(sessions))
+	for i := range sessions {
+		data[i] = toFocusSessionResponse(&sessions[i])
+	}
+
+	// Calculate total pages
+	totalPages := 0
+	if total > 0 {
+		totalPages = int((total + int64(perPage) - 1) / int64(perPage))
+	}
+
+	return response.OK(c, FocusSessionListResponse{
+		Data: data,
+		Meta: FocusSessionMeta{
+			Total:       total,
+			CurrentPage: page,
+			TotalPages:  totalPages,
+			PerPage:     perPage,
+		},
+	})
+}
+
+// Start begins a focus session for a todo
+// POST /api/v1/focus_sessions
+func (h *FocusSessionHandler) Start(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var req StartFocusSessionRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	session, err := h.focusService.Start(c.Request().Context(), service.StartFocusInput{
+		UserID:          currentUser.ID,
+		TodoID:          req.TodoID,
+		DurationMinutes: req.DurationMinutes,
+	})
+	if err != nil {
+		return err
+	}
+
+	return response.Created(c, toFocusSessionResponse(session))
+}
+
+// Active retrieves the current user's running focus session
+// GET /api/v1/focus_sessions/active
+func (h *FocusSessionHandler) Active(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	session, err := h.focusService.Active(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return response.NoContent(c)
+	}
+
+	return response.OK(c, toFocusSessionResponse(session))
+}
+
+// Stop ends a running focus session
+// POST /api/v1/focus_sessions/:id/stop
+func (h *FocusSessionHandler) Stop(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	sessionID, err := ParseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	session, err := h.focusService.Stop(c.Request().Context(), sessionID, currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, toFocusSessionResponse(session))
+}
+
+// Stats retrieves daily focus-time totals in the user's time zone
+// GET /api/v1/focus_sessions/stats?from=&to=
+func (h *FocusSessionHandler) Stats(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	stats, err := h.focusService.DailyStats(c.Request().Context(), currentUser.ID, c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, FocusStatsResponse{
+		Data: stats.Days,
+		Meta: FocusStatsMeta{
+			From:         stats.From,
+			To:           stats.To,
+			Timezone:     stats.Timezone,
+			TotalSeconds: stats.TotalSeconds,
+		},
+	})
+}
@@ -0,0 +1,150 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/model"
+	"todo-api/internal/testutil"
+)
+
+const focusSessionsPath = "/api/v1/focus_sessions"
+
+// TestFocusSessionStart_Success tests starting a focus session with the default length
+func TestFocusSessionStart_Success(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("focusstart@example.com")
+	todo := f.CreateTodo(user.ID, "Deep work")
+
+	body := fmt.Sprintf(`{"todo_id":%d}`, todo.ID)
+	rec, err := f.CallAuth(token, http.MethodPost, focusSessionsPath, body, f.FocusHandler.Start)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(todo.ID), response["todo_id"])
+	assert.Equal(t, float64(model.DefaultFocusMinutes*60), response["planned_seconds"])
+	assert.Equal(t, true, response["active"])
+	assert.Nil(t, response["ended_at"])
+}
+
+// TestFocusSessionStart_AlreadyActive tests that only one session may run at a time
+func TestFocusSessionStart_AlreadyActive(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("focusactive@example.com")
+	todo := f.CreateTodo(user.ID, "Deep work")
+
+	body := fmt.Sprintf(`{"todo_id":%d,"duration_minutes":50}`, todo.ID)
+	_, err := f.CallAuth(token, http.MethodPost, focusSessionsPath, body, f.FocusHandler.Start)
+	require.NoError(t, err)
+
+	_, err = f.CallAuth(token, http.MethodPost, focusSessionsPath, body, f.FocusHandler.Start)
+	require.Error(t, err)
+}
+
+// TestFocusSessionStart_OtherUsersTodo tests that sessions cannot target another user's todo
+func TestFocusSessionStart_OtherUsersTodo(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	owner, _ := f.CreateUser("focusowner@example.com")
+	_, token := f.CreateUser("focusother@example.com")
+	todo := f.CreateTodo(owner.ID, "Private")
+
+	body := fmt.Sprintf(`{"todo_id":%d}`, todo.ID)
+	_, err := f.CallAuth(token, http.MethodPost, focusSessionsPath, body, f.FocusHandler.Start)
+	require.Error(t, err)
+}
+
+// TestFocusSessionStop_Success tests stopping a running session
+func TestFocusSessionStop_Success(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("focusstop@example.com")
+	todo := f.CreateTodo(user.ID, "Deep work")
+
+	session := &model.FocusSession{
+		UserID:         user.ID,
+		TodoID:         todo.ID,
+		StartedAt:      time.Now().Add(-30 * time.Minute),
+		PlannedSeconds: 25 * 60,
+	}
+	require.NoError(t, f.FocusSessionRepo.Create(context.Background(), session))
+
+	path := fmt.Sprintf("%s/%d/stop", focusSessionsPath, session.ID)
+	params := map[string]string{"id": fmt.Sprint(session.ID)}
+	rec, err := f.CallAuthWithParams(token, http.MethodPost, path, "", params, f.FocusHandler.Stop)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, false, response["active"])
+	assert.Equal(t, true, response["completed"])
+	assert.NotNil(t, response["ended_at"])
+	assert.GreaterOrEqual(t, response["duration_seconds"], float64(30*60))
+
+	// Stopping again is rejected
+	_, err = f.CallAuthWithParams(token, http.MethodPost, path, "", params, f.FocusHandler.Stop)
+	require.Error(t, err)
+}
+
+// TestFocusSessionStats_DailyTotals tests daily aggregation with zero-filled days
+func TestFocusSessionStats_DailyTotals(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("focusstats@example.com")
+	todo := f.CreateTodo(user.ID, "Deep work")
+
+	day := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	for _, startedAt := range []time.Time{day, day.Add(2 * time.Hour)} {
+		session := &model.FocusSession{
+			UserID:         user.ID,
+			TodoID:         todo.ID,
+			StartedAt:      startedAt,
+			PlannedSeconds: 25 * 60,
+		}
+		session.Stop(startedAt.Add(25 * time.Minute))
+		require.NoError(t, f.FocusSessionRepo.Create(context.Background(), session))
+	}
+
+	rec, err := f.CallAuth(token, http.MethodGet, focusSessionsPath+"/stats?from=2025-03-09&to=2025-03-11", "", f.FocusHandler.Stats)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	data := response["data"].([]interface{})
+	require.Len(t, data, 3)
+
+	second := data[1].(map[string]interface{})
+	assert.Equal(t, "2025-03-10", second["date"])
+	assert.Equal(t, float64(50*60), second["total_seconds"])
+	assert.Equal(t, float64(2), second["sessions"])
+	assert.Equal(t, float64(2), second["completed_sessions"])
+
+	first := data[0].(map[string]interface{})
+	assert.Equal(t, float64(0), first["total_seconds"])
+
+	meta := response["meta"].(map[string]interface{})
+	assert.Equal(t, "UTC", meta["timezone"])
+	assert.Equal(t, float64(50*60), meta["total_seconds"])
+}
+
+// TestFocusSessionStats_InvalidRange tests validation of the date range
+func TestFocusSessionStats_InvalidRange(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("focusrange@example.com")
+
+	_, err := f.CallAuth(token, http.MethodGet, focusSessionsPath+"/stats?from=2025-03-10&to=2025-03-01", "", f.FocusHandler.Stats)
+	require.Error(t, err)
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/service"
+	"todo-api/pkg/util"
+)
+
+// HealthHandler handles the detailed health endpoint
+type HealthHandler struct {
+	healthService *service.HealthService
+}
+
+// NewHealthHandler creates a new HealthHandler
+func NewHealthHandler(healthService *service.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// HealthDetailsResponse represents the detailed health report
+type HealthDetailsResponse struct {
+	Status        string                   `json:"status"`
+	CheckedAt     string                   `json:"checked_at"`
+	UptimeSeconds int64                    `json:"uptime_seconds"`
+	Build         HealthBuildResponse      `json:"build"`
+	Components    HealthComponentsResponse `json:"components"`
+}
+
+// HealthBuildResponse identifies the running build
+type HealthBuildResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+}
+
+// HealthComponentsResponse represents the health of each component
+type HealthComponentsResponse struct {
+	Database HealthDatabaseResponse `json:"database"`
+	Schema   HealthSchemaResponse   `json:"schema"`
+	Jobs     HealthJobsResponse     `json:"jobs"`
+}
+
+// HealthDatabaseResponse represents the health of the database connection
+type HealthDatabaseResponse struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     *string `json:"error"`
+}
+
+// HealthSchemaResponse represents the health of the database schema
+type HealthSchemaResponse struct {
+	Status        string   `json:"status"`
+	MissingTables []string `json:"missing_tables"`
+	Error         *string  `json:"error"`
+}
+
+// HealthJobsResponse represents the health of the background jobs and the export queue
+type HealthJobsResponse struct {
+	Status                   string              `json:"status"`
+	SchedulerRunning         bool                `json:"scheduler_running"`
+	Jobs                     []HealthJobResponse `json:"jobs"`
+	PendingExports           int64               `json:"pending_exports"`
+	OldestPendingWaitSeconds *int64              `json:"oldest_pending_wait_seconds"`
+	Error                    *string             `json:"error"`
+}
+
+// HealthJobResponse represents the last run of a background job
+type HealthJobResponse struct {
+	Name            string  `json:"name"`
+	IntervalSeconds int64   `json:"interval_seconds"`
+	LastRunAt       *string `json:"last_run_at"`
+	LastError       *string `json:"last_error"`
+}
+
+// Details reports the status of each component; the status code is 503 while the service is down
+// GET /health/details
+func (h *HealthHandler) Details(c echo.Context) error {
+	report := h.healthService.Report(c.Request().Context())
+
+	jobs := make([]HealthJobResponse, len(report.Jobs.Jobs))
+	for i, j := range report.Jobs.Jobs {
+		jobs[i] = HealthJobResponse{
+			Name:            j.Name,
+			IntervalSeconds: int64(j.Interval.Seconds()),
+			LastError:       optionalString(j.LastError),
+		}
+		if j.LastRunAt != nil {
+			lastRunAt := util.FormatRFC3339(*j.LastRunAt)
+			jobs[i].LastRunAt = &lastRunAt
+		}
+	}
+
+	resp := HealthDetailsResponse{
+		Status:        string(report.Status),
+		CheckedAt:     util.FormatRFC3339(report.CheckedAt),
+		UptimeSeconds: int64(report.Uptime.Seconds()),
+		Build: HealthBuildResponse{
+			Version:   report.Build.Version,
+			Commit:    report.Build.Commit,
+			GoVersion: report.GoVersion,
+		},
+		Components: HealthComponentsResponse{
+			Database: HealthDatabaseResponse{
+				Status:    string(report.Database.Status),
+				LatencyMS: float64(report.Database.Latency.Microseconds()) / 1000,
+				Error:     optionalString(report.Database.Error),
+			},
+			Schema: HealthSchemaResponse{
+				Status:        string(report.Schema.Status),
+				MissingTables: report.Schema.MissingTables,
+				Error:         optionalString(report.Schema.Error),
+			},
+			Jobs: HealthJobsResponse{
+				Status:           string(report.Jobs.Status),
+				SchedulerRunning: report.Jobs.SchedulerRunning,
+				Jobs:             jobs,
+				PendingExports:   report.Jobs.PendingExports,
+				Error:            optionalString(report.Jobs.Error),
+			},
+		},
+	}
+	if report.Jobs.OldestPendingWait != nil {
+		wait := int64(report.Jobs.OldestPendingWait.Seconds())
+		resp.Components.Jobs.OldestPendingWaitSeconds = &wait
+	}
+
+	status := http.StatusOK
+	if report.Status == service.HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, resp)
+}
+
+// optionalString returns nil for an empty string
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
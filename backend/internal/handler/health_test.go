@@ -0,0 +1,157 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/config"
+	"todo-api/internal/handler"
+	"todo-api/internal/job"
+	"todo-api/internal/middleware"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+// unmigratedModel is a model whose table is never created
+type unmigratedModel struct {
+	ID int64
+}
+
+// TableName returns the table name for the unmigratedModel model
+func (unmigratedModel) TableName() string {
+	return "health_test_unmigrated"
+}
+
+// healthDetails calls the health handler from remoteAddr, through the network check and the admin auth
+func healthDetails(f *testutil.TestFixture, svc *service.HealthService, cfg *config.Config, networks, remoteAddr, token string) (*httptest.ResponseRecorder, error) {
+	req := httptest.NewRequest(http.MethodGet, "/health/details", nil)
+	req.RemoteAddr = remoteAddr
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	rec := httptest.NewRecorder()
+	c := f.Echo.NewContext(req, rec)
+
+	jwtAuth := middleware.JWTAuth(cfg, f.UserRepo, f.DenylistRepo)
+	adminAuth := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return jwtAuth(middleware.RequireAdmin(cfg)(next))
+	}
+	allowed := config.Config{HealthDetailsAllowedNetworks: networks}
+	h := middleware.RequireNetworkOrAdmin(allowed.GetHealthConfig().AllowedNetworks, adminAuth)(handler.NewHealthHandler(svc).Details)
+	return rec, h(c)
+}
+
+// TestHealthDetails tests the component report and the overall status
+func TestHealthDetails(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	user, _ := f.CreateUser("exporter@example.com")
+	healthRepo := repository.NewHealthRepository(f.DB)
+	build := service.BuildInfo{Version: "1.2.3", Commit: "abc123"}
+
+	// All tables exist and nothing is queued
+	svc := service.NewHealthService(healthRepo, f.DataExportRepo, nil, []any{&model.User{}, &model.Todo{}}, build)
+	rec, err := healthDetails(f, svc, testutil.TestConfig, "127.0.0.0/8", "127.0.0.1:5000", "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	resp := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "ok", resp["status"])
+	assert.Equal(t, map[string]any{"version": "1.2.3", "commit": "abc123", "go_version": resp["build"].(map[string]any)["go_version"]}, resp["build"])
+	components := resp["components"].(map[string]any)
+	assert.Equal(t, "ok", components["database"].(map[string]any)["status"])
+	assert.Equal(t, []any{}, components["schema"].(map[string]any)["missing_tables"])
+	jobs := components["jobs"].(map[string]any)
+	assert.Equal(t, false, jobs["scheduler_running"])
+	assert.Equal(t, float64(0), jobs["pending_exports"])
+	assert.Nil(t, jobs["oldest_pending_wait_seconds"])
+
+	// An export waiting too long degrades the job queue
+	require.NoError(t, f.DB.Create(&model.DataExport{
+		UserID:    user.ID,
+		Status:    model.DataExportStatusPending,
+		CreatedAt: time.Now().Add(-service.DataExportBacklogMaxAge - time.Minute),
+	}).Error)
+	rec, err = healthDetails(f, svc, testutil.TestConfig, "127.0.0.0/8", "127.0.0.1:5000", "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	resp = testutil.JSONResponse(t, rec)
+	assert.Equal(t, "degraded", resp["status"])
+	jobs = resp["components"].(map[string]any)["jobs"].(map[string]any)
+	assert.Equal(t, "degraded", jobs["status"])
+	assert.Equal(t, float64(1), jobs["pending_exports"])
+	assert.Greater(t, jobs["oldest_pending_wait_seconds"], float64(service.DataExportBacklogMaxAge.Seconds()))
+
+	// A missing table takes the service down
+	svc = service.NewHealthService(healthRepo, nil, nil, []any{&model.User{}, &unmigratedModel{}}, build)
+	rec, err = healthDetails(f, svc, testutil.TestConfig, "127.0.0.0/8", "127.0.0.1:5000", "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	resp = testutil.JSONResponse(t, rec)
+	assert.Equal(t, "down", resp["status"])
+	assert.Equal(t, []any{"health_test_unmigrated"}, resp["components"].(map[string]any)["schema"].(map[string]any)["missing_tables"])
+}
+
+// TestHealthDetails_FailingJob tests reporting the last run of the scheduled jobs
+func TestHealthDetails_FailingJob(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	scheduler := job.NewScheduler()
+	scheduler.Register("broken", 10*time.Millisecond, func(ctx context.Context, now time.Time) error {
+		return fmt.Errorf("mail server unreachable")
+	})
+	scheduler.Start(context.Background())
+	require.Eventually(t, func() bool { return scheduler.Statuses()[0].LastRunAt != nil }, time.Second, 10*time.Millisecond)
+	scheduler.Stop()
+
+	svc := service.NewHealthService(repository.NewHealthRepository(f.DB), nil, scheduler, []any{&model.User{}}, service.BuildInfo{Version: "dev"})
+	rec, err := healthDetails(f, svc, testutil.TestConfig, "127.0.0.0/8", "127.0.0.1:5000", "")
+	require.NoError(t, err)
+	resp := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "degraded", resp["status"])
+	jobs := resp["components"].(map[string]any)["jobs"].(map[string]any)
+	assert.Equal(t, true, jobs["scheduler_running"])
+	broken := jobs["jobs"].([]any)[0].(map[string]any)
+	assert.Equal(t, "broken", broken["name"])
+	assert.Equal(t, "mail server unreachable", broken["last_error"])
+	assert.NotNil(t, broken["last_run_at"])
+}
+
+// TestHealthDetails_Access tests allowing the configured networks and admins only
+func TestHealthDetails_Access(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	cfg := *testutil.TestConfig
+	cfg.AdminEmails = "admin@example.com"
+	_, adminToken := f.CreateUser("admin@example.com")
+	_, userToken := f.CreateUser("user@example.com")
+	svc := service.NewHealthService(repository.NewHealthRepository(f.DB), nil, nil, []any{&model.User{}}, service.BuildInfo{Version: "dev"})
+
+	rec, err := healthDetails(f, svc, &cfg, "10.0.0.0/8", "10.1.2.3:5000", "")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Outside the networks, only admins are allowed
+	_, err = healthDetails(f, svc, &cfg, "10.0.0.0/8", "192.0.2.1:5000", "")
+	assertAPIError(t, err, http.StatusUnauthorized)
+	_, err = healthDetails(f, svc, &cfg, "10.0.0.0/8", "192.0.2.1:5000", userToken)
+	assertAPIError(t, err, http.StatusForbidden)
+	rec, err = healthDetails(f, svc, &cfg, "10.0.0.0/8", "192.0.2.1:5000", adminToken)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// X-Forwarded-For is not trusted
+	req := httptest.NewRequest(http.MethodGet, "/health/details", nil)
+	req.Header.Set(echo.HeaderXForwardedFor, "10.1.2.3")
+	c := f.Echo.NewContext(req, httptest.NewRecorder())
+	networks := config.Config{HealthDetailsAllowedNetworks: "10.0.0.0/8"}
+	err = middleware.RequireNetworkOrAdmin(networks.GetHealthConfig().AllowedNetworks, nil)(handler.NewHealthHandler(svc).Details)(c)
+	assertAPIError(t, err, http.StatusForbidden)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/middleware"
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// IntegrationHandler handles endpoints managing the integrations that sign requests
+type IntegrationHandler struct {
+	integrationService *service.IntegrationService
+}
+
+// NewIntegrationHandler creates a new IntegrationHandler
+func NewIntegrationHandler(integrationService *service.IntegrationService) *IntegrationHandler {
+	return &IntegrationHandler{integrationService: integrationService}
+}
+
+// CreateIntegrationRequest represents the request body for creating an integration
+type CreateIntegrationRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// IntegrationResponse represents an integration in API responses
+type IntegrationResponse struct {
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	KeyID      string  `json:"key_id"`
+	LastUsedAt *string `json:"last_used_at"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// CreateIntegrationResponse represents a new integration along with its secret, shown only once
+type CreateIntegrationResponse struct {
+	IntegrationResponse
+	Secret string `json:"secret"`
+}
+
+// toIntegrationResponse converts a model.Integration to IntegrationResponse
+func toIntegrationResponse(integration *model.Integration) IntegrationResponse {
+	resp := IntegrationResponse{
+		ID:        integration.ID,
+		Name:      integration.Name,
+		KeyID:     integration.KeyID,
+		CreatedAt: util.FormatRFC3339(integration.CreatedAt),
+	}
+	if integration.LastUsedAt != nil {
+		lastUsedAt := util.FormatRFC3339(*integration.LastUsedAt)
+		resp.LastUsedAt = &lastUsedAt
+	}
+	return resp
+}
+
+// List retrieves the current user's integrations
+// GET /api/v1/users/me/integrations
+func (h *IntegrationHandler) List(c echo.Context) error {
+	currentUser, err := getUserManagingIntegrations(c)
+	if err != nil {
+		return err
+	}
+
+	integrations, err := h.integrationService.List(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]IntegrationResponse, len(integrations))
+	for i := range integrations {
+		resp[i] = toIntegrationResponse(&integrations[i])
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Create adds an integration and returns its secret
+// POST /api/v1/users/me/integrations
+func (h *IntegrationHandler) Create(c echo.Context) error {
+	currentUser, err := getUserManagingIntegrations(c)
+	if err != nil {
+		return err
+	}
+
+	var req CreateIntegrationRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	integration, secret, err := h.integrationService.Create(c.Request().Context(), currentUser.ID, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return response.Created(c, CreateIntegrationResponse{
+		IntegrationResponse: toIntegrationResponse(integration),
+		Secret:              secret,
+	})
+}
+
+// Delete removes an integration
+// DELETE /api/v1/users/me/integrations/:id
+func (h *IntegrationHandler) Delete(c echo.Context) error {
+	currentUser, err := getUserManagingIntegrations(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := ParseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.integrationService.Delete(c.Request().Context(), id, currentUser.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("Integration", id)
+		}
+		return errors.InternalErrorWithLog(err, "IntegrationHandler.Delete: failed to delete integration")
+	}
+
+	return response.NoContent(c)
+}
+
+// getUserManagingIntegrations returns the current user, unless the request is signed by an integration:
+// integrations cannot create others or outlive their own deletion
+func getUserManagingIntegrations(c echo.Context) (*middleware.CurrentUser, error) {
+	if middleware.GetIntegration(c) != nil {
+		return nil, errors.AuthorizationFailed("integrations", "manage")
+	}
+	return GetCurrentUserOrFail(c)
+}
@@ -0,0 +1,198 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/handler"
+	"todo-api/internal/middleware"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+// signedRequest is a request signed with an integration's key and secret
+type signedRequest struct {
+	keyID     string
+	secret    string
+	timestamp time.Time
+	nonce     string
+}
+
+// callSigned calls h through SignatureAuth with a request signed by the integration.
+// The body sent can differ from the signed one to simulate tampering.
+func callSigned(f *testutil.TestFixture, svc *service.IntegrationService, s signedRequest, method, uri, signedBody, body string, h echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+	timestamp := strconv.FormatInt(s.timestamp.Unix(), 10)
+
+	req := httptest.NewRequest(method, uri, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(middleware.SignatureKeyHeader, s.keyID)
+	req.Header.Set(middleware.SignatureTimestampHeader, timestamp)
+	req.Header.Set(middleware.SignatureNonceHeader, s.nonce)
+	req.Header.Set(middleware.SignatureHeader, service.SignRequest(s.secret, timestamp, s.nonce, method, uri, []byte(signedBody)))
+	rec := httptest.NewRecorder()
+	c := f.Echo.NewContext(req, rec)
+
+	auth := middleware.SignatureAuth(svc, f.UserRepo, middleware.JWTAuth(testutil.TestConfig, f.UserRepo, f.DenylistRepo))
+	return rec, auth(h)(c)
+}
+
+// assertAPIError asserts that err is an API error with the given status
+func assertAPIError(t *testing.T, err error, status int) {
+	t.Helper()
+	require.Error(t, err)
+	apiErr, ok := err.(*errors.ApiError)
+	require.True(t, ok)
+	assert.Equal(t, status, apiErr.StatusCode)
+}
+
+// TestIntegration_SignedRequests tests authenticating signed requests and rejecting replayed or altered ones
+func TestIntegration_SignedRequests(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	user, token := f.CreateUser("integrated@example.com")
+	svc := service.NewIntegrationService(repository.NewIntegrationRepository(f.DB))
+	integrationHandler := handler.NewIntegrationHandler(svc)
+
+	rec, err := f.CallAuth(token, http.MethodPost, "/api/v1/users/me/integrations", `{"name":"Billing"}`, integrationHandler.Create)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	created := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "Billing", created["name"])
+	assert.Len(t, created["secret"], 64)
+	s := signedRequest{
+		keyID:     created["key_id"].(string),
+		secret:    created["secret"].(string),
+		timestamp: time.Now(),
+		nonce:     "nonce-0000000001",
+	}
+
+	body := `{"title":"From billing"}`
+	rec, err = callSigned(f, svc, s, http.MethodPost, "/api/v1/todos", body, body, f.TodoHandler.Create)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	todos, err := f.TodoRepo.FindAllByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	assert.Equal(t, "From billing", todos[0].Title)
+
+	// The same request cannot be replayed
+	_, err = callSigned(f, svc, s, http.MethodPost, "/api/v1/todos", body, body, f.TodoHandler.Create)
+	assertAPIError(t, err, http.StatusUnauthorized)
+
+	// Altered body, stale timestamp, unknown key and wrong secret are rejected
+	s.nonce = "nonce-0000000002"
+	_, err = callSigned(f, svc, s, http.MethodPost, "/api/v1/todos", body, `{"title":"Altered"}`, f.TodoHandler.Create)
+	assertAPIError(t, err, http.StatusUnauthorized)
+
+	stale := s
+	stale.timestamp = time.Now().Add(-service.IntegrationSignatureMaxSkew - time.Minute)
+	_, err = callSigned(f, svc, stale, http.MethodGet, "/api/v1/todos", "", "", f.TodoHandler.List)
+	assertAPIError(t, err, http.StatusUnauthorized)
+
+	unknown := s
+	unknown.keyID = "000000000000000000000000"
+	_, err = callSigned(f, svc, unknown, http.MethodGet, "/api/v1/todos", "", "", f.TodoHandler.List)
+	assertAPIError(t, err, http.StatusUnauthorized)
+
+	wrongSecret := s
+	wrongSecret.secret = strings.Repeat("0", 64)
+	_, err = callSigned(f, svc, wrongSecret, http.MethodGet, "/api/v1/todos", "", "", f.TodoHandler.List)
+	assertAPIError(t, err, http.StatusUnauthorized)
+
+	// A rejected signature does not use up the nonce
+	rec, err = callSigned(f, svc, s, http.MethodGet, "/api/v1/todos?per_page=5", "", "", f.TodoHandler.List)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Integrations cannot manage integrations
+	s.nonce = "nonce-0000000003"
+	_, err = callSigned(f, svc, s, http.MethodGet, "/api/v1/users/me/integrations", "", "", integrationHandler.List)
+	assertAPIError(t, err, http.StatusForbidden)
+
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/users/me/integrations", "", integrationHandler.List)
+	require.NoError(t, err)
+	integrations := testutil.JSONArrayResponse(t, rec)
+	require.Len(t, integrations, 1)
+	listed := integrations[0].(map[string]any)
+	assert.NotNil(t, listed["last_used_at"])
+	assert.NotContains(t, listed, "secret")
+
+	// Deleted integrations can no longer sign requests
+	id := strconv.FormatInt(int64(listed["id"].(float64)), 10)
+	rec, err = f.CallAuthWithParams(token, http.MethodDelete, "/api/v1/users/me/integrations/"+id, "",
+		map[string]string{"id": id}, integrationHandler.Delete)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	s.nonce = "nonce-0000000004"
+	_, err = callSigned(f, svc, s, http.MethodGet, "/api/v1/todos", "", "", f.TodoHandler.List)
+	assertAPIError(t, err, http.StatusUnauthorized)
+}
+
+// TestIntegration_Create_Validation tests validating integration names
+func TestIntegration_Create_Validation(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	_, token := f.CreateUser("integrated@example.com")
+	integrationHandler := handler.NewIntegrationHandler(service.NewIntegrationService(repository.NewIntegrationRepository(f.DB)))
+
+	for _, body := range []string{`{"name":"  "}`, `{"name":"` + strings.Repeat("a", 101) + `"}`} {
+		_, err := f.CallAuth(token, http.MethodPost, "/api/v1/users/me/integrations", body, integrationHandler.Create)
+		assertAPIError(t, err, http.StatusUnprocessableEntity)
+	}
+}
+
+// TestIntegration_RestrictedRoutes tests that integrations cannot use admin and account management routes
+func TestIntegration_RestrictedRoutes(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	cfg := *testutil.TestConfig
+	cfg.AdminEmails = "admin@example.com"
+
+	_, token := f.CreateUser("admin@example.com")
+	svc := service.NewIntegrationService(repository.NewIntegrationRepository(f.DB))
+	integrationHandler := handler.NewIntegrationHandler(svc)
+	usageHandler := handler.NewUsageHandler(usageService(f, 0))
+
+	rec, err := f.CallAuth(token, http.MethodPost, "/api/v1/users/me/integrations", `{"name":"Ops"}`, integrationHandler.Create)
+	require.NoError(t, err)
+	created := testutil.JSONResponse(t, rec)
+	s := signedRequest{
+		keyID:     created["key_id"].(string),
+		secret:    created["secret"].(string),
+		timestamp: time.Now(),
+		nonce:     "nonce-0000000001",
+	}
+
+	// The admin's integration is not an admin
+	adminOnly := middleware.RequireAdmin(&cfg)
+	_, err = callSigned(f, svc, s, http.MethodGet, "/api/v1/admin/usage/api", "", "", adminOnly(usageHandler.Rollup))
+	assertAPIError(t, err, http.StatusForbidden)
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/admin/usage/api", "", adminOnly(usageHandler.Rollup))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Account management routes are for the user only
+	userOnly := middleware.RejectIntegrations("integrations")
+	s.nonce = "nonce-0000000002"
+	body := `{"name":"Escalated"}`
+	_, err = callSigned(f, svc, s, http.MethodPost, "/api/v1/users/me/integrations", body, body, userOnly(integrationHandler.Create))
+	assertAPIError(t, err, http.StatusForbidden)
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/users/me/integrations", "", userOnly(integrationHandler.List))
+	require.NoError(t, err)
+	assert.Len(t, testutil.JSONArrayResponse(t, rec), 1)
+
+	// Bodies read to verify the signature are limited
+	s.nonce = "nonce-0000000003"
+	large := `{"title":"` + strings.Repeat("a", middleware.MaxSignedBodySize) + `"}`
+	_, err = callSigned(f, svc, s, http.MethodPost, "/api/v1/todos", large, large, f.TodoHandler.Create)
+	assertAPIError(t, err, http.StatusRequestEntityTooLarge)
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+)
+
+// MyDayHandler handles My Day planning endpoints
+type MyDayHandler struct {
+	myDayService *service.MyDayService
+}
+
+// NewMyDayHandler creates a new MyDayHandler
+func NewMyDayHandler(myDayService *service.MyDayService) *MyDayHandler {
+	return &MyDayHandler{myDayService: myDayService}
+}
+
+// UpdateMyDayRequest represents the request body for changing today's plan
+type UpdateMyDayRequest struct {
+	Add    []int64 `json:"add" validate:"max=100"`
+	Remove []int64 `json:"remove" validate:"max=100"`
+}
+
+// MyDayResponse represents today's plan in API responses
+type MyDayResponse struct {
+	Date        string                   `json:"date"`
+	Todos       []TodoResponse           `json:"todos"`
+	Suggestions MyDaySuggestionsResponse `json:"suggestions"`
+}
+
+// MyDaySuggestionsResponse represents the todos suggested for today's plan
+type MyDaySuggestionsResponse struct {
+	DueToday       []TodoResponse `json:"due_today"`
+	Overdue        []TodoResponse `json:"overdue"`
+	RecentlyActive []TodoResponse `json:"recently_active"`
+}
+
+// toMyDayResponse converts a service.MyDay to MyDayResponse
+func toMyDayResponse(day *service.MyDay) MyDayResponse {
+	return MyDayResponse{
+		Date:  day.Date.Format("2006-01-02"),
+		Todos: toTodoResponses(day.Todos),
+		Suggestions: MyDaySuggestionsResponse{
+			DueToday:       toTodoResponses(day.Suggestions.DueToday),
+			Overdue:        toTodoResponses(day.Suggestions.Overdue),
+			RecentlyActive: toTodoResponses(day.Suggestions.RecentlyActive),
+		},
+	}
+}
+
+// Show retrieves today's plan and suggestions
+// GET /api/v1/my_day
+func (h *MyDayHandler) Show(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	day, err := h.myDayService.Get(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, toMyDayResponse(day))
+}
+
+// Update adds todos to and removes todos from today's plan
+// POST /api/v1/my_day
+func (h *MyDayHandler) Update(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateMyDayRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	day, err := h.myDayService.Update(c.Request().Context(), currentUser.ID, service.UpdateMyDayInput{
+		Add:    req.Add,
+		Remove: req.Remove,
+	})
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, toMyDayResponse(day))
+}
+
+// toTodoResponses converts todos to responses
+func toTodoResponses(todos []model.Todo) []TodoResponse {
+	resp := make([]TodoResponse, len(todos))
+	for i := range todos {
+		resp[i] = toTodoResponse(&todos[i])
+	}
+	return resp
+}
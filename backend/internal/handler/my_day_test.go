@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/testutil"
+)
+
+const myDayPath = "/api/v1/my_day"
+
+// TestMyDayShow_Suggestions tests that today's plan starts empty and due or overdue todos are suggested
+func TestMyDayShow_Suggestions(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("mydayshow@example.com")
+	dueToday := f.CreateTodoWithDetails(user.ID, "Due today", testutil.TodoOptions{DueDate: dueIn(0)})
+	overdue := f.CreateTodoWithDetails(user.ID, "Overdue", testutil.TodoOptions{DueDate: dueIn(-2)})
+	recent := f.CreateTodo(user.ID, "Recently created")
+
+	rec, err := f.CallAuth(token, http.MethodGet, myDayPath, "", f.MyDayHandler.Show)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, time.Now().UTC().Format("2006-01-02"), response["date"])
+	assert.Empty(t, response["todos"])
+
+	suggestions := response["suggestions"].(map[string]any)
+	assert.Equal(t, []int64{dueToday.ID}, todoIDsOf(suggestions["due_today"]))
+	assert.Equal(t, []int64{overdue.ID}, todoIDsOf(suggestions["overdue"]))
+	// Due and overdue todos are not repeated as recently active
+	assert.Equal(t, []int64{recent.ID}, todoIDsOf(suggestions["recently_active"]))
+}
+
+// TestMyDayUpdate_AddAndRemove tests planning todos for today
+func TestMyDayUpdate_AddAndRemove(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("mydayupdate@example.com")
+	first := f.CreateTodo(user.ID, "First")
+	second := f.CreateTodo(user.ID, "Second")
+
+	body := fmt.Sprintf(`{"add":[%d,%d,%d]}`, second.ID, first.ID, second.ID)
+	rec, err := f.CallAuth(token, http.MethodPost, myDayPath, body, f.MyDayHandler.Update)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, []int64{second.ID, first.ID}, todoIDsOf(response["todos"]))
+	// Planned todos are no longer suggested
+	suggestions := response["suggestions"].(map[string]any)
+	assert.Empty(t, suggestions["recently_active"])
+
+	body = fmt.Sprintf(`{"remove":[%d]}`, second.ID)
+	_, err = f.CallAuth(token, http.MethodPost, myDayPath, body, f.MyDayHandler.Update)
+	require.NoError(t, err)
+
+	// The plan is persisted
+	rec, err = f.CallAuth(token, http.MethodGet, myDayPath, "", f.MyDayHandler.Show)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{first.ID}, todoIDsOf(testutil.JSONResponse(t, rec)["todos"]))
+}
+
+// TestMyDayUpdate_OtherUsersTodo tests that todos of other users cannot be planned
+func TestMyDayUpdate_OtherUsersTodo(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("mydayowner@example.com")
+	other, _ := f.CreateUser("mydayother@example.com")
+	todo := f.CreateTodo(other.ID, "Not mine")
+
+	body := fmt.Sprintf(`{"add":[%d]}`, todo.ID)
+	_, err := f.CallAuth(token, http.MethodPost, myDayPath, body, f.MyDayHandler.Update)
+	require.Error(t, err)
+}
+
+// todoIDsOf returns the IDs of a JSON array of todos
+func todoIDsOf(value any) []int64 {
+	ids := []int64{}
+	for _, item := range value.([]any) {
+		ids = append(ids, int64(item.(map[string]any)["id"].(float64)))
+	}
+	return ids
+}
@@ -138,7 +138,7 @@ func (h *NoteHandler) List(c echo.Context) error {
 	}
 
 	// Search notes
-	notes, total, err := h.noteRepo.Search(repository.NoteSearchInput{
+	notes, total, err := h.noteRepo.Search(c.Request().Context(), repository.NoteSearchInput{
 		UserID:   currentUser.ID,
 		Query:    query,
 		Archived: archived,
@@ -208,7 +208,7 @@ func (h *NoteHandler) Create(c echo.Context) error {
 		return err
 	}
 
-	note, err := h.noteService.Create(service.CreateNoteInput{
+	note, err := h.noteService.Create(c.Request().Context(), service.CreateNoteInput{
 		UserID: currentUser.ID,
 		Title:  req.Title,
 		BodyMD: req.BodyMD,
@@ -258,7 +258,7 @@ func (h *NoteHandler) Show(c echo.Context) error {
 		return err
 	}
 
-	note, err := h.noteRepo.FindByIDIncludingTrashed(noteID, currentUser.ID)
+	note, err := h.noteRepo.FindByIDIncludingTrashed(c.Request().Context(), noteID, currentUser.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Note", noteID)
@@ -307,7 +307,7 @@ func (h *NoteHandler) Update(c echo.Context) error {
 	}
 
 	// Check note exists
-	exists, err := h.noteRepo.ExistsByID(noteID, currentUser.ID)
+	exists, err := h.noteRepo.ExistsByID(c.Request().Context(), noteID, currentUser.ID)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "NoteHandler.Update: failed to check note existence")
 	}
@@ -320,7 +320,7 @@ func (h *NoteHandler) Update(c echo.Context) error {
 		return err
 	}
 
-	note, err := h.noteService.Update(noteID, currentUser.ID, service.UpdateNoteInput{
+	note, err := h.noteService.Update(c.Request().Context(), noteID, currentUser.ID, service.UpdateNoteInput{
 		Title:    req.Title,
 		BodyMD:   req.BodyMD,
 		Pinned:   req.Pinned,
@@ -373,7 +373,7 @@ func (h *NoteHandler) Delete(c echo.Context) error {
 
 	force := c.QueryParam("force") == "true"
 
-	if err := h.noteService.Delete(noteID, currentUser.ID, force); err != nil {
+	if err := h.noteService.Delete(c.Request().Context(), noteID, currentUser.ID, force); err != nil {
 		return err
 	}
 
@@ -394,7 +394,7 @@ func (h *NoteHandler) ListRevisions(c echo.Context) error {
 	}
 
 	// Check note exists and belongs to user
-	exists, err := h.noteRepo.ExistsByID(noteID, currentUser.ID)
+	exists, err := h.noteRepo.ExistsByID(c.Request().Context(), noteID, currentUser.ID)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "NoteHandler.ListRevisions: failed to check note existence")
 	}
@@ -417,7 +417,7 @@ func (h *NoteHandler) ListRevisions(c echo.Context) error {
 		}
 	}
 
-	revisions, total, err := h.revisionRepo.FindByNoteID(noteID, page, perPage)
+	revisions, total, err := h.revisionRepo.FindByNoteID(c.Request().Context(), noteID, currentUser.ID, page, perPage)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "NoteHandler.ListRevisions: failed to fetch revisions")
 	}
@@ -469,7 +469,7 @@ func (h *NoteHandler) RestoreRevision(c echo.Context) error {
 		return err
 	}
 
-	note, err := h.noteService.RestoreRevision(noteID, revisionID, currentUser.ID)
+	note, err := h.noteService.RestoreRevision(c.Request().Context(), noteID, revisionID, currentUser.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Note", noteID)
@@ -1,6 +1,7 @@
 package handler_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -187,7 +188,7 @@ func TestNoteCreate_CreatesInitialRevision(t *testing.T) {
 	noteID := resp.ID
 
 	// Check revision was created
-	revisions, total, err := f.NoteRevisionRepo.FindByNoteID(noteID, 1, 10)
+	revisions, total, err := f.NoteRevisionRepo.FindByNoteID(context.Background(), noteID, user.ID, 1, 10)
 	require.NoError(t, err)
 	assert.Equal(t, int64(1), total)
 	assert.Equal(t, "My Note", *revisions[0].Title)
@@ -273,7 +274,7 @@ func TestNoteUpdate_BodyMD_CreatesRevision(t *testing.T) {
 	note := f.CreateNote(user.ID, "Note", "Original Body")
 
 	// Initial revision exists
-	revisions, _, _ := f.NoteRevisionRepo.FindByNoteID(note.ID, 1, 10)
+	revisions, _, _ := f.NoteRevisionRepo.FindByNoteID(context.Background(), note.ID, user.ID, 1, 10)
 	initialCount := len(revisions)
 
 	// Update body
@@ -282,7 +283,7 @@ func TestNoteUpdate_BodyMD_CreatesRevision(t *testing.T) {
 	require.NoError(t, err)
 
 	// Check new revision created
-	revisions, _, _ = f.NoteRevisionRepo.FindByNoteID(note.ID, 1, 10)
+	revisions, _, _ = f.NoteRevisionRepo.FindByNoteID(context.Background(), note.ID, user.ID, 1, 10)
 	assert.Equal(t, initialCount+1, len(revisions))
 }
 
@@ -293,7 +294,7 @@ func TestNoteUpdate_TitleOnly_NoRevision(t *testing.T) {
 	note := f.CreateNote(user.ID, "Note", "Body")
 
 	// Get initial revision count
-	revisions, _, _ := f.NoteRevisionRepo.FindByNoteID(note.ID, 1, 10)
+	revisions, _, _ := f.NoteRevisionRepo.FindByNoteID(context.Background(), note.ID, user.ID, 1, 10)
 	initialCount := len(revisions)
 
 	// Update title only
@@ -302,7 +303,7 @@ func TestNoteUpdate_TitleOnly_NoRevision(t *testing.T) {
 	require.NoError(t, err)
 
 	// Check no new revision
-	revisions, _, _ = f.NoteRevisionRepo.FindByNoteID(note.ID, 1, 10)
+	revisions, _, _ = f.NoteRevisionRepo.FindByNoteID(context.Background(), note.ID, user.ID, 1, 10)
 	assert.Equal(t, initialCount, len(revisions))
 }
 
@@ -432,7 +433,7 @@ func TestNoteRestoreRevision_Success(t *testing.T) {
 	require.NoError(t, err)
 
 	// Get revisions
-	revisions, _, _ := f.NoteRevisionRepo.FindByNoteID(note.ID, 1, 10)
+	revisions, _, _ := f.NoteRevisionRepo.FindByNoteID(context.Background(), note.ID, user.ID, 1, 10)
 	require.GreaterOrEqual(t, len(revisions), 1)
 
 	// Restore to first revision (oldest)
@@ -471,7 +472,7 @@ func TestNote_RevisionLimit_EnforcedAt50(t *testing.T) {
 	}
 
 	// Check revision count is <= 50
-	count, err := f.NoteRevisionRepo.CountByNoteID(note.ID)
+	count, err := f.NoteRevisionRepo.CountByNoteID(context.Background(), note.ID, user.ID)
 	require.NoError(t, err)
 	assert.LessOrEqual(t, count, int64(model.MaxRevisionsPerNote))
 }
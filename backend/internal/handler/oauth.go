@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/service"
+)
+
+// OAuthHandler handles the OAuth 2.0 authorization code flow with PKCE
+type OAuthHandler struct {
+	oauthService *service.OAuthService
+}
+
+// NewOAuthHandler creates a new OAuthHandler
+func NewOAuthHandler(oauthService *service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+// AuthorizeRequest represents the parameters of an authorization request
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type" query:"response_type"`
+	ClientID            string `json:"client_id" query:"client_id"`
+	RedirectURI         string `json:"redirect_uri" query:"redirect_uri"`
+	CodeChallenge       string `json:"code_challenge" query:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" query:"code_challenge_method"`
+	State               string `json:"state" query:"state" validate:"max=500"`
+}
+
+// AuthorizeResponse represents the redirect that delivers the authorization code to the client
+type AuthorizeResponse struct {
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// TokenResponse represents the response of the token endpoint (RFC 6749 section 5.1)
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// OAuthErrorResponse represents an error of the token endpoint (RFC 6749 section 5.2)
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// AuthorizePage validates an authorization request from a native app and sends the browser
+// to the frontend page that signs the user in and approves it
+// GET /oauth/authorize
+func (h *OAuthHandler) AuthorizePage(c echo.Context) error {
+	var req AuthorizeRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if err := h.oauthService.ValidateAuthorization(authorizationRequest(req)); err != nil {
+		return err
+	}
+	return c.Redirect(http.StatusFound, h.oauthService.LoginURL(c.QueryParams()))
+}
+
+// Authorize issues an authorization code to the client for the signed-in user
+// POST /oauth/authorize
+func (h *OAuthHandler) Authorize(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var req AuthorizeRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	redirectURI, err := h.oauthService.Authorize(c.Request().Context(), currentUser.ID, authorizationRequest(req))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, AuthorizeResponse{RedirectURI: redirectURI})
+}
+
+// Token exchanges an authorization code or a refresh token for tokens.
+// Parameters are form-encoded as required by RFC 6749.
+// POST /oauth/token
+func (h *OAuthHandler) Token(c echo.Context) error {
+	var (
+		tokens *service.OAuthTokens
+		err    error
+	)
+	switch grantType := c.FormValue("grant_type"); grantType {
+	case "authorization_code":
+		tokens, err = h.oauthService.ExchangeCode(
+			c.Request().Context(),
+			c.FormValue("client_id"),
+			c.FormValue("code"),
+			c.FormValue("redirect_uri"),
+			c.FormValue("code_verifier"),
+		)
+	case "refresh_token":
+		tokens, err = h.oauthService.Refresh(c.Request().Context(), c.FormValue("client_id"), c.FormValue("refresh_token"))
+	default:
+		err = &service.OAuthError{Code: service.OAuthUnsupportedGrantType, Description: "grant_type must be authorization_code or refresh_token"}
+	}
+	if err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	setNoStore(c)
+	return c.JSON(http.StatusOK, TokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    tokens.ExpiresIn,
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+// Revoke revokes a refresh token and the tokens refreshed from the same grant (RFC 7009)
+// POST /oauth/revoke
+func (h *OAuthHandler) Revoke(c echo.Context) error {
+	if err := h.oauthService.Revoke(c.Request().Context(), c.FormValue("client_id"), c.FormValue("token")); err != nil {
+		return oauthErrorResponse(c, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// authorizationRequest converts the request parameters for the service
+func authorizationRequest(req AuthorizeRequest) service.AuthorizationRequest {
+	return service.AuthorizationRequest{
+		ResponseType:        req.ResponseType,
+		ClientID:            req.ClientID,
+		RedirectURI:         req.RedirectURI,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		State:               req.State,
+	}
+}
+
+// oauthErrorResponse writes an OAuth error in the format of RFC 6749; other errors go to the API error handler
+func oauthErrorResponse(c echo.Context, err error) error {
+	oauthErr, ok := err.(*service.OAuthError)
+	if !ok {
+		return err
+	}
+	status := http.StatusBadRequest
+	if oauthErr.Code == service.OAuthInvalidClient {
+		status = http.StatusUnauthorized
+	}
+	setNoStore(c)
+	return c.JSON(status, OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+}
+
+// setNoStore prevents caching of responses carrying tokens
+func setNoStore(c echo.Context) {
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+}
@@ -0,0 +1,265 @@
+package handler_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/config"
+	"todo-api/internal/errors"
+	"todo-api/internal/handler"
+	"todo-api/internal/middleware"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+const (
+	oauthClientID    = "mobile"
+	oauthRedirectURI = "com.example.todo:/oauth/callback"
+	oauthVerifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+// oauthHandler returns an OAuthHandler with one registered client
+func oauthHandler(f *testutil.TestFixture) *handler.OAuthHandler {
+	oauthService := service.NewOAuthService(
+		repository.NewOAuthRepository(f.DB),
+		f.UserRepo,
+		service.NewAuthService(f.UserRepo, f.DenylistRepo, testutil.TestConfig),
+		&config.OAuthConfig{
+			Clients:         map[string][]string{oauthClientID: {oauthRedirectURI}},
+			LoginURL:        "http://localhost:3000/oauth/authorize",
+			RefreshTokenTTL: 24 * time.Hour,
+		},
+	)
+	return handler.NewOAuthHandler(oauthService)
+}
+
+// codeChallenge returns the S256 challenge of a code verifier
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// postForm calls an OAuth handler with form-encoded parameters
+func postForm(f *testutil.TestFixture, path string, params url.Values, h echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(params.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	return rec, h(f.Echo.NewContext(req, rec))
+}
+
+// authorize returns an authorization code issued to the test client
+func authorize(t *testing.T, f *testutil.TestFixture, h *handler.OAuthHandler, token string) string {
+	body := `{"response_type":"code","client_id":"` + oauthClientID + `","redirect_uri":"` + oauthRedirectURI +
+		`","code_challenge":"` + codeChallenge(oauthVerifier) + `","code_challenge_method":"S256","state":"xyz"}`
+	rec, err := f.CallAuth(token, http.MethodPost, "/oauth/authorize", body, h.Authorize)
+	require.NoError(t, err)
+
+	redirect, err := url.Parse(testutil.JSONResponse(t, rec)["redirect_uri"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", redirect.Query().Get("state"))
+	require.NotEmpty(t, redirect.Query().Get("code"))
+	return redirect.Query().Get("code")
+}
+
+// TestOAuth_AuthorizationCodeWithPKCE tests the code exchange, refresh token rotation, and refresh token reuse detection
+func TestOAuth_AuthorizationCodeWithPKCE(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	_, token := f.CreateUser("mobile@example.com")
+	h := oauthHandler(f)
+
+	code := authorize(t, f, h, token)
+	exchange := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {oauthClientID},
+		"code":          {code},
+		"redirect_uri":  {oauthRedirectURI},
+		"code_verifier": {strings.Repeat("a", 43)},
+	}
+
+	// A wrong verifier is rejected
+	rec, err := postForm(f, "/oauth/token", exchange, h.Token)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid_grant", testutil.JSONResponse(t, rec)["error"])
+
+	exchange.Set("code_verifier", oauthVerifier)
+	rec, err = postForm(f, "/oauth/token", exchange, h.Token)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get(echo.HeaderCacheControl))
+	tokens := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "Bearer", tokens["token_type"])
+	firstRefresh := tokens["refresh_token"].(string)
+
+	// The access token authenticates API requests
+	rec, err = f.CallAuth("Bearer "+tokens["access_token"].(string), http.MethodGet, "/api/v1/todos", "", f.TodoHandler.List)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Refresh tokens are bound to the client and rotated
+	refresh := url.Values{"grant_type": {"refresh_token"}, "client_id": {"other"}, "refresh_token": {firstRefresh}}
+	rec, err = postForm(f, "/oauth/token", refresh, h.Token)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "invalid_client", testutil.JSONResponse(t, rec)["error"])
+
+	refresh.Set("client_id", oauthClientID)
+	rec, err = postForm(f, "/oauth/token", refresh, h.Token)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	secondRefresh := testutil.JSONResponse(t, rec)["refresh_token"].(string)
+	assert.NotEqual(t, firstRefresh, secondRefresh)
+
+	// Reusing a replaced refresh token revokes the whole family
+	rec, err = postForm(f, "/oauth/token", refresh, h.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_grant", testutil.JSONResponse(t, rec)["error"])
+
+	refresh.Set("refresh_token", secondRefresh)
+	rec, err = postForm(f, "/oauth/token", refresh, h.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_grant", testutil.JSONResponse(t, rec)["error"])
+}
+
+// TestOAuth_CodeReuse tests that the code is single-use and that reusing it revokes the tokens issued for it,
+// including those rotated since and the access tokens issued with them
+func TestOAuth_CodeReuse(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	_, token := f.CreateUser("codereuse@example.com")
+	h := oauthHandler(f)
+	authenticated := middleware.JWTAuth(testutil.TestConfig, f.UserRepo, f.DenylistRepo)(f.TodoHandler.List)
+
+	exchange := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {oauthClientID},
+		"code":          {authorize(t, f, h, token)},
+		"redirect_uri":  {oauthRedirectURI},
+		"code_verifier": {oauthVerifier},
+	}
+	rec, err := postForm(f, "/oauth/token", exchange, h.Token)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	issued := testutil.JSONResponse(t, rec)
+	firstAccess := "Bearer " + issued["access_token"].(string)
+	rec, err = f.CallAuth(firstAccess, http.MethodGet, "/api/v1/todos", "", authenticated)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	refresh := url.Values{"grant_type": {"refresh_token"}, "client_id": {oauthClientID}, "refresh_token": {issued["refresh_token"].(string)}}
+	rec, err = postForm(f, "/oauth/token", refresh, h.Token)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	refresh.Set("refresh_token", testutil.JSONResponse(t, rec)["refresh_token"].(string))
+
+	// Tokens issued for another code are not affected
+	otherRec, err := postForm(f, "/oauth/token", url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {oauthClientID},
+		"code":          {authorize(t, f, h, token)},
+		"redirect_uri":  {oauthRedirectURI},
+		"code_verifier": {oauthVerifier},
+	}, h.Token)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, otherRec.Code)
+
+	rec, err = postForm(f, "/oauth/token", exchange, h.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_grant", testutil.JSONResponse(t, rec)["error"])
+
+	// The access token issued for the code no longer authenticates
+	_, err = f.CallAuth(firstAccess, http.MethodGet, "/api/v1/todos", "", authenticated)
+	require.Error(t, err)
+	apiErr, ok := err.(*errors.ApiError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+
+	rec, err = postForm(f, "/oauth/token", refresh, h.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_grant", testutil.JSONResponse(t, rec)["error"])
+
+	other := testutil.JSONResponse(t, otherRec)
+	rec, err = f.CallAuth("Bearer "+other["access_token"].(string), http.MethodGet, "/api/v1/todos", "", authenticated)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec, err = postForm(f, "/oauth/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {oauthClientID},
+		"refresh_token": {other["refresh_token"].(string)},
+	}, h.Token)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// TestOAuth_Revoke tests revoking a refresh token
+func TestOAuth_Revoke(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	_, token := f.CreateUser("revoke@example.com")
+	h := oauthHandler(f)
+
+	rec, err := postForm(f, "/oauth/token", url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {oauthClientID},
+		"code":          {authorize(t, f, h, token)},
+		"redirect_uri":  {oauthRedirectURI},
+		"code_verifier": {oauthVerifier},
+	}, h.Token)
+	require.NoError(t, err)
+	refreshToken := testutil.JSONResponse(t, rec)["refresh_token"].(string)
+
+	rec, err = postForm(f, "/oauth/revoke", url.Values{"client_id": {oauthClientID}, "token": {refreshToken}}, h.Revoke)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Unknown tokens are ignored
+	rec, err = postForm(f, "/oauth/revoke", url.Values{"client_id": {oauthClientID}, "token": {"unknown"}}, h.Revoke)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec, err = postForm(f, "/oauth/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {oauthClientID},
+		"refresh_token": {refreshToken},
+	}, h.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_grant", testutil.JSONResponse(t, rec)["error"])
+}
+
+// TestOAuth_AuthorizePage tests validating authorization requests before the login page
+func TestOAuth_AuthorizePage(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	h := oauthHandler(f)
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {oauthClientID},
+		"redirect_uri":          {oauthRedirectURI},
+		"code_challenge":        {codeChallenge(oauthVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+query.Encode(), nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.AuthorizePage(f.Echo.NewContext(req, rec)))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "http://localhost:3000/oauth/authorize?"+query.Encode(), rec.Header().Get(echo.HeaderLocation))
+
+	// Unregistered redirect URIs and plain challenges are rejected
+	query.Set("redirect_uri", "https://evil.example.com/callback")
+	query.Set("code_challenge_method", "plain")
+	req = httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+query.Encode(), nil)
+	err := h.AuthorizePage(f.Echo.NewContext(req, httptest.NewRecorder()))
+	require.Error(t, err)
+	apiErr, ok := err.(*errors.ApiError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// PreferenceHandler handles user preference endpoints
+type PreferenceHandler struct {
+	preferenceService *service.PreferenceService
+}
+
+// NewPreferenceHandler creates a new PreferenceHandler
+func NewPreferenceHandler(preferenceService *service.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{preferenceService: preferenceService}
+}
+
+// UpdatePreferenceRequest represents the request body for updating preferences
+type UpdatePreferenceRequest struct {
+	Timezone        *string `json:"timezone" validate:"omitempty,max=64"`
+	DigestFrequency *string `json:"digest_frequency" validate:"omitempty,oneof=none daily weekly"`
+	DigestHour      *int    `json:"digest_hour" validate:"omitempty,gte=0,lte=23"`
+
+	PurgeTrashedNotesAfterDays     *int `json:"purge_trashed_notes_after_days" validate:"omitempty,gte=0,lte=3650"`
+	ArchiveNotesAfterDays          *int `json:"archive_notes_after_days" validate:"omitempty,gte=0,lte=3650"`
+	ArchiveCompletedTodosAfterDays *int `json:"archive_completed_todos_after_days" validate:"omitempty,gte=0,lte=3650"`
+
+	WIPLimit     *int    `json:"wip_limit" validate:"omitempty,gte=0,lte=100"`
+	WIPLimitMode *string `json:"wip_limit_mode" validate:"omitempty,oneof=reject warn"`
+}
+
+// PreferenceResponse represents user preferences in API responses
+type PreferenceResponse struct {
+	Timezone         string  `json:"timezone"`
+	DigestFrequency  string  `json:"digest_frequency"`
+	DigestHour       int     `json:"digest_hour"`
+	DigestLastSentAt *string `json:"digest_last_sent_at"`
+
+	PurgeTrashedNotesAfterDays     int     `json:"purge_trashed_notes_after_days"`
+	ArchiveNotesAfterDays          int     `json:"archive_notes_after_days"`
+	ArchiveCompletedTodosAfterDays int     `json:"archive_completed_todos_after_days"`
+	RetentionLastRunAt             *string `json:"retention_last_run_at"`
+
+	WIPLimit     int    `json:"wip_limit"`
+	WIPLimitMode string `json:"wip_limit_mode"`
+}
+
+// toPreferenceResponse converts a model.UserPreference to PreferenceResponse
+func toPreferenceResponse(pref *model.UserPreference) PreferenceResponse {
+	resp := PreferenceResponse{
+		Timezone:        pref.Timezone,
+		DigestFrequency: string(pref.DigestFrequency),
+		DigestHour:      pref.DigestHour,
+
+		PurgeTrashedNotesAfterDays:     pref.PurgeTrashedNotesAfterDays,
+		ArchiveNotesAfterDays:          pref.ArchiveNotesAfterDays,
+		ArchiveCompletedTodosAfterDays: pref.ArchiveCompletedTodosAfterDays,
+
+		WIPLimit:     pref.WIPLimit,
+		WIPLimitMode: string(pref.WIPLimitMode),
+	}
+	if pref.DigestLastSentAt != nil {
+		sentAt := util.FormatRFC3339(*pref.DigestLastSentAt)
+		resp.DigestLastSentAt = &sentAt
+	}
+	if pref.RetentionLastRunAt != nil {
+		ranAt := util.FormatRFC3339(*pref.RetentionLastRunAt)
+		resp.RetentionLastRunAt = &ranAt
+	}
+	return resp
+}
+
+// Show retrieves the current user's preferences
+// GET /api/v1/users/me/preferences
+func (h *PreferenceHandler) Show(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	pref, err := h.preferenceService.Get(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, toPreferenceResponse(pref))
+}
+
+// Update updates the current user's preferences
+// PATCH /api/v1/users/me/preferences
+func (h *PreferenceHandler) Update(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdatePreferenceRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	pref, err := h.preferenceService.Update(c.Request().Context(), currentUser.ID, service.UpdatePreferenceInput{
+		Timezone:        req.Timezone,
+		DigestFrequency: req.DigestFrequency,
+		DigestHour:      req.DigestHour,
+
+		PurgeTrashedNotesAfterDays:     req.PurgeTrashedNotesAfterDays,
+		ArchiveNotesAfterDays:          req.ArchiveNotesAfterDays,
+		ArchiveCompletedTodosAfterDays: req.ArchiveCompletedTodosAfterDays,
+
+		WIPLimit:     req.WIPLimit,
+		WIPLimitMode: req.WIPLimitMode,
+	})
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, toPreferenceResponse(pref))
+}
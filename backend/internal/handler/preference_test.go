@@ -0,0 +1,91 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/testutil"
+)
+
+const preferencesPath = "/api/v1/users/me/preferences"
+
+// TestPreferenceShow_Defaults tests that defaults are returned before preferences are saved
+func TestPreferenceShow_Defaults(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("prefdefaults@example.com")
+
+	rec, err := f.CallAuth(token, http.MethodGet, preferencesPath, "", f.PreferenceHandler.Show)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "UTC", response["timezone"])
+	assert.Equal(t, "none", response["digest_frequency"])
+	assert.Equal(t, float64(8), response["digest_hour"])
+	assert.Nil(t, response["digest_last_sent_at"])
+}
+
+// TestPreferenceUpdate_Success tests opting in to the digest
+func TestPreferenceUpdate_Success(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("prefupdate@example.com")
+
+	body := `{"timezone":"Asia/Tokyo","digest_frequency":"daily","digest_hour":7}`
+	rec, err := f.CallAuth(token, http.MethodPatch, preferencesPath, body, f.PreferenceHandler.Update)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "Asia/Tokyo", response["timezone"])
+	assert.Equal(t, "daily", response["digest_frequency"])
+	assert.Equal(t, float64(7), response["digest_hour"])
+
+	// Verify persisted
+	pref, err := f.PreferenceRepo.FindByUserID(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.True(t, pref.DigestEnabled())
+}
+
+// TestPreferenceUpdate_InvalidTimezone tests validation of timezone names
+func TestPreferenceUpdate_InvalidTimezone(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("preftz@example.com")
+
+	body := `{"timezone":"Mars/Olympus_Mons"}`
+	_, err := f.CallAuth(token, http.MethodPatch, preferencesPath, body, f.PreferenceHandler.Update)
+	require.Error(t, err)
+}
+
+// TestPreferenceUpdate_ValidationError tests invalid digest settings
+func TestPreferenceUpdate_ValidationError(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("prefvalidation@example.com")
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "invalid frequency", body: `{"digest_frequency":"hourly"}`},
+		{name: "hour too large", body: `{"digest_hour":24}`},
+		{name: "negative hour", body: `{"digest_hour":-1}`},
+		{name: "wip limit too large", body: `{"wip_limit":101}`},
+		{name: "invalid wip limit mode", body: `{"wip_limit_mode":"ignore"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.CallAuth(token, http.MethodPatch, preferencesPath, tt.body, f.PreferenceHandler.Update)
+			require.Error(t, err)
+		})
+	}
+}
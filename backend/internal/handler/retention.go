@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// RetentionHandler handles data retention endpoints
+type RetentionHandler struct {
+	retentionService *service.RetentionService
+}
+
+// NewRetentionHandler creates a new RetentionHandler
+func NewRetentionHandler(retentionService *service.RetentionService) *RetentionHandler {
+	return &RetentionHandler{retentionService: retentionService}
+}
+
+// RetentionPreviewResponse represents the effect of the retention rules in API responses
+type RetentionPreviewResponse struct {
+	PurgeTrashedNotes     RetentionRuleResponse `json:"purge_trashed_notes"`
+	ArchiveNotes          RetentionRuleResponse `json:"archive_notes"`
+	ArchiveCompletedTodos RetentionRuleResponse `json:"archive_completed_todos"`
+}
+
+// RetentionRuleResponse represents the effect of a single rule
+type RetentionRuleResponse struct {
+	Enabled bool                    `json:"enabled"`
+	Days    int                     `json:"days"`
+	Cutoff  *string                 `json:"cutoff"`
+	Total   int                     `json:"total"`
+	Items   []RetentionItemResponse `json:"items"`
+}
+
+// RetentionItemResponse represents a todo or note affected by a rule
+type RetentionItemResponse struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	Date  string `json:"date"`
+}
+
+// Preview shows which todos and notes the retention rules would affect right now.
+// Query parameters override the saved rules so they can be tried before saving.
+// GET /api/v1/users/me/retention/preview
+func (h *RetentionHandler) Preview(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var overrides service.RetentionRuleOverrides
+	params := []struct {
+		name   string
+		target **int
+	}{
+		{"purge_trashed_notes_after_days", &overrides.PurgeTrashedNotesAfterDays},
+		{"archive_notes_after_days", &overrides.ArchiveNotesAfterDays},
+		{"archive_completed_todos_after_days", &overrides.ArchiveCompletedTodosAfterDays},
+	}
+	for _, p := range params {
+		str := c.QueryParam(p.name)
+		if str == "" {
+			continue
+		}
+		days, err := strconv.Atoi(str)
+		if err != nil {
+			return errors.ValidationFailed(map[string][]string{
+				p.name: {"must be an integer"},
+			})
+		}
+		*p.target = &days
+	}
+
+	preview, err := h.retentionService.Preview(c.Request().Context(), currentUser.ID, overrides)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, RetentionPreviewResponse{
+		PurgeTrashedNotes:     toRetentionRuleResponse(preview.PurgeTrashedNotes),
+		ArchiveNotes:          toRetentionRuleResponse(preview.ArchiveNotes),
+		ArchiveCompletedTodos: toRetentionRuleResponse(preview.ArchiveCompletedTodos),
+	})
+}
+
+func toRetentionRuleResponse(effect service.RetentionRuleEffect) RetentionRuleResponse {
+	resp := RetentionRuleResponse{
+		Enabled: effect.Days > 0,
+		Days:    effect.Days,
+		Total:   effect.Total,
+		Items:   make([]RetentionItemResponse, len(effect.Items)),
+	}
+	if effect.Cutoff != nil {
+		cutoff := util.FormatRFC3339(*effect.Cutoff)
+		resp.Cutoff = &cutoff
+	}
+	for i, item := range effect.Items {
+		resp.Items[i] = RetentionItemResponse{
+			ID:    item.ID,
+			Title: item.Title,
+			Date:  util.FormatRFC3339(item.Date),
+		}
+	}
+	return resp
+}
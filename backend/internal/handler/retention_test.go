@@ -0,0 +1,185 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+const retentionPreviewPath = "/api/v1/users/me/retention/preview"
+
+// completeTodoAt marks a todo as completed at the given time
+func completeTodoAt(t *testing.T, f *testutil.TestFixture, todo *model.Todo, at time.Time) {
+	t.Helper()
+	require.NoError(t, f.DB.Model(todo).Updates(map[string]any{
+		"completed":    true,
+		"status":       model.StatusCompleted,
+		"completed_at": at,
+	}).Error)
+}
+
+// TestRetentionPreview_Disabled tests that no rule is enabled by default
+func TestRetentionPreview_Disabled(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("retentionoff@example.com")
+	completeTodoAt(t, f, f.CreateTodo(user.ID, "Old"), time.Now().AddDate(0, 0, -365))
+
+	rec, err := f.CallAuth(token, http.MethodGet, retentionPreviewPath, "", f.RetentionHandler.Preview)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	todos := response["archive_completed_todos"].(map[string]any)
+	assert.Equal(t, false, todos["enabled"])
+	assert.Equal(t, float64(0), todos["total"])
+	assert.Nil(t, todos["cutoff"])
+}
+
+// TestRetentionPreview_SavedRules tests that saved rules select only items past the cutoff
+func TestRetentionPreview_SavedRules(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("retentionsaved@example.com")
+	old := f.CreateTodo(user.ID, "Old")
+	completeTodoAt(t, f, old, time.Now().AddDate(0, 0, -100))
+	completeTodoAt(t, f, f.CreateTodo(user.ID, "Recent"), time.Now().AddDate(0, 0, -10))
+	f.CreateTodo(user.ID, "Open")
+
+	_, err := f.CallAuth(token, http.MethodPatch, preferencesPath, `{"archive_completed_todos_after_days":90}`, f.PreferenceHandler.Update)
+	require.NoError(t, err)
+
+	rec, err := f.CallAuth(token, http.MethodGet, retentionPreviewPath, "", f.RetentionHandler.Preview)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	todos := response["archive_completed_todos"].(map[string]any)
+	assert.Equal(t, true, todos["enabled"])
+	assert.Equal(t, float64(90), todos["days"])
+	assert.Equal(t, float64(1), todos["total"])
+
+	items := todos["items"].([]any)
+	require.Len(t, items, 1)
+	assert.Equal(t, float64(old.ID), items[0].(map[string]any)["id"])
+}
+
+// TestRetentionPreview_Overrides tests trying out rules without saving them
+func TestRetentionPreview_Overrides(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("retentionoverride@example.com")
+	completeTodoAt(t, f, f.CreateTodo(user.ID, "Recent"), time.Now().AddDate(0, 0, -10))
+
+	rec, err := f.CallAuth(token, http.MethodGet, retentionPreviewPath+"?archive_completed_todos_after_days=7", "", f.RetentionHandler.Preview)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	todos := response["archive_completed_todos"].(map[string]any)
+	assert.Equal(t, float64(1), todos["total"])
+
+	// Saved preferences are unchanged
+	rec, err = f.CallAuth(token, http.MethodGet, preferencesPath, "", f.PreferenceHandler.Show)
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), testutil.JSONResponse(t, rec)["archive_completed_todos_after_days"])
+}
+
+// TestRetentionPreview_InvalidOverride tests that out-of-range overrides are rejected
+func TestRetentionPreview_InvalidOverride(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("retentioninvalid@example.com")
+
+	for _, query := range []string{"?archive_notes_after_days=abc", "?archive_notes_after_days=-1", "?archive_notes_after_days=99999"} {
+		_, err := f.CallAuth(token, http.MethodGet, retentionPreviewPath+query, "", f.RetentionHandler.Preview)
+		assert.Error(t, err, query)
+	}
+}
+
+// TestRetentionPreview_CompletedWithoutCompletedAt tests that completed todos without completed_at are measured from updated_at
+func TestRetentionPreview_CompletedWithoutCompletedAt(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("retentionnull@example.com")
+	todo := f.CreateTodo(user.ID, "Completed long ago")
+	updatedAt := time.Now().AddDate(0, 0, -100)
+	require.NoError(t, f.DB.Model(todo).UpdateColumns(map[string]any{
+		"completed":    true,
+		"status":       model.StatusCompleted,
+		"completed_at": nil,
+		"updated_at":   updatedAt,
+	}).Error)
+
+	rec, err := f.CallAuth(token, http.MethodGet, retentionPreviewPath+"?archive_completed_todos_after_days=90", "", f.RetentionHandler.Preview)
+	require.NoError(t, err)
+
+	todos := testutil.JSONResponse(t, rec)["archive_completed_todos"].(map[string]any)
+	assert.Equal(t, float64(1), todos["total"])
+	items := todos["items"].([]any)
+	require.Len(t, items, 1)
+	date, err := time.Parse(time.RFC3339, items[0].(map[string]any)["date"].(string))
+	require.NoError(t, err)
+	assert.WithinDuration(t, updatedAt, date, time.Second)
+}
+
+// TestRetentionApply_ArchivesCompletedTodos tests that old completed todos are archived, not deleted
+func TestRetentionApply_ArchivesCompletedTodos(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("retentionarchive@example.com")
+	old := f.CreateTodo(user.ID, "Old")
+	completeTodoAt(t, f, old, time.Now().AddDate(0, 0, -100))
+	completeTodoAt(t, f, f.CreateTodo(user.ID, "Recent"), time.Now().AddDate(0, 0, -10))
+	f.CreateTodo(user.ID, "Open")
+
+	retentionService := service.NewRetentionService(f.PreferenceRepo, f.TodoRepo, f.NoteRepo)
+	result, err := retentionService.Apply(context.Background(), user.ID, service.RetentionRules{ArchiveCompletedTodosAfterDays: 90}, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ArchivedTodos)
+
+	archived, err := f.TodoRepo.FindByID(context.Background(), old.ID, user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, archived.ArchivedAt)
+
+	// The list and search leave the archived todo out
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", f.TodoHandler.List)
+	require.NoError(t, err)
+	assert.Len(t, testutil.JSONArrayResponse(t, rec), 2)
+
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos/search", "", f.TodoHandler.Search)
+	require.NoError(t, err)
+	assert.Len(t, testutil.JSONResponse(t, rec)["data"], 2)
+
+	// It is found when searching archived todos
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?archived=true", "", f.TodoHandler.Search)
+	require.NoError(t, err)
+	data := testutil.JSONResponse(t, rec)["data"].([]any)
+	require.Len(t, data, 1)
+	assert.Equal(t, float64(old.ID), data[0].(map[string]any)["id"])
+	assert.NotNil(t, data[0].(map[string]any)["archived_at"])
+
+	// Reopening it unarchives it
+	_, err = f.CallAuth(token, http.MethodPatch, testutil.TodoPath(old.ID), `{"completed":false}`, f.TodoHandler.Update)
+	require.NoError(t, err)
+	reopened, err := f.TodoRepo.FindByID(context.Background(), old.ID, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, reopened.ArchivedAt)
+}
+
+// TestTodoSearch_InvalidArchived tests that a non-boolean archived parameter is rejected
+func TestTodoSearch_InvalidArchived(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("archivedinvalid@example.com")
+
+	_, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?archived=yes", "", f.TodoHandler.Search)
+	assertAPIError(t, err, http.StatusUnprocessableEntity)
+}
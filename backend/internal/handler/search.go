@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/highlight"
+	"todo-api/internal/search"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// searchBodyMaxRunes limits the body text and snippet returned with each hit
+const searchBodyMaxRunes = 200
+
+// SearchHandler handles cross-resource search endpoints
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+// NewSearchHandler creates a new SearchHandler
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// GlobalSearchResponse represents grouped search results in API responses
+type GlobalSearchResponse struct {
+	Query  string                `json:"query"`
+	Groups []SearchGroupResponse `json:"groups"`
+}
+
+// SearchGroupResponse represents the results of one resource type
+type SearchGroupResponse struct {
+	Type  string              `json:"type"`
+	Total int64               `json:"total"`
+	Items []SearchHitResponse `json:"items"`
+}
+
+// SearchHitResponse represents a single search result
+type SearchHitResponse struct {
+	Type      string  `json:"type"`
+	ID        int64   `json:"id"`
+	Title     string  `json:"title"`
+	Body      *string `json:"body,omitempty"`
+	TodoID    *int64  `json:"todo_id,omitempty"`
+	Color     *string `json:"color,omitempty"`
+	Score     float64 `json:"score"`
+	UpdatedAt string  `json:"updated_at"`
+
+	// Snippet is the matched part of the title or body, HTML-escaped with <mark> tags
+	Snippet    *string                     `json:"snippet,omitempty"`
+	Highlights map[string][]HighlightMatch `json:"highlights,omitempty"`
+}
+
+// HighlightMatch is an occurrence of the query in a response field.
+// Offsets are UTF-16 code units into the field value as returned.
+type HighlightMatch struct {
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	MatchedText string `json:"matched_text"`
+}
+
+// Search searches todos, comments, categories, and tags at once
+// GET /api/v1/search
+func (h *SearchHandler) Search(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	input := service.GlobalSearchInput{
+		UserID: currentUser.ID,
+		Query:  c.QueryParam("q"),
+	}
+	if typesStr := c.QueryParam("types"); typesStr != "" {
+		input.Types = strings.Split(typesStr, ",")
+	}
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return errors.ValidationFailed(map[string][]string{
+				"limit": {"must be a positive integer"},
+			})
+		}
+		input.Limit = limit
+	}
+
+	result, err := h.searchService.Search(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	groups := make([]SearchGroupResponse, len(result.Groups))
+	for i, g := range result.Groups {
+		items := make([]SearchHitResponse, len(g.Hits))
+		for j, hit := range g.Hits {
+			items[j] = toSearchHitResponse(hit, result.Query)
+		}
+		groups[i] = SearchGroupResponse{
+			Type:  g.Type,
+			Total: g.Total,
+			Items: items,
+		}
+	}
+
+	return response.OK(c, GlobalSearchResponse{
+		Query:  result.Query,
+		Groups: groups,
+	})
+}
+
+func toSearchHitResponse(hit search.Hit, query string) SearchHitResponse {
+	resp := SearchHitResponse{
+		Type:      hit.Type,
+		ID:        hit.ID,
+		Title:     hit.Title,
+		TodoID:    hit.TodoID,
+		Color:     hit.Color,
+		Score:     hit.Score,
+		UpdatedAt: util.FormatRFC3339(hit.UpdatedAt),
+	}
+
+	if hit.Body != nil {
+		// Long bodies are cut around the match so it stays visible
+		body := highlight.Window(*hit.Body, query, searchBodyMaxRunes)
+		resp.Body = &body
+	}
+
+	// The title of a comment hit belongs to its todo and was not searched
+	fields := map[string]*string{"body": resp.Body}
+	if hit.Type != search.TypeComment {
+		fields["title"] = &resp.Title
+	}
+	resp.Highlights = buildHighlights(query, fields)
+
+	// Prefer the title as snippet, then the body (already cut to length above)
+	for _, name := range []string{"title", "body"} {
+		value, ok := fields[name]
+		if !ok || value == nil {
+			continue
+		}
+		if snippet := highlight.Snippet(*value, query, 0); snippet != "" {
+			resp.Snippet = &snippet
+			break
+		}
+	}
+	return resp
+}
+
+// buildHighlights returns the occurrences of the query in every field that contains it
+func buildHighlights(query string, fields map[string]*string) map[string][]HighlightMatch {
+	highlights := make(map[string][]HighlightMatch)
+	for name, value := range fields {
+		if value == nil {
+			continue
+		}
+		found := highlight.Find(*value, query)
+		if len(found) == 0 {
+			continue
+		}
+		matches := make([]HighlightMatch, len(found))
+		for i, m := range found {
+			matches[i] = HighlightMatch{Start: m.Start, End: m.End, MatchedText: m.MatchedText}
+		}
+		highlights[name] = matches
+	}
+	if len(highlights) == 0 {
+		return nil
+	}
+	return highlights
+}
@@ -0,0 +1,228 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/search"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+	"todo-api/pkg/util"
+)
+
+const searchPath = "/api/v1/search"
+
+// findGroup returns the result group of the given type, or nil
+func findGroup(groups []any, groupType string) map[string]any {
+	for _, g := range groups {
+		group := g.(map[string]any)
+		if group["type"] == groupType {
+			return group
+		}
+	}
+	return nil
+}
+
+// TestGlobalSearch_AllTypes tests that todos, comments, categories, and tags are searched together
+func TestGlobalSearch_AllTypes(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("globalsearch@example.com")
+	todo := f.CreateTodo(user.ID, "Prepare invoice")
+	f.CreateComment(user.ID, todo.ID, "Send the invoice by Friday")
+	f.CreateCategory(user.ID, "invoices", "#FF0000")
+	f.CreateTag(user.ID, "invoice", nil)
+	f.CreateTodo(user.ID, "Unrelated")
+
+	rec, err := f.CallAuth(token, http.MethodGet, searchPath+"?q=invoice", "", f.SearchHandler.Search)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "invoice", response["query"])
+
+	groups := response["groups"].([]any)
+	require.Len(t, groups, 4)
+
+	// The exact tag match ranks its group first
+	assert.Equal(t, "tag", groups[0].(map[string]any)["type"])
+
+	todos := findGroup(groups, "todo")
+	require.NotNil(t, todos)
+	assert.Equal(t, float64(1), todos["total"])
+	assert.Equal(t, float64(todo.ID), testutil.ItemAt(todos["items"].([]any), 0)["id"])
+
+	comments := findGroup(groups, "comment")
+	require.NotNil(t, comments)
+	comment := testutil.ItemAt(comments["items"].([]any), 0)
+	assert.Equal(t, "Prepare invoice", comment["title"])
+	assert.Equal(t, float64(todo.ID), comment["todo_id"])
+}
+
+// TestGlobalSearch_TypesAndLimit tests restricting types and the per-group limit
+func TestGlobalSearch_TypesAndLimit(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("globalsearchtypes@example.com")
+	f.CreateTodo(user.ID, "Report one")
+	f.CreateTodo(user.ID, "Report two")
+	f.CreateTodo(user.ID, "Report three")
+	f.CreateTag(user.ID, "report", nil)
+
+	rec, err := f.CallAuth(token, http.MethodGet, searchPath+"?q=report&types=todo&limit=2", "", f.SearchHandler.Search)
+	require.NoError(t, err)
+
+	groups := testutil.JSONResponse(t, rec)["groups"].([]any)
+	require.Len(t, groups, 1)
+
+	todos := groups[0].(map[string]any)
+	assert.Equal(t, "todo", todos["type"])
+	assert.Equal(t, float64(3), todos["total"])
+	assert.Len(t, todos["items"].([]any), 2)
+}
+
+// TestGlobalSearch_OtherUser tests that other users' data is not returned
+func TestGlobalSearch_OtherUser(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	owner, _ := f.CreateUser("globalsearchowner@example.com")
+	_, token := f.CreateUser("globalsearchother@example.com")
+	todo := f.CreateTodo(owner.ID, "Secret plan")
+	f.CreateComment(owner.ID, todo.ID, "Secret comment")
+
+	rec, err := f.CallAuth(token, http.MethodGet, searchPath+"?q=secret", "", f.SearchHandler.Search)
+	require.NoError(t, err)
+
+	assert.Empty(t, testutil.JSONResponse(t, rec)["groups"])
+}
+
+// TestGlobalSearch_ValidationError tests invalid query parameters
+func TestGlobalSearch_ValidationError(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("globalsearchinvalid@example.com")
+
+	for _, query := range []string{"", "?q=", "?q=%20%20", "?q=a&types=note", "?q=a&limit=0"} {
+		_, err := f.CallAuth(token, http.MethodGet, searchPath+query, "", f.SearchHandler.Search)
+		assert.Error(t, err, query)
+	}
+}
+
+// TestGlobalSearch_Highlights tests snippets and match positions for long bodies
+func TestGlobalSearch_Highlights(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("globalsearchhighlight@example.com")
+	todo := f.CreateTodo(user.ID, "Call the bank")
+	f.CreateComment(user.ID, todo.ID, strings.Repeat("x", 300)+" ask about the loan")
+
+	rec, err := f.CallAuth(token, http.MethodGet, searchPath+"?q=loan", "", f.SearchHandler.Search)
+	require.NoError(t, err)
+
+	groups := testutil.JSONResponse(t, rec)["groups"].([]any)
+	comment := testutil.ItemAt(findGroup(groups, "comment")["items"].([]any), 0)
+
+	// The body is cut around the match
+	body := comment["body"].(string)
+	assert.True(t, strings.HasPrefix(body, "…"))
+	assert.True(t, strings.HasSuffix(body, "loan"))
+	assert.True(t, strings.HasSuffix(comment["snippet"].(string), "<mark>loan</mark>"))
+
+	// Only the comment content was searched, and offsets point into the returned body
+	highlights := comment["highlights"].(map[string]any)
+	assert.NotContains(t, highlights, "title")
+	match := highlights["body"].([]any)[0].(map[string]any)
+	bodyLen := len(utf16.Encode([]rune(body)))
+	assert.Equal(t, float64(bodyLen-4), match["start"])
+	assert.Equal(t, float64(bodyLen), match["end"])
+}
+
+// TestGlobalSearch_Ranking tests that the SQL index ranks exact, prefix, word start, and partial title matches
+// in that order, ahead of matches in the description only
+func TestGlobalSearch_Ranking(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("globalsearchranking@example.com")
+	f.CreateTodoWithDetails(user.ID, "Unrelated", testutil.TodoOptions{Description: util.Ptr("Discuss the plan")})
+	f.CreateTodo(user.ID, "Explanation")
+	f.CreateTodo(user.ID, "Team plan")
+	f.CreateTodo(user.ID, "Planning")
+	f.CreateTodo(user.ID, "Plan")
+
+	rec, err := f.CallAuth(token, http.MethodGet, searchPath+"?q=PLAN&types=todo", "", f.SearchHandler.Search)
+	require.NoError(t, err)
+
+	todos := findGroup(testutil.JSONResponse(t, rec)["groups"].([]any), "todo")
+	require.NotNil(t, todos)
+	var titles []string
+	for _, item := range todos["items"].([]any) {
+		titles = append(titles, item.(map[string]any)["title"].(string))
+	}
+	assert.Equal(t, []string{"Plan", "Planning", "Team plan", "Explanation", "Unrelated"}, titles)
+}
+
+// TestSearchCallbacks_Flush tests that writes are sent to the index after they commit, built from the rows
+func TestSearchCallbacks_Flush(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	require.NoError(t, search.RegisterCallbacks(f.DB))
+	syncer := &recordingSyncer{}
+	syncService := service.NewSearchSyncService(repository.NewSearchRepository(f.DB), syncer)
+
+	user, token := f.CreateUser("searchcallbacks@example.com")
+	rec, err := f.CallAuth(token, http.MethodPost, "/api/v1/todos", `{"title":"Draft"}`, f.TodoHandler.Create)
+	require.NoError(t, err)
+	todoID := int64(testutil.JSONResponse(t, rec)["id"].(float64))
+	_, err = f.CallAuth(token, http.MethodPatch, testutil.TodoPath(todoID), `{"title":"Final"}`, f.TodoHandler.Update)
+	require.NoError(t, err)
+	comment := f.CreateComment(user.ID, todoID, "Looks good")
+
+	// Writes of a rolled back transaction are never sent
+	rollback := errors.New("rollback")
+	err = f.DB.Transaction(func(tx *gorm.DB) error {
+		require.NoError(t, tx.Create(&model.Tag{UserID: user.ID, Name: "discarded"}).Error)
+		return rollback
+	})
+	require.ErrorIs(t, err, rollback)
+
+	// Nothing is sent until flushed
+	assert.Empty(t, syncer.docs)
+	require.NoError(t, syncService.Flush(context.Background(), time.Now()))
+
+	// The todo's writes send its current state once
+	var todoDocs []search.Document
+	for _, doc := range syncer.docs {
+		assert.NotEqual(t, search.TypeTag, doc.Type)
+		if doc.Type == search.TypeTodo {
+			todoDocs = append(todoDocs, doc)
+		}
+	}
+	require.Len(t, todoDocs, 1)
+	assert.Equal(t, todoID, todoDocs[0].ID)
+	assert.Equal(t, "Final", todoDocs[0].Title)
+	assert.Equal(t, user.ID, todoDocs[0].UserID)
+
+	var queued int64
+	require.NoError(t, f.DB.Model(&model.PendingSearchUpdate{}).Count(&queued).Error)
+	assert.Zero(t, queued)
+
+	// Deleted rows are removed from the index
+	_, err = f.CallAuth(token, http.MethodDelete, testutil.CommentPath(todoID, comment.ID), "", f.CommentHandler.Delete)
+	require.NoError(t, err)
+	_, err = f.CallAuth(token, http.MethodDelete, testutil.TodoPath(todoID), "", f.TodoHandler.Delete)
+	require.NoError(t, err)
+	require.NoError(t, syncService.Flush(context.Background(), time.Now()))
+	assert.Equal(t, []int64{comment.ID}, syncer.deleted[search.TypeComment])
+	assert.Equal(t, []int64{todoID}, syncer.deleted[search.TypeTodo])
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// StreakHandler handles streak and achievement endpoints
+type StreakHandler struct {
+	streakService *service.StreakService
+}
+
+// NewStreakHandler creates a new StreakHandler
+func NewStreakHandler(streakService *service.StreakService) *StreakHandler {
+	return &StreakHandler{streakService: streakService}
+}
+
+// StreakResponse represents a user's streaks and achievements in API responses
+type StreakResponse struct {
+	CurrentStreak  int                   `json:"current_streak"`
+	LongestStreak  int                   `json:"longest_streak"`
+	LastActiveDate *string               `json:"last_active_date"`
+	TotalCompleted int                   `json:"total_completed"`
+	Achievements   []AchievementResponse `json:"achievements"`
+}
+
+// AchievementResponse represents an achievement and whether the user has unlocked it
+type AchievementResponse struct {
+	Code        string  `json:"code"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Achieved    bool    `json:"achieved"`
+	AchievedAt  *string `json:"achieved_at"`
+}
+
+// Show retrieves the current user's completion streaks and achievements
+// GET /api/v1/users/me/streaks
+func (h *StreakHandler) Show(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	summary, err := h.streakService.Get(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	unlocked := make(map[model.AchievementCode]model.UserAchievement, len(summary.Achievements))
+	for _, a := range summary.Achievements {
+		unlocked[a.Code] = a
+	}
+
+	achievements := make([]AchievementResponse, len(model.Achievements))
+	for i, def := range model.Achievements {
+		achievements[i] = AchievementResponse{
+			Code:        string(def.Code),
+			Title:       def.Title,
+			Description: def.Description,
+		}
+		if a, ok := unlocked[def.Code]; ok {
+			achievedAt := util.FormatRFC3339(a.AchievedAt)
+			achievements[i].Achieved = true
+			achievements[i].AchievedAt = &achievedAt
+		}
+	}
+
+	streak := summary.Streak
+	return response.OK(c, StreakResponse{
+		CurrentStreak:  streak.StreakAsOf(summary.Today),
+		LongestStreak:  streak.LongestStreak,
+		LastActiveDate: util.FormatDate(streak.LastActiveDate),
+		TotalCompleted: streak.TotalCompleted,
+		Achievements:   achievements,
+	})
+}
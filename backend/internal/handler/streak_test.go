@@ -0,0 +1,139 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/model"
+	"todo-api/internal/testutil"
+)
+
+const streaksPath = "/api/v1/users/me/streaks"
+
+// TestStreakShow_Empty tests the response for a user who has not completed anything
+func TestStreakShow_Empty(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("streakempty@example.com")
+
+	rec, err := f.CallAuth(token, http.MethodGet, streaksPath, "", f.StreakHandler.Show)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(0), response["current_streak"])
+	assert.Equal(t, float64(0), response["longest_streak"])
+	assert.Nil(t, response["last_active_date"])
+
+	achievements := response["achievements"].([]interface{})
+	assert.Len(t, achievements, len(model.Achievements))
+	for _, a := range achievements {
+		assert.Equal(t, false, a.(map[string]interface{})["achieved"])
+	}
+}
+
+// TestStreakShow_AfterCompletion tests that completing a todo starts a streak and unlocks an achievement
+func TestStreakShow_AfterCompletion(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("streakcomplete@example.com")
+	todo := f.CreateTodo(user.ID, "Finish me")
+
+	_, err := f.CallAuth(token, http.MethodPatch, testutil.TodoPath(todo.ID), `{"completed":true}`, f.TodoHandler.Update)
+	require.NoError(t, err)
+
+	rec, err := f.CallAuth(token, http.MethodGet, streaksPath, "", f.StreakHandler.Show)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(1), response["current_streak"])
+	assert.Equal(t, float64(1), response["total_completed"])
+
+	first := response["achievements"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, string(model.AchievementFirstCompletion), first["code"])
+	assert.Equal(t, true, first["achieved"])
+	assert.NotNil(t, first["achieved_at"])
+}
+
+// TestStreakShow_ContinuesFromYesterday tests that completing on consecutive days extends the streak
+func TestStreakShow_ContinuesFromYesterday(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("streakcontinue@example.com")
+	todo := f.CreateTodo(user.ID, "Daily habit")
+
+	now := time.Now().UTC()
+	yesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, f.StreakRepo.Save(context.Background(), &model.UserStreak{
+		UserID:         user.ID,
+		CurrentStreak:  2,
+		LongestStreak:  2,
+		LastActiveDate: &yesterday,
+		TotalCompleted: 2,
+	}))
+
+	_, err := f.CallAuth(token, http.MethodPatch, testutil.TodoPath(todo.ID), `{"status":"completed"}`, f.TodoHandler.Update)
+	require.NoError(t, err)
+
+	rec, err := f.CallAuth(token, http.MethodGet, streaksPath, "", f.StreakHandler.Show)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(3), response["current_streak"])
+	assert.Equal(t, float64(3), response["longest_streak"])
+
+	achievements, err := f.StreakRepo.ListAchievements(context.Background(), user.ID)
+	require.NoError(t, err)
+	codes := make([]model.AchievementCode, len(achievements))
+	for i, a := range achievements {
+		codes[i] = a.Code
+	}
+	assert.Contains(t, codes, model.AchievementStreak3)
+}
+
+// TestStreakShow_RecompletedTodoCountedOnce tests that reopening and completing a todo again does not count it twice
+func TestStreakShow_RecompletedTodoCountedOnce(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("streakonce@example.com")
+	todo := f.CreateTodo(user.ID, "Toggle me")
+
+	for _, body := range []string{`{"completed":true}`, `{"completed":false}`, `{"completed":true}`} {
+		_, err := f.CallAuth(token, http.MethodPatch, testutil.TodoPath(todo.ID), body, f.TodoHandler.Update)
+		require.NoError(t, err)
+	}
+
+	rec, err := f.CallAuth(token, http.MethodGet, streaksPath, "", f.StreakHandler.Show)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(1), response["total_completed"])
+	assert.Equal(t, float64(1), response["current_streak"])
+}
+
+// TestStreakBackfillCompletions tests that todos completed before completions were recorded are not counted again
+func TestStreakBackfillCompletions(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, _ := f.CreateUser("streakbackfill@example.com")
+	done := f.CreateTodo(user.ID, "Done before")
+	completeTodoAt(t, f, done, time.Now().UTC().Add(-time.Hour))
+	f.CreateTodo(user.ID, "Open")
+
+	require.NoError(t, f.StreakRepo.BackfillCompletions(context.Background()))
+	require.NoError(t, f.StreakRepo.BackfillCompletions(context.Background()))
+
+	streak, err := f.StreakRepo.RecordCompletion(context.Background(), user.ID, done.ID, time.Now().UTC())
+	require.NoError(t, err)
+	assert.Nil(t, streak)
+
+	var count int64
+	require.NoError(t, f.DB.Model(&model.StreakCompletion{}).Where("user_id = ?", user.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// SuggestionHandler handles AI-assisted suggestion endpoints
+type SuggestionHandler struct {
+	suggestionService *service.SuggestionService
+}
+
+// NewSuggestionHandler creates a new SuggestionHandler
+func NewSuggestionHandler(suggestionService *service.SuggestionService) *SuggestionHandler {
+	return &SuggestionHandler{suggestionService: suggestionService}
+}
+
+// SuggestTodoRequest represents the draft todo to suggest attributes for
+type SuggestTodoRequest struct {
+	Title       string  `json:"title" validate:"required,min=1,max=255"`
+	Description *string `json:"description" validate:"omitempty,max=10000"`
+}
+
+// SuggestionResponse represents suggested attributes for a draft todo
+type SuggestionResponse struct {
+	Priority *string           `json:"priority"`
+	DueDate  *string           `json:"due_date"`
+	Category *CategoryResponse `json:"category"`
+	Subtasks []string          `json:"subtasks"`
+	Provider string            `json:"provider"`
+}
+
+// Suggest proposes priority, due date, category, and subtasks for a draft todo
+// POST /api/v1/todos/suggestions
+func (h *SuggestionHandler) Suggest(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var req SuggestTodoRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	result, err := h.suggestionService.Suggest(c.Request().Context(), service.SuggestInput{
+		UserID:      currentUser.ID,
+		Title:       req.Title,
+		Description: util.DerefString(req.Description, ""),
+	})
+	if err != nil {
+		return err
+	}
+
+	resp := SuggestionResponse{
+		Priority: result.Priority,
+		DueDate:  result.DueDate,
+		Subtasks: result.Subtasks,
+		Provider: result.Provider,
+	}
+	if result.Category != nil {
+		category := toCategoryResponse(result.Category)
+		resp.Category = &category
+	}
+
+	return response.OK(c, resp)
+}
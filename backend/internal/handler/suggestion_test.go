@@ -0,0 +1,77 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/handler"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+const suggestionsPath = "/api/v1/todos/suggestions"
+
+// TestSuggestionSuggest_Heuristic tests suggestions from keywords, categories, and checklist lines
+func TestSuggestionSuggest_Heuristic(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("suggest@example.com")
+	category := f.CreateCategory(user.ID, "Work", "#3B82F6")
+
+	body := `{"title":"Urgent: prepare work report tomorrow","description":"- collect numbers\n- write summary\n\nnotes"}`
+	rec, err := f.CallAuth(token, http.MethodPost, suggestionsPath, body, f.SuggestionHandler.Suggest)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "heuristic", response["provider"])
+	assert.Equal(t, "high", response["priority"])
+	assert.Equal(t, time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02"), response["due_date"])
+
+	suggestedCategory := response["category"].(map[string]interface{})
+	assert.Equal(t, float64(category.ID), suggestedCategory["id"])
+
+	assert.Equal(t, []interface{}{"collect numbers", "write summary"}, response["subtasks"])
+}
+
+// TestSuggestionSuggest_NoSuggestions tests that unknown fields are returned as null
+func TestSuggestionSuggest_NoSuggestions(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("suggestnone@example.com")
+
+	rec, err := f.CallAuth(token, http.MethodPost, suggestionsPath, `{"title":"Water plants"}`, f.SuggestionHandler.Suggest)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Nil(t, response["priority"])
+	assert.Nil(t, response["due_date"])
+	assert.Nil(t, response["category"])
+	assert.Empty(t, response["subtasks"])
+}
+
+// TestSuggestionSuggest_ValidationError tests that a title is required
+func TestSuggestionSuggest_ValidationError(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("suggestinvalid@example.com")
+
+	_, err := f.CallAuth(token, http.MethodPost, suggestionsPath, `{"description":"no title"}`, f.SuggestionHandler.Suggest)
+	require.Error(t, err)
+}
+
+// TestSuggestionSuggest_Disabled tests that the endpoint is unavailable without a provider
+func TestSuggestionSuggest_Disabled(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("suggestdisabled@example.com")
+
+	disabled := handler.NewSuggestionHandler(service.NewSuggestionService(nil, f.TodoRepo, f.CategoryRepo, f.PreferenceRepo))
+	_, err := f.CallAuth(token, http.MethodPost, suggestionsPath, `{"title":"Anything"}`, disabled.Suggest)
+	require.Error(t, err)
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/service"
+	"todo-api/pkg/util"
+)
+
+// SyncHandler handles the sync endpoints used by offline clients
+type SyncHandler struct {
+	syncService *service.SyncService
+}
+
+// NewSyncHandler creates a new SyncHandler
+func NewSyncHandler(syncService *service.SyncService) *SyncHandler {
+	return &SyncHandler{syncService: syncService}
+}
+
+// SyncPushRequest represents the request body for pushing local changes
+type SyncPushRequest struct {
+	Changes []SyncChangeRequest `json:"changes" validate:"required,min=1,dive"`
+}
+
+// SyncChangeRequest represents a single change made by an offline client
+type SyncChangeRequest struct {
+	UUID      string         `json:"uuid" validate:"required,uuid"`
+	Op        string         `json:"op" validate:"required,oneof=upsert delete"`
+	UpdatedAt string         `json:"updated_at" validate:"required"`
+	Fields    map[string]any `json:"fields"`
+	Base      map[string]any `json:"base"`
+}
+
+// SyncPullResponse represents a page of changes pulled by a client
+type SyncPullResponse struct {
+	Todos     []TodoResponse          `json:"todos"`
+	Deleted   []SyncTombstoneResponse `json:"deleted"`
+	SyncToken string                  `json:"sync_token"`
+	HasMore   bool                    `json:"has_more"`
+}
+
+// SyncTombstoneResponse represents a deleted todo in pulled changes
+type SyncTombstoneResponse struct {
+	UUID      string `json:"uuid"`
+	DeletedAt string `json:"deleted_at"`
+}
+
+// SyncPushResponse represents the outcome of pushed changes, in request order
+type SyncPushResponse struct {
+	Results []SyncResultResponse `json:"results"`
+}
+
+// SyncResultResponse represents the outcome of a single pushed change
+type SyncResultResponse struct {
+	UUID      string             `json:"uuid"`
+	Status    string             `json:"status"`
+	Todo      *TodoResponse      `json:"todo"`
+	Deleted   bool               `json:"deleted"`
+	Conflicts []string           `json:"conflicts,omitempty"`
+	Error     *SyncErrorResponse `json:"error,omitempty"`
+}
+
+// SyncErrorResponse represents why a pushed change was invalid
+type SyncErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Pull returns the todos changed and deleted since the sync token
+// GET /api/v1/sync/todos?sync_token=&limit=
+func (h *SyncHandler) Pull(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	limit := service.DefaultSyncPullLimit
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > service.MaxSyncPullLimit {
+			return errors.ValidationFailed(map[string][]string{
+				"limit": {fmt.Sprintf("must be an integer between 1 and %d", service.MaxSyncPullLimit)},
+			})
+		}
+	}
+
+	pull, err := h.syncService.Pull(c.Request().Context(), currentUser.ID, c.QueryParam("sync_token"), limit, time.Now())
+	if err != nil {
+		return err
+	}
+
+	resp := SyncPullResponse{
+		Todos:     toTodoResponses(pull.Todos),
+		Deleted:   make([]SyncTombstoneResponse, len(pull.Tombstones)),
+		SyncToken: pull.SyncToken,
+		HasMore:   pull.HasMore,
+	}
+	for i, tombstone := range pull.Tombstones {
+		resp.Deleted[i] = SyncTombstoneResponse{
+			UUID:      tombstone.UUID,
+			DeletedAt: util.FormatRFC3339(tombstone.DeletedAt),
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Push applies the changes made by an offline client
+// POST /api/v1/sync/todos
+func (h *SyncHandler) Push(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var req SyncPushRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if len(req.Changes) > service.MaxSyncPushChanges {
+		return errors.ValidationFailed(map[string][]string{
+			"changes": {fmt.Sprintf("must contain at most %d changes", service.MaxSyncPushChanges)},
+		})
+	}
+
+	changes := make([]service.SyncChange, len(req.Changes))
+	for i, change := range req.Changes {
+		updatedAt, err := time.Parse(time.RFC3339, change.UpdatedAt)
+		if err != nil {
+			return errors.ValidationFailed(map[string][]string{
+				fmt.Sprintf("changes[%d].updated_at", i): {"must be an RFC 3339 timestamp"},
+			})
+		}
+		changes[i] = service.SyncChange{
+			UUID:      strings.ToLower(change.UUID),
+			Op:        change.Op,
+			UpdatedAt: updatedAt,
+			Fields:    change.Fields,
+			Base:      change.Base,
+		}
+	}
+
+	results, err := h.syncService.Push(c.Request().Context(), currentUser.ID, changes, time.Now())
+	if err != nil {
+		return err
+	}
+
+	resp := SyncPushResponse{Results: make([]SyncResultResponse, len(results))}
+	for i, result := range results {
+		resp.Results[i] = SyncResultResponse{
+			UUID:      result.UUID,
+			Status:    string(result.Status),
+			Deleted:   result.Deleted,
+			Conflicts: result.Conflicts,
+		}
+		if result.Todo != nil {
+			todo := toTodoResponse(result.Todo)
+			resp.Results[i].Todo = &todo
+		}
+		if result.Error != nil {
+			resp.Results[i].Error = &SyncErrorResponse{
+				Code:    result.Error.Code,
+				Message: result.Error.Message,
+				Details: result.Error.Details,
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
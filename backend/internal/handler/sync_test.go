@@ -0,0 +1,238 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/model"
+	"todo-api/internal/testutil"
+)
+
+const syncPath = "/api/v1/sync/todos"
+
+// syncTimestamp formats a client change time; times after the server time count as the server time
+func syncTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// pushChanges pushes a JSON array of changes and returns the results
+func pushChanges(t *testing.T, f *testutil.TestFixture, token, changes string) []any {
+	t.Helper()
+	rec, err := f.CallAuth(token, http.MethodPost, syncPath, `{"changes":`+changes+`}`, f.SyncHandler.Push)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	return testutil.JSONResponse(t, rec)["results"].([]any)
+}
+
+// pullChanges pulls a page of changes with the query string
+func pullChanges(t *testing.T, f *testutil.TestFixture, token, query string) map[string]any {
+	t.Helper()
+	rec, err := f.CallAuth(token, http.MethodGet, syncPath+query, "", f.SyncHandler.Pull)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	return testutil.JSONResponse(t, rec)
+}
+
+// uuidsOf returns the uuid of each object in a JSON array
+func uuidsOf(items any) []string {
+	var uuids []string
+	for _, item := range items.([]any) {
+		uuids = append(uuids, item.(map[string]any)["uuid"].(string))
+	}
+	return uuids
+}
+
+// TestSyncPush_CreateOffline tests creating todos with client-generated UUIDs, idempotently
+func TestSyncPush_CreateOffline(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("synccreate@example.com")
+	todoUUID := uuid.NewString()
+
+	// Due dates that passed while the client was offline are accepted
+	change := fmt.Sprintf(`[{"uuid":%q,"op":"upsert","updated_at":%q,"fields":{"title":"Offline","priority":"high","due_date":"2020-01-01"}}]`,
+		todoUUID, syncTimestamp(time.Now().Add(-time.Hour)))
+	results := pushChanges(t, f, token, change)
+	require.Len(t, results, 1)
+
+	result := results[0].(map[string]any)
+	assert.Equal(t, "applied", result["status"])
+	todo := result["todo"].(map[string]any)
+	assert.Equal(t, todoUUID, todo["uuid"])
+	assert.Equal(t, "Offline", todo["title"])
+	assert.Equal(t, "high", todo["priority"])
+	assert.Equal(t, "2020-01-01", todo["due_date"])
+
+	// Retrying the push does not create a duplicate
+	results = pushChanges(t, f, token, change)
+	assert.Equal(t, "applied", results[0].(map[string]any)["status"])
+
+	var count int64
+	f.DB.Model(&model.Todo{}).Where("uuid = ?", todoUUID).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// Creating requires a title
+	change = fmt.Sprintf(`[{"uuid":%q,"op":"upsert","updated_at":%q,"fields":{"priority":"low"}}]`,
+		uuid.NewString(), syncTimestamp(time.Now()))
+	result = pushChanges(t, f, token, change)[0].(map[string]any)
+	assert.Equal(t, "invalid", result["status"])
+	assert.Nil(t, result["todo"])
+	assert.Equal(t, "VALIDATION_FAILED", result["error"].(map[string]any)["code"])
+
+	// Another user's UUID cannot be taken over
+	_, otherToken := f.CreateUser("synccreateother@example.com")
+	change = fmt.Sprintf(`[{"uuid":%q,"op":"upsert","updated_at":%q,"fields":{"title":"Mine"}}]`,
+		todoUUID, syncTimestamp(time.Now()))
+	result = pushChanges(t, f, otherToken, change)[0].(map[string]any)
+	assert.Equal(t, "invalid", result["status"])
+}
+
+// TestSyncPush_FieldMerge tests that fields changed on both sides go to the later change
+// while fields only the client changed are applied
+func TestSyncPush_FieldMerge(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("syncmerge@example.com")
+	todo := f.CreateTodo(user.ID, "Original")
+	require.NotNil(t, todo.UUID)
+
+	// The server renames the todo after the client's offline edit
+	clientEditedAt := time.Now().Add(-time.Hour)
+	require.NoError(t, f.DB.Model(todo).Update("title", "Server title").Error)
+
+	change := fmt.Sprintf(`[{"uuid":%q,"op":"upsert","updated_at":%q,
+		"fields":{"title":"Client title","priority":"high"},
+		"base":{"title":"Original","priority":"medium"}}]`, *todo.UUID, syncTimestamp(clientEditedAt))
+	result := pushChanges(t, f, token, change)[0].(map[string]any)
+	assert.Equal(t, "merged", result["status"])
+	assert.Equal(t, []any{"title"}, result["conflicts"])
+	merged := result["todo"].(map[string]any)
+	assert.Equal(t, "Server title", merged["title"])
+	assert.Equal(t, "high", merged["priority"])
+
+	// A later client change wins the conflict
+	change = fmt.Sprintf(`[{"uuid":%q,"op":"upsert","updated_at":%q,
+		"fields":{"title":"Client title"},"base":{"title":"Original"}}]`, *todo.UUID, syncTimestamp(time.Now().Add(time.Minute)))
+	result = pushChanges(t, f, token, change)[0].(map[string]any)
+	assert.Equal(t, "applied", result["status"])
+	assert.Equal(t, "Client title", result["todo"].(map[string]any)["title"])
+
+	// Invalid values are rejected without applying anything
+	change = fmt.Sprintf(`[{"uuid":%q,"op":"upsert","updated_at":%q,"fields":{"title":"Valid","status":"done"}}]`,
+		*todo.UUID, syncTimestamp(time.Now()))
+	result = pushChanges(t, f, token, change)[0].(map[string]any)
+	assert.Equal(t, "invalid", result["status"])
+
+	stored, err := f.TodoRepo.FindByID(context.Background(), todo.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Client title", stored.Title)
+}
+
+// TestSyncPush_Delete tests deletions and how they conflict with updates
+func TestSyncPush_Delete(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("syncdelete@example.com")
+	kept := f.CreateTodo(user.ID, "Updated on the server")
+	deleted := f.CreateTodo(user.ID, "Deleted offline")
+
+	// A delete made before the server's update is ignored
+	change := fmt.Sprintf(`[{"uuid":%q,"op":"delete","updated_at":%q}]`, *kept.UUID, syncTimestamp(time.Now().Add(-time.Hour)))
+	result := pushChanges(t, f, token, change)[0].(map[string]any)
+	assert.Equal(t, "ignored", result["status"])
+	assert.Equal(t, false, result["deleted"])
+	assert.NotNil(t, result["todo"])
+
+	change = fmt.Sprintf(`[{"uuid":%q,"op":"delete","updated_at":%q}]`, *deleted.UUID, syncTimestamp(time.Now().Add(time.Minute)))
+	result = pushChanges(t, f, token, change)[0].(map[string]any)
+	assert.Equal(t, "applied", result["status"])
+	assert.Equal(t, true, result["deleted"])
+
+	_, err := f.TodoRepo.FindByID(context.Background(), deleted.ID, user.ID)
+	assert.Error(t, err)
+
+	// An edit made before the deletion does not bring the todo back
+	change = fmt.Sprintf(`[{"uuid":%q,"op":"upsert","updated_at":%q,"fields":{"title":"Edited offline"}}]`,
+		*deleted.UUID, syncTimestamp(time.Now().Add(-time.Hour)))
+	result = pushChanges(t, f, token, change)[0].(map[string]any)
+	assert.Equal(t, "ignored", result["status"])
+	assert.Equal(t, true, result["deleted"])
+
+	// Deleting again is a no-op
+	change = fmt.Sprintf(`[{"uuid":%q,"op":"delete","updated_at":%q}]`, *deleted.UUID, syncTimestamp(time.Now().Add(time.Minute)))
+	result = pushChanges(t, f, token, change)[0].(map[string]any)
+	assert.Equal(t, "applied", result["status"])
+}
+
+// TestSyncPush_OtherUsersTombstone tests that a UUID deleted by another user cannot be reused to take over their tombstone
+func TestSyncPush_OtherUsersTombstone(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	owner, ownerToken := f.CreateUser("synctombstoneowner@example.com")
+	todo := f.CreateTodo(owner.ID, "Deleted by the owner")
+	change := fmt.Sprintf(`[{"uuid":%q,"op":"delete","updated_at":%q}]`, *todo.UUID, syncTimestamp(time.Now().Add(time.Minute)))
+	require.Equal(t, "applied", pushChanges(t, f, ownerToken, change)[0].(map[string]any)["status"])
+
+	_, otherToken := f.CreateUser("synctombstoneother@example.com")
+	change = fmt.Sprintf(`[{"uuid":%q,"op":"upsert","updated_at":%q,"fields":{"title":"Taken over"}}]`,
+		*todo.UUID, syncTimestamp(time.Now().Add(2*time.Minute)))
+	result := pushChanges(t, f, otherToken, change)[0].(map[string]any)
+	assert.Equal(t, "invalid", result["status"])
+	assert.Nil(t, result["todo"])
+
+	var count int64
+	f.DB.Model(&model.Todo{}).Where("uuid = ?", *todo.UUID).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	// The owner still pulls the deletion, and the other user doesn't
+	assert.Equal(t, []string{*todo.UUID}, uuidsOf(pullChanges(t, f, ownerToken, "")["deleted"]))
+	assert.Empty(t, pullChanges(t, f, otherToken, "")["deleted"])
+	var tombstone model.TodoTombstone
+	require.NoError(t, f.DB.Where("uuid = ?", *todo.UUID).First(&tombstone).Error)
+	assert.Equal(t, owner.ID, tombstone.UserID)
+}
+
+// TestSyncPull tests pulling every todo, then only the changes and deletions since the sync token
+func TestSyncPull(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("syncpull@example.com")
+	first := f.CreateTodo(user.ID, "First")
+	second := f.CreateTodo(user.ID, "Second")
+	third := f.CreateTodo(user.ID, "Third")
+	other, _ := f.CreateUser("syncpullother@example.com")
+	f.CreateTodo(other.ID, "Not mine")
+
+	// Legacy todos without a UUID get one
+	require.NoError(t, f.DB.Model(third).UpdateColumn("uuid", nil).Error)
+
+	page := pullChanges(t, f, token, "?limit=2")
+	assert.Equal(t, true, page["has_more"])
+	assert.Len(t, page["todos"], 2)
+	assert.Empty(t, page["deleted"])
+
+	page2 := pullChanges(t, f, token, "?limit=2&sync_token="+page["sync_token"].(string))
+	assert.Equal(t, false, page2["has_more"])
+	require.Len(t, page2["todos"], 1)
+	assert.NotEmpty(t, page2["todos"].([]any)[0].(map[string]any)["uuid"])
+
+	// Changes since the token are pulled; earlier ones may repeat within the overlap window
+	_, err := f.CallAuthWithParams(token, http.MethodDelete, "/api/v1/todos/1", "",
+		map[string]string{"id": fmt.Sprint(first.ID)}, f.TodoHandler.Delete)
+	require.NoError(t, err)
+
+	changes := pullChanges(t, f, token, "?sync_token="+page2["sync_token"].(string))
+	assert.Equal(t, []string{*first.UUID}, uuidsOf(changes["deleted"]))
+	assert.Contains(t, uuidsOf(changes["todos"]), *second.UUID)
+	assert.NotContains(t, uuidsOf(changes["todos"]), *first.UUID)
+
+	_, err = f.CallAuth(token, http.MethodGet, syncPath+"?sync_token=invalid", "", f.SyncHandler.Pull)
+	assertAPIError(t, err, http.StatusUnprocessableEntity)
+}
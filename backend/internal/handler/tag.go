@@ -65,7 +65,7 @@ func (h *TagHandler) List(c echo.Context) error {
 		return err
 	}
 
-	tags, err := h.tagRepo.FindAllByUserID(currentUser.ID)
+	tags, err := h.tagRepo.FindAllByUserID(c.Request().Context(), currentUser.ID)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "TagHandler.List: failed to fetch tags")
 	}
@@ -91,7 +91,7 @@ func (h *TagHandler) Show(c echo.Context) error {
 		return err
 	}
 
-	tag, err := h.tagRepo.FindByID(id, currentUser.ID)
+	tag, err := h.tagRepo.FindByID(c.Request().Context(), id, currentUser.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Tag", id)
@@ -116,7 +116,7 @@ func (h *TagHandler) Create(c echo.Context) error {
 	}
 
 	// Check for duplicate name (names are normalized to lowercase in BeforeSave)
-	exists, err := h.tagRepo.ExistsByName(req.Name, currentUser.ID, nil)
+	exists, err := h.tagRepo.ExistsByName(c.Request().Context(), req.Name, currentUser.ID, nil)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "TagHandler.Create: failed to check duplicate name")
 	}
@@ -130,7 +130,7 @@ func (h *TagHandler) Create(c echo.Context) error {
 		Color:  req.Color,
 	}
 
-	if err := h.tagRepo.Create(tag); err != nil {
+	if err := h.tagRepo.Create(c.Request().Context(), tag); err != nil {
 		return errors.InternalErrorWithLog(err, "TagHandler.Create: failed to create tag")
 	}
 
@@ -150,7 +150,7 @@ func (h *TagHandler) Update(c echo.Context) error {
 		return err
 	}
 
-	tag, err := h.tagRepo.FindByID(id, currentUser.ID)
+	tag, err := h.tagRepo.FindByID(c.Request().Context(), id, currentUser.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Tag", id)
@@ -165,7 +165,7 @@ func (h *TagHandler) Update(c echo.Context) error {
 
 	// Check for duplicate name if name is being changed
 	if req.Name != nil {
-		exists, err := h.tagRepo.ExistsByName(*req.Name, currentUser.ID, &id)
+		exists, err := h.tagRepo.ExistsByName(c.Request().Context(), *req.Name, currentUser.ID, &id)
 		if err != nil {
 			return errors.InternalErrorWithLog(err, "TagHandler.Update: failed to check duplicate name")
 		}
@@ -179,7 +179,7 @@ func (h *TagHandler) Update(c echo.Context) error {
 		tag.Color = req.Color
 	}
 
-	if err := h.tagRepo.Update(tag); err != nil {
+	if err := h.tagRepo.Update(c.Request().Context(), tag); err != nil {
 		return errors.InternalErrorWithLog(err, "TagHandler.Update: failed to update tag")
 	}
 
@@ -199,7 +199,7 @@ func (h *TagHandler) Delete(c echo.Context) error {
 		return err
 	}
 
-	if err := h.tagRepo.Delete(id, currentUser.ID); err != nil {
+	if err := h.tagRepo.Delete(c.Request().Context(), id, currentUser.ID); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Tag", id)
 		}
@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/middleware"
+	"todo-api/internal/service"
+	"todo-api/internal/tenant"
+	"todo-api/internal/testutil"
+)
+
+// tenantToken returns a bearer token issued by the API of a tenant
+func tenantToken(t *testing.T, f *testutil.TestFixture, slug, email string) string {
+	user, _ := f.CreateUser(email)
+	authService := service.NewAuthService(f.UserRepo, f.DenylistRepo, testutil.TestConfig.ForTenant(slug))
+	token, err := authService.GenerateToken(user)
+	require.NoError(t, err)
+	return "Bearer " + token
+}
+
+// TestTenantToken_RejectedByOtherTenant tests that a token issued for one tenant cannot authenticate elsewhere
+func TestTenantToken_RejectedByOtherTenant(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	token := tenantToken(t, f, "acme", "tenant@example.com")
+
+	_, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", f.TodoHandler.List)
+	require.Error(t, err)
+	apiErr, ok := err.(*errors.ApiError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
+
+// TestResolveTenant tests resolving the tenant from the subdomain and the token claim
+func TestResolveTenant(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	cfg := *testutil.TestConfig
+	cfg.TenancyMode = "schema"
+	cfg.TenantBaseDomain = "todo.example.com"
+
+	acmeToken := tenantToken(t, f, "acme", "acme@example.com")
+	otherToken := tenantToken(t, f, "other", "other@example.com")
+
+	tests := []struct {
+		name       string
+		host       string
+		path       string
+		token      string
+		wantTenant string
+		wantStatus int
+	}{
+		{name: "subdomain", host: "acme.todo.example.com", wantTenant: "acme"},
+		{name: "subdomain with port", host: "acme.todo.example.com:3000", wantTenant: "acme"},
+		{name: "claim", host: "api.example.com", token: acmeToken, wantTenant: "acme"},
+		{name: "matching subdomain and claim", host: "acme.todo.example.com", token: acmeToken, wantTenant: "acme"},
+		{name: "mismatching subdomain and claim", host: "acme.todo.example.com", token: otherToken, wantStatus: http.StatusUnauthorized},
+		{name: "nested subdomain", host: "x.acme.todo.example.com", wantStatus: http.StatusBadRequest},
+		{name: "no tenant", host: "todo.example.com", wantStatus: http.StatusBadRequest},
+		{name: "export download token", host: "api.example.com", path: "/exports/download?token=acme.abc123", wantTenant: "acme"},
+		{name: "calendar callback state", host: "api.example.com", path: "/calendar/google/callback?state=acme.abc123&code=x", wantTenant: "acme"},
+		{name: "subdomain over download token", host: "other.todo.example.com", path: "/exports/download?token=acme.abc123", wantTenant: "other"},
+		{name: "download token without tenant", host: "api.example.com", path: "/exports/download?token=abc123", wantStatus: http.StatusBadRequest},
+		{name: "token on other routes", host: "api.example.com", path: "/api/v1/todos?token=acme.abc123", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path
+			if path == "" {
+				path = "/api/v1/todos"
+			}
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Host = tt.host
+			if tt.token != "" {
+				req.Header.Set("Authorization", tt.token)
+			}
+			c := f.Echo.NewContext(req, httptest.NewRecorder())
+
+			resolved := ""
+			err := middleware.ResolveTenant(&cfg)(func(c echo.Context) error {
+				resolved, _ = tenant.FromContext(c.Request().Context())
+				return nil
+			})(c)
+
+			if tt.wantStatus != 0 {
+				require.Error(t, err)
+				apiErr, ok := err.(*errors.ApiError)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantStatus, apiErr.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTenant, resolved)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"gorm.io/gorm"
 
 	"todo-api/internal/errors"
+	"todo-api/internal/highlight"
 	"todo-api/internal/model"
 	"todo-api/internal/repository"
 	"todo-api/internal/service"
@@ -21,13 +23,15 @@ import (
 type TodoHandler struct {
 	todoService *service.TodoService
 	todoRepo    *repository.TodoRepository
+	linkService *service.TodoLinkService
 }
 
 // NewTodoHandler creates a new TodoHandler
-func NewTodoHandler(todoService *service.TodoService, todoRepo *repository.TodoRepository) *TodoHandler {
+func NewTodoHandler(todoService *service.TodoService, todoRepo *repository.TodoRepository, linkService *service.TodoLinkService) *TodoHandler {
 	return &TodoHandler{
 		todoService: todoService,
 		todoRepo:    todoRepo,
+		linkService: linkService,
 	}
 }
 
@@ -57,27 +61,57 @@ type UpdateTodoRequest struct {
 
 // UpdateOrderRequest represents the request body for updating todo positions
 type UpdateOrderRequest struct {
-	Todos []struct {
+	// Scope is "global" (default) to set position, or "category" to set category_position
+	// of todos in CategoryID (null for uncategorized todos)
+	Scope      string `json:"scope" validate:"omitempty,oneof=global category"`
+	CategoryID *int64 `json:"category_id"`
+	Todos      []struct {
 		ID       int64 `json:"id" validate:"required"`
 		Position int   `json:"position" validate:"required,min=0"`
 	} `json:"todos" validate:"required,dive"`
 }
 
+// ReorderTodoRequest represents the request body for moving a todo next to another
+type ReorderTodoRequest struct {
+	ID       int64  `json:"id" validate:"required"`
+	AfterID  *int64 `json:"after_id"`
+	BeforeID *int64 `json:"before_id"`
+	// Scope is "global" (default) to change position, or "category" to change category_position
+	Scope string `json:"scope" validate:"omitempty,oneof=global category"`
+}
+
 // TodoResponse represents a todo in API responses
 type TodoResponse struct {
-	ID          int64            `json:"id"`
-	CategoryID  *int64           `json:"category_id"`
-	Title       string           `json:"title"`
-	Description *string          `json:"description"`
-	Completed   bool             `json:"completed"`
-	Position    *int             `json:"position"`
-	Priority    string           `json:"priority"`
-	Status      string           `json:"status"`
-	DueDate     *string          `json:"due_date"`
-	CreatedAt   string           `json:"created_at"`
-	UpdatedAt   string           `json:"updated_at"`
-	Category    *CategorySummary `json:"category,omitempty"`
-	Tags        []TagSummary     `json:"tags,omitempty"`
+	ID               int64            `json:"id"`
+	UUID             *string          `json:"uuid"`
+	CategoryID       *int64           `json:"category_id"`
+	Title            string           `json:"title"`
+	Description      *string          `json:"description"`
+	Completed        bool             `json:"completed"`
+	Position         *int             `json:"position"`
+	CategoryPosition *int             `json:"category_position"`
+	SnoozeCount      int              `json:"snooze_count"`
+	Priority         string           `json:"priority"`
+	Status           string           `json:"status"`
+	DueDate          *string          `json:"due_date"`
+	CompletedAt      *string          `json:"completed_at"`
+	ArchivedAt       *string          `json:"archived_at"`
+	CreatedAt        string           `json:"created_at"`
+	UpdatedAt        string           `json:"updated_at"`
+	Category         *CategorySummary `json:"category,omitempty"`
+	Tags             []TagSummary     `json:"tags,omitempty"`
+	Warnings         []TodoWarning    `json:"warnings,omitempty"`
+	// Links and Backlinks are only included for a single todo
+	Links     []TodoLinkResponse `json:"links,omitempty"`
+	Backlinks []TodoLinkResponse `json:"backlinks,omitempty"`
+}
+
+// TodoWarning describes a soft limit the change went over
+type TodoWarning struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Limit      int    `json:"limit"`
+	InProgress int64  `json:"in_progress"`
 }
 
 // CategorySummary represents a category summary in todo responses
@@ -97,17 +131,30 @@ type TagSummary struct {
 // toTodoResponse converts a model.Todo to TodoResponse
 func toTodoResponse(todo *model.Todo) TodoResponse {
 	resp := TodoResponse{
-		ID:          todo.ID,
-		CategoryID:  todo.CategoryID,
-		Title:       todo.Title,
-		Description: todo.Description,
-		Completed:   todo.Completed,
-		Position:    todo.Position,
-		Priority:    todo.Priority.String(),
-		Status:      todo.Status.String(),
-		DueDate:     util.FormatDate(todo.DueDate),
-		CreatedAt:   util.FormatRFC3339(todo.CreatedAt),
-		UpdatedAt:   util.FormatRFC3339(todo.UpdatedAt),
+		ID:               todo.ID,
+		UUID:             todo.UUID,
+		CategoryID:       todo.CategoryID,
+		Title:            todo.Title,
+		Description:      todo.Description,
+		Completed:        todo.Completed,
+		Position:         todo.Position,
+		CategoryPosition: todo.CategoryPosition,
+		SnoozeCount:      todo.SnoozeCount,
+		Priority:         todo.Priority.String(),
+		Status:           todo.Status.String(),
+		DueDate:          util.FormatDate(todo.DueDate),
+		CreatedAt:        util.FormatRFC3339(todo.CreatedAt),
+		UpdatedAt:        util.FormatRFC3339(todo.UpdatedAt),
+	}
+
+	if todo.CompletedAt != nil {
+		completedAt := util.FormatRFC3339(*todo.CompletedAt)
+		resp.CompletedAt = &completedAt
+	}
+
+	if todo.ArchivedAt != nil {
+		archivedAt := util.FormatRFC3339(*todo.ArchivedAt)
+		resp.ArchivedAt = &archivedAt
 	}
 
 	if todo.Category != nil {
@@ -140,11 +187,27 @@ func (h *TodoHandler) List(c echo.Context) error {
 		return err
 	}
 
-	todos, err := h.todoRepo.FindAllByUserIDWithRelations(currentUser.ID)
+	groupBy := c.QueryParam("group_by")
+	if err := service.ValidateTodoGroupBy(groupBy); err != nil {
+		return err
+	}
+
+	todos, err := h.todoRepo.FindUnarchivedByUserIDWithRelations(c.Request().Context(), currentUser.ID)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "TodoHandler.List: failed to fetch todos")
 	}
 
+	if groupBy != "" {
+		groups, err := h.todoService.GroupTodos(c.Request().Context(), currentUser.ID, groupBy, todos)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, TodoGroupsResponse{
+			GroupBy: groupBy,
+			Groups:  toTodoGroupResponses(groups),
+		})
+	}
+
 	// Convert to response format
 	todoResponses := make([]TodoResponse, len(todos))
 	for i, todo := range todos {
@@ -154,6 +217,47 @@ func (h *TodoHandler) List(c echo.Context) error {
 	return c.JSON(http.StatusOK, todoResponses)
 }
 
+// TodoGroupsResponse represents todos grouped by one field
+type TodoGroupsResponse struct {
+	GroupBy string              `json:"group_by"`
+	Groups  []TodoGroupResponse `json:"groups"`
+}
+
+// TodoGroupResponse represents one group of todos
+type TodoGroupResponse struct {
+	// Key is the status, priority, or due bucket name, or the category ID (null for uncategorized)
+	Key      any              `json:"key"`
+	Category *CategorySummary `json:"category,omitempty"`
+	Count    int64            `json:"count"`
+	Todos    []TodoResponse   `json:"todos"`
+}
+
+func toTodoGroupResponses(groups []service.TodoGroup) []TodoGroupResponse {
+	responses := make([]TodoGroupResponse, len(groups))
+	for i, g := range groups {
+		resp := TodoGroupResponse{
+			Key:   g.Key,
+			Count: g.Count,
+			Todos: make([]TodoResponse, len(g.Todos)),
+		}
+		if g.Category != nil {
+			resp.Key = g.Category.ID
+			resp.Category = &CategorySummary{
+				ID:    g.Category.ID,
+				Name:  g.Category.Name,
+				Color: g.Category.Color,
+			}
+		} else if g.Key == "" {
+			resp.Key = nil
+		}
+		for j := range g.Todos {
+			resp.Todos[j] = toTodoResponse(&g.Todos[j])
+		}
+		responses[i] = resp
+	}
+	return responses
+}
+
 // Show retrieves a specific todo by ID
 // GET /api/v1/todos/:id
 func (h *TodoHandler) Show(c echo.Context) error {
@@ -167,7 +271,7 @@ func (h *TodoHandler) Show(c echo.Context) error {
 		return err
 	}
 
-	todo, err := h.todoRepo.FindByIDWithRelations(id, currentUser.ID)
+	todo, err := h.todoRepo.FindByIDWithRelations(c.Request().Context(), id, currentUser.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Todo", id)
@@ -175,7 +279,17 @@ func (h *TodoHandler) Show(c echo.Context) error {
 		return errors.InternalErrorWithLog(err, "TodoHandler.Show: failed to fetch todo")
 	}
 
-	return c.JSON(http.StatusOK, toTodoResponse(todo))
+	links, err := h.linkService.List(c.Request().Context(), todo.ID, currentUser.ID)
+	if err != nil {
+		return errors.InternalErrorWithLog(err, "TodoHandler.Show: failed to fetch links")
+	}
+
+	resp := toTodoResponse(todo)
+	linksResp := toTodoLinksResponse(links)
+	resp.Links = linksResp.Links
+	resp.Backlinks = linksResp.Backlinks
+
+	return c.JSON(http.StatusOK, resp)
 }
 
 // Create creates a new todo
@@ -191,7 +305,7 @@ func (h *TodoHandler) Create(c echo.Context) error {
 		return err
 	}
 
-	todo, err := h.todoService.Create(service.CreateInput{
+	todo, err := h.todoService.Create(c.Request().Context(), service.CreateInput{
 		UserID:      currentUser.ID,
 		Title:       req.Title,
 		Description: req.Description,
@@ -205,7 +319,14 @@ func (h *TodoHandler) Create(c echo.Context) error {
 		return err
 	}
 
-	return response.Created(c, toTodoResponse(todo))
+	resp := toTodoResponse(todo)
+	if todo.Status == model.StatusInProgress {
+		if err := h.addWIPWarning(c.Request().Context(), &resp, currentUser.ID); err != nil {
+			return err
+		}
+	}
+
+	return response.Created(c, resp)
 }
 
 // Update updates an existing todo
@@ -226,7 +347,7 @@ func (h *TodoHandler) Update(c echo.Context) error {
 		return err
 	}
 
-	todo, err := h.todoService.Update(id, currentUser.ID, service.UpdateInput{
+	todo, err := h.todoService.Update(c.Request().Context(), id, currentUser.ID, service.UpdateInput{
 		Title:       req.Title,
 		Description: req.Description,
 		CategoryID:  req.CategoryID,
@@ -244,7 +365,29 @@ func (h *TodoHandler) Update(c echo.Context) error {
 		return err
 	}
 
-	return response.OK(c, toTodoResponse(todo))
+	resp := toTodoResponse(todo)
+	if req.Status != nil && todo.Status == model.StatusInProgress {
+		if err := h.addWIPWarning(c.Request().Context(), &resp, currentUser.ID); err != nil {
+			return err
+		}
+	}
+
+	return response.OK(c, resp)
+}
+
+// addWIPWarning adds a warning to resp when the user is over a WIP limit configured to warn
+func (h *TodoHandler) addWIPWarning(ctx context.Context, resp *TodoResponse, userID int64) error {
+	wip, err := h.todoService.WIPWarning(ctx, userID)
+	if err != nil || wip == nil {
+		return err
+	}
+	resp.Warnings = append(resp.Warnings, TodoWarning{
+		Code:       "wip_limit_exceeded",
+		Message:    "The limit of in-progress todos has been exceeded",
+		Limit:      wip.Limit,
+		InProgress: wip.InProgress,
+	})
+	return nil
 }
 
 // Delete removes a todo
@@ -260,7 +403,7 @@ func (h *TodoHandler) Delete(c echo.Context) error {
 		return err
 	}
 
-	if err := h.todoService.Delete(id, currentUser.ID); err != nil {
+	if err := h.todoService.Delete(c.Request().Context(), id, currentUser.ID); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Todo", id)
 		}
@@ -292,13 +435,202 @@ func (h *TodoHandler) UpdateOrder(c echo.Context) error {
 		}
 	}
 
-	if err := h.todoRepo.UpdateOrder(currentUser.ID, updates); err != nil {
+	if req.Scope == "category" {
+		if err := h.todoRepo.UpdateCategoryOrder(c.Request().Context(), currentUser.ID, req.CategoryID, updates); err != nil {
+			return errors.InternalErrorWithLog(err, "TodoHandler.UpdateOrder: failed to update category order")
+		}
+		return response.NoContent(c)
+	}
+
+	if err := h.todoRepo.UpdateOrder(c.Request().Context(), currentUser.ID, updates); err != nil {
 		return errors.InternalErrorWithLog(err, "TodoHandler.UpdateOrder: failed to update order")
 	}
 
 	return response.NoContent(c)
 }
 
+// Reorder moves a single todo directly after or before another todo
+// PATCH /api/v1/todos/reorder
+func (h *TodoHandler) Reorder(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	var req ReorderTodoRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	todo, err := h.todoService.Reorder(c.Request().Context(), req.ID, currentUser.ID, service.ReorderInput{
+		AfterID:  req.AfterID,
+		BeforeID: req.BeforeID,
+		Scope:    req.Scope,
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("Todo", req.ID)
+		}
+		return err
+	}
+
+	return response.OK(c, toTodoResponse(todo))
+}
+
+// SnoozeTodoRequest represents the request body for snoozing a todo
+type SnoozeTodoRequest struct {
+	Preset   *string `json:"preset" validate:"omitempty,oneof=tomorrow next_week"`
+	Duration *string `json:"duration" validate:"omitempty,max=10"`
+}
+
+// Snooze pushes the due date of a todo back
+// POST /api/v1/todos/:id/snooze
+func (h *TodoHandler) Snooze(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := ParseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req SnoozeTodoRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	todo, err := h.todoService.Snooze(c.Request().Context(), id, currentUser.ID, service.SnoozeInput{
+		Preset:   req.Preset,
+		Duration: req.Duration,
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("Todo", id)
+		}
+		return err
+	}
+
+	return response.OK(c, toTodoResponse(todo))
+}
+
+// TodoStatsResponse represents the response for the stats endpoint
+type TodoStatsResponse struct {
+	Total    int64            `json:"total"`
+	ByStatus map[string]int64 `json:"by_status"`
+	WIP      WIPStatsResponse `json:"wip"`
+}
+
+// WIPStatsResponse represents the in-progress todos against the user's limit
+type WIPStatsResponse struct {
+	InProgress int64  `json:"in_progress"`
+	Limit      *int   `json:"limit"`
+	Mode       string `json:"mode"`
+	Exceeded   bool   `json:"exceeded"`
+}
+
+// Stats returns the number of todos per status and the current WIP against the limit
+// GET /api/v1/todos/stats
+func (h *TodoHandler) Stats(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	stats, err := h.todoService.Stats(c.Request().Context(), currentUser.ID)
+	if err != nil {
+		return err
+	}
+
+	resp := TodoStatsResponse{
+		Total:    stats.Total,
+		ByStatus: make(map[string]int64, len(stats.ByStatus)),
+		WIP: WIPStatsResponse{
+			InProgress: stats.WIP.InProgress,
+			Mode:       string(stats.WIP.Mode),
+			Exceeded:   stats.WIP.Exceeded,
+		},
+	}
+	for status, count := range stats.ByStatus {
+		resp.ByStatus[status.String()] = count
+	}
+	if stats.WIP.Limit > 0 {
+		limit := stats.WIP.Limit
+		resp.WIP.Limit = &limit
+	}
+
+	return response.OK(c, resp)
+}
+
+// TodoFacetsResponse represents the response for the facets endpoint
+type TodoFacetsResponse struct {
+	Total          int64                `json:"total"`
+	Status         []FacetValueResponse `json:"status"`
+	Priority       []FacetValueResponse `json:"priority"`
+	Category       []FacetValueResponse `json:"category"`
+	Tag            []FacetValueResponse `json:"tag"`
+	DueBucket      []FacetValueResponse `json:"due_bucket"`
+	FiltersApplied map[string]any       `json:"filters_applied"`
+}
+
+// FacetValueResponse represents the number of todos for one filter value
+type FacetValueResponse struct {
+	// Key is the status, priority, or due bucket name, or the category or tag ID (null for uncategorized)
+	Key      any              `json:"key"`
+	Category *CategorySummary `json:"category,omitempty"`
+	Tag      *TagSummary      `json:"tag,omitempty"`
+	Count    int64            `json:"count"`
+}
+
+func toFacetValueResponses(groups []service.TodoGroup) []FacetValueResponse {
+	responses := make([]FacetValueResponse, len(groups))
+	for i, g := range toTodoGroupResponses(groups) {
+		responses[i] = FacetValueResponse{Key: g.Key, Category: g.Category, Count: g.Count}
+	}
+	return responses
+}
+
+// Facets returns the number of todos per status, priority, category, tag, and due bucket
+// for the search filters. Each facet ignores its own filter.
+// GET /api/v1/todos/facets
+func (h *TodoHandler) Facets(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	searchInput, err := h.parseSearchParams(c)
+	if err != nil {
+		return err
+	}
+	searchInput.UserID = currentUser.ID
+
+	facets, err := h.todoService.Facets(c.Request().Context(), *searchInput)
+	if err != nil {
+		return err
+	}
+
+	resp := TodoFacetsResponse{
+		Total:          facets.Total,
+		Status:         toFacetValueResponses(facets.Status),
+		Priority:       toFacetValueResponses(facets.Priority),
+		Category:       toFacetValueResponses(facets.Category),
+		Tag:            make([]FacetValueResponse, len(facets.Tag)),
+		DueBucket:      toFacetValueResponses(facets.DueBucket),
+		FiltersApplied: h.buildFiltersApplied(searchInput),
+	}
+	for i, t := range facets.Tag {
+		resp.Tag[i] = FacetValueResponse{
+			Key:   t.TagID,
+			Tag:   &TagSummary{ID: t.TagID, Name: t.Name, Color: t.Color},
+			Count: t.Count,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 // SearchMetaResponse represents the meta information in search response
 type SearchMetaResponse struct {
 	Total          int64          `json:"total"`
@@ -315,11 +647,45 @@ type SearchSuggestion struct {
 	CurrentFilters []string `json:"current_filters,omitempty"`
 }
 
+// TodoSearchResult is a todo in search responses, with where the query matched
+type TodoSearchResult struct {
+	TodoResponse
+	Highlights map[string][]HighlightMatch `json:"highlights,omitempty"`
+	// Snippets holds the matched part of each field, HTML-escaped with <mark> tags
+	Snippets map[string]string `json:"snippets,omitempty"`
+}
+
+// todoHighlights finds the query in a todo's title and description
+func todoHighlights(todo *model.Todo, query string) (map[string][]HighlightMatch, map[string]string) {
+	fields := map[string]*string{
+		"title":       &todo.Title,
+		"description": todo.Description,
+	}
+
+	snippets := make(map[string]string)
+	for name, value := range fields {
+		if value == nil {
+			continue
+		}
+		if snippet := highlight.Snippet(*value, query, searchBodyMaxRunes); snippet != "" {
+			snippets[name] = snippet
+		}
+	}
+	if len(snippets) == 0 {
+		snippets = nil
+	}
+
+	return buildHighlights(query, fields), snippets
+}
+
 // SearchResponse represents the response for search endpoint
 type SearchResponse struct {
-	Data        []TodoResponse     `json:"data"`
+	Data        []TodoSearchResult `json:"data"`
 	Meta        SearchMetaResponse `json:"meta"`
 	Suggestions []SearchSuggestion `json:"suggestions,omitempty"`
+	// Groups holds the todos of this page by group_by, with counts over all pages
+	GroupBy string              `json:"group_by,omitempty"`
+	Groups  []TodoGroupResponse `json:"groups,omitempty"`
 }
 
 // Search searches todos with filters
@@ -349,15 +715,18 @@ func (h *TodoHandler) Search(c echo.Context) error {
 	}
 
 	// Execute search
-	result, err := h.todoService.Search(*searchInput)
+	result, err := h.todoService.Search(c.Request().Context(), *searchInput)
 	if err != nil {
 		return err
 	}
 
 	// Convert to response format
-	todoResponses := make([]TodoResponse, len(result.Todos))
+	todoResponses := make([]TodoSearchResult, len(result.Todos))
 	for i, todo := range result.Todos {
-		todoResponses[i] = toTodoResponse(&todo)
+		todoResponses[i] = TodoSearchResult{TodoResponse: toTodoResponse(&todo)}
+		if searchInput.Query != "" {
+			todoResponses[i].Highlights, todoResponses[i].Snippets = todoHighlights(&todo, searchInput.Query)
+		}
 	}
 
 	// Calculate total pages
@@ -372,7 +741,7 @@ func (h *TodoHandler) Search(c echo.Context) error {
 	// Generate suggestions for empty results
 	suggestions := h.generateSuggestions(result, searchInput)
 
-	return c.JSON(http.StatusOK, SearchResponse{
+	resp := SearchResponse{
 		Data: todoResponses,
 		Meta: SearchMetaResponse{
 			Total:          result.Total,
@@ -382,7 +751,13 @@ func (h *TodoHandler) Search(c echo.Context) error {
 			FiltersApplied: filtersApplied,
 		},
 		Suggestions: suggestions,
-	})
+	}
+	if result.Groups != nil {
+		resp.GroupBy = searchInput.GroupBy
+		resp.Groups = toTodoGroupResponses(result.Groups)
+	}
+
+	return c.JSON(http.StatusOK, resp)
 }
 
 // parseSearchParams parses query parameters for search
@@ -479,9 +854,63 @@ func (h *TodoHandler) parseSearchParams(c echo.Context) (*service.SearchInput, e
 		}
 	}
 
+	// Created and updated date range filters (dates in the user's time zone)
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"created_from", &input.CreatedFrom},
+		{"created_to", &input.CreatedTo},
+		{"updated_from", &input.UpdatedFrom},
+		{"updated_to", &input.UpdatedTo},
+	} {
+		date, err := util.ParseDate(c.QueryParam(param.name))
+		if err != nil {
+			return nil, errors.ValidationFailed(map[string][]string{
+				param.name: {"Invalid date format. Use YYYY-MM-DD"},
+			})
+		}
+		*param.target = date
+	}
+
+	// Archived todos are only searched when asked for
+	if archivedStr := c.QueryParam("archived"); archivedStr != "" {
+		archived, err := strconv.ParseBool(archivedStr)
+		if err != nil {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"archived": {"must be true or false"},
+			})
+		}
+		input.Archived = archived
+	}
+
+	// Fuzzy matching (typo tolerant) and its minimum similarity
+	if fuzzyStr := c.QueryParam("fuzzy"); fuzzyStr != "" {
+		fuzzy, err := strconv.ParseBool(fuzzyStr)
+		if err != nil {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"fuzzy": {"must be true or false"},
+			})
+		}
+		input.Fuzzy = fuzzy
+	}
+	if similarityStr := c.QueryParam("similarity"); similarityStr != "" {
+		similarity, err := strconv.ParseFloat(similarityStr, 64)
+		if err != nil {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"similarity": {"must be a number"},
+			})
+		}
+		input.Similarity = &similarity
+	}
+
+	// Grouping
+	input.GroupBy = c.QueryParam("group_by")
+
 	// Sort parameters
 	input.SortBy = c.QueryParam("sort_by")
 	input.SortOrder = c.QueryParam("sort_order")
+	input.Nulls = c.QueryParam("nulls")
 
 	// Pagination
 	if page := c.QueryParam("page"); page != "" {
@@ -504,6 +933,9 @@ func (h *TodoHandler) buildFiltersApplied(input *service.SearchInput) map[string
 
 	if input.Query != "" {
 		filters["search"] = input.Query
+		if input.Fuzzy {
+			filters["fuzzy"] = true
+		}
 	}
 
 	if len(input.Statuses) > 0 {
@@ -549,6 +981,21 @@ func (h *TodoHandler) buildFiltersApplied(input *service.SearchInput) map[string
 	if input.DueDateTo != nil {
 		filters["due_date_to"] = input.DueDateTo.Format("2006-01-02")
 	}
+	if input.CreatedFrom != nil {
+		filters["created_from"] = input.CreatedFrom.Format("2006-01-02")
+	}
+	if input.CreatedTo != nil {
+		filters["created_to"] = input.CreatedTo.Format("2006-01-02")
+	}
+	if input.UpdatedFrom != nil {
+		filters["updated_from"] = input.UpdatedFrom.Format("2006-01-02")
+	}
+	if input.UpdatedTo != nil {
+		filters["updated_to"] = input.UpdatedTo.Format("2006-01-02")
+	}
+	if input.Archived {
+		filters["archived"] = true
+	}
 
 	return filters
 }
@@ -588,6 +1035,12 @@ func (h *TodoHandler) generateSuggestions(result *service.SearchResult, input *s
 		if input.DueDateFrom != nil || input.DueDateTo != nil {
 			currentFilters = append(currentFilters, "期限")
 		}
+		if input.CreatedFrom != nil || input.CreatedTo != nil {
+			currentFilters = append(currentFilters, "作成日")
+		}
+		if input.UpdatedFrom != nil || input.UpdatedTo != nil {
+			currentFilters = append(currentFilters, "更新日")
+		}
 
 		suggestions = append(suggestions, SearchSuggestion{
 			Type:           "reduce_filters",
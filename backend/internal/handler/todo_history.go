@@ -75,7 +75,7 @@ func (h *TodoHistoryHandler) List(c echo.Context) error {
 	}
 
 	// Verify todo exists and belongs to user
-	_, err = h.todoRepo.FindByID(todoID, currentUser.ID)
+	_, err = h.todoRepo.FindByID(c.Request().Context(), todoID, currentUser.ID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.NotFound("Todo", todoID)
@@ -98,7 +98,7 @@ func (h *TodoHistoryHandler) List(c echo.Context) error {
 	}
 
 	// Fetch histories
-	histories, total, err := h.historyRepo.FindByTodoIDWithUser(todoID, page, perPage)
+	histories, total, err := h.historyRepo.FindByTodoIDWithUser(c.Request().Context(), todoID, currentUser.ID, page, perPage)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "TodoHistoryHandler.List: failed to fetch histories")
 	}
@@ -132,7 +132,7 @@ func toHistoryResponse(history *model.TodoHistory) HistoryResponse {
 		ID:                  history.ID,
 		TodoID:              history.TodoID,
 		Action:              string(history.Action),
-		Changes:             history.Changes,
+		Changes:             json.RawMessage(history.Changes),
 		CreatedAt:           util.FormatRFC3339(history.CreatedAt),
 		HumanReadableChange: generateHumanReadableChange(history),
 	}
@@ -154,17 +154,22 @@ func toHistoryResponse(history *model.TodoHistory) HistoryResponse {
 
 // generateHumanReadableChange generates a human-readable description of the change in Japanese
 func generateHumanReadableChange(history *model.TodoHistory) string {
+	changes := json.RawMessage(history.Changes)
 	switch history.Action {
 	case model.ActionCreated:
 		return "Todoが作成されました"
 	case model.ActionDeleted:
 		return "Todoが削除されました"
 	case model.ActionStatusChanged:
-		return generateStatusChangeMessage(history.Changes)
+		return generateStatusChangeMessage(changes)
 	case model.ActionPriorityChanged:
-		return generatePriorityChangeMessage(history.Changes)
+		return generatePriorityChangeMessage(changes)
+	case model.ActionPriorityEscalated:
+		return generatePriorityEscalationMessage(changes)
+	case model.ActionSnoozed:
+		return generateSnoozeMessage(changes)
 	case model.ActionUpdated:
-		return generateUpdateMessage(history.Changes)
+		return generateUpdateMessage(changes)
 	default:
 		return "変更されました"
 	}
@@ -202,6 +207,39 @@ func generatePriorityChangeMessage(changes json.RawMessage) string {
 	return "優先度が変更されました"
 }
 
+// generatePriorityEscalationMessage generates message for an automatic escalation
+func generatePriorityEscalationMessage(changes json.RawMessage) string {
+	var data map[string]interface{}
+	if err := json.Unmarshal(changes, &data); err != nil {
+		return "期限が近いため優先度が引き上げられました"
+	}
+
+	if priorityArr, ok := data["priority"].([]interface{}); ok && len(priorityArr) == 2 {
+		oldPriority := translatePriority(fmt.Sprint(priorityArr[0]))
+		newPriority := translatePriority(fmt.Sprint(priorityArr[1]))
+		return fmt.Sprintf("期限が近いため優先度が「%s」から「%s」に引き上げられました", oldPriority, newPriority)
+	}
+
+	return "期限が近いため優先度が引き上げられました"
+}
+
+// generateSnoozeMessage generates message for a snooze
+func generateSnoozeMessage(changes json.RawMessage) string {
+	var data map[string]interface{}
+	if err := json.Unmarshal(changes, &data); err != nil {
+		return "期限が延期されました"
+	}
+
+	if dateArr, ok := data["due_date"].([]interface{}); ok && len(dateArr) == 2 {
+		if dateArr[0] == nil {
+			return fmt.Sprintf("スヌーズにより期限が「%v」に設定されました", dateArr[1])
+		}
+		return fmt.Sprintf("スヌーズにより期限が「%v」から「%v」に延期されました", dateArr[0], dateArr[1])
+	}
+
+	return "期限が延期されました"
+}
+
 // generateUpdateMessage generates message for general updates
 func generateUpdateMessage(changes json.RawMessage) string {
 	var data map[string]interface{}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// TodoLinkHandler handles endpoints for links between todos
+type TodoLinkHandler struct {
+	linkService *service.TodoLinkService
+}
+
+// NewTodoLinkHandler creates a new TodoLinkHandler
+func NewTodoLinkHandler(linkService *service.TodoLinkService) *TodoLinkHandler {
+	return &TodoLinkHandler{linkService: linkService}
+}
+
+// CreateTodoLinkRequest represents the request body for linking a todo to another
+type CreateTodoLinkRequest struct {
+	TargetTodoID int64   `json:"target_todo_id" validate:"required"`
+	LinkType     *string `json:"link_type" validate:"omitempty,oneof=relates_to duplicates"`
+}
+
+// UpdateTodoLinkRequest represents the request body for changing a link
+type UpdateTodoLinkRequest struct {
+	LinkType string `json:"link_type" validate:"required,oneof=relates_to duplicates"`
+}
+
+// TodoLinksResponse represents the links from and to a todo
+type TodoLinksResponse struct {
+	Links     []TodoLinkResponse `json:"links"`
+	Backlinks []TodoLinkResponse `json:"backlinks"`
+}
+
+// TodoLinkResponse represents a link in API responses.
+// Todo is the todo at the other end of the link: the target for links, the source for backlinks.
+type TodoLinkResponse struct {
+	ID           int64              `json:"id"`
+	LinkType     string             `json:"link_type"`
+	SourceTodoID int64              `json:"source_todo_id"`
+	TargetTodoID int64              `json:"target_todo_id"`
+	Todo         *LinkedTodoSummary `json:"todo,omitempty"`
+	CreatedAt    string             `json:"created_at"`
+}
+
+// LinkedTodoSummary represents the linked todo in link responses
+type LinkedTodoSummary struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	Completed bool   `json:"completed"`
+}
+
+// toTodoLinkResponse converts a model.TodoLink to TodoLinkResponse showing the other todo
+func toTodoLinkResponse(link *model.TodoLink, other *model.Todo) TodoLinkResponse {
+	resp := TodoLinkResponse{
+		ID:           link.ID,
+		LinkType:     string(link.LinkType),
+		SourceTodoID: link.SourceTodoID,
+		TargetTodoID: link.TargetTodoID,
+		CreatedAt:    util.FormatRFC3339(link.CreatedAt),
+	}
+	if other != nil {
+		resp.Todo = &LinkedTodoSummary{
+			ID:        other.ID,
+			Title:     other.Title,
+			Status:    other.Status.String(),
+			Completed: other.Completed,
+		}
+	}
+	return resp
+}
+
+// toTodoLinksResponse converts service.TodoLinks to TodoLinksResponse
+func toTodoLinksResponse(links *service.TodoLinks) TodoLinksResponse {
+	resp := TodoLinksResponse{
+		Links:     make([]TodoLinkResponse, len(links.Links)),
+		Backlinks: make([]TodoLinkResponse, len(links.Backlinks)),
+	}
+	for i := range links.Links {
+		resp.Links[i] = toTodoLinkResponse(&links.Links[i], links.Links[i].TargetTodo)
+	}
+	for i := range links.Backlinks {
+		resp.Backlinks[i] = toTodoLinkResponse(&links.Backlinks[i], links.Backlinks[i].SourceTodo)
+	}
+	return resp
+}
+
+// toTodoLinkResponseFrom converts a link seen from the todo in the path
+func toTodoLinkResponseFrom(link *model.TodoLink, todoID int64) TodoLinkResponse {
+	if link.SourceTodoID == todoID {
+		return toTodoLinkResponse(link, link.TargetTodo)
+	}
+	return toTodoLinkResponse(link, link.SourceTodo)
+}
+
+// List retrieves the links from and to a todo
+// GET /api/v1/todos/:todo_id/links
+func (h *TodoLinkHandler) List(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	todoID, err := ParseIDParam(c, "todo_id")
+	if err != nil {
+		return err
+	}
+
+	links, err := h.linkService.List(c.Request().Context(), todoID, currentUser.ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("Todo", todoID)
+		}
+		return err
+	}
+
+	return response.OK(c, toTodoLinksResponse(links))
+}
+
+// Create links a todo to another todo
+// POST /api/v1/todos/:todo_id/links
+func (h *TodoLinkHandler) Create(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	todoID, err := ParseIDParam(c, "todo_id")
+	if err != nil {
+		return err
+	}
+
+	var req CreateTodoLinkRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	link, err := h.linkService.Create(c.Request().Context(), todoID, currentUser.ID, service.CreateTodoLinkInput{
+		TargetTodoID: req.TargetTodoID,
+		LinkType:     req.LinkType,
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("Todo", todoID)
+		}
+		return err
+	}
+
+	return response.Created(c, toTodoLinkResponseFrom(link, todoID))
+}
+
+// Update changes the type of a link from or to a todo
+// PATCH /api/v1/todos/:todo_id/links/:id
+func (h *TodoLinkHandler) Update(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	todoID, err := ParseIDParam(c, "todo_id")
+	if err != nil {
+		return err
+	}
+
+	linkID, err := ParseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req UpdateTodoLinkRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	link, err := h.linkService.Update(c.Request().Context(), todoID, linkID, currentUser.ID, req.LinkType)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("TodoLink", linkID)
+		}
+		return err
+	}
+
+	return response.OK(c, toTodoLinkResponseFrom(link, todoID))
+}
+
+// Delete removes a link from or to a todo
+// DELETE /api/v1/todos/:todo_id/links/:id
+func (h *TodoLinkHandler) Delete(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	todoID, err := ParseIDParam(c, "todo_id")
+	if err != nil {
+		return err
+	}
+
+	linkID, err := ParseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err := h.linkService.Delete(c.Request().Context(), todoID, linkID, currentUser.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.NotFound("TodoLink", linkID)
+		}
+		return errors.InternalErrorWithLog(err, "TodoLinkHandler.Delete: failed to delete link")
+	}
+
+	return response.NoContent(c)
+}
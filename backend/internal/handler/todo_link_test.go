@@ -0,0 +1,119 @@
+package handler_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/testutil"
+)
+
+// linksPath returns the links path of a todo
+func linksPath(todoID int64) string {
+	return fmt.Sprintf("%s/links", testutil.TodoPath(todoID))
+}
+
+// TestTodoLinkCreate_ShownOnBothTodos tests that a link appears on the source todo and as a backlink on the target todo
+func TestTodoLinkCreate_ShownOnBothTodos(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("linkcreate@example.com")
+	source := f.CreateTodo(user.ID, "Write report")
+	target := f.CreateTodo(user.ID, "Collect data")
+
+	rec, err := f.CallAuth(token, http.MethodPost, linksPath(source.ID), fmt.Sprintf(`{"target_todo_id":%d}`, target.ID), f.TodoLinkHandler.Create)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "relates_to", response["link_type"])
+	assert.Equal(t, float64(target.ID), response["todo"].(map[string]any)["id"])
+
+	rec, err = f.CallAuth(token, http.MethodGet, linksPath(target.ID), "", f.TodoLinkHandler.List)
+	require.NoError(t, err)
+
+	response = testutil.JSONResponse(t, rec)
+	assert.Empty(t, response["links"])
+	backlinks := response["backlinks"].([]any)
+	require.Len(t, backlinks, 1)
+	assert.Equal(t, "Write report", backlinks[0].(map[string]any)["todo"].(map[string]any)["title"])
+
+	// The single todo response includes the links
+	rec, err = f.CallAuth(token, http.MethodGet, testutil.TodoPath(source.ID), "", f.TodoHandler.Show)
+	require.NoError(t, err)
+
+	response = testutil.JSONResponse(t, rec)
+	links := response["links"].([]any)
+	require.Len(t, links, 1)
+	assert.Equal(t, "Collect data", links[0].(map[string]any)["todo"].(map[string]any)["title"])
+}
+
+// TestTodoLinkCreate_ValidationError tests invalid and duplicate links
+func TestTodoLinkCreate_ValidationError(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("linkinvalid@example.com")
+	other, _ := f.CreateUser("linkother@example.com")
+	source := f.CreateTodo(user.ID, "Source")
+	target := f.CreateTodo(user.ID, "Target")
+	otherTodo := f.CreateTodo(other.ID, "Other")
+
+	_, err := f.CallAuth(token, http.MethodPost, linksPath(source.ID), fmt.Sprintf(`{"target_todo_id":%d,"link_type":"duplicates"}`, target.ID), f.TodoLinkHandler.Create)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		todoID int64
+		body   string
+	}{
+		{name: "missing target", todoID: source.ID, body: `{}`},
+		{name: "itself", todoID: source.ID, body: fmt.Sprintf(`{"target_todo_id":%d}`, source.ID)},
+		{name: "other user's target", todoID: source.ID, body: fmt.Sprintf(`{"target_todo_id":%d}`, otherTodo.ID)},
+		{name: "other user's todo", todoID: otherTodo.ID, body: fmt.Sprintf(`{"target_todo_id":%d}`, source.ID)},
+		{name: "invalid type", todoID: source.ID, body: fmt.Sprintf(`{"target_todo_id":%d,"link_type":"blocks"}`, target.ID)},
+		{name: "duplicate in reverse", todoID: target.ID, body: fmt.Sprintf(`{"target_todo_id":%d,"link_type":"duplicates"}`, source.ID)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.CallAuth(token, http.MethodPost, linksPath(tt.todoID), tt.body, f.TodoLinkHandler.Create)
+			require.Error(t, err)
+		})
+	}
+}
+
+// TestTodoLinkUpdateAndDelete tests changing and removing a link from the target todo
+func TestTodoLinkUpdateAndDelete(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("linkupdate@example.com")
+	source := f.CreateTodo(user.ID, "Source")
+	target := f.CreateTodo(user.ID, "Target")
+	unrelated := f.CreateTodo(user.ID, "Unrelated")
+
+	rec, err := f.CallAuth(token, http.MethodPost, linksPath(source.ID), fmt.Sprintf(`{"target_todo_id":%d}`, target.ID), f.TodoLinkHandler.Create)
+	require.NoError(t, err)
+	linkID := testutil.JSONResponse(t, rec)["id"]
+
+	rec, err = f.CallAuth(token, http.MethodPatch, fmt.Sprintf("%s/%v", linksPath(target.ID), linkID), `{"link_type":"duplicates"}`, f.TodoLinkHandler.Update)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "duplicates", response["link_type"])
+	// Seen from the target, the other end is the source
+	assert.Equal(t, float64(source.ID), response["todo"].(map[string]any)["id"])
+
+	// The link does not belong to an unrelated todo
+	_, err = f.CallAuth(token, http.MethodDelete, fmt.Sprintf("%s/%v", linksPath(unrelated.ID), linkID), "", f.TodoLinkHandler.Delete)
+	require.Error(t, err)
+
+	_, err = f.CallAuth(token, http.MethodDelete, fmt.Sprintf("%s/%v", linksPath(target.ID), linkID), "", f.TodoLinkHandler.Delete)
+	require.NoError(t, err)
+
+	rec, err = f.CallAuth(token, http.MethodGet, linksPath(source.ID), "", f.TodoLinkHandler.List)
+	require.NoError(t, err)
+	assert.Empty(t, testutil.JSONResponse(t, rec)["links"])
+}
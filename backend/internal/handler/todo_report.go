@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/model"
+	"todo-api/internal/pdf"
+	"todo-api/internal/service"
+	"todo-api/pkg/util"
+)
+
+// Layout of the PDF report in points (A4 portrait)
+const (
+	reportMargin    = 40.0
+	reportWidth     = pdf.A4Width - 2*reportMargin
+	reportRowHeight = 16.0
+	reportFontSize  = 8.5
+)
+
+// reportColumn is a column of the todo table in the PDF report
+type reportColumn struct {
+	title string
+	width float64
+	value func(todo *model.Todo, report *service.TodoReport) string
+}
+
+// reportColumns lists the columns of the todo table; their widths add up to reportWidth
+var reportColumns = []reportColumn{
+	{"タイトル", reportWidth - 315, func(todo *model.Todo, _ *service.TodoReport) string { return todo.Title }},
+	{"ステータス", 50, func(todo *model.Todo, _ *service.TodoReport) string { return translateStatus(todo.Status.String()) }},
+	{"優先度", 35, func(todo *model.Todo, _ *service.TodoReport) string { return translatePriority(todo.Priority.String()) }},
+	{"期限", 65, func(todo *model.Todo, _ *service.TodoReport) string {
+		return util.DerefString(util.FormatDate(todo.DueDate), "-")
+	}},
+	{"カテゴリ", 95, func(todo *model.Todo, _ *service.TodoReport) string {
+		if todo.Category == nil {
+			return "-"
+		}
+		return todo.Category.Name
+	}},
+	{"更新日", 70, func(todo *model.Todo, report *service.TodoReport) string {
+		return todo.UpdatedAt.In(report.GeneratedAt.Location()).Format("2006-01-02")
+	}},
+}
+
+// dueBucketLabels are the Japanese labels of due buckets in the PDF report
+var dueBucketLabels = map[string]string{
+	model.DueBucketOverdue:   "期限切れ",
+	model.DueBucketToday:     "今日",
+	model.DueBucketNext7Days: "7日以内",
+	model.DueBucketLater:     "それ以降",
+	model.DueBucketNoDueDate: "期限なし",
+}
+
+// Report streams a printable PDF report of the todos matching the search parameters,
+// with counts per status, priority, and due date. Pagination parameters are ignored.
+// GET /api/v1/todos/report.pdf
+func (h *TodoHandler) Report(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	searchInput, err := h.parseSearchParams(c)
+	if err != nil {
+		return err
+	}
+	searchInput.UserID = currentUser.ID
+
+	report, err := h.todoService.Report(c.Request().Context(), *searchInput)
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("todo-report-%s.pdf", report.GeneratedAt.Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentType, "application/pdf")
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().WriteHeader(http.StatusOK)
+
+	return writeTodoReportPDF(c.Response(), report, h.buildFiltersApplied(searchInput))
+}
+
+// writeTodoReportPDF lays out the report: a header with the filters, the summary, and a table of the todos
+// continued over as many pages as needed
+func writeTodoReportPDF(w io.Writer, report *service.TodoReport, filters map[string]any) error {
+	doc := pdf.New(pdf.A4Width, pdf.A4Height)
+	doc.SetTitle("Todoレポート")
+	doc.AddPage()
+
+	// Header
+	y := reportMargin + 18
+	doc.Text(reportMargin, y, 18, "Todoレポート")
+	y += 20
+	doc.Text(reportMargin, y, 9, "作成日時: "+report.GeneratedAt.Format("2006-01-02 15:04 MST"))
+	y += 14
+	doc.Text(reportMargin, y, 9, pdf.Truncate("条件: "+formatReportFilters(filters), 9, reportWidth))
+	y += 14
+
+	// Summary
+	doc.FillRect(reportMargin, y, reportWidth, 64, 0.95)
+	y += 17
+	doc.Text(reportMargin+10, y, 10, fmt.Sprintf("件数: %d件　完了率: %.0f%%", report.Total, report.CompletionRate()*100))
+	y += 15
+	doc.Text(reportMargin+10, y, 9, "ステータス: "+formatReportGroups(report.Status, translateStatus))
+	y += 13
+	doc.Text(reportMargin+10, y, 9, "優先度: "+formatReportGroups(report.Priority, translatePriority))
+	y += 13
+	doc.Text(reportMargin+10, y, 9, "期限: "+formatReportGroups(report.DueBucket, func(key string) string {
+		return dueBucketLabels[key]
+	}))
+	y += 26
+
+	if int64(len(report.Todos)) < report.Total {
+		doc.Text(reportMargin, y, 9, fmt.Sprintf("全%d件のうち先頭%d件を表示しています", report.Total, len(report.Todos)))
+		y += 14
+	}
+	if len(report.Todos) == 0 {
+		doc.Text(reportMargin, y, 10, "条件に一致するTodoはありません")
+	} else {
+		y = writeReportTableHeader(doc, y)
+		for i := range report.Todos {
+			if y+reportRowHeight > pdf.A4Height-reportMargin {
+				doc.AddPage()
+				y = writeReportTableHeader(doc, reportMargin)
+			}
+			x := reportMargin
+			for _, column := range reportColumns {
+				value := pdf.Truncate(column.value(&report.Todos[i], report), reportFontSize, column.width-6)
+				doc.Text(x+3, y+11, reportFontSize, value)
+				x += column.width
+			}
+			y += reportRowHeight
+			doc.Line(reportMargin, y, reportMargin+reportWidth, y, 0.5, 0.8)
+		}
+	}
+
+	// Page numbers
+	for i := 0; i < doc.PageCount(); i++ {
+		doc.SetPage(i)
+		doc.TextRight(reportMargin+reportWidth, pdf.A4Height-20, 8, fmt.Sprintf("%d / %d", i+1, doc.PageCount()))
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// writeReportTableHeader draws the column titles at y and returns the y of the first row
+func writeReportTableHeader(doc *pdf.Document, y float64) float64 {
+	doc.FillRect(reportMargin, y, reportWidth, reportRowHeight, 0.88)
+	x := reportMargin
+	for _, column := range reportColumns {
+		doc.Text(x+3, y+11, reportFontSize, column.title)
+		x += column.width
+	}
+	return y + reportRowHeight
+}
+
+// formatReportGroups formats group counts as "label n / label n"
+func formatReportGroups(groups []service.TodoGroup, label func(string) string) string {
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		parts[i] = fmt.Sprintf("%s %d", label(group.Key), group.Count)
+	}
+	return strings.Join(parts, " / ")
+}
+
+// formatReportFilters formats the applied filters as "name=value" pairs sorted by name
+func formatReportFilters(filters map[string]any) string {
+	if len(filters) == 0 {
+		return "なし"
+	}
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		value := filters[name]
+		switch v := value.(type) {
+		case nil:
+			value = "none"
+		case []string:
+			value = strings.Join(v, ",")
+		case []int64:
+			ids := make([]string, len(v))
+			for j, id := range v {
+				ids[j] = fmt.Sprint(id)
+			}
+			value = strings.Join(ids, ",")
+		}
+		parts[i] = fmt.Sprintf("%s=%v", name, value)
+	}
+	return strings.Join(parts, ", ")
+}
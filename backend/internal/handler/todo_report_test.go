@@ -0,0 +1,118 @@
+package handler_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/model"
+	"todo-api/internal/testutil"
+)
+
+const reportPath = "/api/v1/todos/report.pdf"
+
+// pdfContents returns the decompressed content streams of a PDF
+func pdfContents(t *testing.T, body []byte) string {
+	t.Helper()
+	var contents strings.Builder
+	for _, part := range bytes.Split(body, []byte(">>\nstream\n"))[1:] {
+		end := bytes.Index(part, []byte("\nendstream"))
+		require.GreaterOrEqual(t, end, 0)
+		zr, err := zlib.NewReader(bytes.NewReader(part[:end]))
+		require.NoError(t, err)
+		data, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		contents.Write(data)
+	}
+	return contents.String()
+}
+
+// pdfText returns s as drawn in the report's content streams
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	return "<" + b.String() + ">"
+}
+
+// TestTodoReport_PDF tests the PDF report of the todos matching the search filters
+func TestTodoReport_PDF(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("report@example.com")
+	other, _ := f.CreateUser("reportother@example.com")
+	category := f.CreateCategory(user.ID, "仕事", "#3B82F6")
+
+	f.CreateTodoWithDetails(user.ID, "見積書を送る", testutil.TodoOptions{DueDate: dueIn(1), CategoryID: &category.ID})
+	done := f.CreateTodo(user.ID, "Book flights")
+	require.NoError(t, f.DB.Model(done).Updates(map[string]any{"status": model.StatusCompleted, "completed": true}).Error)
+	f.CreateTodo(other.ID, "Someone else's todo")
+
+	rec, err := f.CallAuth(token, http.MethodGet, reportPath, "", f.TodoHandler.Report)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment; filename=\"todo-report-")
+
+	body := rec.Body.Bytes()
+	assert.True(t, bytes.HasPrefix(body, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(body, []byte("%%EOF\n")))
+	assert.Equal(t, 1, bytes.Count(body, []byte("/Type /Page /Parent")))
+
+	contents := pdfContents(t, body)
+	assert.Contains(t, contents, pdfText("見積書を送る"))
+	assert.Contains(t, contents, pdfText("仕事"))
+	assert.Contains(t, contents, pdfText("Book flights"))
+	assert.Contains(t, contents, pdfText("件数: 2件　完了率: 50%"))
+	assert.NotContains(t, contents, pdfText("Someone else's todo"))
+
+	// Search filters apply to the report
+	rec, err = f.CallAuth(token, http.MethodGet, reportPath+"?status=completed", "", f.TodoHandler.Report)
+	require.NoError(t, err)
+	contents = pdfContents(t, rec.Body.Bytes())
+	assert.Contains(t, contents, pdfText("Book flights"))
+	assert.NotContains(t, contents, pdfText("見積書を送る"))
+	assert.Contains(t, contents, pdfText("条件: status=completed"))
+}
+
+// TestTodoReport_Pages tests that long reports continue over several pages
+func TestTodoReport_Pages(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("reportpages@example.com")
+	for i := 1; i <= 60; i++ {
+		f.CreateTodo(user.ID, fmt.Sprintf("Todo %d", i))
+	}
+
+	rec, err := f.CallAuth(token, http.MethodGet, reportPath+"?per_page=10", "", f.TodoHandler.Report)
+	require.NoError(t, err)
+
+	body := rec.Body.Bytes()
+	assert.Equal(t, 2, bytes.Count(body, []byte("/Type /Page /Parent")))
+
+	// Pagination parameters are ignored
+	contents := pdfContents(t, body)
+	assert.Contains(t, contents, pdfText("Todo 1"))
+	assert.Contains(t, contents, pdfText("Todo 60"))
+	assert.Contains(t, contents, pdfText("2 / 2"))
+}
+
+// TestTodoReport_InvalidFilter tests that invalid search parameters are rejected
+func TestTodoReport_InvalidFilter(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("reportinvalid@example.com")
+
+	_, err := f.CallAuth(token, http.MethodGet, reportPath+"?sort_by=unknown", "", f.TodoHandler.Report)
+	assertAPIError(t, err, http.StatusUnprocessableEntity)
+}
@@ -1,15 +1,18 @@
 package handler_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"todo-api/internal/model"
 	"todo-api/internal/testutil"
+	"todo-api/pkg/database"
 )
 
 // TestTodoList_Success tests successful todo list retrieval
@@ -66,6 +69,110 @@ func TestTodoList_UserScope(t *testing.T) {
 	assert.Equal(t, "User1 Todo", firstTodo["title"])
 }
 
+// TestTodoList_GroupByStatus tests that todos are grouped by status with counts
+func TestTodoList_GroupByStatus(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("groupstatus@example.com")
+	f.CreateTodo(user.ID, "Pending 1")
+	f.CreateTodo(user.ID, "Pending 2")
+	f.CreateTodoWithDetails(user.ID, "Done", testutil.TodoOptions{Status: model.StatusCompleted})
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos?group_by=status", "", f.TodoHandler.List)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "status", response["group_by"])
+
+	groups := response["groups"].([]any)
+	require.Len(t, groups, 3)
+	expected := []struct {
+		key   string
+		count int
+	}{{"pending", 2}, {"in_progress", 0}, {"completed", 1}}
+	for i, e := range expected {
+		group := groups[i].(map[string]any)
+		assert.Equal(t, e.key, group["key"])
+		assert.Equal(t, float64(e.count), group["count"])
+		assert.Len(t, group["todos"], e.count)
+	}
+}
+
+// TestTodoList_GroupByCategory tests grouping by category including uncategorized todos
+func TestTodoList_GroupByCategory(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("groupcategory@example.com")
+	work := f.CreateCategory(user.ID, "Work", "#3B82F6")
+	f.CreateCategory(user.ID, "Private", "#10B981")
+	f.CreateTodoWithDetails(user.ID, "Report", testutil.TodoOptions{CategoryID: &work.ID})
+	f.CreateTodo(user.ID, "Loose")
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos?group_by=category", "", f.TodoHandler.List)
+	require.NoError(t, err)
+
+	groups := testutil.JSONResponse(t, rec)["groups"].([]any)
+	require.Len(t, groups, 3)
+
+	// Categories are ordered by name, uncategorized last; names are stored lowercased
+	private := groups[0].(map[string]any)
+	assert.Equal(t, "private", private["category"].(map[string]any)["name"])
+	assert.Equal(t, float64(0), private["count"])
+
+	workGroup := groups[1].(map[string]any)
+	assert.Equal(t, float64(work.ID), workGroup["key"])
+	assert.Equal(t, float64(1), workGroup["count"])
+
+	uncategorized := groups[2].(map[string]any)
+	assert.Nil(t, uncategorized["key"])
+	assert.Nil(t, uncategorized["category"])
+	assert.Equal(t, float64(1), uncategorized["count"])
+}
+
+// TestTodoList_GroupByDueBucket tests grouping by how soon todos are due
+func TestTodoList_GroupByDueBucket(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("groupdue@example.com")
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	nextWeek := now.AddDate(0, 0, 3)
+	nextMonth := now.AddDate(0, 1, 0)
+	f.CreateTodoWithDetails(user.ID, "Late", testutil.TodoOptions{DueDate: &yesterday})
+	f.CreateTodoWithDetails(user.ID, "Today", testutil.TodoOptions{DueDate: &now})
+	f.CreateTodoWithDetails(user.ID, "Soon", testutil.TodoOptions{DueDate: &nextWeek})
+	f.CreateTodoWithDetails(user.ID, "Later", testutil.TodoOptions{DueDate: &nextMonth})
+	f.CreateTodo(user.ID, "Someday")
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos?group_by=due_bucket", "", f.TodoHandler.List)
+	require.NoError(t, err)
+
+	groups := testutil.JSONResponse(t, rec)["groups"].([]any)
+	require.Len(t, groups, 5)
+	for i, e := range []struct{ key, title string }{
+		{"overdue", "Late"}, {"today", "Today"}, {"next_7_days", "Soon"}, {"later", "Later"}, {"no_due_date", "Someday"},
+	} {
+		group := groups[i].(map[string]any)
+		assert.Equal(t, e.key, group["key"])
+		todos := group["todos"].([]any)
+		require.Len(t, todos, 1, e.key)
+		assert.Equal(t, e.title, todos[0].(map[string]any)["title"])
+	}
+}
+
+// TestTodoList_InvalidGroupBy tests validation of group_by
+func TestTodoList_InvalidGroupBy(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("groupinvalid@example.com")
+
+	_, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos?group_by=color", "", f.TodoHandler.List)
+	require.Error(t, err)
+
+	_, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?group_by=color", "", f.TodoHandler.Search)
+	require.Error(t, err)
+}
+
 // TestTodoCreate_Success tests successful todo creation
 func TestTodoCreate_Success(t *testing.T) {
 	f := testutil.SetupTestFixture(t)
@@ -287,6 +394,297 @@ func TestTodoUpdateOrder_Success(t *testing.T) {
 	assert.Equal(t, 2, *updated3.Position)
 }
 
+// TestTodoUpdateOrder_CategoryScope tests reordering within one category
+func TestTodoUpdateOrder_CategoryScope(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("categoryorder@example.com")
+	work := f.CreateCategory(user.ID, "Work", "#3B82F6")
+	home := f.CreateCategory(user.ID, "Home", "#10B981")
+	work1 := f.CreateTodoWithDetails(user.ID, "Work 1", testutil.TodoOptions{CategoryID: &work.ID})
+	home1 := f.CreateTodoWithDetails(user.ID, "Home 1", testutil.TodoOptions{CategoryID: &home.ID})
+	work2 := f.CreateTodoWithDetails(user.ID, "Work 2", testutil.TodoOptions{CategoryID: &work.ID})
+
+	// Positions within a category are numbered independently of other categories
+	assert.Equal(t, 1, *work1.CategoryPosition)
+	assert.Equal(t, 1, *home1.CategoryPosition)
+	assert.Equal(t, 2, *work2.CategoryPosition)
+
+	// The todo from another category is ignored
+	body := fmt.Sprintf(`{"scope":"category","category_id":%d,"todos":[{"id":%d,"position":2},{"id":%d,"position":1},{"id":%d,"position":5}]}`,
+		work.ID, work1.ID, work2.ID, home1.ID)
+	_, err := f.CallAuth(token, http.MethodPatch, "/api/v1/todos/update_order", body, f.TodoHandler.UpdateOrder)
+	require.NoError(t, err)
+
+	var updated1, updated2, updatedHome model.Todo
+	f.DB.First(&updated1, work1.ID)
+	f.DB.First(&updated2, work2.ID)
+	f.DB.First(&updatedHome, home1.ID)
+
+	assert.Equal(t, 2, *updated1.CategoryPosition)
+	assert.Equal(t, 1, *updated2.CategoryPosition)
+	assert.Equal(t, 1, *updatedHome.CategoryPosition)
+	// Global positions are unchanged
+	assert.Equal(t, *work1.Position, *updated1.Position)
+	assert.Equal(t, *work2.Position, *updated2.Position)
+}
+
+// TestTodoReorder_Success tests moving a todo after and before another todo
+func TestTodoReorder_Success(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("reorder@example.com")
+	todo1 := f.CreateTodoWithPosition(user.ID, "Todo 1", 1)
+	todo2 := f.CreateTodoWithPosition(user.ID, "Todo 2", 2)
+	todo3 := f.CreateTodoWithPosition(user.ID, "Todo 3", 3)
+
+	positions := func() []int {
+		var t1, t2, t3 model.Todo
+		f.DB.First(&t1, todo1.ID)
+		f.DB.First(&t2, todo2.ID)
+		f.DB.First(&t3, todo3.ID)
+		return []int{*t1.Position, *t2.Position, *t3.Position}
+	}
+
+	// Todo 1 after Todo 3: 2, 3, 1
+	body := fmt.Sprintf(`{"id":%d,"after_id":%d}`, todo1.ID, todo3.ID)
+	rec, err := f.CallAuth(token, http.MethodPatch, "/api/v1/todos/reorder", body, f.TodoHandler.Reorder)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	p := positions()
+	assert.Less(t, p[1], p[2])
+	assert.Less(t, p[2], p[0])
+	assert.Equal(t, float64(p[0]), testutil.JSONResponse(t, rec)["position"])
+
+	// Todo 3 before Todo 2: 3, 2, 1
+	body = fmt.Sprintf(`{"id":%d,"before_id":%d}`, todo3.ID, todo2.ID)
+	_, err = f.CallAuth(token, http.MethodPatch, "/api/v1/todos/reorder", body, f.TodoHandler.Reorder)
+	require.NoError(t, err)
+
+	p = positions()
+	assert.Less(t, p[2], p[1])
+	assert.Less(t, p[1], p[0])
+}
+
+// TestTodoReorder_ValidationError tests invalid anchors
+func TestTodoReorder_ValidationError(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("reorderinvalid@example.com")
+	other, _ := f.CreateUser("reorderother@example.com")
+	work := f.CreateCategory(user.ID, "Work", "#3B82F6")
+	todo := f.CreateTodoWithDetails(user.ID, "Work", testutil.TodoOptions{CategoryID: &work.ID})
+	uncategorized := f.CreateTodo(user.ID, "Uncategorized")
+	otherTodo := f.CreateTodo(other.ID, "Other")
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "no anchor", body: fmt.Sprintf(`{"id":%d}`, todo.ID)},
+		{name: "both anchors", body: fmt.Sprintf(`{"id":%d,"after_id":%d,"before_id":%d}`, todo.ID, uncategorized.ID, uncategorized.ID)},
+		{name: "itself", body: fmt.Sprintf(`{"id":%d,"after_id":%d}`, todo.ID, todo.ID)},
+		{name: "other user's anchor", body: fmt.Sprintf(`{"id":%d,"after_id":%d}`, todo.ID, otherTodo.ID)},
+		{name: "other user's todo", body: fmt.Sprintf(`{"id":%d,"after_id":%d}`, otherTodo.ID, todo.ID)},
+		{name: "anchor in another category", body: fmt.Sprintf(`{"id":%d,"after_id":%d,"scope":"category"}`, todo.ID, uncategorized.ID)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.CallAuth(token, http.MethodPatch, "/api/v1/todos/reorder", tt.body, f.TodoHandler.Reorder)
+			require.Error(t, err)
+		})
+	}
+}
+
+// TestTodoUpdate_CategoryChangeAppendsCategoryPosition tests that moving a todo puts it at the end of the new category
+func TestTodoUpdate_CategoryChangeAppendsCategoryPosition(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("categorymove@example.com")
+	work := f.CreateCategory(user.ID, "Work", "#3B82F6")
+	f.CreateTodoWithDetails(user.ID, "Work 1", testutil.TodoOptions{CategoryID: &work.ID})
+	f.CreateTodoWithDetails(user.ID, "Work 2", testutil.TodoOptions{CategoryID: &work.ID})
+	todo := f.CreateTodo(user.ID, "Loose")
+
+	body := fmt.Sprintf(`{"category_id":%d}`, work.ID)
+	rec, err := f.CallAuth(token, http.MethodPatch, testutil.TodoPath(todo.ID), body, f.TodoHandler.Update)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(3), response["category_position"])
+}
+
+// TestTodoUpdate_WIPLimitReject tests that moving a todo to in_progress over the limit is rejected
+func TestTodoUpdate_WIPLimitReject(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("wipreject@example.com")
+	_, err := f.CallAuth(token, http.MethodPatch, preferencesPath, `{"wip_limit":1}`, f.PreferenceHandler.Update)
+	require.NoError(t, err)
+
+	f.CreateTodoWithDetails(user.ID, "Doing", testutil.TodoOptions{Status: model.StatusInProgress})
+	todo := f.CreateTodo(user.ID, "Next")
+
+	body := `{"status":"in_progress"}`
+	_, err = f.CallAuth(token, http.MethodPatch, testutil.TodoPath(todo.ID), body, f.TodoHandler.Update)
+	assertAPIError(t, err, http.StatusConflict)
+
+	// Creating a todo directly in progress is rejected as well
+	body = `{"title":"Also doing","status":"in_progress"}`
+	_, err = f.CallAuth(token, http.MethodPost, "/api/v1/todos", body, f.TodoHandler.Create)
+	assertAPIError(t, err, http.StatusConflict)
+}
+
+// TestTodoUpdate_WIPLimitWarn tests that warn mode allows the change and returns a warning
+func TestTodoUpdate_WIPLimitWarn(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("wipwarn@example.com")
+	_, err := f.CallAuth(token, http.MethodPatch, preferencesPath, `{"wip_limit":1,"wip_limit_mode":"warn"}`, f.PreferenceHandler.Update)
+	require.NoError(t, err)
+
+	f.CreateTodoWithDetails(user.ID, "Doing", testutil.TodoOptions{Status: model.StatusInProgress})
+	todo := f.CreateTodo(user.ID, "Next")
+
+	body := `{"status":"in_progress"}`
+	rec, err := f.CallAuth(token, http.MethodPatch, testutil.TodoPath(todo.ID), body, f.TodoHandler.Update)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, "in_progress", response["status"])
+	warnings, ok := response["warnings"].([]any)
+	require.True(t, ok)
+	require.Len(t, warnings, 1)
+	warning := warnings[0].(map[string]any)
+	assert.Equal(t, "wip_limit_exceeded", warning["code"])
+	assert.Equal(t, float64(1), warning["limit"])
+	assert.Equal(t, float64(2), warning["in_progress"])
+}
+
+// TestTodoStats tests the counts per status and the WIP against the limit
+func TestTodoStats(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("todostats@example.com")
+	f.CreateTodoWithDetails(user.ID, "Pending", testutil.TodoOptions{Status: model.StatusPending})
+	f.CreateTodoWithDetails(user.ID, "Doing 1", testutil.TodoOptions{Status: model.StatusInProgress})
+	f.CreateTodoWithDetails(user.ID, "Doing 2", testutil.TodoOptions{Status: model.StatusInProgress})
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/stats", "", f.TodoHandler.Stats)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(3), response["total"])
+	byStatus := response["by_status"].(map[string]any)
+	assert.Equal(t, float64(1), byStatus["pending"])
+	assert.Equal(t, float64(2), byStatus["in_progress"])
+	assert.Equal(t, float64(0), byStatus["completed"])
+	wip := response["wip"].(map[string]any)
+	assert.Nil(t, wip["limit"])
+	assert.Equal(t, false, wip["exceeded"])
+
+	_, err = f.CallAuth(token, http.MethodPatch, preferencesPath, `{"wip_limit":1,"wip_limit_mode":"warn"}`, f.PreferenceHandler.Update)
+	require.NoError(t, err)
+
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos/stats", "", f.TodoHandler.Stats)
+	require.NoError(t, err)
+
+	wip = testutil.JSONResponse(t, rec)["wip"].(map[string]any)
+	assert.Equal(t, float64(2), wip["in_progress"])
+	assert.Equal(t, float64(1), wip["limit"])
+	assert.Equal(t, "warn", wip["mode"])
+	assert.Equal(t, true, wip["exceeded"])
+}
+
+// TestTodoSnooze_Presets tests snoozing with presets relative to today
+func TestTodoSnooze_Presets(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("snoozepreset@example.com")
+	todo := f.CreateTodo(user.ID, "Later")
+	path := testutil.TodoPath(todo.ID) + "/snooze"
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	rec, err := f.CallAuth(token, http.MethodPost, path, `{"preset":"tomorrow"}`, f.TodoHandler.Snooze)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, today.AddDate(0, 0, 1).Format("2006-01-02"), response["due_date"])
+	assert.Equal(t, float64(1), response["snooze_count"])
+
+	rec, err = f.CallAuth(token, http.MethodPost, path, `{"preset":"next_week"}`, f.TodoHandler.Snooze)
+	require.NoError(t, err)
+
+	response = testutil.JSONResponse(t, rec)
+	dueDate, err := time.Parse("2006-01-02", response["due_date"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, time.Monday, dueDate.Weekday())
+	assert.True(t, dueDate.After(today))
+	assert.Equal(t, float64(2), response["snooze_count"])
+
+	// Each snooze is recorded in history
+	histories, _, err := f.HistoryRepo.FindByTodoID(context.Background(), todo.ID, user.ID, 1, 10)
+	require.NoError(t, err)
+	snoozes := 0
+	for _, h := range histories {
+		if h.Action == model.ActionSnoozed {
+			snoozes++
+		}
+	}
+	assert.Equal(t, 2, snoozes)
+}
+
+// TestTodoSnooze_Duration tests that a duration is added to a future due date
+func TestTodoSnooze_Duration(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("snoozeduration@example.com")
+	due := time.Now().UTC().AddDate(0, 0, 5)
+	due = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+	todo := f.CreateTodoWithDetails(user.ID, "Report", testutil.TodoOptions{DueDate: &due})
+
+	rec, err := f.CallAuth(token, http.MethodPost, testutil.TodoPath(todo.ID)+"/snooze", `{"duration":"1w"}`, f.TodoHandler.Snooze)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, due.AddDate(0, 0, 7).Format("2006-01-02"), response["due_date"])
+}
+
+// TestTodoSnooze_ValidationError tests invalid snooze requests
+func TestTodoSnooze_ValidationError(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("snoozeinvalid@example.com")
+	due := time.Now().UTC().AddDate(0, 0, 30)
+	todo := f.CreateTodoWithDetails(user.ID, "Far away", testutil.TodoOptions{DueDate: &due})
+	done := f.CreateTodoWithDetails(user.ID, "Done", testutil.TodoOptions{Status: model.StatusCompleted})
+	require.NoError(t, f.DB.Model(done).Update("completed", true).Error)
+
+	tests := []struct {
+		name string
+		id   int64
+		body string
+	}{
+		{name: "neither preset nor duration", id: todo.ID, body: `{}`},
+		{name: "both preset and duration", id: todo.ID, body: `{"preset":"tomorrow","duration":"1d"}`},
+		{name: "unknown preset", id: todo.ID, body: `{"preset":"someday"}`},
+		{name: "invalid duration", id: todo.ID, body: `{"duration":"3 days"}`},
+		{name: "preset before the due date", id: todo.ID, body: `{"preset":"tomorrow"}`},
+		{name: "completed todo", id: done.ID, body: `{"preset":"tomorrow"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.CallAuth(token, http.MethodPost, testutil.TodoPath(tt.id)+"/snooze", tt.body, f.TodoHandler.Snooze)
+			require.Error(t, err)
+		})
+	}
+}
+
 // TestTodoCreate_WithDueDate tests todo creation with due date
 func TestTodoCreate_WithDueDate(t *testing.T) {
 	f := testutil.SetupTestFixture(t)
@@ -397,6 +795,123 @@ func TestTodoSearch_TextQuery(t *testing.T) {
 	assert.Len(t, data2, 1)
 }
 
+// TestTodoSearch_Highlights tests that match positions and snippets are returned for the query
+func TestTodoSearch_Highlights(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("searchhighlight@example.com")
+	desc := "Agenda for the <team> meeting"
+	f.CreateTodoWithDetails(user.ID, "Weekly Meeting", testutil.TodoOptions{Description: &desc})
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?q=meeting", "", f.TodoHandler.Search)
+	require.NoError(t, err)
+
+	data := testutil.JSONResponse(t, rec)["data"].([]any)
+	require.Len(t, data, 1)
+	todo := data[0].(map[string]any)
+
+	highlights := todo["highlights"].(map[string]any)
+	title := highlights["title"].([]any)
+	require.Len(t, title, 1)
+	match := title[0].(map[string]any)
+	assert.Equal(t, float64(7), match["start"])
+	assert.Equal(t, float64(14), match["end"])
+	assert.Equal(t, "Meeting", match["matched_text"])
+	assert.Len(t, highlights["description"], 1)
+
+	snippets := todo["snippets"].(map[string]any)
+	assert.Equal(t, "Weekly <mark>Meeting</mark>", snippets["title"])
+	assert.Equal(t, "Agenda for the &lt;team&gt; <mark>meeting</mark>", snippets["description"])
+}
+
+// TestTodoSearch_HighlightsMultibyte tests that offsets are UTF-16 code units
+func TestTodoSearch_HighlightsMultibyte(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("searchhighlightja@example.com")
+	f.CreateTodo(user.ID, "🎉 週次の会議")
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?q=会議", "", f.TodoHandler.Search)
+	require.NoError(t, err)
+
+	data := testutil.JSONResponse(t, rec)["data"].([]any)
+	require.Len(t, data, 1)
+
+	match := data[0].(map[string]any)["highlights"].(map[string]any)["title"].([]any)[0].(map[string]any)
+	// The emoji is a surrogate pair, so "会議" starts at 2 + 1 + 3
+	assert.Equal(t, float64(6), match["start"])
+	assert.Equal(t, float64(8), match["end"])
+}
+
+// TestTodoSearch_NoHighlightsWithoutQuery tests that highlights are omitted when there is no text query
+func TestTodoSearch_NoHighlightsWithoutQuery(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("searchnohighlight@example.com")
+	f.CreateTodo(user.ID, "Meeting notes")
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search", "", f.TodoHandler.Search)
+	require.NoError(t, err)
+
+	data := testutil.JSONResponse(t, rec)["data"].([]any)
+	require.Len(t, data, 1)
+	assert.NotContains(t, data[0].(map[string]any), "highlights")
+}
+
+// TestTodoSearch_Fuzzy tests that fuzzy search finds todos despite typos
+func TestTodoSearch_Fuzzy(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	if !database.DialectOf(f.DB).SupportsTrigram() {
+		t.Skip("Fuzzy search requires pg_trgm")
+	}
+
+	user, token := f.CreateUser("fuzzysearch@example.com")
+	f.CreateTodo(user.ID, "Receive package")
+	f.CreateTodo(user.ID, "Shopping list")
+
+	// Exact matching finds nothing
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?q=recieve%20pakage", "", f.TodoHandler.Search)
+	require.NoError(t, err)
+	assert.Len(t, testutil.JSONResponse(t, rec)["data"], 0)
+
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?q=recieve%20pakage&fuzzy=true", "", f.TodoHandler.Search)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	data := response["data"].([]any)
+	require.Len(t, data, 1)
+	assert.Equal(t, "Receive package", data[0].(map[string]any)["title"])
+	filters := response["meta"].(map[string]any)["filters_applied"].(map[string]any)
+	assert.Equal(t, true, filters["fuzzy"])
+
+	// A strict threshold rejects the misspelling
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?q=recieve%20pakage&fuzzy=true&similarity=0.9", "", f.TodoHandler.Search)
+	require.NoError(t, err)
+	assert.Len(t, testutil.JSONResponse(t, rec)["data"], 0)
+}
+
+// TestTodoSearch_FuzzyInvalidSimilarity tests validation of the similarity threshold
+func TestTodoSearch_FuzzyInvalidSimilarity(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("fuzzyinvalid@example.com")
+
+	for _, similarity := range []string{"0", "1.5", "abc"} {
+		_, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?q=test&fuzzy=true&similarity="+similarity, "", f.TodoHandler.Search)
+		require.Error(t, err, similarity)
+	}
+}
+
+// TestTodoSearch_FuzzyInvalid tests that a non-boolean fuzzy parameter is rejected instead of ignored
+func TestTodoSearch_FuzzyInvalid(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("fuzzyflag@example.com")
+
+	_, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?q=test&fuzzy=yes", "", f.TodoHandler.Search)
+	assertAPIError(t, err, http.StatusUnprocessableEntity)
+}
+
 // TestTodoSearch_StatusFilter tests status filter with multiple values
 func TestTodoSearch_StatusFilter(t *testing.T) {
 	f := testutil.SetupTestFixture(t)
@@ -556,6 +1071,60 @@ func TestTodoSearch_DateRangeFilter(t *testing.T) {
 	assert.Len(t, data, 2)
 }
 
+// TestTodoSearch_CreatedUpdatedRangeFilter tests filtering by creation and update dates in the user's time zone
+func TestTodoSearch_CreatedUpdatedRangeFilter(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("createdrange@example.com")
+	pref, err := f.PreferenceRepo.FindOrDefault(context.Background(), user.ID)
+	require.NoError(t, err)
+	pref.Timezone = "Asia/Tokyo"
+	require.NoError(t, f.PreferenceRepo.Save(context.Background(), pref))
+
+	setTimes := func(todo *model.Todo, createdAt, updatedAt string) {
+		created, err := time.Parse(time.RFC3339, createdAt)
+		require.NoError(t, err)
+		updated, err := time.Parse(time.RFC3339, updatedAt)
+		require.NoError(t, err)
+		require.NoError(t, f.DB.Model(&model.Todo{}).Where("id = ?", todo.ID).
+			UpdateColumns(map[string]any{"created_at": created, "updated_at": updated}).Error)
+	}
+	// January 31 23:30 in Tokyo, updated in March
+	setTimes(f.CreateTodo(user.ID, "Added in January"), "2024-01-31T14:30:00Z", "2024-03-10T00:00:00Z")
+	// February 1 00:30 in Tokyo (still January 31 in UTC)
+	setTimes(f.CreateTodo(user.ID, "Added on February 1"), "2024-01-31T15:30:00Z", "2024-02-01T00:00:00Z")
+	// February 29 23:00 in Tokyo
+	setTimes(f.CreateTodo(user.ID, "Added on February 29"), "2024-02-29T14:00:00Z", "2024-02-29T14:00:00Z")
+	setTimes(f.CreateTodo(user.ID, "Added in March"), "2024-03-05T00:00:00Z", "2024-03-05T00:00:00Z")
+
+	searchTitles := func(query string) []string {
+		rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?sort_by=created_at&sort_order=asc&"+query, "", f.TodoHandler.Search)
+		require.NoError(t, err)
+		data := testutil.JSONResponse(t, rec)["data"].([]any)
+		titles := make([]string, len(data))
+		for i, item := range data {
+			titles[i] = item.(map[string]any)["title"].(string)
+		}
+		return titles
+	}
+
+	assert.Equal(t, []string{"Added on February 1", "Added on February 29"}, searchTitles("created_from=2024-02-01&created_to=2024-02-29"))
+	assert.Equal(t, []string{"Added in January"}, searchTitles("created_to=2024-01-31"))
+	assert.Equal(t, []string{"Added in January", "Added in March"}, searchTitles("updated_from=2024-03-01"))
+	assert.Equal(t, []string{"Added on February 29"}, searchTitles("created_from=2024-02-01&updated_to=2024-02-29&updated_from=2024-02-29"))
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?created_from=2024-02-01", "", f.TodoHandler.Search)
+	require.NoError(t, err)
+	filters := testutil.JSONResponse(t, rec)["meta"].(map[string]any)["filters_applied"].(map[string]any)
+	assert.Equal(t, "2024-02-01", filters["created_from"])
+
+	_, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?created_from=yesterday", "", f.TodoHandler.Search)
+	assertAPIError(t, err, http.StatusUnprocessableEntity)
+
+	_, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?updated_from=2024-03-01&updated_to=2024-02-01", "", f.TodoHandler.Search)
+	assertAPIError(t, err, http.StatusUnprocessableEntity)
+}
+
 // TestTodoSearch_Pagination tests pagination
 func TestTodoSearch_Pagination(t *testing.T) {
 	f := testutil.SetupTestFixture(t)
@@ -590,6 +1159,40 @@ func TestTodoSearch_Pagination(t *testing.T) {
 	assert.Len(t, data2, 5)
 }
 
+// TestTodoSearch_GroupBy tests that search groups count all matches, not just the current page
+func TestTodoSearch_GroupBy(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("searchgroup@example.com")
+	for i := 1; i <= 3; i++ {
+		f.CreateTodoWithDetails(user.ID, fmt.Sprintf("Urgent %d", i), testutil.TodoOptions{Priority: model.PriorityHigh})
+	}
+	// The column default turns a zero priority into medium on create, so low is set afterwards
+	for _, title := range []string{"Urgent low", "Other"} {
+		todo := f.CreateTodo(user.ID, title)
+		require.NoError(t, f.DB.Model(todo).Update("priority", model.PriorityLow).Error)
+	}
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?q=urgent&group_by=priority&per_page=2", "", f.TodoHandler.Search)
+	require.NoError(t, err)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Len(t, response["data"], 2)
+	assert.Equal(t, "priority", response["group_by"])
+
+	groups := response["groups"].([]any)
+	require.Len(t, groups, 3)
+	counts := map[string]float64{}
+	pageTodos := 0
+	for _, g := range groups {
+		group := g.(map[string]any)
+		counts[group["key"].(string)] = group["count"].(float64)
+		pageTodos += len(group["todos"].([]any))
+	}
+	assert.Equal(t, map[string]float64{"high": 3, "medium": 0, "low": 1}, counts)
+	assert.Equal(t, 2, pageTodos)
+}
+
 // TestTodoSearch_Sorting tests sort functionality
 func TestTodoSearch_Sorting(t *testing.T) {
 	f := testutil.SetupTestFixture(t)
@@ -634,6 +1237,41 @@ func TestTodoSearch_DueDateSortNullLast(t *testing.T) {
 	assert.Equal(t, "With due date", data[0].(map[string]any)["title"])
 }
 
+// TestTodoSearch_NullsPlacement tests placing todos without a due date first or last in either direction
+func TestTodoSearch_NullsPlacement(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("nullsplacement@example.com")
+
+	f.CreateTodoWithDetails(user.ID, "Early", testutil.TodoOptions{DueDate: testutil.ParseDate("2030-01-15")})
+	f.CreateTodo(user.ID, "Undated")
+	f.CreateTodoWithDetails(user.ID, "Late", testutil.TodoOptions{DueDate: testutil.ParseDate("2030-02-15")})
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"sort_by=due_date&sort_order=desc", []string{"Late", "Early", "Undated"}},
+		{"sort_by=due_date&sort_order=asc&nulls=last", []string{"Early", "Late", "Undated"}},
+		{"sort_by=due_date&sort_order=asc&nulls=first", []string{"Undated", "Early", "Late"}},
+		{"sort_by=due_date&sort_order=desc&nulls=first", []string{"Undated", "Late", "Early"}},
+	}
+	for _, tt := range tests {
+		rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?"+tt.query, "", f.TodoHandler.Search)
+		require.NoError(t, err)
+
+		data := testutil.JSONResponse(t, rec)["data"].([]any)
+		titles := make([]string, len(data))
+		for i, item := range data {
+			titles[i] = item.(map[string]any)["title"].(string)
+		}
+		assert.Equal(t, tt.expected, titles, tt.query)
+	}
+
+	_, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/search?sort_by=due_date&nulls=middle", "", f.TodoHandler.Search)
+	require.Error(t, err)
+}
+
 // TestTodoSearch_UserScope tests that users only see their own todos in search
 func TestTodoSearch_UserScope(t *testing.T) {
 	f := testutil.SetupTestFixture(t)
@@ -707,3 +1345,93 @@ func TestTodoSearch_CombinedFilters(t *testing.T) {
 	assert.Len(t, data, 1)
 	assert.Equal(t, "Work urgent pending", data[0].(map[string]any)["title"])
 }
+
+// facetCounts maps the keys of a facet to their counts
+func facetCounts(facet any) map[any]float64 {
+	counts := map[any]float64{}
+	for _, v := range facet.([]any) {
+		value := v.(map[string]any)
+		counts[value["key"]] = value["count"].(float64)
+	}
+	return counts
+}
+
+// TestTodoFacets tests that each facet counts todos with the other filters applied
+func TestTodoFacets(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, token := f.CreateUser("facets@example.com")
+	category := f.CreateCategory(user.ID, "Work", "#FF0000")
+	tag := f.CreateTag(user.ID, "urgent", nil)
+
+	f.CreateTodoWithDetails(user.ID, "Work pending", testutil.TodoOptions{
+		CategoryID: &category.ID, Priority: model.PriorityHigh, TagIDs: []int64{tag.ID},
+	})
+	f.CreateTodoWithDetails(user.ID, "Work done", testutil.TodoOptions{
+		CategoryID: &category.ID, Priority: model.PriorityHigh, Status: model.StatusCompleted,
+	})
+	f.CreateTodoWithDetails(user.ID, "Home pending", testutil.TodoOptions{Priority: model.PriorityMedium, DueDate: dueIn(0)})
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/facets?status=pending", "", f.TodoHandler.Facets)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	response := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(2), response["total"])
+
+	// The status facet ignores the status filter
+	status := facetCounts(response["status"])
+	assert.Equal(t, float64(2), status["pending"])
+	assert.Equal(t, float64(1), status["completed"])
+	assert.Equal(t, float64(0), status["in_progress"])
+
+	priority := facetCounts(response["priority"])
+	assert.Equal(t, float64(1), priority["high"])
+	assert.Equal(t, float64(1), priority["medium"])
+
+	categories := facetCounts(response["category"])
+	assert.Equal(t, float64(1), categories[float64(category.ID)])
+	assert.Equal(t, float64(1), categories[nil])
+
+	tags := facetCounts(response["tag"])
+	assert.Equal(t, float64(1), tags[float64(tag.ID)])
+
+	dueBuckets := facetCounts(response["due_bucket"])
+	assert.Equal(t, float64(1), dueBuckets["today"])
+	assert.Equal(t, float64(1), dueBuckets["no_due_date"])
+	assert.Equal(t, float64(0), dueBuckets["overdue"])
+}
+
+// TestTodoFacets_ValidationError tests invalid filters
+func TestTodoFacets_ValidationError(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	_, token := f.CreateUser("facetsinvalid@example.com")
+
+	_, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos/facets?tag_mode=some", "", f.TodoHandler.Facets)
+	require.Error(t, err)
+}
+
+// TestTodoBackfillCompletedAt tests that todos with the completed status get completed and a completion time
+func TestTodoBackfillCompletedAt(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+
+	user, _ := f.CreateUser("backfillcompleted@example.com")
+	done := f.CreateTodoWithDetails(user.ID, "Done", testutil.TodoOptions{Status: model.StatusCompleted})
+	open := f.CreateTodo(user.ID, "Open")
+	require.False(t, done.Completed)
+	require.Nil(t, done.CompletedAt)
+
+	require.NoError(t, f.TodoRepo.BackfillCompletedAt(context.Background()))
+
+	backfilled, err := f.TodoRepo.FindByID(context.Background(), done.ID, user.ID)
+	require.NoError(t, err)
+	assert.True(t, backfilled.Completed)
+	require.NotNil(t, backfilled.CompletedAt)
+	assert.WithinDuration(t, backfilled.UpdatedAt, *backfilled.CompletedAt, time.Second)
+
+	untouched, err := f.TodoRepo.FindByID(context.Background(), open.ID, user.ID)
+	require.NoError(t, err)
+	assert.False(t, untouched.Completed)
+	assert.Nil(t, untouched.CompletedAt)
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/pkg/response"
+	"todo-api/pkg/util"
+)
+
+// UsageHandler handles API usage metering endpoints
+type UsageHandler struct {
+	usageService *service.UsageService
+}
+
+// NewUsageHandler creates a new UsageHandler
+func NewUsageHandler(usageService *service.UsageService) *UsageHandler {
+	return &UsageHandler{usageService: usageService}
+}
+
+// UsageResponse represents the current user's API usage in API responses
+type UsageResponse struct {
+	DailyQuota *int                 `json:"daily_quota"`
+	UsedToday  int64                `json:"used_today"`
+	Remaining  *int64               `json:"remaining"`
+	ResetsAt   string               `json:"resets_at"`
+	Days       []DailyUsageResponse `json:"days"`
+}
+
+// DailyUsageResponse represents the requests of one UTC day
+type DailyUsageResponse struct {
+	Date          string                     `json:"date"`
+	Requests      int64                      `json:"requests"`
+	ByIntegration []IntegrationUsageResponse `json:"by_integration"`
+}
+
+// IntegrationUsageResponse represents the requests made through one integration, or with a user token
+// when IntegrationID is nil
+type IntegrationUsageResponse struct {
+	IntegrationID *int64 `json:"integration_id"`
+	Requests      int64  `json:"requests"`
+}
+
+// UsageRollupResponse represents the API usage of all users in admin API responses
+type UsageRollupResponse struct {
+	From            string                                `json:"from"`
+	To              string                                `json:"to"`
+	TotalRequests   int64                                 `json:"total_requests"`
+	Days            []repository.DailyAPIUsageTotal       `json:"days"`
+	TopUsers        []repository.UserAPIUsageTotal        `json:"top_users"`
+	TopIntegrations []repository.IntegrationAPIUsageTotal `json:"top_integrations"`
+}
+
+// UpdateQuotaRequest represents the request body for overriding a user's daily quota
+type UpdateQuotaRequest struct {
+	DailyQuota *int `json:"daily_quota"`
+}
+
+// QuotaResponse represents a user's daily quota in admin API responses
+type QuotaResponse struct {
+	UserID              int64  `json:"user_id"`
+	Email               string `json:"email"`
+	DailyQuota          *int   `json:"daily_quota"`
+	EffectiveDailyQuota *int   `json:"effective_daily_quota"`
+}
+
+// Show retrieves the current user's daily quota and request counts
+// GET /api/v1/users/me/usage/api
+func (h *UsageHandler) Show(c echo.Context) error {
+	currentUser, err := GetCurrentUserOrFail(c)
+	if err != nil {
+		return err
+	}
+
+	days := service.DefaultUsageDays
+	if daysStr := c.QueryParam("days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil {
+			return errors.ValidationFailed(map[string][]string{
+				"days": {"Must be between 1 and 366"},
+			})
+		}
+	}
+
+	usage, err := h.usageService.Usage(c.Request().Context(), currentUser.ID, currentUser.APIDailyQuota, days)
+	if err != nil {
+		return err
+	}
+
+	resp := UsageResponse{
+		UsedToday: usage.Quota.Used,
+		ResetsAt:  util.FormatRFC3339(usage.Quota.ResetAt),
+		Days:      make([]DailyUsageResponse, len(usage.Days)),
+	}
+	if usage.Quota.Limited() {
+		resp.DailyQuota = util.Ptr(usage.Quota.Limit)
+		resp.Remaining = util.Ptr(usage.Quota.Remaining())
+	}
+	for i, day := range usage.Days {
+		resp.Days[i] = DailyUsageResponse{
+			Date:          day.Date,
+			Requests:      day.Requests,
+			ByIntegration: make([]IntegrationUsageResponse, len(day.ByIntegration)),
+		}
+		for j, counted := range day.ByIntegration {
+			resp.Days[i].ByIntegration[j] = IntegrationUsageResponse{IntegrationID: counted.IntegrationID, Requests: counted.RequestCount}
+		}
+	}
+	return response.OK(c, resp)
+}
+
+// Rollup aggregates the API usage of all users per day, per user and per integration
+// GET /api/v1/admin/usage/api
+func (h *UsageHandler) Rollup(c echo.Context) error {
+	rollup, err := h.usageService.Rollup(c.Request().Context(), c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		return err
+	}
+
+	resp := UsageRollupResponse{
+		From:            rollup.From,
+		To:              rollup.To,
+		TotalRequests:   rollup.TotalRequests,
+		Days:            rollup.Days,
+		TopUsers:        rollup.TopUsers,
+		TopIntegrations: rollup.TopIntegrations,
+	}
+	if resp.Days == nil {
+		resp.Days = []repository.DailyAPIUsageTotal{}
+	}
+	if resp.TopUsers == nil {
+		resp.TopUsers = []repository.UserAPIUsageTotal{}
+	}
+	if resp.TopIntegrations == nil {
+		resp.TopIntegrations = []repository.IntegrationAPIUsageTotal{}
+	}
+	return response.OK(c, resp)
+}
+
+// UpdateQuota overrides the daily quota of a user; null restores the default
+// PATCH /api/v1/admin/users/:id/api_quota
+func (h *UsageHandler) UpdateQuota(c echo.Context) error {
+	userID, err := ParseIDParam(c, "id")
+	if err != nil {
+		return err
+	}
+
+	var req UpdateQuotaRequest
+	if err := BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	user, err := h.usageService.SetDailyQuota(c.Request().Context(), userID, req.DailyQuota)
+	if err != nil {
+		return err
+	}
+
+	resp := QuotaResponse{
+		UserID:     user.ID,
+		Email:      user.Email,
+		DailyQuota: user.APIDailyQuota,
+	}
+	if quota := h.usageService.DailyQuota(user.APIDailyQuota); quota > 0 {
+		resp.EffectiveDailyQuota = &quota
+	}
+	return response.OK(c, resp)
+}
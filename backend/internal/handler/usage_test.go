@@ -0,0 +1,204 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"todo-api/internal/config"
+	"todo-api/internal/errors"
+	"todo-api/internal/handler"
+	"todo-api/internal/middleware"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/service"
+	"todo-api/internal/testutil"
+)
+
+// usageService returns a UsageService with the given default daily quota
+func usageService(f *testutil.TestFixture, dailyQuota int) *service.UsageService {
+	return service.NewUsageService(repository.NewAPIUsageRepository(f.DB), f.UserRepo, &config.UsageConfig{DailyQuota: dailyQuota})
+}
+
+// TestMeterAPIUsage_Quota tests counting requests, the rate limit headers, and rejecting requests over the quota
+func TestMeterAPIUsage_Quota(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	_, token := f.CreateUser("metered@example.com")
+
+	svc := usageService(f, 2)
+	metered := middleware.MeterAPIUsage(svc)(f.TodoHandler.List)
+
+	for i := 1; i <= 2; i++ {
+		rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", metered)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get(middleware.RateLimitLimitHeader))
+		assert.Equal(t, strconv.Itoa(2-i), rec.Header().Get(middleware.RateLimitRemainingHeader))
+		assert.NotEmpty(t, rec.Header().Get(middleware.RateLimitResetHeader))
+	}
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", metered)
+	require.Error(t, err)
+	apiErr, ok := err.(*errors.ApiError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, "RATE_LIMIT_EXCEEDED", apiErr.Code)
+	assert.Equal(t, "0", rec.Header().Get(middleware.RateLimitRemainingHeader))
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/users/me/usage/api", "", handler.NewUsageHandler(svc).Show)
+	require.NoError(t, err)
+	resp := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(2), resp["daily_quota"])
+	assert.Equal(t, float64(3), resp["used_today"])
+	assert.Equal(t, float64(0), resp["remaining"])
+	days := resp["days"].([]any)
+	require.Len(t, days, 1)
+	assert.Equal(t, float64(3), days[0].(map[string]any)["requests"])
+}
+
+// TestMeterAPIUsage_Unlimited tests that requests are counted without rate limit headers when no quota applies
+func TestMeterAPIUsage_Unlimited(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	_, token := f.CreateUser("unlimited@example.com")
+
+	svc := usageService(f, 0)
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", middleware.MeterAPIUsage(svc)(f.TodoHandler.List))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(middleware.RateLimitLimitHeader))
+
+	rec, err = f.CallAuth(token, http.MethodGet, "/api/v1/users/me/usage/api", "", handler.NewUsageHandler(svc).Show)
+	require.NoError(t, err)
+	resp := testutil.JSONResponse(t, rec)
+	assert.Nil(t, resp["daily_quota"])
+	assert.Nil(t, resp["remaining"])
+	assert.Equal(t, float64(1), resp["used_today"])
+}
+
+// TestUsageAdmin tests the admin usage rollup and per-user quota overrides
+func TestUsageAdmin(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	cfg := *testutil.TestConfig
+	cfg.AdminEmails = "admin@example.com"
+
+	admin, adminToken := f.CreateUser("admin@example.com")
+	user, userToken := f.CreateUser("heavy@example.com")
+
+	svc := usageService(f, 0)
+	usageHandler := handler.NewUsageHandler(svc)
+	metered := middleware.MeterAPIUsage(svc)(f.TodoHandler.List)
+	adminOnly := middleware.RequireAdmin(&cfg)
+
+	// Non-admins are rejected
+	_, err := f.CallAuth(userToken, http.MethodGet, "/api/v1/admin/usage/api", "", adminOnly(usageHandler.Rollup))
+	require.Error(t, err)
+	apiErr, ok := err.(*errors.ApiError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+
+	// Override the quota of one user
+	rec, err := f.CallAuthWithParams(adminToken, http.MethodPatch, "/api/v1/admin/users/"+strconv.FormatInt(user.ID, 10)+"/api_quota",
+		`{"daily_quota":5}`, map[string]string{"id": strconv.FormatInt(user.ID, 10)}, adminOnly(usageHandler.UpdateQuota))
+	require.NoError(t, err)
+	resp := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(5), resp["daily_quota"])
+	assert.Equal(t, float64(5), resp["effective_daily_quota"])
+
+	for i := 0; i < 3; i++ {
+		rec, err = f.CallAuth(userToken, http.MethodGet, "/api/v1/todos", "", metered)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "5", rec.Header().Get(middleware.RateLimitLimitHeader))
+	assert.Equal(t, "2", rec.Header().Get(middleware.RateLimitRemainingHeader))
+
+	rec, err = f.CallAuth(adminToken, http.MethodGet, "/api/v1/todos", "", metered)
+	require.NoError(t, err)
+	assert.Empty(t, rec.Header().Get(middleware.RateLimitLimitHeader))
+
+	// Rollup per day and per user
+	rec, err = f.CallAuth(adminToken, http.MethodGet, "/api/v1/admin/usage/api", "", adminOnly(usageHandler.Rollup))
+	require.NoError(t, err)
+	resp = testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(4), resp["total_requests"])
+	days := resp["days"].([]any)
+	require.Len(t, days, 1)
+	assert.Equal(t, float64(2), days[0].(map[string]any)["users"])
+	topUsers := resp["top_users"].([]any)
+	require.Len(t, topUsers, 2)
+	assert.Equal(t, float64(user.ID), topUsers[0].(map[string]any)["user_id"])
+	assert.Equal(t, "heavy@example.com", topUsers[0].(map[string]any)["email"])
+	assert.Equal(t, float64(3), topUsers[0].(map[string]any)["requests"])
+	assert.Equal(t, float64(admin.ID), topUsers[1].(map[string]any)["user_id"])
+
+	_, err = f.CallAuth(adminToken, http.MethodGet, "/api/v1/admin/usage/api?from=2024-02-01&to=2024-01-01", "", adminOnly(usageHandler.Rollup))
+	require.Error(t, err)
+}
+
+// TestMeterAPIUsage_Integrations tests that requests signed by an integration are counted for it
+// and against the user's quota
+func TestMeterAPIUsage_Integrations(t *testing.T) {
+	f := testutil.SetupTestFixture(t)
+	cfg := *testutil.TestConfig
+	cfg.AdminEmails = "usageadmin@example.com"
+
+	_, adminToken := f.CreateUser("usageadmin@example.com")
+	user, token := f.CreateUser("integrated@example.com")
+	integration := &model.Integration{UserID: user.ID, Name: "CI", KeyID: "key-usage", Secret: "secret"}
+	require.NoError(t, repository.NewIntegrationRepository(f.DB).Create(context.Background(), integration))
+
+	svc := usageService(f, 3)
+	usageHandler := handler.NewUsageHandler(svc)
+	metered := middleware.MeterAPIUsage(svc)(f.TodoHandler.List)
+	// Stands in for SignatureAuth, which sets the integration that signed the request
+	signed := func(c echo.Context) error {
+		c.Set(middleware.IntegrationKey, integration)
+		return metered(c)
+	}
+
+	_, err := f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", metered)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", signed)
+		require.NoError(t, err)
+	}
+
+	// The quota covers both
+	_, err = f.CallAuth(token, http.MethodGet, "/api/v1/todos", "", signed)
+	require.Error(t, err)
+
+	rec, err := f.CallAuth(token, http.MethodGet, "/api/v1/users/me/usage/api", "", usageHandler.Show)
+	require.NoError(t, err)
+	resp := testutil.JSONResponse(t, rec)
+	assert.Equal(t, float64(4), resp["used_today"])
+	days := resp["days"].([]any)
+	require.Len(t, days, 1)
+	day := days[0].(map[string]any)
+	assert.Equal(t, float64(4), day["requests"])
+	byIntegration := day["by_integration"].([]any)
+	require.Len(t, byIntegration, 2)
+	assert.Nil(t, byIntegration[0].(map[string]any)["integration_id"])
+	assert.Equal(t, float64(1), byIntegration[0].(map[string]any)["requests"])
+	assert.Equal(t, float64(integration.ID), byIntegration[1].(map[string]any)["integration_id"])
+	assert.Equal(t, float64(3), byIntegration[1].(map[string]any)["requests"])
+
+	rec, err = f.CallAuth(adminToken, http.MethodGet, "/api/v1/admin/usage/api", "", middleware.RequireAdmin(&cfg)(usageHandler.Rollup))
+	require.NoError(t, err)
+	resp = testutil.JSONResponse(t, rec)
+	adminDays := resp["days"].([]any)
+	require.Len(t, adminDays, 1)
+	assert.Equal(t, float64(4), adminDays[0].(map[string]any)["requests"])
+	assert.Equal(t, float64(3), adminDays[0].(map[string]any)["integration_requests"])
+	assert.Equal(t, float64(1), adminDays[0].(map[string]any)["users"])
+	topIntegrations := resp["top_integrations"].([]any)
+	require.Len(t, topIntegrations, 1)
+	assert.Equal(t, float64(integration.ID), topIntegrations[0].(map[string]any)["integration_id"])
+	assert.Equal(t, "CI", topIntegrations[0].(map[string]any)["name"])
+	assert.Equal(t, float64(user.ID), topIntegrations[0].(map[string]any)["user_id"])
+	assert.Equal(t, float64(3), topIntegrations[0].(map[string]any)["requests"])
+}
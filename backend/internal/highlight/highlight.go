@@ -0,0 +1,136 @@
+// Package highlight finds where a search query occurs in text
+package highlight
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Ellipsis marks text cut from either side of a window
+const Ellipsis = "…"
+
+// contextRunes is how much text is kept before the first match when text is cut
+const contextRunes = 30
+
+// Match is an occurrence of the query.
+// Start and End are UTF-16 code unit offsets so clients can pass them to String.prototype.substring.
+type Match struct {
+	Start       int
+	End         int
+	MatchedText string
+}
+
+// span is a match as rune offsets
+type span struct {
+	start, end int
+}
+
+// Find returns the non-overlapping, case-insensitive occurrences of query in text
+func Find(text, query string) []Match {
+	runes := []rune(text)
+	spans := findSpans(runes, query)
+	if len(spans) == 0 {
+		return nil
+	}
+
+	matches := make([]Match, len(spans))
+	for i, s := range spans {
+		matches[i] = Match{
+			Start:       utf16Len(runes[:s.start]),
+			End:         utf16Len(runes[:s.end]),
+			MatchedText: string(runes[s.start:s.end]),
+		}
+	}
+	return matches
+}
+
+// Window returns text cut to maxRunes, starting shortly before the first occurrence of query.
+// Cut ends are marked with Ellipsis. Text without the query is cut from the beginning.
+func Window(text, query string, maxRunes int) string {
+	runes := []rune(text)
+	if maxRunes <= 0 || len(runes) <= maxRunes {
+		return text
+	}
+
+	start := 0
+	if spans := findSpans(runes, query); len(spans) > 0 {
+		start = max(spans[0].start-contextRunes, 0)
+	}
+	end := min(start+maxRunes, len(runes))
+	start = max(end-maxRunes, 0)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(Ellipsis)
+	}
+	b.WriteString(string(runes[start:end]))
+	if end < len(runes) {
+		b.WriteString(Ellipsis)
+	}
+	return b.String()
+}
+
+// Snippet returns the Window of text HTML-escaped, with every occurrence of query wrapped in <mark>.
+// Returns "" if the query does not occur in the window.
+func Snippet(text, query string, maxRunes int) string {
+	runes := []rune(Window(text, query, maxRunes))
+	spans := findSpans(runes, query)
+	if len(spans) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	pos := 0
+	for _, s := range spans {
+		b.WriteString(html.EscapeString(string(runes[pos:s.start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[s.start:s.end])))
+		b.WriteString("</mark>")
+		pos = s.end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:])))
+	return b.String()
+}
+
+// findSpans locates the query in runes, comparing rune by rune so offsets match the original text
+func findSpans(runes []rune, query string) []span {
+	q := []rune(strings.TrimSpace(query))
+	if len(q) == 0 {
+		return nil
+	}
+	for i, r := range q {
+		q[i] = unicode.ToLower(r)
+	}
+
+	var spans []span
+	for i := 0; i+len(q) <= len(runes); {
+		if hasPrefixFold(runes[i:], q) {
+			spans = append(spans, span{start: i, end: i + len(q)})
+			i += len(q)
+			continue
+		}
+		i++
+	}
+	return spans
+}
+
+// hasPrefixFold reports whether runes starts with the lower-cased query
+func hasPrefixFold(runes, q []rune) bool {
+	for i, r := range q {
+		if unicode.ToLower(runes[i]) != r {
+			return false
+		}
+	}
+	return true
+}
+
+// utf16Len returns the length of runes in UTF-16 code units
+func utf16Len(runes []rune) int {
+	n := 0
+	for _, r := range runes {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,140 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Func is a unit of scheduled work. It receives the tick time so jobs can be tested deterministically.
+type Func func(ctx context.Context, now time.Time) error
+
+// entry is a registered job
+type entry struct {
+	name     string
+	interval time.Duration
+	fn       Func
+}
+
+// Status is the outcome of the last run of a job
+type Status struct {
+	Name     string
+	Interval time.Duration
+	// LastRunAt is when the last run finished; nil until the job has run
+	LastRunAt *time.Time
+	// LastError is the error of the last run, or empty if it succeeded
+	LastError string
+}
+
+// Scheduler runs registered jobs periodically in background goroutines
+type Scheduler struct {
+	jobs   []entry
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	statuses map[string]Status
+}
+
+// NewScheduler creates a new Scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{statuses: map[string]Status{}}
+}
+
+// Register adds a job that runs every interval. Must be called before Start.
+func (s *Scheduler) Register(name string, interval time.Duration, fn Func) {
+	s.jobs = append(s.jobs, entry{
+		name:     name,
+		interval: interval,
+		fn:       fn,
+	})
+
+	s.mu.Lock()
+	s.statuses[name] = Status{Name: name, Interval: interval}
+	s.mu.Unlock()
+}
+
+// Statuses returns the status of every registered job, in registration order
+func (s *Scheduler) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, len(s.jobs))
+	for i, j := range s.jobs {
+		statuses[i] = s.statuses[j.name]
+	}
+	return statuses
+}
+
+// record stores the outcome of a run of a job
+func (s *Scheduler) record(j entry, err error) {
+	now := time.Now()
+	status := Status{Name: j.name, Interval: j.interval, LastRunAt: &now}
+	if err != nil {
+		status.LastError = err.Error()
+	}
+
+	s.mu.Lock()
+	s.statuses[j.name] = status
+	s.mu.Unlock()
+}
+
+// Start launches all registered jobs
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.run(ctx, j)
+	}
+
+	log.Info().Int("jobs", len(s.jobs)).Msg("Scheduler started")
+}
+
+// Stop cancels all running jobs and waits for in-flight runs to finish
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	log.Info().Msg("Scheduler stopped")
+}
+
+// run executes a single job on its interval until the context is cancelled
+func (s *Scheduler) run(ctx context.Context, j entry) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.execute(ctx, j, now)
+		}
+	}
+}
+
+// execute runs a job once, recovering from panics so one job cannot take down the scheduler
+func (s *Scheduler) execute(ctx context.Context, j entry, now time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Str("job", j.name).Msg("Scheduled job panicked")
+			s.record(j, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	start := time.Now()
+	err := j.fn(ctx, now)
+	s.record(j, err)
+	if err != nil {
+		log.Error().Err(err).Str("job", j.name).Msg("Scheduled job failed")
+		return
+	}
+	log.Debug().Str("job", j.name).Dur("elapsed", time.Since(start)).Msg("Scheduled job finished")
+}
@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	appconfig "todo-api/internal/config"
+)
+
+// Message represents an outgoing email
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Mailer defines the interface for sending emails
+type Mailer interface {
+	// Send delivers the message to its recipient
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New returns an SMTP mailer when SMTP is configured, otherwise a mailer that only logs messages
+func New(cfg *appconfig.MailConfig) Mailer {
+	if cfg.SMTPHost == "" {
+		log.Info().Msg("SMTP is not configured, emails will be logged instead of sent")
+		return NewLogMailer()
+	}
+	return NewSMTPMailer(cfg)
+}
+
+// LogMailer writes messages to the application log instead of sending them
+type LogMailer struct{}
+
+// NewLogMailer creates a new LogMailer
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send logs the message
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	log.Info().
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Msg("Email (not sent, SMTP disabled)")
+	return nil
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/model"
+	"todo-api/internal/service"
+	"todo-api/pkg/database"
+)
+
+// AuditWrites records every successful write request in the audit log, with the request ID and client IP.
+// The rows it writes are recorded separately by service.AuditService.RegisterCallbacks, including those of
+// background jobs; this entry tells where the request came from. The request ID and IP are also set on the
+// request context (database.WithRequest), so the entries of the rows carry them too.
+// The resource is derived from the matched route: its static segments after /api/v1 form the resource type
+// (e.g. "todos.comments") and its last path parameter is the resource ID, which is empty for creations. A failure to record is logged
+// rather than failing the request, whose response has already been written. The actor is read after the
+// request is handled, so it may be mounted before the authentication middleware.
+func AuditWrites(auditService *service.AuditService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := database.Request{ID: auditRequestID(c), IP: auditIP(c)}
+			c.SetRequest(c.Request().WithContext(database.WithRequest(c.Request().Context(), req)))
+			err := next(c)
+
+			action, write := service.AuditActionForMethod(c.Request().Method)
+			if !write || err != nil || c.Response().Status >= http.StatusBadRequest {
+				return err
+			}
+
+			entry := auditEntry(c, action)
+			if recordErr := auditService.Record(c.Request().Context(), entry); recordErr != nil {
+				log.Error().Err(recordErr).Str("path", entry.Path).Msg("AuditWrites: failed to record audit log")
+			}
+			return nil
+		}
+	}
+}
+
+// auditEntry builds the audit log entry of a request
+func auditEntry(c echo.Context, action model.AuditAction) *model.AuditLog {
+	entry := &model.AuditLog{
+		Action:     action,
+		Method:     c.Request().Method,
+		Path:       c.Request().URL.Path,
+		StatusCode: c.Response().Status,
+		RequestID:  auditRequestID(c),
+		IPAddress:  auditIP(c),
+	}
+	if currentUser := GetCurrentUser(c); currentUser != nil {
+		entry.ActorID = &currentUser.ID
+		entry.ActorEmail = currentUser.Email
+	}
+
+	var resource []string
+	for _, segment := range strings.Split(strings.TrimPrefix(c.Path(), "/api/v1/"), "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			entry.ResourceID = c.Param(name)
+		} else if segment != "" {
+			resource = append(resource, segment)
+		}
+	}
+	entry.ResourceType = strings.Join(resource, ".")
+	return entry
+}
+
+// auditRequestID returns the ID of a request, as set by the RequestID middleware or else by the client
+func auditRequestID(c echo.Context) string {
+	if id := c.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Request().Header.Get(echo.HeaderXRequestID)
+}
+
+// auditIP returns the client IP of a request: the direct peer, as X-Forwarded-For and X-Real-IP can be set by the client
+func auditIP(c echo.Context) string {
+	return echo.ExtractIPDirect()(c.Request())
+}
@@ -8,6 +8,7 @@ import (
 
 	"todo-api/internal/config"
 	"todo-api/internal/errors"
+	"todo-api/internal/model"
 	"todo-api/internal/repository"
 	"todo-api/internal/service"
 	"todo-api/pkg/util"
@@ -24,6 +25,10 @@ type CurrentUser struct {
 	ID    int64
 	Email string
 	Name  string
+	// APIDailyQuota is the user's override of API_DAILY_QUOTA, if any
+	APIDailyQuota *int
+	// ViaIntegration is set when the request is signed by an integration rather than made by the user
+	ViaIntegration bool
 }
 
 // JWTAuth creates a JWT authentication middleware
@@ -46,7 +51,7 @@ func JWTAuth(cfg *config.Config, userRepo *repository.UserRepository, denylistRe
 			tokenString := parts[1]
 
 			// Validate token
-			claims, err := authService.ValidateToken(tokenString)
+			claims, err := authService.ValidateToken(c.Request().Context(), tokenString)
 			if err != nil {
 				errMsg := err.Error()
 				if strings.Contains(errMsg, "expired") {
@@ -65,17 +70,13 @@ func JWTAuth(cfg *config.Config, userRepo *repository.UserRepository, denylistRe
 			}
 
 			// Get user from database
-			user, err := userRepo.FindByID(userID)
+			user, err := userRepo.FindByID(c.Request().Context(), userID)
 			if err != nil {
 				return errors.AuthenticationFailed("User not found")
 			}
 
 			// Set current user in context
-			c.Set(CurrentUserKey, &CurrentUser{
-				ID:    user.ID,
-				Email: user.Email,
-				Name:  util.DerefString(user.Name, ""),
-			})
+			c.Set(CurrentUserKey, newCurrentUser(user))
 
 			// Store claims for later use (e.g., sign out)
 			c.Set(JWTClaimsKey, claims)
@@ -85,6 +86,30 @@ func JWTAuth(cfg *config.Config, userRepo *repository.UserRepository, denylistRe
 	}
 }
 
+// newCurrentUser creates the CurrentUser of an authenticated user
+func newCurrentUser(user *model.User) *CurrentUser {
+	return &CurrentUser{
+		ID:    user.ID,
+		Email: user.Email,
+		Name:  util.DerefString(user.Name, ""),
+
+		APIDailyQuota: user.APIDailyQuota,
+	}
+}
+
+// RequireAdmin allows only the admins listed in ADMIN_EMAILS, and not their integrations. Must run after JWTAuth.
+func RequireAdmin(cfg *config.Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			currentUser := GetCurrentUser(c)
+			if currentUser == nil || currentUser.ViaIntegration || !cfg.IsAdmin(currentUser.Email) {
+				return errors.AuthorizationFailed("admin", "access")
+			}
+			return next(c)
+		}
+	}
+}
+
 // GetCurrentUser retrieves the current user from the request context
 func GetCurrentUser(c echo.Context) *CurrentUser {
 	user, ok := c.Get(CurrentUserKey).(*CurrentUser)
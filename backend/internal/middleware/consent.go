@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/service"
+)
+
+// PolicyAcceptanceRequiredHeader lists the policies the current user has not accepted in their current version
+const PolicyAcceptanceRequiredHeader = "X-Policy-Acceptance-Required"
+
+// RequirePolicyConsent checks that the current user has accepted the current policy versions.
+// Depending on POLICY_CONSENT_ENFORCEMENT, users who have not are flagged with a response header
+// or rejected with POLICY_ACCEPTANCE_REQUIRED. Must run after JWTAuth.
+func RequirePolicyConsent(consentService *service.ConsentService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			currentUser := GetCurrentUser(c)
+			if currentUser == nil || !consentService.Enabled() {
+				return next(c)
+			}
+
+			pending, err := consentService.Pending(c.Request().Context(), currentUser.ID)
+			if err != nil {
+				return errors.InternalErrorWithLog(err, "RequirePolicyConsent: failed to check consents")
+			}
+			if len(pending) > 0 {
+				if consentService.Blocking() {
+					return errors.PolicyAcceptanceRequired(pending)
+				}
+				c.Response().Header().Set(PolicyAcceptanceRequiredHeader, strings.Join(pending, ","))
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/errors"
+)
+
+// RequireNetworkOrAdmin allows requests connecting from one of the networks, and otherwise passes them
+// to adminAuth (e.g. JWTAuth followed by RequireAdmin). Without adminAuth other requests are rejected.
+//
+// The address of the connection is used rather than X-Forwarded-For, which clients can set:
+// behind a reverse proxy, only admins are allowed unless the proxy's own address is listed.
+func RequireNetworkOrAdmin(networks []*net.IPNet, adminAuth echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		var adminNext echo.HandlerFunc
+		if adminAuth != nil {
+			adminNext = adminAuth(next)
+		}
+
+		return func(c echo.Context) error {
+			if ip := net.ParseIP(echo.ExtractIPDirect()(c.Request())); ip != nil {
+				for _, network := range networks {
+					if network.Contains(ip) {
+						return next(c)
+					}
+				}
+			}
+			if adminNext == nil {
+				return errors.AuthorizationFailed("network", "access")
+			}
+			return adminNext(c)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"todo-api/internal/config"
+	"todo-api/internal/errors"
+	"todo-api/internal/service"
+	"todo-api/internal/tenant"
+)
+
+// ResolveTenant resolves the tenant of a request in schema tenancy mode and adds it to the request context.
+// The tenant is the subdomain of TENANT_BASE_DOMAIN or, without one, the tenant claim of the bearer token;
+// when a request has both they must match. Export downloads and the calendar OAuth callback, which are
+// reached through URLs shared by all tenants, fall back to the tenant their token or state is prefixed with.
+// The token is fully validated (denylist, user) later by JWTAuth of the tenant.
+func ResolveTenant(cfg *config.Config) echo.MiddlewareFunc {
+	baseDomain := cfg.GetTenancyConfig().BaseDomain
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			hostTenant, fromHost := tenant.FromHost(c.Request().Host, baseDomain)
+
+			claimTenant := ""
+			if tokenString, ok := bearerToken(c); ok {
+				claims, err := service.ParseToken(tokenString, cfg.JWTSecret)
+				switch {
+				case err == nil:
+					claimTenant = claims.Tenant
+				case fromHost:
+					// JWTAuth of the tenant reports the error if the route needs the token
+				case strings.Contains(err.Error(), "expired"):
+					return errors.TokenExpired()
+				default:
+					return errors.AuthenticationFailed("Invalid token")
+				}
+			}
+
+			slug := hostTenant
+			switch {
+			case fromHost && claimTenant != "" && claimTenant != hostTenant:
+				return errors.AuthenticationFailed("Token belongs to another tenant")
+			case !fromHost && claimTenant != "":
+				slug = claimTenant
+			case !fromHost:
+				var ok bool
+				if slug, ok = tokenTenant(c); !ok {
+					return errors.ParameterMissing("tenant")
+				}
+			}
+
+			c.SetRequest(c.Request().WithContext(tenant.WithSlug(c.Request().Context(), slug)))
+			return next(c)
+		}
+	}
+}
+
+// tenantTokenParams are the query parameters carrying a token prefixed with the tenant
+// (tenant.TokenWithSlug), by the path of the routes reached through URLs shared by all tenants
+var tenantTokenParams = map[string]string{
+	"/exports/download":         "token",
+	"/calendar/google/callback": "state",
+}
+
+// tokenTenant returns the tenant of the token in the query of a route reached through a shared URL
+func tokenTenant(c echo.Context) (string, bool) {
+	param, ok := tenantTokenParams[c.Request().URL.Path]
+	if !ok {
+		return "", false
+	}
+	return tenant.SlugFromToken(c.QueryParam(param))
+}
+
+// bearerToken returns the token of a "Bearer" Authorization header
+func bearerToken(c echo.Context) (string, bool) {
+	parts := strings.SplitN(c.Request().Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", false
+	}
+	return parts[1], true
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/service"
+)
+
+// Rate limit headers describing the current user's daily API quota
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// MeterAPIUsage counts the request against the current user's daily usage and sets the rate limit headers.
+// Requests signed by an integration are counted for it as well as against the user's quota.
+// Requests over the daily quota are rejected with RATE_LIMIT_EXCEEDED. If the request cannot be counted,
+// it is served without rate limit headers rather than failing. Must run after JWTAuth or SignatureAuth.
+func MeterAPIUsage(usageService *service.UsageService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			currentUser := GetCurrentUser(c)
+			if currentUser == nil {
+				return next(c)
+			}
+
+			var integrationID *int64
+			if integration := GetIntegration(c); integration != nil {
+				integrationID = &integration.ID
+			}
+
+			quota, err := usageService.Record(c.Request().Context(), currentUser.ID, integrationID, currentUser.APIDailyQuota)
+			if err != nil {
+				log.Error().Err(err).Int64("user_id", currentUser.ID).Msg("MeterAPIUsage: failed to record API usage")
+				return next(c)
+			}
+			if !quota.Limited() {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(RateLimitLimitHeader, strconv.Itoa(quota.Limit))
+			header.Set(RateLimitRemainingHeader, strconv.FormatInt(quota.Remaining(), 10))
+			header.Set(RateLimitResetHeader, strconv.FormatInt(quota.ResetAt.Unix(), 10))
+			if quota.Exceeded() {
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(int(time.Until(quota.ResetAt).Seconds())+1))
+				return errors.RateLimitExceeded()
+			}
+			return next(c)
+		}
+	}
+}
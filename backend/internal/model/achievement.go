@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+)
+
+// AchievementCode identifies a milestone achievement
+type AchievementCode string
+
+const (
+	AchievementFirstCompletion AchievementCode = "first_completion"
+	AchievementCompleted10     AchievementCode = "completed_10"
+	AchievementCompleted50     AchievementCode = "completed_50"
+	AchievementCompleted100    AchievementCode = "completed_100"
+	AchievementCompleted500    AchievementCode = "completed_500"
+	AchievementStreak3         AchievementCode = "streak_3"
+	AchievementStreak7         AchievementCode = "streak_7"
+	AchievementStreak30        AchievementCode = "streak_30"
+	AchievementStreak100       AchievementCode = "streak_100"
+)
+
+// AchievementDefinition describes a milestone and the condition that unlocks it
+type AchievementDefinition struct {
+	Code        AchievementCode
+	Title       string
+	Description string
+	Unlocked    func(s *UserStreak) bool
+}
+
+// Achievements lists all milestone achievements in display order
+var Achievements = []AchievementDefinition{
+	{AchievementFirstCompletion, "First Step", "Complete your first todo", totalAtLeast(1)},
+	{AchievementCompleted10, "Getting Things Done", "Complete 10 todos", totalAtLeast(10)},
+	{AchievementCompleted50, "Productive", "Complete 50 todos", totalAtLeast(50)},
+	{AchievementCompleted100, "Centurion", "Complete 100 todos", totalAtLeast(100)},
+	{AchievementCompleted500, "Unstoppable", "Complete 500 todos", totalAtLeast(500)},
+	{AchievementStreak3, "On a Roll", "Complete todos 3 days in a row", streakAtLeast(3)},
+	{AchievementStreak7, "Week Warrior", "Complete todos 7 days in a row", streakAtLeast(7)},
+	{AchievementStreak30, "Habit Formed", "Complete todos 30 days in a row", streakAtLeast(30)},
+	{AchievementStreak100, "Legendary", "Complete todos 100 days in a row", streakAtLeast(100)},
+}
+
+func totalAtLeast(n int) func(s *UserStreak) bool {
+	return func(s *UserStreak) bool { return s.TotalCompleted >= n }
+}
+
+func streakAtLeast(n int) func(s *UserStreak) bool {
+	return func(s *UserStreak) bool { return s.LongestStreak >= n }
+}
+
+// UserAchievement records when a user unlocked an achievement
+type UserAchievement struct {
+	ID         int64           `gorm:"primaryKey" json:"id"`
+	UserID     int64           `gorm:"not null;uniqueIndex:idx_user_achievement" json:"user_id"`
+	Code       AchievementCode `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_achievement" json:"code"`
+	AchievedAt time.Time       `gorm:"not null" json:"achieved_at"`
+	CreatedAt  time.Time       `json:"created_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName returns the table name for the UserAchievement model
+func (UserAchievement) TableName() string {
+	return "user_achievements"
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// APIUsage counts the API requests a user made during one UTC day, either with a user token
+// (IntegrationID nil) or through one of their integrations
+type APIUsage struct {
+	ID     int64 `gorm:"primaryKey" json:"id"`
+	UserID int64 `gorm:"not null;index:idx_api_usage_user_date_integration" json:"user_id"`
+	// Date is the UTC day (YYYY-MM-DD)
+	Date string `gorm:"not null;size:10;index:idx_api_usage_user_date_integration;index" json:"date"`
+	// IntegrationID is kept after the integration is deleted, so that its requests still count
+	IntegrationID *int64    `gorm:"index:idx_api_usage_user_date_integration" json:"integration_id"`
+	RequestCount  int64     `gorm:"not null;default:0" json:"request_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for the APIUsage model
+func (APIUsage) TableName() string {
+	return "api_usages"
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"time"
+)
+
+// AuditAction is the kind of write recorded in the audit log
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// AuditLog is an append-only record of a write operation.
+// Entries form a hash chain: Hash covers the entry and the previous entry's hash,
+// so editing or removing an entry breaks the chain after it. PrevHash is unique, so concurrent
+// writers cannot both extend the same entry.
+// It has no foreign key to users, so entries outlive the accounts they mention.
+type AuditLog struct {
+	ID           int64       `gorm:"primaryKey" json:"id"`
+	ActorID      *int64      `gorm:"index" json:"actor_id"`
+	ActorEmail   string      `gorm:"size:255" json:"actor_email"`
+	Action       AuditAction `gorm:"size:20;not null;index" json:"action"`
+	ResourceType string      `gorm:"size:100;not null;index:idx_audit_log_resource" json:"resource_type"`
+	ResourceID   string      `gorm:"size:100;index:idx_audit_log_resource" json:"resource_id"`
+	Method       string      `gorm:"size:10;not null" json:"method"`
+	Path         string      `gorm:"size:500;not null" json:"path"`
+	StatusCode   int         `gorm:"not null" json:"status_code"`
+	RequestID    string      `gorm:"size:100" json:"request_id"`
+	IPAddress    string      `gorm:"size:45" json:"ip_address"`
+	CreatedAt    time.Time   `gorm:"not null;index" json:"created_at"`
+	PrevHash     string      `gorm:"size:64;not null;uniqueIndex" json:"prev_hash"`
+	Hash         string      `gorm:"size:64;not null;uniqueIndex" json:"hash"`
+}
+
+// TableName returns the table name for the AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// PendingAuditLog is an audit log entry not yet linked into the hash chain.
+// Writes insert their entries here, in their own transaction, so they do not wait on each other for the
+// head of the chain; AuditService.Flush moves them into audit_logs in insertion order.
+type PendingAuditLog struct {
+	ID           int64 `gorm:"primaryKey"`
+	ActorID      *int64
+	ActorEmail   string      `gorm:"size:255"`
+	Action       AuditAction `gorm:"size:20;not null"`
+	ResourceType string      `gorm:"size:100;not null"`
+	ResourceID   string      `gorm:"size:100"`
+	Method       string      `gorm:"size:10;not null"`
+	Path         string      `gorm:"size:500;not null"`
+	StatusCode   int         `gorm:"not null"`
+	RequestID    string      `gorm:"size:100"`
+	IPAddress    string      `gorm:"size:45"`
+	CreatedAt    time.Time   `gorm:"not null"`
+}
+
+// TableName returns the table name for the PendingAuditLog model
+func (PendingAuditLog) TableName() string {
+	return "pending_audit_logs"
+}
+
+// NewPendingAuditLog returns the pending entry of an audit log entry; its hashes are left out
+func NewPendingAuditLog(entry *AuditLog) *PendingAuditLog {
+	return &PendingAuditLog{
+		ActorID:      entry.ActorID,
+		ActorEmail:   entry.ActorEmail,
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		Method:       entry.Method,
+		Path:         entry.Path,
+		StatusCode:   entry.StatusCode,
+		RequestID:    entry.RequestID,
+		IPAddress:    entry.IPAddress,
+		CreatedAt:    entry.CreatedAt,
+	}
+}
+
+// Entry returns the audit log entry of a pending entry, to be linked into the chain
+func (p *PendingAuditLog) Entry() *AuditLog {
+	return &AuditLog{
+		ActorID:      p.ActorID,
+		ActorEmail:   p.ActorEmail,
+		Action:       p.Action,
+		ResourceType: p.ResourceType,
+		ResourceID:   p.ResourceID,
+		Method:       p.Method,
+		Path:         p.Path,
+		StatusCode:   p.StatusCode,
+		RequestID:    p.RequestID,
+		IPAddress:    p.IPAddress,
+		CreatedAt:    p.CreatedAt,
+	}
+}
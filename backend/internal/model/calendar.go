@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+)
+
+// CalendarConnection links a user to the calendar their todos' due dates are synced to.
+// While the user is being sent through the provider's consent page it holds the hash of the OAuth state.
+type CalendarConnection struct {
+	ID             int64      `gorm:"primaryKey" json:"id"`
+	UserID         int64      `gorm:"not null;uniqueIndex" json:"user_id"`
+	Provider       string     `gorm:"size:20;not null" json:"provider"`
+	CalendarID     string     `gorm:"size:255" json:"calendar_id"`
+	AccessToken    string     `gorm:"type:text;serializer:encrypted" json:"-"`
+	RefreshToken   string     `gorm:"type:text;serializer:encrypted" json:"-"`
+	TokenExpiresAt *time.Time `json:"token_expires_at"`
+	// PullEnabled applies completion and date edits made in the calendar back to the todos
+	PullEnabled    bool       `gorm:"not null;default:false" json:"pull_enabled"`
+	StateHash      *string    `gorm:"size:64;uniqueIndex" json:"-"`
+	StateExpiresAt *time.Time `json:"-"`
+	ConnectedAt    *time.Time `json:"connected_at"`
+	LastPushedAt   *time.Time `json:"last_pushed_at"`
+	LastPulledAt   *time.Time `json:"last_pulled_at"`
+	LastError      string     `gorm:"type:text" json:"last_error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for the CalendarConnection model
+func (CalendarConnection) TableName() string {
+	return "calendar_connections"
+}
+
+// Connected returns true once the user granted access and the calendar was created
+func (c *CalendarConnection) Connected() bool {
+	return c.ConnectedAt != nil && c.CalendarID != ""
+}
+
+// CalendarEvent maps a todo to the calendar event of its due date
+type CalendarEvent struct {
+	ID           int64  `gorm:"primaryKey" json:"id"`
+	UserID       int64  `gorm:"not null;index" json:"user_id"`
+	ConnectionID int64  `gorm:"not null;uniqueIndex:idx_calendar_event_todo" json:"connection_id"`
+	TodoUUID     string `gorm:"size:36;not null;uniqueIndex:idx_calendar_event_todo" json:"todo_uuid"`
+	EventID      string `gorm:"size:255;not null" json:"event_id"`
+	// EventUpdatedAt is the event's update time as last written or read, to tell edits made in the calendar
+	EventUpdatedAt time.Time `json:"event_updated_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// Relations
+	Connection *CalendarConnection `gorm:"foreignKey:ConnectionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for the CalendarEvent model
+func (CalendarEvent) TableName() string {
+	return "calendar_events"
+}
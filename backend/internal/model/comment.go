@@ -17,7 +17,7 @@ const EditWindowMinutes = 15
 // Comment represents a comment on a resource (polymorphic)
 type Comment struct {
 	ID              int64          `gorm:"primaryKey" json:"id"`
-	Content         string         `gorm:"type:text;not null" json:"content"`
+	Content         string         `gorm:"type:text;not null;serializer:encrypted" json:"content"`
 	UserID          int64          `gorm:"not null;index" json:"user_id"`
 	CommentableType string         `gorm:"not null;size:50;index:idx_commentable" json:"commentable_type"`
 	CommentableID   int64          `gorm:"not null;index:idx_commentable" json:"commentable_id"`
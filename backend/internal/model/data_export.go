@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+)
+
+// DataExportStatus represents the processing state of an account data export
+type DataExportStatus string
+
+const (
+	DataExportStatusPending    DataExportStatus = "pending"
+	DataExportStatusProcessing DataExportStatus = "processing"
+	DataExportStatusCompleted  DataExportStatus = "completed"
+	DataExportStatusFailed     DataExportStatus = "failed"
+	DataExportStatusExpired    DataExportStatus = "expired"
+)
+
+// DataExport represents a requested archive of all of a user's data.
+// Pending rows form the queue processed by the data export job.
+type DataExport struct {
+	ID          int64            `gorm:"primaryKey" json:"id"`
+	UserID      int64            `gorm:"not null;index" json:"user_id"`
+	Status      DataExportStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	StoragePath *string          `gorm:"size:500" json:"-"`
+	FileSize    int64            `gorm:"not null;default:0" json:"file_size"`
+	TokenHash   *string          `gorm:"size:64;uniqueIndex" json:"-"`
+	Error       *string          `gorm:"type:text" json:"error,omitempty"`
+	CompletedAt *time.Time       `json:"completed_at"`
+	ExpiresAt   *time.Time       `gorm:"index" json:"expires_at"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName returns the table name for the DataExport model
+func (DataExport) TableName() string {
+	return "data_exports"
+}
+
+// IsInProgress returns true if the export has not finished yet
+func (e *DataExport) IsInProgress() bool {
+	return e.Status == DataExportStatusPending || e.Status == DataExportStatusProcessing
+}
+
+// IsDownloadable returns true if the archive exists and the link has not expired
+func (e *DataExport) IsDownloadable(now time.Time) bool {
+	return e.Status == DataExportStatusCompleted &&
+		e.StoragePath != nil &&
+		e.ExpiresAt != nil &&
+		now.Before(*e.ExpiresAt)
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// MaxEscalationWithinHours is the upper bound for how long before the due date a rule applies (30 days)
+const MaxEscalationWithinHours = 720
+
+// EscalationRule raises the priority of a user's incomplete todos as their due date approaches.
+// Rules are opt-in: todos of users without enabled rules are never escalated.
+type EscalationRule struct {
+	ID     int64 `gorm:"primaryKey" json:"id"`
+	UserID int64 `gorm:"not null;index" json:"user_id"`
+	// WithinHours is how long before the end of the due date the rule starts to apply
+	WithinHours int `gorm:"not null" json:"within_hours"`
+	// Priority is the priority todos are raised to; todos already at or above it are left alone
+	Priority Priority `gorm:"not null" json:"priority"`
+	// Notify sends an email listing the todos the rule escalated
+	Notify    bool      `gorm:"not null" json:"notify"`
+	Enabled   bool      `gorm:"not null;index" json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName returns the table name for the EscalationRule model
+func (EscalationRule) TableName() string {
+	return "escalation_rules"
+}
@@ -18,6 +18,17 @@ const (
 	FileTypeOther    FileType = "other"
 )
 
+// ScanStatus represents the antivirus scan state of a file
+type ScanStatus string
+
+const (
+	ScanStatusPending  ScanStatus = "pending"
+	ScanStatusClean    ScanStatus = "clean"
+	ScanStatusInfected ScanStatus = "infected"
+	ScanStatusError    ScanStatus = "error"
+	ScanStatusSkipped  ScanStatus = "skipped"
+)
+
 // MaxFileSize is the maximum allowed file size in bytes (10MB)
 const MaxFileSize = 10 * 1024 * 1024
 
@@ -60,6 +71,12 @@ type File struct {
 	ThumbPath      *string  `gorm:"size:500" json:"thumb_path,omitempty"`
 	MediumPath     *string  `gorm:"size:500" json:"medium_path,omitempty"`
 
+	// Antivirus scan results
+	ScanStatus    ScanStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"scan_status"`
+	ScanSignature *string    `gorm:"size:255" json:"scan_signature,omitempty"`
+	ScannedAt     *time.Time `json:"scanned_at,omitempty"`
+	QuarantinedAt *time.Time `json:"quarantined_at,omitempty"`
+
 	CreatedAt time.Time `gorm:"index" json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -77,6 +94,21 @@ func (f *File) IsImage() bool {
 	return f.FileType == FileTypeImage
 }
 
+// IsQuarantined returns true if the file was flagged as infected and moved to quarantine
+func (f *File) IsQuarantined() bool {
+	return f.QuarantinedAt != nil
+}
+
+// IsInfected returns true if the scanner flagged the file, whether or not it has been quarantined yet
+func (f *File) IsInfected() bool {
+	return f.ScanStatus == ScanStatusInfected || f.IsQuarantined()
+}
+
+// IsAwaitingScan returns true if the file has not been scanned successfully yet
+func (f *File) IsAwaitingScan() bool {
+	return f.ScanStatus == ScanStatusPending || f.ScanStatus == ScanStatusError
+}
+
 // IsOwnedBy checks if the file is owned by the given user
 func (f *File) IsOwnedBy(userID int64) bool {
 	return f.UserID == userID
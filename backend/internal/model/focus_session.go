@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+)
+
+// DefaultFocusMinutes is the default planned length of a focus (Pomodoro) session
+const DefaultFocusMinutes = 25
+
+// FocusSession represents a time-boxed deep-work session against a todo
+type FocusSession struct {
+	ID              int64      `gorm:"primaryKey" json:"id"`
+	UserID          int64      `gorm:"not null;index:idx_focus_user_started" json:"user_id"`
+	TodoID          int64      `gorm:"not null;index" json:"todo_id"`
+	StartedAt       time.Time  `gorm:"not null;index:idx_focus_user_started" json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at"`
+	PlannedSeconds  int        `gorm:"not null" json:"planned_seconds"`
+	DurationSeconds int        `gorm:"not null;default:0" json:"duration_seconds"`
+	Completed       bool       `gorm:"not null;default:false" json:"completed"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Relations
+	Todo *Todo `gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE" json:"-"`
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName returns the table name for the FocusSession model
+func (FocusSession) TableName() string {
+	return "focus_sessions"
+}
+
+// IsActive returns true if the session has not been stopped yet
+func (s *FocusSession) IsActive() bool {
+	return s.EndedAt == nil
+}
+
+// Stop ends the session at the given time, recording the elapsed duration and
+// whether the planned time box was reached
+func (s *FocusSession) Stop(at time.Time) {
+	elapsed := int(at.Sub(s.StartedAt).Seconds())
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	s.EndedAt = &at
+	s.DurationSeconds = elapsed
+	s.Completed = elapsed >= s.PlannedSeconds
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+)
+
+// Integration is a server-to-server caller acting as its user with HMAC-signed requests.
+// The secret is kept rather than hashed, since verifying a signature needs it; it is encrypted when a key ring is configured.
+type Integration struct {
+	ID         int64      `gorm:"primaryKey" json:"id"`
+	UserID     int64      `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	KeyID      string     `gorm:"size:32;not null;uniqueIndex" json:"key_id"`
+	Secret     string     `gorm:"type:text;not null;serializer:encrypted" json:"-"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for the Integration model
+func (Integration) TableName() string {
+	return "integrations"
+}
+
+// IntegrationNonce is a nonce an integration has already signed a request with,
+// kept until the request's timestamp falls out of the accepted window
+type IntegrationNonce struct {
+	ID            int64     `gorm:"primaryKey" json:"id"`
+	IntegrationID int64     `gorm:"not null;uniqueIndex:idx_integration_nonce" json:"integration_id"`
+	Nonce         string    `gorm:"size:64;not null;uniqueIndex:idx_integration_nonce" json:"nonce"`
+	ExpiresAt     time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Relations
+	Integration *Integration `gorm:"foreignKey:IntegrationID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for the IntegrationNonce model
+func (IntegrationNonce) TableName() string {
+	return "integration_nonces"
+}
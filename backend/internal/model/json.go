@@ -0,0 +1,60 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"todo-api/pkg/database"
+)
+
+// JSON is a raw JSON column, stored as jsonb on PostgreSQL, json on MySQL, and text on SQLite
+type JSON json.RawMessage
+
+// MarshalJSON returns the raw JSON
+func (j JSON) MarshalJSON() ([]byte, error) {
+	return json.RawMessage(j).MarshalJSON()
+}
+
+// UnmarshalJSON stores a copy of the raw JSON
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	return (*json.RawMessage)(j).UnmarshalJSON(data)
+}
+
+// Value implements driver.Valuer
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return []byte(j), nil
+}
+
+// Scan implements sql.Scanner
+func (j *JSON) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append(JSON(nil), v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JSON", value)
+	}
+	return nil
+}
+
+// GormDBDataType returns the column type for the connected database
+func (JSON) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	switch database.DialectOf(db) {
+	case database.MySQL:
+		return "json"
+	case database.SQLite:
+		return "text"
+	default:
+		return "jsonb"
+	}
+}
@@ -0,0 +1,40 @@
+package model
+
+// All returns every model stored per user, in migration order.
+// In schema tenancy mode each tenant schema has all of them; Tenant is not included.
+func All() []any {
+	return []any{
+		&User{},
+		&JwtDenylist{},
+		&Category{},
+		&Tag{},
+		&Todo{},
+		&TodoTag{},
+		&Comment{},
+		&TodoHistory{},
+		&File{},
+		&Note{},
+		&NoteRevision{},
+		&UserPreference{},
+		&FocusSession{},
+		&UserStreak{},
+		&StreakCompletion{},
+		&UserAchievement{},
+		&DataExport{},
+		&EscalationRule{},
+		&MyDayItem{},
+		&TodoLink{},
+		&PolicyConsent{},
+		&APIUsage{},
+		&OAuthAuthorizationCode{},
+		&OAuthRefreshToken{},
+		&AuditLog{},
+		&PendingAuditLog{},
+		&PendingSearchUpdate{},
+		&Integration{},
+		&IntegrationNonce{},
+		&TodoTombstone{},
+		&CalendarConnection{},
+		&CalendarEvent{},
+	}
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// MyDayItem is a todo the user planned to work on during a given day
+type MyDayItem struct {
+	ID     int64 `gorm:"primaryKey" json:"id"`
+	UserID int64 `gorm:"not null;uniqueIndex:idx_my_day_user_date_todo" json:"user_id"`
+	// Date is the day in the user's time zone
+	Date      time.Time `gorm:"type:date;not null;uniqueIndex:idx_my_day_user_date_todo" json:"date"`
+	TodoID    int64     `gorm:"not null;index;uniqueIndex:idx_my_day_user_date_todo" json:"todo_id"`
+	Position  int       `gorm:"not null" json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relations
+	Todo *Todo `gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE" json:"todo,omitempty"`
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName returns the table name for the MyDayItem model
+func (MyDayItem) TableName() string {
+	return "my_day_items"
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+)
+
+// OAuthAuthorizationCode is a single-use code issued to an OAuth client for a user,
+// redeemable only with the PKCE verifier matching its challenge.
+// FamilyID is the refresh token family of the tokens issued for the code, revoked if the code is reused.
+type OAuthAuthorizationCode struct {
+	ID            int64      `gorm:"primaryKey" json:"id"`
+	CodeHash      string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ClientID      string     `gorm:"size:100;not null" json:"client_id"`
+	UserID        int64      `gorm:"not null;index" json:"user_id"`
+	RedirectURI   string     `gorm:"size:500;not null" json:"redirect_uri"`
+	CodeChallenge string     `gorm:"size:128;not null" json:"-"`
+	FamilyID      string     `gorm:"size:36;not null;default:''" json:"-"`
+	ExpiresAt     time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for the OAuthAuthorizationCode model
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// OAuthRefreshToken is a refresh token bound to the OAuth client it was issued to.
+// Each refresh replaces the token with a new one of the same family.
+// AccessTokenJti and AccessTokenExpiresAt identify the access token issued alongside it,
+// denylisted when the family is revoked.
+type OAuthRefreshToken struct {
+	ID                   int64      `gorm:"primaryKey" json:"id"`
+	TokenHash            string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ClientID             string     `gorm:"size:100;not null" json:"client_id"`
+	UserID               int64      `gorm:"not null;index" json:"user_id"`
+	FamilyID             string     `gorm:"size:36;not null;index" json:"family_id"`
+	AccessTokenJti       string     `gorm:"size:36;not null;default:''" json:"-"`
+	AccessTokenExpiresAt *time.Time `json:"-"`
+	ExpiresAt            time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedAt            *time.Time `json:"revoked_at"`
+	CreatedAt            time.Time  `json:"created_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for the OAuthRefreshToken model
+func (OAuthRefreshToken) TableName() string {
+	return "oauth_refresh_tokens"
+}
+
+// IsActive returns true if the token can still be used
+func (t *OAuthRefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+)
+
+// Policy is a document users must accept, such as the terms of service
+type Policy string
+
+const (
+	// PolicyTerms is the terms of service
+	PolicyTerms Policy = "terms"
+	// PolicyPrivacy is the privacy policy
+	PolicyPrivacy Policy = "privacy"
+)
+
+// PolicyConsent records that a user accepted a version of a policy
+type PolicyConsent struct {
+	ID         int64     `gorm:"primaryKey" json:"id"`
+	UserID     int64     `gorm:"not null;uniqueIndex:idx_policy_consent_user_policy_version" json:"user_id"`
+	Policy     Policy    `gorm:"not null;size:20;uniqueIndex:idx_policy_consent_user_policy_version" json:"policy"`
+	Version    string    `gorm:"not null;size:100;uniqueIndex:idx_policy_consent_user_policy_version" json:"version"`
+	AcceptedAt time.Time `gorm:"not null" json:"accepted_at"`
+	IPAddress  string    `gorm:"size:45" json:"ip_address"`
+	UserAgent  string    `gorm:"size:500" json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for the PolicyConsent model
+func (PolicyConsent) TableName() string {
+	return "policy_consents"
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// PendingSearchUpdate is a write of a searchable row (todo, comment, category, or tag) not yet sent to the
+// external search index. It is inserted in the transaction of the write, so updates of rolled back writes are
+// never sent; the row's current document is built and sent once the write has committed.
+type PendingSearchUpdate struct {
+	ID int64 `gorm:"primaryKey"`
+	// DocType is the search resource type of the row (e.g. "todo")
+	DocType   string    `gorm:"size:20;not null"`
+	DocID     int64     `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for the PendingSearchUpdate model
+func (PendingSearchUpdate) TableName() string {
+	return "pending_search_updates"
+}
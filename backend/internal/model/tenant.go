@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+)
+
+// Tenant represents an organization with its own database schema (schema tenancy mode only).
+// Tenants are stored in the public schema.
+type Tenant struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	Slug      string    `gorm:"uniqueIndex;not null;size:40" json:"slug"`
+	Name      string    `gorm:"not null;size:255" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the Tenant model
+func (Tenant) TableName() string {
+	return "tenants"
+}
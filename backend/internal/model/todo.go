@@ -3,7 +3,11 @@ package model
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	_ "todo-api/internal/encryption" // registers the encrypted serializer
+	"todo-api/pkg/database"
 )
 
 // Priority represents the priority level of a todo
@@ -26,18 +30,26 @@ const (
 
 // Todo represents a task in the system
 type Todo struct {
-	ID          int64      `gorm:"primaryKey" json:"id"`
-	UserID      int64      `gorm:"not null;index" json:"user_id"`
-	CategoryID  *int64     `gorm:"index" json:"category_id"`
-	Title       string     `gorm:"not null;size:255" json:"title"`
-	Description *string    `gorm:"type:text" json:"description"`
-	Completed   bool       `gorm:"default:false" json:"completed"`
-	Position    *int       `gorm:"index" json:"position"`
-	Priority    Priority   `gorm:"not null;default:1;index" json:"priority"`
-	Status      Status     `gorm:"not null;default:0;index" json:"status"`
-	DueDate     *time.Time `gorm:"type:date;index" json:"due_date"`
-	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
-	UpdatedAt   time.Time  `gorm:"index" json:"updated_at"`
+	ID          int64   `gorm:"primaryKey" json:"id"`
+	UserID      int64   `gorm:"not null;index" json:"user_id"`
+	CategoryID  *int64  `gorm:"index" json:"category_id"`
+	Title       string  `gorm:"not null;size:255" json:"title"`
+	Description *string `gorm:"type:text;serializer:encrypted" json:"description"`
+	Completed   bool    `gorm:"default:false" json:"completed"`
+	Position    *int    `gorm:"index" json:"position"`
+	// CategoryPosition orders todos within their category (or among uncategorized todos)
+	CategoryPosition *int       `gorm:"index" json:"category_position"`
+	Priority         Priority   `gorm:"not null;default:1;index" json:"priority"`
+	Status           Status     `gorm:"not null;default:0;index" json:"status"`
+	DueDate          *time.Time `gorm:"type:date;index" json:"due_date"`
+	CompletedAt      *time.Time `gorm:"index" json:"completed_at"`
+	// ArchivedAt is set when retention archives the completed todo; archived todos are left out of the todo list and search
+	ArchivedAt  *time.Time `gorm:"index" json:"archived_at"`
+	SnoozeCount int        `gorm:"not null;default:0" json:"snooze_count"`
+	// UUID identifies the todo across devices; offline clients generate it before syncing
+	UUID      *string   `gorm:"size:36;uniqueIndex" json:"uuid"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `gorm:"index" json:"updated_at"`
 
 	// Relations (will be preloaded when needed)
 	User     *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -50,8 +62,12 @@ func (Todo) TableName() string {
 	return "todos"
 }
 
-// BeforeCreate sets the position for new todos
+// BeforeCreate sets the UUID and position for new todos
 func (t *Todo) BeforeCreate(tx *gorm.DB) error {
+	if t.UUID == nil {
+		id := uuid.NewString()
+		t.UUID = &id
+	}
 	if t.Position == nil {
 		// Get the max position for the user's todos
 		var maxPosition int
@@ -63,6 +79,17 @@ func (t *Todo) BeforeCreate(tx *gorm.DB) error {
 		newPosition := maxPosition + 1
 		t.Position = &newPosition
 	}
+	if t.CategoryPosition == nil {
+		// Append to the end of the todo's category
+		var maxCategoryPosition int
+		tx.Model(&Todo{}).
+			Where("user_id = ? AND "+database.DialectOf(tx).NullSafeEqual("category_id"), t.UserID, t.CategoryID).
+			Select("COALESCE(MAX(category_position), 0)").
+			Scan(&maxCategoryPosition)
+
+		newCategoryPosition := maxCategoryPosition + 1
+		t.CategoryPosition = &newCategoryPosition
+	}
 	return nil
 }
 
@@ -103,3 +130,58 @@ func (s Status) String() string {
 		return "unknown"
 	}
 }
+
+// Snooze presets
+const (
+	SnoozePresetTomorrow = "tomorrow"
+	SnoozePresetNextWeek = "next_week"
+)
+
+// Fields todos can be grouped by
+const (
+	TodoGroupByStatus    = "status"
+	TodoGroupByPriority  = "priority"
+	TodoGroupByCategory  = "category"
+	TodoGroupByDueBucket = "due_bucket"
+)
+
+// IsValidTodoGroupBy checks if the group_by value is supported
+func IsValidTodoGroupBy(groupBy string) bool {
+	switch groupBy {
+	case TodoGroupByStatus, TodoGroupByPriority, TodoGroupByCategory, TodoGroupByDueBucket:
+		return true
+	}
+	return false
+}
+
+// Due buckets group todos by how soon they are due
+const (
+	DueBucketOverdue   = "overdue"
+	DueBucketToday     = "today"
+	DueBucketNext7Days = "next_7_days"
+	DueBucketLater     = "later"
+	DueBucketNoDueDate = "no_due_date"
+)
+
+// DueBuckets lists the due buckets from most to least urgent
+var DueBuckets = []string{DueBucketOverdue, DueBucketToday, DueBucketNext7Days, DueBucketLater, DueBucketNoDueDate}
+
+// DueBucket returns the bucket of the todo's due date relative to today (a date in the user's time zone)
+func (t *Todo) DueBucket(today time.Time) string {
+	if t.DueDate == nil {
+		return DueBucketNoDueDate
+	}
+
+	due := time.Date(t.DueDate.Year(), t.DueDate.Month(), t.DueDate.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	switch days := int(due.Sub(day).Hours() / 24); {
+	case days < 0:
+		return DueBucketOverdue
+	case days == 0:
+		return DueBucketToday
+	case days <= 7:
+		return DueBucketNext7Days
+	default:
+		return DueBucketLater
+	}
+}
@@ -1,7 +1,6 @@
 package model
 
 import (
-	"encoding/json"
 	"time"
 )
 
@@ -9,17 +8,19 @@ import (
 type HistoryAction string
 
 const (
-	ActionCreated         HistoryAction = "created"
-	ActionUpdated         HistoryAction = "updated"
-	ActionDeleted         HistoryAction = "deleted"
-	ActionStatusChanged   HistoryAction = "status_changed"
-	ActionPriorityChanged HistoryAction = "priority_changed"
+	ActionCreated           HistoryAction = "created"
+	ActionUpdated           HistoryAction = "updated"
+	ActionDeleted           HistoryAction = "deleted"
+	ActionStatusChanged     HistoryAction = "status_changed"
+	ActionPriorityChanged   HistoryAction = "priority_changed"
+	ActionPriorityEscalated HistoryAction = "priority_escalated"
+	ActionSnoozed           HistoryAction = "snoozed"
 )
 
 // IsValidHistoryAction checks if the action is valid
 func IsValidHistoryAction(action HistoryAction) bool {
 	switch action {
-	case ActionCreated, ActionUpdated, ActionDeleted, ActionStatusChanged, ActionPriorityChanged:
+	case ActionCreated, ActionUpdated, ActionDeleted, ActionStatusChanged, ActionPriorityChanged, ActionPriorityEscalated, ActionSnoozed:
 		return true
 	default:
 		return false
@@ -28,12 +29,12 @@ func IsValidHistoryAction(action HistoryAction) bool {
 
 // TodoHistory represents a history record for a todo
 type TodoHistory struct {
-	ID        int64           `gorm:"primaryKey" json:"id"`
-	TodoID    int64           `gorm:"not null;index" json:"todo_id"`
-	UserID    int64           `gorm:"not null;index" json:"user_id"`
-	Action    HistoryAction   `gorm:"type:varchar(50);not null;index" json:"action"`
-	Changes   json.RawMessage `gorm:"type:jsonb" json:"changes"`
-	CreatedAt time.Time       `gorm:"not null" json:"created_at"`
+	ID        int64         `gorm:"primaryKey" json:"id"`
+	TodoID    int64         `gorm:"not null;index" json:"todo_id"`
+	UserID    int64         `gorm:"not null;index" json:"user_id"`
+	Action    HistoryAction `gorm:"type:varchar(50);not null;index" json:"action"`
+	Changes   JSON          `json:"changes"`
+	CreatedAt time.Time     `gorm:"not null" json:"created_at"`
 
 	// Relations (will be preloaded when needed)
 	Todo *Todo `gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE" json:"-"`
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+)
+
+// LinkType is the kind of non-blocking relation between two todos
+type LinkType string
+
+const (
+	// LinkTypeRelatesTo links todos that are worth looking at together
+	LinkTypeRelatesTo LinkType = "relates_to"
+	// LinkTypeDuplicates marks the source todo as a duplicate of the target todo
+	LinkTypeDuplicates LinkType = "duplicates"
+)
+
+// IsValidLinkType checks if the link type is supported
+func IsValidLinkType(t LinkType) bool {
+	return t == LinkTypeRelatesTo || t == LinkTypeDuplicates
+}
+
+// TodoLink relates one todo to another without affecting either.
+// A link is shown as a link on the source todo and as a backlink on the target todo.
+type TodoLink struct {
+	ID           int64     `gorm:"primaryKey" json:"id"`
+	UserID       int64     `gorm:"not null;index" json:"user_id"`
+	SourceTodoID int64     `gorm:"not null;uniqueIndex:idx_todo_link_source_target_type" json:"source_todo_id"`
+	TargetTodoID int64     `gorm:"not null;index;uniqueIndex:idx_todo_link_source_target_type" json:"target_todo_id"`
+	LinkType     LinkType  `gorm:"not null;size:20;uniqueIndex:idx_todo_link_source_target_type" json:"link_type"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Relations
+	SourceTodo *Todo `gorm:"foreignKey:SourceTodoID;constraint:OnDelete:CASCADE" json:"source_todo,omitempty"`
+	TargetTodo *Todo `gorm:"foreignKey:TargetTodoID;constraint:OnDelete:CASCADE" json:"target_todo,omitempty"`
+	User       *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName returns the table name for the TodoLink model
+func (TodoLink) TableName() string {
+	return "todo_links"
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// TodoTombstone records a deleted todo's UUID so that sync clients pull the deletion.
+// Tombstones are pruned after a retention period; older sync tokens must resync from scratch.
+type TodoTombstone struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	UserID    int64     `gorm:"not null;index" json:"user_id"`
+	UUID      string    `gorm:"size:36;not null;uniqueIndex" json:"uuid"`
+	DeletedAt time.Time `gorm:"not null;index" json:"deleted_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for the TodoTombstone model
+func (TodoTombstone) TableName() string {
+	return "todo_tombstones"
+}
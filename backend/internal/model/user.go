@@ -17,6 +17,9 @@ type User struct {
 	Name              *string   `gorm:"size:255" json:"name"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
+
+	// APIDailyQuota overrides API_DAILY_QUOTA for the user (0: unlimited)
+	APIDailyQuota *int `gorm:"column:api_daily_quota" json:"-"`
 }
 
 // TableName returns the table name for the User model
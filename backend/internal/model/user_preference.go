@@ -0,0 +1,114 @@
+package model
+
+import (
+	"time"
+)
+
+// DigestFrequency represents how often a user receives the summary email digest
+type DigestFrequency string
+
+const (
+	DigestFrequencyNone   DigestFrequency = "none"
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
+// DefaultTimezone is used when the user has not configured a timezone
+const DefaultTimezone = "UTC"
+
+// DefaultDigestHour is the default local hour at which digests are sent
+const DefaultDigestHour = 8
+
+// MaxRetentionDays is the upper bound for any retention rule
+const MaxRetentionDays = 3650
+
+// MaxWIPLimit is the upper bound for the in-progress todo limit
+const MaxWIPLimit = 100
+
+// WIPLimitMode controls what happens when a todo would exceed the in-progress limit
+type WIPLimitMode string
+
+const (
+	// WIPLimitModeReject refuses to move the todo to in_progress
+	WIPLimitModeReject WIPLimitMode = "reject"
+	// WIPLimitModeWarn allows the change and returns a warning
+	WIPLimitModeWarn WIPLimitMode = "warn"
+)
+
+// IsValidWIPLimitMode checks if the WIP limit mode is valid
+func IsValidWIPLimitMode(m WIPLimitMode) bool {
+	return m == WIPLimitModeReject || m == WIPLimitModeWarn
+}
+
+// IsValidDigestFrequency checks if the digest frequency is valid
+func IsValidDigestFrequency(f DigestFrequency) bool {
+	switch f {
+	case DigestFrequencyNone, DigestFrequencyDaily, DigestFrequencyWeekly:
+		return true
+	default:
+		return false
+	}
+}
+
+// UserPreference holds per-user settings such as timezone and digest subscription
+type UserPreference struct {
+	ID               int64           `gorm:"primaryKey" json:"id"`
+	UserID           int64           `gorm:"not null;uniqueIndex" json:"user_id"`
+	Timezone         string          `gorm:"not null;size:64;default:'UTC'" json:"timezone"`
+	DigestFrequency  DigestFrequency `gorm:"type:varchar(20);not null;default:'none';index" json:"digest_frequency"`
+	DigestHour       int             `gorm:"not null;default:8" json:"digest_hour"`
+	DigestLastSentAt *time.Time      `json:"digest_last_sent_at"`
+
+	// Retention rules; 0 disables a rule
+	PurgeTrashedNotesAfterDays     int        `gorm:"not null;default:0" json:"purge_trashed_notes_after_days"`
+	ArchiveNotesAfterDays          int        `gorm:"not null;default:0" json:"archive_notes_after_days"`
+	ArchiveCompletedTodosAfterDays int        `gorm:"not null;default:0" json:"archive_completed_todos_after_days"`
+	RetentionLastRunAt             *time.Time `json:"retention_last_run_at"`
+
+	// Maximum number of in-progress todos; 0 means no limit
+	WIPLimit     int          `gorm:"not null;default:0" json:"wip_limit"`
+	WIPLimitMode WIPLimitMode `gorm:"type:varchar(10);not null;default:'reject'" json:"wip_limit_mode"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for the UserPreference model
+func (UserPreference) TableName() string {
+	return "user_preferences"
+}
+
+// NewDefaultUserPreference returns the preference values used before the user customizes them
+func NewDefaultUserPreference(userID int64) *UserPreference {
+	return &UserPreference{
+		UserID:          userID,
+		Timezone:        DefaultTimezone,
+		DigestFrequency: DigestFrequencyNone,
+		DigestHour:      DefaultDigestHour,
+		WIPLimitMode:    WIPLimitModeReject,
+	}
+}
+
+// Location returns the user's time zone, falling back to UTC if it cannot be loaded
+func (p *UserPreference) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// DigestEnabled returns true if the user opted in to receive digests
+func (p *UserPreference) DigestEnabled() bool {
+	return p.DigestFrequency == DigestFrequencyDaily || p.DigestFrequency == DigestFrequencyWeekly
+}
+
+// HasRetentionRules returns true if any retention rule is enabled
+func (p *UserPreference) HasRetentionRules() bool {
+	return p.PurgeTrashedNotesAfterDays > 0 ||
+		p.ArchiveNotesAfterDays > 0 ||
+		p.ArchiveCompletedTodosAfterDays > 0
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+)
+
+// UserStreak tracks consecutive days on which a user completed at least one todo.
+// Dates are calendar days in the user's time zone, stored as midnight UTC.
+type UserStreak struct {
+	ID             int64      `gorm:"primaryKey" json:"id"`
+	UserID         int64      `gorm:"not null;uniqueIndex" json:"user_id"`
+	CurrentStreak  int        `gorm:"not null;default:0" json:"current_streak"`
+	LongestStreak  int        `gorm:"not null;default:0" json:"longest_streak"`
+	LastActiveDate *time.Time `gorm:"type:date" json:"last_active_date"`
+	TotalCompleted int        `gorm:"not null;default:0" json:"total_completed"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName returns the table name for the UserStreak model
+func (UserStreak) TableName() string {
+	return "user_streaks"
+}
+
+// StreakCompletion records that a todo was counted towards its owner's streak,
+// so completing it again after reopening it is not counted twice
+type StreakCompletion struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	UserID    int64     `gorm:"not null;index" json:"user_id"`
+	TodoID    int64     `gorm:"not null;uniqueIndex" json:"todo_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relations
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName returns the table name for the StreakCompletion model
+func (StreakCompletion) TableName() string {
+	return "streak_completions"
+}
+
+// RecordCompletion counts a completion on the given calendar day (midnight UTC)
+func (s *UserStreak) RecordCompletion(day time.Time) {
+	s.TotalCompleted++
+
+	switch {
+	case s.LastActiveDate == nil:
+		s.CurrentStreak = 1
+	case day.Equal(*s.LastActiveDate):
+		// Already counted today
+	case day.Equal(s.LastActiveDate.AddDate(0, 0, 1)):
+		s.CurrentStreak++
+	case day.After(*s.LastActiveDate):
+		s.CurrentStreak = 1
+	default:
+		// Completion backdated before the last active day does not affect the streak
+		return
+	}
+
+	s.LastActiveDate = &day
+	if s.CurrentStreak > s.LongestStreak {
+		s.LongestStreak = s.CurrentStreak
+	}
+}
+
+// StreakAsOf returns the streak still alive on the given calendar day.
+// A streak survives until the end of the day after the last completion.
+func (s *UserStreak) StreakAsOf(today time.Time) int {
+	if s.LastActiveDate == nil || today.After(s.LastActiveDate.AddDate(0, 0, 1)) {
+		return 0
+	}
+	return s.CurrentStreak
+}
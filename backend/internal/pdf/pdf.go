@@ -0,0 +1,257 @@
+// Package pdf writes simple documents of text, lines and filled rectangles as PDF.
+//
+// Text is set in HeiseiKakuGo-W5, one of the Japanese fonts PDF viewers provide (Adobe-Japan1),
+// so Japanese and Latin text render without embedding a font file.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// A4 page size in points
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Document is a PDF document built page by page in memory.
+// Coordinates are in points from the top-left corner of the page.
+type Document struct {
+	width, height float64
+	title         string
+	pages         []*bytes.Buffer
+	current       int
+}
+
+// New creates an empty document with pages of the given size
+func New(width, height float64) *Document {
+	return &Document{width: width, height: height, current: -1}
+}
+
+// SetTitle sets the title shown by PDF viewers
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+// AddPage adds a page and makes it the current page
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.current = len(d.pages) - 1
+}
+
+// SetPage makes the page at index (from 0) the current page, e.g. to add page numbers at the end
+func (d *Document) SetPage(index int) {
+	d.current = index
+}
+
+// PageCount returns the number of pages
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// Text draws s with its baseline at y. Line breaks are drawn as spaces.
+func (d *Document) Text(x, y, size float64, s string) {
+	fmt.Fprintf(d.page(), "BT /F1 %s Tf %s %s Td <%s> Tj ET\n", num(size), num(x), num(d.height-y), encode(s))
+}
+
+// TextRight draws s ending at x
+func (d *Document) TextRight(x, y, size float64, s string) {
+	d.Text(x-TextWidth(s, size), y, size, s)
+}
+
+// Line draws a line of the given width and gray level (0 is black, 1 is white)
+func (d *Document) Line(x1, y1, x2, y2, width, gray float64) {
+	fmt.Fprintf(d.page(), "q %s G %s w %s %s m %s %s l S Q\n",
+		num(gray), num(width), num(x1), num(d.height-y1), num(x2), num(d.height-y2))
+}
+
+// FillRect fills a rectangle whose top-left corner is at x, y with a gray level
+func (d *Document) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(d.page(), "q %s g %s %s %s %s re f Q\n", num(gray), num(x), num(d.height-y-h), num(w), num(h))
+}
+
+// page returns the content of the current page, adding the first page if there is none
+func (d *Document) page() *bytes.Buffer {
+	if d.current < 0 {
+		d.AddPage()
+	}
+	return d.pages[d.current]
+}
+
+// TextWidth returns the width of s in points: ASCII and half-width katakana are half the font size wide,
+// everything else is full width
+func TextWidth(s string, size float64) float64 {
+	var em float64
+	for _, r := range s {
+		if halfWidth(r) {
+			em += 0.5
+		} else {
+			em++
+		}
+	}
+	return em * size
+}
+
+// Truncate shortens s with an ellipsis to fit in maxWidth
+func Truncate(s string, size, maxWidth float64) string {
+	if TextWidth(s, size) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && TextWidth(string(runes)+"…", size) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// halfWidth reports whether r is drawn half width by the UniJIS-UCS2-HW-H encoding
+func halfWidth(r rune) bool {
+	return (r >= 0x20 && r <= 0x7e) || (r >= 0xff61 && r <= 0xff9f)
+}
+
+// encode returns s as hex UTF-16BE for the UniJIS-UCS2-HW-H encoding.
+// Control characters become spaces, and characters outside the BMP, which UCS-2 cannot encode, become "?".
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			r = ' '
+		case r > 0xffff:
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// textString returns s as a PDF text string (UTF-16BE with a byte order mark) for metadata
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// num formats a coordinate or size with at most two decimals
+func num(f float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.2f", f), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Fixed objects; each page then takes two objects, the page and its content stream
+const (
+	catalogObj = iota + 1
+	pagesObj
+	infoObj
+	fontObj
+	cidFontObj
+	fontDescriptorObj
+	firstPageObj
+)
+
+// WriteTo writes the document as PDF to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	pw := &writer{w: w}
+	pw.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObj+2*i)
+	}
+
+	pw.object(catalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+	pw.object(pagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	pw.object(infoObj, fmt.Sprintf("<< /Title %s /Producer (todo-api) >>", textString(d.title)))
+	pw.object(fontObj, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /HeiseiKakuGo-W5-UniJIS-UCS2-HW-H "+
+		"/Encoding /UniJIS-UCS2-HW-H /DescendantFonts [%d 0 R] >>", cidFontObj))
+	// CIDs 231-389 are the half-width Latin and katakana glyphs of Adobe-Japan1
+	pw.object(cidFontObj, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /HeiseiKakuGo-W5 "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Japan1) /Supplement 2 >> "+
+		"/FontDescriptor %d 0 R /DW 1000 /W [231 389 500] >>", fontDescriptorObj))
+	pw.object(fontDescriptorObj, "<< /Type /FontDescriptor /FontName /HeiseiKakuGo-W5 /Flags 4 "+
+		"/FontBBox [-92 -250 1010 922] /ItalicAngle 0 /Ascent 752 /Descent -221 /CapHeight 737 /StemV 114 >>")
+
+	for i, content := range d.pages {
+		pageObj := firstPageObj + 2*i
+		pw.object(pageObj, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesObj, num(d.width), num(d.height), fontObj, pageObj+1))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(content.Bytes()); err != nil {
+			return pw.n, err
+		}
+		if err := zw.Close(); err != nil {
+			return pw.n, err
+		}
+		pw.stream(pageObj+1, compressed.Bytes())
+	}
+
+	pw.trailer(infoObj)
+	return pw.n, pw.err
+}
+
+// writer writes PDF objects and remembers their offsets for the cross-reference table
+type writer struct {
+	w       io.Writer
+	n       int64
+	err     error
+	offsets []int64
+}
+
+func (pw *writer) printf(format string, args ...any) {
+	if pw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(pw.w, format, args...)
+	pw.n += int64(n)
+	pw.err = err
+}
+
+func (pw *writer) write(p []byte) {
+	if pw.err != nil {
+		return
+	}
+	n, err := pw.w.Write(p)
+	pw.n += int64(n)
+	pw.err = err
+}
+
+// object writes object id, which must follow the previous one
+func (pw *writer) object(id int, body string) {
+	pw.offsets = append(pw.offsets, pw.n)
+	pw.printf("%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream writes object id as a Flate-compressed stream
+func (pw *writer) stream(id int, data []byte) {
+	pw.offsets = append(pw.offsets, pw.n)
+	pw.printf("%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", id, len(data))
+	pw.write(data)
+	pw.printf("\nendstream\nendobj\n")
+}
+
+// trailer writes the cross-reference table and the trailer
+func (pw *writer) trailer(infoID int) {
+	xref := pw.n
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	for _, offset := range pw.offsets {
+		pw.printf("%010d 00000 n \n", offset)
+	}
+	pw.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(pw.offsets)+1, catalogObj, infoID, xref)
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// APIUsageRepository handles database operations for API usage counters
+type APIUsageRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIUsageRepository creates a new APIUsageRepository
+func NewAPIUsageRepository(db *gorm.DB) *APIUsageRepository {
+	return &APIUsageRepository{db: db}
+}
+
+// DailyAPIUsageTotal represents the API requests of all users during one day
+type DailyAPIUsageTotal struct {
+	Date     string `json:"date"`
+	Requests int64  `json:"requests"`
+	// IntegrationRequests is the part of Requests made through integrations
+	IntegrationRequests int64 `json:"integration_requests"`
+	Users               int64 `json:"users"`
+}
+
+// UserAPIUsageTotal represents the API requests of one user over a period
+type UserAPIUsageTotal struct {
+	UserID   int64  `json:"user_id"`
+	Email    string `json:"email"`
+	Requests int64  `json:"requests"`
+}
+
+// IntegrationAPIUsageTotal represents the API requests made through one integration over a period
+type IntegrationAPIUsageTotal struct {
+	IntegrationID int64 `json:"integration_id"`
+	// Name is empty once the integration is deleted
+	Name     string `json:"name"`
+	UserID   int64  `json:"user_id"`
+	Requests int64  `json:"requests"`
+}
+
+// Increment counts a request of the user on a day, made through the integration if not nil, and returns
+// the user's request count for that day across their integrations. The user's row is locked while counting,
+// as a null integration_id can't be matched by a unique index to upsert on.
+func (r *APIUsageRepository) Increment(ctx context.Context, userID int64, integrationID *int64, date string) (int64, error) {
+	var count int64
+	err := database.ForUser(ctx, r.db, userID).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Model(&model.User{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userID).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		query := tx.Model(&model.APIUsage{}).Where("user_id = ? AND date = ?", userID, date)
+		if integrationID != nil {
+			query = query.Where("integration_id = ?", *integrationID)
+		} else {
+			query = query.Where("integration_id IS NULL")
+		}
+		result := query.UpdateColumns(map[string]interface{}{
+			"request_count": gorm.Expr("request_count + 1"),
+			"updated_at":    time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			usage := &model.APIUsage{UserID: userID, Date: date, IntegrationID: integrationID, RequestCount: 1}
+			if err := tx.Create(usage).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&model.APIUsage{}).
+			Where("user_id = ? AND date = ?", userID, date).
+			Select("COALESCE(SUM(request_count), 0)").
+			Scan(&count).Error
+	})
+	return count, err
+}
+
+// FindByUserID retrieves the user's usage between two days (inclusive), oldest first,
+// with one record per day and integration
+func (r *APIUsageRepository) FindByUserID(ctx context.Context, userID int64, from, to string) ([]model.APIUsage, error) {
+	var usages []model.APIUsage
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND date >= ? AND date <= ?", userID, from, to).
+		Order("date ASC, id ASC").
+		Find(&usages)
+	return usages, result.Error
+}
+
+// DailyTotals aggregates the usage of all users per day between two days (inclusive), oldest first
+func (r *APIUsageRepository) DailyTotals(ctx context.Context, from, to string) ([]DailyAPIUsageTotal, error) {
+	var totals []DailyAPIUsageTotal
+	result := database.AsSystem(ctx, r.db).Model(&model.APIUsage{}).
+		Select(`date, COALESCE(SUM(request_count), 0) AS requests,
+			COALESCE(SUM(CASE WHEN integration_id IS NOT NULL THEN request_count ELSE 0 END), 0) AS integration_requests,
+			COUNT(DISTINCT user_id) AS users`).
+		Where("date >= ? AND date <= ?", from, to).
+		Group("date").
+		Order("date ASC").
+		Scan(&totals)
+	return totals, result.Error
+}
+
+// TopUsers aggregates the usage per user between two days (inclusive), heaviest users first
+func (r *APIUsageRepository) TopUsers(ctx context.Context, from, to string, limit int) ([]UserAPIUsageTotal, error) {
+	var totals []UserAPIUsageTotal
+	result := database.AsSystem(ctx, r.db).Model(&model.APIUsage{}).
+		Select("api_usages.user_id, users.email, COALESCE(SUM(api_usages.request_count), 0) AS requests").
+		Joins("JOIN users ON users.id = api_usages.user_id").
+		Where("api_usages.date >= ? AND api_usages.date <= ?", from, to).
+		Group("api_usages.user_id, users.email").
+		Order("requests DESC, api_usages.user_id ASC").
+		Limit(limit).
+		Scan(&totals)
+	return totals, result.Error
+}
+
+// TopIntegrations aggregates the usage per integration between two days (inclusive), heaviest first
+func (r *APIUsageRepository) TopIntegrations(ctx context.Context, from, to string, limit int) ([]IntegrationAPIUsageTotal, error) {
+	var totals []IntegrationAPIUsageTotal
+	result := database.AsSystem(ctx, r.db).Model(&model.APIUsage{}).
+		Select("api_usages.integration_id, COALESCE(integrations.name, '') AS name, api_usages.user_id, COALESCE(SUM(api_usages.request_count), 0) AS requests").
+		Joins("LEFT JOIN integrations ON integrations.id = api_usages.integration_id").
+		Where("api_usages.integration_id IS NOT NULL AND api_usages.date >= ? AND api_usages.date <= ?", from, to).
+		Group("api_usages.integration_id, integrations.name, api_usages.user_id").
+		Order("requests DESC, api_usages.integration_id ASC").
+		Limit(limit).
+		Scan(&totals)
+	return totals, result.Error
+}
+
+// DropUserDateIndex drops the unique index on user and day of earlier versions, which would keep a user's
+// integrations from being counted separately. It is safe to run repeatedly.
+func (r *APIUsageRepository) DropUserDateIndex(ctx context.Context) error {
+	migrator := r.db.WithContext(ctx).Migrator()
+	if !migrator.HasIndex(&model.APIUsage{}, "idx_api_usage_user_date") {
+		return nil
+	}
+	return migrator.DropIndex(&model.APIUsage{}, "idx_api_usage_user_date")
+}
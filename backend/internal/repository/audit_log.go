@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository handles database operations for the audit log.
+// Entries are only ever appended; only pending entries are deleted, once they are linked into the chain.
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new AuditLogRepository
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// AuditLogSearchInput represents filter and pagination parameters for searching the audit log
+type AuditLogSearchInput struct {
+	ActorID      *int64
+	ResourceType string
+	ResourceID   string
+	Action       string
+	From         *time.Time
+	To           *time.Time
+	Page         int
+	PerPage      int
+}
+
+// Transaction runs fn with a repository in a transaction, or in a savepoint when already in one
+func (r *AuditLogRepository) Transaction(ctx context.Context, fn func(txRepo *AuditLogRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&AuditLogRepository{db: tx})
+	})
+}
+
+// IsConflict reports whether err is a unique violation, i.e. another entry already links to the same previous hash
+func (r *AuditLogRepository) IsConflict(err error) bool {
+	return database.IsUniqueViolation(r.db, err)
+}
+
+// FindLast retrieves the most recent entry
+func (r *AuditLogRepository) FindLast(ctx context.Context) (*model.AuditLog, error) {
+	var entry model.AuditLog
+	result := r.db.WithContext(ctx).Order("id DESC").First(&entry)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &entry, nil
+}
+
+// Create appends an entry
+func (r *AuditLogRepository) Create(ctx context.Context, entry *model.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// CreatePending records an entry to be linked into the chain later
+func (r *AuditLogRepository) CreatePending(ctx context.Context, pending *model.PendingAuditLog) error {
+	return r.db.WithContext(ctx).Create(pending).Error
+}
+
+// FindPending retrieves up to limit pending entries, oldest first
+func (r *AuditLogRepository) FindPending(ctx context.Context, limit int) ([]model.PendingAuditLog, error) {
+	var pending []model.PendingAuditLog
+	result := r.db.WithContext(ctx).Order("id ASC").Limit(limit).Find(&pending)
+	return pending, result.Error
+}
+
+// DeletePending deletes a pending entry and reports whether it was still there.
+// In a transaction the row stays locked until commit, so concurrent deletes of the same entry wait and find it gone.
+func (r *AuditLogRepository) DeletePending(ctx context.Context, id int64) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&model.PendingAuditLog{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Search retrieves entries matching the filters, newest first, with the total count
+func (r *AuditLogRepository) Search(ctx context.Context, input AuditLogSearchInput) ([]model.AuditLog, int64, error) {
+	var entries []model.AuditLog
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.AuditLog{})
+	if input.ActorID != nil {
+		query = query.Where("actor_id = ?", *input.ActorID)
+	}
+	if input.ResourceType != "" {
+		query = query.Where("resource_type = ?", input.ResourceType)
+	}
+	if input.ResourceID != "" {
+		query = query.Where("resource_id = ?", input.ResourceID)
+	}
+	if input.Action != "" {
+		query = query.Where("action = ?", input.Action)
+	}
+	if input.From != nil {
+		query = query.Where("created_at >= ?", *input.From)
+	}
+	if input.To != nil {
+		query = query.Where("created_at < ?", *input.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (input.Page - 1) * input.PerPage
+	result := query.Order("id DESC").Offset(offset).Limit(input.PerPage).Find(&entries)
+	return entries, total, result.Error
+}
+
+// FindAfter retrieves up to limit entries with an ID greater than afterID, oldest first
+func (r *AuditLogRepository) FindAfter(ctx context.Context, afterID int64, limit int) ([]model.AuditLog, error) {
+	var entries []model.AuditLog
+	result := r.db.WithContext(ctx).Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&entries)
+	return entries, result.Error
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// CalendarRepository handles database operations for calendar connections and their synced events
+type CalendarRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarRepository creates a new CalendarRepository
+func NewCalendarRepository(db *gorm.DB) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+// FindConnectionByUserID retrieves a user's calendar connection
+func (r *CalendarRepository) FindConnectionByUserID(ctx context.Context, userID int64) (*model.CalendarConnection, error) {
+	var connection model.CalendarConnection
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		First(&connection)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &connection, nil
+}
+
+// FindConnectionByStateHash retrieves the connection waiting for the OAuth callback with the state
+func (r *CalendarRepository) FindConnectionByStateHash(ctx context.Context, stateHash string) (*model.CalendarConnection, error) {
+	var connection model.CalendarConnection
+	result := database.AsSystem(ctx, r.db).
+		Where("state_hash = ?", stateHash).
+		First(&connection)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &connection, nil
+}
+
+// FindConnected retrieves every connection that finished connecting, oldest first
+func (r *CalendarRepository) FindConnected(ctx context.Context) ([]model.CalendarConnection, error) {
+	var connections []model.CalendarConnection
+	result := database.AsSystem(ctx, r.db).
+		Where("connected_at IS NOT NULL AND calendar_id <> ''").
+		Order("id ASC").
+		Find(&connections)
+	return connections, result.Error
+}
+
+// SaveConnection creates or updates a connection
+func (r *CalendarRepository) SaveConnection(ctx context.Context, connection *model.CalendarConnection) error {
+	return database.ForUser(ctx, r.db, connection.UserID).Save(connection).Error
+}
+
+// DeleteConnection deletes a user's connection along with its events
+func (r *CalendarRepository) DeleteConnection(ctx context.Context, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.CalendarEvent{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("user_id = ?", userID).Delete(&model.CalendarConnection{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// FindEvent retrieves the event synced for a todo
+func (r *CalendarRepository) FindEvent(ctx context.Context, connection *model.CalendarConnection, todoUUID string) (*model.CalendarEvent, error) {
+	var event model.CalendarEvent
+	result := database.ForUser(ctx, r.db, connection.UserID).
+		Where("connection_id = ? AND todo_uuid = ?", connection.ID, todoUUID).
+		First(&event)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &event, nil
+}
+
+// SaveEvent creates or updates a synced event
+func (r *CalendarRepository) SaveEvent(ctx context.Context, event *model.CalendarEvent) error {
+	return database.ForUser(ctx, r.db, event.UserID).Save(event).Error
+}
+
+// DeleteEvent deletes a synced event
+func (r *CalendarRepository) DeleteEvent(ctx context.Context, event *model.CalendarEvent) error {
+	return database.ForUser(ctx, r.db, event.UserID).Delete(event).Error
+}
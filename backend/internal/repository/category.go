@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"context"
 	"strings"
 
 	"gorm.io/gorm"
 
 	"todo-api/internal/model"
+	"todo-api/pkg/database"
 )
 
 // CategoryRepository handles database operations for categories
@@ -19,9 +21,9 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
 }
 
 // FindAllByUserID retrieves all categories for a user ordered by name
-func (r *CategoryRepository) FindAllByUserID(userID int64) ([]model.Category, error) {
+func (r *CategoryRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.Category, error) {
 	var categories []model.Category
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("user_id = ?", userID).
 		Order("name ASC").
 		Find(&categories)
@@ -29,9 +31,9 @@ func (r *CategoryRepository) FindAllByUserID(userID int64) ([]model.Category, er
 }
 
 // FindByID retrieves a category by ID for a specific user
-func (r *CategoryRepository) FindByID(id, userID int64) (*model.Category, error) {
+func (r *CategoryRepository) FindByID(ctx context.Context, id, userID int64) (*model.Category, error) {
 	var category model.Category
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("id = ? AND user_id = ?", id, userID).
 		First(&category)
 	if result.Error != nil {
@@ -41,9 +43,9 @@ func (r *CategoryRepository) FindByID(id, userID int64) (*model.Category, error)
 }
 
 // ExistsByName checks if a category with the given name exists for a user (case-insensitive)
-func (r *CategoryRepository) ExistsByName(name string, userID int64, excludeID *int64) (bool, error) {
+func (r *CategoryRepository) ExistsByName(ctx context.Context, name string, userID int64, excludeID *int64) (bool, error) {
 	var count int64
-	query := r.db.Model(&model.Category{}).
+	query := database.ForUser(ctx, r.db, userID).Model(&model.Category{}).
 		Where("LOWER(name) = LOWER(?) AND user_id = ?", strings.TrimSpace(name), userID)
 	if excludeID != nil {
 		query = query.Where("id != ?", *excludeID)
@@ -53,28 +55,39 @@ func (r *CategoryRepository) ExistsByName(name string, userID int64, excludeID *
 }
 
 // Create creates a new category
-func (r *CategoryRepository) Create(category *model.Category) error {
-	return r.db.Create(category).Error
+func (r *CategoryRepository) Create(ctx context.Context, category *model.Category) error {
+	return database.ForUser(ctx, r.db, category.UserID).Create(category).Error
 }
 
 // Update updates an existing category
-func (r *CategoryRepository) Update(category *model.Category) error {
-	return r.db.Save(category).Error
+func (r *CategoryRepository) Update(ctx context.Context, category *model.Category) error {
+	return database.ForUser(ctx, r.db, category.UserID).Save(category).Error
 }
 
 // Delete deletes a category and nullifies related todos' category_id
-func (r *CategoryRepository) Delete(id, userID int64) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+func (r *CategoryRepository) Delete(ctx context.Context, id, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).Transaction(func(tx *gorm.DB) error {
 		// First, verify category exists and belongs to user
 		var category model.Category
 		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&category).Error; err != nil {
 			return err
 		}
 
-		// Nullify category_id in related todos
+		// Nullify category_id in related todos, appending them after the uncategorized todos.
+		// The last position is read first, as MySQL cannot update a table it selects from.
+		var lastPosition int
+		if err := tx.Model(&model.Todo{}).
+			Where("user_id = ? AND category_id IS NULL", userID).
+			Select("COALESCE(MAX(category_position), 0)").
+			Scan(&lastPosition).Error; err != nil {
+			return err
+		}
 		if err := tx.Model(&model.Todo{}).
 			Where("category_id = ? AND user_id = ?", id, userID).
-			Update("category_id", nil).Error; err != nil {
+			Updates(map[string]any{
+				"category_id":       nil,
+				"category_position": gorm.Expr("COALESCE(category_position, 0) + ?", lastPosition),
+			}).Error; err != nil {
 			return err
 		}
 
@@ -84,22 +97,22 @@ func (r *CategoryRepository) Delete(id, userID int64) error {
 }
 
 // IncrementTodosCount increments the todos_count for a category
-func (r *CategoryRepository) IncrementTodosCount(categoryID int64) error {
-	return r.db.Model(&model.Category{}).
+func (r *CategoryRepository) IncrementTodosCount(ctx context.Context, categoryID, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).Model(&model.Category{}).
 		Where("id = ?", categoryID).
 		UpdateColumn("todos_count", gorm.Expr("todos_count + ?", 1)).Error
 }
 
 // DecrementTodosCount decrements the todos_count for a category (minimum 0)
-func (r *CategoryRepository) DecrementTodosCount(categoryID int64) error {
-	return r.db.Model(&model.Category{}).
+func (r *CategoryRepository) DecrementTodosCount(ctx context.Context, categoryID, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).Model(&model.Category{}).
 		Where("id = ?", categoryID).
-		UpdateColumn("todos_count", gorm.Expr("GREATEST(todos_count - ?, 0)", 1)).Error
+		UpdateColumn("todos_count", gorm.Expr("CASE WHEN todos_count > 0 THEN todos_count - ? ELSE 0 END", 1)).Error
 }
 
 // RecalculateTodosCount recalculates the todos_count from actual todos
-func (r *CategoryRepository) RecalculateTodosCount(categoryID int64) error {
-	return r.db.Exec(`
+func (r *CategoryRepository) RecalculateTodosCount(ctx context.Context, categoryID, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).Exec(`
 		UPDATE categories
 		SET todos_count = (
 			SELECT COUNT(*) FROM todos WHERE category_id = ?
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"context"
+
 	"todo-api/internal/model"
+	"todo-api/pkg/database"
 
 	"gorm.io/gorm"
 )
@@ -18,9 +21,9 @@ func NewCommentRepository(db *gorm.DB) *CommentRepository {
 
 // FindAllByCommentable retrieves all comments for a specific resource
 // Excludes soft-deleted comments, ordered by created_at ASC
-func (r *CommentRepository) FindAllByCommentable(commentableType string, commentableID int64) ([]model.Comment, error) {
+func (r *CommentRepository) FindAllByCommentable(ctx context.Context, commentableType string, commentableID, userID int64) ([]model.Comment, error) {
 	var comments []model.Comment
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Preload("User").
 		Where("commentable_type = ? AND commentable_id = ?", commentableType, commentableID).
 		Order("created_at ASC").
@@ -28,10 +31,20 @@ func (r *CommentRepository) FindAllByCommentable(commentableType string, comment
 	return comments, result.Error
 }
 
+// FindAllByUserID retrieves all comments written by a user, oldest first
+func (r *CommentRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.Comment, error) {
+	var comments []model.Comment
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&comments)
+	return comments, result.Error
+}
+
 // FindByID retrieves a comment by ID (includes soft-deleted for ownership check)
-func (r *CommentRepository) FindByID(id int64) (*model.Comment, error) {
+func (r *CommentRepository) FindByID(ctx context.Context, id, userID int64) (*model.Comment, error) {
 	var comment model.Comment
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Unscoped(). // Include soft-deleted
 		Preload("User").
 		Where("id = ?", id).
@@ -43,9 +56,9 @@ func (r *CommentRepository) FindByID(id int64) (*model.Comment, error) {
 }
 
 // FindByIDWithoutDeleted retrieves a comment by ID (excludes soft-deleted)
-func (r *CommentRepository) FindByIDWithoutDeleted(id int64) (*model.Comment, error) {
+func (r *CommentRepository) FindByIDWithoutDeleted(ctx context.Context, id, userID int64) (*model.Comment, error) {
 	var comment model.Comment
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Preload("User").
 		Where("id = ?", id).
 		First(&comment)
@@ -56,24 +69,24 @@ func (r *CommentRepository) FindByIDWithoutDeleted(id int64) (*model.Comment, er
 }
 
 // Create creates a new comment
-func (r *CommentRepository) Create(comment *model.Comment) error {
-	return r.db.Create(comment).Error
+func (r *CommentRepository) Create(ctx context.Context, comment *model.Comment) error {
+	return database.ForUser(ctx, r.db, comment.UserID).Create(comment).Error
 }
 
 // Update updates an existing comment
-func (r *CommentRepository) Update(comment *model.Comment) error {
-	return r.db.Save(comment).Error
+func (r *CommentRepository) Update(ctx context.Context, comment *model.Comment) error {
+	return database.ForUser(ctx, r.db, comment.UserID).Save(comment).Error
 }
 
 // SoftDelete soft deletes a comment by setting deleted_at
-func (r *CommentRepository) SoftDelete(id int64) error {
-	return r.db.Delete(&model.Comment{}, id).Error
+func (r *CommentRepository) SoftDelete(ctx context.Context, id, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).Delete(&model.Comment{}, id).Error
 }
 
 // ExistsByID checks if a comment exists (excludes soft-deleted)
-func (r *CommentRepository) ExistsByID(id int64) (bool, error) {
+func (r *CommentRepository) ExistsByID(ctx context.Context, id, userID int64) (bool, error) {
 	var count int64
-	result := r.db.Model(&model.Comment{}).
+	result := database.ForUser(ctx, r.db, userID).Model(&model.Comment{}).
 		Where("id = ?", id).
 		Count(&count)
 	return count > 0, result.Error
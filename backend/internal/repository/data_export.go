@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DataExportRepository handles database operations for account data exports
+type DataExportRepository struct {
+	db *gorm.DB
+}
+
+// NewDataExportRepository creates a new DataExportRepository
+func NewDataExportRepository(db *gorm.DB) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+// Create creates a new export request
+func (r *DataExportRepository) Create(ctx context.Context, export *model.DataExport) error {
+	return database.ForUser(ctx, r.db, export.UserID).Create(export).Error
+}
+
+// Update updates an existing export
+func (r *DataExportRepository) Update(ctx context.Context, export *model.DataExport) error {
+	return database.ForUser(ctx, r.db, export.UserID).Save(export).Error
+}
+
+// ListByUserID retrieves a user's most recent exports, newest first
+func (r *DataExportRepository) ListByUserID(ctx context.Context, userID int64, limit int) ([]model.DataExport, error) {
+	var exports []model.DataExport
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&exports)
+	return exports, result.Error
+}
+
+// FindInProgressByUserID retrieves the user's pending or processing export, if any
+func (r *DataExportRepository) FindInProgressByUserID(ctx context.Context, userID int64) (*model.DataExport, error) {
+	var export model.DataExport
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND status IN ?", userID, []model.DataExportStatus{
+			model.DataExportStatusPending,
+			model.DataExportStatusProcessing,
+		}).
+		First(&export)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &export, nil
+}
+
+// FindByTokenHash retrieves an export by the hash of its download token
+func (r *DataExportRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*model.DataExport, error) {
+	var export model.DataExport
+	result := database.AsSystem(ctx, r.db).
+		Where("token_hash = ?", tokenHash).
+		First(&export)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &export, nil
+}
+
+// ClaimPending atomically marks up to limit pending exports as processing and returns them.
+// Exports stuck in processing since before staleBefore (e.g. after a crash) are claimed again.
+func (r *DataExportRepository) ClaimPending(ctx context.Context, limit int, staleBefore time.Time) ([]model.DataExport, error) {
+	db := database.AsSystem(ctx, r.db)
+	if database.DialectOf(db) != database.Postgres {
+		return r.claimPendingInTransaction(ctx, limit, staleBefore)
+	}
+
+	var exports []model.DataExport
+	result := db.Raw(`
+		UPDATE data_exports SET status = ?, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM data_exports
+			WHERE status = ? OR (status = ? AND updated_at < ?)
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		model.DataExportStatusProcessing,
+		model.DataExportStatusPending,
+		model.DataExportStatusProcessing, staleBefore,
+		limit,
+	).Scan(&exports)
+	return exports, result.Error
+}
+
+// claimPendingInTransaction claims like ClaimPending for databases without UPDATE ... RETURNING.
+// SQLite ignores the row lock, but it only allows one writer at a time anyway.
+func (r *DataExportRepository) claimPendingInTransaction(ctx context.Context, limit int, staleBefore time.Time) ([]model.DataExport, error) {
+	var exports []model.DataExport
+	err := database.AsSystem(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Model(&model.DataExport{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND updated_at < ?)",
+				model.DataExportStatusPending, model.DataExportStatusProcessing, staleBefore).
+			Order("id").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&model.DataExport{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{
+				"status":     model.DataExportStatusProcessing,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Order("id").Find(&exports).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+// FindExpired retrieves completed exports whose download link has expired
+func (r *DataExportRepository) FindExpired(ctx context.Context, now time.Time) ([]model.DataExport, error) {
+	var exports []model.DataExport
+	result := database.AsSystem(ctx, r.db).
+		Where("status = ? AND expires_at < ?", model.DataExportStatusCompleted, now).
+		Find(&exports)
+	return exports, result.Error
+}
+
+// PendingBacklog counts the pending exports and returns when the oldest one was requested (nil without any)
+func (r *DataExportRepository) PendingBacklog(ctx context.Context) (int64, *time.Time, error) {
+	pending := database.AsSystem(ctx, r.db).Model(&model.DataExport{}).Where("status = ?", model.DataExportStatusPending)
+
+	var count int64
+	if err := pending.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		return 0, nil, err
+	}
+	if count == 0 {
+		return 0, nil, nil
+	}
+
+	var oldest model.DataExport
+	if err := pending.Session(&gorm.Session{}).Order("created_at ASC").First(&oldest).Error; err != nil {
+		return 0, nil, err
+	}
+	return count, &oldest.CreatedAt, nil
+}
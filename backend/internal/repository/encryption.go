@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// EncryptedColumn is a column stored with the encrypted serializer
+type EncryptedColumn struct {
+	Table  string
+	Column string
+}
+
+// EncryptedColumns lists every column encrypted at the application layer
+var EncryptedColumns = []EncryptedColumn{
+	{Table: "todos", Column: "description"},
+	{Table: "comments", Column: "content"},
+	{Table: "integrations", Column: "secret"},
+	{Table: "calendar_connections", Column: "access_token"},
+	{Table: "calendar_connections", Column: "refresh_token"},
+}
+
+// StoredValue is the value of an encrypted column as stored, without decryption
+type StoredValue struct {
+	ID    int64
+	Value string
+}
+
+// EncryptionRepository reads and writes encrypted columns as stored, bypassing the serializer
+type EncryptionRepository struct {
+	db *gorm.DB
+}
+
+// NewEncryptionRepository creates a new EncryptionRepository
+func NewEncryptionRepository(db *gorm.DB) *EncryptionRepository {
+	return &EncryptionRepository{db: db}
+}
+
+// FindNotEncryptedWith retrieves up to limit values after afterID (in ID order) that are not
+// encrypted with the key of activePrefix, i.e. plaintext or encrypted with an older key.
+// Soft-deleted rows are included.
+func (r *EncryptionRepository) FindNotEncryptedWith(ctx context.Context, column EncryptedColumn, activePrefix string, afterID int64, limit int) ([]StoredValue, error) {
+	var values []StoredValue
+	result := database.AsSystem(ctx, r.db).Table(column.Table).
+		Select("id, "+column.Column+" AS value").
+		Where("id > ? AND "+column.Column+" IS NOT NULL AND "+column.Column+" NOT LIKE ?", afterID, activePrefix+"%").
+		Order("id ASC").
+		Limit(limit).
+		Scan(&values)
+	return values, result.Error
+}
+
+// ReplaceValue replaces a stored value unless it has changed since it was read.
+// updated_at is not touched, as the content itself did not change.
+func (r *EncryptionRepository) ReplaceValue(ctx context.Context, column EncryptedColumn, id int64, oldValue, newValue string) (bool, error) {
+	result := database.AsSystem(ctx, r.db).Table(column.Table).
+		Where("id = ? AND "+column.Column+" = ?", id, oldValue).
+		UpdateColumn(column.Column, newValue)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// EscalationRuleRepository handles database operations for priority escalation rules
+type EscalationRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewEscalationRuleRepository creates a new EscalationRuleRepository
+func NewEscalationRuleRepository(db *gorm.DB) *EscalationRuleRepository {
+	return &EscalationRuleRepository{db: db}
+}
+
+// FindAllByUserID retrieves all rules of a user, closest to the due date first
+func (r *EscalationRuleRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.EscalationRule, error) {
+	var rules []model.EscalationRule
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("within_hours ASC, id ASC").
+		Find(&rules)
+	return rules, result.Error
+}
+
+// FindByID retrieves a rule by ID for a specific user
+func (r *EscalationRuleRepository) FindByID(ctx context.Context, id, userID int64) (*model.EscalationRule, error) {
+	var rule model.EscalationRule
+	result := database.ForUser(ctx, r.db, userID).Where("id = ? AND user_id = ?", id, userID).First(&rule)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &rule, nil
+}
+
+// FindEnabled retrieves every enabled rule with its user, grouped by user
+func (r *EscalationRuleRepository) FindEnabled(ctx context.Context) ([]model.EscalationRule, error) {
+	var rules []model.EscalationRule
+	result := database.AsSystem(ctx, r.db).
+		Preload("User").
+		Where("enabled = ?", true).
+		Order("user_id ASC, within_hours ASC, id ASC").
+		Find(&rules)
+	return rules, result.Error
+}
+
+// Create creates a new rule
+func (r *EscalationRuleRepository) Create(ctx context.Context, rule *model.EscalationRule) error {
+	return database.ForUser(ctx, r.db, rule.UserID).Create(rule).Error
+}
+
+// Update updates an existing rule
+func (r *EscalationRuleRepository) Update(ctx context.Context, rule *model.EscalationRule) error {
+	return database.ForUser(ctx, r.db, rule.UserID).Save(rule).Error
+}
+
+// Delete deletes a rule by ID for a specific user
+func (r *EscalationRuleRepository) Delete(ctx context.Context, id, userID int64) error {
+	result := database.ForUser(ctx, r.db, userID).Where("id = ? AND user_id = ?", id, userID).Delete(&model.EscalationRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -1,7 +1,11 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"todo-api/internal/model"
+	"todo-api/pkg/database"
 
 	"gorm.io/gorm"
 )
@@ -17,9 +21,9 @@ func NewFileRepository(db *gorm.DB) *FileRepository {
 }
 
 // FindByID retrieves a file by ID
-func (r *FileRepository) FindByID(id int64) (*model.File, error) {
+func (r *FileRepository) FindByID(ctx context.Context, id, userID int64) (*model.File, error) {
 	var file model.File
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Preload("User").
 		Where("id = ?", id).
 		First(&file)
@@ -29,10 +33,20 @@ func (r *FileRepository) FindByID(id int64) (*model.File, error) {
 	return &file, nil
 }
 
+// FindAllByUserID retrieves all files uploaded by a user, oldest first
+func (r *FileRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.File, error) {
+	var files []model.File
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&files)
+	return files, result.Error
+}
+
 // FindByAttachable retrieves all files for a specific resource
-func (r *FileRepository) FindByAttachable(attachableType string, attachableID int64) ([]model.File, error) {
+func (r *FileRepository) FindByAttachable(ctx context.Context, attachableType string, attachableID, userID int64) ([]model.File, error) {
 	var files []model.File
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("attachable_type = ? AND attachable_id = ?", attachableType, attachableID).
 		Order("created_at DESC").
 		Find(&files)
@@ -40,9 +54,9 @@ func (r *FileRepository) FindByAttachable(attachableType string, attachableID in
 }
 
 // FindByAttachableWithUser retrieves all files for a specific resource with user info
-func (r *FileRepository) FindByAttachableWithUser(attachableType string, attachableID int64) ([]model.File, error) {
+func (r *FileRepository) FindByAttachableWithUser(ctx context.Context, attachableType string, attachableID, userID int64) ([]model.File, error) {
 	var files []model.File
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Preload("User").
 		Where("attachable_type = ? AND attachable_id = ?", attachableType, attachableID).
 		Order("created_at DESC").
@@ -51,26 +65,48 @@ func (r *FileRepository) FindByAttachableWithUser(attachableType string, attacha
 }
 
 // Create creates a new file record
-func (r *FileRepository) Create(file *model.File) error {
-	return r.db.Create(file).Error
+func (r *FileRepository) Create(ctx context.Context, file *model.File) error {
+	return database.ForUser(ctx, r.db, file.UserID).Create(file).Error
+}
+
+// UpdateScanResult saves the antivirus scan fields of a file, including quarantine changes,
+// and returns false if the file no longer exists
+func (r *FileRepository) UpdateScanResult(ctx context.Context, file *model.File) (bool, error) {
+	result := database.ForUser(ctx, r.db, file.UserID).Model(file).
+		Select("scan_status", "scan_signature", "scanned_at", "quarantined_at", "storage_path", "thumb_path", "medium_path", "updated_at").
+		Updates(file)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindScanRetryable retrieves files still pending, whose scan failed, or that are infected but not quarantined,
+// last updated before the given time
+func (r *FileRepository) FindScanRetryable(ctx context.Context, before time.Time, limit int) ([]model.File, error) {
+	var files []model.File
+	result := database.AsSystem(ctx, r.db).
+		Where("(scan_status IN ? OR (scan_status = ? AND quarantined_at IS NULL)) AND updated_at < ?",
+			[]model.ScanStatus{model.ScanStatusPending, model.ScanStatusError}, model.ScanStatusInfected, before).
+		Order("id ASC").
+		Limit(limit).
+		Find(&files)
+	return files, result.Error
 }
 
 // Delete deletes a file record by ID
-func (r *FileRepository) Delete(id int64) error {
-	return r.db.Delete(&model.File{}, id).Error
+func (r *FileRepository) Delete(ctx context.Context, id, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).Delete(&model.File{}, id).Error
 }
 
 // DeleteByAttachable deletes all files for a specific resource
-func (r *FileRepository) DeleteByAttachable(attachableType string, attachableID int64) error {
-	return r.db.
+func (r *FileRepository) DeleteByAttachable(ctx context.Context, attachableType string, attachableID, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).
 		Where("attachable_type = ? AND attachable_id = ?", attachableType, attachableID).
 		Delete(&model.File{}).Error
 }
 
 // ExistsByID checks if a file exists by ID
-func (r *FileRepository) ExistsByID(id int64) (bool, error) {
+func (r *FileRepository) ExistsByID(ctx context.Context, id, userID int64) (bool, error) {
 	var count int64
-	result := r.db.Model(&model.File{}).
+	result := database.ForUser(ctx, r.db, userID).Model(&model.File{}).
 		Where("id = ?", id).
 		Count(&count)
 	return count > 0, result.Error
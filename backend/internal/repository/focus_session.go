@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FocusSessionRepository handles database operations for focus sessions
+type FocusSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewFocusSessionRepository creates a new FocusSessionRepository
+func NewFocusSessionRepository(db *gorm.DB) *FocusSessionRepository {
+	return &FocusSessionRepository{db: db}
+}
+
+// FocusSessionListInput represents filter and pagination parameters for listing sessions
+type FocusSessionListInput struct {
+	UserID  int64
+	TodoID  *int64
+	From    *time.Time
+	To      *time.Time
+	Page    int
+	PerPage int
+}
+
+// DailyFocusTotal represents aggregated focus time for one local calendar day
+type DailyFocusTotal struct {
+	Date              string `json:"date"`
+	TotalSeconds      int64  `json:"total_seconds"`
+	Sessions          int64  `json:"sessions"`
+	CompletedSessions int64  `json:"completed_sessions"`
+}
+
+// FindAllByUserID retrieves all focus sessions of a user, oldest first
+func (r *FocusSessionRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.FocusSession, error) {
+	var sessions []model.FocusSession
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("started_at ASC, id ASC").
+		Find(&sessions)
+	return sessions, result.Error
+}
+
+// Create creates a new focus session
+func (r *FocusSessionRepository) Create(ctx context.Context, session *model.FocusSession) error {
+	return database.ForUser(ctx, r.db, session.UserID).Create(session).Error
+}
+
+// CreateUnlessActive creates a focus session unless the user already has a running one, which it returns
+// instead. The user's row is locked while checking, so concurrent starts can't both create a session.
+func (r *FocusSessionRepository) CreateUnlessActive(ctx context.Context, session *model.FocusSession) (*model.FocusSession, error) {
+	var active *model.FocusSession
+	err := database.ForUser(ctx, r.db, session.UserID).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Model(&model.User{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", session.UserID).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		var running model.FocusSession
+		err := tx.Where("user_id = ? AND ended_at IS NULL", session.UserID).
+			Order("started_at DESC").
+			First(&running).Error
+		if err == nil {
+			active = &running
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		return tx.Create(session).Error
+	})
+	return active, err
+}
+
+// Update updates an existing focus session
+func (r *FocusSessionRepository) Update(ctx context.Context, session *model.FocusSession) error {
+	return database.ForUser(ctx, r.db, session.UserID).Save(session).Error
+}
+
+// FindByID retrieves a focus session by ID for a specific user
+func (r *FocusSessionRepository) FindByID(ctx context.Context, id, userID int64) (*model.FocusSession, error) {
+	var session model.FocusSession
+	result := database.ForUser(ctx, r.db, userID).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+// FindActiveByUserID retrieves the user's running session, if any
+func (r *FocusSessionRepository) FindActiveByUserID(ctx context.Context, userID int64) (*model.FocusSession, error) {
+	var session model.FocusSession
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND ended_at IS NULL", userID).
+		Order("started_at DESC").
+		First(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+// List retrieves focus sessions with filters and pagination, newest first
+func (r *FocusSessionRepository) List(ctx context.Context, input FocusSessionListInput) ([]model.FocusSession, int64, error) {
+	var sessions []model.FocusSession
+	var total int64
+
+	query := database.ForUser(ctx, r.db, input.UserID).Model(&model.FocusSession{}).Where("user_id = ?", input.UserID)
+	if input.TodoID != nil {
+		query = query.Where("todo_id = ?", *input.TodoID)
+	}
+	if input.From != nil {
+		query = query.Where("started_at >= ?", *input.From)
+	}
+	if input.To != nil {
+		query = query.Where("started_at < ?", *input.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (input.Page - 1) * input.PerPage
+	result := query.
+		Order("started_at DESC").
+		Offset(offset).
+		Limit(input.PerPage).
+		Find(&sessions)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return sessions, total, nil
+}
+
+// DailyTotals aggregates finished sessions per local calendar day in the given time zone.
+// Only sessions started within [from, to) are included.
+func (r *FocusSessionRepository) DailyTotals(ctx context.Context, userID int64, from, to time.Time, timezone string) ([]DailyFocusTotal, error) {
+	if database.DialectOf(r.db) != database.Postgres {
+		return r.dailyTotalsInGo(ctx, userID, from, to, timezone)
+	}
+
+	var totals []DailyFocusTotal
+	result := database.ForUser(ctx, r.db, userID).Model(&model.FocusSession{}).
+		Select(`TO_CHAR(started_at AT TIME ZONE ?, 'YYYY-MM-DD') AS date,
+			COALESCE(SUM(duration_seconds), 0) AS total_seconds,
+			COUNT(*) AS sessions,
+			COALESCE(SUM(CASE WHEN completed THEN 1 ELSE 0 END), 0) AS completed_sessions`, timezone).
+		Where("user_id = ? AND ended_at IS NOT NULL AND started_at >= ? AND started_at < ?", userID, from, to).
+		Group("1").
+		Order("1 ASC").
+		Scan(&totals)
+	return totals, result.Error
+}
+
+// dailyTotalsInGo aggregates like DailyTotals for databases without time zone conversion in SQL
+func (r *FocusSessionRepository) dailyTotalsInGo(ctx context.Context, userID int64, from, to time.Time, timezone string) ([]DailyFocusTotal, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []model.FocusSession
+	if err := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND ended_at IS NOT NULL AND started_at >= ? AND started_at < ?", userID, from, to).
+		Order("started_at ASC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	var totals []DailyFocusTotal
+	for _, session := range sessions {
+		date := session.StartedAt.In(location).Format("2006-01-02")
+		// Sessions are ordered by start, so each day is contiguous
+		if len(totals) == 0 || totals[len(totals)-1].Date != date {
+			totals = append(totals, DailyFocusTotal{Date: date})
+		}
+		total := &totals[len(totals)-1]
+		total.TotalSeconds += int64(session.DurationSeconds)
+		total.Sessions++
+		if session.Completed {
+			total.CompletedSessions++
+		}
+	}
+	return totals, nil
+}
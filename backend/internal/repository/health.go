@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// HealthRepository checks the database for the health report
+type HealthRepository struct {
+	db *gorm.DB
+}
+
+// NewHealthRepository creates a new HealthRepository
+func NewHealthRepository(db *gorm.DB) *HealthRepository {
+	return &HealthRepository{db: db}
+}
+
+// Ping checks that the database accepts connections
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// MissingTables returns the tables of the models that do not exist
+func (r *HealthRepository) MissingTables(ctx context.Context, models []any) ([]string, error) {
+	db := r.db.WithContext(ctx)
+	missing := []string{}
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return nil, err
+		}
+		if !db.Migrator().HasTable(stmt.Table) {
+			missing = append(missing, stmt.Table)
+		}
+	}
+	return missing, nil
+}
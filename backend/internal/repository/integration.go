@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IntegrationRepository handles database operations for integrations and their used nonces
+type IntegrationRepository struct {
+	db *gorm.DB
+}
+
+// NewIntegrationRepository creates a new IntegrationRepository
+func NewIntegrationRepository(db *gorm.DB) *IntegrationRepository {
+	return &IntegrationRepository{db: db}
+}
+
+// FindAllByUserID retrieves all integrations of a user, oldest first
+func (r *IntegrationRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.Integration, error) {
+	var integrations []model.Integration
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&integrations)
+	return integrations, result.Error
+}
+
+// CountByUserID counts the integrations of a user
+func (r *IntegrationRepository) CountByUserID(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	result := database.ForUser(ctx, r.db, userID).
+		Model(&model.Integration{}).
+		Where("user_id = ?", userID).
+		Count(&count)
+	return count, result.Error
+}
+
+// FindByKeyID retrieves an integration by its public key ID
+func (r *IntegrationRepository) FindByKeyID(ctx context.Context, keyID string) (*model.Integration, error) {
+	var integration model.Integration
+	result := database.AsSystem(ctx, r.db).Where("key_id = ?", keyID).First(&integration)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &integration, nil
+}
+
+// Create creates a new integration
+func (r *IntegrationRepository) Create(ctx context.Context, integration *model.Integration) error {
+	return database.ForUser(ctx, r.db, integration.UserID).Create(integration).Error
+}
+
+// Delete deletes an integration by ID for a specific user
+func (r *IntegrationRepository) Delete(ctx context.Context, id, userID int64) error {
+	result := database.ForUser(ctx, r.db, userID).Where("id = ? AND user_id = ?", id, userID).Delete(&model.Integration{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records when an integration was last used.
+// updated_at is not touched, as the integration itself did not change.
+func (r *IntegrationRepository) TouchLastUsed(ctx context.Context, integration *model.Integration, now time.Time) error {
+	return database.ForUser(ctx, r.db, integration.UserID).
+		Model(&model.Integration{}).
+		Where("id = ?", integration.ID).
+		UpdateColumn("last_used_at", now).Error
+}
+
+// UseNonce records a nonce of an integration.
+// Returns false if the integration has already used it.
+func (r *IntegrationRepository) UseNonce(ctx context.Context, integrationID int64, nonce string, expiresAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&model.IntegrationNonce{
+		IntegrationID: integrationID,
+		Nonce:         nonce,
+		ExpiresAt:     expiresAt,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteExpiredNonces deletes nonces whose requests can no longer be replayed
+func (r *IntegrationRepository) DeleteExpiredNonces(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&model.IntegrationNonce{})
+	return result.RowsAffected, result.Error
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"todo-api/internal/model"
@@ -8,119 +9,166 @@ import (
 
 // UserRepositoryInterface defines the contract for user repository operations
 type UserRepositoryInterface interface {
-	FindByEmail(email string) (*model.User, error)
-	Create(user *model.User) error
-	FindByID(id int64) (*model.User, error)
-	ExistsByEmail(email string) (bool, error)
+	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	Create(ctx context.Context, user *model.User) error
+	FindByID(ctx context.Context, id int64) (*model.User, error)
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
 }
 
 // TodoRepositoryInterface defines the contract for todo repository operations
 type TodoRepositoryInterface interface {
-	FindAllByUserID(userID int64) ([]model.Todo, error)
-	FindAllByUserIDWithRelations(userID int64) ([]model.Todo, error)
-	FindByID(id, userID int64) (*model.Todo, error)
-	FindByIDWithRelations(id, userID int64) (*model.Todo, error)
-	Create(todo *model.Todo) error
-	Update(todo *model.Todo) error
-	Delete(id, userID int64) error
-	UpdateOrder(userID int64, updates []OrderUpdate) error
-	Count(userID int64) (int64, error)
-	ExistsByID(id, userID int64) (bool, error)
-	ValidateCategoryOwnership(categoryID, userID int64) (bool, error)
+	FindAllByUserID(ctx context.Context, userID int64) ([]model.Todo, error)
+	FindAllByUserIDWithRelations(ctx context.Context, userID int64) ([]model.Todo, error)
+	FindByID(ctx context.Context, id, userID int64) (*model.Todo, error)
+	FindByIDWithRelations(ctx context.Context, id, userID int64) (*model.Todo, error)
+	Create(ctx context.Context, todo *model.Todo) error
+	Update(ctx context.Context, todo *model.Todo) error
+	Delete(ctx context.Context, id, userID int64) error
+	UpdateOrder(ctx context.Context, userID int64, updates []OrderUpdate) error
+	Count(ctx context.Context, userID int64) (int64, error)
+	ExistsByID(ctx context.Context, id, userID int64) (bool, error)
+	ValidateCategoryOwnership(ctx context.Context, categoryID, userID int64) (bool, error)
 }
 
 // JwtDenylistRepositoryInterface defines the contract for JWT denylist operations
 type JwtDenylistRepositoryInterface interface {
-	Add(jti string, exp time.Time) error
-	Exists(jti string) (bool, error)
-	CleanupExpired() error
+	Add(ctx context.Context, jti string, exp time.Time) error
+	Exists(ctx context.Context, jti string) (bool, error)
+	CleanupExpired(ctx context.Context) error
 }
 
 // CategoryRepositoryInterface defines the contract for category repository operations
 type CategoryRepositoryInterface interface {
-	FindAllByUserID(userID int64) ([]model.Category, error)
-	FindByID(id, userID int64) (*model.Category, error)
-	ExistsByName(name string, userID int64, excludeID *int64) (bool, error)
-	Create(category *model.Category) error
-	Update(category *model.Category) error
-	Delete(id, userID int64) error
-	IncrementTodosCount(categoryID int64) error
-	DecrementTodosCount(categoryID int64) error
-	RecalculateTodosCount(categoryID int64) error
+	FindAllByUserID(ctx context.Context, userID int64) ([]model.Category, error)
+	FindByID(ctx context.Context, id, userID int64) (*model.Category, error)
+	ExistsByName(ctx context.Context, name string, userID int64, excludeID *int64) (bool, error)
+	Create(ctx context.Context, category *model.Category) error
+	Update(ctx context.Context, category *model.Category) error
+	Delete(ctx context.Context, id, userID int64) error
+	IncrementTodosCount(ctx context.Context, categoryID, userID int64) error
+	DecrementTodosCount(ctx context.Context, categoryID, userID int64) error
+	RecalculateTodosCount(ctx context.Context, categoryID, userID int64) error
 }
 
 // TagRepositoryInterface defines the contract for tag repository operations
 type TagRepositoryInterface interface {
-	FindAllByUserID(userID int64) ([]model.Tag, error)
-	FindByID(id, userID int64) (*model.Tag, error)
-	ExistsByName(name string, userID int64, excludeID *int64) (bool, error)
-	Create(tag *model.Tag) error
-	Update(tag *model.Tag) error
-	Delete(id, userID int64) error
-	FindByIDs(ids []int64, userID int64) ([]model.Tag, error)
-	ValidateTagOwnership(tagIDs []int64, userID int64) (bool, error)
+	FindAllByUserID(ctx context.Context, userID int64) ([]model.Tag, error)
+	FindByID(ctx context.Context, id, userID int64) (*model.Tag, error)
+	ExistsByName(ctx context.Context, name string, userID int64, excludeID *int64) (bool, error)
+	Create(ctx context.Context, tag *model.Tag) error
+	Update(ctx context.Context, tag *model.Tag) error
+	Delete(ctx context.Context, id, userID int64) error
+	FindByIDs(ctx context.Context, ids []int64, userID int64) ([]model.Tag, error)
+	ValidateTagOwnership(ctx context.Context, tagIDs []int64, userID int64) (bool, error)
 }
 
 // CommentRepositoryInterface defines the contract for comment repository operations
 type CommentRepositoryInterface interface {
-	FindAllByCommentable(commentableType string, commentableID int64) ([]model.Comment, error)
-	FindByID(id int64) (*model.Comment, error)
-	FindByIDWithoutDeleted(id int64) (*model.Comment, error)
-	Create(comment *model.Comment) error
-	Update(comment *model.Comment) error
-	SoftDelete(id int64) error
-	ExistsByID(id int64) (bool, error)
+	FindAllByCommentable(ctx context.Context, commentableType string, commentableID, userID int64) ([]model.Comment, error)
+	FindByID(ctx context.Context, id, userID int64) (*model.Comment, error)
+	FindByIDWithoutDeleted(ctx context.Context, id, userID int64) (*model.Comment, error)
+	Create(ctx context.Context, comment *model.Comment) error
+	Update(ctx context.Context, comment *model.Comment) error
+	SoftDelete(ctx context.Context, id, userID int64) error
+	ExistsByID(ctx context.Context, id, userID int64) (bool, error)
 }
 
 // TodoHistoryRepositoryInterface defines the contract for todo history repository operations
 type TodoHistoryRepositoryInterface interface {
-	Create(history *model.TodoHistory) error
-	FindByTodoID(todoID int64, page, perPage int) ([]model.TodoHistory, int64, error)
-	FindByTodoIDWithUser(todoID int64, page, perPage int) ([]model.TodoHistory, int64, error)
+	Create(ctx context.Context, history *model.TodoHistory) error
+	FindByTodoID(ctx context.Context, todoID, userID int64, page, perPage int) ([]model.TodoHistory, int64, error)
+	FindByTodoIDWithUser(ctx context.Context, todoID, userID int64, page, perPage int) ([]model.TodoHistory, int64, error)
 }
 
 // FileRepositoryInterface defines the contract for file repository operations
 type FileRepositoryInterface interface {
-	FindByID(id int64) (*model.File, error)
-	FindByAttachable(attachableType string, attachableID int64) ([]model.File, error)
-	FindByAttachableWithUser(attachableType string, attachableID int64) ([]model.File, error)
-	Create(file *model.File) error
-	Delete(id int64) error
-	DeleteByAttachable(attachableType string, attachableID int64) error
-	ExistsByID(id int64) (bool, error)
+	FindByID(ctx context.Context, id, userID int64) (*model.File, error)
+	FindByAttachable(ctx context.Context, attachableType string, attachableID, userID int64) ([]model.File, error)
+	FindByAttachableWithUser(ctx context.Context, attachableType string, attachableID, userID int64) ([]model.File, error)
+	Create(ctx context.Context, file *model.File) error
+	UpdateScanResult(ctx context.Context, file *model.File) (bool, error)
+	FindScanRetryable(ctx context.Context, before time.Time, limit int) ([]model.File, error)
+	Delete(ctx context.Context, id, userID int64) error
+	DeleteByAttachable(ctx context.Context, attachableType string, attachableID, userID int64) error
+	ExistsByID(ctx context.Context, id, userID int64) (bool, error)
 }
 
 // NoteRepositoryInterface defines the contract for note repository operations
 type NoteRepositoryInterface interface {
-	FindByID(id, userID int64) (*model.Note, error)
-	FindByIDIncludingTrashed(id, userID int64) (*model.Note, error)
-	Search(input NoteSearchInput) ([]model.Note, int64, error)
-	Create(note *model.Note) error
-	Update(note *model.Note) error
-	SoftDelete(id, userID int64) error
-	HardDelete(id, userID int64) error
-	ExistsByID(id, userID int64) (bool, error)
+	FindByID(ctx context.Context, id, userID int64) (*model.Note, error)
+	FindByIDIncludingTrashed(ctx context.Context, id, userID int64) (*model.Note, error)
+	Search(ctx context.Context, input NoteSearchInput) ([]model.Note, int64, error)
+	Create(ctx context.Context, note *model.Note) error
+	Update(ctx context.Context, note *model.Note) error
+	SoftDelete(ctx context.Context, id, userID int64) error
+	HardDelete(ctx context.Context, id, userID int64) error
+	ExistsByID(ctx context.Context, id, userID int64) (bool, error)
 }
 
 // NoteRevisionRepositoryInterface defines the contract for note revision repository operations
 type NoteRevisionRepositoryInterface interface {
-	Create(revision *model.NoteRevision) error
-	FindByNoteID(noteID int64, page, perPage int) ([]model.NoteRevision, int64, error)
-	FindByID(id, noteID int64) (*model.NoteRevision, error)
-	CountByNoteID(noteID int64) (int64, error)
-	DeleteOldestByNoteID(noteID int64, keepCount int) error
+	Create(ctx context.Context, revision *model.NoteRevision) error
+	FindByNoteID(ctx context.Context, noteID, userID int64, page, perPage int) ([]model.NoteRevision, int64, error)
+	FindByID(ctx context.Context, id, noteID, userID int64) (*model.NoteRevision, error)
+	CountByNoteID(ctx context.Context, noteID, userID int64) (int64, error)
+	DeleteOldestByNoteID(ctx context.Context, noteID, userID int64, keepCount int) error
+}
+
+// UserPreferenceRepositoryInterface defines the contract for user preference repository operations
+type UserPreferenceRepositoryInterface interface {
+	FindByUserID(ctx context.Context, userID int64) (*model.UserPreference, error)
+	FindOrDefault(ctx context.Context, userID int64) (*model.UserPreference, error)
+	Save(ctx context.Context, pref *model.UserPreference) error
+	FindDigestSubscribers(ctx context.Context) ([]model.UserPreference, error)
+	MarkDigestSent(ctx context.Context, id int64, sentAt time.Time) error
+	FindRetentionSubscribers(ctx context.Context) ([]model.UserPreference, error)
+	MarkRetentionRun(ctx context.Context, id int64, ranAt time.Time) error
+}
+
+// FocusSessionRepositoryInterface defines the contract for focus session repository operations
+type FocusSessionRepositoryInterface interface {
+	Create(ctx context.Context, session *model.FocusSession) error
+	Update(ctx context.Context, session *model.FocusSession) error
+	FindByID(ctx context.Context, id, userID int64) (*model.FocusSession, error)
+	FindActiveByUserID(ctx context.Context, userID int64) (*model.FocusSession, error)
+	List(ctx context.Context, input FocusSessionListInput) ([]model.FocusSession, int64, error)
+	DailyTotals(ctx context.Context, userID int64, from, to time.Time, timezone string) ([]DailyFocusTotal, error)
+}
+
+// StreakRepositoryInterface defines the contract for streak and achievement repository operations
+type StreakRepositoryInterface interface {
+	FindByUserID(ctx context.Context, userID int64) (*model.UserStreak, error)
+	FindOrNew(ctx context.Context, userID int64) (*model.UserStreak, error)
+	Save(ctx context.Context, streak *model.UserStreak) error
+	ListAchievements(ctx context.Context, userID int64) ([]model.UserAchievement, error)
+	CreateAchievement(ctx context.Context, achievement *model.UserAchievement) (bool, error)
+}
+
+// DataExportRepositoryInterface defines the contract for data export repository operations
+type DataExportRepositoryInterface interface {
+	Create(ctx context.Context, export *model.DataExport) error
+	Update(ctx context.Context, export *model.DataExport) error
+	ListByUserID(ctx context.Context, userID int64, limit int) ([]model.DataExport, error)
+	FindInProgressByUserID(ctx context.Context, userID int64) (*model.DataExport, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*model.DataExport, error)
+	ClaimPending(ctx context.Context, limit int, staleBefore time.Time) ([]model.DataExport, error)
+	FindExpired(ctx context.Context, now time.Time) ([]model.DataExport, error)
 }
 
 // Ensure concrete types implement interfaces
 var (
-	_ UserRepositoryInterface            = (*UserRepository)(nil)
-	_ TodoRepositoryInterface            = (*TodoRepository)(nil)
-	_ JwtDenylistRepositoryInterface     = (*JwtDenylistRepository)(nil)
-	_ CategoryRepositoryInterface        = (*CategoryRepository)(nil)
-	_ TagRepositoryInterface             = (*TagRepository)(nil)
-	_ CommentRepositoryInterface         = (*CommentRepository)(nil)
-	_ TodoHistoryRepositoryInterface     = (*TodoHistoryRepository)(nil)
-	_ FileRepositoryInterface            = (*FileRepository)(nil)
-	_ NoteRepositoryInterface            = (*NoteRepository)(nil)
-	_ NoteRevisionRepositoryInterface    = (*NoteRevisionRepository)(nil)
+	_ UserRepositoryInterface           = (*UserRepository)(nil)
+	_ TodoRepositoryInterface           = (*TodoRepository)(nil)
+	_ JwtDenylistRepositoryInterface    = (*JwtDenylistRepository)(nil)
+	_ CategoryRepositoryInterface       = (*CategoryRepository)(nil)
+	_ TagRepositoryInterface            = (*TagRepository)(nil)
+	_ CommentRepositoryInterface        = (*CommentRepository)(nil)
+	_ TodoHistoryRepositoryInterface    = (*TodoHistoryRepository)(nil)
+	_ FileRepositoryInterface           = (*FileRepository)(nil)
+	_ NoteRepositoryInterface           = (*NoteRepository)(nil)
+	_ NoteRevisionRepositoryInterface   = (*NoteRevisionRepository)(nil)
+	_ UserPreferenceRepositoryInterface = (*UserPreferenceRepository)(nil)
+	_ FocusSessionRepositoryInterface   = (*FocusSessionRepository)(nil)
+	_ StreakRepositoryInterface         = (*StreakRepository)(nil)
+	_ DataExportRepositoryInterface     = (*DataExportRepository)(nil)
 )
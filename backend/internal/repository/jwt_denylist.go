@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"todo-api/internal/model"
@@ -19,22 +20,22 @@ func NewJwtDenylistRepository(db *gorm.DB) *JwtDenylistRepository {
 }
 
 // Add adds a token to the denylist
-func (r *JwtDenylistRepository) Add(jti string, exp time.Time) error {
+func (r *JwtDenylistRepository) Add(ctx context.Context, jti string, exp time.Time) error {
 	denylist := &model.JwtDenylist{
 		Jti: jti,
 		Exp: exp,
 	}
-	return r.db.Create(denylist).Error
+	return r.db.WithContext(ctx).Create(denylist).Error
 }
 
 // Exists checks if a token with the given jti exists in the denylist
-func (r *JwtDenylistRepository) Exists(jti string) (bool, error) {
+func (r *JwtDenylistRepository) Exists(ctx context.Context, jti string) (bool, error) {
 	var count int64
-	result := r.db.Model(&model.JwtDenylist{}).Where("jti = ?", jti).Count(&count)
+	result := r.db.WithContext(ctx).Model(&model.JwtDenylist{}).Where("jti = ?", jti).Count(&count)
 	return count > 0, result.Error
 }
 
 // CleanupExpired removes expired tokens from the denylist
-func (r *JwtDenylistRepository) CleanupExpired() error {
-	return r.db.Where("exp < ?", time.Now()).Delete(&model.JwtDenylist{}).Error
+func (r *JwtDenylistRepository) CleanupExpired(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("exp < ?", time.Now()).Delete(&model.JwtDenylist{}).Error
 }
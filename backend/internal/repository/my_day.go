@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MyDayRepository handles database operations for My Day plans
+type MyDayRepository struct {
+	db *gorm.DB
+}
+
+// NewMyDayRepository creates a new MyDayRepository
+func NewMyDayRepository(db *gorm.DB) *MyDayRepository {
+	return &MyDayRepository{db: db}
+}
+
+// FindAllByUserID retrieves the items of all of a user's plans, by day and in the order they were added
+func (r *MyDayRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.MyDayItem, error) {
+	var items []model.MyDayItem
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("date ASC, position ASC, id ASC").
+		Find(&items)
+	return items, result.Error
+}
+
+// FindByDate retrieves the user's plan for a day with the todos, in the order they were added
+func (r *MyDayRepository) FindByDate(ctx context.Context, userID int64, date time.Time) ([]model.MyDayItem, error) {
+	var items []model.MyDayItem
+	result := database.ForUser(ctx, r.db, userID).
+		Preload("Todo").
+		Preload("Todo.Category").
+		Preload("Todo.Tags").
+		Where("user_id = ? AND date = ?", userID, date).
+		Order("position ASC, id ASC").
+		Find(&items)
+	return items, result.Error
+}
+
+// Add appends todos to the user's plan for a day; todos already planned are left in place
+func (r *MyDayRepository) Add(ctx context.Context, userID int64, date time.Time, todoIDs []int64) error {
+	if len(todoIDs) == 0 {
+		return nil
+	}
+
+	return database.ForUser(ctx, r.db, userID).Transaction(func(tx *gorm.DB) error {
+		var maxPosition int
+		if err := tx.Model(&model.MyDayItem{}).
+			Where("user_id = ? AND date = ?", userID, date).
+			Select("COALESCE(MAX(position), 0)").
+			Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+
+		for i, todoID := range todoIDs {
+			item := &model.MyDayItem{
+				UserID:   userID,
+				Date:     date,
+				TodoID:   todoID,
+				Position: maxPosition + i + 1,
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(item).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Remove removes todos from the user's plan for a day
+func (r *MyDayRepository) Remove(ctx context.Context, userID int64, date time.Time, todoIDs []int64) error {
+	if len(todoIDs) == 0 {
+		return nil
+	}
+	return database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND date = ? AND todo_id IN ?", userID, date, todoIDs).
+		Delete(&model.MyDayItem{}).Error
+}
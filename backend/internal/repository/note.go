@@ -1,7 +1,11 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"todo-api/internal/model"
+	"todo-api/pkg/database"
 
 	"gorm.io/gorm"
 )
@@ -27,10 +31,20 @@ type NoteSearchInput struct {
 	PerPage  int
 }
 
+// FindAllByUserID retrieves all notes of a user including archived and trashed ones, oldest first
+func (r *NoteRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.Note, error) {
+	var notes []model.Note
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&notes)
+	return notes, result.Error
+}
+
 // FindByID retrieves a note by ID for a specific user (excludes trashed)
-func (r *NoteRepository) FindByID(id, userID int64) (*model.Note, error) {
+func (r *NoteRepository) FindByID(ctx context.Context, id, userID int64) (*model.Note, error) {
 	var note model.Note
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("id = ? AND user_id = ? AND trashed_at IS NULL", id, userID).
 		First(&note)
 	if result.Error != nil {
@@ -40,9 +54,9 @@ func (r *NoteRepository) FindByID(id, userID int64) (*model.Note, error) {
 }
 
 // FindByIDIncludingTrashed retrieves a note by ID including trashed ones
-func (r *NoteRepository) FindByIDIncludingTrashed(id, userID int64) (*model.Note, error) {
+func (r *NoteRepository) FindByIDIncludingTrashed(ctx context.Context, id, userID int64) (*model.Note, error) {
 	var note model.Note
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("id = ? AND user_id = ?", id, userID).
 		First(&note)
 	if result.Error != nil {
@@ -52,16 +66,17 @@ func (r *NoteRepository) FindByIDIncludingTrashed(id, userID int64) (*model.Note
 }
 
 // Search searches notes with filters and pagination
-func (r *NoteRepository) Search(input NoteSearchInput) ([]model.Note, int64, error) {
+func (r *NoteRepository) Search(ctx context.Context, input NoteSearchInput) ([]model.Note, int64, error) {
 	var notes []model.Note
 	var total int64
 
-	query := r.db.Model(&model.Note{}).Where("user_id = ?", input.UserID)
+	query := database.ForUser(ctx, r.db, input.UserID).Model(&model.Note{}).Where("user_id = ?", input.UserID)
 
 	// Text search (title and body_plain)
 	if input.Query != "" {
 		searchPattern := "%" + input.Query + "%"
-		query = query.Where("(title ILIKE ? OR body_plain ILIKE ?)", searchPattern, searchPattern)
+		dialect := database.DialectOf(r.db)
+		query = query.Where("("+dialect.ILike("title")+" OR "+dialect.ILike("body_plain")+")", searchPattern, searchPattern)
 	}
 
 	// Archived filter
@@ -111,35 +126,65 @@ func (r *NoteRepository) Search(input NoteSearchInput) ([]model.Note, int64, err
 }
 
 // Create creates a new note
-func (r *NoteRepository) Create(note *model.Note) error {
-	return r.db.Create(note).Error
+func (r *NoteRepository) Create(ctx context.Context, note *model.Note) error {
+	return database.ForUser(ctx, r.db, note.UserID).Create(note).Error
 }
 
 // Update updates an existing note
-func (r *NoteRepository) Update(note *model.Note) error {
-	return r.db.Save(note).Error
+func (r *NoteRepository) Update(ctx context.Context, note *model.Note) error {
+	return database.ForUser(ctx, r.db, note.UserID).Save(note).Error
 }
 
 // SoftDelete sets trashed_at timestamp
-func (r *NoteRepository) SoftDelete(id, userID int64) error {
-	return r.db.Model(&model.Note{}).
+func (r *NoteRepository) SoftDelete(ctx context.Context, id, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).Model(&model.Note{}).
 		Where("id = ? AND user_id = ?", id, userID).
-		Update("trashed_at", gorm.Expr("NOW()")).Error
+		Update("trashed_at", time.Now()).Error
 }
 
 // HardDelete permanently deletes a note and its revisions
-func (r *NoteRepository) HardDelete(id, userID int64) error {
+func (r *NoteRepository) HardDelete(ctx context.Context, id, userID int64) error {
 	// Delete revisions first (foreign key constraint)
-	if err := r.db.Where("note_id = ?", id).Delete(&model.NoteRevision{}).Error; err != nil {
+	if err := database.ForUser(ctx, r.db, userID).Where("note_id = ?", id).Delete(&model.NoteRevision{}).Error; err != nil {
 		return err
 	}
-	return r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Note{}).Error
+	return database.ForUser(ctx, r.db, userID).Where("id = ? AND user_id = ?", id, userID).Delete(&model.Note{}).Error
+}
+
+// FindTrashedBefore retrieves notes moved to trash before the given time, oldest first
+func (r *NoteRepository) FindTrashedBefore(ctx context.Context, userID int64, before time.Time) ([]model.Note, error) {
+	var notes []model.Note
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND trashed_at < ?", userID, before).
+		Order("trashed_at ASC").
+		Find(&notes)
+	return notes, result.Error
+}
+
+// FindInactiveBefore retrieves active, unpinned notes last edited before the given time, oldest first
+func (r *NoteRepository) FindInactiveBefore(ctx context.Context, userID int64, before time.Time) ([]model.Note, error) {
+	var notes []model.Note
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND archived_at IS NULL AND trashed_at IS NULL AND pinned = ? AND last_edited_at < ?", userID, false, before).
+		Order("last_edited_at ASC").
+		Find(&notes)
+	return notes, result.Error
+}
+
+// ArchiveByIDs sets archived_at on the given notes of a user
+func (r *NoteRepository) ArchiveByIDs(ctx context.Context, ids []int64, userID int64, archivedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return database.ForUser(ctx, r.db, userID).Model(&model.Note{}).
+		Where("id IN ? AND user_id = ? AND archived_at IS NULL", ids, userID).
+		Update("archived_at", archivedAt).Error
 }
 
 // ExistsByID checks if a note exists for a user
-func (r *NoteRepository) ExistsByID(id, userID int64) (bool, error) {
+func (r *NoteRepository) ExistsByID(ctx context.Context, id, userID int64) (bool, error) {
 	var count int64
-	result := r.db.Model(&model.Note{}).
+	result := database.ForUser(ctx, r.db, userID).Model(&model.Note{}).
 		Where("id = ? AND user_id = ?", id, userID).
 		Count(&count)
 	return count > 0, result.Error
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"context"
+
 	"todo-api/internal/model"
+	"todo-api/pkg/database"
 
 	"gorm.io/gorm"
 )
@@ -17,23 +20,24 @@ func NewNoteRevisionRepository(db *gorm.DB) *NoteRevisionRepository {
 }
 
 // Create creates a new revision
-func (r *NoteRevisionRepository) Create(revision *model.NoteRevision) error {
-	return r.db.Create(revision).Error
+func (r *NoteRevisionRepository) Create(ctx context.Context, revision *model.NoteRevision) error {
+	return database.ForUser(ctx, r.db, revision.UserID).Create(revision).Error
 }
 
 // FindByNoteID retrieves all revisions for a note with pagination
-func (r *NoteRevisionRepository) FindByNoteID(noteID int64, page, perPage int) ([]model.NoteRevision, int64, error) {
+func (r *NoteRevisionRepository) FindByNoteID(ctx context.Context, noteID, userID int64, page, perPage int) ([]model.NoteRevision, int64, error) {
+	db := database.ForUser(ctx, r.db, userID)
 	var revisions []model.NoteRevision
 	var total int64
 
 	// Count total
-	if err := r.db.Model(&model.NoteRevision{}).Where("note_id = ?", noteID).Count(&total).Error; err != nil {
+	if err := db.Model(&model.NoteRevision{}).Where("note_id = ?", noteID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated results (newest first)
 	offset := (page - 1) * perPage
-	result := r.db.
+	result := db.
 		Where("note_id = ?", noteID).
 		Order("created_at DESC").
 		Offset(offset).
@@ -48,9 +52,9 @@ func (r *NoteRevisionRepository) FindByNoteID(noteID int64, page, perPage int) (
 }
 
 // FindByID retrieves a specific revision
-func (r *NoteRevisionRepository) FindByID(id, noteID int64) (*model.NoteRevision, error) {
+func (r *NoteRevisionRepository) FindByID(ctx context.Context, id, noteID, userID int64) (*model.NoteRevision, error) {
 	var revision model.NoteRevision
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("id = ? AND note_id = ?", id, noteID).
 		First(&revision)
 	if result.Error != nil {
@@ -60,19 +64,20 @@ func (r *NoteRevisionRepository) FindByID(id, noteID int64) (*model.NoteRevision
 }
 
 // CountByNoteID counts revisions for a note
-func (r *NoteRevisionRepository) CountByNoteID(noteID int64) (int64, error) {
+func (r *NoteRevisionRepository) CountByNoteID(ctx context.Context, noteID, userID int64) (int64, error) {
 	var count int64
-	result := r.db.Model(&model.NoteRevision{}).
+	result := database.ForUser(ctx, r.db, userID).Model(&model.NoteRevision{}).
 		Where("note_id = ?", noteID).
 		Count(&count)
 	return count, result.Error
 }
 
 // DeleteOldestByNoteID deletes the oldest revisions exceeding the limit
-func (r *NoteRevisionRepository) DeleteOldestByNoteID(noteID int64, keepCount int) error {
+func (r *NoteRevisionRepository) DeleteOldestByNoteID(ctx context.Context, noteID, userID int64, keepCount int) error {
+	db := database.ForUser(ctx, r.db, userID)
 	// Get IDs of revisions to keep (newest ones)
 	var keepIDs []int64
-	err := r.db.Model(&model.NoteRevision{}).
+	err := db.Model(&model.NoteRevision{}).
 		Select("id").
 		Where("note_id = ?", noteID).
 		Order("created_at DESC").
@@ -87,7 +92,7 @@ func (r *NoteRevisionRepository) DeleteOldestByNoteID(noteID int64, keepCount in
 	}
 
 	// Delete revisions not in the keep list
-	return r.db.
+	return db.
 		Where("note_id = ? AND id NOT IN ?", noteID, keepIDs).
 		Delete(&model.NoteRevision{}).Error
 }
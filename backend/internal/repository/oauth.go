@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// OAuthRepository handles database operations for OAuth authorization codes and refresh tokens
+type OAuthRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthRepository creates a new OAuthRepository
+func NewOAuthRepository(db *gorm.DB) *OAuthRepository {
+	return &OAuthRepository{db: db}
+}
+
+// CreateCode creates a new authorization code
+func (r *OAuthRepository) CreateCode(ctx context.Context, code *model.OAuthAuthorizationCode) error {
+	return database.ForUser(ctx, r.db, code.UserID).Create(code).Error
+}
+
+// FindCodeByHash retrieves an authorization code by the hash of its value
+func (r *OAuthRepository) FindCodeByHash(ctx context.Context, codeHash string) (*model.OAuthAuthorizationCode, error) {
+	var code model.OAuthAuthorizationCode
+	result := database.AsSystem(ctx, r.db).Where("code_hash = ?", codeHash).First(&code)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &code, nil
+}
+
+// MarkCodeUsed atomically marks an authorization code as used.
+// Returns false if it had already been used.
+func (r *OAuthRepository) MarkCodeUsed(ctx context.Context, code *model.OAuthAuthorizationCode, now time.Time) (bool, error) {
+	result := database.ForUser(ctx, r.db, code.UserID).
+		Model(&model.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		UpdateColumn("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateRefreshToken creates a new refresh token
+func (r *OAuthRepository) CreateRefreshToken(ctx context.Context, token *model.OAuthRefreshToken) error {
+	return database.ForUser(ctx, r.db, token.UserID).Create(token).Error
+}
+
+// FindRefreshTokenByHash retrieves a refresh token by the hash of its value
+func (r *OAuthRepository) FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.OAuthRefreshToken, error) {
+	var token model.OAuthRefreshToken
+	result := database.AsSystem(ctx, r.db).Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken atomically revokes a refresh token.
+// Returns false if it had already been revoked.
+func (r *OAuthRepository) RevokeRefreshToken(ctx context.Context, token *model.OAuthRefreshToken, now time.Time) (bool, error) {
+	result := database.ForUser(ctx, r.db, token.UserID).
+		Model(&model.OAuthRefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", token.ID).
+		UpdateColumn("revoked_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RevokeFamily revokes all active refresh tokens of a family and denylists the unexpired access tokens
+// issued with any of its tokens, including ones already replaced
+func (r *OAuthRepository) RevokeFamily(ctx context.Context, userID int64, familyID string, now time.Time) error {
+	return database.ForUser(ctx, r.db, userID).Transaction(func(tx *gorm.DB) error {
+		var tokens []model.OAuthRefreshToken
+		if err := tx.Where("user_id = ? AND family_id = ? AND access_token_jti <> '' AND access_token_expires_at > ?", userID, familyID, now).
+			Where("NOT EXISTS (SELECT 1 FROM jwt_denylists WHERE jwt_denylists.jti = oauth_refresh_tokens.access_token_jti)").
+			Find(&tokens).Error; err != nil {
+			return err
+		}
+		if len(tokens) > 0 {
+			denylist := make([]model.JwtDenylist, len(tokens))
+			for i, token := range tokens {
+				denylist[i] = model.JwtDenylist{Jti: token.AccessTokenJti, Exp: *token.AccessTokenExpiresAt}
+			}
+			if err := tx.Create(&denylist).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&model.OAuthRefreshToken{}).
+			Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, familyID).
+			UpdateColumn("revoked_at", now).Error
+	})
+}
+
+// DeleteExpired deletes authorization codes and refresh tokens that expired before the given time
+func (r *OAuthRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	db := database.AsSystem(ctx, r.db)
+	codes := db.Where("expires_at < ?", before).Delete(&model.OAuthAuthorizationCode{})
+	if codes.Error != nil {
+		return 0, codes.Error
+	}
+	tokens := db.Where("expires_at < ?", before).Delete(&model.OAuthRefreshToken{})
+	if tokens.Error != nil {
+		return codes.RowsAffected, tokens.Error
+	}
+	return codes.RowsAffected + tokens.RowsAffected, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PolicyConsentRepository handles database operations for policy consents
+type PolicyConsentRepository struct {
+	db *gorm.DB
+}
+
+// NewPolicyConsentRepository creates a new PolicyConsentRepository
+func NewPolicyConsentRepository(db *gorm.DB) *PolicyConsentRepository {
+	return &PolicyConsentRepository{db: db}
+}
+
+// Create records a consent. Accepting a version again keeps the first record.
+func (r *PolicyConsentRepository) Create(ctx context.Context, consent *model.PolicyConsent) error {
+	return database.ForUser(ctx, r.db, consent.UserID).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(consent).Error
+}
+
+// FindAllByUserID retrieves every policy version a user has accepted, oldest first
+func (r *PolicyConsentRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.PolicyConsent, error) {
+	var consents []model.PolicyConsent
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("accepted_at ASC, id ASC").
+		Find(&consents)
+	return consents, result.Error
+}
+
+// FindLatestByUserID retrieves the most recently accepted version of each policy for a user
+func (r *PolicyConsentRepository) FindLatestByUserID(ctx context.Context, userID int64) (map[model.Policy]model.PolicyConsent, error) {
+	var consents []model.PolicyConsent
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("accepted_at ASC, id ASC").
+		Find(&consents)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	latest := make(map[model.Policy]model.PolicyConsent, len(consents))
+	for _, consent := range consents {
+		latest[consent.Policy] = consent
+	}
+	return latest, nil
+}
+
+// AcceptedPolicies returns which of the given policy versions the user has accepted
+func (r *PolicyConsentRepository) AcceptedPolicies(ctx context.Context, userID int64, versions map[model.Policy]string) (map[model.Policy]bool, error) {
+	accepted := make(map[model.Policy]bool, len(versions))
+	if len(versions) == 0 {
+		return accepted, nil
+	}
+
+	conditions := r.db.WithContext(ctx).Where("1 = 0")
+	for policy, version := range versions {
+		conditions = conditions.Or("policy = ? AND version = ?", policy, version)
+	}
+
+	var policies []model.Policy
+	result := database.ForUser(ctx, r.db, userID).Model(&model.PolicyConsent{}).
+		Where("user_id = ?", userID).
+		Where(conditions).
+		Pluck("policy", &policies)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, policy := range policies {
+		accepted[policy] = true
+	}
+	return accepted, nil
+}
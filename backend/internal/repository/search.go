@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/encryption"
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchRepository handles cross-resource text search queries
+type SearchRepository struct {
+	db *gorm.DB
+}
+
+// NewSearchRepository creates a new SearchRepository
+func NewSearchRepository(db *gorm.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// CommentSearchRow is a matched comment together with the title of its todo
+type CommentSearchRow struct {
+	model.Comment
+	TodoTitle string
+}
+
+// rankedOrder orders exact matches of column first, then prefix matches, then the rest by tiebreak
+func rankedOrder(dialect database.Dialect, column, query, tiebreak string) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL: "CASE WHEN LOWER(" + column + ") = LOWER(?) THEN 0 " +
+			"WHEN " + dialect.ILike(column) + " THEN 1 ELSE 2 END, " + tiebreak,
+		Vars:               []any{query, query + "%"},
+		WithoutParentheses: true,
+	}}
+}
+
+// SearchTodos retrieves the user's todos whose title or description contains the query.
+// Only titles are matched while field encryption is enabled.
+func (r *SearchRepository) SearchTodos(ctx context.Context, userID int64, query string, limit int) ([]model.Todo, int64, error) {
+	dialect := database.DialectOf(r.db)
+	pattern := "%" + query + "%"
+	textMatch := dialect.ILike("title")
+	args := []any{userID, pattern}
+	if !encryption.Enabled() {
+		textMatch += " OR " + dialect.ILike("description")
+		args = append(args, pattern)
+	}
+	q := database.ForUser(ctx, r.db, userID).Model(&model.Todo{}).
+		Where("user_id = ? AND ("+textMatch+")", args...)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var todos []model.Todo
+	err := q.
+		Order(rankedOrder(dialect, "title", query, "updated_at DESC")).
+		Limit(limit).
+		Find(&todos).Error
+	return todos, total, err
+}
+
+// SearchComments retrieves comments on the user's todos whose content contains the query.
+// Encrypted content can't be matched in SQL, so nothing is found while field encryption is enabled.
+func (r *SearchRepository) SearchComments(ctx context.Context, userID int64, query string, limit int) ([]CommentSearchRow, int64, error) {
+	if encryption.Enabled() {
+		return nil, 0, nil
+	}
+	pattern := "%" + query + "%"
+	q := database.ForUser(ctx, r.db, userID).Model(&model.Comment{}).
+		Joins("JOIN todos ON todos.id = comments.commentable_id AND comments.commentable_type = ?", model.CommentableTypeTodo).
+		Where("todos.user_id = ? AND "+database.DialectOf(r.db).ILike("comments.content"), userID, pattern)
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []CommentSearchRow
+	err := q.
+		Select("comments.*, todos.title AS todo_title").
+		Order("comments.created_at DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, total, err
+}
+
+// SearchCategories retrieves the user's categories whose name contains the query
+func (r *SearchRepository) SearchCategories(ctx context.Context, userID int64, query string, limit int) ([]model.Category, int64, error) {
+	return searchNamed[model.Category](database.ForUser(ctx, r.db, userID), userID, query, limit)
+}
+
+// SearchTags retrieves the user's tags whose name contains the query
+func (r *SearchRepository) SearchTags(ctx context.Context, userID int64, query string, limit int) ([]model.Tag, int64, error) {
+	return searchNamed[model.Tag](database.ForUser(ctx, r.db, userID), userID, query, limit)
+}
+
+// searchNamed searches a user-owned table by its name column
+func searchNamed[T any](db *gorm.DB, userID int64, query string, limit int) ([]T, int64, error) {
+	dialect := database.DialectOf(db)
+	q := db.Model(new(T)).
+		Where("user_id = ? AND "+dialect.ILike("name"), userID, "%"+query+"%")
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var records []T
+	err := q.
+		Order(rankedOrder(dialect, "name", query, "name ASC")).
+		Limit(limit).
+		Find(&records).Error
+	return records, total, err
+}
+
+// FindTodosByIDs retrieves the user's todos with the given IDs
+func (r *SearchRepository) FindTodosByIDs(ctx context.Context, userID int64, ids []int64) ([]model.Todo, error) {
+	var todos []model.Todo
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Find(&todos)
+	return todos, result.Error
+}
+
+// FindCommentsByIDs retrieves comments with the given IDs on the user's todos
+func (r *SearchRepository) FindCommentsByIDs(ctx context.Context, userID int64, ids []int64) ([]CommentSearchRow, error) {
+	var rows []CommentSearchRow
+	result := database.ForUser(ctx, r.db, userID).Model(&model.Comment{}).
+		Joins("JOIN todos ON todos.id = comments.commentable_id AND comments.commentable_type = ?", model.CommentableTypeTodo).
+		Where("todos.user_id = ? AND comments.id IN ?", userID, ids).
+		Select("comments.*, todos.title AS todo_title").
+		Scan(&rows)
+	return rows, result.Error
+}
+
+// FindCategoriesByIDs retrieves the user's categories with the given IDs
+func (r *SearchRepository) FindCategoriesByIDs(ctx context.Context, userID int64, ids []int64) ([]model.Category, error) {
+	var categories []model.Category
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Find(&categories)
+	return categories, result.Error
+}
+
+// FindTagsByIDs retrieves the user's tags with the given IDs
+func (r *SearchRepository) FindTagsByIDs(ctx context.Context, userID int64, ids []int64) ([]model.Tag, error) {
+	var tags []model.Tag
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Find(&tags)
+	return tags, result.Error
+}
+
+// FindTodosChangedSince retrieves todos of all users updated at or after since, oldest change first
+func (r *SearchRepository) FindTodosChangedSince(ctx context.Context, since time.Time, offset, limit int) ([]model.Todo, error) {
+	var todos []model.Todo
+	result := database.AsSystem(ctx, r.db).
+		Where("updated_at >= ?", since).
+		Order("updated_at ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&todos)
+	return todos, result.Error
+}
+
+// FindCommentsChangedSince retrieves comments of all users updated or deleted at or after since,
+// including soft-deleted ones, oldest change first
+func (r *SearchRepository) FindCommentsChangedSince(ctx context.Context, since time.Time, offset, limit int) ([]model.Comment, error) {
+	var comments []model.Comment
+	result := database.AsSystem(ctx, r.db).
+		Unscoped().
+		Where("updated_at >= ? OR deleted_at >= ?", since, since).
+		Order("CASE WHEN deleted_at > updated_at THEN deleted_at ELSE updated_at END ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&comments)
+	return comments, result.Error
+}
+
+// FindCategoriesChangedSince retrieves categories of all users updated at or after since, oldest change first
+func (r *SearchRepository) FindCategoriesChangedSince(ctx context.Context, since time.Time, offset, limit int) ([]model.Category, error) {
+	var categories []model.Category
+	result := database.AsSystem(ctx, r.db).
+		Where("updated_at >= ?", since).
+		Order("updated_at ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&categories)
+	return categories, result.Error
+}
+
+// FindTagsChangedSince retrieves tags of all users updated at or after since, oldest change first
+func (r *SearchRepository) FindTagsChangedSince(ctx context.Context, since time.Time, offset, limit int) ([]model.Tag, error) {
+	var tags []model.Tag
+	result := database.AsSystem(ctx, r.db).
+		Where("updated_at >= ?", since).
+		Order("updated_at ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&tags)
+	return tags, result.Error
+}
+
+// QueueUpdates records writes of searchable rows, to be sent to the external index later
+func (r *SearchRepository) QueueUpdates(ctx context.Context, updates []model.PendingSearchUpdate) error {
+	return r.db.WithContext(ctx).Create(&updates).Error
+}
+
+// FindQueuedUpdates retrieves up to limit queued updates, oldest first
+func (r *SearchRepository) FindQueuedUpdates(ctx context.Context, limit int) ([]model.PendingSearchUpdate, error) {
+	var updates []model.PendingSearchUpdate
+	result := r.db.WithContext(ctx).Order("id ASC").Limit(limit).Find(&updates)
+	return updates, result.Error
+}
+
+// DeleteQueuedUpdates deletes the queued updates with the given IDs
+func (r *SearchRepository) DeleteQueuedUpdates(ctx context.Context, ids []int64) error {
+	return r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.PendingSearchUpdate{}).Error
+}
+
+// FindAllTodosByIDs retrieves todos of all users with the given IDs
+func (r *SearchRepository) FindAllTodosByIDs(ctx context.Context, ids []int64) ([]model.Todo, error) {
+	var todos []model.Todo
+	result := database.AsSystem(ctx, r.db).Where("id IN ?", ids).Find(&todos)
+	return todos, result.Error
+}
+
+// FindAllCommentsByIDs retrieves comments of all users with the given IDs; soft-deleted ones are left out
+func (r *SearchRepository) FindAllCommentsByIDs(ctx context.Context, ids []int64) ([]model.Comment, error) {
+	var comments []model.Comment
+	result := database.AsSystem(ctx, r.db).Where("id IN ?", ids).Find(&comments)
+	return comments, result.Error
+}
+
+// FindAllCategoriesByIDs retrieves categories of all users with the given IDs
+func (r *SearchRepository) FindAllCategoriesByIDs(ctx context.Context, ids []int64) ([]model.Category, error) {
+	var categories []model.Category
+	result := database.AsSystem(ctx, r.db).Where("id IN ?", ids).Find(&categories)
+	return categories, result.Error
+}
+
+// FindAllTagsByIDs retrieves tags of all users with the given IDs
+func (r *SearchRepository) FindAllTagsByIDs(ctx context.Context, ids []int64) ([]model.Tag, error) {
+	var tags []model.Tag
+	result := database.AsSystem(ctx, r.db).Where("id IN ?", ids).Find(&tags)
+	return tags, result.Error
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StreakRepository handles database operations for streaks and achievements
+type StreakRepository struct {
+	db *gorm.DB
+}
+
+// NewStreakRepository creates a new StreakRepository
+func NewStreakRepository(db *gorm.DB) *StreakRepository {
+	return &StreakRepository{db: db}
+}
+
+// FindByUserID retrieves the streak record for a user
+func (r *StreakRepository) FindByUserID(ctx context.Context, userID int64) (*model.UserStreak, error) {
+	var streak model.UserStreak
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		First(&streak)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &streak, nil
+}
+
+// FindOrNew retrieves the streak record for a user, or an unsaved empty record if none exists
+func (r *StreakRepository) FindOrNew(ctx context.Context, userID int64) (*model.UserStreak, error) {
+	streak, err := r.FindByUserID(ctx, userID)
+	if err == gorm.ErrRecordNotFound {
+		return &model.UserStreak{UserID: userID}, nil
+	}
+	return streak, err
+}
+
+// Save creates or updates a streak record
+func (r *StreakRepository) Save(ctx context.Context, streak *model.UserStreak) error {
+	return database.ForUser(ctx, r.db, streak.UserID).Save(streak).Error
+}
+
+// RecordCompletion counts a todo's completion on the given day towards the user's streak and returns
+// the updated streak, or nil if the todo was counted before. The streak row is locked while it is
+// updated so concurrent completions are applied one after another instead of overwriting each other.
+func (r *StreakRepository) RecordCompletion(ctx context.Context, userID, todoID int64, day time.Time) (*model.UserStreak, error) {
+	var streak *model.UserStreak
+	err := database.ForUser(ctx, r.db, userID).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.StreakCompletion{UserID: userID, TodoID: todoID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.UserStreak{UserID: userID}).Error; err != nil {
+			return err
+		}
+		var locked model.UserStreak
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			First(&locked).Error; err != nil {
+			return err
+		}
+
+		locked.RecordCompletion(day)
+		if err := tx.Save(&locked).Error; err != nil {
+			return err
+		}
+		streak = &locked
+		return nil
+	})
+	return streak, err
+}
+
+// BackfillCompletions records the todos completed so far as counted, so completing them again after
+// reopening them does not count them twice. It is safe to run repeatedly.
+func (r *StreakRepository) BackfillCompletions(ctx context.Context) error {
+	return database.AsSystem(ctx, r.db).Exec(`
+		INSERT INTO streak_completions (user_id, todo_id, created_at)
+		SELECT todos.user_id, todos.id, todos.completed_at FROM todos
+		WHERE todos.completed_at IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM streak_completions WHERE streak_completions.todo_id = todos.id)`).Error
+}
+
+// ListAchievements retrieves all achievements unlocked by a user, oldest first
+func (r *StreakRepository) ListAchievements(ctx context.Context, userID int64) ([]model.UserAchievement, error) {
+	var achievements []model.UserAchievement
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("achieved_at ASC, id ASC").
+		Find(&achievements)
+	return achievements, result.Error
+}
+
+// CreateAchievement records an unlocked achievement.
+// Returns false if the user had already unlocked it.
+func (r *StreakRepository) CreateAchievement(ctx context.Context, achievement *model.UserAchievement) (bool, error) {
+	result := database.ForUser(ctx, r.db, achievement.UserID).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(achievement)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SyncCursor is the position of the last todo on a page of changes
+type SyncCursor struct {
+	UpdatedAt time.Time
+	ID        int64
+}
+
+// SyncRepository handles database operations for syncing todos with offline clients
+type SyncRepository struct {
+	db *gorm.DB
+}
+
+// NewSyncRepository creates a new SyncRepository
+func NewSyncRepository(db *gorm.DB) *SyncRepository {
+	return &SyncRepository{db: db}
+}
+
+// FindTodoByUUID retrieves a user's todo by its UUID
+func (r *SyncRepository) FindTodoByUUID(ctx context.Context, todoUUID string, userID int64) (*model.Todo, error) {
+	var todo model.Todo
+	result := database.ForUser(ctx, r.db, userID).
+		Where("uuid = ? AND user_id = ?", todoUUID, userID).
+		First(&todo)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &todo, nil
+}
+
+// UUIDInUse checks if any user has a todo with the UUID, or another user a tombstone of it
+func (r *SyncRepository) UUIDInUse(ctx context.Context, todoUUID string, userID int64) (bool, error) {
+	db := database.AsSystem(ctx, r.db)
+
+	var count int64
+	if err := db.Model(&model.Todo{}).
+		Where("uuid = ?", todoUUID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	// Tombstones are unique by UUID, so reusing another user's would take over their deletion
+	result := db.Model(&model.TodoTombstone{}).
+		Where("uuid = ? AND user_id <> ?", todoUUID, userID).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// AssignMissingUUIDs gives the user's todos created before UUIDs existed one, without touching updated_at
+func (r *SyncRepository) AssignMissingUUIDs(ctx context.Context, userID int64) error {
+	db := database.ForUser(ctx, r.db, userID)
+
+	var ids []int64
+	if err := db.Model(&model.Todo{}).
+		Where("user_id = ? AND uuid IS NULL", userID).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := db.Model(&model.Todo{}).
+			Where("id = ? AND uuid IS NULL", id).
+			UpdateColumn("uuid", uuid.NewString()).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindChangedTodos retrieves up to limit of the user's todos updated after since,
+// ordered by update time and ID and starting after the cursor, if any
+func (r *SyncRepository) FindChangedTodos(ctx context.Context, userID int64, since time.Time, after *SyncCursor, limit int) ([]model.Todo, error) {
+	query := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID)
+	if !since.IsZero() {
+		query = query.Where("updated_at > ?", since)
+	}
+	if after != nil {
+		query = query.Where("updated_at > ? OR (updated_at = ? AND id > ?)", after.UpdatedAt, after.UpdatedAt, after.ID)
+	}
+
+	var todos []model.Todo
+	result := query.
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&todos)
+	return todos, result.Error
+}
+
+// FindTombstone retrieves the tombstone of a user's deleted todo
+func (r *SyncRepository) FindTombstone(ctx context.Context, todoUUID string, userID int64) (*model.TodoTombstone, error) {
+	var tombstone model.TodoTombstone
+	result := database.ForUser(ctx, r.db, userID).
+		Where("uuid = ? AND user_id = ?", todoUUID, userID).
+		First(&tombstone)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &tombstone, nil
+}
+
+// FindTombstonesSince retrieves the user's tombstones of todos deleted after since, oldest first
+func (r *SyncRepository) FindTombstonesSince(ctx context.Context, userID int64, since time.Time) ([]model.TodoTombstone, error) {
+	query := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID)
+	if !since.IsZero() {
+		query = query.Where("deleted_at > ?", since)
+	}
+
+	var tombstones []model.TodoTombstone
+	result := query.
+		Order("deleted_at ASC, id ASC").
+		Find(&tombstones)
+	return tombstones, result.Error
+}
+
+// DeleteTombstone removes the tombstone of a todo that was created again
+func (r *SyncRepository) DeleteTombstone(ctx context.Context, todoUUID string, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).
+		Where("uuid = ? AND user_id = ?", todoUUID, userID).
+		Delete(&model.TodoTombstone{}).Error
+}
+
+// DeleteTombstonesBefore removes tombstones of todos deleted before the given time
+func (r *SyncRepository) DeleteTombstonesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := database.AsSystem(ctx, r.db).
+		Where("deleted_at < ?", before).
+		Delete(&model.TodoTombstone{})
+	return result.RowsAffected, result.Error
+}
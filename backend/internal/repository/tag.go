@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"context"
 	"strings"
 
 	"gorm.io/gorm"
 
 	"todo-api/internal/model"
+	"todo-api/pkg/database"
 )
 
 // TagRepository handles database operations for tags
@@ -19,9 +21,9 @@ func NewTagRepository(db *gorm.DB) *TagRepository {
 }
 
 // FindAllByUserID retrieves all tags for a user ordered by name
-func (r *TagRepository) FindAllByUserID(userID int64) ([]model.Tag, error) {
+func (r *TagRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.Tag, error) {
 	var tags []model.Tag
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("user_id = ?", userID).
 		Order("name ASC").
 		Find(&tags)
@@ -29,9 +31,9 @@ func (r *TagRepository) FindAllByUserID(userID int64) ([]model.Tag, error) {
 }
 
 // FindByID retrieves a tag by ID for a specific user
-func (r *TagRepository) FindByID(id, userID int64) (*model.Tag, error) {
+func (r *TagRepository) FindByID(ctx context.Context, id, userID int64) (*model.Tag, error) {
 	var tag model.Tag
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("id = ? AND user_id = ?", id, userID).
 		First(&tag)
 	if result.Error != nil {
@@ -42,10 +44,10 @@ func (r *TagRepository) FindByID(id, userID int64) (*model.Tag, error) {
 
 // ExistsByName checks if a tag with the given name exists for a user
 // Note: Tag names are normalized to lowercase in BeforeSave hook
-func (r *TagRepository) ExistsByName(name string, userID int64, excludeID *int64) (bool, error) {
+func (r *TagRepository) ExistsByName(ctx context.Context, name string, userID int64, excludeID *int64) (bool, error) {
 	var count int64
 	normalizedName := strings.ToLower(strings.TrimSpace(name))
-	query := r.db.Model(&model.Tag{}).
+	query := database.ForUser(ctx, r.db, userID).Model(&model.Tag{}).
 		Where("name = ? AND user_id = ?", normalizedName, userID)
 	if excludeID != nil {
 		query = query.Where("id != ?", *excludeID)
@@ -55,18 +57,18 @@ func (r *TagRepository) ExistsByName(name string, userID int64, excludeID *int64
 }
 
 // Create creates a new tag
-func (r *TagRepository) Create(tag *model.Tag) error {
-	return r.db.Create(tag).Error
+func (r *TagRepository) Create(ctx context.Context, tag *model.Tag) error {
+	return database.ForUser(ctx, r.db, tag.UserID).Create(tag).Error
 }
 
 // Update updates an existing tag
-func (r *TagRepository) Update(tag *model.Tag) error {
-	return r.db.Save(tag).Error
+func (r *TagRepository) Update(ctx context.Context, tag *model.Tag) error {
+	return database.ForUser(ctx, r.db, tag.UserID).Save(tag).Error
 }
 
 // Delete deletes a tag (CASCADE will remove from todo_tags)
-func (r *TagRepository) Delete(id, userID int64) error {
-	result := r.db.
+func (r *TagRepository) Delete(ctx context.Context, id, userID int64) error {
+	result := database.ForUser(ctx, r.db, userID).
 		Where("id = ? AND user_id = ?", id, userID).
 		Delete(&model.Tag{})
 	if result.Error != nil {
@@ -79,24 +81,24 @@ func (r *TagRepository) Delete(id, userID int64) error {
 }
 
 // FindByIDs retrieves tags by IDs for a specific user
-func (r *TagRepository) FindByIDs(ids []int64, userID int64) ([]model.Tag, error) {
+func (r *TagRepository) FindByIDs(ctx context.Context, ids []int64, userID int64) ([]model.Tag, error) {
 	var tags []model.Tag
 	if len(ids) == 0 {
 		return tags, nil
 	}
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("id IN ? AND user_id = ?", ids, userID).
 		Find(&tags)
 	return tags, result.Error
 }
 
 // ValidateTagOwnership checks if all tag IDs belong to the user
-func (r *TagRepository) ValidateTagOwnership(tagIDs []int64, userID int64) (bool, error) {
+func (r *TagRepository) ValidateTagOwnership(ctx context.Context, tagIDs []int64, userID int64) (bool, error) {
 	if len(tagIDs) == 0 {
 		return true, nil
 	}
 	var count int64
-	result := r.db.Model(&model.Tag{}).
+	result := database.ForUser(ctx, r.db, userID).Model(&model.Tag{}).
 		Where("id IN ? AND user_id = ?", tagIDs, userID).
 		Count(&count)
 	if result.Error != nil {
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"todo-api/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// TenantRepository handles database operations for tenants
+type TenantRepository struct {
+	db *gorm.DB
+}
+
+// NewTenantRepository creates a new TenantRepository
+func NewTenantRepository(db *gorm.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+// FindBySlug finds a tenant by slug
+func (r *TenantRepository) FindBySlug(ctx context.Context, slug string) (*model.Tenant, error) {
+	var tenant model.Tenant
+	result := r.db.WithContext(ctx).Where("slug = ?", slug).First(&tenant)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &tenant, nil
+}
+
+// FindAll retrieves all tenants ordered by slug
+func (r *TenantRepository) FindAll(ctx context.Context) ([]model.Tenant, error) {
+	var tenants []model.Tenant
+	result := r.db.WithContext(ctx).Order("slug ASC").Find(&tenants)
+	return tenants, result.Error
+}
+
+// Create creates a new tenant
+func (r *TenantRepository) Create(ctx context.Context, tenant *model.Tenant) error {
+	return r.db.WithContext(ctx).Create(tenant).Error
+}
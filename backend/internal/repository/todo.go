@@ -1,13 +1,18 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"todo-api/internal/encryption"
 	"todo-api/internal/model"
+	"todo-api/pkg/database"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // OrderUpdate represents a single position update for a todo
@@ -27,9 +32,9 @@ func NewTodoRepository(db *gorm.DB) *TodoRepository {
 }
 
 // FindAllByUserID retrieves all todos for a user
-func (r *TodoRepository) FindAllByUserID(userID int64) ([]model.Todo, error) {
+func (r *TodoRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.Todo, error) {
 	var todos []model.Todo
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("user_id = ?", userID).
 		Order("COALESCE(position, 0) ASC, created_at DESC").
 		Find(&todos)
@@ -40,9 +45,9 @@ func (r *TodoRepository) FindAllByUserID(userID int64) ([]model.Todo, error) {
 }
 
 // FindAllByUserIDWithRelations retrieves all todos for a user with preloaded relations
-func (r *TodoRepository) FindAllByUserIDWithRelations(userID int64) ([]model.Todo, error) {
+func (r *TodoRepository) FindAllByUserIDWithRelations(ctx context.Context, userID int64) ([]model.Todo, error) {
 	var todos []model.Todo
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Preload("Category").
 		Preload("Tags").
 		Where("user_id = ?", userID).
@@ -54,10 +59,25 @@ func (r *TodoRepository) FindAllByUserIDWithRelations(userID int64) ([]model.Tod
 	return todos, nil
 }
 
+// FindUnarchivedByUserIDWithRelations retrieves the todos of a user that are not archived, with preloaded relations
+func (r *TodoRepository) FindUnarchivedByUserIDWithRelations(ctx context.Context, userID int64) ([]model.Todo, error) {
+	var todos []model.Todo
+	result := database.ForUser(ctx, r.db, userID).
+		Preload("Category").
+		Preload("Tags").
+		Where("user_id = ? AND archived_at IS NULL", userID).
+		Order("COALESCE(position, 0) ASC, created_at DESC").
+		Find(&todos)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return todos, nil
+}
+
 // FindByID retrieves a todo by ID for a specific user
-func (r *TodoRepository) FindByID(id, userID int64) (*model.Todo, error) {
+func (r *TodoRepository) FindByID(ctx context.Context, id, userID int64) (*model.Todo, error) {
 	var todo model.Todo
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Where("id = ? AND user_id = ?", id, userID).
 		First(&todo)
 	if result.Error != nil {
@@ -67,9 +87,9 @@ func (r *TodoRepository) FindByID(id, userID int64) (*model.Todo, error) {
 }
 
 // FindByIDWithRelations retrieves a todo by ID with preloaded relations
-func (r *TodoRepository) FindByIDWithRelations(id, userID int64) (*model.Todo, error) {
+func (r *TodoRepository) FindByIDWithRelations(ctx context.Context, id, userID int64) (*model.Todo, error) {
 	var todo model.Todo
-	result := r.db.
+	result := database.ForUser(ctx, r.db, userID).
 		Preload("Category").
 		Preload("Tags").
 		Where("id = ? AND user_id = ?", id, userID).
@@ -81,32 +101,52 @@ func (r *TodoRepository) FindByIDWithRelations(id, userID int64) (*model.Todo, e
 }
 
 // Create creates a new todo
-func (r *TodoRepository) Create(todo *model.Todo) error {
-	return r.db.Create(todo).Error
+func (r *TodoRepository) Create(ctx context.Context, todo *model.Todo) error {
+	return database.ForUser(ctx, r.db, todo.UserID).Create(todo).Error
 }
 
 // Update updates an existing todo
-func (r *TodoRepository) Update(todo *model.Todo) error {
-	return r.db.Save(todo).Error
+func (r *TodoRepository) Update(ctx context.Context, todo *model.Todo) error {
+	return database.ForUser(ctx, r.db, todo.UserID).Save(todo).Error
 }
 
-// Delete deletes a todo by ID for a specific user
-func (r *TodoRepository) Delete(id, userID int64) error {
-	result := r.db.
-		Where("id = ? AND user_id = ?", id, userID).
-		Delete(&model.Todo{})
-	if result.Error != nil {
-		return result.Error
-	}
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return nil
+// Delete deletes a todo by ID for a specific user, leaving a tombstone for sync clients
+func (r *TodoRepository) Delete(ctx context.Context, id, userID int64) error {
+	return database.ForUser(ctx, r.db, userID).Transaction(func(tx *gorm.DB) error {
+		var todo model.Todo
+		if err := tx.Select("id", "uuid").
+			Where("id = ? AND user_id = ?", id, userID).
+			First(&todo).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Todo{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if todo.UUID == nil {
+			return nil
+		}
+		// Sync doesn't reuse UUIDs of other users' tombstones, so an existing one is the user's own;
+		// user_id is left as is so that a tombstone never moves to another user
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "uuid"}},
+			DoUpdates: clause.AssignmentColumns([]string{"deleted_at"}),
+		}).Create(&model.TodoTombstone{
+			UserID:    userID,
+			UUID:      *todo.UUID,
+			DeletedAt: time.Now(),
+		}).Error
+	})
 }
 
 // UpdateOrder updates the positions of multiple todos
-func (r *TodoRepository) UpdateOrder(userID int64, updates []OrderUpdate) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+func (r *TodoRepository) UpdateOrder(ctx context.Context, userID int64, updates []OrderUpdate) error {
+	return database.ForUser(ctx, r.db, userID).Transaction(func(tx *gorm.DB) error {
 		for _, update := range updates {
 			result := tx.Model(&model.Todo{}).
 				Where("id = ? AND user_id = ?", update.ID, userID).
@@ -120,33 +160,313 @@ func (r *TodoRepository) UpdateOrder(userID int64, updates []OrderUpdate) error
 	})
 }
 
+// UpdateCategoryOrder updates the positions of todos within one category (nil for uncategorized).
+// Todos in other categories are skipped.
+func (r *TodoRepository) UpdateCategoryOrder(ctx context.Context, userID int64, categoryID *int64, updates []OrderUpdate) error {
+	return database.ForUser(ctx, r.db, userID).Transaction(func(tx *gorm.DB) error {
+		for _, update := range updates {
+			result := tx.Model(&model.Todo{}).
+				Where("id = ? AND user_id = ? AND "+database.DialectOf(tx).NullSafeEqual("category_id"), update.ID, userID, categoryID).
+				Update("category_position", update.Position)
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+}
+
+// MoveRelative places a todo directly before or after an anchor todo.
+// Todos from the new position on are shifted back by one. With inCategory the position within the
+// todo's category is changed, otherwise the global position.
+func (r *TodoRepository) MoveRelative(ctx context.Context, todo *model.Todo, anchorID int64, after, inCategory bool) error {
+	column := "position"
+	if inCategory {
+		column = "category_position"
+	}
+
+	return database.ForUser(ctx, r.db, todo.UserID).Transaction(func(tx *gorm.DB) error {
+		var anchorPosition int
+		if err := tx.Model(&model.Todo{}).
+			Where("id = ? AND user_id = ?", anchorID, todo.UserID).
+			Select("COALESCE(" + column + ", 0)").
+			Scan(&anchorPosition).Error; err != nil {
+			return err
+		}
+		target := anchorPosition
+		if after {
+			target++
+		}
+
+		// Shifting does not touch updated_at, as the shifted todos themselves did not change
+		shift := tx.Model(&model.Todo{}).
+			Where("user_id = ? AND id <> ? AND COALESCE("+column+", 0) >= ?", todo.UserID, todo.ID, target)
+		if inCategory {
+			shift = shift.Where(database.DialectOf(tx).NullSafeEqual("category_id"), todo.CategoryID)
+		}
+		if err := shift.UpdateColumn(column, gorm.Expr("COALESCE("+column+", 0) + 1")).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Todo{}).
+			Where("id = ? AND user_id = ?", todo.ID, todo.UserID).
+			Update(column, target).Error
+	})
+}
+
+// NextCategoryPosition returns the position after the last todo of a category (nil for uncategorized)
+func (r *TodoRepository) NextCategoryPosition(ctx context.Context, userID int64, categoryID *int64) (int, error) {
+	var maxPosition int
+	result := database.ForUser(ctx, r.db, userID).Model(&model.Todo{}).
+		Where("user_id = ? AND "+database.DialectOf(r.db).NullSafeEqual("category_id"), userID, categoryID).
+		Select("COALESCE(MAX(category_position), 0)").
+		Scan(&maxPosition)
+	return maxPosition + 1, result.Error
+}
+
+// BackfillCategoryPositions numbers todos within each category by their global position.
+// Only categories where no todo has a category position yet are filled, so it is safe to run repeatedly.
+func (r *TodoRepository) BackfillCategoryPositions(ctx context.Context) error {
+	db := database.AsSystem(ctx, r.db)
+	// existing is NULL when no todo of the category has a position yet
+	ranked := `(
+		SELECT id, ROW_NUMBER() OVER (
+			PARTITION BY user_id, category_id ORDER BY COALESCE(position, 0), created_at
+		) AS pos,
+		MAX(category_position) OVER (PARTITION BY user_id, category_id) AS existing
+		FROM todos
+	) ranked`
+
+	if database.DialectOf(db) == database.MySQL {
+		// MySQL has no UPDATE ... FROM
+		return db.Exec(`
+			UPDATE todos JOIN ` + ranked + ` ON todos.id = ranked.id
+			SET todos.category_position = ranked.pos
+			WHERE ranked.existing IS NULL`).Error
+	}
+	return db.Exec(`
+		UPDATE todos SET category_position = ranked.pos
+		FROM ` + ranked + `
+		WHERE todos.id = ranked.id
+		AND ranked.existing IS NULL`).Error
+}
+
+// BackfillCompletedAt marks todos with the completed status as completed, with their last update as the
+// completion time when none was recorded. It is safe to run repeatedly.
+func (r *TodoRepository) BackfillCompletedAt(ctx context.Context) error {
+	// UpdateColumns keeps updated_at, which the completion time is taken from
+	return database.AsSystem(ctx, r.db).Model(&model.Todo{}).
+		Where("status = ? AND (completed = ? OR completed_at IS NULL)", model.StatusCompleted, false).
+		UpdateColumns(map[string]any{
+			"completed":    true,
+			"completed_at": gorm.Expr("COALESCE(completed_at, updated_at)"),
+		}).Error
+}
+
 // Count returns the total number of todos for a user
-func (r *TodoRepository) Count(userID int64) (int64, error) {
+func (r *TodoRepository) Count(ctx context.Context, userID int64) (int64, error) {
 	var count int64
-	result := r.db.Model(&model.Todo{}).
+	result := database.ForUser(ctx, r.db, userID).Model(&model.Todo{}).
 		Where("user_id = ?", userID).
 		Count(&count)
 	return count, result.Error
 }
 
+// SaveWithinWIPLimit creates or updates a todo moving to in_progress unless the user already has limit
+// todos in progress, and returns whether it was saved along with that count. The user's row is locked
+// while counting, so concurrent moves to in_progress can't both pass the check.
+func (r *TodoRepository) SaveWithinWIPLimit(ctx context.Context, todo *model.Todo, limit int) (bool, int64, error) {
+	var saved bool
+	var count int64
+	err := database.ForUser(ctx, r.db, todo.UserID).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Model(&model.User{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", todo.UserID).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.Todo{}).
+			Where("user_id = ? AND status = ? AND id <> ?", todo.UserID, model.StatusInProgress, todo.ID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(limit) {
+			return nil
+		}
+
+		saved = true
+		return tx.Save(todo).Error
+	})
+	return saved, count, err
+}
+
+// CountByStatus returns the number of a user's todos with the given status
+func (r *TodoRepository) CountByStatus(ctx context.Context, userID int64, status model.Status) (int64, error) {
+	var count int64
+	result := database.ForUser(ctx, r.db, userID).Model(&model.Todo{}).
+		Where("user_id = ? AND status = ?", userID, status).
+		Count(&count)
+	return count, result.Error
+}
+
+// CountGroupedByStatus returns the number of a user's todos per status; statuses without todos are omitted
+func (r *TodoRepository) CountGroupedByStatus(ctx context.Context, userID int64) (map[model.Status]int64, error) {
+	var rows []struct {
+		Status model.Status
+		Count  int64
+	}
+	err := database.ForUser(ctx, r.db, userID).Model(&model.Todo{}).
+		Select("status, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[model.Status]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
 // ExistsByID checks if a todo exists for a specific user
-func (r *TodoRepository) ExistsByID(id, userID int64) (bool, error) {
+func (r *TodoRepository) ExistsByID(ctx context.Context, id, userID int64) (bool, error) {
 	var count int64
-	result := r.db.Model(&model.Todo{}).
+	result := database.ForUser(ctx, r.db, userID).Model(&model.Todo{}).
 		Where("id = ? AND user_id = ?", id, userID).
 		Count(&count)
 	return count > 0, result.Error
 }
 
 // ValidateCategoryOwnership checks if a category belongs to a user
-func (r *TodoRepository) ValidateCategoryOwnership(categoryID, userID int64) (bool, error) {
+func (r *TodoRepository) ValidateCategoryOwnership(ctx context.Context, categoryID, userID int64) (bool, error) {
 	var count int64
-	result := r.db.Model(&model.Category{}).
+	result := database.ForUser(ctx, r.db, userID).Model(&model.Category{}).
 		Where("id = ? AND user_id = ?", categoryID, userID).
 		Count(&count)
 	return count > 0, result.Error
 }
 
+// FindDueBetween retrieves incomplete todos whose due date falls within the given date range (inclusive)
+func (r *TodoRepository) FindDueBetween(ctx context.Context, userID int64, from, to time.Time) ([]model.Todo, error) {
+	var todos []model.Todo
+	result := database.ForUser(ctx, r.db, userID).
+		Preload("Category").
+		Where("user_id = ? AND completed = ? AND due_date >= ? AND due_date <= ?", userID, false, from, to).
+		Order("due_date ASC, priority DESC").
+		Find(&todos)
+	return todos, result.Error
+}
+
+// FindOverdue retrieves incomplete todos whose due date is before the given date
+func (r *TodoRepository) FindOverdue(ctx context.Context, userID int64, before time.Time) ([]model.Todo, error) {
+	var todos []model.Todo
+	result := database.ForUser(ctx, r.db, userID).
+		Preload("Category").
+		Where("user_id = ? AND completed = ? AND due_date < ?", userID, false, before).
+		Order("due_date ASC, priority DESC").
+		Find(&todos)
+	return todos, result.Error
+}
+
+// FindDueOnOrBefore retrieves incomplete todos whose due date is on or before the given date, including overdue ones
+func (r *TodoRepository) FindDueOnOrBefore(ctx context.Context, userID int64, date time.Time) ([]model.Todo, error) {
+	var todos []model.Todo
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND completed = ? AND due_date <= ?", userID, false, date).
+		Order("due_date ASC, id ASC").
+		Find(&todos)
+	return todos, result.Error
+}
+
+// UpdatePriority sets the priority of a todo without touching its other columns
+func (r *TodoRepository) UpdatePriority(ctx context.Context, id, userID int64, priority model.Priority) error {
+	return database.ForUser(ctx, r.db, userID).Model(&model.Todo{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("priority", priority).Error
+}
+
+// Snooze sets the due date of a todo and increments its snooze count in the same statement,
+// so concurrent snoozes each count
+func (r *TodoRepository) Snooze(ctx context.Context, id, userID int64, dueDate time.Time) error {
+	return database.ForUser(ctx, r.db, userID).Model(&model.Todo{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		UpdateColumns(map[string]any{
+			"due_date":     dueDate,
+			"snooze_count": gorm.Expr("snooze_count + 1"),
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+// FindCompletedBetween retrieves todos completed within the given time range [from, to)
+func (r *TodoRepository) FindCompletedBetween(ctx context.Context, userID int64, from, to time.Time) ([]model.Todo, error) {
+	var todos []model.Todo
+	result := database.ForUser(ctx, r.db, userID).
+		Preload("Category").
+		Where("user_id = ? AND completed = ? AND completed_at >= ? AND completed_at < ?", userID, true, from, to).
+		Order("completed_at ASC").
+		Find(&todos)
+	return todos, result.Error
+}
+
+// FindCompletedBefore retrieves todos completed before the given time, oldest first
+func (r *TodoRepository) FindCompletedBefore(ctx context.Context, userID int64, before time.Time) ([]model.Todo, error) {
+	var todos []model.Todo
+	// Todos completed before completed_at was recorded fall back to their last update
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ? AND completed = ? AND archived_at IS NULL AND COALESCE(completed_at, updated_at) < ?", userID, true, before).
+		Order("COALESCE(completed_at, updated_at) ASC").
+		Find(&todos)
+	return todos, result.Error
+}
+
+// ArchiveByIDs sets archived_at on the given todos of a user
+func (r *TodoRepository) ArchiveByIDs(ctx context.Context, ids []int64, userID int64, archivedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return database.ForUser(ctx, r.db, userID).Model(&model.Todo{}).
+		Where("id IN ? AND user_id = ? AND archived_at IS NULL", ids, userID).
+		Update("archived_at", archivedAt).Error
+}
+
+// FindRecent retrieves the user's most recently created todos with their category
+func (r *TodoRepository) FindRecent(ctx context.Context, userID int64, limit int) ([]model.Todo, error) {
+	var todos []model.Todo
+	result := database.ForUser(ctx, r.db, userID).
+		Preload("Category").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&todos)
+	return todos, result.Error
+}
+
+// FindRecentlyActive retrieves incomplete todos updated since the given time, most recent first
+func (r *TodoRepository) FindRecentlyActive(ctx context.Context, userID int64, since time.Time, limit int) ([]model.Todo, error) {
+	var todos []model.Todo
+	result := database.ForUser(ctx, r.db, userID).
+		Preload("Category").
+		Where("user_id = ? AND completed = ? AND updated_at >= ?", userID, false, since).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&todos)
+	return todos, result.Error
+}
+
+// CountByIDs returns how many of the given todo IDs belong to the user
+func (r *TodoRepository) CountByIDs(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	var count int64
+	result := database.ForUser(ctx, r.db, userID).Model(&model.Todo{}).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Count(&count)
+	return count, result.Error
+}
+
 // SearchInput represents the input for repository search operation
 type SearchInput struct {
 	UserID         int64
@@ -159,21 +479,232 @@ type SearchInput struct {
 	TagMode        string
 	DueDateFrom    *time.Time
 	DueDateTo      *time.Time
+	// CreatedFrom and UpdatedFrom are inclusive, CreatedBefore and UpdatedBefore exclusive
+	CreatedFrom   *time.Time
+	CreatedBefore *time.Time
+	UpdatedFrom   *time.Time
+	UpdatedBefore *time.Time
+	// Archived searches archived todos instead of the others
+	Archived bool
+	// FuzzyThreshold enables trigram matching of the query when greater than 0
+	FuzzyThreshold float64
 	SortBy         string
 	SortOrder      string
-	Page           int
-	PerPage        int
+	// Nulls places todos without a value for the sort field first or last (default: last)
+	Nulls   string
+	Page    int
+	PerPage int
 }
 
 // Search searches todos with filters and pagination
-func (r *TodoRepository) Search(input SearchInput) ([]model.Todo, int64, error) {
+func (r *TodoRepository) Search(ctx context.Context, input SearchInput) ([]model.Todo, int64, error) {
+	var todos []model.Todo
+	var total int64
+	err := r.withSearchSettings(ctx, input, func(db *gorm.DB) error {
+		query := r.applySearchFilters(db, input)
+
+		// Get total count before pagination
+		if err := query.Count(&total).Error; err != nil {
+			return err
+		}
+
+		// Apply sorting; fuzzy results are ranked by similarity unless a sort is requested
+		if r.fuzzy(input) && input.SortBy == "" {
+			columns := searchableTodoColumns()
+			similarities := make([]string, len(columns))
+			vars := make([]any, len(columns))
+			for i, column := range columns {
+				similarities[i] = "word_similarity(?, COALESCE(" + column + ", ''))"
+				vars[i] = input.Query
+			}
+			query = query.Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "GREATEST(" + strings.Join(similarities, ", ") + ") DESC, created_at DESC",
+				Vars:               vars,
+				WithoutParentheses: true,
+			}})
+		} else {
+			query = r.applySort(query, input.SortBy, input.SortOrder, input.Nulls)
+		}
+
+		// Apply pagination
+		offset := (input.Page - 1) * input.PerPage
+		query = query.Offset(offset).Limit(input.PerPage)
+
+		// Preload relations and fetch
+		return query.Preload("Category").Preload("Tags").Find(&todos).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return todos, total, nil
+}
+
+// SearchGroupCounts counts all todos matching the search filters per group, ignoring pagination.
+// Keys are status and priority names, category IDs ("" for uncategorized), or due buckets
+// relative to today (a date in the user's time zone).
+func (r *TodoRepository) SearchGroupCounts(ctx context.Context, input SearchInput, groupBy string, today time.Time) (map[string]int64, error) {
+	dialect := database.DialectOf(r.db)
+	var keyExpr clause.Expr
+	switch groupBy {
+	case model.TodoGroupByStatus:
+		keyExpr = clause.Expr{SQL: dialect.CastText("status")}
+	case model.TodoGroupByPriority:
+		keyExpr = clause.Expr{SQL: dialect.CastText("priority")}
+	case model.TodoGroupByCategory:
+		keyExpr = clause.Expr{SQL: "COALESCE(" + dialect.CastText("category_id") + ", '')"}
+	case model.TodoGroupByDueBucket:
+		// Date arithmetic differs between databases, so the bounds are computed here
+		day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+		keyExpr = clause.Expr{
+			SQL: "CASE WHEN due_date IS NULL THEN ? " +
+				"WHEN due_date < ? THEN ? " +
+				"WHEN due_date = ? THEN ? " +
+				"WHEN due_date <= ? THEN ? " +
+				"ELSE ? END",
+			Vars: []any{
+				model.DueBucketNoDueDate,
+				day, model.DueBucketOverdue,
+				day, model.DueBucketToday,
+				day.AddDate(0, 0, 7), model.DueBucketNext7Days,
+				model.DueBucketLater,
+			},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported group_by: %s", groupBy)
+	}
+
+	// group_key rather than key, which is a reserved word in MySQL
+	var rows []struct {
+		GroupKey string
+		Count    int64
+	}
+	err := r.withSearchSettings(ctx, input, func(db *gorm.DB) error {
+		return r.applySearchFilters(db, input).
+			Select("? AS group_key, COUNT(*) AS count", keyExpr).
+			Group("group_key").
+			Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		key := row.GroupKey
+		if groupBy == model.TodoGroupByStatus || groupBy == model.TodoGroupByPriority {
+			n, err := strconv.Atoi(row.GroupKey)
+			if err != nil {
+				return nil, err
+			}
+			if groupBy == model.TodoGroupByStatus {
+				key = model.Status(n).String()
+			} else {
+				key = model.Priority(n).String()
+			}
+		}
+		counts[key] = row.Count
+	}
+	return counts, nil
+}
+
+// SearchCount counts all todos matching the search filters
+func (r *TodoRepository) SearchCount(ctx context.Context, input SearchInput) (int64, error) {
+	var count int64
+	err := r.withSearchSettings(ctx, input, func(db *gorm.DB) error {
+		return r.applySearchFilters(db, input).Count(&count).Error
+	})
+	return count, err
+}
+
+// TagCount is the number of todos matching the search filters that have a tag
+type TagCount struct {
+	TagID int64
+	Name  string
+	Color *string
+	Count int64
+}
+
+// SearchTagCounts counts the todos matching the search filters per tag of the user.
+// Every tag is returned, ordered by name, even when no todo matches.
+func (r *TodoRepository) SearchTagCounts(ctx context.Context, input SearchInput) ([]TagCount, error) {
+	var rows []TagCount
+	err := r.withSearchSettings(ctx, input, func(db *gorm.DB) error {
+		matching := r.applySearchFilters(db, input).Select("todos.id")
+		return db.Table("tags").
+			Select("tags.id AS tag_id, tags.name, tags.color, COUNT(todo_tags.todo_id) AS count").
+			Joins("LEFT JOIN todo_tags ON todo_tags.tag_id = tags.id AND todo_tags.todo_id IN (?)", matching).
+			Where("tags.user_id = ?", input.UserID).
+			Group("tags.id, tags.name, tags.color").
+			Order("tags.name ASC").
+			Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// fuzzy reports whether the search matches by trigram similarity.
+// Databases without pg_trgm fall back to plain substring matching.
+func (r *TodoRepository) fuzzy(input SearchInput) bool {
+	return input.Query != "" && input.FuzzyThreshold > 0 && database.DialectOf(r.db).SupportsTrigram()
+}
+
+// searchableTodoColumns returns the columns matched by text search.
+// Encrypted descriptions can't be matched in SQL (the ciphertext would match instead),
+// so only titles are searched while field encryption is enabled.
+func searchableTodoColumns() []string {
+	if encryption.Enabled() {
+		return []string{"title"}
+	}
+	return []string{"title", "description"}
+}
+
+// withSearchSettings runs fn with the session settings the search needs.
+// The <% operator of fuzzy matching compares against a setting, so it is set for one transaction only.
+func (r *TodoRepository) withSearchSettings(ctx context.Context, input SearchInput, fn func(db *gorm.DB) error) error {
+	if !r.fuzzy(input) {
+		return fn(database.ForUser(ctx, r.db, input.UserID))
+	}
+	return database.ForUser(ctx, r.db, input.UserID).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)",
+			strconv.FormatFloat(input.FuzzyThreshold, 'f', -1, 64)).Error; err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// applySearchFilters scopes the query to the user and applies the search filters
+func (r *TodoRepository) applySearchFilters(db *gorm.DB, input SearchInput) *gorm.DB {
 	// Base query with user scope (required)
-	query := r.db.Model(&model.Todo{}).Where("user_id = ?", input.UserID)
+	query := db.Model(&model.Todo{}).Where("user_id = ?", input.UserID)
 
-	// Text search (ILIKE for case-insensitive)
+	// Archived todos are only found when asked for
+	if input.Archived {
+		query = query.Where("archived_at IS NOT NULL")
+	} else {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	// Text search (case-insensitive)
 	if input.Query != "" {
-		searchPattern := "%" + input.Query + "%"
-		query = query.Where("(title ILIKE ? OR description ILIKE ?)", searchPattern, searchPattern)
+		dialect := database.DialectOf(db)
+		columns := searchableTodoColumns()
+		var conditions []string
+		var args []any
+		for _, column := range columns {
+			conditions = append(conditions, dialect.ILike(column))
+			args = append(args, "%"+input.Query+"%")
+		}
+		if r.fuzzy(input) {
+			// Trigram word similarity also matches misspellings (uses the gin_trgm_ops indexes)
+			for _, column := range columns {
+				conditions = append(conditions, "? <% "+column)
+				args = append(args, input.Query)
+			}
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
 	}
 
 	// Status filter (multiple)
@@ -214,31 +745,26 @@ func (r *TodoRepository) Search(input SearchInput) ([]model.Todo, int64, error)
 		query = query.Where("due_date <= ?", input.DueDateTo)
 	}
 
-	// Get total count before pagination
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	// Created and updated time range filters
+	if input.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *input.CreatedFrom)
 	}
-
-	// Apply sorting
-	query = r.applySort(query, input.SortBy, input.SortOrder)
-
-	// Apply pagination
-	offset := (input.Page - 1) * input.PerPage
-	query = query.Offset(offset).Limit(input.PerPage)
-
-	// Preload relations and fetch
-	var todos []model.Todo
-	if err := query.Preload("Category").Preload("Tags").Find(&todos).Error; err != nil {
-		return nil, 0, err
+	if input.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *input.CreatedBefore)
+	}
+	if input.UpdatedFrom != nil {
+		query = query.Where("updated_at >= ?", *input.UpdatedFrom)
+	}
+	if input.UpdatedBefore != nil {
+		query = query.Where("updated_at < ?", *input.UpdatedBefore)
 	}
 
-	return todos, total, nil
+	return query
 }
 
 // ReplaceTags replaces all tags for a todo
-func (r *TodoRepository) ReplaceTags(todoID int64, tagIDs []int64) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+func (r *TodoRepository) ReplaceTags(ctx context.Context, todoID int64, tagIDs []int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Delete existing tag associations
 		if err := tx.Exec("DELETE FROM todo_tags WHERE todo_id = ?", todoID).Error; err != nil {
 			return err
@@ -256,7 +782,7 @@ func (r *TodoRepository) ReplaceTags(todoID int64, tagIDs []int64) error {
 }
 
 // applySort applies sorting to the query
-func (r *TodoRepository) applySort(query *gorm.DB, sortBy, sortOrder string) *gorm.DB {
+func (r *TodoRepository) applySort(query *gorm.DB, sortBy, sortOrder, nulls string) *gorm.DB {
 	// Default sort field
 	if sortBy == "" {
 		sortBy = "created_at"
@@ -268,13 +794,25 @@ func (r *TodoRepository) applySort(query *gorm.DB, sortBy, sortOrder string) *go
 		sortOrder = "DESC"
 	}
 
-	// Special handling for due_date: NULL values should be last
-	if sortBy == "due_date" {
-		if sortOrder == "ASC" {
-			return query.Order("CASE WHEN due_date IS NULL THEN 1 ELSE 0 END, due_date ASC")
-		}
-		return query.Order("CASE WHEN due_date IS NULL THEN 1 ELSE 0 END, due_date DESC")
+	// Category positions are only comparable within a category, so todos are kept together by category
+	if sortBy == "category_position" {
+		return query.Order(fmt.Sprintf("%s, category_id ASC, %s, category_position %s",
+			nullsOrder("category_id", nulls), nullsOrder("category_position", nulls), sortOrder))
+	}
+
+	// Nullable fields: todos without a value are placed by nulls rather than by the database's default
+	if sortBy == "due_date" || sortBy == "position" {
+		return query.Order(fmt.Sprintf("%s, %s %s", nullsOrder(sortBy, nulls), sortBy, sortOrder))
 	}
 
 	return query.Order(fmt.Sprintf("%s %s", sortBy, sortOrder))
 }
+
+// nullsOrder returns the ORDER BY term placing NULLs of column first or last (the default).
+// MySQL does not support NULLS FIRST/LAST, so a CASE expression is used on every database.
+func nullsOrder(column, nulls string) string {
+	if nulls == "first" {
+		return fmt.Sprintf("CASE WHEN %s IS NULL THEN 0 ELSE 1 END", column)
+	}
+	return fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END", column)
+}
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"context"
+
 	"todo-api/internal/model"
+	"todo-api/pkg/database"
 
 	"gorm.io/gorm"
 )
@@ -17,23 +20,44 @@ func NewTodoHistoryRepository(db *gorm.DB) *TodoHistoryRepository {
 }
 
 // Create creates a new todo history record
-func (r *TodoHistoryRepository) Create(history *model.TodoHistory) error {
-	return r.db.Create(history).Error
+func (r *TodoHistoryRepository) Create(ctx context.Context, history *model.TodoHistory) error {
+	return database.ForUser(ctx, r.db, history.UserID).Create(history).Error
+}
+
+// HasEscalation checks if a todo was already escalated to one of the given priorities
+func (r *TodoHistoryRepository) HasEscalation(ctx context.Context, todoID, userID int64, priorities []string) (bool, error) {
+	var count int64
+	result := database.ForUser(ctx, r.db, userID).Model(&model.TodoHistory{}).
+		Where("todo_id = ? AND action = ?", todoID, model.ActionPriorityEscalated).
+		Where("changes->'priority'->>1 IN ?", priorities).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// FindAllByUserID retrieves all history records created by a user, oldest first
+func (r *TodoHistoryRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.TodoHistory, error) {
+	var histories []model.TodoHistory
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&histories)
+	return histories, result.Error
 }
 
 // FindByTodoID retrieves histories for a specific todo with pagination
-func (r *TodoHistoryRepository) FindByTodoID(todoID int64, page, perPage int) ([]model.TodoHistory, int64, error) {
+func (r *TodoHistoryRepository) FindByTodoID(ctx context.Context, todoID, userID int64, page, perPage int) ([]model.TodoHistory, int64, error) {
+	db := database.ForUser(ctx, r.db, userID)
 	var histories []model.TodoHistory
 	var total int64
 
 	// Count total
-	if err := r.db.Model(&model.TodoHistory{}).Where("todo_id = ?", todoID).Count(&total).Error; err != nil {
+	if err := db.Model(&model.TodoHistory{}).Where("todo_id = ?", todoID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated results
 	offset := (page - 1) * perPage
-	result := r.db.
+	result := db.
 		Where("todo_id = ?", todoID).
 		Order("created_at DESC").
 		Offset(offset).
@@ -48,18 +72,19 @@ func (r *TodoHistoryRepository) FindByTodoID(todoID int64, page, perPage int) ([
 }
 
 // FindByTodoIDWithUser retrieves histories with preloaded user information
-func (r *TodoHistoryRepository) FindByTodoIDWithUser(todoID int64, page, perPage int) ([]model.TodoHistory, int64, error) {
+func (r *TodoHistoryRepository) FindByTodoIDWithUser(ctx context.Context, todoID, userID int64, page, perPage int) ([]model.TodoHistory, int64, error) {
+	db := database.ForUser(ctx, r.db, userID)
 	var histories []model.TodoHistory
 	var total int64
 
 	// Count total
-	if err := r.db.Model(&model.TodoHistory{}).Where("todo_id = ?", todoID).Count(&total).Error; err != nil {
+	if err := db.Model(&model.TodoHistory{}).Where("todo_id = ?", todoID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated results with user preload
 	offset := (page - 1) * perPage
-	result := r.db.
+	result := db.
 		Preload("User").
 		Where("todo_id = ?", todoID).
 		Order("created_at DESC").
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// TodoLinkRepository handles database operations for links between todos
+type TodoLinkRepository struct {
+	db *gorm.DB
+}
+
+// NewTodoLinkRepository creates a new TodoLinkRepository
+func NewTodoLinkRepository(db *gorm.DB) *TodoLinkRepository {
+	return &TodoLinkRepository{db: db}
+}
+
+// FindAllByUserID retrieves all links between a user's todos, oldest first
+func (r *TodoLinkRepository) FindAllByUserID(ctx context.Context, userID int64) ([]model.TodoLink, error) {
+	var links []model.TodoLink
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		Order("created_at ASC, id ASC").
+		Find(&links)
+	return links, result.Error
+}
+
+// FindBySourceTodoID retrieves the links from a todo with their target todos, oldest first
+func (r *TodoLinkRepository) FindBySourceTodoID(ctx context.Context, todoID, userID int64) ([]model.TodoLink, error) {
+	var links []model.TodoLink
+	result := database.ForUser(ctx, r.db, userID).
+		Preload("TargetTodo").
+		Where("source_todo_id = ? AND user_id = ?", todoID, userID).
+		Order("created_at ASC, id ASC").
+		Find(&links)
+	return links, result.Error
+}
+
+// FindByTargetTodoID retrieves the links to a todo with their source todos, oldest first
+func (r *TodoLinkRepository) FindByTargetTodoID(ctx context.Context, todoID, userID int64) ([]model.TodoLink, error) {
+	var links []model.TodoLink
+	result := database.ForUser(ctx, r.db, userID).
+		Preload("SourceTodo").
+		Where("target_todo_id = ? AND user_id = ?", todoID, userID).
+		Order("created_at ASC, id ASC").
+		Find(&links)
+	return links, result.Error
+}
+
+// FindByID retrieves a link by ID for a specific user
+func (r *TodoLinkRepository) FindByID(ctx context.Context, id, userID int64) (*model.TodoLink, error) {
+	var link model.TodoLink
+	result := database.ForUser(ctx, r.db, userID).
+		Preload("SourceTodo").
+		Preload("TargetTodo").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&link)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &link, nil
+}
+
+// ExistsBetween checks if a link of the given type exists between two todos in either direction.
+// excludeID skips a link, for checking a link that is being changed.
+func (r *TodoLinkRepository) ExistsBetween(ctx context.Context, todoID, otherTodoID, userID int64, linkType model.LinkType, excludeID *int64) (bool, error) {
+	var count int64
+	query := database.ForUser(ctx, r.db, userID).Model(&model.TodoLink{}).
+		Where("((source_todo_id = ? AND target_todo_id = ?) OR (source_todo_id = ? AND target_todo_id = ?)) AND link_type = ?",
+			todoID, otherTodoID, otherTodoID, todoID, linkType)
+	if excludeID != nil {
+		query = query.Where("id <> ?", *excludeID)
+	}
+	result := query.Count(&count)
+	return count > 0, result.Error
+}
+
+// Create creates a new link
+func (r *TodoLinkRepository) Create(ctx context.Context, link *model.TodoLink) error {
+	return database.ForUser(ctx, r.db, link.UserID).Create(link).Error
+}
+
+// UpdateLinkType changes the type of a link
+func (r *TodoLinkRepository) UpdateLinkType(ctx context.Context, link *model.TodoLink, linkType model.LinkType) error {
+	return database.ForUser(ctx, r.db, link.UserID).Model(link).Update("link_type", linkType).Error
+}
+
+// Delete deletes a link by ID for a specific user
+func (r *TodoLinkRepository) Delete(ctx context.Context, id, userID int64) error {
+	result := database.ForUser(ctx, r.db, userID).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&model.TodoLink{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
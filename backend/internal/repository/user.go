@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"todo-api/internal/model"
 
 	"gorm.io/gorm"
@@ -17,9 +19,9 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 }
 
 // FindByEmail finds a user by email address
-func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	result := r.db.Where("email = ?", email).First(&user)
+	result := r.db.WithContext(ctx).Where("email = ?", email).First(&user)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -27,14 +29,14 @@ func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
 }
 
 // Create creates a new user
-func (r *UserRepository) Create(user *model.User) error {
-	return r.db.Create(user).Error
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
 }
 
 // FindByID finds a user by ID
-func (r *UserRepository) FindByID(id int64) (*model.User, error) {
+func (r *UserRepository) FindByID(ctx context.Context, id int64) (*model.User, error) {
 	var user model.User
-	result := r.db.First(&user, id)
+	result := r.db.WithContext(ctx).First(&user, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -42,8 +44,13 @@ func (r *UserRepository) FindByID(id int64) (*model.User, error) {
 }
 
 // ExistsByEmail checks if a user with the given email exists
-func (r *UserRepository) ExistsByEmail(email string) (bool, error) {
+func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	var count int64
-	result := r.db.Model(&model.User{}).Where("email = ?", email).Count(&count)
+	result := r.db.WithContext(ctx).Model(&model.User{}).Where("email = ?", email).Count(&count)
 	return count > 0, result.Error
 }
+
+// UpdateAPIDailyQuota sets the user's daily API quota; nil falls back to API_DAILY_QUOTA
+func (r *UserRepository) UpdateAPIDailyQuota(ctx context.Context, id int64, quota *int) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).UpdateColumn("api_daily_quota", quota).Error
+}
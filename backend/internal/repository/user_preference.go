@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/model"
+	"todo-api/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// UserPreferenceRepository handles database operations for user preferences
+type UserPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewUserPreferenceRepository creates a new UserPreferenceRepository
+func NewUserPreferenceRepository(db *gorm.DB) *UserPreferenceRepository {
+	return &UserPreferenceRepository{db: db}
+}
+
+// FindByUserID retrieves the preference record for a user
+func (r *UserPreferenceRepository) FindByUserID(ctx context.Context, userID int64) (*model.UserPreference, error) {
+	var pref model.UserPreference
+	result := database.ForUser(ctx, r.db, userID).
+		Where("user_id = ?", userID).
+		First(&pref)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &pref, nil
+}
+
+// FindOrDefault retrieves the preference record for a user, or unsaved defaults if none exists
+func (r *UserPreferenceRepository) FindOrDefault(ctx context.Context, userID int64) (*model.UserPreference, error) {
+	pref, err := r.FindByUserID(ctx, userID)
+	if err == gorm.ErrRecordNotFound {
+		return model.NewDefaultUserPreference(userID), nil
+	}
+	return pref, err
+}
+
+// Save creates or updates a preference record
+func (r *UserPreferenceRepository) Save(ctx context.Context, pref *model.UserPreference) error {
+	return database.ForUser(ctx, r.db, pref.UserID).Save(pref).Error
+}
+
+// FindDigestSubscribers retrieves preferences of all users who opted in to digests
+func (r *UserPreferenceRepository) FindDigestSubscribers(ctx context.Context) ([]model.UserPreference, error) {
+	var prefs []model.UserPreference
+	result := database.AsSystem(ctx, r.db).
+		Preload("User").
+		Where("digest_frequency IN ?", []model.DigestFrequency{model.DigestFrequencyDaily, model.DigestFrequencyWeekly}).
+		Order("id ASC").
+		Find(&prefs)
+	return prefs, result.Error
+}
+
+// MarkDigestSent records the time the last digest was sent
+func (r *UserPreferenceRepository) MarkDigestSent(ctx context.Context, id int64, sentAt time.Time) error {
+	return database.AsSystem(ctx, r.db).Model(&model.UserPreference{}).
+		Where("id = ?", id).
+		Update("digest_last_sent_at", sentAt).Error
+}
+
+// FindRetentionSubscribers retrieves preferences of all users with at least one retention rule enabled
+func (r *UserPreferenceRepository) FindRetentionSubscribers(ctx context.Context) ([]model.UserPreference, error) {
+	var prefs []model.UserPreference
+	result := database.AsSystem(ctx, r.db).
+		Where("purge_trashed_notes_after_days > 0 OR archive_notes_after_days > 0 OR archive_completed_todos_after_days > 0").
+		Order("id ASC").
+		Find(&prefs)
+	return prefs, result.Error
+}
+
+// MarkRetentionRun records the time retention rules were last applied
+func (r *UserPreferenceRepository) MarkRetentionRun(ctx context.Context, id int64, ranAt time.Time) error {
+	return database.AsSystem(ctx, r.db).Model(&model.UserPreference{}).
+		Where("id = ?", id).
+		Update("retention_last_run_at", ranAt).Error
+}
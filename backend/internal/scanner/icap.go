@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// icapChunkSize is the size of each chunk of the encapsulated response body
+const icapChunkSize = 64 * 1024
+
+// icapDefaultPort is the standard ICAP port
+const icapDefaultPort = "1344"
+
+// ICAPScanner scans content with an ICAP server (e.g. c-icap, McAfee Web Gateway) using RESPMOD
+type ICAPScanner struct {
+	url     *url.URL
+	timeout time.Duration
+}
+
+// NewICAPScanner creates a new ICAPScanner for a service URL such as icap://host:1344/avscan
+func NewICAPScanner(rawURL string, timeout time.Duration) (*ICAPScanner, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "icap" || u.Host == "" {
+		return nil, fmt.Errorf("invalid ICAP URL %q", rawURL)
+	}
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), icapDefaultPort)
+	}
+	return &ICAPScanner{url: u, timeout: timeout}, nil
+}
+
+// Name returns the backend identifier
+func (s *ICAPScanner) Name() string {
+	return BackendICAP
+}
+
+// Scan sends the content as an encapsulated HTTP response and interprets the ICAP verdict.
+// 204 means the content is unmodified (clean); infection headers on a 200 mean it was blocked.
+func (s *ICAPScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.url.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ICAP server: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	resHeader := "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nTransfer-Encoding: chunked\r\n\r\n"
+
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "RESPMOD %s ICAP/1.0\r\n", s.url.String())
+	fmt.Fprintf(w, "Host: %s\r\n", s.url.Host)
+	fmt.Fprintf(w, "Allow: 204\r\n")
+	fmt.Fprintf(w, "Connection: close\r\n")
+	fmt.Fprintf(w, "Encapsulated: res-hdr=0, res-body=%d\r\n\r\n", len(resHeader))
+	w.WriteString(resHeader)
+
+	buf := make([]byte, icapChunkSize)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			fmt.Fprintf(w, "%x\r\n", n)
+			w.Write(buf[:n])
+			w.WriteString("\r\n")
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read content: %w", readErr)
+		}
+	}
+	w.WriteString("0\r\n\r\n")
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	tp := textproto.NewReader(bufio.NewReader(conn))
+	statusLine, err := tp.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status: %w", err)
+	}
+	headers, err := tp.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read headers: %w", err)
+	}
+
+	return parseICAPResponse(statusLine, headers)
+}
+
+// parseICAPResponse interprets the ICAP status line and infection headers
+func parseICAPResponse(statusLine string, headers textproto.MIMEHeader) (*Result, error) {
+	parts := strings.SplitN(statusLine, " ", 3)
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "ICAP/") {
+		return nil, fmt.Errorf("malformed ICAP status line: %q", statusLine)
+	}
+	code, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ICAP status code: %q", statusLine)
+	}
+
+	switch code {
+	case 204:
+		return &Result{}, nil
+	case 200:
+		if threat := icapThreat(headers); threat != "" {
+			return &Result{Infected: true, Signature: threat}, nil
+		}
+		return &Result{}, nil
+	default:
+		return nil, fmt.Errorf("ICAP server error: %s", statusLine)
+	}
+}
+
+// icapThreat extracts the threat name from common vendor infection headers
+func icapThreat(headers textproto.MIMEHeader) string {
+	// X-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;
+	if found := headers.Get("X-Infection-Found"); found != "" {
+		for _, field := range strings.Split(found, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+			if ok && strings.EqualFold(key, "Threat") {
+				return value
+			}
+		}
+		return "unknown"
+	}
+	for _, name := range []string{"X-Virus-ID", "X-Violations-Found"} {
+		if value := headers.Get(name); value != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
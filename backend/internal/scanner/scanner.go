@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	appconfig "todo-api/internal/config"
+)
+
+// Backend names accepted by SCANNER_BACKEND
+const (
+	BackendNone   = "none"
+	BackendClamAV = "clamav"
+	BackendICAP   = "icap"
+)
+
+// Result represents the outcome of a scan
+type Result struct {
+	Infected  bool
+	Signature string
+}
+
+// Scanner defines the interface for antivirus backends
+type Scanner interface {
+	// Name returns the backend identifier
+	Name() string
+	// Scan reads the content and reports whether it is infected
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// New returns the scanner selected by configuration, or nil if scanning is disabled
+func New(cfg *appconfig.ScannerConfig) (Scanner, error) {
+	switch cfg.Backend {
+	case "", BackendNone:
+		return nil, nil
+	case BackendClamAV:
+		return NewClamAVScanner(cfg.ClamAVAddress, cfg.Timeout), nil
+	case BackendICAP:
+		sc, err := NewICAPScanner(cfg.ICAPURL, cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		return sc, nil
+	default:
+		return nil, fmt.Errorf("unknown scanner backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,184 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appconfig "todo-api/internal/config"
+)
+
+// serveOnce accepts a single connection on a local listener and hands it to handle
+func serveOnce(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}()
+	return ln.Addr().String()
+}
+
+// readClamAVStream reads a zINSTREAM command and returns the streamed content
+func readClamAVStream(conn net.Conn) ([]byte, error) {
+	r := bufio.NewReader(conn)
+	if _, err := r.ReadString(0); err != nil {
+		return nil, err
+	}
+	var content bytes.Buffer
+	size := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, size); err != nil {
+			return nil, err
+		}
+		n := binary.BigEndian.Uint32(size)
+		if n == 0 {
+			return content.Bytes(), nil
+		}
+		if _, err := io.CopyN(&content, r, int64(n)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	sc, err := New(&appconfig.ScannerConfig{Backend: ""})
+	require.NoError(t, err)
+	assert.Nil(t, sc)
+
+	sc, err = New(&appconfig.ScannerConfig{Backend: BackendNone})
+	require.NoError(t, err)
+	assert.Nil(t, sc)
+
+	sc, err = New(&appconfig.ScannerConfig{Backend: BackendClamAV, ClamAVAddress: "localhost:3310"})
+	require.NoError(t, err)
+	assert.Equal(t, BackendClamAV, sc.Name())
+
+	sc, err = New(&appconfig.ScannerConfig{Backend: BackendICAP, ICAPURL: "icap://localhost/avscan"})
+	require.NoError(t, err)
+	assert.Equal(t, BackendICAP, sc.Name())
+	assert.Equal(t, "localhost:1344", sc.(*ICAPScanner).url.Host)
+
+	_, err = New(&appconfig.ScannerConfig{Backend: BackendICAP, ICAPURL: "http://localhost/avscan"})
+	assert.Error(t, err)
+
+	_, err = New(&appconfig.ScannerConfig{Backend: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestParseClamAVReply(t *testing.T) {
+	result, err := parseClamAVReply("stream: OK")
+	require.NoError(t, err)
+	assert.False(t, result.Infected)
+
+	result, err = parseClamAVReply("stream: Eicar-Signature FOUND")
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Signature", result.Signature)
+
+	_, err = parseClamAVReply("INSTREAM size limit exceeded. ERROR")
+	assert.Error(t, err)
+}
+
+func TestClamAVScanner_Scan(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), clamAVChunkSize+10)
+
+	received := make(chan []byte, 1)
+	addr := serveOnce(t, func(conn net.Conn) {
+		streamed, err := readClamAVStream(conn)
+		if err != nil {
+			return
+		}
+		received <- streamed
+		conn.Write([]byte("stream: Eicar-Signature FOUND\x00"))
+	})
+
+	result, err := NewClamAVScanner(addr, 5*time.Second).Scan(context.Background(), bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Signature", result.Signature)
+	assert.Equal(t, content, <-received)
+}
+
+func TestClamAVScanner_ConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	_, err = NewClamAVScanner(addr, time.Second).Scan(context.Background(), strings.NewReader("content"))
+	assert.Error(t, err)
+}
+
+func TestParseICAPResponse(t *testing.T) {
+	result, err := parseICAPResponse("ICAP/1.0 204 No Content", textproto.MIMEHeader{})
+	require.NoError(t, err)
+	assert.False(t, result.Infected)
+
+	result, err = parseICAPResponse("ICAP/1.0 200 OK", textproto.MIMEHeader{})
+	require.NoError(t, err)
+	assert.False(t, result.Infected)
+
+	result, err = parseICAPResponse("ICAP/1.0 200 OK", textproto.MIMEHeader{
+		"X-Infection-Found": {"Type=0; Resolution=2; Threat=Eicar-Test-Signature;"},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", result.Signature)
+
+	result, err = parseICAPResponse("ICAP/1.0 200 OK", textproto.MIMEHeader{"X-Virus-Id": {"EICAR"}})
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "EICAR", result.Signature)
+
+	_, err = parseICAPResponse("ICAP/1.0 500 Server Error", textproto.MIMEHeader{})
+	assert.Error(t, err)
+
+	_, err = parseICAPResponse("HTTP/1.1 200 OK", textproto.MIMEHeader{})
+	assert.Error(t, err)
+}
+
+func TestICAPScanner_Scan(t *testing.T) {
+	received := make(chan string, 1)
+	addr := serveOnce(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		var buf strings.Builder
+		for !strings.HasSuffix(buf.String(), "0\r\n\r\n") {
+			line, err := r.ReadString('\n')
+			buf.WriteString(line)
+			if err != nil {
+				return
+			}
+		}
+		received <- buf.String()
+		conn.Write([]byte("ICAP/1.0 200 OK\r\nX-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;\r\n\r\n"))
+	})
+
+	sc, err := NewICAPScanner("icap://"+addr+"/avscan", 5*time.Second)
+	require.NoError(t, err)
+
+	result, err := sc.Scan(context.Background(), strings.NewReader("infected content"))
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", result.Signature)
+	request := <-received
+	assert.True(t, strings.HasPrefix(request, "RESPMOD icap://"+addr+"/avscan ICAP/1.0\r\n"))
+	assert.Contains(t, request, "\r\n10\r\ninfected content\r\n0\r\n\r\n")
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/pkg/database"
+)
+
+// RegisterCallbacks queues an index update for each create, update, or delete of a searchable model made
+// through db. The update is queued on the connection of the write, so it commits or rolls back with the
+// write's transaction; service.SearchSyncService.Flush sends the row's document once committed, built from
+// the row as it is then. Queuing never fails the write itself. Writes the callbacks cannot see (writes of
+// several rows, or by conditions other than the ID) are reconciled by the periodic sync job and when searching.
+func RegisterCallbacks(db *gorm.DB) error {
+	queue := func(tx *gorm.DB) {
+		if tx.Error != nil {
+			return
+		}
+		updates := updatesOf(tx)
+		if len(updates) == 0 {
+			return
+		}
+		// A new statement, as sessions derived from tx would still carry the write's table and conditions
+		queueDB := tx.Session(&gorm.Session{NewDB: true}).Model(&model.PendingSearchUpdate{})
+		if err := repository.NewSearchRepository(queueDB).QueueUpdates(tx.Statement.Context, updates); err != nil {
+			log.Warn().Err(err).Msg("search.RegisterCallbacks: failed to queue search index update")
+		}
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("search:queue", queue); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("search:queue", queue); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("search:queue", queue)
+}
+
+// updatesOf returns the index update of a write of a searchable model, which targets the row with the ID
+// of the model value or of an "id = ?" condition (see database.WrittenID). Other writes yield nothing.
+func updatesOf(tx *gorm.DB) []model.PendingSearchUpdate {
+	var docType string
+	switch tx.Statement.Model.(type) {
+	case *model.Todo:
+		docType = TypeTodo
+	case *model.Comment:
+		docType = TypeComment
+	case *model.Category:
+		docType = TypeCategory
+	case *model.Tag:
+		docType = TypeTag
+	default:
+		return nil
+	}
+
+	written, ok := database.WrittenID(tx)
+	if !ok {
+		return nil
+	}
+	id, ok := written.(int64)
+	if !ok || id == 0 {
+		return nil
+	}
+	return []model.PendingSearchUpdate{{DocType: docType, DocID: id}}
+}
+
+// Documents returns the current documents of the rows of one type with the given IDs, and the IDs of the
+// rows that no longer exist (or are soft-deleted) and have to be removed from the index
+func Documents(ctx context.Context, repo *repository.SearchRepository, docType string, ids []int64) ([]Document, []int64, error) {
+	var docs []Document
+	var err error
+	switch docType {
+	case TypeTodo:
+		docs, err = documentsByIDs(ctx, ids, repo.FindAllTodosByIDs, TodoDocument)
+	case TypeComment:
+		docs, err = documentsByIDs(ctx, ids, repo.FindAllCommentsByIDs, CommentDocument)
+	case TypeCategory:
+		docs, err = documentsByIDs(ctx, ids, repo.FindAllCategoriesByIDs, CategoryDocument)
+	case TypeTag:
+		docs, err = documentsByIDs(ctx, ids, repo.FindAllTagsByIDs, TagDocument)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := make(map[int64]bool, len(docs))
+	for _, doc := range docs {
+		found[doc.ID] = true
+	}
+	var gone []int64
+	for _, id := range ids {
+		if !found[id] {
+			gone = append(gone, id)
+			found[id] = true
+		}
+	}
+	return docs, gone, nil
+}
+
+// documentsByIDs returns the documents of the rows returned by find
+func documentsByIDs[T any](
+	ctx context.Context,
+	ids []int64,
+	find func(ctx context.Context, ids []int64) ([]T, error),
+	toDocument func(*T) Document,
+) ([]Document, error) {
+	rows, err := find(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]Document, len(rows))
+	for i := range rows {
+		docs[i] = toDocument(&rows[i])
+	}
+	return docs, nil
+}
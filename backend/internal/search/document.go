@@ -0,0 +1,126 @@
+package search
+
+import (
+	"todo-api/internal/encryption"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/pkg/util"
+)
+
+// todoHits converts todos to hits
+func todoHits(todos []model.Todo) []Hit {
+	hits := make([]Hit, len(todos))
+	for i, t := range todos {
+		hits[i] = Hit{
+			Type:      TypeTodo,
+			ID:        t.ID,
+			Title:     t.Title,
+			Body:      t.Description,
+			UpdatedAt: t.UpdatedAt,
+		}
+	}
+	return hits
+}
+
+// commentHits converts comments to hits titled with their todo
+func commentHits(rows []repository.CommentSearchRow) []Hit {
+	hits := make([]Hit, len(rows))
+	for i, r := range rows {
+		content := r.Content
+		todoID := r.CommentableID
+		hits[i] = Hit{
+			Type:      TypeComment,
+			ID:        r.ID,
+			Title:     r.TodoTitle,
+			Body:      &content,
+			TodoID:    &todoID,
+			UpdatedAt: r.UpdatedAt,
+		}
+	}
+	return hits
+}
+
+// categoryHits converts categories to hits
+func categoryHits(categories []model.Category) []Hit {
+	hits := make([]Hit, len(categories))
+	for i, c := range categories {
+		color := c.Color
+		hits[i] = Hit{
+			Type:      TypeCategory,
+			ID:        c.ID,
+			Title:     c.Name,
+			Color:     &color,
+			UpdatedAt: c.UpdatedAt,
+		}
+	}
+	return hits
+}
+
+// tagHits converts tags to hits
+func tagHits(tags []model.Tag) []Hit {
+	hits := make([]Hit, len(tags))
+	for i, t := range tags {
+		hits[i] = Hit{
+			Type:      TypeTag,
+			ID:        t.ID,
+			Title:     t.Name,
+			Color:     t.Color,
+			UpdatedAt: t.UpdatedAt,
+		}
+	}
+	return hits
+}
+
+// TodoDocument returns the indexed form of a todo.
+// The description is left out while field encryption is enabled, so the index holds no plaintext copy of it.
+func TodoDocument(t *model.Todo) Document {
+	doc := Document{
+		Type:      TypeTodo,
+		ID:        t.ID,
+		UserID:    t.UserID,
+		Title:     t.Title,
+		UpdatedAt: t.UpdatedAt,
+	}
+	if !encryption.Enabled() {
+		doc.Body = util.DerefString(t.Description, "")
+	}
+	return doc
+}
+
+// CommentDocument returns the indexed form of a comment.
+// Only the author can comment on a todo, so the author is also the owner the document is scoped to.
+// The content is left out while field encryption is enabled, as for todo descriptions.
+func CommentDocument(c *model.Comment) Document {
+	doc := Document{
+		Type:      TypeComment,
+		ID:        c.ID,
+		UserID:    c.UserID,
+		UpdatedAt: c.UpdatedAt,
+	}
+	if !encryption.Enabled() {
+		doc.Body = c.Content
+	}
+	return doc
+}
+
+// CategoryDocument returns the indexed form of a category
+func CategoryDocument(c *model.Category) Document {
+	return Document{
+		Type:      TypeCategory,
+		ID:        c.ID,
+		UserID:    c.UserID,
+		Title:     c.Name,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// TagDocument returns the indexed form of a tag
+func TagDocument(t *model.Tag) Document {
+	return Document{
+		Type:      TypeTag,
+		ID:        t.ID,
+		UserID:    t.UserID,
+		Title:     t.Name,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
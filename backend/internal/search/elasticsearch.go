@@ -0,0 +1,355 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+
+	appconfig "todo-api/internal/config"
+	"todo-api/internal/repository"
+)
+
+// elasticsearchMapping defines the fields stored for each document
+const elasticsearchMapping = `{
+  "mappings": {
+    "properties": {
+      "type":       {"type": "keyword"},
+      "id":         {"type": "long"},
+      "user_id":    {"type": "long"},
+      "title":      {"type": "text"},
+      "body":       {"type": "text"},
+      "updated_at": {"type": "date"}
+    }
+  }
+}`
+
+// ElasticsearchIndex searches documents kept in an Elasticsearch index.
+// Only IDs and scores are taken from Elasticsearch; the hits are loaded from the database
+// so that responses always reflect the current data.
+type ElasticsearchIndex struct {
+	client   *http.Client
+	baseURL  string
+	index    string
+	username string
+	password string
+	repo     *repository.SearchRepository
+}
+
+// NewElasticsearchIndex creates a new ElasticsearchIndex
+func NewElasticsearchIndex(cfg *appconfig.SearchConfig, repo *repository.SearchRepository) *ElasticsearchIndex {
+	return &ElasticsearchIndex{
+		client:   &http.Client{Timeout: cfg.Timeout},
+		baseURL:  cfg.ElasticsearchURL,
+		index:    cfg.ElasticsearchIndex,
+		username: cfg.ElasticsearchUsername,
+		password: cfg.ElasticsearchPassword,
+		repo:     repo,
+	}
+}
+
+// Name returns the backend identifier
+func (i *ElasticsearchIndex) Name() string {
+	return BackendElasticsearch
+}
+
+type esSearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Score  float64 `json:"_score"`
+			Source struct {
+				ID int64 `json:"id"`
+			} `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// Search returns the best matches of a single resource type
+func (i *ElasticsearchIndex) Search(ctx context.Context, q Query) (*Group, error) {
+	fields := []string{"title^2", "body"}
+	if q.Type == TypeComment {
+		fields = []string{"body"}
+	}
+
+	body := map[string]any{
+		"size":             q.Limit,
+		"track_total_hits": true,
+		"_source":          []string{"id"},
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []any{
+					map[string]any{"term": map[string]any{"user_id": q.UserID}},
+					map[string]any{"term": map[string]any{"type": q.Type}},
+				},
+				"must": map[string]any{
+					"multi_match": map[string]any{
+						"query":     q.Text,
+						"fields":    fields,
+						"fuzziness": "AUTO",
+					},
+				},
+			},
+		},
+	}
+
+	var resp esSearchResponse
+	if err := i.do(ctx, http.MethodPost, "/"+i.index+"/_search", body, &resp); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(resp.Hits.Hits))
+	scores := make(map[int64]float64, len(resp.Hits.Hits))
+	for j, h := range resp.Hits.Hits {
+		ids[j] = h.Source.ID
+		scores[h.Source.ID] = h.Score
+	}
+
+	hits, err := i.hydrate(ctx, q.UserID, q.Type, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep Elasticsearch's ranking and drop documents whose rows no longer exist
+	byID := make(map[int64]Hit, len(hits))
+	for _, h := range hits {
+		byID[h.ID] = h
+	}
+	group := &Group{Type: q.Type, Total: resp.Hits.Total.Value}
+	var stale []int64
+	for _, id := range ids {
+		h, ok := byID[id]
+		if !ok {
+			stale = append(stale, id)
+			continue
+		}
+		h.Score = scores[id]
+		group.Hits = append(group.Hits, h)
+	}
+	if len(stale) > 0 {
+		group.Total -= int64(len(stale))
+		go i.deleteStale(q.Type, stale)
+	}
+
+	return group, nil
+}
+
+// hydrate loads the hits for the given IDs from the database
+func (i *ElasticsearchIndex) hydrate(ctx context.Context, userID int64, docType string, ids []int64) ([]Hit, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	switch docType {
+	case TypeTodo:
+		todos, err := i.repo.FindTodosByIDs(ctx, userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		return todoHits(todos), nil
+	case TypeComment:
+		rows, err := i.repo.FindCommentsByIDs(ctx, userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		return commentHits(rows), nil
+	case TypeCategory:
+		categories, err := i.repo.FindCategoriesByIDs(ctx, userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		return categoryHits(categories), nil
+	case TypeTag:
+		tags, err := i.repo.FindTagsByIDs(ctx, userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		return tagHits(tags), nil
+	}
+	return nil, nil
+}
+
+// deleteStale removes documents that were found in the index but not in the database
+func (i *ElasticsearchIndex) deleteStale(docType string, ids []int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), i.client.Timeout)
+	defer cancel()
+
+	if err := i.Delete(ctx, docType, ids); err != nil {
+		log.Warn().Err(err).Str("type", docType).Msg("ElasticsearchIndex.deleteStale: failed to delete stale documents")
+	}
+}
+
+// EnsureIndex creates the index with its mapping if it does not exist yet
+func (i *ElasticsearchIndex) EnsureIndex(ctx context.Context) error {
+	err := i.do(ctx, http.MethodHead, "/"+i.index, nil, nil)
+	if err == nil {
+		return nil
+	}
+	if !isNotFound(err) {
+		return err
+	}
+	return i.do(ctx, http.MethodPut, "/"+i.index, json.RawMessage(elasticsearchMapping), nil)
+}
+
+// Upsert adds or replaces documents
+func (i *ElasticsearchIndex) Upsert(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, doc := range docs {
+		action := map[string]any{"index": map[string]string{"_id": documentID(doc.Type, doc.ID)}}
+		if err := enc.Encode(action); err != nil {
+			return fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode document: %w", err)
+		}
+	}
+	return i.bulk(ctx, &buf)
+}
+
+// Delete removes documents of one type; unknown IDs are ignored
+func (i *ElasticsearchIndex) Delete(ctx context.Context, docType string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, id := range ids {
+		action := map[string]any{"delete": map[string]string{"_id": documentID(docType, id)}}
+		if err := enc.Encode(action); err != nil {
+			return fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+	}
+	return i.bulk(ctx, &buf)
+}
+
+type esBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// bulk sends an NDJSON body to the bulk API and reports the first failed item
+func (i *ElasticsearchIndex) bulk(ctx context.Context, body io.Reader) error {
+	req, err := i.newRequest(ctx, http.MethodPost, "/"+i.index+"/_bulk", body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	var resp esBulkResponse
+	if err := i.send(req, &resp); err != nil {
+		return err
+	}
+	if !resp.Errors {
+		return nil
+	}
+	for _, item := range resp.Items {
+		for _, result := range item {
+			// Deleting a document that is already gone is not an error
+			if result.Status == http.StatusNotFound || result.Error == nil {
+				continue
+			}
+			return fmt.Errorf("bulk item failed with status %d: %s", result.Status, result.Error.Reason)
+		}
+	}
+	return nil
+}
+
+// esStatusError is returned for non-2xx responses
+type esStatusError struct {
+	status int
+	body   string
+}
+
+func (e *esStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.status, e.body)
+}
+
+func isNotFound(err error) bool {
+	statusErr, ok := err.(*esStatusError)
+	return ok && statusErr.status == http.StatusNotFound
+}
+
+// do sends a JSON request and decodes the JSON response into out when it is non-nil
+func (i *ElasticsearchIndex) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := i.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return i.send(req, out)
+}
+
+func (i *ElasticsearchIndex) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, i.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if i.username != "" {
+		req.SetBasicAuth(i.username, i.password)
+	}
+	return req, nil
+}
+
+func (i *ElasticsearchIndex) send(req *http.Request, out any) error {
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &esStatusError{status: resp.StatusCode, body: truncate(string(respBody), 200)}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// documentID returns the Elasticsearch _id of a resource; IDs are only unique per table
+func documentID(docType string, id int64) string {
+	return docType + "_" + strconv.FormatInt(id, 10)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
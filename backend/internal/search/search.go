@@ -0,0 +1,95 @@
+// Package search provides the backends behind global search
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appconfig "todo-api/internal/config"
+	"todo-api/internal/repository"
+)
+
+// Resource types that can be searched
+const (
+	TypeTodo     = "todo"
+	TypeComment  = "comment"
+	TypeCategory = "category"
+	TypeTag      = "tag"
+)
+
+// Types lists all searchable resource types in display order
+var Types = []string{TypeTodo, TypeComment, TypeCategory, TypeTag}
+
+// Backend names accepted by SEARCH_BACKEND
+const (
+	BackendSQL           = "sql"
+	BackendElasticsearch = "elasticsearch"
+)
+
+// Query is a search for one resource type of one user
+type Query struct {
+	UserID int64
+	Text   string
+	Type   string
+	Limit  int
+}
+
+// Hit is a single matched resource
+type Hit struct {
+	Type  string
+	ID    int64
+	Title string
+	// Body is the secondary text that was searched (todo description, comment content)
+	Body *string
+	// TodoID links comments to the todo they belong to
+	TodoID    *int64
+	Color     *string
+	Score     float64
+	UpdatedAt time.Time
+}
+
+// Group holds the hits of one resource type, best first
+type Group struct {
+	Type  string
+	Total int64
+	Hits  []Hit
+}
+
+// Index searches the user's data
+type Index interface {
+	// Name returns the backend identifier
+	Name() string
+	// Search returns the best matches of a single resource type
+	Search(ctx context.Context, q Query) (*Group, error)
+}
+
+// Document is the searchable text of a resource as stored by an external index
+type Document struct {
+	Type      string    `json:"type"`
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Syncer is implemented by indexes that keep their own copy of the data
+type Syncer interface {
+	// Upsert adds or replaces documents
+	Upsert(ctx context.Context, docs []Document) error
+	// Delete removes documents of one type; unknown IDs are ignored
+	Delete(ctx context.Context, docType string, ids []int64) error
+}
+
+// New returns the index selected by configuration
+func New(cfg *appconfig.SearchConfig, repo *repository.SearchRepository) (Index, error) {
+	switch cfg.Backend {
+	case "", BackendSQL:
+		return NewSQLIndex(repo), nil
+	case BackendElasticsearch:
+		return NewElasticsearchIndex(cfg, repo), nil
+	default:
+		return nil, fmt.Errorf("unknown search backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,111 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"todo-api/internal/repository"
+)
+
+// Match scores of the SQL index; higher is more relevant
+const (
+	scoreExact     = 100
+	scorePrefix    = 80
+	scoreWordStart = 60
+	scoreContains  = 40
+	scoreBody      = 20
+)
+
+// SQLIndex searches the database directly with case-insensitive partial matching
+type SQLIndex struct {
+	repo *repository.SearchRepository
+}
+
+// NewSQLIndex creates a new SQLIndex
+func NewSQLIndex(repo *repository.SearchRepository) *SQLIndex {
+	return &SQLIndex{repo: repo}
+}
+
+// Name returns the backend identifier
+func (i *SQLIndex) Name() string {
+	return BackendSQL
+}
+
+// Search returns the best matches of a single resource type
+func (i *SQLIndex) Search(ctx context.Context, q Query) (*Group, error) {
+	group := &Group{Type: q.Type}
+
+	switch q.Type {
+	case TypeTodo:
+		todos, total, err := i.repo.SearchTodos(ctx, q.UserID, q.Text, q.Limit)
+		if err != nil {
+			return nil, err
+		}
+		group.Total = total
+		group.Hits = todoHits(todos)
+	case TypeComment:
+		rows, total, err := i.repo.SearchComments(ctx, q.UserID, q.Text, q.Limit)
+		if err != nil {
+			return nil, err
+		}
+		group.Total = total
+		group.Hits = commentHits(rows)
+	case TypeCategory:
+		categories, total, err := i.repo.SearchCategories(ctx, q.UserID, q.Text, q.Limit)
+		if err != nil {
+			return nil, err
+		}
+		group.Total = total
+		group.Hits = categoryHits(categories)
+	case TypeTag:
+		tags, total, err := i.repo.SearchTags(ctx, q.UserID, q.Text, q.Limit)
+		if err != nil {
+			return nil, err
+		}
+		group.Total = total
+		group.Hits = tagHits(tags)
+	}
+
+	for j := range group.Hits {
+		hit := &group.Hits[j]
+		if hit.Type == TypeComment {
+			// The title of a comment hit is its todo's; only the content was matched
+			hit.Score = scoreText(q.Text, "", hit.Body)
+		} else {
+			hit.Score = scoreText(q.Text, hit.Title, hit.Body)
+		}
+	}
+	sort.SliceStable(group.Hits, func(a, b int) bool {
+		return group.Hits[a].Score > group.Hits[b].Score
+	})
+
+	return group, nil
+}
+
+// scoreText rates how well the query matches a title and optional body
+func scoreText(query, title string, body *string) float64 {
+	q := strings.ToLower(query)
+	t := strings.ToLower(title)
+
+	switch {
+	case t == "":
+	case t == q:
+		return scoreExact
+	case strings.HasPrefix(t, q):
+		return scorePrefix
+	case strings.Contains(t, " "+q):
+		return scoreWordStart
+	case strings.Contains(t, q):
+		return scoreContains
+	}
+
+	if body != nil && strings.Contains(strings.ToLower(*body), q) {
+		if title == "" {
+			// Body-only resources (comments) are ranked like a partial title match
+			return scoreContains
+		}
+		return scoreBody
+	}
+	return 0
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/pkg/database"
+)
+
+const (
+	// AuditGenesisHash is the previous hash of the first audit log entry
+	AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+	// AuditFlushJobInterval is how often pending entries are linked into the audit log
+	AuditFlushJobInterval = 10 * time.Second
+
+	// auditAppendAttempts is how many times appending is tried when other writers keep extending the chain first
+	auditAppendAttempts = 5
+	// auditVerifyBatchSize is the number of entries read at a time while verifying the chain
+	auditVerifyBatchSize = 500
+	// auditFlushBatchSize is the number of pending entries read at a time while flushing
+	auditFlushBatchSize = 500
+)
+
+// AuditService appends write operations to the tamper-evident audit log and queries it
+type AuditService struct {
+	auditRepo *repository.AuditLogRepository
+}
+
+// NewAuditService creates a new AuditService
+func NewAuditService(auditRepo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// AuditVerification represents the result of verifying the hash chain
+type AuditVerification struct {
+	Valid   bool
+	Checked int64
+	// BrokenAtID is the first entry whose hash or link to the previous entry does not match
+	BrokenAtID *int64
+}
+
+// auditUnrecordedTables are not recorded by the write callbacks: the audit log itself and its pending
+// entries, the queued search index updates, and the API usage counters and signature nonces that every
+// request writes
+var auditUnrecordedTables = map[string]bool{
+	"audit_logs":             true,
+	"pending_audit_logs":     true,
+	"pending_search_updates": true,
+	"api_usages":             true,
+	"integration_nonces":     true,
+}
+
+// auditRawWritePattern matches the verb and table of a write run as raw SQL
+var auditRawWritePattern = regexp.MustCompile(`(?is)^\s*(INSERT\s+INTO|UPDATE|DELETE\s+FROM)\s+"?(\w+)`)
+
+// Record queues an entry for the audit log; Flush links it into the chain
+func (s *AuditService) Record(ctx context.Context, entry *model.AuditLog) error {
+	return queueAuditLog(ctx, s.auditRepo, entry)
+}
+
+// queueAuditLog records an entry as pending, timestamped now
+func queueAuditLog(ctx context.Context, auditRepo *repository.AuditLogRepository, entry *model.AuditLog) error {
+	entry.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	return auditRepo.CreatePending(ctx, model.NewPendingAuditLog(entry))
+}
+
+// RegisterCallbacks records every create, update, and delete made through db, including raw INSERT,
+// UPDATE, and DELETE statements, whichever handler, service, or job makes it. The entry is queued on the
+// connection of the write, so it commits or rolls back with the write's transaction, and linked into the
+// chain by Flush afterwards. The actor is the user the statement runs as (see database.ForUser); writes by
+// the system or before authentication have none. The request ID and IP are those of the API request the
+// statement is made for (see database.WithRequest). A failure to record is logged rather than failing the write.
+func (s *AuditService) RegisterCallbacks(db *gorm.DB) error {
+	record := func(action model.AuditAction) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if tx.Error != nil || tx.Statement.Table == "" {
+				return
+			}
+			resourceID := ""
+			if id, ok := database.WrittenID(tx); ok {
+				resourceID = fmt.Sprint(id)
+			}
+			s.recordWrite(tx, action, tx.Statement.Table, resourceID)
+		}
+	}
+	recordRaw := func(tx *gorm.DB) {
+		if tx.Error != nil {
+			return
+		}
+		match := auditRawWritePattern.FindStringSubmatch(tx.Statement.SQL.String())
+		if match == nil {
+			return
+		}
+		action := model.AuditActionUpdate
+		switch strings.ToUpper(match[1][:6]) {
+		case "INSERT":
+			action = model.AuditActionCreate
+		case "DELETE":
+			action = model.AuditActionDelete
+		}
+		s.recordWrite(tx, action, match[2], "")
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("audit:record", record(model.AuditActionCreate)); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("audit:record", record(model.AuditActionUpdate)); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("audit:record", record(model.AuditActionDelete)); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("audit:record", recordRaw)
+}
+
+// recordWrite queues the entry of a write made by tx
+func (s *AuditService) recordWrite(tx *gorm.DB, action model.AuditAction, table, resourceID string) {
+	if auditUnrecordedTables[table] {
+		return
+	}
+
+	entry := &model.AuditLog{
+		Action:       action,
+		ResourceType: table,
+		ResourceID:   resourceID,
+	}
+	ctx := tx.Statement.Context
+	if userID, ok := database.UserIDFrom(ctx); ok {
+		entry.ActorID = &userID
+	}
+	if req, ok := database.RequestFrom(ctx); ok {
+		entry.RequestID = req.ID
+		entry.IPAddress = req.IP
+	}
+	// Sessions derived from tx carry over the write's statement; a new statement on the pending entry's
+	// model keeps the write's table and conditions out of the insert
+	auditDB := tx.Session(&gorm.Session{NewDB: true}).Model(&model.PendingAuditLog{})
+	if err := queueAuditLog(ctx, repository.NewAuditLogRepository(auditDB), entry); err != nil {
+		log.Error().Err(err).Str("table", table).Msg("AuditService.RegisterCallbacks: failed to record audit log")
+	}
+}
+
+// Run is the audit flush job: it links the entries queued since the last run into the chain
+func (s *AuditService) Run(ctx context.Context, _ time.Time) error {
+	flushed, err := s.Flush(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to flush audit log: %w", err)
+	}
+	if flushed > 0 {
+		log.Debug().Int("entries", flushed).Msg("Audit log entries flushed")
+	}
+	return nil
+}
+
+// Flush links the pending entries into the chain, oldest first, and returns how many it linked.
+// Each entry is moved in a transaction of its own that deletes the pending row first: a concurrent flush,
+// in this process or another, waits on that row and skips the entry once it is gone.
+func (s *AuditService) Flush(ctx context.Context) (int, error) {
+	flushed := 0
+	for {
+		pending, err := s.auditRepo.FindPending(ctx, auditFlushBatchSize)
+		if err != nil {
+			return flushed, err
+		}
+		for i := range pending {
+			claimed := false
+			err := s.auditRepo.Transaction(ctx, func(txRepo *repository.AuditLogRepository) error {
+				var err error
+				if claimed, err = txRepo.DeletePending(ctx, pending[i].ID); err != nil || !claimed {
+					return err
+				}
+				return s.append(ctx, txRepo, pending[i].Entry())
+			})
+			if err != nil {
+				return flushed, err
+			}
+			if claimed {
+				flushed++
+			}
+		}
+		if len(pending) < auditFlushBatchSize {
+			return flushed, nil
+		}
+	}
+}
+
+// append links an entry to the latest entry and inserts it.
+// Concurrent flushes may read the same latest entry; the unique previous hash lets only one of them
+// insert, and the others link to its entry and try again.
+func (s *AuditService) append(ctx context.Context, auditRepo *repository.AuditLogRepository, entry *model.AuditLog) error {
+	var err error
+	for attempt := 0; attempt < auditAppendAttempts; attempt++ {
+		// A savepoint keeps a conflict from aborting the flush's transaction
+		err = auditRepo.Transaction(ctx, func(txRepo *repository.AuditLogRepository) error {
+			entry.ID = 0
+			entry.PrevHash = AuditGenesisHash
+			last, findErr := txRepo.FindLast(ctx)
+			if findErr == nil {
+				entry.PrevHash = last.Hash
+			} else if findErr != gorm.ErrRecordNotFound {
+				return findErr
+			}
+			entry.Hash = AuditHash(entry)
+			return txRepo.Create(ctx, entry)
+		})
+		if err == nil || !auditRepo.IsConflict(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// Search retrieves audit log entries matching the filters, newest first, with the total count.
+// Pending entries are flushed first, so the log is complete even when the flush job does not run.
+func (s *AuditService) Search(ctx context.Context, input repository.AuditLogSearchInput) ([]model.AuditLog, int64, error) {
+	if input.Action != "" && !validAuditAction(input.Action) {
+		return nil, 0, errors.ValidationFailed(map[string][]string{
+			"action": {"must be one of create, update, delete"},
+		})
+	}
+	if _, err := s.Flush(ctx); err != nil {
+		return nil, 0, errors.InternalErrorWithLog(err, "AuditService.Search: failed to flush audit log")
+	}
+	entries, total, err := s.auditRepo.Search(ctx, input)
+	if err != nil {
+		return nil, 0, errors.InternalErrorWithLog(err, "AuditService.Search: failed to search audit log")
+	}
+	return entries, total, nil
+}
+
+// Verify walks the whole audit log and checks that every entry matches its hash
+// and links to the hash of the entry before it. Pending entries are flushed first, as in Search.
+func (s *AuditService) Verify(ctx context.Context) (*AuditVerification, error) {
+	if _, err := s.Flush(ctx); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "AuditService.Verify: failed to flush audit log")
+	}
+	result := &AuditVerification{Valid: true}
+	prevHash := AuditGenesisHash
+	var afterID int64
+	for {
+		entries, err := s.auditRepo.FindAfter(ctx, afterID, auditVerifyBatchSize)
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "AuditService.Verify: failed to read audit log")
+		}
+		for i := range entries {
+			entry := &entries[i]
+			if entry.PrevHash != prevHash || entry.Hash != AuditHash(entry) {
+				result.Valid = false
+				result.BrokenAtID = &entry.ID
+				return result, nil
+			}
+			result.Checked++
+			prevHash = entry.Hash
+			afterID = entry.ID
+		}
+		if len(entries) < auditVerifyBatchSize {
+			return result, nil
+		}
+	}
+}
+
+// AuditHash returns the SHA-256 hex digest of an entry's content and its previous hash.
+// The ID is not covered since it is assigned on insert; the chain orders entries instead.
+func AuditHash(entry *model.AuditLog) string {
+	content, _ := json.Marshal(struct {
+		ActorID      *int64            `json:"actor_id"`
+		ActorEmail   string            `json:"actor_email"`
+		Action       model.AuditAction `json:"action"`
+		ResourceType string            `json:"resource_type"`
+		ResourceID   string            `json:"resource_id"`
+		Method       string            `json:"method"`
+		Path         string            `json:"path"`
+		StatusCode   int               `json:"status_code"`
+		RequestID    string            `json:"request_id"`
+		IPAddress    string            `json:"ip_address"`
+		CreatedAt    string            `json:"created_at"`
+		PrevHash     string            `json:"prev_hash"`
+	}{
+		ActorID:      entry.ActorID,
+		ActorEmail:   entry.ActorEmail,
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		Method:       entry.Method,
+		Path:         entry.Path,
+		StatusCode:   entry.StatusCode,
+		RequestID:    entry.RequestID,
+		IPAddress:    entry.IPAddress,
+		CreatedAt:    entry.CreatedAt.UTC().Format(time.RFC3339),
+		PrevHash:     entry.PrevHash,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditActionForMethod returns the audit action of a write HTTP method, or false for reads
+func AuditActionForMethod(method string) (model.AuditAction, bool) {
+	switch strings.ToUpper(method) {
+	case "POST":
+		return model.AuditActionCreate, true
+	case "PUT", "PATCH":
+		return model.AuditActionUpdate, true
+	case "DELETE":
+		return model.AuditActionDelete, true
+	}
+	return "", false
+}
+
+// validAuditAction reports whether action is a recorded audit action
+func validAuditAction(action string) bool {
+	switch model.AuditAction(action) {
+	case model.AuditActionCreate, model.AuditActionUpdate, model.AuditActionDelete:
+		return true
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -35,14 +36,15 @@ func NewAuthService(
 
 // JWTClaims represents the claims in a JWT token (Rails devise-jwt compatible)
 type JWTClaims struct {
-	Sub string `json:"sub"` // User ID
-	Jti string `json:"jti"` // Token identifier
-	Scp string `json:"scp"` // Scope
+	Sub    string `json:"sub"`              // User ID
+	Jti    string `json:"jti"`              // Token identifier
+	Scp    string `json:"scp"`              // Scope
+	Tenant string `json:"tenant,omitempty"` // Tenant slug (schema tenancy mode only)
 	jwt.RegisteredClaims
 }
 
 // SignUp registers a new user and returns the user and JWT token
-func (s *AuthService) SignUp(email, password, passwordConfirmation, name string) (*model.User, string, error) {
+func (s *AuthService) SignUp(ctx context.Context, email, password, passwordConfirmation, name string) (*model.User, string, error) {
 	// Validate password confirmation
 	if password != passwordConfirmation {
 		return nil, "", errors.ValidationFailed(map[string][]string{
@@ -51,7 +53,7 @@ func (s *AuthService) SignUp(email, password, passwordConfirmation, name string)
 	}
 
 	// Check for duplicate email
-	exists, err := s.userRepo.ExistsByEmail(email)
+	exists, err := s.userRepo.ExistsByEmail(ctx, email)
 	if err != nil {
 		return nil, "", errors.InternalErrorWithLog(err, "AuthService.SignUp: failed to check email")
 	}
@@ -68,7 +70,7 @@ func (s *AuthService) SignUp(email, password, passwordConfirmation, name string)
 		return nil, "", errors.InternalErrorWithLog(err, "AuthService.SignUp: failed to hash password")
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, "", errors.InternalErrorWithLog(err, "AuthService.SignUp: failed to create user")
 	}
 
@@ -82,8 +84,8 @@ func (s *AuthService) SignUp(email, password, passwordConfirmation, name string)
 }
 
 // SignIn authenticates a user and returns the user and JWT token
-func (s *AuthService) SignIn(email, password string) (*model.User, string, error) {
-	user, err := s.userRepo.FindByEmail(email)
+func (s *AuthService) SignIn(ctx context.Context, email, password string) (*model.User, string, error) {
+	user, err := s.userRepo.FindByEmail(ctx, email)
 	if err != nil {
 		return nil, "", errors.AuthenticationFailed("Invalid email or password")
 	}
@@ -101,37 +103,78 @@ func (s *AuthService) SignIn(email, password string) (*model.User, string, error
 }
 
 // SignOut revokes the given token by adding its jti to the denylist
-func (s *AuthService) SignOut(jti string, exp time.Time) error {
-	return s.denylistRepo.Add(jti, exp)
+func (s *AuthService) SignOut(ctx context.Context, jti string, exp time.Time) error {
+	return s.denylistRepo.Add(ctx, jti, exp)
+}
+
+// TokenTTL returns how long a generated JWT token is valid
+func (s *AuthService) TokenTTL() time.Duration {
+	return time.Duration(s.config.JWTExpirationHours) * time.Hour
 }
 
 // GenerateToken creates a new JWT token for the given user
 func (s *AuthService) GenerateToken(user *model.User) (string, error) {
+	token, _, err := s.GenerateTokenWithClaims(user)
+	return token, err
+}
+
+// GenerateTokenWithClaims creates a new JWT token for the given user and returns its claims,
+// for callers that need to revoke it later by jti
+func (s *AuthService) GenerateTokenWithClaims(user *model.User) (string, *JWTClaims, error) {
 	now := time.Now()
-	expiration := now.Add(time.Duration(s.config.JWTExpirationHours) * time.Hour)
+	expiration := now.Add(s.TokenTTL())
 
 	claims := JWTClaims{
-		Sub: fmt.Sprintf("%d", user.ID),
-		Jti: uuid.New().String(),
-		Scp: "user",
+		Sub:    fmt.Sprintf("%d", user.ID),
+		Jti:    uuid.New().String(),
+		Scp:    "user",
+		Tenant: s.config.Tenant,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expiration),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.config.JWTSecret))
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.config.JWTSecret))
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &claims, nil
 }
 
 // ValidateToken validates a JWT token and returns the claims
-func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
+func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*JWTClaims, error) {
+	claims, err := ParseToken(tokenString, s.config.JWTSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	// A token issued for another tenant is not valid here, even if its user ID exists
+	if claims.Tenant != s.config.Tenant {
+		return nil, fmt.Errorf("token belongs to another tenant")
+	}
+
+	// Check if token is revoked
+	revoked, err := s.denylistRepo.Exists(ctx, claims.Jti)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("token has been revoked")
+	}
+
+	return claims, nil
+}
+
+// ParseToken verifies the signature and expiry of a JWT token and returns the claims.
+// It does not check the denylist; use AuthService.ValidateToken to authenticate a request.
+func ParseToken(tokenString, secret string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(s.config.JWTSecret), nil
+		return []byte(secret), nil
 	})
 
 	if err != nil {
@@ -142,15 +185,5 @@ func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
 	if !ok || !token.Valid {
 		return nil, fmt.Errorf("invalid token claims")
 	}
-
-	// Check if token is revoked
-	revoked, err := s.denylistRepo.Exists(claims.Jti)
-	if err != nil {
-		return nil, err
-	}
-	if revoked {
-		return nil, fmt.Errorf("token has been revoked")
-	}
-
 	return claims, nil
 }
@@ -0,0 +1,489 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"todo-api/internal/calendar"
+	"todo-api/internal/config"
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/tenant"
+	"todo-api/pkg/util"
+)
+
+const (
+	// CalendarSyncJobInterval is how often todos are synced with the connected calendars
+	CalendarSyncJobInterval = 5 * time.Minute
+	// CalendarStateTTL is how long a user has to grant access on the provider's consent page
+	CalendarStateTTL = 10 * time.Minute
+	// CalendarName is the name of the calendar created for the due dates
+	CalendarName = "Todos"
+	// CalendarCompletedMark prefixes the event title of completed todos; adding or removing it
+	// in the calendar completes or reopens the todo when pulling is enabled
+	CalendarCompletedMark = "✔"
+	// calendarSyncOverlap re-reads changes made shortly before the last sync to catch late commits
+	calendarSyncOverlap = time.Minute
+	// calendarTokenRefreshMargin refreshes access tokens that expire within it
+	calendarTokenRefreshMargin = time.Minute
+	// calendarPushBatchSize is the number of changed todos read at once
+	calendarPushBatchSize = 200
+)
+
+// CalendarService syncs the due dates of a user's todos to a calendar created for them.
+// The sync job pushes the todos changed since its previous run (creating, updating and deleting events)
+// and, when the user enabled it, pulls completion and date edits made in the calendar back.
+type CalendarService struct {
+	calendarRepo *repository.CalendarRepository
+	syncRepo     *repository.SyncRepository
+	todoService  *TodoService
+	provider     calendar.Provider
+	returnURL    string
+	tenantSlug   string
+}
+
+// NewCalendarService creates a new CalendarService. A nil provider disables calendar sync.
+func NewCalendarService(
+	calendarRepo *repository.CalendarRepository,
+	syncRepo *repository.SyncRepository,
+	todoService *TodoService,
+	provider calendar.Provider,
+	cfg *config.CalendarConfig,
+) *CalendarService {
+	return &CalendarService{
+		calendarRepo: calendarRepo,
+		syncRepo:     syncRepo,
+		todoService:  todoService,
+		provider:     provider,
+		returnURL:    cfg.ReturnURL,
+		tenantSlug:   cfg.Tenant,
+	}
+}
+
+// Enabled returns true if a calendar provider is configured
+func (s *CalendarService) Enabled() bool {
+	return s.provider != nil
+}
+
+// ReturnURL returns the frontend page users are sent back to after granting access
+func (s *CalendarService) ReturnURL() string {
+	return s.returnURL
+}
+
+// Status returns the user's connection, or nil without one
+func (s *CalendarService) Status(ctx context.Context, userID int64) (*model.CalendarConnection, error) {
+	if !s.Enabled() {
+		return nil, errors.FeatureDisabled("google_calendar")
+	}
+
+	connection, err := s.calendarRepo.FindConnectionByUserID(ctx, userID)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "CalendarService.Status: failed to fetch connection")
+	}
+	return connection, nil
+}
+
+// Connect starts connecting the user's calendar and returns the provider's consent page URL.
+// Connecting again (e.g. after revoking access) keeps the calendar.
+func (s *CalendarService) Connect(ctx context.Context, userID int64, now time.Time) (string, error) {
+	if !s.Enabled() {
+		return "", errors.FeatureDisabled("google_calendar")
+	}
+
+	connection, err := s.calendarRepo.FindConnectionByUserID(ctx, userID)
+	if err == gorm.ErrRecordNotFound {
+		connection = &model.CalendarConnection{UserID: userID, Provider: s.provider.Name()}
+	} else if err != nil {
+		return "", errors.InternalErrorWithLog(err, "CalendarService.Connect: failed to fetch connection")
+	}
+
+	state, err := generateToken()
+	if err != nil {
+		return "", errors.InternalErrorWithLog(err, "CalendarService.Connect: failed to generate state")
+	}
+	// Google redirects every tenant to the same callback URL
+	state = tenant.TokenWithSlug(s.tenantSlug, state)
+	stateHash := hashToken(state)
+	expiresAt := now.Add(CalendarStateTTL)
+	connection.StateHash = &stateHash
+	connection.StateExpiresAt = &expiresAt
+
+	if err := s.calendarRepo.SaveConnection(ctx, connection); err != nil {
+		return "", errors.InternalErrorWithLog(err, "CalendarService.Connect: failed to save connection")
+	}
+
+	return s.provider.AuthCodeURL(state), nil
+}
+
+// Callback finishes connecting with the authorization code the provider redirected the user back with,
+// creating the calendar on first connection
+func (s *CalendarService) Callback(ctx context.Context, state, code string, now time.Time) error {
+	if !s.Enabled() {
+		return errors.FeatureDisabled("google_calendar")
+	}
+
+	connection, err := s.calendarRepo.FindConnectionByStateHash(ctx, hashToken(state))
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return errors.InternalErrorWithLog(err, "CalendarService.Callback: failed to fetch connection")
+	}
+	if connection == nil || connection.StateExpiresAt == nil || now.After(*connection.StateExpiresAt) {
+		return errors.ValidationFailed(map[string][]string{
+			"state": {"is invalid or expired"},
+		})
+	}
+
+	token, err := s.provider.Exchange(ctx, code)
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", connection.UserID).Msg("CalendarService.Callback: failed to exchange code")
+		return errors.ExternalServiceError(s.provider.Name())
+	}
+	s.applyToken(connection, token)
+
+	if connection.CalendarID == "" {
+		connection.CalendarID, err = s.provider.CreateCalendar(ctx, token.AccessToken, CalendarName)
+		if err != nil {
+			log.Error().Err(err).Int64("user_id", connection.UserID).Msg("CalendarService.Callback: failed to create calendar")
+			return errors.ExternalServiceError(s.provider.Name())
+		}
+	}
+
+	connection.StateHash = nil
+	connection.StateExpiresAt = nil
+	connection.ConnectedAt = &now
+	connection.LastError = ""
+	if err := s.calendarRepo.SaveConnection(ctx, connection); err != nil {
+		return errors.InternalErrorWithLog(err, "CalendarService.Callback: failed to save connection")
+	}
+	return nil
+}
+
+// UpdateSettings turns pulling edits made in the calendar on or off.
+// Only edits made after turning it on are pulled.
+func (s *CalendarService) UpdateSettings(ctx context.Context, userID int64, pullEnabled bool, now time.Time) (*model.CalendarConnection, error) {
+	if !s.Enabled() {
+		return nil, errors.FeatureDisabled("google_calendar")
+	}
+
+	connection, err := s.calendarRepo.FindConnectionByUserID(ctx, userID)
+	if err != nil {
+		return nil, err // Let handler handle gorm.ErrRecordNotFound
+	}
+
+	if pullEnabled && !connection.PullEnabled {
+		connection.LastPulledAt = &now
+	}
+	connection.PullEnabled = pullEnabled
+
+	if err := s.calendarRepo.SaveConnection(ctx, connection); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "CalendarService.UpdateSettings: failed to save connection")
+	}
+	return connection, nil
+}
+
+// Disconnect stops syncing. The calendar and its events are left in place.
+func (s *CalendarService) Disconnect(ctx context.Context, userID int64) error {
+	if !s.Enabled() {
+		return errors.FeatureDisabled("google_calendar")
+	}
+	return s.calendarRepo.DeleteConnection(ctx, userID)
+}
+
+// Run syncs every connected calendar. A failing calendar is recorded on its connection
+// and does not stop the others.
+func (s *CalendarService) Run(ctx context.Context, now time.Time) error {
+	connections, err := s.calendarRepo.FindConnected(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch calendar connections: %w", err)
+	}
+
+	for i := range connections {
+		connection := &connections[i]
+		if err := s.syncConnection(ctx, connection, now); err != nil {
+			log.Warn().Err(err).Int64("user_id", connection.UserID).Msg("CalendarService.Run: failed to sync calendar")
+			connection.LastError = err.Error()
+			if stderrors.Is(err, calendar.ErrAuthorizationRevoked) {
+				connection.LastError = "Access to the calendar was revoked; connect it again"
+			}
+		} else {
+			connection.LastError = ""
+		}
+		if err := s.calendarRepo.SaveConnection(ctx, connection); err != nil {
+			return fmt.Errorf("failed to save calendar connection: %w", err)
+		}
+	}
+	return nil
+}
+
+// syncConnection pulls the calendar's edits, if enabled, then pushes the todos' changes
+func (s *CalendarService) syncConnection(ctx context.Context, connection *model.CalendarConnection, now time.Time) error {
+	accessToken, err := s.accessToken(ctx, connection, now)
+	if err != nil {
+		return err
+	}
+
+	if connection.PullEnabled {
+		if err := s.pull(ctx, connection, accessToken, now); err != nil {
+			return err
+		}
+	}
+	return s.push(ctx, connection, accessToken, now)
+}
+
+// accessToken returns the connection's access token, refreshing it when it is about to expire
+func (s *CalendarService) accessToken(ctx context.Context, connection *model.CalendarConnection, now time.Time) (string, error) {
+	if connection.TokenExpiresAt != nil && now.Add(calendarTokenRefreshMargin).Before(*connection.TokenExpiresAt) {
+		return connection.AccessToken, nil
+	}
+
+	token, err := s.provider.Refresh(ctx, connection.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	s.applyToken(connection, token)
+	return token.AccessToken, nil
+}
+
+// applyToken stores a token on the connection, keeping the refresh token when none was issued
+func (s *CalendarService) applyToken(connection *model.CalendarConnection, token *calendar.Token) {
+	connection.AccessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		connection.RefreshToken = token.RefreshToken
+	}
+	expiresAt := token.ExpiresAt
+	connection.TokenExpiresAt = &expiresAt
+}
+
+// push writes the todos changed and deleted since the last push to the calendar
+func (s *CalendarService) push(ctx context.Context, connection *model.CalendarConnection, accessToken string, now time.Time) error {
+	if err := s.syncRepo.AssignMissingUUIDs(ctx, connection.UserID); err != nil {
+		return fmt.Errorf("failed to assign todo UUIDs: %w", err)
+	}
+
+	var since time.Time
+	if connection.LastPushedAt != nil {
+		since = connection.LastPushedAt.Add(-calendarSyncOverlap)
+	}
+
+	var after *repository.SyncCursor
+	for {
+		todos, err := s.syncRepo.FindChangedTodos(ctx, connection.UserID, since, after, calendarPushBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to fetch changed todos: %w", err)
+		}
+		for i := range todos {
+			if err := s.pushTodo(ctx, connection, accessToken, &todos[i]); err != nil {
+				return err
+			}
+		}
+		if len(todos) < calendarPushBatchSize {
+			break
+		}
+		last := todos[len(todos)-1]
+		after = &repository.SyncCursor{UpdatedAt: last.UpdatedAt, ID: last.ID}
+	}
+
+	tombstones, err := s.syncRepo.FindTombstonesSince(ctx, connection.UserID, since)
+	if err != nil {
+		return fmt.Errorf("failed to fetch deleted todos: %w", err)
+	}
+	for _, tombstone := range tombstones {
+		event, err := s.findEvent(ctx, connection, tombstone.UUID)
+		if err != nil {
+			return err
+		}
+		if event != nil {
+			if err := s.deleteEvent(ctx, connection, accessToken, event); err != nil {
+				return err
+			}
+		}
+	}
+
+	connection.LastPushedAt = &now
+	return nil
+}
+
+// pushTodo creates or updates the event of a todo with a due date, and deletes it once the due date is removed
+func (s *CalendarService) pushTodo(ctx context.Context, connection *model.CalendarConnection, accessToken string, todo *model.Todo) error {
+	if todo.UUID == nil {
+		return nil
+	}
+	event, err := s.findEvent(ctx, connection, *todo.UUID)
+	if err != nil {
+		return err
+	}
+
+	if todo.DueDate == nil {
+		if event == nil {
+			return nil
+		}
+		return s.deleteEvent(ctx, connection, accessToken, event)
+	}
+
+	calendarEvent := calendarEventOf(todo)
+	var stored *calendar.Event
+	if event != nil {
+		calendarEvent.ID = event.EventID
+		stored, err = s.provider.UpdateEvent(ctx, accessToken, connection.CalendarID, calendarEvent)
+	}
+	if event == nil || stderrors.Is(err, calendar.ErrNotFound) {
+		stored, err = s.provider.InsertEvent(ctx, accessToken, connection.CalendarID, calendarEvent)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write event of todo %s: %w", *todo.UUID, err)
+	}
+
+	if event == nil {
+		event = &model.CalendarEvent{
+			UserID:       connection.UserID,
+			ConnectionID: connection.ID,
+			TodoUUID:     *todo.UUID,
+		}
+	}
+	event.EventID = stored.ID
+	event.EventUpdatedAt = stored.Updated
+	if err := s.calendarRepo.SaveEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to save calendar event: %w", err)
+	}
+	return nil
+}
+
+// pull applies completion and date edits made in the calendar since the last pull.
+// Edits older than the todo's last update are left for the next push to overwrite.
+func (s *CalendarService) pull(ctx context.Context, connection *model.CalendarConnection, accessToken string, now time.Time) error {
+	var since time.Time
+	if connection.LastPulledAt != nil {
+		since = connection.LastPulledAt.Add(-calendarSyncOverlap)
+	}
+
+	calendarEvents, err := s.provider.ListEvents(ctx, accessToken, connection.CalendarID, since)
+	if err != nil {
+		return fmt.Errorf("failed to list events: %w", err)
+	}
+
+	for i := range calendarEvents {
+		calendarEvent := &calendarEvents[i]
+		if calendarEvent.TodoUUID == "" {
+			continue
+		}
+		event, err := s.findEvent(ctx, connection, calendarEvent.TodoUUID)
+		if err != nil {
+			return err
+		}
+		// Skip events this service wrote last
+		if event == nil || event.EventID != calendarEvent.ID || !calendarEvent.Updated.After(event.EventUpdatedAt) {
+			continue
+		}
+
+		// An event deleted in the calendar is created again the next time the todo changes
+		if calendarEvent.Cancelled {
+			if err := s.calendarRepo.DeleteEvent(ctx, event); err != nil {
+				return fmt.Errorf("failed to delete calendar event: %w", err)
+			}
+			continue
+		}
+
+		if err := s.pullEvent(ctx, connection, calendarEvent); err != nil {
+			return err
+		}
+
+		event.EventUpdatedAt = calendarEvent.Updated
+		if err := s.calendarRepo.SaveEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to save calendar event: %w", err)
+		}
+	}
+
+	connection.LastPulledAt = &now
+	return nil
+}
+
+// pullEvent applies the date and completion of an event edited in the calendar to its todo
+func (s *CalendarService) pullEvent(ctx context.Context, connection *model.CalendarConnection, calendarEvent *calendar.Event) error {
+	todo, err := s.syncRepo.FindTodoByUUID(ctx, calendarEvent.TodoUUID, connection.UserID)
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch todo: %w", err)
+	}
+	if todo.UpdatedAt.After(calendarEvent.Updated) {
+		return nil
+	}
+
+	var input UpdateInput
+	changed := false
+	if dueDate := util.FormatDate(todo.DueDate); calendarEvent.Date != "" && (dueDate == nil || *dueDate != calendarEvent.Date) {
+		input.DueDate = &calendarEvent.Date
+		changed = true
+	}
+	if completed := strings.HasPrefix(strings.TrimSpace(calendarEvent.Summary), CalendarCompletedMark); completed != todo.Completed {
+		status := model.StatusPending.String()
+		if completed {
+			status = model.StatusCompleted.String()
+		}
+		input.Status = &status
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	if _, err := s.todoService.Update(ctx, todo.ID, connection.UserID, input); err != nil {
+		if _, ok := err.(*errors.ApiError); ok {
+			// e.g. the WIP limit; the next push restores the event
+			log.Warn().Err(err).Str("todo_uuid", calendarEvent.TodoUUID).Msg("CalendarService.pull: calendar edit rejected")
+			return nil
+		}
+		return fmt.Errorf("failed to update todo: %w", err)
+	}
+	return nil
+}
+
+// findEvent returns the event synced for a todo, or nil without one
+func (s *CalendarService) findEvent(ctx context.Context, connection *model.CalendarConnection, todoUUID string) (*model.CalendarEvent, error) {
+	event, err := s.calendarRepo.FindEvent(ctx, connection, todoUUID)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar event: %w", err)
+	}
+	return event, nil
+}
+
+// deleteEvent deletes an event from the calendar, if it is still there, and forgets it
+func (s *CalendarService) deleteEvent(ctx context.Context, connection *model.CalendarConnection, accessToken string, event *model.CalendarEvent) error {
+	err := s.provider.DeleteEvent(ctx, accessToken, connection.CalendarID, event.EventID)
+	if err != nil && !stderrors.Is(err, calendar.ErrNotFound) {
+		return fmt.Errorf("failed to delete event of todo %s: %w", event.TodoUUID, err)
+	}
+	if err := s.calendarRepo.DeleteEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to delete calendar event: %w", err)
+	}
+	return nil
+}
+
+// calendarEventOf returns the all-day event of a todo's due date
+func calendarEventOf(todo *model.Todo) *calendar.Event {
+	event := &calendar.Event{
+		Summary:  todo.Title,
+		Date:     *util.FormatDate(todo.DueDate),
+		TodoUUID: *todo.UUID,
+	}
+	if todo.Completed {
+		event.Summary = CalendarCompletedMark + " " + todo.Title
+	}
+	if todo.Description != nil {
+		event.Description = *todo.Description
+	}
+	return event
+}
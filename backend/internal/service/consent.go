@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/config"
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+)
+
+// ConsentService tracks which versions of the terms of service and privacy policy users have accepted
+type ConsentService struct {
+	consentRepo *repository.PolicyConsentRepository
+	config      *config.PolicyConfig
+}
+
+// NewConsentService creates a new ConsentService
+func NewConsentService(consentRepo *repository.PolicyConsentRepository, cfg *config.PolicyConfig) *ConsentService {
+	return &ConsentService{
+		consentRepo: consentRepo,
+		config:      cfg,
+	}
+}
+
+// PolicyStatus represents a user's consent to the current version of a policy
+type PolicyStatus struct {
+	Policy          model.Policy
+	CurrentVersion  string
+	AcceptedVersion *string
+	AcceptedAt      *time.Time
+}
+
+// Accepted reports whether the current version has been accepted
+func (p PolicyStatus) Accepted() bool {
+	return p.AcceptedVersion != nil && *p.AcceptedVersion == p.CurrentVersion
+}
+
+// ConsentInput represents the policy versions a user accepts, and the request they accepted them with
+type ConsentInput struct {
+	Versions  map[model.Policy]string
+	IPAddress string
+	UserAgent string
+}
+
+// Enabled reports whether any policy is tracked
+func (s *ConsentService) Enabled() bool {
+	return len(s.CurrentVersions()) > 0
+}
+
+// Enforcement returns how users who have not accepted the current versions are treated (flag or block)
+func (s *ConsentService) Enforcement() string {
+	return s.config.Enforcement
+}
+
+// Blocking reports whether users are blocked until they accept the current versions
+func (s *ConsentService) Blocking() bool {
+	return s.config.Enforcement == "block"
+}
+
+// CurrentVersions returns the current version of each tracked policy
+func (s *ConsentService) CurrentVersions() map[model.Policy]string {
+	versions := map[model.Policy]string{}
+	if s.config.TermsVersion != "" {
+		versions[model.PolicyTerms] = s.config.TermsVersion
+	}
+	if s.config.PrivacyVersion != "" {
+		versions[model.PolicyPrivacy] = s.config.PrivacyVersion
+	}
+	return versions
+}
+
+// ValidateSignUp checks that a new user accepts the current version of every tracked policy
+func (s *ConsentService) ValidateSignUp(input ConsentInput) error {
+	validationErrors := map[string][]string{}
+	for policy, version := range s.CurrentVersions() {
+		if input.Versions[policy] != version {
+			validationErrors[versionField(policy)] = []string{fmt.Sprintf("must accept the current version (%s)", version)}
+		}
+	}
+	if len(validationErrors) > 0 {
+		return errors.ValidationFailed(validationErrors)
+	}
+	return nil
+}
+
+// RecordSignUp records the consents of a new user validated by ValidateSignUp.
+// The account exists at this point, so a failure is logged rather than failing the sign-up;
+// the user is then asked to accept again.
+func (s *ConsentService) RecordSignUp(ctx context.Context, userID int64, input ConsentInput) {
+	if !s.Enabled() {
+		return
+	}
+	input.Versions = s.CurrentVersions()
+	if err := s.Accept(ctx, userID, input); err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Msg("ConsentService.RecordSignUp: failed to record consent")
+	}
+}
+
+// Accept records the consents of a user. Each given version must be the current version of its policy,
+// so a client cannot record consent to a version it did not show.
+func (s *ConsentService) Accept(ctx context.Context, userID int64, input ConsentInput) error {
+	current := s.CurrentVersions()
+	validationErrors := map[string][]string{}
+	given := 0
+	for policy, version := range input.Versions {
+		if version == "" {
+			continue
+		}
+		given++
+		if current[policy] == "" {
+			validationErrors[versionField(policy)] = []string{"is not tracked"}
+		} else if version != current[policy] {
+			validationErrors[versionField(policy)] = []string{fmt.Sprintf("must be the current version (%s)", current[policy])}
+		}
+	}
+	if given == 0 {
+		for policy := range current {
+			validationErrors[versionField(policy)] = []string{"is required"}
+		}
+	}
+	if len(validationErrors) > 0 {
+		return errors.ValidationFailed(validationErrors)
+	}
+
+	now := time.Now()
+	for policy, version := range input.Versions {
+		if version == "" {
+			continue
+		}
+		consent := &model.PolicyConsent{
+			UserID:     userID,
+			Policy:     policy,
+			Version:    version,
+			AcceptedAt: now,
+			IPAddress:  input.IPAddress,
+			UserAgent:  truncate(input.UserAgent, 500),
+		}
+		if err := s.consentRepo.Create(ctx, consent); err != nil {
+			return errors.InternalErrorWithLog(err, "ConsentService.Accept: failed to record consent")
+		}
+	}
+	return nil
+}
+
+// Status returns the user's consent to each tracked policy, ordered by policy
+func (s *ConsentService) Status(ctx context.Context, userID int64) ([]PolicyStatus, error) {
+	latest, err := s.consentRepo.FindLatestByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "ConsentService.Status: failed to fetch consents")
+	}
+
+	statuses := []PolicyStatus{}
+	for policy, version := range s.CurrentVersions() {
+		status := PolicyStatus{Policy: policy, CurrentVersion: version}
+		if consent, ok := latest[policy]; ok {
+			status.AcceptedVersion = &consent.Version
+			status.AcceptedAt = &consent.AcceptedAt
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Policy < statuses[j].Policy })
+	return statuses, nil
+}
+
+// Pending returns the tracked policies whose current version the user has not accepted, ordered by policy
+func (s *ConsentService) Pending(ctx context.Context, userID int64) ([]string, error) {
+	current := s.CurrentVersions()
+	accepted, err := s.consentRepo.AcceptedPolicies(ctx, userID, current)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []string{}
+	for policy := range current {
+		if !accepted[policy] {
+			pending = append(pending, string(policy))
+		}
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
+
+// versionField returns the request field holding the version of a policy
+func versionField(policy model.Policy) string {
+	return string(policy) + "_version"
+}
+
+// truncate shortens s to at most n characters, without splitting a multi-byte character
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"todo-api/internal/config"
+	"todo-api/internal/errors"
+	"todo-api/internal/mailer"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/storage"
+	"todo-api/internal/tenant"
+	"todo-api/pkg/util"
+)
+
+const (
+	// DataExportJobInterval is how often the export queue is processed
+	DataExportJobInterval = time.Minute
+	// dataExportBatchSize limits the number of exports built per job run
+	dataExportBatchSize = 5
+	// dataExportStaleAfter is how long an export may stay processing before it is retried
+	dataExportStaleAfter = time.Hour
+	// dataExportListLimit is the number of recent exports returned to the user
+	dataExportListLimit = 10
+)
+
+// DataExportService assembles downloadable archives of all of a user's data
+type DataExportService struct {
+	exportRepo      *repository.DataExportRepository
+	userRepo        *repository.UserRepository
+	prefRepo        *repository.UserPreferenceRepository
+	todoRepo        *repository.TodoRepository
+	categoryRepo    *repository.CategoryRepository
+	tagRepo         *repository.TagRepository
+	commentRepo     *repository.CommentRepository
+	historyRepo     *repository.TodoHistoryRepository
+	noteRepo        *repository.NoteRepository
+	fileRepo        *repository.FileRepository
+	focusRepo       *repository.FocusSessionRepository
+	streakRepo      *repository.StreakRepository
+	consentRepo     *repository.PolicyConsentRepository
+	todoLinkRepo    *repository.TodoLinkRepository
+	myDayRepo       *repository.MyDayRepository
+	escalationRepo  *repository.EscalationRuleRepository
+	integrationRepo *repository.IntegrationRepository
+	calendarRepo    *repository.CalendarRepository
+	storage         storage.Storage
+	mailer          mailer.Mailer
+	cfg             *config.DataExportConfig
+}
+
+// NewDataExportService creates a new DataExportService
+func NewDataExportService(
+	exportRepo *repository.DataExportRepository,
+	userRepo *repository.UserRepository,
+	prefRepo *repository.UserPreferenceRepository,
+	todoRepo *repository.TodoRepository,
+	categoryRepo *repository.CategoryRepository,
+	tagRepo *repository.TagRepository,
+	commentRepo *repository.CommentRepository,
+	historyRepo *repository.TodoHistoryRepository,
+	noteRepo *repository.NoteRepository,
+	fileRepo *repository.FileRepository,
+	focusRepo *repository.FocusSessionRepository,
+	streakRepo *repository.StreakRepository,
+	consentRepo *repository.PolicyConsentRepository,
+	todoLinkRepo *repository.TodoLinkRepository,
+	myDayRepo *repository.MyDayRepository,
+	escalationRepo *repository.EscalationRuleRepository,
+	integrationRepo *repository.IntegrationRepository,
+	calendarRepo *repository.CalendarRepository,
+	storage storage.Storage,
+	m mailer.Mailer,
+	cfg *config.DataExportConfig,
+) *DataExportService {
+	return &DataExportService{
+		exportRepo:      exportRepo,
+		userRepo:        userRepo,
+		prefRepo:        prefRepo,
+		todoRepo:        todoRepo,
+		categoryRepo:    categoryRepo,
+		tagRepo:         tagRepo,
+		commentRepo:     commentRepo,
+		historyRepo:     historyRepo,
+		noteRepo:        noteRepo,
+		fileRepo:        fileRepo,
+		focusRepo:       focusRepo,
+		streakRepo:      streakRepo,
+		consentRepo:     consentRepo,
+		todoLinkRepo:    todoLinkRepo,
+		myDayRepo:       myDayRepo,
+		escalationRepo:  escalationRepo,
+		integrationRepo: integrationRepo,
+		calendarRepo:    calendarRepo,
+		storage:         storage,
+		mailer:          m,
+		cfg:             cfg,
+	}
+}
+
+// DataExportEmail is the template data for the export-ready notification
+type DataExportEmail struct {
+	UserName    string
+	DownloadURL string
+	ExpiresAt   string
+}
+
+// Request queues a new export for the user.
+// If an export is already queued or running, that export is returned instead.
+func (s *DataExportService) Request(ctx context.Context, userID int64) (*model.DataExport, error) {
+	existing, err := s.exportRepo.FindInProgressByUserID(ctx, userID)
+	if err == nil {
+		return existing, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, errors.InternalErrorWithLog(err, "DataExportService.Request: failed to check existing export")
+	}
+
+	export := &model.DataExport{
+		UserID: userID,
+		Status: model.DataExportStatusPending,
+	}
+	if err := s.exportRepo.Create(ctx, export); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "DataExportService.Request: failed to create export")
+	}
+	return export, nil
+}
+
+// List returns the user's recent exports
+func (s *DataExportService) List(ctx context.Context, userID int64) ([]model.DataExport, error) {
+	exports, err := s.exportRepo.ListByUserID(ctx, userID, dataExportListLimit)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "DataExportService.List: failed to list exports")
+	}
+	return exports, nil
+}
+
+// Download returns the archive for a valid, unexpired download token
+func (s *DataExportService) Download(ctx context.Context, token string) (io.ReadCloser, *model.DataExport, error) {
+	export, err := s.exportRepo.FindByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, errors.NotFound("DataExport", "token")
+		}
+		return nil, nil, errors.InternalErrorWithLog(err, "DataExportService.Download: failed to fetch export")
+	}
+	if !export.IsDownloadable(time.Now()) {
+		return nil, nil, errors.NotFound("DataExport", "token")
+	}
+
+	reader, err := s.storage.Download(ctx, *export.StoragePath)
+	if err != nil {
+		return nil, nil, errors.InternalErrorWithLog(err, "DataExportService.Download: failed to download archive")
+	}
+	return reader, export, nil
+}
+
+// Run expires old archives and builds queued exports.
+// It is intended to be called periodically by the scheduler.
+func (s *DataExportService) Run(ctx context.Context, now time.Time) error {
+	s.expire(ctx, now)
+
+	exports, err := s.exportRepo.ClaimPending(ctx, dataExportBatchSize, now.Add(-dataExportStaleAfter))
+	if err != nil {
+		return fmt.Errorf("failed to claim exports: %w", err)
+	}
+
+	for i := range exports {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		export := &exports[i]
+		if err := s.process(ctx, export); err != nil {
+			log.Error().Err(err).Int64("export_id", export.ID).Msg("DataExportService.Run: export failed")
+			message := err.Error()
+			export.Status = model.DataExportStatusFailed
+			export.Error = &message
+			if err := s.exportRepo.Update(ctx, export); err != nil {
+				log.Error().Err(err).Int64("export_id", export.ID).Msg("DataExportService.Run: failed to mark export failed")
+			}
+		}
+	}
+
+	return nil
+}
+
+// process builds the archive, stores it, and notifies the user
+func (s *DataExportService) process(ctx context.Context, export *model.DataExport) error {
+	user, err := s.userRepo.FindByID(ctx, export.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	tmp, err := os.CreateTemp("", "data-export-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := s.writeArchive(ctx, tmp, user); err != nil {
+		return err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to measure archive: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind archive: %w", err)
+	}
+
+	storagePath := fmt.Sprintf("exports/%d/%s.zip", user.ID, uuid.New().String())
+	if _, err := s.storage.Upload(ctx, storagePath, tmp, size, "application/zip"); err != nil {
+		return fmt.Errorf("failed to upload archive: %w", err)
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate download token: %w", err)
+	}
+	// The download link is on PUBLIC_API_URL, shared by all tenants
+	token = tenant.TokenWithSlug(s.cfg.Tenant, token)
+	tokenHash := hashToken(token)
+
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.LinkTTL)
+	export.Status = model.DataExportStatusCompleted
+	export.StoragePath = &storagePath
+	export.FileSize = size
+	export.TokenHash = &tokenHash
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	export.Error = nil
+	if err := s.exportRepo.Update(ctx, export); err != nil {
+		return fmt.Errorf("failed to save export: %w", err)
+	}
+
+	if err := s.notify(ctx, user, token, expiresAt); err != nil {
+		// The archive is still listed in the exports API, so a failed email is not fatal
+		log.Error().Err(err).Int64("export_id", export.ID).Msg("DataExportService.process: failed to send notification")
+	}
+	return nil
+}
+
+// writeArchive writes all of the user's data as JSON files plus attachment contents
+func (s *DataExportService) writeArchive(ctx context.Context, w io.Writer, user *model.User) error {
+	zw := zip.NewWriter(w)
+
+	pref, err := s.prefRepo.FindOrDefault(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch preferences: %w", err)
+	}
+	todos, err := s.todoRepo.FindAllByUserIDWithRelations(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch todos: %w", err)
+	}
+	categories, err := s.categoryRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch categories: %w", err)
+	}
+	tags, err := s.tagRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	comments, err := s.commentRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch comments: %w", err)
+	}
+	histories, err := s.historyRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch histories: %w", err)
+	}
+	notes, err := s.noteRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch notes: %w", err)
+	}
+	files, err := s.fileRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch files: %w", err)
+	}
+	focusSessions, err := s.focusRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch focus sessions: %w", err)
+	}
+	streak, err := s.streakRepo.FindByUserID(ctx, user.ID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to fetch streak: %w", err)
+	}
+	achievements, err := s.streakRepo.ListAchievements(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch achievements: %w", err)
+	}
+	consents, err := s.consentRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch policy consents: %w", err)
+	}
+	links, err := s.todoLinkRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch todo links: %w", err)
+	}
+	myDayItems, err := s.myDayRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch My Day items: %w", err)
+	}
+	escalationRules, err := s.escalationRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch escalation rules: %w", err)
+	}
+	// Secrets and OAuth tokens are not serialized
+	integrations, err := s.integrationRepo.FindAllByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch integrations: %w", err)
+	}
+	calendarConnection, err := s.calendarRepo.FindConnectionByUserID(ctx, user.ID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to fetch calendar connection: %w", err)
+	}
+
+	// Attachments go first, so that attachments.json can flag the ones whose contents are gone
+	attachments := make([]exportedAttachment, len(files))
+	for i := range files {
+		missing, err := s.writeAttachment(ctx, zw, &files[i])
+		if err != nil {
+			return err
+		}
+		attachments[i] = exportedAttachment{File: files[i], Missing: missing}
+	}
+
+	documents := []struct {
+		name string
+		data any
+	}{
+		{"account.json", map[string]any{"user": user, "preferences": pref}},
+		{"todos.json", todos},
+		{"categories.json", categories},
+		{"tags.json", tags},
+		{"comments.json", comments},
+		{"todo_histories.json", histories},
+		{"notes.json", notes},
+		{"attachments.json", attachments},
+		{"focus_sessions.json", focusSessions},
+		{"streaks.json", map[string]any{"streak": streak, "achievements": achievements}},
+		{"policy_consents.json", consents},
+		{"todo_links.json", links},
+		{"my_day.json", myDayItems},
+		{"escalation_rules.json", escalationRules},
+		{"integrations.json", integrations},
+		{"calendar.json", map[string]any{"connection": calendarConnection}},
+	}
+	for _, doc := range documents {
+		if err := writeJSONEntry(zw, doc.name, doc.data); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	return nil
+}
+
+// exportedAttachment is the attachment metadata written to the archive
+type exportedAttachment struct {
+	model.File
+	// Missing is set when the stored contents no longer exist
+	Missing bool `json:"missing,omitempty"`
+}
+
+// writeAttachment copies a stored file into the archive; infected files are left out.
+// Returns true if the stored contents no longer exist, which does not fail the export.
+func (s *DataExportService) writeAttachment(ctx context.Context, zw *zip.Writer, file *model.File) (bool, error) {
+	if file.IsInfected() {
+		return false, nil
+	}
+
+	reader, err := s.storage.Download(ctx, file.StoragePath)
+	if err != nil {
+		// Only a confirmed missing object is skipped; anything else may be transient and is retried
+		if exists, existsErr := s.storage.Exists(ctx, file.StoragePath); existsErr == nil && !exists {
+			log.Warn().Int64("file_id", file.ID).Str("path", file.StoragePath).Msg("DataExportService.writeAttachment: attachment missing from storage")
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to download attachment %d: %w", file.ID, err)
+	}
+	defer reader.Close()
+
+	name := fmt.Sprintf("attachments/%d_%s", file.ID, path.Base(file.OriginalName))
+	entry, err := zw.Create(name)
+	if err != nil {
+		return false, fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := io.Copy(entry, reader); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return false, nil
+}
+
+// notify emails the user a time-limited download link
+func (s *DataExportService) notify(ctx context.Context, user *model.User, token string, expiresAt time.Time) error {
+	pref, err := s.prefRepo.FindOrDefault(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch preferences: %w", err)
+	}
+
+	data := DataExportEmail{
+		UserName:    util.DerefString(user.Name, user.Email),
+		DownloadURL: fmt.Sprintf("%s/exports/download?token=%s", s.cfg.PublicAPIURL, token),
+		ExpiresAt:   expiresAt.In(pref.Location()).Format("2006-01-02 15:04 MST"),
+	}
+	text, html, err := mailer.Render("data_export", data)
+	if err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:       user.Email,
+		Subject:  "[Todo] データエクスポートの準備ができました",
+		TextBody: text,
+		HTMLBody: html,
+	})
+}
+
+// expire deletes archives whose download link has expired
+func (s *DataExportService) expire(ctx context.Context, now time.Time) {
+	exports, err := s.exportRepo.FindExpired(ctx, now)
+	if err != nil {
+		log.Error().Err(err).Msg("DataExportService.expire: failed to fetch expired exports")
+		return
+	}
+
+	for i := range exports {
+		export := &exports[i]
+		if export.StoragePath != nil {
+			if err := s.storage.Delete(ctx, *export.StoragePath); err != nil {
+				log.Error().Err(err).Int64("export_id", export.ID).Msg("DataExportService.expire: failed to delete archive")
+				continue
+			}
+		}
+		export.Status = model.DataExportStatusExpired
+		export.StoragePath = nil
+		export.TokenHash = nil
+		if err := s.exportRepo.Update(ctx, export); err != nil {
+			log.Error().Err(err).Int64("export_id", export.ID).Msg("DataExportService.expire: failed to update export")
+		}
+	}
+}
+
+// writeJSONEntry adds an indented JSON document to the archive
+func writeJSONEntry(zw *zip.Writer, name string, data any) error {
+	entry, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	enc := json.NewEncoder(entry)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return nil
+}
+
+// generateToken returns a random URL-safe download token
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken returns the SHA-256 hex digest stored in place of the raw token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
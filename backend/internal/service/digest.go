@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/mailer"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/pkg/util"
+)
+
+// DigestJobInterval is how often the digest job checks for users whose send hour has arrived
+const DigestJobInterval = 15 * time.Minute
+
+// DigestService composes and sends per-user summary email digests
+type DigestService struct {
+	prefRepo *repository.UserPreferenceRepository
+	todoRepo *repository.TodoRepository
+	mailer   mailer.Mailer
+}
+
+// NewDigestService creates a new DigestService
+func NewDigestService(
+	prefRepo *repository.UserPreferenceRepository,
+	todoRepo *repository.TodoRepository,
+	m mailer.Mailer,
+) *DigestService {
+	return &DigestService{
+		prefRepo: prefRepo,
+		todoRepo: todoRepo,
+		mailer:   m,
+	}
+}
+
+// DigestItem represents a single todo line in a digest
+type DigestItem struct {
+	Title    string
+	DueDate  string
+	Priority string
+	Category string
+}
+
+// Digest represents the rendered content of a digest email
+type Digest struct {
+	UserName       string
+	Date           string
+	PeriodLabel    string
+	DueLabel       string
+	CompletedLabel string
+	Due            []DigestItem
+	Overdue        []DigestItem
+	Completed      []DigestItem
+}
+
+// IsEmpty returns true if the digest has nothing to report
+func (d *Digest) IsEmpty() bool {
+	return len(d.Due) == 0 && len(d.Overdue) == 0 && len(d.Completed) == 0
+}
+
+// Run sends digests to every subscriber whose preferred send time has arrived.
+// It is intended to be called periodically by the scheduler.
+func (s *DigestService) Run(ctx context.Context, now time.Time) error {
+	prefs, err := s.prefRepo.FindDigestSubscribers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch digest subscribers: %w", err)
+	}
+
+	for i := range prefs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pref := &prefs[i]
+		if pref.User == nil || !s.isDue(pref, now) {
+			continue
+		}
+
+		if err := s.send(ctx, pref, now); err != nil {
+			log.Error().Err(err).Int64("user_id", pref.UserID).Msg("DigestService.Run: failed to send digest")
+		}
+	}
+
+	return nil
+}
+
+// isDue checks whether the user's digest should be sent at the given time
+func (s *DigestService) isDue(pref *model.UserPreference, now time.Time) bool {
+	loc := pref.Location()
+	local := now.In(loc)
+
+	if local.Hour() != pref.DigestHour {
+		return false
+	}
+	if pref.DigestFrequency == model.DigestFrequencyWeekly && local.Weekday() != time.Monday {
+		return false
+	}
+	if pref.DigestLastSentAt != nil {
+		last := pref.DigestLastSentAt.In(loc)
+		if last.Year() == local.Year() && last.YearDay() == local.YearDay() {
+			return false
+		}
+	}
+	return true
+}
+
+// send builds, renders, and delivers the digest for a single user
+func (s *DigestService) send(ctx context.Context, pref *model.UserPreference, now time.Time) error {
+	digest, err := s.Build(ctx, pref, now)
+	if err != nil {
+		return err
+	}
+	if digest.IsEmpty() {
+		return nil
+	}
+
+	text, html, err := mailer.Render("digest", digest)
+	if err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	msg := &mailer.Message{
+		To:       pref.User.Email,
+		Subject:  fmt.Sprintf("[Todo] %sのサマリー (%s)", digest.PeriodLabel, digest.Date),
+		TextBody: text,
+		HTMLBody: html,
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return err
+	}
+
+	return s.prefRepo.MarkDigestSent(ctx, pref.ID, now)
+}
+
+// Build collects the todos for the user's digest period relative to now in the user's timezone
+func (s *DigestService) Build(ctx context.Context, pref *model.UserPreference, now time.Time) (*Digest, error) {
+	loc := pref.Location()
+	local := now.In(loc)
+
+	// Start of the user's local day, and the same calendar date as a UTC midnight for date columns
+	startOfDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	days := 1
+	digest := &Digest{
+		Date:           today.Format("2006-01-02"),
+		PeriodLabel:    "今日",
+		DueLabel:       "今日",
+		CompletedLabel: "昨日",
+	}
+	if pref.DigestFrequency == model.DigestFrequencyWeekly {
+		days = 7
+		digest.PeriodLabel = "今週"
+		digest.DueLabel = "今週中"
+		digest.CompletedLabel = "先週"
+	}
+	if pref.User != nil {
+		digest.UserName = util.DerefString(pref.User.Name, pref.User.Email)
+	}
+
+	due, err := s.todoRepo.FindDueBetween(ctx, pref.UserID, today, today.AddDate(0, 0, days-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch due todos: %w", err)
+	}
+	overdue, err := s.todoRepo.FindOverdue(ctx, pref.UserID, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch overdue todos: %w", err)
+	}
+	// In UTC, as SQLite compares timestamps as text
+	completed, err := s.todoRepo.FindCompletedBetween(ctx, pref.UserID, startOfDay.AddDate(0, 0, -days).UTC(), startOfDay.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch completed todos: %w", err)
+	}
+
+	digest.Due = toDigestItems(due)
+	digest.Overdue = toDigestItems(overdue)
+	digest.Completed = toDigestItems(completed)
+
+	return digest, nil
+}
+
+// toDigestItems converts todos to digest lines
+func toDigestItems(todos []model.Todo) []DigestItem {
+	items := make([]DigestItem, len(todos))
+	for i, todo := range todos {
+		items[i] = DigestItem{
+			Title:    todo.Title,
+			DueDate:  util.DerefString(util.FormatDate(todo.DueDate), ""),
+			Priority: priorityLabel(todo.Priority),
+		}
+		if todo.Category != nil {
+			items[i].Category = todo.Category.Name
+		}
+	}
+	return items
+}
+
+// priorityLabel returns the Japanese label for a priority
+func priorityLabel(p model.Priority) string {
+	switch p {
+	case model.PriorityLow:
+		return "低"
+	case model.PriorityHigh:
+		return "高"
+	default:
+		return "中"
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/encryption"
+	"todo-api/internal/repository"
+)
+
+const (
+	// ReencryptionJobInterval is how often values are re-encrypted with the active key
+	ReencryptionJobInterval = time.Hour
+	// reencryptionBatchSize is the number of values read per query
+	reencryptionBatchSize = 500
+)
+
+// ReencryptionService encrypts values stored in plaintext or with a previous key with the active key.
+// After it has run once following a key rotation, the previous key can be removed from the configuration.
+type ReencryptionService struct {
+	encryptionRepo *repository.EncryptionRepository
+}
+
+// NewReencryptionService creates a new ReencryptionService
+func NewReencryptionService(encryptionRepo *repository.EncryptionRepository) *ReencryptionService {
+	return &ReencryptionService{encryptionRepo: encryptionRepo}
+}
+
+// Run re-encrypts every encrypted column. It does nothing while encryption is disabled.
+func (s *ReencryptionService) Run(ctx context.Context, _ time.Time) error {
+	ring := encryption.CurrentKeyRing()
+	if ring == nil {
+		return nil
+	}
+
+	for _, column := range repository.EncryptedColumns {
+		count, err := s.reencryptColumn(ctx, ring, column)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			log.Info().
+				Str("column", column.Table+"."+column.Column).
+				Str("key_id", ring.ActiveKeyID()).
+				Int("count", count).
+				Msg("ReencryptionService.Run: re-encrypted values")
+		}
+	}
+	return nil
+}
+
+// reencryptColumn re-encrypts the values of one column and returns how many were replaced.
+// Values that cannot be decrypted (e.g. with a removed key) are logged and skipped.
+func (s *ReencryptionService) reencryptColumn(ctx context.Context, ring *encryption.KeyRing, column repository.EncryptedColumn) (int, error) {
+	count := 0
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		values, err := s.encryptionRepo.FindNotEncryptedWith(ctx, column, ring.ActivePrefix(), afterID, reencryptionBatchSize)
+		if err != nil {
+			return count, fmt.Errorf("failed to fetch %s.%s: %w", column.Table, column.Column, err)
+		}
+
+		for _, value := range values {
+			afterID = value.ID
+
+			plaintext, err := ring.Decrypt(value.Value)
+			if err != nil {
+				log.Error().Err(err).
+					Str("column", column.Table+"."+column.Column).
+					Int64("id", value.ID).
+					Msg("ReencryptionService.Run: failed to decrypt value")
+				continue
+			}
+			encrypted, err := ring.Encrypt(plaintext)
+			if err != nil {
+				return count, err
+			}
+
+			// A value changed concurrently has been encrypted with the active key already
+			replaced, err := s.encryptionRepo.ReplaceValue(ctx, column, value.ID, value.Value, encrypted)
+			if err != nil {
+				return count, fmt.Errorf("failed to update %s.%s: %w", column.Table, column.Column, err)
+			}
+			if replaced {
+				count++
+			}
+		}
+
+		if len(values) < reencryptionBatchSize {
+			return count, nil
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/mailer"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/pkg/util"
+)
+
+// EscalationJobInterval is how often escalation rules are evaluated
+const EscalationJobInterval = 15 * time.Minute
+
+// EscalationService raises the priority of todos whose due date is near, following per-user rules
+type EscalationService struct {
+	ruleRepo    *repository.EscalationRuleRepository
+	prefRepo    *repository.UserPreferenceRepository
+	todoRepo    *repository.TodoRepository
+	historyRepo *repository.TodoHistoryRepository
+	mailer      mailer.Mailer
+}
+
+// NewEscalationService creates a new EscalationService
+func NewEscalationService(
+	ruleRepo *repository.EscalationRuleRepository,
+	prefRepo *repository.UserPreferenceRepository,
+	todoRepo *repository.TodoRepository,
+	historyRepo *repository.TodoHistoryRepository,
+	m mailer.Mailer,
+) *EscalationService {
+	return &EscalationService{
+		ruleRepo:    ruleRepo,
+		prefRepo:    prefRepo,
+		todoRepo:    todoRepo,
+		historyRepo: historyRepo,
+		mailer:      m,
+	}
+}
+
+// EscalationRuleInput represents input for creating or updating a rule; nil keeps the current value
+type EscalationRuleInput struct {
+	WithinHours *int
+	Priority    *string
+	Notify      *bool
+	Enabled     *bool
+}
+
+// Escalation is a todo whose priority a rule raises
+type Escalation struct {
+	Todo model.Todo
+	From model.Priority
+	To   model.Priority
+	Rule *model.EscalationRule
+	// Deadline is the end of the due date in the user's time zone
+	Deadline time.Time
+}
+
+// EscalationEmail is the template data for the escalation notification
+type EscalationEmail struct {
+	UserName string
+	Items    []EscalationEmailItem
+}
+
+// EscalationEmailItem is a single escalated todo in the notification
+type EscalationEmailItem struct {
+	Title   string
+	DueDate string
+	From    string
+	To      string
+}
+
+// List returns the user's rules
+func (s *EscalationService) List(ctx context.Context, userID int64) ([]model.EscalationRule, error) {
+	rules, err := s.ruleRepo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "EscalationService.List: failed to fetch rules")
+	}
+	return rules, nil
+}
+
+// Create adds a rule. Rules raise to high, notify, and are enabled unless specified otherwise.
+func (s *EscalationService) Create(ctx context.Context, userID int64, input EscalationRuleInput) (*model.EscalationRule, error) {
+	rule := &model.EscalationRule{
+		UserID:   userID,
+		Priority: model.PriorityHigh,
+		Notify:   true,
+		Enabled:  true,
+	}
+	if input.WithinHours == nil {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"within_hours": {"is required"},
+		})
+	}
+	if err := applyEscalationRuleInput(rule, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "EscalationService.Create: failed to create rule")
+	}
+	return rule, nil
+}
+
+// Update changes a rule
+func (s *EscalationService) Update(ctx context.Context, id, userID int64, input EscalationRuleInput) (*model.EscalationRule, error) {
+	rule, err := s.ruleRepo.FindByID(ctx, id, userID)
+	if err != nil {
+		return nil, err // Let handler handle gorm.ErrRecordNotFound
+	}
+	if err := applyEscalationRuleInput(rule, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "EscalationService.Update: failed to update rule")
+	}
+	return rule, nil
+}
+
+// Delete removes a rule
+func (s *EscalationService) Delete(ctx context.Context, id, userID int64) error {
+	return s.ruleRepo.Delete(ctx, id, userID)
+}
+
+// Preview returns the escalations the user's enabled rules would make right now. Nothing is changed.
+func (s *EscalationService) Preview(ctx context.Context, userID int64, now time.Time) ([]Escalation, error) {
+	rules, err := s.ruleRepo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "EscalationService.Preview: failed to fetch rules")
+	}
+
+	enabled := make([]model.EscalationRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Enabled {
+			enabled = append(enabled, rule)
+		}
+	}
+
+	escalations, err := s.evaluate(ctx, userID, enabled, now)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "EscalationService.Preview: failed to evaluate rules")
+	}
+	return escalations, nil
+}
+
+// Run applies the enabled rules of every user and notifies them of the escalated todos.
+// It is intended to be called periodically by the scheduler.
+func (s *EscalationService) Run(ctx context.Context, now time.Time) error {
+	rules, err := s.ruleRepo.FindEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch escalation rules: %w", err)
+	}
+
+	// Rules are ordered by user, so each user's rules are contiguous
+	for start := 0; start < len(rules); {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := start
+		for end < len(rules) && rules[end].UserID == rules[start].UserID {
+			end++
+		}
+		userRules := rules[start:end]
+		start = end
+
+		userID := userRules[0].UserID
+		escalations, err := s.Apply(ctx, userID, userRules, now)
+		if err != nil {
+			log.Error().Err(err).Int64("user_id", userID).Msg("EscalationService.Run: failed to apply escalation rules")
+		}
+		if len(escalations) == 0 {
+			continue
+		}
+		log.Info().Int64("user_id", userID).Int("escalated_todos", len(escalations)).Msg("Escalation rules applied")
+
+		if err := s.notify(ctx, userRules[0].User, escalations); err != nil {
+			log.Error().Err(err).Int64("user_id", userID).Msg("EscalationService.Run: failed to send notification")
+		}
+	}
+
+	return nil
+}
+
+// Apply raises the priority of the user's todos matched by the rules and records each change in history.
+// The escalations made before an error are returned along with it.
+func (s *EscalationService) Apply(ctx context.Context, userID int64, rules []model.EscalationRule, now time.Time) ([]Escalation, error) {
+	escalations, err := s.evaluate(ctx, userID, rules, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, e := range escalations {
+		if err := s.todoRepo.UpdatePriority(ctx, e.Todo.ID, userID, e.To); err != nil {
+			return escalations[:i], fmt.Errorf("failed to escalate todo %d: %w", e.Todo.ID, err)
+		}
+		if err := s.recordHistory(ctx, userID, &e); err != nil {
+			log.Error().Err(err).Int64("todo_id", e.Todo.ID).Msg("EscalationService.Apply: failed to record history")
+		}
+	}
+	return escalations, nil
+}
+
+// evaluate finds the todos the rules would escalate at now.
+// When several rules match a todo, the one with the highest priority wins.
+// A todo is escalated to a priority only once, so lowering it by hand afterwards sticks.
+func (s *EscalationService) evaluate(ctx context.Context, userID int64, rules []model.EscalationRule, now time.Time) ([]Escalation, error) {
+	if len(rules) == 0 {
+		return []Escalation{}, nil
+	}
+
+	pref, err := s.prefRepo.FindOrDefault(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch preferences: %w", err)
+	}
+	loc := pref.Location()
+
+	maxHours := 0
+	for _, rule := range rules {
+		maxHours = max(maxHours, rule.WithinHours)
+	}
+	// Same calendar date as a UTC midnight for the date column
+	horizon := now.Add(time.Duration(maxHours) * time.Hour).In(loc)
+	lastDate := time.Date(horizon.Year(), horizon.Month(), horizon.Day(), 0, 0, 0, 0, time.UTC)
+
+	todos, err := s.todoRepo.FindDueOnOrBefore(ctx, userID, lastDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch todos: %w", err)
+	}
+
+	escalations := []Escalation{}
+	for _, todo := range todos {
+		due := *todo.DueDate
+		deadline := time.Date(due.Year(), due.Month(), due.Day()+1, 0, 0, 0, 0, loc)
+		remaining := deadline.Sub(now)
+
+		var match *model.EscalationRule
+		for i := range rules {
+			if remaining > time.Duration(rules[i].WithinHours)*time.Hour {
+				continue
+			}
+			if match == nil || rules[i].Priority > match.Priority {
+				match = &rules[i]
+			}
+		}
+		if match == nil || todo.Priority >= match.Priority {
+			continue
+		}
+
+		escalated, err := s.historyRepo.HasEscalation(ctx, todo.ID, todo.UserID, prioritiesAtLeast(match.Priority))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check escalation history: %w", err)
+		}
+		if escalated {
+			continue
+		}
+
+		escalations = append(escalations, Escalation{
+			Todo:     todo,
+			From:     todo.Priority,
+			To:       match.Priority,
+			Rule:     match,
+			Deadline: deadline,
+		})
+	}
+	return escalations, nil
+}
+
+// recordHistory records an escalation as a history entry of the todo owner
+func (s *EscalationService) recordHistory(ctx context.Context, userID int64, e *Escalation) error {
+	changes, err := json.Marshal(map[string]interface{}{
+		"priority":     []string{e.From.String(), e.To.String()},
+		"rule_id":      e.Rule.ID,
+		"within_hours": e.Rule.WithinHours,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.historyRepo.Create(ctx, &model.TodoHistory{
+		TodoID:  e.Todo.ID,
+		UserID:  userID,
+		Action:  model.ActionPriorityEscalated,
+		Changes: changes,
+	})
+}
+
+// notify emails the user the todos escalated by rules that have notifications on
+func (s *EscalationService) notify(ctx context.Context, user *model.User, escalations []Escalation) error {
+	if user == nil {
+		return nil
+	}
+
+	data := EscalationEmail{UserName: util.DerefString(user.Name, user.Email)}
+	for _, e := range escalations {
+		if !e.Rule.Notify {
+			continue
+		}
+		data.Items = append(data.Items, EscalationEmailItem{
+			Title:   e.Todo.Title,
+			DueDate: util.DerefString(util.FormatDate(e.Todo.DueDate), ""),
+			From:    priorityLabel(e.From),
+			To:      priorityLabel(e.To),
+		})
+	}
+	if len(data.Items) == 0 {
+		return nil
+	}
+
+	text, html, err := mailer.Render("escalation", data)
+	if err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:       user.Email,
+		Subject:  fmt.Sprintf("[Todo] 期限が近い%d件のTodoの優先度を上げました", len(data.Items)),
+		TextBody: text,
+		HTMLBody: html,
+	})
+}
+
+// applyEscalationRuleInput validates and applies rule changes
+func applyEscalationRuleInput(rule *model.EscalationRule, input EscalationRuleInput) error {
+	fieldErrors := map[string][]string{}
+
+	if input.WithinHours != nil {
+		if *input.WithinHours < 1 || *input.WithinHours > model.MaxEscalationWithinHours {
+			fieldErrors["within_hours"] = []string{fmt.Sprintf("must be between 1 and %d", model.MaxEscalationWithinHours)}
+		} else {
+			rule.WithinHours = *input.WithinHours
+		}
+	}
+	if input.Priority != nil {
+		switch *input.Priority {
+		case "medium":
+			rule.Priority = model.PriorityMedium
+		case "high":
+			rule.Priority = model.PriorityHigh
+		default:
+			fieldErrors["priority"] = []string{"Invalid priority. Valid values: medium, high"}
+		}
+	}
+	if input.Notify != nil {
+		rule.Notify = *input.Notify
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+
+	if len(fieldErrors) > 0 {
+		return errors.ValidationFailed(fieldErrors)
+	}
+	return nil
+}
+
+// prioritiesAtLeast returns the names of p and every higher priority
+func prioritiesAtLeast(p model.Priority) []string {
+	var names []string
+	for q := p; q <= model.PriorityHigh; q++ {
+		names = append(names, q.String())
+	}
+	return names
+}
@@ -23,6 +23,7 @@ type FileService struct {
 	todoRepo *repository.TodoRepository
 	storage  storage.Storage
 	thumbSvc *ThumbnailService
+	scanSvc  *FileScanService
 }
 
 // NewFileService creates a new FileService
@@ -31,12 +32,14 @@ func NewFileService(
 	todoRepo *repository.TodoRepository,
 	storage storage.Storage,
 	thumbSvc *ThumbnailService,
+	scanSvc *FileScanService,
 ) *FileService {
 	return &FileService{
 		fileRepo: fileRepo,
 		todoRepo: todoRepo,
 		storage:  storage,
 		thumbSvc: thumbSvc,
+		scanSvc:  scanSvc,
 	}
 }
 
@@ -67,7 +70,7 @@ func (s *FileService) Upload(ctx context.Context, input UploadInput) (*model.Fil
 	}
 
 	// Validate todo ownership
-	if _, err := s.todoRepo.FindByID(input.TodoID, input.UserID); err != nil {
+	if _, err := s.todoRepo.FindByID(ctx, input.TodoID, input.UserID); err != nil {
 		return nil, errors.NotFound("Todo", input.TodoID)
 	}
 
@@ -96,6 +99,7 @@ func (s *FileService) Upload(ctx context.Context, input UploadInput) (*model.Fil
 		ContentType:    input.ContentType,
 		FileSize:       input.FileSize,
 		FileType:       model.GetFileType(input.ContentType),
+		ScanStatus:     s.scanSvc.InitialStatus(),
 	}
 
 	// Generate thumbnails for images
@@ -111,24 +115,27 @@ func (s *FileService) Upload(ctx context.Context, input UploadInput) (*model.Fil
 	}
 
 	// Save to database
-	if err := s.fileRepo.Create(file); err != nil {
+	if err := s.fileRepo.Create(ctx, file); err != nil {
 		// Cleanup uploaded files on failure
 		s.cleanupStoredFiles(ctx, file)
 		return nil, errors.InternalErrorWithLog(err, "FileService.Upload: failed to save file record")
 	}
 
+	// Scan for malware in the background; the file is served only after it is not found infected
+	s.scanSvc.ScanAsync(file, buf.Bytes())
+
 	return file, nil
 }
 
 // Delete deletes a file
 func (s *FileService) Delete(ctx context.Context, fileID, todoID, userID int64) error {
 	// Validate todo ownership
-	if _, err := s.todoRepo.FindByID(todoID, userID); err != nil {
+	if _, err := s.todoRepo.FindByID(ctx, todoID, userID); err != nil {
 		return errors.NotFound("Todo", todoID)
 	}
 
 	// Get file
-	file, err := s.fileRepo.FindByID(fileID)
+	file, err := s.fileRepo.FindByID(ctx, fileID, userID)
 	if err != nil {
 		return errors.NotFound("File", fileID)
 	}
@@ -147,7 +154,7 @@ func (s *FileService) Delete(ctx context.Context, fileID, todoID, userID int64)
 	s.cleanupStoredFiles(ctx, file)
 
 	// Delete from database
-	if err := s.fileRepo.Delete(fileID); err != nil {
+	if err := s.fileRepo.Delete(ctx, fileID, userID); err != nil {
 		return errors.InternalErrorWithLog(err, "FileService.Delete: failed to delete file record")
 	}
 
@@ -157,12 +164,12 @@ func (s *FileService) Delete(ctx context.Context, fileID, todoID, userID int64)
 // Download returns a reader for downloading a file
 func (s *FileService) Download(ctx context.Context, fileID, todoID, userID int64) (io.ReadCloser, *model.File, error) {
 	// Validate todo ownership
-	if _, err := s.todoRepo.FindByID(todoID, userID); err != nil {
+	if _, err := s.todoRepo.FindByID(ctx, todoID, userID); err != nil {
 		return nil, nil, errors.NotFound("Todo", todoID)
 	}
 
 	// Get file
-	file, err := s.fileRepo.FindByID(fileID)
+	file, err := s.fileRepo.FindByID(ctx, fileID, userID)
 	if err != nil {
 		return nil, nil, errors.NotFound("File", fileID)
 	}
@@ -172,6 +179,10 @@ func (s *FileService) Download(ctx context.Context, fileID, todoID, userID int64
 		return nil, nil, errors.NotFound("File", fileID)
 	}
 
+	if err := checkServable(file); err != nil {
+		return nil, nil, err
+	}
+
 	// Download from storage
 	reader, err := s.storage.Download(ctx, file.StoragePath)
 	if err != nil {
@@ -184,12 +195,12 @@ func (s *FileService) Download(ctx context.Context, fileID, todoID, userID int64
 // DownloadThumbnail returns a reader for downloading a thumbnail
 func (s *FileService) DownloadThumbnail(ctx context.Context, fileID, todoID, userID int64, size string) (io.ReadCloser, *model.File, error) {
 	// Validate todo ownership
-	if _, err := s.todoRepo.FindByID(todoID, userID); err != nil {
+	if _, err := s.todoRepo.FindByID(ctx, todoID, userID); err != nil {
 		return nil, nil, errors.NotFound("Todo", todoID)
 	}
 
 	// Get file
-	file, err := s.fileRepo.FindByID(fileID)
+	file, err := s.fileRepo.FindByID(ctx, fileID, userID)
 	if err != nil {
 		return nil, nil, errors.NotFound("File", fileID)
 	}
@@ -199,6 +210,10 @@ func (s *FileService) DownloadThumbnail(ctx context.Context, fileID, todoID, use
 		return nil, nil, errors.NotFound("File", fileID)
 	}
 
+	if err := checkServable(file); err != nil {
+		return nil, nil, err
+	}
+
 	// Verify it's an image
 	if !file.IsImage() {
 		return nil, nil, errors.ValidationFailed(map[string][]string{
@@ -235,11 +250,11 @@ func (s *FileService) DownloadThumbnail(ctx context.Context, fileID, todoID, use
 // ListByTodo returns all files for a todo
 func (s *FileService) ListByTodo(ctx context.Context, todoID, userID int64) ([]model.File, error) {
 	// Validate todo ownership
-	if _, err := s.todoRepo.FindByID(todoID, userID); err != nil {
+	if _, err := s.todoRepo.FindByID(ctx, todoID, userID); err != nil {
 		return nil, errors.NotFound("Todo", todoID)
 	}
 
-	files, err := s.fileRepo.FindByAttachable(model.AttachableTypeTodo, todoID)
+	files, err := s.fileRepo.FindByAttachable(ctx, model.AttachableTypeTodo, todoID, userID)
 	if err != nil {
 		return nil, errors.InternalErrorWithLog(err, "FileService.ListByTodo: failed to list files")
 	}
@@ -272,3 +287,15 @@ func (s *FileService) cleanupStoredFiles(ctx context.Context, file *model.File)
 		}
 	}
 }
+
+// checkServable refuses the content of files that are infected or not scanned yet.
+// Files awaiting a scan are held back until it succeeds, as they may turn out to be infected.
+func checkServable(file *model.File) error {
+	if file.IsInfected() {
+		return errors.FileInfected(file.ID)
+	}
+	if file.IsAwaitingScan() {
+		return errors.FileNotScanned(file.ID)
+	}
+	return nil
+}
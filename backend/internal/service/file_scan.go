@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/scanner"
+	"todo-api/internal/storage"
+)
+
+const (
+	// FileScanJobInterval is how often files left unscanned (e.g. after a restart or scanner outage) are retried
+	FileScanJobInterval = 10 * time.Minute
+	// fileScanRetryAfter is how long a file stays pending, errored, or infected but not quarantined before it is retried
+	fileScanRetryAfter = 10 * time.Minute
+	// fileScanBatchSize limits the number of files rescanned per job run
+	fileScanBatchSize = 100
+	// fileScanTimeout bounds a single asynchronous scan
+	fileScanTimeout = 5 * time.Minute
+	// quarantinePrefix is the storage prefix infected files are moved under
+	quarantinePrefix = "quarantine/"
+)
+
+// FileScanService scans uploaded files for malware and quarantines infected ones
+type FileScanService struct {
+	fileRepo *repository.FileRepository
+	storage  storage.Storage
+	scanner  scanner.Scanner
+}
+
+// NewFileScanService creates a new FileScanService.
+// A nil scanner disables scanning; new files are then marked as skipped.
+func NewFileScanService(
+	fileRepo *repository.FileRepository,
+	storage storage.Storage,
+	sc scanner.Scanner,
+) *FileScanService {
+	return &FileScanService{
+		fileRepo: fileRepo,
+		storage:  storage,
+		scanner:  sc,
+	}
+}
+
+// Enabled returns true if a scanner backend is configured
+func (s *FileScanService) Enabled() bool {
+	return s != nil && s.scanner != nil
+}
+
+// InitialStatus returns the scan status to store for a newly uploaded file
+func (s *FileScanService) InitialStatus() model.ScanStatus {
+	if s.Enabled() {
+		return model.ScanStatusPending
+	}
+	return model.ScanStatusSkipped
+}
+
+// ScanAsync scans a newly uploaded file in the background
+func (s *FileScanService) ScanAsync(file *model.File, content []byte) {
+	if !s.Enabled() {
+		return
+	}
+
+	// Copy so the caller's struct is not mutated concurrently
+	f := *file
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), fileScanTimeout)
+		defer cancel()
+
+		if err := s.Scan(ctx, &f, content); err != nil {
+			log.Error().Err(err).Int64("file_id", f.ID).Msg("FileScanService.ScanAsync: scan failed")
+		}
+	}()
+}
+
+// Scan scans the file content, records the result, and quarantines the file if infected.
+// An infected file that could not be quarantined is still recorded as infected, so it is not served,
+// and RescanPending retries the quarantine. A file deleted while it was being scanned is left alone,
+// and a quarantine copy made just before its deletion is removed.
+func (s *FileScanService) Scan(ctx context.Context, file *model.File, content []byte) error {
+	result, scanErr := s.scanner.Scan(ctx, bytes.NewReader(content))
+
+	now := time.Now()
+	file.ScannedAt = &now
+
+	var quarantineErr error
+	quarantined := false
+	switch {
+	case scanErr != nil:
+		file.ScanStatus = model.ScanStatusError
+	case result.Infected:
+		file.ScanStatus = model.ScanStatusInfected
+		signature := result.Signature
+		file.ScanSignature = &signature
+		log.Warn().
+			Int64("file_id", file.ID).
+			Int64("user_id", file.UserID).
+			Str("signature", signature).
+			Msg("Infected file detected")
+
+		// The scan may finish after the user deleted the file, whose objects are then gone already
+		exists, err := s.fileRepo.ExistsByID(ctx, file.ID, file.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to check file: %w", err)
+		}
+		if !exists {
+			return nil
+		}
+		quarantineErr = s.quarantine(ctx, file, content)
+		quarantined = quarantineErr == nil
+	default:
+		file.ScanStatus = model.ScanStatusClean
+	}
+
+	updated, err := s.fileRepo.UpdateScanResult(ctx, file)
+	if err != nil {
+		return fmt.Errorf("failed to save scan result: %w", err)
+	}
+	if !updated {
+		// Deleted in the meantime: nothing refers to the quarantine copy
+		if quarantined {
+			if err := s.storage.Delete(ctx, file.StoragePath); err != nil {
+				return fmt.Errorf("failed to delete quarantine copy of deleted file: %w", err)
+			}
+		}
+		return nil
+	}
+	if scanErr != nil {
+		return fmt.Errorf("%s scan failed: %w", s.scanner.Name(), scanErr)
+	}
+	if quarantineErr != nil {
+		return fmt.Errorf("failed to quarantine infected file: %w", quarantineErr)
+	}
+	return nil
+}
+
+// quarantine moves the original into the quarantine prefix and removes derived thumbnails
+// so the content can no longer be served
+func (s *FileScanService) quarantine(ctx context.Context, file *model.File, content []byte) error {
+	quarantinePath := quarantinePrefix + file.StoragePath
+	if _, err := s.storage.Upload(ctx, quarantinePath, bytes.NewReader(content), int64(len(content)), file.ContentType); err != nil {
+		return fmt.Errorf("failed to copy to quarantine: %w", err)
+	}
+
+	for _, path := range []*string{&file.StoragePath, file.ThumbPath, file.MediumPath} {
+		if path == nil {
+			continue
+		}
+		if err := s.storage.Delete(ctx, *path); err != nil {
+			log.Error().Err(err).Str("path", *path).Msg("FileScanService.quarantine: failed to delete file")
+		}
+	}
+
+	now := time.Now()
+	file.StoragePath = quarantinePath
+	file.ThumbPath = nil
+	file.MediumPath = nil
+	file.QuarantinedAt = &now
+	return nil
+}
+
+// RescanPending retries files that are still pending, whose last scan failed, or that are infected
+// but could not be quarantined.
+// It is intended to be called periodically by the scheduler.
+func (s *FileScanService) RescanPending(ctx context.Context, now time.Time) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	files, err := s.fileRepo.FindScanRetryable(ctx, now.Add(-fileScanRetryAfter), fileScanBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to fetch unscanned files: %w", err)
+	}
+
+	for i := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		file := &files[i]
+		content, err := s.download(ctx, file.StoragePath)
+		if err != nil {
+			log.Error().Err(err).Int64("file_id", file.ID).Msg("FileScanService.RescanPending: failed to download file")
+			continue
+		}
+		if err := s.Scan(ctx, file, content); err != nil {
+			log.Error().Err(err).Int64("file_id", file.ID).Msg("FileScanService.RescanPending: scan failed")
+		}
+	}
+
+	return nil
+}
+
+// download reads a stored object into memory
+func (s *FileScanService) download(ctx context.Context, path string) ([]byte, error) {
+	reader, err := s.storage.Download(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
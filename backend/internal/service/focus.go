@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api/internal/constants"
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+)
+
+// MaxFocusStatsDays limits the range of the daily focus aggregate endpoint
+const MaxFocusStatsDays = 366
+
+// FocusService handles focus (Pomodoro) session business logic
+type FocusService struct {
+	focusRepo *repository.FocusSessionRepository
+	todoRepo  *repository.TodoRepository
+	prefRepo  *repository.UserPreferenceRepository
+}
+
+// NewFocusService creates a new FocusService
+func NewFocusService(
+	focusRepo *repository.FocusSessionRepository,
+	todoRepo *repository.TodoRepository,
+	prefRepo *repository.UserPreferenceRepository,
+) *FocusService {
+	return &FocusService{
+		focusRepo: focusRepo,
+		todoRepo:  todoRepo,
+		prefRepo:  prefRepo,
+	}
+}
+
+// StartFocusInput represents input for starting a focus session
+type StartFocusInput struct {
+	UserID          int64
+	TodoID          int64
+	DurationMinutes *int
+}
+
+// FocusStats represents daily focus-time aggregates over a date range
+type FocusStats struct {
+	From         string
+	To           string
+	Timezone     string
+	TotalSeconds int64
+	Days         []repository.DailyFocusTotal
+}
+
+// Start begins a new focus session. Only one session may run at a time per user.
+func (s *FocusService) Start(ctx context.Context, input StartFocusInput) (*model.FocusSession, error) {
+	if _, err := s.todoRepo.FindByID(ctx, input.TodoID, input.UserID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"todo_id": {"Todo not found or not owned by user"},
+			})
+		}
+		return nil, errors.InternalErrorWithLog(err, "FocusService.Start: failed to fetch todo")
+	}
+
+	minutes := model.DefaultFocusMinutes
+	if input.DurationMinutes != nil {
+		minutes = *input.DurationMinutes
+	}
+
+	session := &model.FocusSession{
+		UserID:         input.UserID,
+		TodoID:         input.TodoID,
+		StartedAt:      time.Now(),
+		PlannedSeconds: minutes * 60,
+	}
+	active, err := s.focusRepo.CreateUnlessActive(ctx, session)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "FocusService.Start: failed to create session")
+	}
+	if active != nil {
+		return nil, errors.FocusSessionAlreadyActive(active.ID)
+	}
+
+	return session, nil
+}
+
+// Stop ends a running focus session
+func (s *FocusService) Stop(ctx context.Context, sessionID, userID int64) (*model.FocusSession, error) {
+	session, err := s.focusRepo.FindByID(ctx, sessionID, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("FocusSession", sessionID)
+		}
+		return nil, errors.InternalErrorWithLog(err, "FocusService.Stop: failed to fetch session")
+	}
+
+	if !session.IsActive() {
+		return nil, errors.InvalidStateTransition("stopped", "stopped")
+	}
+
+	session.Stop(time.Now())
+	if err := s.focusRepo.Update(ctx, session); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "FocusService.Stop: failed to update session")
+	}
+
+	return session, nil
+}
+
+// Active returns the user's running session, or nil if none
+func (s *FocusService) Active(ctx context.Context, userID int64) (*model.FocusSession, error) {
+	session, err := s.focusRepo.FindActiveByUserID(ctx, userID)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "FocusService.Active: failed to fetch session")
+	}
+	return session, nil
+}
+
+// List returns the user's focus sessions
+func (s *FocusService) List(ctx context.Context, input repository.FocusSessionListInput) ([]model.FocusSession, int64, error) {
+	sessions, total, err := s.focusRepo.List(ctx, input)
+	if err != nil {
+		return nil, 0, errors.InternalErrorWithLog(err, "FocusService.List: failed to list sessions")
+	}
+	return sessions, total, nil
+}
+
+// DailyStats aggregates focus time per day in the user's time zone.
+// from and to are inclusive local dates (YYYY-MM-DD); both default to the last 7 days.
+func (s *FocusService) DailyStats(ctx context.Context, userID int64, fromStr, toStr string) (*FocusStats, error) {
+	pref, err := s.prefRepo.FindOrDefault(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "FocusService.DailyStats: failed to fetch preferences")
+	}
+	loc := pref.Location()
+
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from := to.AddDate(0, 0, -6)
+
+	if fromStr != "" {
+		parsed, err := time.ParseInLocation(constants.DateFormat, fromStr, loc)
+		if err != nil {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"from": {"Invalid date format. Use YYYY-MM-DD"},
+			})
+		}
+		from = parsed
+	}
+	if toStr != "" {
+		parsed, err := time.ParseInLocation(constants.DateFormat, toStr, loc)
+		if err != nil {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"to": {"Invalid date format. Use YYYY-MM-DD"},
+			})
+		}
+		to = parsed
+	}
+
+	if to.Before(from) {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"to": {"Must be on or after from"},
+		})
+	}
+	if to.Sub(from) > MaxFocusStatsDays*24*time.Hour {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"from": {"Date range must not exceed 366 days"},
+		})
+	}
+
+	totals, err := s.focusRepo.DailyTotals(ctx, userID, from, to.AddDate(0, 0, 1), loc.String())
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "FocusService.DailyStats: failed to aggregate sessions")
+	}
+
+	// Fill in days without sessions so clients can chart a continuous range
+	byDate := make(map[string]repository.DailyFocusTotal, len(totals))
+	for _, t := range totals {
+		byDate[t.Date] = t
+	}
+
+	stats := &FocusStats{
+		From:     from.Format(constants.DateFormat),
+		To:       to.Format(constants.DateFormat),
+		Timezone: loc.String(),
+	}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format(constants.DateFormat)
+		day, ok := byDate[date]
+		if !ok {
+			day = repository.DailyFocusTotal{Date: date}
+		}
+		stats.TotalSeconds += day.TotalSeconds
+		stats.Days = append(stats.Days, day)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"todo-api/internal/job"
+	"todo-api/internal/repository"
+)
+
+const (
+	// HealthCheckTimeout bounds each database check of the health report
+	HealthCheckTimeout = 2 * time.Second
+	// DataExportBacklogMaxAge is how long an export may wait before the job queue is reported as degraded
+	DataExportBacklogMaxAge = 15 * time.Minute
+)
+
+// HealthStatus is the status of a component or of the whole service
+type HealthStatus string
+
+// Health statuses, from best to worst
+const (
+	HealthOK       HealthStatus = "ok"
+	HealthDegraded HealthStatus = "degraded"
+	HealthDown     HealthStatus = "down"
+)
+
+// worse returns the worse of two statuses
+func (s HealthStatus) worse(other HealthStatus) HealthStatus {
+	rank := map[HealthStatus]int{HealthOK: 0, HealthDegraded: 1, HealthDown: 2}
+	if rank[other] > rank[s] {
+		return other
+	}
+	return s
+}
+
+// BuildInfo identifies the running build
+type BuildInfo struct {
+	Version string
+	// Commit is the VCS revision; when empty it is read from the Go build information
+	Commit string
+}
+
+// DatabaseHealth is the health of the database connection
+type DatabaseHealth struct {
+	Status  HealthStatus
+	Latency time.Duration
+	Error   string
+}
+
+// SchemaHealth is the health of the database schema: every model's table must exist
+type SchemaHealth struct {
+	Status        HealthStatus
+	MissingTables []string
+	Error         string
+}
+
+// JobsHealth is the health of the background jobs and the export queue
+type JobsHealth struct {
+	Status HealthStatus
+	// SchedulerRunning is false when SCHEDULER_ENABLED is off or jobs run per tenant
+	SchedulerRunning  bool
+	Jobs              []job.Status
+	PendingExports    int64
+	OldestPendingWait *time.Duration
+	Error             string
+}
+
+// HealthReport is the detailed health of the service
+type HealthReport struct {
+	Status    HealthStatus
+	CheckedAt time.Time
+	Uptime    time.Duration
+	Build     BuildInfo
+	GoVersion string
+	Database  DatabaseHealth
+	Schema    SchemaHealth
+	Jobs      JobsHealth
+}
+
+// HealthService reports the health of the service's components
+type HealthService struct {
+	healthRepo *repository.HealthRepository
+	exportRepo *repository.DataExportRepository
+	scheduler  *job.Scheduler
+	models     []any
+	build      BuildInfo
+	startedAt  time.Time
+}
+
+// NewHealthService creates a new HealthService. models are the tables the schema must have;
+// exportRepo and scheduler are nil when the export queue and jobs are per tenant.
+func NewHealthService(
+	healthRepo *repository.HealthRepository,
+	exportRepo *repository.DataExportRepository,
+	scheduler *job.Scheduler,
+	models []any,
+	build BuildInfo,
+) *HealthService {
+	if build.Commit == "" {
+		build.Commit = vcsRevision()
+	}
+	return &HealthService{
+		healthRepo: healthRepo,
+		exportRepo: exportRepo,
+		scheduler:  scheduler,
+		models:     models,
+		build:      build,
+		startedAt:  time.Now(),
+	}
+}
+
+// Report checks every component. The service is down when the database or its schema is unusable,
+// and degraded when background work is failing or falling behind.
+func (s *HealthService) Report(ctx context.Context) *HealthReport {
+	now := time.Now()
+	report := &HealthReport{
+		CheckedAt: now,
+		Uptime:    now.Sub(s.startedAt),
+		Build:     s.build,
+		GoVersion: runtime.Version(),
+		Database:  s.checkDatabase(ctx),
+	}
+
+	if report.Database.Status == HealthOK {
+		report.Schema = s.checkSchema(ctx)
+	} else {
+		report.Schema = SchemaHealth{Status: HealthDown, Error: "database is unavailable"}
+	}
+	report.Jobs = s.checkJobs(ctx, now)
+
+	report.Status = report.Database.Status.worse(report.Schema.Status).worse(report.Jobs.Status)
+	return report
+}
+
+// checkDatabase pings the database and measures the round trip
+func (s *HealthService) checkDatabase(ctx context.Context) DatabaseHealth {
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := s.healthRepo.Ping(ctx); err != nil {
+		return DatabaseHealth{Status: HealthDown, Latency: time.Since(start), Error: err.Error()}
+	}
+	return DatabaseHealth{Status: HealthOK, Latency: time.Since(start)}
+}
+
+// checkSchema looks for the tables that have not been migrated
+func (s *HealthService) checkSchema(ctx context.Context) SchemaHealth {
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	missing, err := s.healthRepo.MissingTables(ctx, s.models)
+	if err != nil {
+		return SchemaHealth{Status: HealthDown, Error: err.Error()}
+	}
+	if len(missing) > 0 {
+		return SchemaHealth{Status: HealthDown, MissingTables: missing}
+	}
+	return SchemaHealth{Status: HealthOK, MissingTables: missing}
+}
+
+// checkJobs reports the last run of each job and the export queue backlog
+func (s *HealthService) checkJobs(ctx context.Context, now time.Time) JobsHealth {
+	health := JobsHealth{Status: HealthOK, Jobs: []job.Status{}}
+
+	if s.scheduler != nil {
+		health.SchedulerRunning = true
+		health.Jobs = s.scheduler.Statuses()
+		for _, j := range health.Jobs {
+			if j.LastError != "" {
+				health.Status = HealthDegraded
+			}
+		}
+	}
+
+	if s.exportRepo != nil {
+		count, oldest, err := s.exportRepo.PendingBacklog(ctx)
+		if err != nil {
+			health.Status = HealthDegraded
+			health.Error = err.Error()
+			return health
+		}
+		health.PendingExports = count
+		if oldest != nil {
+			wait := now.Sub(*oldest)
+			health.OldestPendingWait = &wait
+			if wait > DataExportBacklogMaxAge {
+				health.Status = HealthDegraded
+			}
+		}
+	}
+
+	return health
+}
+
+// vcsRevision returns the VCS revision the binary was built from, if recorded
+func vcsRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+)
+
+const (
+	// IntegrationSignatureMaxSkew is how far the timestamp of a signed request may be from the server time
+	IntegrationSignatureMaxSkew = 5 * time.Minute
+	// MaxIntegrationsPerUser is how many integrations a user can have
+	MaxIntegrationsPerUser = 20
+	// MaxIntegrationNameLength is the maximum length of an integration name
+	MaxIntegrationNameLength = 100
+	// IntegrationCleanupJobInterval is how often used nonces that can no longer be replayed are deleted
+	IntegrationCleanupJobInterval = time.Hour
+)
+
+// integrationNoncePattern matches the nonces integrations may sign requests with
+var integrationNoncePattern = regexp.MustCompile(`^[A-Za-z0-9\-_]{16,64}$`)
+
+// IntegrationService manages integrations and authenticates their HMAC-signed requests
+type IntegrationService struct {
+	integrationRepo *repository.IntegrationRepository
+}
+
+// NewIntegrationService creates a new IntegrationService
+func NewIntegrationService(integrationRepo *repository.IntegrationRepository) *IntegrationService {
+	return &IntegrationService{integrationRepo: integrationRepo}
+}
+
+// SignedRequest is the part of an HTTP request covered by its signature, along with the signature headers
+type SignedRequest struct {
+	KeyID     string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Method    string
+	// URI is the request path with its query string, as sent
+	URI  string
+	Body []byte
+}
+
+// SignRequest returns the hex HMAC-SHA256 signature of a request with an integration secret.
+// The signed string is the timestamp, nonce, method, URI and hex SHA-256 of the body, joined by newlines.
+func SignRequest(secret, timestamp, nonce, method, uri string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{
+		timestamp,
+		nonce,
+		strings.ToUpper(method),
+		uri,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// List returns the user's integrations
+func (s *IntegrationService) List(ctx context.Context, userID int64) ([]model.Integration, error) {
+	integrations, err := s.integrationRepo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "IntegrationService.List: failed to fetch integrations")
+	}
+	return integrations, nil
+}
+
+// Create adds an integration and returns it with its secret, which cannot be retrieved again
+func (s *IntegrationService) Create(ctx context.Context, userID int64, name string) (*model.Integration, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.ValidationFailed(map[string][]string{
+			"name": {"is required"},
+		})
+	}
+	if utf8.RuneCountInString(name) > MaxIntegrationNameLength {
+		return nil, "", errors.ValidationFailed(map[string][]string{
+			"name": {fmt.Sprintf("must be at most %d characters", MaxIntegrationNameLength)},
+		})
+	}
+
+	count, err := s.integrationRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, "", errors.InternalErrorWithLog(err, "IntegrationService.Create: failed to count integrations")
+	}
+	if count >= MaxIntegrationsPerUser {
+		return nil, "", errors.ValidationFailed(map[string][]string{
+			"integrations": {fmt.Sprintf("must be at most %d per user", MaxIntegrationsPerUser)},
+		})
+	}
+
+	keyBytes := make([]byte, 12)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return nil, "", errors.InternalErrorWithLog(err, "IntegrationService.Create: failed to generate key ID")
+	}
+	secret, err := generateToken()
+	if err != nil {
+		return nil, "", errors.InternalErrorWithLog(err, "IntegrationService.Create: failed to generate secret")
+	}
+
+	integration := &model.Integration{
+		UserID: userID,
+		Name:   name,
+		KeyID:  hex.EncodeToString(keyBytes),
+		Secret: secret,
+	}
+	if err := s.integrationRepo.Create(ctx, integration); err != nil {
+		return nil, "", errors.InternalErrorWithLog(err, "IntegrationService.Create: failed to create integration")
+	}
+	return integration, secret, nil
+}
+
+// Delete removes an integration; requests signed with its secret are rejected from then on
+func (s *IntegrationService) Delete(ctx context.Context, id, userID int64) error {
+	return s.integrationRepo.Delete(ctx, id, userID)
+}
+
+// Authenticate verifies a signed request and returns the integration that signed it.
+// A request is accepted once: its nonce is recorded and rejected while the timestamp is still accepted.
+func (s *IntegrationService) Authenticate(ctx context.Context, req SignedRequest, now time.Time) (*model.Integration, error) {
+	integration, err := s.integrationRepo.FindByKeyID(ctx, req.KeyID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.AuthenticationFailed("Unknown integration key")
+		}
+		return nil, errors.InternalErrorWithLog(err, "IntegrationService.Authenticate: failed to fetch integration")
+	}
+
+	unix, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.AuthenticationFailed("Invalid signature timestamp")
+	}
+	signedAt := time.Unix(unix, 0)
+	if signedAt.Before(now.Add(-IntegrationSignatureMaxSkew)) || signedAt.After(now.Add(IntegrationSignatureMaxSkew)) {
+		return nil, errors.AuthenticationFailed("Signature timestamp is outside the accepted window")
+	}
+	if !integrationNoncePattern.MatchString(req.Nonce) {
+		return nil, errors.AuthenticationFailed("Invalid signature nonce")
+	}
+
+	expected := SignRequest(integration.Secret, req.Timestamp, req.Nonce, req.Method, req.URI, req.Body)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(req.Signature))) {
+		return nil, errors.AuthenticationFailed("Invalid signature")
+	}
+
+	// Only recorded once the signature is valid, so others cannot use up an integration's nonces
+	fresh, err := s.integrationRepo.UseNonce(ctx, integration.ID, req.Nonce, signedAt.Add(IntegrationSignatureMaxSkew))
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "IntegrationService.Authenticate: failed to record nonce")
+	}
+	if !fresh {
+		return nil, errors.AuthenticationFailed("Request has already been used")
+	}
+
+	if err := s.integrationRepo.TouchLastUsed(ctx, integration, now); err != nil {
+		log.Error().Err(err).Int64("integration_id", integration.ID).Msg("IntegrationService.Authenticate: failed to record last use")
+	}
+	return integration, nil
+}
+
+// Run deletes the used nonces whose requests can no longer be replayed.
+// It is intended to be called periodically by the scheduler.
+func (s *IntegrationService) Run(ctx context.Context, now time.Time) error {
+	deleted, err := s.integrationRepo.DeleteExpiredNonces(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired integration nonces: %w", err)
+	}
+	if deleted > 0 {
+		log.Info().Int64("nonces", deleted).Msg("Expired integration nonces deleted")
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+)
+
+const (
+	// myDaySuggestionLimit caps the number of todos in each suggestion list
+	myDaySuggestionLimit = 10
+	// myDayRecentWindow is how recently a todo must have been updated to be suggested as recently active
+	myDayRecentWindow = 3 * 24 * time.Hour
+)
+
+// MyDayService manages the todos users plan to work on each day
+type MyDayService struct {
+	myDayRepo *repository.MyDayRepository
+	todoRepo  *repository.TodoRepository
+	prefRepo  *repository.UserPreferenceRepository
+}
+
+// NewMyDayService creates a new MyDayService
+func NewMyDayService(
+	myDayRepo *repository.MyDayRepository,
+	todoRepo *repository.TodoRepository,
+	prefRepo *repository.UserPreferenceRepository,
+) *MyDayService {
+	return &MyDayService{
+		myDayRepo: myDayRepo,
+		todoRepo:  todoRepo,
+		prefRepo:  prefRepo,
+	}
+}
+
+// MyDay is the user's plan for today with suggestions for what to add
+type MyDay struct {
+	// Date is today in the user's time zone
+	Date        time.Time
+	Todos       []model.Todo
+	Suggestions MyDaySuggestions
+}
+
+// MyDaySuggestions lists incomplete todos not yet planned for today.
+// A todo appears in at most one list.
+type MyDaySuggestions struct {
+	DueToday       []model.Todo
+	Overdue        []model.Todo
+	RecentlyActive []model.Todo
+}
+
+// UpdateMyDayInput represents the todos to add to and remove from today's plan
+type UpdateMyDayInput struct {
+	Add    []int64
+	Remove []int64
+}
+
+// Get returns today's plan and suggestions
+func (s *MyDayService) Get(ctx context.Context, userID int64) (*MyDay, error) {
+	today, err := s.today(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "MyDayService.Get: failed to fetch preferences")
+	}
+	return s.build(ctx, userID, today)
+}
+
+// Update adds todos to and removes todos from today's plan.
+// Added todos go to the end of the plan; todos already planned keep their place.
+func (s *MyDayService) Update(ctx context.Context, userID int64, input UpdateMyDayInput) (*MyDay, error) {
+	today, err := s.today(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "MyDayService.Update: failed to fetch preferences")
+	}
+
+	add := uniqueIDs(input.Add)
+	if len(add) > 0 {
+		count, err := s.todoRepo.CountByIDs(ctx, userID, add)
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "MyDayService.Update: failed to check todos")
+		}
+		if count != int64(len(add)) {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"add": {"contains todos that do not exist"},
+			})
+		}
+	}
+
+	if err := s.myDayRepo.Remove(ctx, userID, today, uniqueIDs(input.Remove)); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "MyDayService.Update: failed to remove todos")
+	}
+	if err := s.myDayRepo.Add(ctx, userID, today, add); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "MyDayService.Update: failed to add todos")
+	}
+
+	return s.build(ctx, userID, today)
+}
+
+// build loads the plan for the given day and the suggestions
+func (s *MyDayService) build(ctx context.Context, userID int64, today time.Time) (*MyDay, error) {
+	items, err := s.myDayRepo.FindByDate(ctx, userID, today)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "MyDayService.build: failed to fetch plan")
+	}
+
+	day := &MyDay{Date: today, Todos: make([]model.Todo, 0, len(items))}
+	seen := make(map[int64]bool, len(items))
+	for _, item := range items {
+		if item.Todo == nil {
+			continue
+		}
+		day.Todos = append(day.Todos, *item.Todo)
+		seen[item.TodoID] = true
+	}
+
+	dueToday, err := s.todoRepo.FindDueBetween(ctx, userID, today, today)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "MyDayService.build: failed to fetch due todos")
+	}
+	overdue, err := s.todoRepo.FindOverdue(ctx, userID, today)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "MyDayService.build: failed to fetch overdue todos")
+	}
+	recent, err := s.todoRepo.FindRecentlyActive(ctx, userID, time.Now().Add(-myDayRecentWindow), myDaySuggestionLimit*3)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "MyDayService.build: failed to fetch recent todos")
+	}
+
+	day.Suggestions = MyDaySuggestions{
+		DueToday:       unplanned(dueToday, seen),
+		Overdue:        unplanned(overdue, seen),
+		RecentlyActive: unplanned(recent, seen),
+	}
+	return day, nil
+}
+
+// today returns the current date in the user's time zone as a UTC midnight for the date column
+func (s *MyDayService) today(ctx context.Context, userID int64) (time.Time, error) {
+	pref, err := s.prefRepo.FindOrDefault(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	local := time.Now().In(pref.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// unplanned returns up to myDaySuggestionLimit todos not in seen, and marks them as seen
+func unplanned(todos []model.Todo, seen map[int64]bool) []model.Todo {
+	result := []model.Todo{}
+	for _, todo := range todos {
+		if len(result) == myDaySuggestionLimit {
+			break
+		}
+		if seen[todo.ID] {
+			continue
+		}
+		seen[todo.ID] = true
+		result = append(result, todo)
+	}
+	return result
+}
+
+// uniqueIDs returns ids without duplicates, keeping the first occurrence
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
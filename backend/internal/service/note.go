@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"regexp"
 	"strings"
 	"time"
@@ -47,7 +48,7 @@ type UpdateNoteInput struct {
 }
 
 // Create creates a new note with initial revision
-func (s *NoteService) Create(input CreateNoteInput) (*model.Note, error) {
+func (s *NoteService) Create(ctx context.Context, input CreateNoteInput) (*model.Note, error) {
 	// Generate body_plain from body_md
 	var bodyPlain *string
 	if input.BodyMD != nil && *input.BodyMD != "" {
@@ -68,12 +69,12 @@ func (s *NoteService) Create(input CreateNoteInput) (*model.Note, error) {
 		Pinned:    pinned,
 	}
 
-	if err := s.noteRepo.Create(note); err != nil {
+	if err := s.noteRepo.Create(ctx, note); err != nil {
 		return nil, errors.InternalErrorWithLog(err, "NoteService.Create: failed to create note")
 	}
 
 	// Create initial revision
-	if err := s.createRevision(note, input.UserID); err != nil {
+	if err := s.createRevision(ctx, note, input.UserID); err != nil {
 		log.Error().Err(err).Msg("NoteService.Create: failed to create initial revision")
 	}
 
@@ -81,9 +82,9 @@ func (s *NoteService) Create(input CreateNoteInput) (*model.Note, error) {
 }
 
 // Update updates a note, creating revision if body_md changed
-func (s *NoteService) Update(noteID, userID int64, input UpdateNoteInput) (*model.Note, error) {
+func (s *NoteService) Update(ctx context.Context, noteID, userID int64, input UpdateNoteInput) (*model.Note, error) {
 	// Get existing note (including trashed for recovery)
-	note, err := s.noteRepo.FindByIDIncludingTrashed(noteID, userID)
+	note, err := s.noteRepo.FindByIDIncludingTrashed(ctx, noteID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -152,18 +153,18 @@ func (s *NoteService) Update(noteID, userID int64, input UpdateNoteInput) (*mode
 	}
 
 	// Save changes
-	if err := s.noteRepo.Update(note); err != nil {
+	if err := s.noteRepo.Update(ctx, note); err != nil {
 		return nil, errors.InternalErrorWithLog(err, "NoteService.Update: failed to update note")
 	}
 
 	// Create revision only if body_md changed
 	if bodyChanged {
-		if err := s.createRevision(note, userID); err != nil {
+		if err := s.createRevision(ctx, note, userID); err != nil {
 			log.Error().Err(err).Msg("NoteService.Update: failed to create revision")
 		}
 
 		// Enforce revision limit
-		if err := s.enforceRevisionLimit(noteID); err != nil {
+		if err := s.enforceRevisionLimit(ctx, noteID, userID); err != nil {
 			log.Error().Err(err).Msg("NoteService.Update: failed to enforce revision limit")
 		}
 	}
@@ -172,9 +173,9 @@ func (s *NoteService) Update(noteID, userID int64, input UpdateNoteInput) (*mode
 }
 
 // Delete handles soft/hard delete
-func (s *NoteService) Delete(noteID, userID int64, force bool) error {
+func (s *NoteService) Delete(ctx context.Context, noteID, userID int64, force bool) error {
 	// Check note exists
-	exists, err := s.noteRepo.ExistsByID(noteID, userID)
+	exists, err := s.noteRepo.ExistsByID(ctx, noteID, userID)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "NoteService.Delete: failed to check note existence")
 	}
@@ -184,29 +185,29 @@ func (s *NoteService) Delete(noteID, userID int64, force bool) error {
 
 	if force {
 		// Hard delete
-		return s.noteRepo.HardDelete(noteID, userID)
+		return s.noteRepo.HardDelete(ctx, noteID, userID)
 	}
 
 	// Soft delete
-	return s.noteRepo.SoftDelete(noteID, userID)
+	return s.noteRepo.SoftDelete(ctx, noteID, userID)
 }
 
 // RestoreRevision restores a note from a specific revision
-func (s *NoteService) RestoreRevision(noteID, revisionID, userID int64) (*model.Note, error) {
+func (s *NoteService) RestoreRevision(ctx context.Context, noteID, revisionID, userID int64) (*model.Note, error) {
 	// Get the note
-	note, err := s.noteRepo.FindByIDIncludingTrashed(noteID, userID)
+	note, err := s.noteRepo.FindByIDIncludingTrashed(ctx, noteID, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get the revision
-	revision, err := s.revisionRepo.FindByID(revisionID, noteID)
+	revision, err := s.revisionRepo.FindByID(ctx, revisionID, noteID, userID)
 	if err != nil {
 		return nil, errors.NotFound("NoteRevision", revisionID)
 	}
 
 	// Create revision of current state before restoring
-	if err := s.createRevision(note, userID); err != nil {
+	if err := s.createRevision(ctx, note, userID); err != nil {
 		log.Error().Err(err).Msg("NoteService.RestoreRevision: failed to create pre-restore revision")
 	}
 
@@ -222,12 +223,12 @@ func (s *NoteService) RestoreRevision(noteID, revisionID, userID int64) (*model.
 	note.LastEditedAt = time.Now()
 
 	// Save
-	if err := s.noteRepo.Update(note); err != nil {
+	if err := s.noteRepo.Update(ctx, note); err != nil {
 		return nil, errors.InternalErrorWithLog(err, "NoteService.RestoreRevision: failed to update note")
 	}
 
 	// Enforce revision limit
-	if err := s.enforceRevisionLimit(noteID); err != nil {
+	if err := s.enforceRevisionLimit(ctx, noteID, userID); err != nil {
 		log.Error().Err(err).Msg("NoteService.RestoreRevision: failed to enforce revision limit")
 	}
 
@@ -235,25 +236,25 @@ func (s *NoteService) RestoreRevision(noteID, revisionID, userID int64) (*model.
 }
 
 // createRevision creates a revision and enforces 50 limit
-func (s *NoteService) createRevision(note *model.Note, userID int64) error {
+func (s *NoteService) createRevision(ctx context.Context, note *model.Note, userID int64) error {
 	revision := &model.NoteRevision{
 		NoteID: note.ID,
 		UserID: userID,
 		Title:  note.Title,
 		BodyMD: note.BodyMD,
 	}
-	return s.revisionRepo.Create(revision)
+	return s.revisionRepo.Create(ctx, revision)
 }
 
 // enforceRevisionLimit deletes old revisions if exceeding 50
-func (s *NoteService) enforceRevisionLimit(noteID int64) error {
-	count, err := s.revisionRepo.CountByNoteID(noteID)
+func (s *NoteService) enforceRevisionLimit(ctx context.Context, noteID, userID int64) error {
+	count, err := s.revisionRepo.CountByNoteID(ctx, noteID, userID)
 	if err != nil {
 		return err
 	}
 
 	if count > model.MaxRevisionsPerNote {
-		return s.revisionRepo.DeleteOldestByNoteID(noteID, model.MaxRevisionsPerNote)
+		return s.revisionRepo.DeleteOldestByNoteID(ctx, noteID, userID, model.MaxRevisionsPerNote)
 	}
 
 	return nil
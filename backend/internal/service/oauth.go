@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"todo-api/internal/config"
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+)
+
+const (
+	// OAuthCodeTTL is how long an authorization code can be exchanged for tokens
+	OAuthCodeTTL = 5 * time.Minute
+	// OAuthCleanupJobInterval is how often expired codes and refresh tokens are deleted
+	OAuthCleanupJobInterval = time.Hour
+)
+
+// pkceValuePattern matches PKCE code verifiers and S256 challenges (RFC 7636)
+var pkceValuePattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// OAuth error codes returned by the token endpoint (RFC 6749 section 5.2)
+const (
+	OAuthInvalidRequest       = "invalid_request"
+	OAuthInvalidClient        = "invalid_client"
+	OAuthInvalidGrant         = "invalid_grant"
+	OAuthUnsupportedGrantType = "unsupported_grant_type"
+)
+
+// OAuthError is an error reported to OAuth clients in the format of RFC 6749
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+// Error implements the error interface
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// oauthError creates an OAuthError
+func oauthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// OAuthService implements the OAuth 2.0 authorization code flow with PKCE for first-party native apps
+type OAuthService struct {
+	oauthRepo   *repository.OAuthRepository
+	userRepo    *repository.UserRepository
+	authService *AuthService
+	config      *config.OAuthConfig
+}
+
+// NewOAuthService creates a new OAuthService
+func NewOAuthService(
+	oauthRepo *repository.OAuthRepository,
+	userRepo *repository.UserRepository,
+	authService *AuthService,
+	cfg *config.OAuthConfig,
+) *OAuthService {
+	return &OAuthService{
+		oauthRepo:   oauthRepo,
+		userRepo:    userRepo,
+		authService: authService,
+		config:      cfg,
+	}
+}
+
+// AuthorizationRequest represents the parameters of an authorization request
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	State               string
+}
+
+// OAuthTokens represents the tokens issued by the token endpoint
+type OAuthTokens struct {
+	AccessToken  string
+	ExpiresIn    int
+	RefreshToken string
+}
+
+// Enabled reports whether any OAuth client is registered
+func (s *OAuthService) Enabled() bool {
+	return len(s.config.Clients) > 0
+}
+
+// ValidateAuthorization checks an authorization request against the registered clients
+func (s *OAuthService) ValidateAuthorization(req AuthorizationRequest) error {
+	if !s.Enabled() {
+		return errors.FeatureDisabled("oauth")
+	}
+
+	validationErrors := map[string][]string{}
+	redirectURIs, ok := s.config.Clients[req.ClientID]
+	if !ok {
+		validationErrors["client_id"] = []string{"is not a registered client"}
+	} else if !slices.Contains(redirectURIs, req.RedirectURI) {
+		validationErrors["redirect_uri"] = []string{"is not registered for the client"}
+	}
+	if req.ResponseType != "code" {
+		validationErrors["response_type"] = []string{"must be code"}
+	}
+	if req.CodeChallengeMethod != "S256" {
+		validationErrors["code_challenge_method"] = []string{"must be S256"}
+	}
+	if !pkceValuePattern.MatchString(req.CodeChallenge) {
+		validationErrors["code_challenge"] = []string{"must be a base64url SHA-256 digest of the code verifier"}
+	}
+	if len(validationErrors) > 0 {
+		return errors.ValidationFailed(validationErrors)
+	}
+	return nil
+}
+
+// LoginURL returns the frontend page that signs the user in and approves an authorization request
+func (s *OAuthService) LoginURL(query url.Values) string {
+	return s.config.LoginURL + "?" + query.Encode()
+}
+
+// Authorize issues an authorization code to the client for the signed-in user
+// and returns the redirect URI to send it to
+func (s *OAuthService) Authorize(ctx context.Context, userID int64, req AuthorizationRequest) (string, error) {
+	if err := s.ValidateAuthorization(req); err != nil {
+		return "", err
+	}
+
+	code, err := generateToken()
+	if err != nil {
+		return "", errors.InternalErrorWithLog(err, "OAuthService.Authorize: failed to generate code")
+	}
+	if err := s.oauthRepo.CreateCode(ctx, &model.OAuthAuthorizationCode{
+		CodeHash:      hashToken(code),
+		ClientID:      req.ClientID,
+		UserID:        userID,
+		RedirectURI:   req.RedirectURI,
+		CodeChallenge: req.CodeChallenge,
+		FamilyID:      uuid.New().String(),
+		ExpiresAt:     time.Now().Add(OAuthCodeTTL),
+	}); err != nil {
+		return "", errors.InternalErrorWithLog(err, "OAuthService.Authorize: failed to create code")
+	}
+
+	redirect, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		return "", errors.InternalErrorWithLog(err, "OAuthService.Authorize: invalid registered redirect URI")
+	}
+	query := redirect.Query()
+	query.Set("code", code)
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	redirect.RawQuery = query.Encode()
+	return redirect.String(), nil
+}
+
+// ExchangeCode redeems an authorization code for an access token and a refresh token.
+// The code verifier must match the challenge of the authorization request.
+func (s *OAuthService) ExchangeCode(ctx context.Context, clientID, code, redirectURI, codeVerifier string) (*OAuthTokens, error) {
+	if err := s.checkClient(clientID); err != nil {
+		return nil, err
+	}
+	if code == "" || redirectURI == "" || codeVerifier == "" {
+		return nil, oauthError(OAuthInvalidRequest, "code, redirect_uri and code_verifier are required")
+	}
+	if !pkceValuePattern.MatchString(codeVerifier) {
+		return nil, oauthError(OAuthInvalidRequest, "code_verifier is malformed")
+	}
+
+	authCode, err := s.oauthRepo.FindCodeByHash(ctx, hashToken(code))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, oauthError(OAuthInvalidGrant, "authorization code is invalid")
+		}
+		return nil, errors.InternalErrorWithLog(err, "OAuthService.ExchangeCode: failed to fetch code")
+	}
+
+	now := time.Now()
+	if authCode.ClientID != clientID || authCode.RedirectURI != redirectURI {
+		return nil, oauthError(OAuthInvalidGrant, "authorization code was issued to another client or redirect URI")
+	}
+	if authCode.UsedAt != nil {
+		return nil, s.revokeReusedCode(ctx, authCode, now)
+	}
+	if !now.Before(authCode.ExpiresAt) {
+		return nil, oauthError(OAuthInvalidGrant, "authorization code has expired")
+	}
+	if !verifyCodeChallenge(codeVerifier, authCode.CodeChallenge) {
+		return nil, oauthError(OAuthInvalidGrant, "code_verifier does not match the code challenge")
+	}
+
+	marked, err := s.oauthRepo.MarkCodeUsed(ctx, authCode, now)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "OAuthService.ExchangeCode: failed to mark code used")
+	}
+	if !marked {
+		return nil, s.revokeReusedCode(ctx, authCode, now)
+	}
+
+	return s.issue(ctx, authCode.UserID, clientID, authCode.FamilyID, now)
+}
+
+// revokeReusedCode revokes the tokens issued for an authorization code presented again, which may have been
+// intercepted (RFC 6749 §4.1.2), and returns the error to respond with
+func (s *OAuthService) revokeReusedCode(ctx context.Context, authCode *model.OAuthAuthorizationCode, now time.Time) error {
+	if err := s.oauthRepo.RevokeFamily(ctx, authCode.UserID, authCode.FamilyID, now); err != nil {
+		return errors.InternalErrorWithLog(err, "OAuthService.ExchangeCode: failed to revoke token family")
+	}
+	log.Warn().Int64("user_id", authCode.UserID).Str("client_id", authCode.ClientID).Msg("Authorization code reused; token family revoked")
+	return oauthError(OAuthInvalidGrant, "authorization code has already been used")
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token of the same family.
+// Presenting a token that was already replaced revokes the whole family, since it may have leaked.
+func (s *OAuthService) Refresh(ctx context.Context, clientID, refreshToken string) (*OAuthTokens, error) {
+	if err := s.checkClient(clientID); err != nil {
+		return nil, err
+	}
+	if refreshToken == "" {
+		return nil, oauthError(OAuthInvalidRequest, "refresh_token is required")
+	}
+
+	token, err := s.findRefreshToken(ctx, clientID, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !now.Before(token.ExpiresAt) {
+		return nil, oauthError(OAuthInvalidGrant, "refresh token has expired")
+	}
+	revoked, err := s.oauthRepo.RevokeRefreshToken(ctx, token, now)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "OAuthService.Refresh: failed to revoke refresh token")
+	}
+	if !revoked {
+		if err := s.oauthRepo.RevokeFamily(ctx, token.UserID, token.FamilyID, now); err != nil {
+			return nil, errors.InternalErrorWithLog(err, "OAuthService.Refresh: failed to revoke token family")
+		}
+		log.Warn().Int64("user_id", token.UserID).Str("client_id", clientID).Msg("Revoked refresh token reused; token family revoked")
+		return nil, oauthError(OAuthInvalidGrant, "refresh token has been revoked")
+	}
+
+	return s.issue(ctx, token.UserID, clientID, token.FamilyID, now)
+}
+
+// Revoke revokes a refresh token and every token of its family.
+// Unknown tokens are ignored, as required by RFC 7009.
+func (s *OAuthService) Revoke(ctx context.Context, clientID, refreshToken string) error {
+	if err := s.checkClient(clientID); err != nil {
+		return err
+	}
+
+	token, err := s.findRefreshToken(ctx, clientID, refreshToken)
+	if err != nil {
+		if oauthErr, ok := err.(*OAuthError); ok && oauthErr.Code == OAuthInvalidGrant {
+			return nil
+		}
+		return err
+	}
+	if err := s.oauthRepo.RevokeFamily(ctx, token.UserID, token.FamilyID, time.Now()); err != nil {
+		return errors.InternalErrorWithLog(err, "OAuthService.Revoke: failed to revoke token family")
+	}
+	return nil
+}
+
+// Run deletes expired authorization codes and refresh tokens
+func (s *OAuthService) Run(ctx context.Context, now time.Time) error {
+	deleted, err := s.oauthRepo.DeleteExpired(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired OAuth grants: %w", err)
+	}
+	if deleted > 0 {
+		log.Info().Int64("deleted", deleted).Msg("Expired OAuth grants deleted")
+	}
+	return nil
+}
+
+// checkClient checks that the client is registered
+func (s *OAuthService) checkClient(clientID string) error {
+	if _, ok := s.config.Clients[clientID]; !ok {
+		return oauthError(OAuthInvalidClient, "client is not registered")
+	}
+	return nil
+}
+
+// findRefreshToken retrieves a refresh token issued to the client
+func (s *OAuthService) findRefreshToken(ctx context.Context, clientID, refreshToken string) (*model.OAuthRefreshToken, error) {
+	token, err := s.oauthRepo.FindRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, oauthError(OAuthInvalidGrant, "refresh token is invalid")
+		}
+		return nil, errors.InternalErrorWithLog(err, "OAuthService: failed to fetch refresh token")
+	}
+	if token.ClientID != clientID {
+		return nil, oauthError(OAuthInvalidGrant, "refresh token was issued to another client")
+	}
+	return token, nil
+}
+
+// issue creates an access token and a refresh token of the given family for the user.
+// The access token's jti is kept with the refresh token so that revoking the family also denylists it.
+func (s *OAuthService) issue(ctx context.Context, userID int64, clientID, familyID string, now time.Time) (*OAuthTokens, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, oauthError(OAuthInvalidGrant, "user no longer exists")
+		}
+		return nil, errors.InternalErrorWithLog(err, "OAuthService.issue: failed to fetch user")
+	}
+
+	accessToken, claims, err := s.authService.GenerateTokenWithClaims(user)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "OAuthService.issue: failed to generate access token")
+	}
+	refreshToken, err := generateToken()
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "OAuthService.issue: failed to generate refresh token")
+	}
+	if err := s.oauthRepo.CreateRefreshToken(ctx, &model.OAuthRefreshToken{
+		TokenHash:            hashToken(refreshToken),
+		ClientID:             clientID,
+		UserID:               userID,
+		FamilyID:             familyID,
+		AccessTokenJti:       claims.Jti,
+		AccessTokenExpiresAt: &claims.ExpiresAt.Time,
+		ExpiresAt:            now.Add(s.config.RefreshTokenTTL),
+	}); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "OAuthService.issue: failed to create refresh token")
+	}
+
+	return &OAuthTokens{
+		AccessToken:  accessToken,
+		ExpiresIn:    int(s.authService.TokenTTL().Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// verifyCodeChallenge checks a PKCE code verifier against an S256 challenge
+func verifyCodeChallenge(verifier, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+)
+
+// PreferenceService handles user preference business logic
+type PreferenceService struct {
+	prefRepo *repository.UserPreferenceRepository
+}
+
+// NewPreferenceService creates a new PreferenceService
+func NewPreferenceService(prefRepo *repository.UserPreferenceRepository) *PreferenceService {
+	return &PreferenceService{prefRepo: prefRepo}
+}
+
+// UpdatePreferenceInput represents input for updating preferences
+type UpdatePreferenceInput struct {
+	Timezone        *string
+	DigestFrequency *string
+	DigestHour      *int
+
+	PurgeTrashedNotesAfterDays     *int
+	ArchiveNotesAfterDays          *int
+	ArchiveCompletedTodosAfterDays *int
+
+	WIPLimit     *int
+	WIPLimitMode *string
+}
+
+// Get returns the user's preferences (defaults if never saved)
+func (s *PreferenceService) Get(ctx context.Context, userID int64) (*model.UserPreference, error) {
+	pref, err := s.prefRepo.FindOrDefault(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "PreferenceService.Get: failed to fetch preferences")
+	}
+	return pref, nil
+}
+
+// Update applies the given changes and persists the preferences
+func (s *PreferenceService) Update(ctx context.Context, userID int64, input UpdatePreferenceInput) (*model.UserPreference, error) {
+	pref, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Timezone != nil {
+		if _, err := time.LoadLocation(*input.Timezone); err != nil || *input.Timezone == "" {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"timezone": {"Invalid timezone. Use an IANA name such as Asia/Tokyo"},
+			})
+		}
+		pref.Timezone = *input.Timezone
+	}
+
+	if input.DigestFrequency != nil {
+		freq := model.DigestFrequency(*input.DigestFrequency)
+		if !model.IsValidDigestFrequency(freq) {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"digest_frequency": {"Invalid digest frequency. Valid values: none, daily, weekly"},
+			})
+		}
+		pref.DigestFrequency = freq
+	}
+
+	if input.DigestHour != nil {
+		pref.DigestHour = *input.DigestHour
+	}
+
+	if input.WIPLimit != nil {
+		if *input.WIPLimit < 0 || *input.WIPLimit > model.MaxWIPLimit {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"wip_limit": {"WIP limit must be between 0 and 100"},
+			})
+		}
+		pref.WIPLimit = *input.WIPLimit
+	}
+
+	if input.WIPLimitMode != nil {
+		mode := model.WIPLimitMode(*input.WIPLimitMode)
+		if !model.IsValidWIPLimitMode(mode) {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"wip_limit_mode": {"Invalid WIP limit mode. Valid values: reject, warn"},
+			})
+		}
+		pref.WIPLimitMode = mode
+	}
+
+	rules := rulesFromPreference(pref)
+	if err := applyRetentionOverrides(&rules, RetentionRuleOverrides{
+		PurgeTrashedNotesAfterDays:     input.PurgeTrashedNotesAfterDays,
+		ArchiveNotesAfterDays:          input.ArchiveNotesAfterDays,
+		ArchiveCompletedTodosAfterDays: input.ArchiveCompletedTodosAfterDays,
+	}); err != nil {
+		return nil, err
+	}
+	pref.PurgeTrashedNotesAfterDays = rules.PurgeTrashedNotesAfterDays
+	pref.ArchiveNotesAfterDays = rules.ArchiveNotesAfterDays
+	pref.ArchiveCompletedTodosAfterDays = rules.ArchiveCompletedTodosAfterDays
+
+	if err := s.prefRepo.Save(ctx, pref); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "PreferenceService.Update: failed to save preferences")
+	}
+
+	return pref, nil
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/pkg/util"
+)
+
+const (
+	// RetentionJobInterval is how often retention rules are enforced
+	RetentionJobInterval = time.Hour
+	// retentionPreviewLimit caps the number of items listed per rule in a preview
+	retentionPreviewLimit = 50
+)
+
+// RetentionService enforces per-user data retention rules
+type RetentionService struct {
+	prefRepo *repository.UserPreferenceRepository
+	todoRepo *repository.TodoRepository
+	noteRepo *repository.NoteRepository
+}
+
+// NewRetentionService creates a new RetentionService
+func NewRetentionService(
+	prefRepo *repository.UserPreferenceRepository,
+	todoRepo *repository.TodoRepository,
+	noteRepo *repository.NoteRepository,
+) *RetentionService {
+	return &RetentionService{
+		prefRepo: prefRepo,
+		todoRepo: todoRepo,
+		noteRepo: noteRepo,
+	}
+}
+
+// RetentionRules holds the retention periods in days; 0 disables a rule
+type RetentionRules struct {
+	PurgeTrashedNotesAfterDays     int
+	ArchiveNotesAfterDays          int
+	ArchiveCompletedTodosAfterDays int
+}
+
+// RetentionRuleOverrides replaces saved rules when previewing; nil keeps the saved value
+type RetentionRuleOverrides struct {
+	PurgeTrashedNotesAfterDays     *int
+	ArchiveNotesAfterDays          *int
+	ArchiveCompletedTodosAfterDays *int
+}
+
+// RetentionItem is a single todo or note affected by a rule
+type RetentionItem struct {
+	ID    int64
+	Title string
+	// Date is the timestamp the rule is measured from (completion, trash, or last edit).
+	// Todos completed without a recorded completion time are measured from their last update.
+	Date time.Time
+}
+
+// RetentionRuleEffect lists what a single rule would do
+type RetentionRuleEffect struct {
+	Days   int
+	Cutoff *time.Time
+	Total  int
+	Items  []RetentionItem
+}
+
+// RetentionPreview describes what the next enforcement would change
+type RetentionPreview struct {
+	PurgeTrashedNotes     RetentionRuleEffect
+	ArchiveNotes          RetentionRuleEffect
+	ArchiveCompletedTodos RetentionRuleEffect
+}
+
+// RetentionResult counts what an enforcement run changed
+type RetentionResult struct {
+	PurgedNotes   int
+	ArchivedNotes int
+	ArchivedTodos int
+}
+
+// Preview returns the items the user's rules would affect right now.
+// Overrides allow trying out rules before saving them.
+func (s *RetentionService) Preview(ctx context.Context, userID int64, overrides RetentionRuleOverrides) (*RetentionPreview, error) {
+	pref, err := s.prefRepo.FindOrDefault(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "RetentionService.Preview: failed to fetch preferences")
+	}
+
+	rules := rulesFromPreference(pref)
+	if err := applyRetentionOverrides(&rules, overrides); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	preview := &RetentionPreview{}
+
+	if cutoff, ok := retentionCutoff(now, rules.PurgeTrashedNotesAfterDays); ok {
+		notes, err := s.noteRepo.FindTrashedBefore(ctx, userID, cutoff)
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "RetentionService.Preview: failed to fetch trashed notes")
+		}
+		preview.PurgeTrashedNotes = noteEffect(rules.PurgeTrashedNotesAfterDays, cutoff, notes, func(n *model.Note) time.Time {
+			return *n.TrashedAt
+		})
+	} else {
+		preview.PurgeTrashedNotes = RetentionRuleEffect{Items: []RetentionItem{}}
+	}
+
+	if cutoff, ok := retentionCutoff(now, rules.ArchiveNotesAfterDays); ok {
+		notes, err := s.noteRepo.FindInactiveBefore(ctx, userID, cutoff)
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "RetentionService.Preview: failed to fetch inactive notes")
+		}
+		preview.ArchiveNotes = noteEffect(rules.ArchiveNotesAfterDays, cutoff, notes, func(n *model.Note) time.Time {
+			return n.LastEditedAt
+		})
+	} else {
+		preview.ArchiveNotes = RetentionRuleEffect{Items: []RetentionItem{}}
+	}
+
+	if cutoff, ok := retentionCutoff(now, rules.ArchiveCompletedTodosAfterDays); ok {
+		todos, err := s.todoRepo.FindCompletedBefore(ctx, userID, cutoff)
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "RetentionService.Preview: failed to fetch completed todos")
+		}
+		effect := RetentionRuleEffect{
+			Days:   rules.ArchiveCompletedTodosAfterDays,
+			Cutoff: &cutoff,
+			Total:  len(todos),
+			Items:  make([]RetentionItem, 0, min(len(todos), retentionPreviewLimit)),
+		}
+		for i := range todos {
+			if i == retentionPreviewLimit {
+				break
+			}
+			effect.Items = append(effect.Items, RetentionItem{
+				ID:    todos[i].ID,
+				Title: todos[i].Title,
+				Date:  completionDate(&todos[i]),
+			})
+		}
+		preview.ArchiveCompletedTodos = effect
+	} else {
+		preview.ArchiveCompletedTodos = RetentionRuleEffect{Items: []RetentionItem{}}
+	}
+
+	return preview, nil
+}
+
+// Run enforces the retention rules of every user who has any enabled.
+// It is intended to be called periodically by the scheduler.
+func (s *RetentionService) Run(ctx context.Context, now time.Time) error {
+	prefs, err := s.prefRepo.FindRetentionSubscribers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch retention subscribers: %w", err)
+	}
+
+	for i := range prefs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pref := &prefs[i]
+		result, err := s.Apply(ctx, pref.UserID, rulesFromPreference(pref), now)
+		if err != nil {
+			log.Error().Err(err).Int64("user_id", pref.UserID).Msg("RetentionService.Run: failed to apply retention rules")
+			continue
+		}
+		if result.PurgedNotes+result.ArchivedNotes+result.ArchivedTodos > 0 {
+			log.Info().
+				Int64("user_id", pref.UserID).
+				Int("purged_notes", result.PurgedNotes).
+				Int("archived_notes", result.ArchivedNotes).
+				Int("archived_todos", result.ArchivedTodos).
+				Msg("Retention rules applied")
+		}
+
+		if err := s.prefRepo.MarkRetentionRun(ctx, pref.ID, now); err != nil {
+			log.Error().Err(err).Int64("user_id", pref.UserID).Msg("RetentionService.Run: failed to mark retention run")
+		}
+	}
+
+	return nil
+}
+
+// Apply enforces the given rules for a single user
+func (s *RetentionService) Apply(ctx context.Context, userID int64, rules RetentionRules, now time.Time) (*RetentionResult, error) {
+	result := &RetentionResult{}
+
+	if cutoff, ok := retentionCutoff(now, rules.PurgeTrashedNotesAfterDays); ok {
+		notes, err := s.noteRepo.FindTrashedBefore(ctx, userID, cutoff)
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch trashed notes: %w", err)
+		}
+		for _, note := range notes {
+			if err := s.noteRepo.HardDelete(ctx, note.ID, userID); err != nil {
+				return result, fmt.Errorf("failed to purge note %d: %w", note.ID, err)
+			}
+			result.PurgedNotes++
+		}
+	}
+
+	if cutoff, ok := retentionCutoff(now, rules.ArchiveNotesAfterDays); ok {
+		notes, err := s.noteRepo.FindInactiveBefore(ctx, userID, cutoff)
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch inactive notes: %w", err)
+		}
+		ids := make([]int64, len(notes))
+		for i, note := range notes {
+			ids[i] = note.ID
+		}
+		if err := s.noteRepo.ArchiveByIDs(ctx, ids, userID, now); err != nil {
+			return result, fmt.Errorf("failed to archive notes: %w", err)
+		}
+		result.ArchivedNotes = len(ids)
+	}
+
+	if cutoff, ok := retentionCutoff(now, rules.ArchiveCompletedTodosAfterDays); ok {
+		todos, err := s.todoRepo.FindCompletedBefore(ctx, userID, cutoff)
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch completed todos: %w", err)
+		}
+		ids := make([]int64, len(todos))
+		for i, todo := range todos {
+			ids[i] = todo.ID
+		}
+		if err := s.todoRepo.ArchiveByIDs(ctx, ids, userID, now); err != nil {
+			return result, fmt.Errorf("failed to archive todos: %w", err)
+		}
+		result.ArchivedTodos = len(ids)
+	}
+
+	return result, nil
+}
+
+// rulesFromPreference extracts the retention rules saved in preferences
+func rulesFromPreference(pref *model.UserPreference) RetentionRules {
+	return RetentionRules{
+		PurgeTrashedNotesAfterDays:     pref.PurgeTrashedNotesAfterDays,
+		ArchiveNotesAfterDays:          pref.ArchiveNotesAfterDays,
+		ArchiveCompletedTodosAfterDays: pref.ArchiveCompletedTodosAfterDays,
+	}
+}
+
+// applyRetentionOverrides validates and applies preview overrides
+func applyRetentionOverrides(rules *RetentionRules, overrides RetentionRuleOverrides) error {
+	fieldErrors := map[string][]string{}
+	apply := func(field string, value *int, target *int) {
+		if value == nil {
+			return
+		}
+		if !IsValidRetentionDays(*value) {
+			fieldErrors[field] = []string{fmt.Sprintf("must be between 0 and %d", model.MaxRetentionDays)}
+			return
+		}
+		*target = *value
+	}
+
+	apply("purge_trashed_notes_after_days", overrides.PurgeTrashedNotesAfterDays, &rules.PurgeTrashedNotesAfterDays)
+	apply("archive_notes_after_days", overrides.ArchiveNotesAfterDays, &rules.ArchiveNotesAfterDays)
+	apply("archive_completed_todos_after_days", overrides.ArchiveCompletedTodosAfterDays, &rules.ArchiveCompletedTodosAfterDays)
+
+	if len(fieldErrors) > 0 {
+		return errors.ValidationFailed(fieldErrors)
+	}
+	return nil
+}
+
+// IsValidRetentionDays checks if a retention period is within the allowed range
+func IsValidRetentionDays(days int) bool {
+	return days >= 0 && days <= model.MaxRetentionDays
+}
+
+// retentionCutoff returns the time before which items are affected, or false if the rule is disabled
+func retentionCutoff(now time.Time, days int) (time.Time, bool) {
+	if days <= 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, 0, -days), true
+}
+
+// completionDate returns when a todo was completed, or its last update if that wasn't recorded
+func completionDate(todo *model.Todo) time.Time {
+	if todo.CompletedAt != nil {
+		return *todo.CompletedAt
+	}
+	return todo.UpdatedAt
+}
+
+// noteEffect builds a preview entry for a note rule
+func noteEffect(days int, cutoff time.Time, notes []model.Note, dateOf func(*model.Note) time.Time) RetentionRuleEffect {
+	effect := RetentionRuleEffect{
+		Days:   days,
+		Cutoff: &cutoff,
+		Total:  len(notes),
+		Items:  make([]RetentionItem, 0, min(len(notes), retentionPreviewLimit)),
+	}
+	for i := range notes {
+		if i == retentionPreviewLimit {
+			break
+		}
+		effect.Items = append(effect.Items, RetentionItem{
+			ID:    notes[i].ID,
+			Title: util.DerefString(notes[i].Title, ""),
+			Date:  dateOf(&notes[i]),
+		})
+	}
+	return effect
+}
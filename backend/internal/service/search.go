@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/search"
+)
+
+const (
+	// DefaultSearchLimit is the default number of results per group
+	DefaultSearchLimit = 5
+	// MaxSearchLimit is the maximum number of results per group
+	MaxSearchLimit = 20
+	// MaxSearchQueryLength is the maximum accepted query length
+	MaxSearchQueryLength = 100
+)
+
+// SearchService searches todos, comments, categories, and tags in one call
+// through the configured search index
+type SearchService struct {
+	index search.Index
+}
+
+// NewSearchService creates a new SearchService
+func NewSearchService(index search.Index) *SearchService {
+	return &SearchService{index: index}
+}
+
+// GlobalSearchInput represents input for a cross-resource search
+type GlobalSearchInput struct {
+	UserID int64
+	Query  string
+	Types  []string
+	Limit  int
+}
+
+// SearchGroup holds the hits of one resource type
+type SearchGroup struct {
+	Type     string
+	Total    int64
+	TopScore float64
+	Hits     []search.Hit
+}
+
+// GlobalSearchResult is the grouped outcome of a search
+type GlobalSearchResult struct {
+	Query  string
+	Groups []SearchGroup
+}
+
+// Search runs the query against every requested resource type.
+// Groups are ordered by their best hit, and empty groups are omitted.
+func (s *SearchService) Search(ctx context.Context, input GlobalSearchInput) (*GlobalSearchResult, error) {
+	query := strings.TrimSpace(input.Query)
+	if query == "" {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"q": {"is required"},
+		})
+	}
+	if len([]rune(query)) > MaxSearchQueryLength {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"q": {"is too long (maximum is 100 characters)"},
+		})
+	}
+
+	types, err := normalizeSearchTypes(input.Types)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	result := &GlobalSearchResult{Query: query, Groups: []SearchGroup{}}
+	for _, t := range types {
+		group, err := s.index.Search(ctx, search.Query{
+			UserID: input.UserID,
+			Text:   query,
+			Type:   t,
+			Limit:  limit,
+		})
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "SearchService.Search: failed to search "+t+" via "+s.index.Name())
+		}
+		if len(group.Hits) == 0 {
+			continue
+		}
+		result.Groups = append(result.Groups, SearchGroup{
+			Type:     group.Type,
+			Total:    group.Total,
+			TopScore: group.Hits[0].Score,
+			Hits:     group.Hits,
+		})
+	}
+
+	// Stable sort keeps the display order of search.Types for equal scores
+	sort.SliceStable(result.Groups, func(i, j int) bool {
+		return result.Groups[i].TopScore > result.Groups[j].TopScore
+	})
+
+	return result, nil
+}
+
+// normalizeSearchTypes validates the requested types, defaulting to all
+func normalizeSearchTypes(types []string) ([]string, error) {
+	if len(types) == 0 {
+		return search.Types, nil
+	}
+
+	requested := make(map[string]bool, len(types))
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		valid := false
+		for _, known := range search.Types {
+			if t == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"types": {"Invalid type. Valid values: " + strings.Join(search.Types, ", ")},
+			})
+		}
+		requested[t] = true
+	}
+
+	// Keep display order regardless of the order in the request
+	normalized := make([]string, 0, len(requested))
+	for _, t := range search.Types {
+		if requested[t] {
+			normalized = append(normalized, t)
+		}
+	}
+	if len(normalized) == 0 {
+		return search.Types, nil
+	}
+	return normalized, nil
+}
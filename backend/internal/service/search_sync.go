@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/repository"
+	"todo-api/internal/search"
+)
+
+const (
+	// SearchSyncJobInterval is how often changed rows are copied to an external search index
+	SearchSyncJobInterval = 5 * time.Minute
+	// SearchFlushJobInterval is how often the index updates queued by writes are sent
+	SearchFlushJobInterval = 5 * time.Second
+	// searchSyncBatchSize is the number of rows sent to the index per request
+	searchSyncBatchSize = 500
+	// searchSyncOverlap re-reads rows changed shortly before the last run to catch late commits
+	searchSyncOverlap = time.Minute
+)
+
+// SearchSyncService copies changed rows to an external search index.
+// Flush sends the updates queued by the write callbacks registered by search.RegisterCallbacks. Run
+// complements them: the first run after startup reindexes everything, later runs only rows changed since
+// the previous run.
+type SearchSyncService struct {
+	searchRepo *repository.SearchRepository
+	syncer     search.Syncer
+
+	mu       sync.Mutex
+	lastSync time.Time
+}
+
+// NewSearchSyncService creates a new SearchSyncService
+func NewSearchSyncService(searchRepo *repository.SearchRepository, syncer search.Syncer) *SearchSyncService {
+	return &SearchSyncService{searchRepo: searchRepo, syncer: syncer}
+}
+
+// Run sends every row changed since the last successful run to the index
+func (s *SearchSyncService) Run(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	since := s.lastSync
+	if !since.IsZero() {
+		since = since.Add(-searchSyncOverlap)
+	}
+
+	steps := []struct {
+		docType string
+		sync    func(ctx context.Context, since time.Time) (int, error)
+	}{
+		{search.TypeTodo, s.syncTodos},
+		{search.TypeComment, s.syncComments},
+		{search.TypeCategory, s.syncCategories},
+		{search.TypeTag, s.syncTags},
+	}
+	for _, step := range steps {
+		count, err := step.sync(ctx, since)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			log.Info().Str("type", step.docType).Int("count", count).Msg("SearchSyncService.Run: synced documents")
+		}
+	}
+
+	s.lastSync = now
+	return nil
+}
+
+// Flush sends the index updates queued by writes that have committed, oldest first. Each row's document
+// is built from the row as it is now, so updates queued by several writes send its latest state once, and
+// rows that no longer exist are deleted from the index. Updates are dequeued once sent; if sending fails they
+// are sent again on the next run.
+func (s *SearchSyncService) Flush(ctx context.Context, _ time.Time) error {
+	for {
+		updates, err := s.searchRepo.FindQueuedUpdates(ctx, searchSyncBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+
+		idsByType := map[string][]int64{}
+		queued := make([]int64, len(updates))
+		for i, update := range updates {
+			idsByType[update.DocType] = append(idsByType[update.DocType], update.DocID)
+			queued[i] = update.ID
+		}
+		for _, docType := range search.Types {
+			ids := idsByType[docType]
+			if len(ids) == 0 {
+				continue
+			}
+			docs, gone, err := search.Documents(ctx, s.searchRepo, docType, ids)
+			if err != nil {
+				return err
+			}
+			if err := s.syncer.Upsert(ctx, docs); err != nil {
+				return err
+			}
+			if err := s.syncer.Delete(ctx, docType, gone); err != nil {
+				return err
+			}
+		}
+		if err := s.searchRepo.DeleteQueuedUpdates(ctx, queued); err != nil {
+			return err
+		}
+
+		if len(updates) < searchSyncBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *SearchSyncService) syncTodos(ctx context.Context, since time.Time) (int, error) {
+	return syncPages(ctx, s.syncer, since, s.searchRepo.FindTodosChangedSince, search.TodoDocument)
+}
+
+func (s *SearchSyncService) syncComments(ctx context.Context, since time.Time) (int, error) {
+	count := 0
+	for offset := 0; ; offset += searchSyncBatchSize {
+		comments, err := s.searchRepo.FindCommentsChangedSince(ctx, since, offset, searchSyncBatchSize)
+		if err != nil {
+			return count, err
+		}
+
+		var docs []search.Document
+		var deleted []int64
+		for i := range comments {
+			if comments[i].DeletedAt.Valid {
+				deleted = append(deleted, comments[i].ID)
+				continue
+			}
+			docs = append(docs, search.CommentDocument(&comments[i]))
+		}
+		if err := s.syncer.Upsert(ctx, docs); err != nil {
+			return count, err
+		}
+		if err := s.syncer.Delete(ctx, search.TypeComment, deleted); err != nil {
+			return count, err
+		}
+
+		count += len(comments)
+		if len(comments) < searchSyncBatchSize {
+			return count, nil
+		}
+	}
+}
+
+func (s *SearchSyncService) syncCategories(ctx context.Context, since time.Time) (int, error) {
+	return syncPages(ctx, s.syncer, since, s.searchRepo.FindCategoriesChangedSince, search.CategoryDocument)
+}
+
+func (s *SearchSyncService) syncTags(ctx context.Context, since time.Time) (int, error) {
+	return syncPages(ctx, s.syncer, since, s.searchRepo.FindTagsChangedSince, search.TagDocument)
+}
+
+// syncPages upserts the documents of all rows returned by find, one batch at a time
+func syncPages[T any](
+	ctx context.Context,
+	syncer search.Syncer,
+	since time.Time,
+	find func(ctx context.Context, since time.Time, offset, limit int) ([]T, error),
+	toDocument func(*T) search.Document,
+) (int, error) {
+	count := 0
+	for offset := 0; ; offset += searchSyncBatchSize {
+		rows, err := find(ctx, since, offset, searchSyncBatchSize)
+		if err != nil {
+			return count, err
+		}
+
+		docs := make([]search.Document, len(rows))
+		for i := range rows {
+			docs[i] = toDocument(&rows[i])
+		}
+		if err := syncer.Upsert(ctx, docs); err != nil {
+			return count, err
+		}
+
+		count += len(rows)
+		if len(rows) < searchSyncBatchSize {
+			return count, nil
+		}
+	}
+}
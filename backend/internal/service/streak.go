@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+)
+
+// StreakService tracks completion streaks and milestone achievements
+type StreakService struct {
+	streakRepo *repository.StreakRepository
+	prefRepo   *repository.UserPreferenceRepository
+}
+
+// NewStreakService creates a new StreakService
+func NewStreakService(
+	streakRepo *repository.StreakRepository,
+	prefRepo *repository.UserPreferenceRepository,
+) *StreakService {
+	return &StreakService{
+		streakRepo: streakRepo,
+		prefRepo:   prefRepo,
+	}
+}
+
+// StreakSummary represents a user's streak state and achievement progress
+type StreakSummary struct {
+	Streak       *model.UserStreak
+	Today        time.Time
+	Achievements []model.UserAchievement
+}
+
+// RecordCompletion updates the user's streak for a todo completed at the given time
+// and unlocks any achievements reached. Each todo is counted once, however often it is reopened
+// and completed again. Returns the newly unlocked achievements.
+func (s *StreakService) RecordCompletion(ctx context.Context, userID, todoID int64, completedAt time.Time) ([]model.UserAchievement, error) {
+	day, err := s.localDay(ctx, userID, completedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	streak, err := s.streakRepo.RecordCompletion(ctx, userID, todoID, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record completion: %w", err)
+	}
+	if streak == nil {
+		return nil, nil
+	}
+
+	var unlocked []model.UserAchievement
+	for _, def := range model.Achievements {
+		if !def.Unlocked(streak) {
+			continue
+		}
+		achievement := model.UserAchievement{
+			UserID:     userID,
+			Code:       def.Code,
+			AchievedAt: completedAt,
+		}
+		created, err := s.streakRepo.CreateAchievement(ctx, &achievement)
+		if err != nil {
+			return unlocked, fmt.Errorf("failed to record achievement %s: %w", def.Code, err)
+		}
+		if created {
+			unlocked = append(unlocked, achievement)
+		}
+	}
+
+	return unlocked, nil
+}
+
+// Get returns the user's streak and unlocked achievements
+func (s *StreakService) Get(ctx context.Context, userID int64) (*StreakSummary, error) {
+	today, err := s.localDay(ctx, userID, time.Now())
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "StreakService.Get: failed to resolve user timezone")
+	}
+
+	streak, err := s.streakRepo.FindOrNew(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "StreakService.Get: failed to fetch streak")
+	}
+
+	achievements, err := s.streakRepo.ListAchievements(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "StreakService.Get: failed to fetch achievements")
+	}
+
+	return &StreakSummary{
+		Streak:       streak,
+		Today:        today,
+		Achievements: achievements,
+	}, nil
+}
+
+// localDay returns the calendar day of t in the user's timezone, as midnight UTC
+func (s *StreakService) localDay(ctx context.Context, userID int64, t time.Time) (time.Time, error) {
+	pref, err := s.prefRepo.FindOrDefault(ctx, userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch preferences: %w", err)
+	}
+	local := t.In(pref.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC), nil
+}
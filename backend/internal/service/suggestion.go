@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/constants"
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/internal/suggest"
+)
+
+const (
+	// suggestionHistoryLimit is the number of recent todos sent to the provider as context
+	suggestionHistoryLimit = 50
+	// maxSuggestedDueDays caps suggested due dates at one year ahead
+	maxSuggestedDueDays = 365
+	// maxSubtaskLength caps the length of each suggested subtask title
+	maxSubtaskLength = 255
+	// maxSuggestedSubtasks caps the number of suggested subtasks
+	maxSuggestedSubtasks = 10
+)
+
+// SuggestionService proposes attributes for draft todos using a pluggable provider
+type SuggestionService struct {
+	provider     suggest.Provider
+	todoRepo     *repository.TodoRepository
+	categoryRepo *repository.CategoryRepository
+	prefRepo     *repository.UserPreferenceRepository
+}
+
+// NewSuggestionService creates a new SuggestionService.
+// A nil provider disables suggestions.
+func NewSuggestionService(
+	provider suggest.Provider,
+	todoRepo *repository.TodoRepository,
+	categoryRepo *repository.CategoryRepository,
+	prefRepo *repository.UserPreferenceRepository,
+) *SuggestionService {
+	return &SuggestionService{
+		provider:     provider,
+		todoRepo:     todoRepo,
+		categoryRepo: categoryRepo,
+		prefRepo:     prefRepo,
+	}
+}
+
+// SuggestInput represents a draft todo to suggest attributes for
+type SuggestInput struct {
+	UserID      int64
+	Title       string
+	Description string
+}
+
+// SuggestionResult represents validated suggestions for a draft todo
+type SuggestionResult struct {
+	Provider string
+	Priority *string
+	DueDate  *string
+	Category *model.Category
+	Subtasks []string
+}
+
+// Enabled returns true if a suggestion provider is configured
+func (s *SuggestionService) Enabled() bool {
+	return s.provider != nil
+}
+
+// Suggest asks the provider for suggestions and validates them against the user's data
+func (s *SuggestionService) Suggest(ctx context.Context, input SuggestInput) (*SuggestionResult, error) {
+	if !s.Enabled() {
+		return nil, errors.FeatureDisabled("ai_suggestions")
+	}
+
+	categories, err := s.categoryRepo.FindAllByUserID(ctx, input.UserID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "SuggestionService.Suggest: failed to fetch categories")
+	}
+	recent, err := s.todoRepo.FindRecent(ctx, input.UserID, suggestionHistoryLimit)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "SuggestionService.Suggest: failed to fetch recent todos")
+	}
+	pref, err := s.prefRepo.FindOrDefault(ctx, input.UserID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "SuggestionService.Suggest: failed to fetch preferences")
+	}
+
+	req := &suggest.Request{
+		Title:       input.Title,
+		Description: input.Description,
+		Categories:  make([]string, len(categories)),
+		History:     make([]suggest.HistoryItem, len(recent)),
+	}
+	for i, c := range categories {
+		req.Categories[i] = c.Name
+	}
+	for i, todo := range recent {
+		req.History[i] = toHistoryItem(todo)
+	}
+
+	suggestion, err := s.provider.Suggest(ctx, req)
+	if err != nil {
+		log.Error().Err(err).Str("provider", s.provider.Name()).Msg("SuggestionService.Suggest: provider failed")
+		return nil, errors.ExternalServiceError("ai_suggestions")
+	}
+
+	return s.normalize(suggestion, categories, pref.Location()), nil
+}
+
+// normalize discards suggestions that do not fit the API's constraints
+func (s *SuggestionService) normalize(suggestion *suggest.Suggestion, categories []model.Category, loc *time.Location) *SuggestionResult {
+	result := &SuggestionResult{
+		Provider: s.provider.Name(),
+		Subtasks: []string{},
+	}
+
+	priority := strings.ToLower(strings.TrimSpace(suggestion.Priority))
+	switch priority {
+	case "low", "medium", "high":
+		result.Priority = &priority
+	}
+
+	if suggestion.DueInDays != nil && *suggestion.DueInDays >= 0 && *suggestion.DueInDays <= maxSuggestedDueDays {
+		due := time.Now().In(loc).AddDate(0, 0, *suggestion.DueInDays).Format(constants.DateFormat)
+		result.DueDate = &due
+	}
+
+	if name := strings.TrimSpace(suggestion.Category); name != "" {
+		for i := range categories {
+			if strings.EqualFold(categories[i].Name, name) {
+				result.Category = &categories[i]
+				break
+			}
+		}
+	}
+
+	for _, subtask := range suggestion.Subtasks {
+		subtask = strings.TrimSpace(subtask)
+		if subtask == "" {
+			continue
+		}
+		if runes := []rune(subtask); len(runes) > maxSubtaskLength {
+			subtask = string(runes[:maxSubtaskLength])
+		}
+		result.Subtasks = append(result.Subtasks, subtask)
+		if len(result.Subtasks) == maxSuggestedSubtasks {
+			break
+		}
+	}
+
+	return result
+}
+
+// toHistoryItem summarizes a todo as provider context
+func toHistoryItem(todo model.Todo) suggest.HistoryItem {
+	item := suggest.HistoryItem{
+		Title:    todo.Title,
+		Priority: todo.Priority.String(),
+	}
+	if todo.Category != nil {
+		item.Category = todo.Category.Name
+	}
+	if todo.DueDate != nil {
+		created := time.Date(todo.CreatedAt.Year(), todo.CreatedAt.Month(), todo.CreatedAt.Day(), 0, 0, 0, 0, time.UTC)
+		days := int(todo.DueDate.Sub(created).Hours() / 24)
+		item.DueInDays = &days
+	}
+	return item
+}
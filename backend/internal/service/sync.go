@@ -0,0 +1,516 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/pkg/util"
+)
+
+const (
+	// SyncTombstoneRetention is how long deletions are kept for clients to pull; older sync tokens expire
+	SyncTombstoneRetention = 30 * 24 * time.Hour
+	// SyncTokenOverlap is how far before a sync token's time changes are pulled again,
+	// covering clock differences between servers and transactions committed late
+	SyncTokenOverlap = time.Minute
+	// MaxSyncPushChanges is how many changes a client can push at once
+	MaxSyncPushChanges = 100
+	// DefaultSyncPullLimit is how many todos a page of pulled changes has by default
+	DefaultSyncPullLimit = 200
+	// MaxSyncPullLimit is how many todos a page of pulled changes can have
+	MaxSyncPullLimit = 1000
+	// SyncCleanupJobInterval is how often expired tombstones are deleted
+	SyncCleanupJobInterval = 24 * time.Hour
+)
+
+// Operations a pushed change can make
+const (
+	SyncOpUpsert = "upsert"
+	SyncOpDelete = "delete"
+)
+
+// SyncResultStatus is the outcome of a pushed change
+type SyncResultStatus string
+
+const (
+	// SyncResultApplied means every field of the change was applied
+	SyncResultApplied SyncResultStatus = "applied"
+	// SyncResultMerged means the server kept its own value for the fields listed as conflicts
+	SyncResultMerged SyncResultStatus = "merged"
+	// SyncResultIgnored means a newer change on the server superseded the whole change
+	SyncResultIgnored SyncResultStatus = "ignored"
+	// SyncResultInvalid means the change failed validation and nothing was applied
+	SyncResultInvalid SyncResultStatus = "invalid"
+)
+
+// syncFields are the todo fields synced, in the order they are merged
+var syncFields = []string{"title", "description", "status", "priority", "due_date", "category_id"}
+
+// SyncChange is a change an offline client made to a todo
+type SyncChange struct {
+	UUID string
+	Op   string
+	// UpdatedAt is when the client made the change; times after the server time count as the server time
+	UpdatedAt time.Time
+	// Fields are the changed fields with their new values
+	Fields map[string]any
+	// Base are the values of the fields the client last pulled, used to tell its own changes from the server's
+	Base map[string]any
+}
+
+// SyncResult is the outcome of a pushed change along with the todo as stored
+type SyncResult struct {
+	UUID      string
+	Status    SyncResultStatus
+	Todo      *model.Todo
+	Deleted   bool
+	Conflicts []string
+	Error     *errors.ApiError
+}
+
+// SyncPull is a page of the changes since a sync token
+type SyncPull struct {
+	Todos      []model.Todo
+	Tombstones []model.TodoTombstone
+	SyncToken  string
+	HasMore    bool
+}
+
+// syncToken is the state encoded in the opaque sync tokens handed to clients
+type syncToken struct {
+	// Since is the time changes are pulled from (zero for a full sync)
+	Since time.Time `json:"s"`
+	// Next is when the first page was pulled; it becomes Since once the last page is pulled
+	Next time.Time `json:"n"`
+	// AfterUpdatedAt and AfterID are the position of the last todo pulled, when more pages follow
+	AfterUpdatedAt *time.Time `json:"u,omitempty"`
+	AfterID        int64      `json:"i,omitempty"`
+}
+
+// SyncService syncs todos with offline clients: clients push the changes they made locally
+// and pull the changes made elsewhere since their sync token
+type SyncService struct {
+	syncRepo    *repository.SyncRepository
+	todoService *TodoService
+}
+
+// NewSyncService creates a new SyncService
+func NewSyncService(syncRepo *repository.SyncRepository, todoService *TodoService) *SyncService {
+	return &SyncService{syncRepo: syncRepo, todoService: todoService}
+}
+
+// Pull returns a page of the user's todos changed and deleted since the sync token (everything without one).
+// Pages can repeat changes, so clients apply them by UUID.
+func (s *SyncService) Pull(ctx context.Context, userID int64, token string, limit int, now time.Time) (*SyncPull, error) {
+	state := syncToken{Next: now}
+	if token != "" {
+		decoded, err := decodeSyncToken(token)
+		if err != nil {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"sync_token": {"Invalid sync token"},
+			})
+		}
+		state = *decoded
+	}
+
+	firstPage := state.AfterUpdatedAt == nil
+	if firstPage {
+		if !state.Since.IsZero() && state.Since.Before(now.Add(-SyncTombstoneRetention)) {
+			return nil, errors.SyncTokenExpired()
+		}
+		state.Next = now
+		if err := s.syncRepo.AssignMissingUUIDs(ctx, userID); err != nil {
+			return nil, errors.InternalErrorWithLog(err, "SyncService.Pull: failed to assign todo UUIDs")
+		}
+	}
+
+	since := state.Since
+	if !since.IsZero() {
+		since = since.Add(-SyncTokenOverlap)
+	}
+
+	var after *repository.SyncCursor
+	if !firstPage {
+		after = &repository.SyncCursor{UpdatedAt: *state.AfterUpdatedAt, ID: state.AfterID}
+	}
+	todos, err := s.syncRepo.FindChangedTodos(ctx, userID, since, after, limit+1)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "SyncService.Pull: failed to fetch changed todos")
+	}
+
+	pull := &SyncPull{Todos: todos}
+	if firstPage {
+		pull.Tombstones, err = s.syncRepo.FindTombstonesSince(ctx, userID, since)
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "SyncService.Pull: failed to fetch tombstones")
+		}
+	}
+
+	next := syncToken{Since: state.Next, Next: state.Next}
+	if len(todos) > limit {
+		pull.Todos = todos[:limit]
+		pull.HasMore = true
+		last := pull.Todos[limit-1]
+		next = syncToken{
+			Since:          state.Since,
+			Next:           state.Next,
+			AfterUpdatedAt: &last.UpdatedAt,
+			AfterID:        last.ID,
+		}
+	}
+	pull.SyncToken = encodeSyncToken(next)
+
+	return pull, nil
+}
+
+// Push applies the changes a client made offline, in order, and returns the outcome of each.
+// Fields the server changed since the client's base keep the newer value (the server wins ties);
+// the other fields take the client's value.
+func (s *SyncService) Push(ctx context.Context, userID int64, changes []SyncChange, now time.Time) ([]SyncResult, error) {
+	// A client's own changes are ordered, so later changes to a todo in the same push
+	// are compared with the server as it was before the push
+	serverUpdatedAt := make(map[string]time.Time)
+
+	results := make([]SyncResult, len(changes))
+	for i, change := range changes {
+		if change.UpdatedAt.After(now) {
+			change.UpdatedAt = now
+		}
+
+		todo, err := s.syncRepo.FindTodoByUUID(ctx, change.UUID, userID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, errors.InternalErrorWithLog(err, "SyncService.Push: failed to fetch todo")
+		}
+		if _, seen := serverUpdatedAt[change.UUID]; !seen {
+			serverUpdatedAt[change.UUID] = time.Time{}
+			if todo != nil {
+				serverUpdatedAt[change.UUID] = todo.UpdatedAt
+			}
+		}
+
+		var result *SyncResult
+		switch {
+		case change.Op == SyncOpDelete:
+			result, err = s.pushDelete(ctx, userID, todo, change, serverUpdatedAt[change.UUID])
+		case todo == nil:
+			result, err = s.pushCreate(ctx, userID, change)
+		default:
+			result, err = s.pushUpdate(ctx, userID, todo, change, serverUpdatedAt[change.UUID])
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.UUID = change.UUID
+		results[i] = *result
+	}
+
+	return results, nil
+}
+
+// pushDelete deletes the todo unless it was updated on the server after the client deleted it
+func (s *SyncService) pushDelete(ctx context.Context, userID int64, todo *model.Todo, change SyncChange, serverUpdatedAt time.Time) (*SyncResult, error) {
+	if todo == nil {
+		return &SyncResult{Status: SyncResultApplied, Deleted: true}, nil
+	}
+	if serverUpdatedAt.After(change.UpdatedAt) {
+		return &SyncResult{Status: SyncResultIgnored, Todo: todo}, nil
+	}
+
+	if err := s.todoService.Delete(ctx, todo.ID, userID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &SyncResult{Status: SyncResultApplied, Deleted: true}, nil
+		}
+		return nil, errors.InternalErrorWithLog(err, "SyncService.Push: failed to delete todo")
+	}
+	return &SyncResult{Status: SyncResultApplied, Deleted: true}, nil
+}
+
+// pushCreate creates a todo the client created offline, unless it was deleted on the server after the change
+func (s *SyncService) pushCreate(ctx context.Context, userID int64, change SyncChange) (*SyncResult, error) {
+	tombstone, err := s.syncRepo.FindTombstone(ctx, change.UUID, userID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.InternalErrorWithLog(err, "SyncService.Push: failed to fetch tombstone")
+	}
+	if tombstone != nil && !change.UpdatedAt.After(tombstone.DeletedAt) {
+		return &SyncResult{Status: SyncResultIgnored, Deleted: true}, nil
+	}
+
+	values, apiErr := normalizeSyncValues(change.Fields, "fields")
+	if apiErr != nil {
+		return invalidSyncResult(apiErr), nil
+	}
+	if _, ok := values["title"]; !ok {
+		return invalidSyncResult(errors.ValidationFailed(map[string][]string{
+			"fields.title": {"is required to create a todo"},
+		})), nil
+	}
+
+	inUse, err := s.syncRepo.UUIDInUse(ctx, change.UUID, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "SyncService.Push: failed to check todo UUID")
+	}
+	if inUse {
+		return invalidSyncResult(errors.ValidationFailed(map[string][]string{
+			"uuid": {"is already in use"},
+		})), nil
+	}
+
+	input := CreateInput{
+		UserID:           userID,
+		UUID:             &change.UUID,
+		AllowPastDueDate: true,
+	}
+	input.Title, _ = values["title"].(string)
+	if description, ok := values["description"].(string); ok {
+		input.Description = &description
+	}
+	if status, ok := values["status"].(string); ok {
+		input.Status = &status
+	}
+	if priority, ok := values["priority"].(string); ok {
+		input.Priority = &priority
+	}
+	if dueDate, ok := values["due_date"].(string); ok {
+		input.DueDate = &dueDate
+	}
+	if categoryID, ok := values["category_id"].(int64); ok {
+		input.CategoryID = &categoryID
+	}
+
+	todo, err := s.todoService.Create(ctx, input)
+	if err != nil {
+		return syncErrorResult(err, "SyncService.Push: failed to create todo")
+	}
+
+	if tombstone != nil {
+		if err := s.syncRepo.DeleteTombstone(ctx, change.UUID, userID); err != nil {
+			log.Error().Err(err).Msg("SyncService.Push: failed to delete tombstone")
+		}
+	}
+
+	return &SyncResult{Status: SyncResultApplied, Todo: todo}, nil
+}
+
+// pushUpdate merges the client's fields into the todo.
+// A field changed on both sides since the client's base goes to the later change.
+func (s *SyncService) pushUpdate(ctx context.Context, userID int64, todo *model.Todo, change SyncChange, serverUpdatedAt time.Time) (*SyncResult, error) {
+	values, apiErr := normalizeSyncValues(change.Fields, "fields")
+	if apiErr != nil {
+		return invalidSyncResult(apiErr), nil
+	}
+	base, apiErr := normalizeSyncValues(change.Base, "base")
+	if apiErr != nil {
+		return invalidSyncResult(apiErr), nil
+	}
+
+	server := syncValuesOf(todo)
+	clientWins := change.UpdatedAt.After(serverUpdatedAt)
+
+	var input UpdateInput
+	var changed bool
+	var conflicts []string
+	for _, field := range syncFields {
+		value, ok := values[field]
+		if !ok || value == server[field] {
+			continue
+		}
+		if baseValue, ok := base[field]; !(ok && baseValue == server[field]) && !clientWins {
+			conflicts = append(conflicts, field)
+			continue
+		}
+		applySyncValue(&input, field, value)
+		changed = true
+	}
+
+	if changed {
+		updated, err := s.todoService.Update(ctx, todo.ID, userID, input)
+		if err != nil {
+			return syncErrorResult(err, "SyncService.Push: failed to update todo")
+		}
+		todo = updated
+	}
+
+	result := &SyncResult{Status: SyncResultApplied, Todo: todo, Conflicts: conflicts}
+	if len(conflicts) > 0 {
+		result.Status = SyncResultMerged
+	}
+	return result, nil
+}
+
+// Run deletes the tombstones older than the retention period
+func (s *SyncService) Run(ctx context.Context, now time.Time) error {
+	deleted, err := s.syncRepo.DeleteTombstonesBefore(ctx, now.Add(-SyncTombstoneRetention))
+	if err != nil {
+		return fmt.Errorf("failed to delete expired todo tombstones: %w", err)
+	}
+	if deleted > 0 {
+		log.Info().Int64("tombstones", deleted).Msg("Expired todo tombstones deleted")
+	}
+	return nil
+}
+
+// syncValuesOf returns the synced fields of a todo in their normalized form
+func syncValuesOf(todo *model.Todo) map[string]any {
+	values := map[string]any{
+		"title":       todo.Title,
+		"description": nil,
+		"status":      todo.Status.String(),
+		"priority":    todo.Priority.String(),
+		"due_date":    nil,
+		"category_id": nil,
+	}
+	if todo.Description != nil && *todo.Description != "" {
+		values["description"] = *todo.Description
+	}
+	if dueDate := util.FormatDate(todo.DueDate); dueDate != nil {
+		values["due_date"] = *dueDate
+	}
+	if todo.CategoryID != nil {
+		values["category_id"] = *todo.CategoryID
+	}
+	return values
+}
+
+// normalizeSyncValues validates pushed field values and normalizes them to compare with syncValuesOf:
+// strings, int64 category IDs and nil for cleared fields
+func normalizeSyncValues(fields map[string]any, prefix string) (map[string]any, *errors.ApiError) {
+	values := make(map[string]any, len(fields))
+	validationErrors := make(map[string][]string)
+	for field, value := range fields {
+		normalized, message := normalizeSyncValue(field, value)
+		if message != "" {
+			key := prefix + "." + field
+			validationErrors[key] = append(validationErrors[key], message)
+			continue
+		}
+		values[field] = normalized
+	}
+	if len(validationErrors) > 0 {
+		return nil, errors.ValidationFailed(validationErrors)
+	}
+	return values, nil
+}
+
+// normalizeSyncValue normalizes a single field value, or returns why it is invalid
+func normalizeSyncValue(field string, value any) (any, string) {
+	str, isString := value.(string)
+	switch field {
+	case "title":
+		if !isString || str == "" || utf8.RuneCountInString(str) > 255 {
+			return nil, "must be a string of 1 to 255 characters"
+		}
+		return str, ""
+	case "description":
+		if value == nil || (isString && str == "") {
+			return nil, ""
+		}
+		if !isString || utf8.RuneCountInString(str) > 10000 {
+			return nil, "must be a string of at most 10000 characters or null"
+		}
+		return str, ""
+	case "status":
+		if !isString || (str != "pending" && str != "in_progress" && str != "completed") {
+			return nil, "must be one of pending, in_progress, completed"
+		}
+		return str, ""
+	case "priority":
+		if !isString || (str != "low" && str != "medium" && str != "high") {
+			return nil, "must be one of low, medium, high"
+		}
+		return str, ""
+	case "due_date":
+		if value == nil || (isString && str == "") {
+			return nil, ""
+		}
+		if !isString {
+			return nil, "must be a date (YYYY-MM-DD) or null"
+		}
+		dueDate, err := util.ParseDate(str)
+		if err != nil {
+			return nil, "must be a date (YYYY-MM-DD) or null"
+		}
+		return *util.FormatDate(dueDate), ""
+	case "category_id":
+		if value == nil {
+			return nil, ""
+		}
+		id, ok := value.(float64)
+		if !ok || id < 1 || id != math.Trunc(id) {
+			return nil, "must be a category ID or null"
+		}
+		return int64(id), ""
+	default:
+		return nil, "is not a synced field"
+	}
+}
+
+// applySyncValue sets a normalized field value on an update, clearing the field for nil
+func applySyncValue(input *UpdateInput, field string, value any) {
+	str, _ := value.(string)
+	switch field {
+	case "title":
+		input.Title = &str
+	case "description":
+		input.Description = &str
+	case "status":
+		input.Status = &str
+	case "priority":
+		input.Priority = &str
+	case "due_date":
+		input.DueDate = &str
+	case "category_id":
+		id, _ := value.(int64)
+		input.CategoryID = &id
+	}
+}
+
+// invalidSyncResult returns the result of a change that failed validation
+func invalidSyncResult(apiErr *errors.ApiError) *SyncResult {
+	return &SyncResult{Status: SyncResultInvalid, Error: apiErr}
+}
+
+// syncErrorResult turns a client error from the todo service (e.g. an unknown category or the WIP limit)
+// into an invalid result; other errors fail the push
+func syncErrorResult(err error, context string) (*SyncResult, error) {
+	if apiErr, ok := err.(*errors.ApiError); ok && apiErr.StatusCode < http.StatusInternalServerError {
+		return invalidSyncResult(apiErr), nil
+	}
+	if _, ok := err.(*errors.ApiError); ok {
+		return nil, err
+	}
+	return nil, errors.InternalErrorWithLog(err, context)
+}
+
+// encodeSyncToken encodes a sync token for clients
+func encodeSyncToken(token syncToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSyncToken decodes a sync token from a client
+func decodeSyncToken(token string) (*syncToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	var decoded syncToken
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	if decoded.Next.IsZero() {
+		return nil, fmt.Errorf("sync token without a pull time")
+	}
+	return &decoded, nil
+}
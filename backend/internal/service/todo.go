@@ -1,11 +1,14 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
+	"todo-api/internal/config"
+	"todo-api/internal/encryption"
 	"todo-api/internal/errors"
 	"todo-api/internal/model"
 	"todo-api/internal/repository"
@@ -14,9 +17,12 @@ import (
 
 // TodoService handles todo business logic
 type TodoService struct {
-	todoRepo     *repository.TodoRepository
-	categoryRepo *repository.CategoryRepository
-	historyRepo  *repository.TodoHistoryRepository
+	todoRepo      *repository.TodoRepository
+	categoryRepo  *repository.CategoryRepository
+	historyRepo   *repository.TodoHistoryRepository
+	prefRepo      *repository.UserPreferenceRepository
+	streakService *StreakService
+	searchCfg     *config.SearchConfig
 }
 
 // NewTodoService creates a new TodoService
@@ -24,11 +30,17 @@ func NewTodoService(
 	todoRepo *repository.TodoRepository,
 	categoryRepo *repository.CategoryRepository,
 	historyRepo *repository.TodoHistoryRepository,
+	prefRepo *repository.UserPreferenceRepository,
+	streakService *StreakService,
+	searchCfg *config.SearchConfig,
 ) *TodoService {
 	return &TodoService{
-		todoRepo:     todoRepo,
-		categoryRepo: categoryRepo,
-		historyRepo:  historyRepo,
+		todoRepo:      todoRepo,
+		categoryRepo:  categoryRepo,
+		historyRepo:   historyRepo,
+		prefRepo:      prefRepo,
+		streakService: streakService,
+		searchCfg:     searchCfg,
 	}
 }
 
@@ -42,6 +54,10 @@ type CreateInput struct {
 	Status      *string
 	DueDate     *string
 	Position    *int
+	// UUID is generated by offline clients; a new one is assigned when nil
+	UUID *string
+	// AllowPastDueDate accepts due dates that passed while an offline client was creating the todo
+	AllowPastDueDate bool
 }
 
 // UpdateInput represents input for updating a todo
@@ -58,22 +74,23 @@ type UpdateInput struct {
 }
 
 // Create creates a new todo
-func (s *TodoService) Create(input CreateInput) (*model.Todo, error) {
+func (s *TodoService) Create(ctx context.Context, input CreateInput) (*model.Todo, error) {
 	// Validate category ownership if provided
 	if input.CategoryID != nil {
-		if err := s.validateCategoryOwnership(*input.CategoryID, input.UserID); err != nil {
+		if err := s.validateCategoryOwnership(ctx, *input.CategoryID, input.UserID); err != nil {
 			return nil, err
 		}
 	}
 
 	// Parse and validate due date
-	dueDate, err := s.parseDueDate(input.DueDate, true)
+	dueDate, err := s.parseDueDate(input.DueDate, !input.AllowPastDueDate)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create todo model
 	todo := &model.Todo{
+		UUID:        input.UUID,
 		UserID:      input.UserID,
 		Title:       input.Title,
 		Description: input.Description,
@@ -84,28 +101,52 @@ func (s *TodoService) Create(input CreateInput) (*model.Todo, error) {
 		Status:      s.resolveStatus(input.Status),
 	}
 
-	if err := s.todoRepo.Create(todo); err != nil {
+	// Todos created as completed count as completed now
+	if todo.Status == model.StatusCompleted {
+		now := time.Now()
+		todo.Completed = true
+		todo.CompletedAt = &now
+	}
+
+	var wipLimit int
+	if todo.Status == model.StatusInProgress {
+		if wipLimit, err = s.rejectingWIPLimit(ctx, input.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	if wipLimit > 0 {
+		if err := s.saveWithinWIPLimit(ctx, todo, wipLimit); err != nil {
+			return nil, err
+		}
+	} else if err := s.todoRepo.Create(ctx, todo); err != nil {
 		return nil, errors.InternalErrorWithLog(err, "TodoService.Create: failed to create todo")
 	}
 
 	// Record history
-	if err := s.recordCreatedHistory(todo, input.UserID); err != nil {
+	if err := s.recordCreatedHistory(ctx, todo, input.UserID); err != nil {
 		log.Error().Err(err).Msg("TodoService.Create: failed to record history")
 	}
 
 	// Increment category todo count if category is set
 	if todo.CategoryID != nil {
-		_ = s.categoryRepo.IncrementTodosCount(*todo.CategoryID)
+		_ = s.categoryRepo.IncrementTodosCount(ctx, *todo.CategoryID, todo.UserID)
+	}
+
+	if todo.CompletedAt != nil {
+		if _, err := s.streakService.RecordCompletion(ctx, input.UserID, todo.ID, *todo.CompletedAt); err != nil {
+			log.Error().Err(err).Msg("TodoService.Create: failed to record streak")
+		}
 	}
 
 	// Reload to get auto-generated position and relations
-	return s.todoRepo.FindByIDWithRelations(todo.ID, input.UserID)
+	return s.todoRepo.FindByIDWithRelations(ctx, todo.ID, input.UserID)
 }
 
 // Update updates an existing todo
-func (s *TodoService) Update(todoID, userID int64, input UpdateInput) (*model.Todo, error) {
+func (s *TodoService) Update(ctx context.Context, todoID, userID int64, input UpdateInput) (*model.Todo, error) {
 	// Get existing todo
-	todo, err := s.todoRepo.FindByID(todoID, userID)
+	todo, err := s.todoRepo.FindByID(ctx, todoID, userID)
 	if err != nil {
 		return nil, err // Let handler handle gorm.ErrRecordNotFound
 	}
@@ -119,13 +160,20 @@ func (s *TodoService) Update(todoID, userID int64, input UpdateInput) (*model.To
 	s.applyTextFields(todo, input)
 
 	// Handle category update
-	if err := s.applyCategory(todo, input.CategoryID, userID); err != nil {
+	if err := s.applyCategory(ctx, todo, input.CategoryID, userID); err != nil {
 		return nil, err
 	}
 
 	// Sync status and completed
 	s.syncStatusAndCompleted(todo, input)
 
+	var wipLimit int
+	if oldTodo.Status != model.StatusInProgress && todo.Status == model.StatusInProgress {
+		if wipLimit, err = s.rejectingWIPLimit(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply other fields
 	if input.Priority != nil {
 		todo.Priority = s.resolvePriority(input.Priority)
@@ -144,34 +192,54 @@ func (s *TodoService) Update(todoID, userID int64, input UpdateInput) (*model.To
 		todo.Position = input.Position
 	}
 
+	// Moving to another category appends the todo to the end of that category
+	if s.categoryChanged(oldCategoryID, todo.CategoryID) {
+		next, err := s.todoRepo.NextCategoryPosition(ctx, userID, todo.CategoryID)
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "TodoService.Update: failed to get category position")
+		}
+		todo.CategoryPosition = &next
+	}
+
 	// Save changes
-	if err := s.todoRepo.Update(todo); err != nil {
+	if wipLimit > 0 {
+		if err := s.saveWithinWIPLimit(ctx, todo, wipLimit); err != nil {
+			return nil, err
+		}
+	} else if err := s.todoRepo.Update(ctx, todo); err != nil {
 		return nil, errors.InternalErrorWithLog(err, "TodoService.Update: failed to update todo")
 	}
 
 	// Update tags if provided
 	if input.TagIDs != nil {
-		if err := s.todoRepo.ReplaceTags(todoID, *input.TagIDs); err != nil {
+		if err := s.todoRepo.ReplaceTags(ctx, todoID, *input.TagIDs); err != nil {
 			return nil, errors.InternalErrorWithLog(err, "TodoService.Update: failed to update tags")
 		}
 	}
 
 	// Record history
-	if err := s.recordUpdatedHistory(&oldTodo, todo, userID); err != nil {
+	if err := s.recordUpdatedHistory(ctx, &oldTodo, todo, userID); err != nil {
 		log.Error().Err(err).Msg("TodoService.Update: failed to record history")
 	}
 
 	// Update category counts if changed
-	s.updateCategoryCounts(oldCategoryID, todo.CategoryID)
+	s.updateCategoryCounts(ctx, oldCategoryID, todo.CategoryID, userID)
+
+	// Count newly completed todos towards the user's streak
+	if oldTodo.CompletedAt == nil && todo.CompletedAt != nil {
+		if _, err := s.streakService.RecordCompletion(ctx, userID, todo.ID, *todo.CompletedAt); err != nil {
+			log.Error().Err(err).Msg("TodoService.Update: failed to record streak")
+		}
+	}
 
 	// Reload with relations
-	return s.todoRepo.FindByIDWithRelations(todoID, userID)
+	return s.todoRepo.FindByIDWithRelations(ctx, todoID, userID)
 }
 
 // Delete deletes a todo
-func (s *TodoService) Delete(todoID, userID int64) error {
+func (s *TodoService) Delete(ctx context.Context, todoID, userID int64) error {
 	// Get todo first to update category count and record history
-	todo, err := s.todoRepo.FindByID(todoID, userID)
+	todo, err := s.todoRepo.FindByID(ctx, todoID, userID)
 	if err != nil {
 		return err // Let handler handle gorm.ErrRecordNotFound
 	}
@@ -179,26 +247,26 @@ func (s *TodoService) Delete(todoID, userID int64) error {
 	categoryID := todo.CategoryID
 
 	// Delete todo first
-	if err := s.todoRepo.Delete(todoID, userID); err != nil {
+	if err := s.todoRepo.Delete(ctx, todoID, userID); err != nil {
 		return err
 	}
 
 	// Record history after successful deletion
-	if err := s.recordDeletedHistory(todo, userID); err != nil {
+	if err := s.recordDeletedHistory(ctx, todo, userID); err != nil {
 		log.Error().Err(err).Msg("TodoService.Delete: failed to record history")
 	}
 
 	// Decrement category count if category was set
 	if categoryID != nil {
-		_ = s.categoryRepo.DecrementTodosCount(*categoryID)
+		_ = s.categoryRepo.DecrementTodosCount(ctx, *categoryID, userID)
 	}
 
 	return nil
 }
 
 // validateCategoryOwnership checks if a category belongs to the user
-func (s *TodoService) validateCategoryOwnership(categoryID, userID int64) error {
-	valid, err := s.todoRepo.ValidateCategoryOwnership(categoryID, userID)
+func (s *TodoService) validateCategoryOwnership(ctx context.Context, categoryID, userID int64) error {
+	valid, err := s.todoRepo.ValidateCategoryOwnership(ctx, categoryID, userID)
 	if err != nil {
 		return errors.InternalErrorWithLog(err, "TodoService: failed to validate category ownership")
 	}
@@ -243,7 +311,7 @@ func (s *TodoService) applyTextFields(todo *model.Todo, input UpdateInput) {
 }
 
 // applyCategory handles category updates including setting to null
-func (s *TodoService) applyCategory(todo *model.Todo, categoryID *int64, userID int64) error {
+func (s *TodoService) applyCategory(ctx context.Context, todo *model.Todo, categoryID *int64, userID int64) error {
 	if categoryID == nil {
 		return nil
 	}
@@ -255,7 +323,7 @@ func (s *TodoService) applyCategory(todo *model.Todo, categoryID *int64, userID
 	}
 
 	// Validate category ownership
-	if err := s.validateCategoryOwnership(*categoryID, userID); err != nil {
+	if err := s.validateCategoryOwnership(ctx, *categoryID, userID); err != nil {
 		return err
 	}
 	todo.CategoryID = categoryID
@@ -279,18 +347,28 @@ func (s *TodoService) syncStatusAndCompleted(todo *model.Todo, input UpdateInput
 		// Update completed based on status
 		todo.Completed = (todo.Status == model.StatusCompleted)
 	}
+
+	// Track when the todo was completed
+	if todo.Completed && todo.CompletedAt == nil {
+		now := time.Now()
+		todo.CompletedAt = &now
+	} else if !todo.Completed {
+		// Reopening an archived todo brings it back
+		todo.CompletedAt = nil
+		todo.ArchivedAt = nil
+	}
 }
 
 // updateCategoryCounts updates category counts when category changes
-func (s *TodoService) updateCategoryCounts(oldCategoryID, newCategoryID *int64) {
+func (s *TodoService) updateCategoryCounts(ctx context.Context, oldCategoryID, newCategoryID *int64, userID int64) {
 	if !s.categoryChanged(oldCategoryID, newCategoryID) {
 		return
 	}
 	if oldCategoryID != nil {
-		_ = s.categoryRepo.DecrementTodosCount(*oldCategoryID)
+		_ = s.categoryRepo.DecrementTodosCount(ctx, *oldCategoryID, userID)
 	}
 	if newCategoryID != nil {
-		_ = s.categoryRepo.IncrementTodosCount(*newCategoryID)
+		_ = s.categoryRepo.IncrementTodosCount(ctx, *newCategoryID, userID)
 	}
 }
 
@@ -350,27 +428,100 @@ type SearchInput struct {
 	TagMode        string
 	DueDateFrom    *time.Time
 	DueDateTo      *time.Time
-	SortBy         string
-	SortOrder      string
-	Page           int
-	PerPage        int
+	// CreatedFrom, CreatedTo, UpdatedFrom and UpdatedTo are dates (inclusive) in the user's time zone
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	// Archived searches archived todos instead of the others
+	Archived bool
+	// Fuzzy also matches the query with typos using trigram similarity
+	Fuzzy bool
+	// Similarity overrides the configured minimum similarity for fuzzy matching
+	Similarity *float64
+	// GroupBy additionally groups the page and counts all matches per group
+	GroupBy   string
+	SortBy    string
+	SortOrder string
+	// Nulls places todos without a due date or position first or last (default: last)
+	Nulls   string
+	Page    int
+	PerPage int
 }
 
+// DefaultFuzzyThreshold is the minimum similarity used when none is configured
+const DefaultFuzzyThreshold = 0.3
+
 // SearchResult represents the result of a search operation
 type SearchResult struct {
 	Todos      []model.Todo
 	Total      int64
 	HasFilters bool
+	// Groups is set when GroupBy was requested
+	Groups []TodoGroup
 }
 
 // Search searches todos with the given filters
-func (s *TodoService) Search(input SearchInput) (*SearchResult, error) {
+func (s *TodoService) Search(ctx context.Context, input SearchInput) (*SearchResult, error) {
 	// Validate search input
 	if err := s.validateSearchInput(&input); err != nil {
 		return nil, err
 	}
 
 	// Convert to repository input
+	repoInput, err := s.toRepoSearchInput(ctx, input)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Search: failed to fetch preferences")
+	}
+
+	// Execute search
+	todos, total, err := s.todoRepo.Search(ctx, repoInput)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Search: failed to search todos")
+	}
+
+	// Determine if any filters are applied
+	hasFilters := input.Query != "" ||
+		len(input.Statuses) > 0 ||
+		input.Priority != nil ||
+		input.CategoryID != nil ||
+		input.CategoryIDNull ||
+		len(input.TagIDs) > 0 ||
+		input.DueDateFrom != nil ||
+		input.DueDateTo != nil ||
+		input.CreatedFrom != nil ||
+		input.CreatedTo != nil ||
+		input.UpdatedFrom != nil ||
+		input.UpdatedTo != nil ||
+		input.Archived
+
+	result := &SearchResult{
+		Todos:      todos,
+		Total:      total,
+		HasFilters: hasFilters,
+	}
+
+	if input.GroupBy != "" {
+		today, err := s.userToday(ctx, input.UserID)
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "TodoService.Search: failed to fetch preferences")
+		}
+		counts, err := s.todoRepo.SearchGroupCounts(ctx, repoInput, input.GroupBy, today)
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "TodoService.Search: failed to count groups")
+		}
+		result.Groups, err = s.buildGroups(ctx, input.UserID, input.GroupBy, todos, counts, today)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// toRepoSearchInput converts a validated SearchInput to the repository search input.
+// Created and updated dates are converted to the bounds of those days in the user's time zone.
+func (s *TodoService) toRepoSearchInput(ctx context.Context, input SearchInput) (repository.SearchInput, error) {
 	repoInput := repository.SearchInput{
 		UserID:         input.UserID,
 		Query:          input.Query,
@@ -382,33 +533,52 @@ func (s *TodoService) Search(input SearchInput) (*SearchResult, error) {
 		TagMode:        input.TagMode,
 		DueDateFrom:    input.DueDateFrom,
 		DueDateTo:      input.DueDateTo,
+		Archived:       input.Archived,
+		FuzzyThreshold: s.fuzzyThreshold(input),
 		SortBy:         input.SortBy,
 		SortOrder:      input.SortOrder,
+		Nulls:          input.Nulls,
 		Page:           input.Page,
 		PerPage:        input.PerPage,
 	}
 
-	// Execute search
-	todos, total, err := s.todoRepo.Search(repoInput)
-	if err != nil {
-		return nil, errors.InternalErrorWithLog(err, "TodoService.Search: failed to search todos")
+	if input.CreatedFrom != nil || input.CreatedTo != nil || input.UpdatedFrom != nil || input.UpdatedTo != nil {
+		pref, err := s.prefRepo.FindOrDefault(ctx, input.UserID)
+		if err != nil {
+			return repository.SearchInput{}, err
+		}
+		loc := pref.Location()
+		repoInput.CreatedFrom = startOfDate(input.CreatedFrom, 0, loc)
+		repoInput.CreatedBefore = startOfDate(input.CreatedTo, 1, loc)
+		repoInput.UpdatedFrom = startOfDate(input.UpdatedFrom, 0, loc)
+		repoInput.UpdatedBefore = startOfDate(input.UpdatedTo, 1, loc)
 	}
 
-	// Determine if any filters are applied
-	hasFilters := input.Query != "" ||
-		len(input.Statuses) > 0 ||
-		input.Priority != nil ||
-		input.CategoryID != nil ||
-		input.CategoryIDNull ||
-		len(input.TagIDs) > 0 ||
-		input.DueDateFrom != nil ||
-		input.DueDateTo != nil
+	return repoInput, nil
+}
 
-	return &SearchResult{
-		Todos:      todos,
-		Total:      total,
-		HasFilters: hasFilters,
-	}, nil
+// startOfDate returns the start of the day days after date in loc, or nil without a date.
+// It is returned in UTC, as SQLite compares timestamps as text.
+func startOfDate(date *time.Time, days int, loc *time.Location) *time.Time {
+	if date == nil {
+		return nil
+	}
+	start := time.Date(date.Year(), date.Month(), date.Day()+days, 0, 0, 0, 0, loc).UTC()
+	return &start
+}
+
+// fuzzyThreshold returns the minimum similarity for fuzzy matching, or 0 when fuzzy matching is off
+func (s *TodoService) fuzzyThreshold(input SearchInput) float64 {
+	if !input.Fuzzy || input.Query == "" {
+		return 0
+	}
+	if input.Similarity != nil {
+		return *input.Similarity
+	}
+	if s.searchCfg != nil && s.searchCfg.FuzzyThreshold > 0 && s.searchCfg.FuzzyThreshold <= 1 {
+		return s.searchCfg.FuzzyThreshold
+	}
+	return DefaultFuzzyThreshold
 }
 
 // validateSearchInput validates the search input and applies defaults
@@ -422,10 +592,13 @@ func (s *TodoService) validateSearchInput(input *SearchInput) error {
 		"priority":   true,
 		"status":     true,
 		"position":   true,
+		// Orders by category, then by position within the category
+		"category_position": true,
+		"snooze_count":      true,
 	}
 	if input.SortBy != "" && !validSortFields[input.SortBy] {
 		return errors.ValidationFailed(map[string][]string{
-			"sort_by": {"Invalid sort field. Valid values: created_at, updated_at, due_date, title, priority, status, position"},
+			"sort_by": {"Invalid sort field. Valid values: created_at, updated_at, due_date, title, priority, status, position, category_position, snooze_count"},
 		})
 	}
 
@@ -439,6 +612,39 @@ func (s *TodoService) validateSearchInput(input *SearchInput) error {
 		input.SortOrder = "desc"
 	}
 
+	// Validate and set default for nulls; undated todos are last in either direction
+	if input.Nulls != "" && input.Nulls != "first" && input.Nulls != "last" {
+		return errors.ValidationFailed(map[string][]string{
+			"nulls": {"Invalid nulls placement. Valid values: first, last"},
+		})
+	}
+	if input.Nulls == "" {
+		input.Nulls = "last"
+	}
+
+	if err := ValidateTodoGroupBy(input.GroupBy); err != nil {
+		return err
+	}
+
+	// Validate created and updated date ranges
+	if input.CreatedFrom != nil && input.CreatedTo != nil && input.CreatedTo.Before(*input.CreatedFrom) {
+		return errors.ValidationFailed(map[string][]string{
+			"created_to": {"must be on or after created_from"},
+		})
+	}
+	if input.UpdatedFrom != nil && input.UpdatedTo != nil && input.UpdatedTo.Before(*input.UpdatedFrom) {
+		return errors.ValidationFailed(map[string][]string{
+			"updated_to": {"must be on or after updated_from"},
+		})
+	}
+
+	// Validate similarity threshold for fuzzy matching
+	if input.Similarity != nil && (*input.Similarity <= 0 || *input.Similarity > 1) {
+		return errors.ValidationFailed(map[string][]string{
+			"similarity": {"must be greater than 0 and at most 1"},
+		})
+	}
+
 	// Validate and set default for tag_mode
 	if input.TagMode != "" && input.TagMode != "any" && input.TagMode != "all" {
 		return errors.ValidationFailed(map[string][]string{
@@ -470,17 +676,17 @@ func (s *TodoService) validateSearchInput(input *SearchInput) error {
 // =============================================================================
 
 // recordCreatedHistory records a history entry for todo creation
-func (s *TodoService) recordCreatedHistory(todo *model.Todo, userID int64) error {
+func (s *TodoService) recordCreatedHistory(ctx context.Context, todo *model.Todo, userID int64) error {
 	if s.historyRepo == nil {
 		return nil
 	}
 
 	changes := s.buildCreatedChanges(todo)
-	return s.recordHistory(todo.ID, userID, model.ActionCreated, changes)
+	return s.recordHistory(ctx, todo.ID, userID, model.ActionCreated, changes)
 }
 
 // recordUpdatedHistory records a history entry for todo update
-func (s *TodoService) recordUpdatedHistory(oldTodo, newTodo *model.Todo, userID int64) error {
+func (s *TodoService) recordUpdatedHistory(ctx context.Context, oldTodo, newTodo *model.Todo, userID int64) error {
 	if s.historyRepo == nil {
 		return nil
 	}
@@ -490,21 +696,21 @@ func (s *TodoService) recordUpdatedHistory(oldTodo, newTodo *model.Todo, userID
 		return nil
 	}
 
-	return s.recordHistory(newTodo.ID, userID, action, changes)
+	return s.recordHistory(ctx, newTodo.ID, userID, action, changes)
 }
 
 // recordDeletedHistory records a history entry for todo deletion
-func (s *TodoService) recordDeletedHistory(todo *model.Todo, userID int64) error {
+func (s *TodoService) recordDeletedHistory(ctx context.Context, todo *model.Todo, userID int64) error {
 	if s.historyRepo == nil {
 		return nil
 	}
 
 	changes := s.buildDeletedChanges(todo)
-	return s.recordHistory(todo.ID, userID, model.ActionDeleted, changes)
+	return s.recordHistory(ctx, todo.ID, userID, model.ActionDeleted, changes)
 }
 
 // recordHistory creates a history record
-func (s *TodoService) recordHistory(todoID, userID int64, action model.HistoryAction, changes map[string]interface{}) error {
+func (s *TodoService) recordHistory(ctx context.Context, todoID, userID int64, action model.HistoryAction, changes map[string]interface{}) error {
 	changesJSON, err := json.Marshal(changes)
 	if err != nil {
 		return err
@@ -517,7 +723,7 @@ func (s *TodoService) recordHistory(todoID, userID int64, action model.HistoryAc
 		Changes: changesJSON,
 	}
 
-	return s.historyRepo.Create(history)
+	return s.historyRepo.Create(ctx, history)
 }
 
 // buildCreatedChanges builds the changes map for a created action
@@ -528,7 +734,7 @@ func (s *TodoService) buildCreatedChanges(todo *model.Todo) map[string]interface
 		"status":   todo.Status.String(),
 	}
 	if todo.Description != nil && *todo.Description != "" {
-		changes["description"] = *todo.Description
+		changes["description"] = historyDescription(*todo.Description)
 	}
 	if todo.DueDate != nil {
 		changes["due_date"] = todo.DueDate.Format("2006-01-02")
@@ -539,6 +745,15 @@ func (s *TodoService) buildCreatedChanges(todo *model.Todo) map[string]interface
 	return changes
 }
 
+// historyDescription returns the description to record in history.
+// Encrypted descriptions are not copied into history in plaintext.
+func historyDescription(description string) interface{} {
+	if encryption.Enabled() {
+		return encryption.Redacted
+	}
+	return description
+}
+
 // buildDeletedChanges builds the changes map for a deleted action
 func (s *TodoService) buildDeletedChanges(todo *model.Todo) map[string]interface{} {
 	changes := map[string]interface{}{
@@ -548,7 +763,7 @@ func (s *TodoService) buildDeletedChanges(todo *model.Todo) map[string]interface
 		"status":    todo.Status.String(),
 	}
 	if todo.Description != nil && *todo.Description != "" {
-		changes["description"] = *todo.Description
+		changes["description"] = historyDescription(*todo.Description)
 	}
 	if todo.DueDate != nil {
 		changes["due_date"] = todo.DueDate.Format("2006-01-02")
@@ -581,10 +796,10 @@ func (s *TodoService) detectChanges(oldTodo, newTodo *model.Todo) (model.History
 	if oldDesc != newDesc {
 		var oldVal, newVal interface{} = nil, nil
 		if oldTodo.Description != nil && *oldTodo.Description != "" {
-			oldVal = *oldTodo.Description
+			oldVal = historyDescription(*oldTodo.Description)
 		}
 		if newTodo.Description != nil && *newTodo.Description != "" {
-			newVal = *newTodo.Description
+			newVal = historyDescription(*newTodo.Description)
 		}
 		changes["description"] = []interface{}{oldVal, newVal}
 	}
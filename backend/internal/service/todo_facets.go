@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+)
+
+// TodoFacets holds the number of todos per filter value for the current search filters
+type TodoFacets struct {
+	// Total is the number of todos matching all filters
+	Total     int64
+	Status    []TodoGroup
+	Priority  []TodoGroup
+	Category  []TodoGroup
+	Tag       []repository.TagCount
+	DueBucket []TodoGroup
+}
+
+// Facets counts the todos matching the search filters per status, priority, category, tag, and due bucket.
+// Each facet ignores its own filter, so the counts show how many todos each alternative value would match.
+func (s *TodoService) Facets(ctx context.Context, input SearchInput) (*TodoFacets, error) {
+	if err := s.validateSearchInput(&input); err != nil {
+		return nil, err
+	}
+	repoInput, err := s.toRepoSearchInput(ctx, input)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Facets: failed to fetch preferences")
+	}
+
+	today, err := s.userToday(ctx, input.UserID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Facets: failed to fetch preferences")
+	}
+
+	total, err := s.todoRepo.SearchCount(ctx, repoInput)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Facets: failed to count todos")
+	}
+	facets := &TodoFacets{Total: total}
+
+	statusInput := repoInput
+	statusInput.Statuses = nil
+	if facets.Status, err = s.facetGroups(ctx, statusInput, model.TodoGroupByStatus, today); err != nil {
+		return nil, err
+	}
+
+	priorityInput := repoInput
+	priorityInput.Priority = nil
+	if facets.Priority, err = s.facetGroups(ctx, priorityInput, model.TodoGroupByPriority, today); err != nil {
+		return nil, err
+	}
+
+	categoryInput := repoInput
+	categoryInput.CategoryID = nil
+	categoryInput.CategoryIDNull = false
+	if facets.Category, err = s.facetGroups(ctx, categoryInput, model.TodoGroupByCategory, today); err != nil {
+		return nil, err
+	}
+
+	dueInput := repoInput
+	dueInput.DueDateFrom = nil
+	dueInput.DueDateTo = nil
+	if facets.DueBucket, err = s.facetGroups(ctx, dueInput, model.TodoGroupByDueBucket, today); err != nil {
+		return nil, err
+	}
+
+	tagInput := repoInput
+	tagInput.TagIDs = nil
+	facets.Tag, err = s.todoRepo.SearchTagCounts(ctx, tagInput)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Facets: failed to count tags")
+	}
+
+	return facets, nil
+}
+
+// facetGroups counts the todos matching the filters in every group of groupBy
+func (s *TodoService) facetGroups(ctx context.Context, input repository.SearchInput, groupBy string, today time.Time) ([]TodoGroup, error) {
+	counts, err := s.todoRepo.SearchGroupCounts(ctx, input, groupBy, today)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.facetGroups: failed to count "+groupBy)
+	}
+	return s.buildGroups(ctx, input.UserID, groupBy, nil, counts, today)
+}
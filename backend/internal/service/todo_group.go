@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/pkg/util"
+)
+
+// todoGroupByValues lists the accepted group_by values for error messages
+var todoGroupByValues = []string{
+	model.TodoGroupByStatus,
+	model.TodoGroupByPriority,
+	model.TodoGroupByCategory,
+	model.TodoGroupByDueBucket,
+}
+
+// TodoGroup holds the todos that share one value of the group_by field
+type TodoGroup struct {
+	// Key is the status or priority name, the category ID ("" for uncategorized), or the due bucket
+	Key string
+	// Category is set for category groups, except the uncategorized one
+	Category *model.Category
+	// Count is the number of todos in the group, including those not in Todos
+	Count int64
+	Todos []model.Todo
+}
+
+// ValidateTodoGroupBy validates a group_by value; an empty value disables grouping
+func ValidateTodoGroupBy(groupBy string) error {
+	if groupBy == "" || model.IsValidTodoGroupBy(groupBy) {
+		return nil
+	}
+	return errors.ValidationFailed(map[string][]string{
+		"group_by": {"Invalid group. Valid values: " + strings.Join(todoGroupByValues, ", ")},
+	})
+}
+
+// GroupTodos groups the todos by the given field.
+// Every possible group is returned, in display order, even when it is empty.
+func (s *TodoService) GroupTodos(ctx context.Context, userID int64, groupBy string, todos []model.Todo) ([]TodoGroup, error) {
+	if err := ValidateTodoGroupBy(groupBy); err != nil {
+		return nil, err
+	}
+
+	today, err := s.userToday(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.GroupTodos: failed to fetch preferences")
+	}
+	groups, err := s.buildGroups(ctx, userID, groupBy, todos, nil, today)
+	if err != nil {
+		return nil, err
+	}
+
+	// Category lists are ordered by the position within the category
+	if groupBy == model.TodoGroupByCategory {
+		for _, g := range groups {
+			sort.SliceStable(g.Todos, func(i, j int) bool {
+				return util.DerefInt(g.Todos[i].CategoryPosition, 0) < util.DerefInt(g.Todos[j].CategoryPosition, 0)
+			})
+		}
+	}
+	return groups, nil
+}
+
+// buildGroups distributes todos into the groups of groupBy.
+// Counts come from counts when given, otherwise from todos.
+func (s *TodoService) buildGroups(ctx context.Context, userID int64, groupBy string, todos []model.Todo, counts map[string]int64, today time.Time) ([]TodoGroup, error) {
+	var groups []TodoGroup
+	switch groupBy {
+	case model.TodoGroupByStatus:
+		for _, status := range []model.Status{model.StatusPending, model.StatusInProgress, model.StatusCompleted} {
+			groups = append(groups, TodoGroup{Key: status.String()})
+		}
+	case model.TodoGroupByPriority:
+		for _, priority := range []model.Priority{model.PriorityHigh, model.PriorityMedium, model.PriorityLow} {
+			groups = append(groups, TodoGroup{Key: priority.String()})
+		}
+	case model.TodoGroupByCategory:
+		categories, err := s.categoryRepo.FindAllByUserID(ctx, userID)
+		if err != nil {
+			return nil, errors.InternalErrorWithLog(err, "TodoService.buildGroups: failed to fetch categories")
+		}
+		for i := range categories {
+			groups = append(groups, TodoGroup{
+				Key:      strconv.FormatInt(categories[i].ID, 10),
+				Category: &categories[i],
+			})
+		}
+		groups = append(groups, TodoGroup{Key: ""})
+	case model.TodoGroupByDueBucket:
+		for _, bucket := range model.DueBuckets {
+			groups = append(groups, TodoGroup{Key: bucket})
+		}
+	}
+
+	index := make(map[string]int, len(groups))
+	for i, g := range groups {
+		index[g.Key] = i
+		groups[i].Todos = []model.Todo{}
+	}
+	for _, todo := range todos {
+		if i, ok := index[todoGroupKey(&todo, groupBy, today)]; ok {
+			groups[i].Todos = append(groups[i].Todos, todo)
+		}
+	}
+	for i := range groups {
+		if counts != nil {
+			groups[i].Count = counts[groups[i].Key]
+		} else {
+			groups[i].Count = int64(len(groups[i].Todos))
+		}
+	}
+
+	return groups, nil
+}
+
+// todoGroupKey returns the key of the group the todo belongs to
+func todoGroupKey(todo *model.Todo, groupBy string, today time.Time) string {
+	switch groupBy {
+	case model.TodoGroupByStatus:
+		return todo.Status.String()
+	case model.TodoGroupByPriority:
+		return todo.Priority.String()
+	case model.TodoGroupByCategory:
+		if todo.CategoryID == nil {
+			return ""
+		}
+		return strconv.FormatInt(*todo.CategoryID, 10)
+	case model.TodoGroupByDueBucket:
+		return todo.DueBucket(today)
+	}
+	return ""
+}
+
+// userToday returns the current date in the user's time zone
+func (s *TodoService) userToday(ctx context.Context, userID int64) (time.Time, error) {
+	pref, err := s.prefRepo.FindOrDefault(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().In(pref.Location()), nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+)
+
+// TodoLinkService manages non-blocking links between todos
+type TodoLinkService struct {
+	linkRepo *repository.TodoLinkRepository
+	todoRepo *repository.TodoRepository
+}
+
+// NewTodoLinkService creates a new TodoLinkService
+func NewTodoLinkService(linkRepo *repository.TodoLinkRepository, todoRepo *repository.TodoRepository) *TodoLinkService {
+	return &TodoLinkService{
+		linkRepo: linkRepo,
+		todoRepo: todoRepo,
+	}
+}
+
+// TodoLinks holds the links from a todo and the links to it
+type TodoLinks struct {
+	Links     []model.TodoLink
+	Backlinks []model.TodoLink
+}
+
+// CreateTodoLinkInput represents input for linking a todo to another
+type CreateTodoLinkInput struct {
+	TargetTodoID int64
+	// LinkType defaults to relates_to
+	LinkType *string
+}
+
+// List retrieves the links and backlinks of a todo
+func (s *TodoLinkService) List(ctx context.Context, todoID, userID int64) (*TodoLinks, error) {
+	if _, err := s.todoRepo.FindByID(ctx, todoID, userID); err != nil {
+		return nil, err // Let handler handle gorm.ErrRecordNotFound
+	}
+
+	links, err := s.linkRepo.FindBySourceTodoID(ctx, todoID, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoLinkService.List: failed to fetch links")
+	}
+	backlinks, err := s.linkRepo.FindByTargetTodoID(ctx, todoID, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoLinkService.List: failed to fetch backlinks")
+	}
+
+	return &TodoLinks{Links: links, Backlinks: backlinks}, nil
+}
+
+// Create links a todo to another todo of the same user
+func (s *TodoLinkService) Create(ctx context.Context, todoID, userID int64, input CreateTodoLinkInput) (*model.TodoLink, error) {
+	if _, err := s.todoRepo.FindByID(ctx, todoID, userID); err != nil {
+		return nil, err // Let handler handle gorm.ErrRecordNotFound
+	}
+
+	linkType := model.LinkTypeRelatesTo
+	if input.LinkType != nil {
+		linkType = model.LinkType(*input.LinkType)
+	}
+	if err := validateLinkType(linkType); err != nil {
+		return nil, err
+	}
+
+	if input.TargetTodoID == todoID {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"target_todo_id": {"cannot link a todo to itself"},
+		})
+	}
+	exists, err := s.todoRepo.ExistsByID(ctx, input.TargetTodoID, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoLinkService.Create: failed to check target todo")
+	}
+	if !exists {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"target_todo_id": {"todo not found"},
+		})
+	}
+
+	if err := s.checkDuplicate(ctx, todoID, input.TargetTodoID, userID, linkType, nil); err != nil {
+		return nil, err
+	}
+
+	link := &model.TodoLink{
+		UserID:       userID,
+		SourceTodoID: todoID,
+		TargetTodoID: input.TargetTodoID,
+		LinkType:     linkType,
+	}
+	if err := s.linkRepo.Create(ctx, link); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoLinkService.Create: failed to create link")
+	}
+
+	return s.linkRepo.FindByID(ctx, link.ID, userID)
+}
+
+// Update changes the type of a link from or to the todo
+func (s *TodoLinkService) Update(ctx context.Context, todoID, linkID, userID int64, linkType string) (*model.TodoLink, error) {
+	link, err := s.findForTodo(ctx, todoID, linkID, userID)
+	if err != nil {
+		return nil, err // Let handler handle gorm.ErrRecordNotFound
+	}
+
+	newType := model.LinkType(linkType)
+	if err := validateLinkType(newType); err != nil {
+		return nil, err
+	}
+	if newType == link.LinkType {
+		return link, nil
+	}
+
+	if err := s.checkDuplicate(ctx, link.SourceTodoID, link.TargetTodoID, userID, newType, &link.ID); err != nil {
+		return nil, err
+	}
+	if err := s.linkRepo.UpdateLinkType(ctx, link, newType); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoLinkService.Update: failed to update link")
+	}
+
+	return s.linkRepo.FindByID(ctx, link.ID, userID)
+}
+
+// Delete removes a link from or to the todo
+func (s *TodoLinkService) Delete(ctx context.Context, todoID, linkID, userID int64) error {
+	if _, err := s.findForTodo(ctx, todoID, linkID, userID); err != nil {
+		return err // Let handler handle gorm.ErrRecordNotFound
+	}
+	return s.linkRepo.Delete(ctx, linkID, userID)
+}
+
+// findForTodo retrieves a link that starts or ends at the todo
+func (s *TodoLinkService) findForTodo(ctx context.Context, todoID, linkID, userID int64) (*model.TodoLink, error) {
+	link, err := s.linkRepo.FindByID(ctx, linkID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if link.SourceTodoID != todoID && link.TargetTodoID != todoID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return link, nil
+}
+
+// checkDuplicate rejects a second link of the same type between two todos, in either direction
+func (s *TodoLinkService) checkDuplicate(ctx context.Context, todoID, otherTodoID, userID int64, linkType model.LinkType, excludeID *int64) error {
+	exists, err := s.linkRepo.ExistsBetween(ctx, todoID, otherTodoID, userID, linkType, excludeID)
+	if err != nil {
+		return errors.InternalErrorWithLog(err, "TodoLinkService.checkDuplicate: failed to check links")
+	}
+	if exists {
+		return errors.DuplicateResource("TodoLink", "target_todo_id")
+	}
+	return nil
+}
+
+// validateLinkType returns a validation error for unsupported link types
+func validateLinkType(linkType model.LinkType) error {
+	if !model.IsValidLinkType(linkType) {
+		return errors.ValidationFailed(map[string][]string{
+			"link_type": {"Invalid link type. Valid values: relates_to, duplicates"},
+		})
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+)
+
+// Reorder scopes
+const (
+	ReorderScopeGlobal   = "global"
+	ReorderScopeCategory = "category"
+)
+
+// ReorderInput represents input for moving a todo next to another; exactly one of AfterID and BeforeID is set
+type ReorderInput struct {
+	AfterID  *int64
+	BeforeID *int64
+	// Scope is ReorderScopeGlobal (default) to change position, or ReorderScopeCategory to change
+	// category_position, in which case the anchor must be in the same category
+	Scope string
+}
+
+// Reorder moves a todo directly after or before another todo, computing the new position on the server
+func (s *TodoService) Reorder(ctx context.Context, todoID, userID int64, input ReorderInput) (*model.Todo, error) {
+	field := "after_id"
+	anchorID := input.AfterID
+	if input.BeforeID != nil {
+		field = "before_id"
+		anchorID = input.BeforeID
+	}
+	if (input.AfterID == nil) == (input.BeforeID == nil) {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"after_id": {"Specify either after_id or before_id"},
+		})
+	}
+	if *anchorID == todoID {
+		return nil, errors.ValidationFailed(map[string][]string{
+			field: {"cannot be the todo itself"},
+		})
+	}
+
+	todo, err := s.todoRepo.FindByID(ctx, todoID, userID)
+	if err != nil {
+		return nil, err // Let handler handle gorm.ErrRecordNotFound
+	}
+
+	anchor, err := s.todoRepo.FindByID(ctx, *anchorID, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ValidationFailed(map[string][]string{
+				field: {"todo not found"},
+			})
+		}
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Reorder: failed to fetch anchor todo")
+	}
+
+	inCategory := input.Scope == ReorderScopeCategory
+	if inCategory && !s.equalInt64Ptr(todo.CategoryID, anchor.CategoryID) {
+		return nil, errors.ValidationFailed(map[string][]string{
+			field: {"must be in the same category as the todo"},
+		})
+	}
+
+	if err := s.todoRepo.MoveRelative(ctx, todo, anchor.ID, input.AfterID != nil, inCategory); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Reorder: failed to move todo")
+	}
+
+	return s.todoRepo.FindByIDWithRelations(ctx, todoID, userID)
+}
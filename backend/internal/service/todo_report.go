@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+)
+
+// MaxReportTodos is the maximum number of todos listed in a report; the summary counts all matches
+const MaxReportTodos = 500
+
+// TodoReport holds the todos matching the search filters along with a summary, for a printable report
+type TodoReport struct {
+	// GeneratedAt is in the user's time zone
+	GeneratedAt time.Time
+	// Total is the number of todos matching the filters, including those not in Todos
+	Total     int64
+	Status    []TodoGroup
+	Priority  []TodoGroup
+	DueBucket []TodoGroup
+	Todos     []model.Todo
+}
+
+// CompletionRate returns the share of completed todos from 0 to 1
+func (r *TodoReport) CompletionRate() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	for _, group := range r.Status {
+		if group.Key == model.StatusCompleted.String() {
+			return float64(group.Count) / float64(r.Total)
+		}
+	}
+	return 0
+}
+
+// Report collects the todos matching the search filters, in the search order, and counts them
+// per status, priority, and due bucket. Pagination parameters are ignored.
+func (s *TodoService) Report(ctx context.Context, input SearchInput) (*TodoReport, error) {
+	if err := s.validateSearchInput(&input); err != nil {
+		return nil, err
+	}
+	input.Page = 1
+	input.PerPage = MaxReportTodos
+
+	repoInput, err := s.toRepoSearchInput(ctx, input)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Report: failed to fetch preferences")
+	}
+
+	today, err := s.userToday(ctx, input.UserID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Report: failed to fetch preferences")
+	}
+
+	todos, total, err := s.todoRepo.Search(ctx, repoInput)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Report: failed to search todos")
+	}
+	report := &TodoReport{GeneratedAt: today, Total: total, Todos: todos}
+
+	if report.Status, err = s.facetGroups(ctx, repoInput, model.TodoGroupByStatus, today); err != nil {
+		return nil, err
+	}
+	if report.Priority, err = s.facetGroups(ctx, repoInput, model.TodoGroupByPriority, today); err != nil {
+		return nil, err
+	}
+	if report.DueBucket, err = s.facetGroups(ctx, repoInput, model.TodoGroupByDueBucket, today); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+)
+
+// maxSnoozeDays caps how far a single snooze can push the due date
+const maxSnoozeDays = 365
+
+// snoozeDurationPattern matches durations such as "3d" or "2w"
+var snoozeDurationPattern = regexp.MustCompile(`^([1-9][0-9]*)([dw])$`)
+
+// SnoozeInput represents input for snoozing a todo; exactly one of Preset and Duration is set
+type SnoozeInput struct {
+	Preset   *string
+	Duration *string
+}
+
+// Snooze pushes the due date of an incomplete todo back and records the snooze in history.
+// Presets are relative to today in the user's time zone. Durations are added to the current due date,
+// or to today when the todo has no due date or is overdue.
+func (s *TodoService) Snooze(ctx context.Context, todoID, userID int64, input SnoozeInput) (*model.Todo, error) {
+	if (input.Preset == nil) == (input.Duration == nil) {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"preset": {"Specify either preset or duration"},
+		})
+	}
+
+	todo, err := s.todoRepo.FindByID(ctx, todoID, userID)
+	if err != nil {
+		return nil, err // Let handler handle gorm.ErrRecordNotFound
+	}
+	if todo.Completed {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"status": {"Completed todos cannot be snoozed"},
+		})
+	}
+
+	local, err := s.userToday(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Snooze: failed to fetch preferences")
+	}
+	// Same calendar date as a UTC midnight for the date column
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	changes := map[string]interface{}{}
+	var newDueDate time.Time
+	if input.Preset != nil {
+		switch *input.Preset {
+		case model.SnoozePresetTomorrow:
+			newDueDate = today.AddDate(0, 0, 1)
+		case model.SnoozePresetNextWeek:
+			// Monday of next week
+			days := (8 - int(today.Weekday())) % 7
+			if days == 0 {
+				days = 7
+			}
+			newDueDate = today.AddDate(0, 0, days)
+		default:
+			return nil, errors.ValidationFailed(map[string][]string{
+				"preset": {"Invalid preset. Valid values: tomorrow, next_week"},
+			})
+		}
+		changes["preset"] = *input.Preset
+	} else {
+		days, ok := parseSnoozeDuration(*input.Duration)
+		if !ok {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"duration": {"Invalid duration. Use a number of days or weeks such as 3d or 2w (up to 365 days)"},
+			})
+		}
+		base := today
+		if todo.DueDate != nil && todo.DueDate.After(today) {
+			base = *todo.DueDate
+		}
+		newDueDate = base.AddDate(0, 0, days)
+		changes["duration"] = *input.Duration
+	}
+
+	if todo.DueDate != nil && !newDueDate.After(*todo.DueDate) {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"due_date": {"Snoozing must move the due date later"},
+		})
+	}
+
+	var oldDueDate interface{}
+	if todo.DueDate != nil {
+		oldDueDate = todo.DueDate.Format("2006-01-02")
+	}
+	if err := s.todoRepo.Snooze(ctx, todo.ID, userID, newDueDate); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Snooze: failed to update todo")
+	}
+
+	// Re-read for the count after the increment
+	snoozed, err := s.todoRepo.FindByIDWithRelations(ctx, todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record history
+	changes["due_date"] = []interface{}{oldDueDate, newDueDate.Format("2006-01-02")}
+	changes["snooze_count"] = snoozed.SnoozeCount
+	if err := s.recordHistory(ctx, todo.ID, userID, model.ActionSnoozed, changes); err != nil {
+		log.Error().Err(err).Msg("TodoService.Snooze: failed to record history")
+	}
+
+	return snoozed, nil
+}
+
+// parseSnoozeDuration returns the number of days of a duration such as "3d" or "2w"
+func parseSnoozeDuration(duration string) (int, bool) {
+	m := snoozeDurationPattern.FindStringSubmatch(duration)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	if m[2] == "w" {
+		n *= 7
+	}
+	if n > maxSnoozeDays {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+)
+
+// WIPStatus reports the user's in-progress todos against their limit
+type WIPStatus struct {
+	InProgress int64
+	// Limit is 0 when no limit is configured
+	Limit    int
+	Mode     model.WIPLimitMode
+	Exceeded bool
+}
+
+// TodoStats holds the number of todos per status and the WIP status
+type TodoStats struct {
+	Total    int64
+	ByStatus map[model.Status]int64
+	WIP      WIPStatus
+}
+
+// Stats returns the number of the user's todos per status and the current WIP against the limit
+func (s *TodoService) Stats(ctx context.Context, userID int64) (*TodoStats, error) {
+	counts, err := s.todoRepo.CountGroupedByStatus(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Stats: failed to count todos")
+	}
+	pref, err := s.prefRepo.FindOrDefault(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.Stats: failed to fetch preferences")
+	}
+
+	stats := &TodoStats{ByStatus: make(map[model.Status]int64, 3)}
+	for _, status := range []model.Status{model.StatusPending, model.StatusInProgress, model.StatusCompleted} {
+		stats.ByStatus[status] = counts[status]
+		stats.Total += counts[status]
+	}
+	stats.WIP = newWIPStatus(pref, counts[model.StatusInProgress])
+	return stats, nil
+}
+
+// WIPWarning returns the WIP status when the user is over a limit configured to warn, otherwise nil.
+// It is meant to be called after a todo was moved to in_progress.
+func (s *TodoService) WIPWarning(ctx context.Context, userID int64) (*WIPStatus, error) {
+	pref, err := s.prefRepo.FindOrDefault(ctx, userID)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.WIPWarning: failed to fetch preferences")
+	}
+	if pref.WIPLimit == 0 || pref.WIPLimitMode != model.WIPLimitModeWarn {
+		return nil, nil
+	}
+
+	count, err := s.todoRepo.CountByStatus(ctx, userID, model.StatusInProgress)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "TodoService.WIPWarning: failed to count todos")
+	}
+	status := newWIPStatus(pref, count)
+	if !status.Exceeded {
+		return nil, nil
+	}
+	return &status, nil
+}
+
+// rejectingWIPLimit returns the user's WIP limit if it is configured to reject, otherwise 0
+func (s *TodoService) rejectingWIPLimit(ctx context.Context, userID int64) (int, error) {
+	pref, err := s.prefRepo.FindOrDefault(ctx, userID)
+	if err != nil {
+		return 0, errors.InternalErrorWithLog(err, "TodoService.rejectingWIPLimit: failed to fetch preferences")
+	}
+	if pref.WIPLimitMode != model.WIPLimitModeReject {
+		return 0, nil
+	}
+	return pref.WIPLimit, nil
+}
+
+// saveWithinWIPLimit creates or updates a todo moving to in_progress, rejecting it when the limit is reached.
+// The limit is checked in the transaction that saves the todo. Callers only call it for todos not yet in progress.
+func (s *TodoService) saveWithinWIPLimit(ctx context.Context, todo *model.Todo, limit int) error {
+	saved, count, err := s.todoRepo.SaveWithinWIPLimit(ctx, todo, limit)
+	if err != nil {
+		return errors.InternalErrorWithLog(err, "TodoService.saveWithinWIPLimit: failed to save todo")
+	}
+	if !saved {
+		return errors.WIPLimitExceeded(limit, count)
+	}
+	return nil
+}
+
+func newWIPStatus(pref *model.UserPreference, inProgress int64) WIPStatus {
+	return WIPStatus{
+		InProgress: inProgress,
+		Limit:      pref.WIPLimit,
+		Mode:       pref.WIPLimitMode,
+		Exceeded:   pref.WIPLimit > 0 && inProgress > int64(pref.WIPLimit),
+	}
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"todo-api/internal/config"
+	"todo-api/internal/constants"
+	"todo-api/internal/errors"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/pkg/util"
+)
+
+// Limits of API usage queries
+const (
+	DefaultUsageDays          = 30
+	MaxUsageDays              = 366
+	UsageTopUsersLimit        = 50
+	UsageTopIntegrationsLimit = 50
+)
+
+// UsageService meters API requests per user, integration and UTC day and enforces daily quotas per user
+type UsageService struct {
+	usageRepo *repository.APIUsageRepository
+	userRepo  *repository.UserRepository
+	config    *config.UsageConfig
+}
+
+// NewUsageService creates a new UsageService
+func NewUsageService(usageRepo *repository.APIUsageRepository, userRepo *repository.UserRepository, cfg *config.UsageConfig) *UsageService {
+	return &UsageService{
+		usageRepo: usageRepo,
+		userRepo:  userRepo,
+		config:    cfg,
+	}
+}
+
+// Quota represents a user's API usage against their daily quota
+type Quota struct {
+	// Limit is the number of requests allowed per UTC day (0: unlimited)
+	Limit int
+	// Used is the number of requests made today
+	Used int64
+	// ResetAt is when the count restarts (the next UTC midnight)
+	ResetAt time.Time
+}
+
+// Limited reports whether a daily quota applies
+func (q Quota) Limited() bool {
+	return q.Limit > 0
+}
+
+// Remaining returns the number of requests left today
+func (q Quota) Remaining() int64 {
+	if remaining := int64(q.Limit) - q.Used; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Exceeded reports whether the requests made today are over the quota
+func (q Quota) Exceeded() bool {
+	return q.Limited() && q.Used > int64(q.Limit)
+}
+
+// UserUsage represents a user's quota and daily request counts
+type UserUsage struct {
+	Quota Quota
+	Days  []DailyUsage
+}
+
+// DailyUsage represents a user's requests during one UTC day
+type DailyUsage struct {
+	Date     string
+	Requests int64
+	// ByIntegration splits Requests by integration, starting with the requests made with a user token
+	// (nil IntegrationID)
+	ByIntegration []model.APIUsage
+}
+
+// UsageRollup represents the API usage of all users over a period
+type UsageRollup struct {
+	From            string
+	To              string
+	TotalRequests   int64
+	Days            []repository.DailyAPIUsageTotal
+	TopUsers        []repository.UserAPIUsageTotal
+	TopIntegrations []repository.IntegrationAPIUsageTotal
+}
+
+// DailyQuota returns the daily quota of a user given their override (0: unlimited)
+func (s *UsageService) DailyQuota(override *int) int {
+	if override != nil {
+		return *override
+	}
+	return s.config.DailyQuota
+}
+
+// Record counts a request of the user, made through the integration if not nil, and returns their quota after it.
+// The quota covers the requests of the user's integrations too.
+// Requests over the quota are counted too, so retrying does not help until the reset.
+func (s *UsageService) Record(ctx context.Context, userID int64, integrationID *int64, override *int) (*Quota, error) {
+	today := s.today()
+	used, err := s.usageRepo.Increment(ctx, userID, integrationID, today.Format(constants.DateFormat))
+	if err != nil {
+		return nil, err
+	}
+	return &Quota{
+		Limit:   s.DailyQuota(override),
+		Used:    used,
+		ResetAt: today.AddDate(0, 0, 1),
+	}, nil
+}
+
+// Usage returns the user's quota and their request counts for the last days (including today)
+func (s *UsageService) Usage(ctx context.Context, userID int64, override *int, days int) (*UserUsage, error) {
+	if days < 1 || days > MaxUsageDays {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"days": {"Must be between 1 and 366"},
+		})
+	}
+
+	today := s.today()
+	usages, err := s.usageRepo.FindByUserID(ctx, userID, today.AddDate(0, 0, 1-days).Format(constants.DateFormat), today.Format(constants.DateFormat))
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "UsageService.Usage: failed to fetch usage")
+	}
+
+	// One record per day and integration, grouped by day
+	var daily []DailyUsage
+	for _, usage := range usages {
+		if len(daily) == 0 || daily[len(daily)-1].Date != usage.Date {
+			daily = append(daily, DailyUsage{Date: usage.Date})
+		}
+		day := &daily[len(daily)-1]
+		day.Requests += usage.RequestCount
+		day.ByIntegration = append(day.ByIntegration, usage)
+	}
+	for _, day := range daily {
+		slices.SortFunc(day.ByIntegration, func(a, b model.APIUsage) int {
+			return cmp.Compare(util.DerefInt64(a.IntegrationID, 0), util.DerefInt64(b.IntegrationID, 0))
+		})
+	}
+
+	quota := Quota{Limit: s.DailyQuota(override), ResetAt: today.AddDate(0, 0, 1)}
+	if len(daily) > 0 && daily[len(daily)-1].Date == today.Format(constants.DateFormat) {
+		quota.Used = daily[len(daily)-1].Requests
+	}
+	return &UserUsage{Quota: quota, Days: daily}, nil
+}
+
+// Rollup aggregates the usage of all users between two days (YYYY-MM-DD, inclusive).
+// Empty bounds default to the last DefaultUsageDays days.
+func (s *UsageService) Rollup(ctx context.Context, fromStr, toStr string) (*UsageRollup, error) {
+	to := s.today()
+	from := to.AddDate(0, 0, 1-DefaultUsageDays)
+
+	if fromStr != "" {
+		parsed, err := time.ParseInLocation(constants.DateFormat, fromStr, time.UTC)
+		if err != nil {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"from": {"Invalid date format. Use YYYY-MM-DD"},
+			})
+		}
+		from = parsed
+	}
+	if toStr != "" {
+		parsed, err := time.ParseInLocation(constants.DateFormat, toStr, time.UTC)
+		if err != nil {
+			return nil, errors.ValidationFailed(map[string][]string{
+				"to": {"Invalid date format. Use YYYY-MM-DD"},
+			})
+		}
+		to = parsed
+	}
+
+	if to.Before(from) {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"to": {"Must be on or after from"},
+		})
+	}
+	if to.Sub(from) >= MaxUsageDays*24*time.Hour {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"from": {"Date range must not exceed 366 days"},
+		})
+	}
+
+	rollup := &UsageRollup{From: from.Format(constants.DateFormat), To: to.Format(constants.DateFormat)}
+	days, err := s.usageRepo.DailyTotals(ctx, rollup.From, rollup.To)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "UsageService.Rollup: failed to aggregate usage per day")
+	}
+	topUsers, err := s.usageRepo.TopUsers(ctx, rollup.From, rollup.To, UsageTopUsersLimit)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "UsageService.Rollup: failed to aggregate usage per user")
+	}
+	topIntegrations, err := s.usageRepo.TopIntegrations(ctx, rollup.From, rollup.To, UsageTopIntegrationsLimit)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "UsageService.Rollup: failed to aggregate usage per integration")
+	}
+
+	rollup.Days = days
+	rollup.TopUsers = topUsers
+	rollup.TopIntegrations = topIntegrations
+	for _, day := range days {
+		rollup.TotalRequests += day.Requests
+	}
+	return rollup, nil
+}
+
+// SetDailyQuota overrides the daily quota of a user; nil restores API_DAILY_QUOTA
+func (s *UsageService) SetDailyQuota(ctx context.Context, userID int64, quota *int) (*model.User, error) {
+	if quota != nil && *quota < 0 {
+		return nil, errors.ValidationFailed(map[string][]string{
+			"daily_quota": {"Must be greater than or equal to 0"},
+		})
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("User", userID)
+		}
+		return nil, errors.InternalErrorWithLog(err, "UsageService.SetDailyQuota: failed to fetch user")
+	}
+	if err := s.userRepo.UpdateAPIDailyQuota(ctx, userID, quota); err != nil {
+		return nil, errors.InternalErrorWithLog(err, "UsageService.SetDailyQuota: failed to update quota")
+	}
+	user.APIDailyQuota = quota
+	return user, nil
+}
+
+// today returns the start of the current UTC day
+func (s *UsageService) today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,172 @@
+package suggest
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// maxSubtasks limits the number of subtasks any provider may return
+const maxSubtasks = 10
+
+var (
+	highPriorityKeywords = []string{"urgent", "asap", "critical", "immediately", "至急", "緊急", "重要", "急ぎ"}
+	lowPriorityKeywords  = []string{"someday", "maybe", "nice to have", "eventually", "いつか", "余裕があれば"}
+
+	// dueKeywords maps phrases to a due date offset in days, checked in order
+	dueKeywords = []struct {
+		keywords []string
+		days     int
+	}{
+		{[]string{"today", "tonight", "今日", "本日"}, 0},
+		{[]string{"tomorrow", "明日"}, 1},
+		{[]string{"next week", "来週"}, 7},
+		{[]string{"this week", "今週"}, 3},
+		{[]string{"next month", "来月"}, 30},
+	}
+
+	subtaskPrefixes = []string{"- [ ] ", "- ", "* ", "・", "□"}
+)
+
+// HeuristicProvider suggests attributes with keyword rules and the user's history.
+// It needs no external service and is the default provider.
+type HeuristicProvider struct{}
+
+// NewHeuristicProvider creates a new HeuristicProvider
+func NewHeuristicProvider() *HeuristicProvider {
+	return &HeuristicProvider{}
+}
+
+// Name returns the provider identifier
+func (p *HeuristicProvider) Name() string {
+	return ProviderHeuristic
+}
+
+// Suggest proposes attributes for the draft todo
+func (p *HeuristicProvider) Suggest(ctx context.Context, req *Request) (*Suggestion, error) {
+	text := strings.ToLower(req.Title + "\n" + req.Description)
+	similar := similarHistory(req)
+
+	suggestion := &Suggestion{
+		Priority: keywordPriority(text),
+		Category: mentionedCategory(text, req.Categories),
+		Subtasks: listItems(req.Description),
+	}
+
+	for _, due := range dueKeywords {
+		if containsAny(text, due.keywords) {
+			days := due.days
+			suggestion.DueInDays = &days
+			break
+		}
+	}
+
+	if suggestion.Priority == "" {
+		suggestion.Priority = mostCommon(similar, func(h HistoryItem) string { return h.Priority })
+	}
+	if suggestion.Category == "" {
+		suggestion.Category = mostCommon(similar, func(h HistoryItem) string { return h.Category })
+	}
+
+	return suggestion, nil
+}
+
+// keywordPriority returns a priority implied by urgency words in the text
+func keywordPriority(text string) string {
+	switch {
+	case containsAny(text, highPriorityKeywords):
+		return "high"
+	case containsAny(text, lowPriorityKeywords):
+		return "low"
+	default:
+		return ""
+	}
+}
+
+// mentionedCategory returns the first category whose name appears in the text
+func mentionedCategory(text string, categories []string) string {
+	for _, name := range categories {
+		if name != "" && strings.Contains(text, strings.ToLower(name)) {
+			return name
+		}
+	}
+	return ""
+}
+
+// listItems extracts bullet or checklist lines from the description as subtasks
+func listItems(description string) []string {
+	var items []string
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range subtaskPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				if item := strings.TrimSpace(strings.TrimPrefix(line, prefix)); item != "" {
+					items = append(items, item)
+				}
+				break
+			}
+		}
+		if len(items) == maxSubtasks {
+			break
+		}
+	}
+	return items
+}
+
+// similarHistory returns history items sharing at least one word with the draft title
+func similarHistory(req *Request) []HistoryItem {
+	words := tokenize(req.Title)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var similar []HistoryItem
+	for _, item := range req.History {
+		for w := range tokenize(item.Title) {
+			if words[w] {
+				similar = append(similar, item)
+				break
+			}
+		}
+	}
+	return similar
+}
+
+// tokenize splits text into lowercase words of at least two characters
+func tokenize(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if len([]rune(w)) >= 2 {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// mostCommon returns the most frequent non-empty value among the items
+func mostCommon(items []HistoryItem, value func(HistoryItem) string) string {
+	counts := make(map[string]int)
+	best, bestCount := "", 0
+	for _, item := range items {
+		v := value(item)
+		if v == "" {
+			continue
+		}
+		counts[v]++
+		if counts[v] > bestCount {
+			best, bestCount = v, counts[v]
+		}
+	}
+	return best
+}
+
+func containsAny(text string, keywords []string) bool {
+	for _, k := range keywords {
+		if strings.Contains(text, k) {
+			return true
+		}
+	}
+	return false
+}
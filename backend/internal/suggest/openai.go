@@ -0,0 +1,172 @@
+package suggest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	appconfig "todo-api/internal/config"
+)
+
+// openAISystemPrompt instructs the model to answer with a fixed JSON shape
+const openAISystemPrompt = `You help users fill in details for a new todo item.
+Respond with a single JSON object with these keys:
+"priority": one of "low", "medium", "high";
+"due_in_days": integer number of days from today, or null if no deadline is implied;
+"category": one of the user's category names exactly as given, or "" if none fits;
+"subtasks": array of at most 10 short, actionable subtask titles in the same language as the todo.
+Use the user's recent todos to match their usual priorities and categories.`
+
+// OpenAIProvider requests suggestions from an OpenAI-compatible chat completions API
+type OpenAIProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+// NewOpenAIProvider creates a new OpenAIProvider
+func NewOpenAIProvider(cfg *appconfig.AIConfig) *OpenAIProvider {
+	return &OpenAIProvider{
+		client:  &http.Client{Timeout: cfg.Timeout},
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:  cfg.APIKey,
+		model:   cfg.Model,
+	}
+}
+
+// Name returns the provider identifier
+func (p *OpenAIProvider) Name() string {
+	return ProviderOpenAI
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string            `json:"model"`
+	Messages       []chatMessage     `json:"messages"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+type suggestionPayload struct {
+	Priority  string   `json:"priority"`
+	DueInDays *int     `json:"due_in_days"`
+	Category  string   `json:"category"`
+	Subtasks  []string `json:"subtasks"`
+}
+
+// Suggest proposes attributes for the draft todo
+func (p *OpenAIProvider) Suggest(ctx context.Context, req *Request) (*Suggestion, error) {
+	userPrompt, err := buildUserPrompt(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(chatRequest{
+		Model: p.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: openAISystemPrompt},
+			{Role: "user", Content: userPrompt},
+		},
+		Temperature:    0.2,
+		ResponseFormat: map[string]string{"type": "json_object"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, truncate(string(respBody), 200))
+	}
+
+	var chat chatResponse
+	if err := json.Unmarshal(respBody, &chat); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(chat.Choices) == 0 {
+		return nil, fmt.Errorf("response contained no choices")
+	}
+
+	var payload suggestionPayload
+	if err := json.Unmarshal([]byte(chat.Choices[0].Message.Content), &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode suggestion: %w", err)
+	}
+
+	return &Suggestion{
+		Priority:  payload.Priority,
+		DueInDays: payload.DueInDays,
+		Category:  payload.Category,
+		Subtasks:  payload.Subtasks,
+	}, nil
+}
+
+// buildUserPrompt serializes the draft and the user's context as JSON for the model
+func buildUserPrompt(req *Request) (string, error) {
+	type historyEntry struct {
+		Title     string `json:"title"`
+		Priority  string `json:"priority"`
+		Category  string `json:"category,omitempty"`
+		DueInDays *int   `json:"due_in_days,omitempty"`
+	}
+
+	history := make([]historyEntry, len(req.History))
+	for i, h := range req.History {
+		history[i] = historyEntry{
+			Title:     h.Title,
+			Priority:  h.Priority,
+			Category:  h.Category,
+			DueInDays: h.DueInDays,
+		}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"todo": map[string]string{
+			"title":       req.Title,
+			"description": req.Description,
+		},
+		"categories":   req.Categories,
+		"recent_todos": history,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode prompt: %w", err)
+	}
+	return string(data), nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
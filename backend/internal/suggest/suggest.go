@@ -0,0 +1,63 @@
+package suggest
+
+import (
+	"context"
+	"fmt"
+
+	appconfig "todo-api/internal/config"
+)
+
+// Provider names accepted by AI_PROVIDER
+const (
+	ProviderHeuristic = "heuristic"
+	ProviderOpenAI    = "openai"
+)
+
+// HistoryItem summarizes one of the user's existing todos for context.
+// DueInDays is the number of days between creation and the due date.
+type HistoryItem struct {
+	Title     string
+	Priority  string
+	Category  string
+	DueInDays *int
+}
+
+// Request contains the draft todo and the user's context
+type Request struct {
+	Title       string
+	Description string
+	Categories  []string
+	History     []HistoryItem
+}
+
+// Suggestion is a provider's proposal for a draft todo.
+// Empty fields mean the provider has no suggestion.
+type Suggestion struct {
+	Priority  string
+	DueInDays *int
+	Category  string
+	Subtasks  []string
+}
+
+// Provider defines the interface for suggestion backends
+type Provider interface {
+	// Name returns the provider identifier
+	Name() string
+	// Suggest proposes attributes for the draft todo
+	Suggest(ctx context.Context, req *Request) (*Suggestion, error)
+}
+
+// New returns the provider selected by configuration
+func New(cfg *appconfig.AIConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "", ProviderHeuristic:
+		return NewHeuristicProvider(), nil
+	case ProviderOpenAI:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("AI_API_KEY is required for provider %q", cfg.Provider)
+		}
+		return NewOpenAIProvider(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q", cfg.Provider)
+	}
+}
@@ -0,0 +1,40 @@
+package tenant
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/pkg/database"
+)
+
+// Migrate creates or updates the tables of a tenant schema.
+// db must be connected to the schema (see database.ConnectSchema); encrypted tells whether field encryption is enabled
+// and rls whether row-level security is.
+func Migrate(ctx context.Context, db *gorm.DB, encrypted, rls bool) error {
+	if err := db.AutoMigrate(model.All()...); err != nil {
+		return err
+	}
+	if err := repository.NewAPIUsageRepository(db).DropUserDateIndex(ctx); err != nil {
+		return err
+	}
+	if err := database.EnableTrigramSearch(db, encrypted); err != nil {
+		return err
+	}
+	// Before the backfills, which the policies of an earlier run would otherwise keep from seeing any rows
+	if rls {
+		if err := database.EnableRowLevelSecurity(db); err != nil {
+			return err
+		}
+	}
+	todoRepo := repository.NewTodoRepository(db)
+	if err := todoRepo.BackfillCategoryPositions(ctx); err != nil {
+		return err
+	}
+	if err := todoRepo.BackfillCompletedAt(ctx); err != nil {
+		return err
+	}
+	return repository.NewStreakRepository(db).BackfillCompletions(ctx)
+}
@@ -0,0 +1,257 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+
+	"todo-api/internal/config"
+	"todo-api/internal/errors"
+	"todo-api/internal/job"
+	"todo-api/internal/model"
+	"todo-api/internal/repository"
+	"todo-api/pkg/database"
+)
+
+// BuildFunc builds the API of a tenant from its configuration and schema connection.
+// The returned scheduler, if any, is started by the registry.
+type BuildFunc func(cfg *config.Config, db *gorm.DB) (http.Handler, *job.Scheduler, error)
+
+// app is the API of a loaded tenant
+type app struct {
+	handler   http.Handler
+	db        *gorm.DB
+	scheduler *job.Scheduler
+}
+
+// entry holds the API of a tenant once built. Its lock is held while building,
+// so only requests to the same tenant wait for a slow tenant to load.
+type entry struct {
+	mu      sync.Mutex
+	app     *app
+	removed bool
+}
+
+// Registry creates tenants and serves each one with an API of its own, connected to its schema.
+// Tenant APIs are built on first use and kept until Close.
+type Registry struct {
+	cfg        *config.Config
+	db         *gorm.DB
+	tenantRepo *repository.TenantRepository
+	build      BuildFunc
+
+	mu   sync.Mutex
+	apps map[string]*entry
+}
+
+// NewRegistry creates a new Registry. db is the connection to the public schema holding the tenants;
+// build may be nil when the registry only manages schemas.
+func NewRegistry(cfg *config.Config, db *gorm.DB, build BuildFunc) *Registry {
+	return &Registry{
+		cfg:        cfg,
+		db:         db,
+		tenantRepo: repository.NewTenantRepository(db),
+		build:      build,
+		apps:       map[string]*entry{},
+	}
+}
+
+// Tenants retrieves all tenants
+func (r *Registry) Tenants(ctx context.Context) ([]model.Tenant, error) {
+	return r.tenantRepo.FindAll(ctx)
+}
+
+// Create creates a tenant, its schema, and its tables
+func (r *Registry) Create(ctx context.Context, slug, name string) (*model.Tenant, error) {
+	if !ValidSlug(slug) {
+		return nil, fmt.Errorf("invalid tenant slug %q: use lowercase letters, digits, and hyphens", slug)
+	}
+	if _, err := r.tenantRepo.FindBySlug(ctx, slug); err == nil {
+		return nil, fmt.Errorf("tenant %s already exists", slug)
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if err := r.migrate(ctx, slug); err != nil {
+		return nil, err
+	}
+	tenant := &model.Tenant{Slug: slug, Name: name}
+	if err := r.tenantRepo.Create(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// Migrate creates or updates the tables in the schema of a tenant
+func (r *Registry) Migrate(ctx context.Context, slug string) error {
+	if _, err := r.tenantRepo.FindBySlug(ctx, slug); err != nil {
+		return fmt.Errorf("failed to find tenant %s: %w", slug, err)
+	}
+	return r.migrate(ctx, slug)
+}
+
+// MigrateAll migrates the schemas of all tenants
+func (r *Registry) MigrateAll(ctx context.Context) error {
+	tenants, err := r.tenantRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		if err := r.migrate(ctx, t.Slug); err != nil {
+			return err
+		}
+		log.Info().Str("tenant", t.Slug).Msg("Tenant schema migrated")
+	}
+	return nil
+}
+
+// migrate creates the schema of a tenant if needed and migrates its tables.
+// The schema must exist before connecting, or PostgreSQL would skip it in the search path
+// and the tables would be created in public.
+func (r *Registry) migrate(ctx context.Context, slug string) error {
+	if err := database.CreateSchema(r.db, Schema(slug)); err != nil {
+		return fmt.Errorf("failed to create schema of tenant %s: %w", slug, err)
+	}
+
+	db, err := r.connect(slug)
+	if err != nil {
+		return err
+	}
+	defer database.Close(db)
+
+	if err := Migrate(ctx, db, r.cfg.GetEncryptionConfig().Keys != "", r.cfg.DatabaseRLSEnabled); err != nil {
+		return fmt.Errorf("failed to migrate tenant %s: %w", slug, err)
+	}
+	return nil
+}
+
+// LoadAll builds the APIs of all tenants, so their background jobs run without waiting for a request
+func (r *Registry) LoadAll(ctx context.Context) error {
+	tenants, err := r.tenantRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		if _, err := r.app(ctx, t.Slug); err != nil {
+			return err
+		}
+	}
+	log.Info().Int("tenants", len(tenants)).Msg("Tenants loaded")
+	return nil
+}
+
+// Handle serves a request with the API of the tenant resolved by the ResolveTenant middleware
+func (r *Registry) Handle(c echo.Context) error {
+	slug, ok := FromContext(c.Request().Context())
+	if !ok {
+		return errors.ParameterMissing("tenant")
+	}
+	a, err := r.app(c.Request().Context(), slug)
+	if err != nil {
+		return err
+	}
+	a.handler.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// Close stops the background jobs of all tenants and closes their connections
+func (r *Registry) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for slug, e := range r.apps {
+		// Wait for a tenant being built
+		e.mu.Lock()
+		a := e.app
+		e.app, e.removed = nil, true
+		e.mu.Unlock()
+		if a == nil {
+			continue
+		}
+		if a.scheduler != nil {
+			a.scheduler.Stop()
+		}
+		if err := database.Close(a.db); err != nil {
+			log.Error().Err(err).Str("tenant", slug).Msg("Failed to close tenant database")
+		}
+	}
+	r.apps = map[string]*entry{}
+}
+
+// app returns the API of a tenant, building it on first use.
+// The registry lock is only held to find the tenant's entry; building holds the entry's lock.
+func (r *Registry) app(ctx context.Context, slug string) (*app, error) {
+	for {
+		r.mu.Lock()
+		e, ok := r.apps[slug]
+		if !ok {
+			e = &entry{}
+			r.apps[slug] = e
+		}
+		r.mu.Unlock()
+
+		e.mu.Lock()
+		if e.removed {
+			// The build we waited for failed; retry with a new entry
+			e.mu.Unlock()
+			continue
+		}
+		if e.app != nil {
+			e.mu.Unlock()
+			return e.app, nil
+		}
+
+		a, err := r.load(ctx, slug)
+		if err != nil {
+			// Forget the entry so unknown slugs don't accumulate
+			e.removed = true
+			r.mu.Lock()
+			if r.apps[slug] == e {
+				delete(r.apps, slug)
+			}
+			r.mu.Unlock()
+			e.mu.Unlock()
+			return nil, err
+		}
+		e.app = a
+		e.mu.Unlock()
+		return a, nil
+	}
+}
+
+// load connects to the schema of a tenant and builds its API
+func (r *Registry) load(ctx context.Context, slug string) (*app, error) {
+	if _, err := r.tenantRepo.FindBySlug(ctx, slug); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NotFound("Tenant", slug)
+		}
+		return nil, errors.InternalErrorWithLog(err, "Registry.app: failed to find tenant")
+	}
+
+	db, err := r.connect(slug)
+	if err != nil {
+		return nil, errors.InternalErrorWithLog(err, "Registry.app: failed to connect to tenant schema")
+	}
+	handler, scheduler, err := r.build(r.cfg.ForTenant(slug), db)
+	if err != nil {
+		database.Close(db)
+		return nil, errors.InternalErrorWithLog(err, "Registry.app: failed to build tenant API")
+	}
+	if scheduler != nil && r.cfg.SchedulerEnabled {
+		scheduler.Start(context.Background())
+	}
+
+	a := &app{handler: handler, db: db, scheduler: scheduler}
+	log.Info().Str("tenant", slug).Msg("Tenant loaded")
+	return a, nil
+}
+
+// connect opens a connection to the schema of a tenant
+func (r *Registry) connect(slug string) (*gorm.DB, error) {
+	return database.ConnectSchema(r.cfg.DatabaseURL, Schema(slug), r.cfg.GetTenancyConfig().MaxOpenConns)
+}
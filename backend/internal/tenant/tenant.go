@@ -0,0 +1,70 @@
+package tenant
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// slugPattern matches tenant slugs: lowercase letters, digits, and inner hyphens, usable as a subdomain
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,38}[a-z0-9])?$`)
+
+// ValidSlug reports whether slug can identify a tenant
+func ValidSlug(slug string) bool {
+	return slugPattern.MatchString(slug)
+}
+
+// Schema returns the name of the database schema of a tenant
+func Schema(slug string) string {
+	return "tenant_" + strings.ReplaceAll(slug, "-", "_")
+}
+
+// FromHost returns the tenant slug of a host that is a direct subdomain of baseDomain,
+// e.g. "acme" for "acme.todo.example.com:443" with the base domain "todo.example.com"
+func FromHost(host, baseDomain string) (string, bool) {
+	if baseDomain == "" {
+		return "", false
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	slug, ok := strings.CutSuffix(strings.ToLower(host), "."+baseDomain)
+	if !ok || !ValidSlug(slug) {
+		return "", false
+	}
+	return slug, true
+}
+
+// TokenWithSlug prefixes a token handed out through a URL shared by all tenants (an export download link,
+// an OAuth state) with the tenant slug, so the request bringing it back can be routed to the tenant without
+// a subdomain or a bearer token. The prefixed token is what is stored and checked. An empty slug leaves it as is.
+func TokenWithSlug(slug, token string) string {
+	if slug == "" {
+		return token
+	}
+	return slug + "." + token
+}
+
+// SlugFromToken returns the tenant slug of a token prefixed by TokenWithSlug
+func SlugFromToken(token string) (string, bool) {
+	slug, _, ok := strings.Cut(token, ".")
+	if !ok || !ValidSlug(slug) {
+		return "", false
+	}
+	return slug, true
+}
+
+// slugKey is the context key of the tenant slug
+type slugKey struct{}
+
+// WithSlug returns a context of a request to the tenant
+func WithSlug(ctx context.Context, slug string) context.Context {
+	return context.WithValue(ctx, slugKey{}, slug)
+}
+
+// FromContext returns the tenant slug of a request context
+func FromContext(ctx context.Context) (string, bool) {
+	slug, ok := ctx.Value(slugKey{}).(string)
+	return slug, ok
+}
@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,34 +13,54 @@ import (
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
+	"todo-api/internal/config"
 	"todo-api/internal/handler"
+	"todo-api/internal/mailer"
 	"todo-api/internal/middleware"
 	"todo-api/internal/model"
 	"todo-api/internal/repository"
+	"todo-api/internal/search"
 	"todo-api/internal/service"
+	"todo-api/internal/suggest"
 )
 
 // TestFixture holds all dependencies needed for handler tests
 type TestFixture struct {
-	T                *testing.T
-	DB               *gorm.DB
-	Echo             *echo.Echo
-	UserRepo         *repository.UserRepository
-	DenylistRepo     *repository.JwtDenylistRepository
-	TodoRepo         *repository.TodoRepository
-	CategoryRepo     *repository.CategoryRepository
-	TagRepo          *repository.TagRepository
-	CommentRepo      *repository.CommentRepository
-	HistoryRepo      *repository.TodoHistoryRepository
-	NoteRepo         *repository.NoteRepository
-	NoteRevisionRepo *repository.NoteRevisionRepository
-	AuthHandler      *handler.AuthHandler
-	TodoHandler      *handler.TodoHandler
-	CategoryHandler  *handler.CategoryHandler
-	TagHandler       *handler.TagHandler
-	CommentHandler   *handler.CommentHandler
-	HistoryHandler   *handler.TodoHistoryHandler
-	NoteHandler      *handler.NoteHandler
+	T                 *testing.T
+	DB                *gorm.DB
+	Echo              *echo.Echo
+	UserRepo          *repository.UserRepository
+	DenylistRepo      *repository.JwtDenylistRepository
+	TodoRepo          *repository.TodoRepository
+	CategoryRepo      *repository.CategoryRepository
+	TagRepo           *repository.TagRepository
+	CommentRepo       *repository.CommentRepository
+	HistoryRepo       *repository.TodoHistoryRepository
+	NoteRepo          *repository.NoteRepository
+	NoteRevisionRepo  *repository.NoteRevisionRepository
+	PreferenceRepo    *repository.UserPreferenceRepository
+	FocusSessionRepo  *repository.FocusSessionRepository
+	StreakRepo        *repository.StreakRepository
+	DataExportRepo    *repository.DataExportRepository
+	EscalationRepo    *repository.EscalationRuleRepository
+	AuthHandler       *handler.AuthHandler
+	TodoHandler       *handler.TodoHandler
+	CategoryHandler   *handler.CategoryHandler
+	TagHandler        *handler.TagHandler
+	CommentHandler    *handler.CommentHandler
+	HistoryHandler    *handler.TodoHistoryHandler
+	NoteHandler       *handler.NoteHandler
+	PreferenceHandler *handler.PreferenceHandler
+	FocusHandler      *handler.FocusSessionHandler
+	StreakHandler     *handler.StreakHandler
+	SuggestionHandler *handler.SuggestionHandler
+	DataExportHandler *handler.DataExportHandler
+	RetentionHandler  *handler.RetentionHandler
+	SearchHandler     *handler.SearchHandler
+	EscalationHandler *handler.EscalationHandler
+	MyDayHandler      *handler.MyDayHandler
+	TodoLinkHandler   *handler.TodoLinkHandler
+	SyncHandler       *handler.SyncHandler
 }
 
 // SetupTestFixture creates a new TestFixture with all dependencies initialized
@@ -56,44 +77,98 @@ func SetupTestFixture(t *testing.T) *TestFixture {
 	historyRepo := repository.NewTodoHistoryRepository(db)
 	noteRepo := repository.NewNoteRepository(db)
 	noteRevisionRepo := repository.NewNoteRevisionRepository(db)
+	preferenceRepo := repository.NewUserPreferenceRepository(db)
+	focusSessionRepo := repository.NewFocusSessionRepository(db)
+	streakRepo := repository.NewStreakRepository(db)
+	dataExportRepo := repository.NewDataExportRepository(db)
+	escalationRepo := repository.NewEscalationRuleRepository(db)
+	myDayRepo := repository.NewMyDayRepository(db)
+	todoLinkRepo := repository.NewTodoLinkRepository(db)
+	consentRepo := repository.NewPolicyConsentRepository(db)
+	syncRepo := repository.NewSyncRepository(db)
 
 	// Initialize services
-	todoService := service.NewTodoService(todoRepo, categoryRepo, historyRepo)
+	streakService := service.NewStreakService(streakRepo, preferenceRepo)
+	todoService := service.NewTodoService(todoRepo, categoryRepo, historyRepo, preferenceRepo, streakService, &config.SearchConfig{FuzzyThreshold: 0.3})
 	noteService := service.NewNoteService(noteRepo, noteRevisionRepo)
+	preferenceService := service.NewPreferenceService(preferenceRepo)
+	focusService := service.NewFocusService(focusSessionRepo, todoRepo, preferenceRepo)
+	suggestionService := service.NewSuggestionService(suggest.NewHeuristicProvider(), todoRepo, categoryRepo, preferenceRepo)
+	escalationService := service.NewEscalationService(escalationRepo, preferenceRepo, todoRepo, historyRepo, mailer.NewLogMailer())
+	myDayService := service.NewMyDayService(myDayRepo, todoRepo, preferenceRepo)
+	todoLinkService := service.NewTodoLinkService(todoLinkRepo, todoRepo)
+	searchService := service.NewSearchService(search.NewSQLIndex(repository.NewSearchRepository(db)))
+	retentionService := service.NewRetentionService(preferenceRepo, todoRepo, noteRepo)
+	syncService := service.NewSyncService(syncRepo, todoService)
+	// Storage is not available in tests; only request and list paths are exercised
+	dataExportService := service.NewDataExportService(
+		dataExportRepo, userRepo, preferenceRepo, todoRepo, categoryRepo, tagRepo,
+		commentRepo, historyRepo, noteRepo, nil, focusSessionRepo, streakRepo, consentRepo, todoLinkRepo,
+		myDayRepo, escalationRepo, repository.NewIntegrationRepository(db), repository.NewCalendarRepository(db),
+		nil, mailer.NewLogMailer(),
+		&config.DataExportConfig{PublicAPIURL: "http://localhost:3000", LinkTTL: 24 * time.Hour},
+	)
 
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(userRepo, denylistRepo, TestConfig)
-	todoHandler := handler.NewTodoHandler(todoService, todoRepo)
+	authHandler := handler.NewAuthHandler(userRepo, denylistRepo, consentRepo, TestConfig)
+	todoHandler := handler.NewTodoHandler(todoService, todoRepo, todoLinkService)
 	categoryHandler := handler.NewCategoryHandler(categoryRepo)
 	tagHandler := handler.NewTagHandler(tagRepo)
 	commentHandler := handler.NewCommentHandler(commentRepo, todoRepo)
 	historyHandler := handler.NewTodoHistoryHandler(historyRepo, todoRepo)
 	noteHandler := handler.NewNoteHandler(noteService, noteRepo, noteRevisionRepo)
+	preferenceHandler := handler.NewPreferenceHandler(preferenceService)
+	focusHandler := handler.NewFocusSessionHandler(focusService)
+	streakHandler := handler.NewStreakHandler(streakService)
+	suggestionHandler := handler.NewSuggestionHandler(suggestionService)
+	dataExportHandler := handler.NewDataExportHandler(dataExportService)
+	retentionHandler := handler.NewRetentionHandler(retentionService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	escalationHandler := handler.NewEscalationHandler(escalationService)
+	myDayHandler := handler.NewMyDayHandler(myDayService)
+	todoLinkHandler := handler.NewTodoLinkHandler(todoLinkService)
+	syncHandler := handler.NewSyncHandler(syncService)
 
 	t.Cleanup(func() {
 		CleanupTestDB(db)
 	})
 
 	return &TestFixture{
-		T:                t,
-		DB:               db,
-		Echo:             e,
-		UserRepo:         userRepo,
-		DenylistRepo:     denylistRepo,
-		TodoRepo:         todoRepo,
-		CategoryRepo:     categoryRepo,
-		TagRepo:          tagRepo,
-		CommentRepo:      commentRepo,
-		HistoryRepo:      historyRepo,
-		NoteRepo:         noteRepo,
-		NoteRevisionRepo: noteRevisionRepo,
-		AuthHandler:      authHandler,
-		TodoHandler:      todoHandler,
-		CategoryHandler:  categoryHandler,
-		TagHandler:       tagHandler,
-		CommentHandler:   commentHandler,
-		HistoryHandler:   historyHandler,
-		NoteHandler:      noteHandler,
+		T:                 t,
+		DB:                db,
+		Echo:              e,
+		UserRepo:          userRepo,
+		DenylistRepo:      denylistRepo,
+		TodoRepo:          todoRepo,
+		CategoryRepo:      categoryRepo,
+		TagRepo:           tagRepo,
+		CommentRepo:       commentRepo,
+		HistoryRepo:       historyRepo,
+		NoteRepo:          noteRepo,
+		NoteRevisionRepo:  noteRevisionRepo,
+		PreferenceRepo:    preferenceRepo,
+		FocusSessionRepo:  focusSessionRepo,
+		StreakRepo:        streakRepo,
+		DataExportRepo:    dataExportRepo,
+		EscalationRepo:    escalationRepo,
+		AuthHandler:       authHandler,
+		TodoHandler:       todoHandler,
+		CategoryHandler:   categoryHandler,
+		TagHandler:        tagHandler,
+		CommentHandler:    commentHandler,
+		HistoryHandler:    historyHandler,
+		NoteHandler:       noteHandler,
+		PreferenceHandler: preferenceHandler,
+		FocusHandler:      focusHandler,
+		StreakHandler:     streakHandler,
+		SuggestionHandler: suggestionHandler,
+		DataExportHandler: dataExportHandler,
+		RetentionHandler:  retentionHandler,
+		SearchHandler:     searchHandler,
+		EscalationHandler: escalationHandler,
+		MyDayHandler:      myDayHandler,
+		TodoLinkHandler:   todoLinkHandler,
+		SyncHandler:       syncHandler,
 	}
 }
 
@@ -112,7 +187,7 @@ func (f *TestFixture) CreateUser(email string) (*model.User, string) {
 	token := rec.Header().Get("Authorization")
 	require.NotEmpty(f.T, token)
 
-	user, err := f.UserRepo.FindByEmail(email)
+	user, err := f.UserRepo.FindByEmail(context.Background(), email)
 	require.NoError(f.T, err)
 
 	return user, token
@@ -140,7 +215,7 @@ func (f *TestFixture) CreateTodoWithPosition(userID int64, title string, positio
 }
 
 // CallAuthGeneric calls a handler with JWT authentication middleware
-// This is the unified method for all resource types (todos, categories, tags, comments, histories)
+// This is the unified method for all resource types (todos, categories, tags, comments, links, histories)
 func (f *TestFixture) CallAuthGeneric(token, method, path, body string, handlerFunc echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
 	var req *http.Request
 	if body != "" {
@@ -155,8 +230,8 @@ func (f *TestFixture) CallAuthGeneric(token, method, path, body string, handlerF
 	c := f.Echo.NewContext(req, rec)
 
 	// Extract path params for nested routes
-	// Pattern: /api/v1/todos/:todo_id/comments/:id or /api/v1/todos/:todo_id/histories
-	if strings.Contains(path, "/todos/") && (strings.Contains(path, "/comments") || strings.Contains(path, "/histories")) {
+	// Pattern: /api/v1/todos/:todo_id/{comments,links}/:id or /api/v1/todos/:todo_id/histories
+	if strings.Contains(path, "/todos/") && (strings.Contains(path, "/comments") || strings.Contains(path, "/links") || strings.Contains(path, "/histories")) {
 		// Extract todo_id
 		parts := strings.Split(path, "/todos/")
 		if len(parts) > 1 {
@@ -164,13 +239,17 @@ func (f *TestFixture) CallAuthGeneric(token, method, path, body string, handlerF
 			var todoID string
 			var resourceID string
 
-			if strings.Contains(subPath, "/comments") {
-				// Split by /comments
-				commentParts := strings.Split(subPath, "/comments")
-				todoID = commentParts[0]
-				if len(commentParts) > 1 && commentParts[1] != "" {
+			if strings.Contains(subPath, "/comments") || strings.Contains(subPath, "/links") {
+				// Split by /comments or /links
+				nested := "/comments"
+				if strings.Contains(subPath, "/links") {
+					nested = "/links"
+				}
+				nestedParts := strings.Split(subPath, nested)
+				todoID = nestedParts[0]
+				if len(nestedParts) > 1 && nestedParts[1] != "" {
 					// /api/v1/todos/{todo_id}/comments/{id}
-					resourceID = strings.TrimPrefix(commentParts[1], "/")
+					resourceID = strings.TrimPrefix(nestedParts[1], "/")
 					if resourceID != "" {
 						c.SetParamNames("todo_id", "id")
 						c.SetParamValues(todoID, resourceID)
@@ -194,18 +273,19 @@ func (f *TestFixture) CallAuthGeneric(token, method, path, body string, handlerF
 	} else {
 		// Extract path params for any resource type
 		// Pattern: /api/v1/{resource}/{id} or /{resource}/{id}
-		resources := []string{"todos", "categories", "tags"}
+		resources := []string{"todos", "categories", "tags", "escalation_rules"}
 		for _, resource := range resources {
 			pattern := "/" + resource + "/"
 			if strings.Contains(path, pattern) {
 				// Skip special endpoints like /todos/update_order
-				if resource == "todos" && strings.HasSuffix(path, "/update_order") {
+				if resource == "todos" && (strings.HasSuffix(path, "/update_order") || strings.HasSuffix(path, "/reorder")) {
 					continue
 				}
 				parts := strings.Split(path, pattern)
 				if len(parts) > 1 && parts[1] != "" {
+					// Drop action suffixes such as /todos/{id}/snooze
 					c.SetParamNames("id")
-					c.SetParamValues(parts[1])
+					c.SetParamValues(strings.SplitN(parts[1], "/", 2)[0])
 					break
 				}
 			}
@@ -219,6 +299,37 @@ func (f *TestFixture) CallAuthGeneric(token, method, path, body string, handlerF
 	return rec, err
 }
 
+// CallAuthWithParams calls a handler with JWT authentication middleware and explicit path params.
+// Use this for routes whose params are not inferred by CallAuthGeneric.
+func (f *TestFixture) CallAuthWithParams(token, method, path, body string, params map[string]string, handlerFunc echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	req.Header.Set("Authorization", token)
+
+	rec := httptest.NewRecorder()
+	c := f.Echo.NewContext(req, rec)
+
+	names := make([]string, 0, len(params))
+	values := make([]string, 0, len(params))
+	for name, value := range params {
+		names = append(names, name)
+		values = append(values, value)
+	}
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+
+	authMiddleware := middleware.JWTAuth(TestConfig, f.UserRepo, f.DenylistRepo)
+	wrappedHandler := authMiddleware(handlerFunc)
+	err := wrappedHandler(c)
+
+	return rec, err
+}
+
 // CallAuth calls a handler with JWT authentication middleware (alias for CallAuthGeneric)
 func (f *TestFixture) CallAuth(token, method, path, body string, handlerFunc echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
 	return f.CallAuthGeneric(token, method, path, body, handlerFunc)
@@ -1,19 +1,21 @@
 package testutil
 
 import (
+	"context"
 	"os"
 	"testing"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
 	"todo-api/internal/config"
 	"todo-api/internal/errors"
 	"todo-api/internal/model"
+	"todo-api/internal/repository"
 	"todo-api/internal/validator"
+	"todo-api/pkg/database"
 )
 
 // TestConfig provides default test configuration
@@ -23,7 +25,7 @@ var TestConfig = &config.Config{
 }
 
 // GetTestDSN returns the database DSN for testing
-// Uses TEST_DATABASE_URL environment variable if set, otherwise falls back to default
+// Uses TEST_DATABASE_URL environment variable if set (any URL database.Open supports), otherwise falls back to default
 func GetTestDSN() string {
 	if dsn := os.Getenv("TEST_DATABASE_URL"); dsn != "" {
 		return dsn
@@ -34,8 +36,9 @@ func GetTestDSN() string {
 
 // SetupTestDB creates a test database connection
 func SetupTestDB(t *testing.T) *gorm.DB {
-	dsn := GetTestDSN()
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	dialector, err := database.Open(GetTestDSN())
+	require.NoError(t, err)
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
@@ -54,8 +57,32 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 		&model.TodoHistory{},
 		&model.Note{},
 		&model.NoteRevision{},
+		&model.UserPreference{},
+		&model.FocusSession{},
+		&model.UserStreak{},
+		&model.StreakCompletion{},
+		&model.UserAchievement{},
+		&model.DataExport{},
+		&model.EscalationRule{},
+		&model.MyDayItem{},
+		&model.TodoLink{},
+		&model.PolicyConsent{},
+		&model.APIUsage{},
+		&model.OAuthAuthorizationCode{},
+		&model.OAuthRefreshToken{},
+		&model.AuditLog{},
+		&model.PendingAuditLog{},
+		&model.PendingSearchUpdate{},
+		&model.Integration{},
+		&model.IntegrationNonce{},
+		&model.TodoTombstone{},
+		&model.CalendarConnection{},
+		&model.CalendarEvent{},
+		&model.File{},
 	)
 	require.NoError(t, err)
+	require.NoError(t, repository.NewAPIUsageRepository(db).DropUserDateIndex(context.Background()))
+	require.NoError(t, database.EnableTrigramSearch(db, false))
 
 	return db
 }
@@ -63,6 +90,26 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 // CleanupTestDB cleans up test data
 func CleanupTestDB(db *gorm.DB) {
 	// Delete in order respecting foreign key constraints
+	db.Exec("DELETE FROM pending_search_updates")
+	db.Exec("DELETE FROM files")
+	db.Exec("DELETE FROM calendar_events")
+	db.Exec("DELETE FROM calendar_connections")
+	db.Exec("DELETE FROM todo_tombstones")
+	db.Exec("DELETE FROM integration_nonces")
+	db.Exec("DELETE FROM integrations")
+	db.Exec("DELETE FROM oauth_refresh_tokens")
+	db.Exec("DELETE FROM oauth_authorization_codes")
+	db.Exec("DELETE FROM api_usages")
+	db.Exec("DELETE FROM policy_consents")
+	db.Exec("DELETE FROM todo_links")
+	db.Exec("DELETE FROM my_day_items")
+	db.Exec("DELETE FROM escalation_rules")
+	db.Exec("DELETE FROM data_exports")
+	db.Exec("DELETE FROM user_achievements")
+	db.Exec("DELETE FROM streak_completions")
+	db.Exec("DELETE FROM user_streaks")
+	db.Exec("DELETE FROM focus_sessions")
+	db.Exec("DELETE FROM user_preferences")
 	db.Exec("DELETE FROM note_revisions")
 	db.Exec("DELETE FROM notes")
 	db.Exec("DELETE FROM comments")
@@ -73,6 +120,9 @@ func CleanupTestDB(db *gorm.DB) {
 	db.Exec("DELETE FROM categories")
 	db.Exec("DELETE FROM jwt_denylists")
 	db.Exec("DELETE FROM users")
+	// Last, as audit callbacks registered by a test record the deletes above
+	db.Exec("DELETE FROM pending_audit_logs")
+	db.Exec("DELETE FROM audit_logs")
 }
 
 // SetupEcho creates an Echo instance for testing
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Dialect identifies the SQL database the API runs against
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+	SQLite   Dialect = "sqlite"
+)
+
+// DialectOf returns the dialect of an open connection
+func DialectOf(db *gorm.DB) Dialect {
+	return Dialect(db.Dialector.Name())
+}
+
+// IsUniqueViolation reports whether err, returned by a statement of db, violates a unique constraint
+func IsUniqueViolation(db *gorm.DB, err error) bool {
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// ILike returns a case-insensitive LIKE condition on column with one placeholder for the pattern
+func (d Dialect) ILike(column string) string {
+	if d == Postgres {
+		return column + " ILIKE ?"
+	}
+	return "LOWER(" + column + ") LIKE LOWER(?)"
+}
+
+// NullSafeEqual returns a condition comparing column with one placeholder, where NULL equals NULL
+func (d Dialect) NullSafeEqual(column string) string {
+	return d.NullSafeEqualColumns(column, "?")
+}
+
+// NullSafeEqualColumns returns a condition comparing two expressions, where NULL equals NULL
+func (d Dialect) NullSafeEqualColumns(left, right string) string {
+	switch d {
+	case MySQL:
+		return left + " <=> " + right
+	case SQLite:
+		return left + " IS " + right
+	default:
+		return left + " IS NOT DISTINCT FROM " + right
+	}
+}
+
+// CastText returns expr converted to a string
+func (d Dialect) CastText(expr string) string {
+	if d == MySQL {
+		return "CAST(" + expr + " AS CHAR)"
+	}
+	return "CAST(" + expr + " AS TEXT)"
+}
+
+// SupportsTrigram reports whether fuzzy matching with pg_trgm is available
+func (d Dialect) SupportsTrigram() bool {
+	return d == Postgres
+}
@@ -0,0 +1,272 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// currentUserSetting is the session variable the row-level security policies compare user_id with,
+// and bypassSetting the one that lets system statements through
+const (
+	currentUserSetting = "app.current_user_id"
+	bypassSetting      = "app.rls_bypass"
+)
+
+// RLSTables are the tables owned by a user through their user_id column
+var RLSTables = []string{
+	"todos",
+	"categories",
+	"tags",
+	"comments",
+	"todo_histories",
+	"todo_links",
+	"files",
+	"my_day_items",
+	"notes",
+	"note_revisions",
+	"focus_sessions",
+	"escalation_rules",
+	"data_exports",
+	"user_preferences",
+	"user_streaks",
+	"streak_completions",
+	"user_achievements",
+	"policy_consents",
+	"api_usages",
+	"oauth_authorization_codes",
+	"oauth_refresh_tokens",
+	"integrations",
+	"todo_tombstones",
+	"calendar_connections",
+	"calendar_events",
+}
+
+// scopeKey is the context key of the row-level security scope
+type scopeKey struct{}
+
+// scope is who the statements of a context run as: a user, or the system
+type scope struct {
+	userID int64
+	system bool
+}
+
+// WithUserID returns a context whose database statements run as the user
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope{userID: userID})
+}
+
+// WithSystem returns a context whose database statements bypass row-level security
+func WithSystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope{system: true})
+}
+
+// scopeFrom returns the row-level security scope of a context
+func scopeFrom(ctx context.Context) (scope, bool) {
+	if ctx == nil {
+		return scope{}, false
+	}
+	s, ok := ctx.Value(scopeKey{}).(scope)
+	return s, ok
+}
+
+// UserIDFrom returns the user the statements of a context run as, if set with ForUser or WithUserID
+func UserIDFrom(ctx context.Context) (int64, bool) {
+	s, ok := scopeFrom(ctx)
+	return s.userID, ok && !s.system
+}
+
+// requestKey is the context key of the API request statements are made for
+type requestKey struct{}
+
+// Request identifies the API request statements are made for
+type Request struct {
+	ID string
+	IP string
+}
+
+// WithRequest returns a context whose database statements are made for the API request
+func WithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// RequestFrom returns the API request the statements of a context are made for, if set with WithRequest
+func RequestFrom(ctx context.Context) (Request, bool) {
+	if ctx == nil {
+		return Request{}, false
+	}
+	req, ok := ctx.Value(requestKey{}).(Request)
+	return req, ok
+}
+
+// ForUser returns db with its statements running with ctx as the user.
+// With row-level security enabled they can only read and write the user's rows;
+// otherwise this has no effect.
+func ForUser(ctx context.Context, db *gorm.DB, userID int64) *gorm.DB {
+	return db.WithContext(WithUserID(ctx, userID))
+}
+
+// AsSystem returns db with its statements running with ctx and bypassing row-level security.
+// It is meant for background jobs and lookups that precede authentication (tokens, signatures),
+// which have to read across users; request handling uses ForUser.
+func AsSystem(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(WithSystem(ctx))
+}
+
+// EnableRowLevelSecurity creates the policies restricting the RLSTables to the current user and
+// switches db to connections that set the current user before every statement. It must run after
+// the tables exist and before db is shared, and is safe to run repeatedly.
+//
+// The policies fail closed: statements run with neither ForUser nor AsSystem see and write no rows.
+func EnableRowLevelSecurity(db *gorm.DB) error {
+	if DialectOf(db) != Postgres {
+		return fmt.Errorf("row-level security requires PostgreSQL, not %s", DialectOf(db))
+	}
+
+	// NULLIF keeps the cast from failing when no user is set; user_id = NULL matches nothing
+	policy := fmt.Sprintf("current_setting('%s', true) = 'on' OR user_id = NULLIF(current_setting('%s', true), '')::bigint",
+		bypassSetting, currentUserSetting)
+	for _, table := range RLSTables {
+		stmts := []string{
+			fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY", table),
+			// The API connects as the table owner, which bypasses policies unless forced
+			fmt.Sprintf("ALTER TABLE %s FORCE ROW LEVEL SECURITY", table),
+			fmt.Sprintf("DROP POLICY IF EXISTS user_isolation ON %s", table),
+			fmt.Sprintf("CREATE POLICY user_isolation ON %s USING (%s)", table, policy),
+		}
+		for _, stmt := range stmts {
+			if err := db.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("failed to enable row-level security on %s: %w", table, err)
+			}
+		}
+	}
+
+	return useScopedConnections(db)
+}
+
+// useScopedConnections replaces the connection pool of db with one of the same size whose
+// connections set the row-level security settings from the context of each statement
+func useScopedConnections(db *gorm.DB) error {
+	dialector, ok := db.Dialector.(*postgres.Dialector)
+	if !ok {
+		return fmt.Errorf("row-level security does not support dialector %T", db.Dialector)
+	}
+	oldDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if _, ok := oldDB.Driver().(scopedDriver); ok {
+		return nil
+	}
+
+	config, err := pgx.ParseConfig(dialector.DSN)
+	if err != nil {
+		return fmt.Errorf("invalid PostgreSQL database URL: %w", err)
+	}
+	sqlDB := sql.OpenDB(scopedConnector{stdlib.GetConnector(*config)})
+	setPoolSize(sqlDB, oldDB.Stats().MaxOpenConnections)
+	sqlDB.SetConnMaxLifetime(time.Hour)
+	if err := sqlDB.Ping(); err != nil {
+		_ = sqlDB.Close()
+		return err
+	}
+
+	db.ConnPool = sqlDB
+	db.Statement.ConnPool = sqlDB
+	dialector.Conn = sqlDB
+	return oldDB.Close()
+}
+
+// scopedConnector opens connections that apply the row-level security scope of each statement
+type scopedConnector struct {
+	driver.Connector
+}
+
+func (c scopedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &scopedConn{Conn: conn.(*stdlib.Conn)}, nil
+}
+
+func (c scopedConnector) Driver() driver.Driver {
+	return scopedDriver{c.Connector.Driver()}
+}
+
+// scopedDriver marks a pool already using scoped connections
+type scopedDriver struct {
+	driver.Driver
+}
+
+// scopedConn sets the row-level security settings before each statement.
+// They are session settings, so a value left on a pooled connection is overwritten by the next statement
+// and never used.
+type scopedConn struct {
+	*stdlib.Conn
+}
+
+// applyScope sets the settings for a statement run with ctx; without a scope both are cleared
+func (c *scopedConn) applyScope(ctx context.Context) error {
+	userID, bypass := "", ""
+	if s, ok := scopeFrom(ctx); ok {
+		if s.system {
+			bypass = "on"
+		} else {
+			userID = strconv.FormatInt(s.userID, 10)
+		}
+	}
+	_, err := c.Conn.Conn().Exec(ctx, "SELECT set_config($1, $2, false), set_config($3, $4, false)",
+		currentUserSetting, userID, bypassSetting, bypass)
+	return err
+}
+
+func (c *scopedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.applyScope(ctx); err != nil {
+		return nil, err
+	}
+	return c.Conn.ExecContext(ctx, query, args)
+}
+
+func (c *scopedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.applyScope(ctx); err != nil {
+		return nil, err
+	}
+	return c.Conn.QueryContext(ctx, query, args)
+}
+
+func (c *scopedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &scopedStmt{Stmt: stmt.(*stdlib.Stmt), conn: c}, nil
+}
+
+// scopedStmt applies the scope before each execution of a prepared statement
+type scopedStmt struct {
+	*stdlib.Stmt
+	conn *scopedConn
+}
+
+func (s *scopedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.conn.applyScope(ctx); err != nil {
+		return nil, err
+	}
+	return s.Stmt.ExecContext(ctx, args)
+}
+
+func (s *scopedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.conn.applyScope(ctx); err != nil {
+		return nil, err
+	}
+	return s.Stmt.QueryContext(ctx, args)
+}
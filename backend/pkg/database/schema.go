@@ -0,0 +1,56 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ConnectSchema connects to the PostgreSQL database of the URL with schema first in the search path,
+// so unqualified table names refer to the tables of the schema. public stays in the search path
+// for extensions such as pg_trgm.
+func ConnectSchema(databaseURL, schema string, maxOpenConns int) (*gorm.DB, error) {
+	if strings.HasPrefix(databaseURL, "mysql://") || strings.HasPrefix(databaseURL, "sqlite:") {
+		return nil, fmt.Errorf("database schemas require PostgreSQL")
+	}
+
+	dsn, err := withSearchPath(databaseURL, schema+",public")
+	if err != nil {
+		return nil, err
+	}
+	db, err := Connect(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every schema has a pool of its own, so keep each one small
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	setPoolSize(sqlDB, maxOpenConns)
+	return db, nil
+}
+
+// withSearchPath adds a search_path run-time parameter to a PostgreSQL URL or key=value DSN
+func withSearchPath(databaseURL, searchPath string) (string, error) {
+	if !strings.HasPrefix(databaseURL, "postgres://") && !strings.HasPrefix(databaseURL, "postgresql://") {
+		return databaseURL + " search_path=" + searchPath, nil
+	}
+
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid PostgreSQL database URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("search_path", searchPath)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// CreateSchema creates a schema unless it exists
+func CreateSchema(db *gorm.DB, schema string) error {
+	return db.Exec(`CREATE SCHEMA IF NOT EXISTS "` + strings.ReplaceAll(schema, `"`, `""`) + `"`).Error
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"reflect"
+	"regexp"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// idCondition matches a condition on the ID, e.g. "id = ? AND user_id = ?"
+var idCondition = regexp.MustCompile(`^\s*(\w+\.)?id\s*=\s*\?`)
+
+// WrittenID returns the primary key of the row a write targets, for use in write callbacks: that of the
+// written model value, or of an "id = ?" condition. Writes of several rows or by other conditions have none.
+func WrittenID(tx *gorm.DB) (any, bool) {
+	stmt := tx.Statement
+	if stmt.Schema != nil && stmt.Schema.PrioritizedPrimaryField != nil && stmt.ReflectValue.Kind() == reflect.Struct {
+		if value, zero := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, stmt.ReflectValue); !zero {
+			return value, true
+		}
+	}
+
+	where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where)
+	if !ok {
+		return nil, false
+	}
+	for _, expr := range where.Exprs {
+		switch e := expr.(type) {
+		case clause.Expr:
+			if len(e.Vars) > 0 && idCondition.MatchString(e.SQL) {
+				return e.Vars[0], true
+			}
+		case clause.IN:
+			// Delete(&model.X{}, id) adds the ID as a primary key condition
+			if column, ok := e.Column.(clause.Column); ok && (column.Name == clause.PrimaryKey || column.Name == "id") && len(e.Values) == 1 {
+				return e.Values[0], true
+			}
+		}
+	}
+	return nil, false
+}